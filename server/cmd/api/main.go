@@ -12,8 +12,11 @@ import (
 	"time"
 
 	"github.com/crea8r/muninn/server/internal/api"
+	"github.com/crea8r/muninn/server/internal/blob"
+	"github.com/crea8r/muninn/server/internal/calendar"
 	"github.com/crea8r/muninn/server/internal/config"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/maildrop"
 	"github.com/crea8r/muninn/server/internal/service"
 	"github.com/crea8r/muninn/server/internal/task"
 	_ "github.com/lib/pq"
@@ -38,8 +41,31 @@ func main() {
 
 	// Initialize services
 	queries := database.New(db)
-	automationSvc := service.NewAutomationService(queries)
-	taskRunner := task.NewRunner(queries, automationSvc)
+
+	// Bulk operations run within the server, the ones a previous run left
+	// pending or processing will never finish
+	interrupted, err := queries.FailInterruptedBulkOperations(context.Background(), sql.NullString{
+		String: "Interrupted by a server restart",
+		Valid:  true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to fail interrupted bulk operations: %v", err)
+	}
+	if interrupted > 0 {
+		log.Printf("Marked %d interrupted bulk operations as failed", interrupted)
+	}
+
+	automationSvc := service.NewAutomationService(queries, db)
+	trashSvc := service.NewTrashService(queries, db)
+	blobStore, err := blob.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up blob store: %v", err)
+	}
+	photoSvc := service.NewPhotoService(queries, db, blobStore)
+	attachmentSvc := service.NewAttachmentService(queries, blobStore)
+	healthSvc := service.NewHealthService(queries)
+	calendarImporter := calendar.NewImporter(calendar.ConfigFromEnv(), queries, db)
+	taskRunner := task.NewRunner(queries, automationSvc, trashSvc, photoSvc, attachmentSvc, healthSvc, calendarImporter, cfg.TrashRetention)
 
 	// Setup router
 	router := api.SetupRouter(queries, db)
@@ -48,6 +74,23 @@ func main() {
 		Handler: router,
 	}
 
+	// Mail drop-box, when SMTP_ADDR is set
+	mailConfig, err := maildrop.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load mail drop-box configuration: %v", err)
+	}
+	var mailServer *maildrop.Server
+	if mailConfig.Addr != "" {
+		ingester := maildrop.NewIngester(mailConfig, queries, db, attachmentSvc)
+		mailServer = &maildrop.Server{
+			Addr:     mailConfig.Addr,
+			Hostname: mailConfig.Domain,
+			MaxSize:  mailConfig.MaxSize,
+			Accept:   ingester.Accept,
+			Deliver:  ingester.Deliver,
+		}
+	}
+
 	// Start task runner
 	taskRunner.Start()
 
@@ -62,6 +105,14 @@ func main() {
 				log.Fatalf("HTTP server error: %v", err)
 		}
 	}()
+	if mailServer != nil {
+		go func() {
+			fmt.Printf("Mail drop-box is listening on %s\n", mailConfig.Addr)
+			if err := mailServer.ListenAndServe(); err != maildrop.ErrServerClosed {
+				log.Fatalf("SMTP server error: %v", err)
+			}
+		}()
+	}
 
 	// Wait for shutdown signal
 	<-shutdown
@@ -78,6 +129,11 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("HTTP server shutdown error: %v", err)
 	}
+	if mailServer != nil {
+		if err := mailServer.Close(ctx); err != nil {
+			log.Printf("SMTP server shutdown error: %v", err)
+		}
+	}
 
 	log.Println("Server gracefully stopped")
 }
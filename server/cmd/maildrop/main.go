@@ -0,0 +1,75 @@
+// Command maildrop runs the mail drop-box SMTP server on its own, for
+// deployments where the API does not receive mail. It listens on SMTP_ADDR,
+// ":2525" by default.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/blob"
+	"github.com/crea8r/muninn/server/internal/config"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/maildrop"
+	"github.com/crea8r/muninn/server/internal/service"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	mailConfig, err := maildrop.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load mail drop-box configuration: %v", err)
+	}
+	if mailConfig.Addr == "" {
+		mailConfig.Addr = ":2525"
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	queries := database.New(db)
+	blobStore, err := blob.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up blob store: %v", err)
+	}
+	ingester := maildrop.NewIngester(mailConfig, queries, db, service.NewAttachmentService(queries, blobStore))
+	server := &maildrop.Server{
+		Addr:     mailConfig.Addr,
+		Hostname: mailConfig.Domain,
+		MaxSize:  mailConfig.MaxSize,
+		Accept:   ingester.Accept,
+		Deliver:  ingester.Deliver,
+	}
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		log.Printf("Mail drop-box is listening on %s for @%s", mailConfig.Addr, mailConfig.Domain)
+		if err := server.ListenAndServe(); err != maildrop.ErrServerClosed {
+			log.Fatalf("SMTP server error: %v", err)
+		}
+	}()
+
+	<-shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Close(ctx); err != nil {
+		log.Printf("SMTP server shutdown error: %v", err)
+	}
+	log.Println("Mail drop-box stopped")
+}
@@ -2,15 +2,30 @@ package main
 
 import (
 	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/crea8r/muninn/server/internal/config"
 	_ "github.com/lib/pq"
 )
 
+// Applies the migrations of the migrations directory that the database has
+// not applied yet, in the order of their file names. Each migration runs in
+// its own transaction with the row recording it in schema_version.
+//
+//	migrate                         apply the pending migrations
+//	migrate -baseline 004_x.sql     record up to 004_x.sql as already applied, for
+//	                                databases migrated by hand, then apply the rest
+//	migrate migrations/004_x.sql    apply only the given file
 func main() {
+	dir := flag.String("dir", "migrations", "directory of the migration files")
+	baseline := flag.String("baseline", "", "last migration file already applied by hand")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
@@ -26,24 +41,133 @@ func main() {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
-	// Read the migration file
-	migrationFile := "migrations/004_add_creator_access_obj_type.sql"
-	content, err := os.ReadFile(migrationFile)
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+    filename TEXT PRIMARY KEY,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`); err != nil {
+		log.Fatalf("Failed to create schema_version: %v", err)
+	}
+
+	if flag.NArg() > 0 {
+		migrationFile := flag.Arg(0)
+		if err := apply(db, migrationFile); err != nil {
+			log.Fatalf("Failed to execute migration %s: %v", migrationFile, err)
+		}
+		log.Printf("Migration applied successfully: %s", migrationFile)
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.sql"))
+	if err != nil {
+		log.Fatalf("Failed to list migrations: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("No migration found in %s", *dir)
+	}
+	sort.Strings(files)
+
+	applied, err := appliedMigrations(db)
 	if err != nil {
-		// Try absolute path if relative fails
-		cwd, _ := os.Getwd()
-		migrationFile = filepath.Join(cwd, "migrations/004_add_creator_access_obj_type.sql")
-		content, err = os.ReadFile(migrationFile)
-		if err != nil {
-			log.Fatalf("Failed to read migration file: %v", err)
+		log.Fatalf("Failed to read schema_version: %v", err)
+	}
+	if *baseline != "" {
+		if err := recordBaseline(db, files, *baseline); err != nil {
+			log.Fatalf("Failed to record the baseline: %v", err)
+		}
+		if applied, err = appliedMigrations(db); err != nil {
+			log.Fatalf("Failed to read schema_version: %v", err)
+		}
+	} else if len(applied) == 0 {
+		// Migrations used to be run by hand, running them all again on such a
+		// database would fail half way
+		var migrated bool
+		if err := db.QueryRow(`SELECT to_regclass('obj') IS NOT NULL`).Scan(&migrated); err != nil {
+			log.Fatalf("Failed to inspect the database: %v", err)
+		}
+		if migrated {
+			log.Fatalf("The database has tables but no schema_version, run again with -baseline set to the last migration it has")
+		}
+	}
+
+	count := 0
+	for _, file := range files {
+		if applied[filepath.Base(file)] {
+			continue
+		}
+		if err := apply(db, file); err != nil {
+			log.Fatalf("Failed to execute migration %s: %v", file, err)
 		}
+		log.Printf("Migration applied successfully: %s", file)
+		count++
 	}
+	if count == 0 {
+		log.Println("The database is up to date")
+	}
+}
 
-	query := string(content)
-	_, err = db.Exec(query)
+// appliedMigrations returns the file names recorded in schema_version
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT filename FROM schema_version`)
 	if err != nil {
-		log.Fatalf("Failed to execute migration: %v", err)
+		return nil, err
 	}
+	defer rows.Close()
+	applied := map[string]bool{}
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, err
+		}
+		applied[filename] = true
+	}
+	return applied, rows.Err()
+}
+
+// recordBaseline records the files up to baseline as applied without
+// running them
+func recordBaseline(db *sql.DB, files []string, baseline string) error {
+	baseline = filepath.Base(baseline)
+	found := false
+	for _, file := range files {
+		if filepath.Base(file) == baseline {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s is not one of the migrations", baseline)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, file := range files {
+		if _, err := tx.Exec(`INSERT INTO schema_version (filename) VALUES ($1) ON CONFLICT DO NOTHING`, filepath.Base(file)); err != nil {
+			return err
+		}
+		if filepath.Base(file) == baseline {
+			break
+		}
+	}
+	return tx.Commit()
+}
 
-	log.Println("Migration applied successfully: Added creator_obj_type_access table")
+// apply runs a migration file and records it, in one transaction
+func apply(db *sql.DB, migrationFile string) error {
+	content, err := os.ReadFile(migrationFile)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(string(content)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (filename) VALUES ($1) ON CONFLICT DO NOTHING`, filepath.Base(migrationFile)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
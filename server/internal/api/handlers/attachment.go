@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type AttachmentHandler struct {
+	attachments *service.AttachmentService
+}
+
+func NewAttachmentHandler(attachments *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachments: attachments}
+}
+
+// UploadToFact attaches the "file" of a multipart form to the fact {id}
+func (h *AttachmentHandler) UploadToFact(w http.ResponseWriter, r *http.Request) {
+	factID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid fact ID", http.StatusBadRequest)
+		return
+	}
+	h.upload(w, r, service.AttachmentTarget{FactID: factID})
+}
+
+// UploadToObject attaches the "file" of a multipart form to the object {id}
+func (h *AttachmentHandler) UploadToObject(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	h.upload(w, r, service.AttachmentTarget{ObjID: objectID})
+}
+
+func (h *AttachmentHandler) upload(w http.ResponseWriter, r *http.Request, target service.AttachmentTarget) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	// Leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxAttachmentSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, service.ErrAttachmentTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachments.Upload(r.Context(), target, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID), header.Filename, file)
+	switch {
+	case err == service.ErrAttachmentTooLarge:
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err == service.ErrAttachmentTarget:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("Error uploading attachment: %v", err)
+		http.Error(w, "Failed to upload attachment", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.NewAttachment(attachment))
+}
+
+// Download sends the file of an attachment of the org
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	attachment, rc, err := h.attachments.Open(r.Context(), id, uuid.MustParse(claims.OrgID))
+	if err == service.ErrAttachmentNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load attachment", http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, rc)
+}
+
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.attachments.Delete(r.Context(), id, uuid.MustParse(claims.OrgID))
+	if err == service.ErrAttachmentNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
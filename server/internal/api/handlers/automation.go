@@ -9,6 +9,7 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -67,6 +68,10 @@ func (h *AutomationHandler) CreateAction(w http.ResponseWriter, r *http.Request)
         return
     }
 
+    if !h.checkFilterConfig(w, r, input.FilterConfig) {
+        return
+    }
+
     action, err := h.db.CreateAutomatedAction(r.Context(), database.CreateAutomatedActionParams{
         OrgID:        uuid.MustParse(claims.OrgID),
         Name:         input.Name,
@@ -83,6 +88,22 @@ func (h *AutomationHandler) CreateAction(w http.ResponseWriter, r *http.Request)
     json.NewEncoder(w).Encode(action)
 }
 
+// checkFilterConfig checks the text query of an automation filter and writes
+// a 400 with the positions of its errors. It returns false when the request
+// must stop.
+func (h *AutomationHandler) checkFilterConfig(w http.ResponseWriter, r *http.Request, filterConfig json.RawMessage) bool {
+    claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+    err := service.CheckFilterQuery(r.Context(), h.db, uuid.MustParse(claims.OrgID), filterConfig)
+    if writeQueryError(w, err) {
+        return false
+    }
+    if err != nil {
+        http.Error(w, "Failed to check filter config", http.StatusInternalServerError)
+        return false
+    }
+    return true
+}
+
 // ListActions returns all automated actions for an organization
 func (h *AutomationHandler) ListActions(w http.ResponseWriter, r *http.Request) {
     claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
@@ -283,6 +304,10 @@ func (h *AutomationHandler) UpdateAction(w http.ResponseWriter, r *http.Request)
         return
     }
 
+    if !h.checkFilterConfig(w, r, input.FilterConfig) {
+        return
+    }
+
     // Update action
     updatedAction, err := h.db.UpdateAutomatedAction(r.Context(), database.UpdateAutomatedActionParams{
         ID:           uuid.MustParse(actionID),
@@ -0,0 +1,566 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/query"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/crea8r/muninn/server/internal/service"
+)
+
+// Operations applied by POST /objects/bulk
+const (
+	BulkAddTags      = "add_tags"
+	BulkRemoveTags   = "remove_tags"
+	BulkSetTypeValue = "set_type_value"
+	BulkMoveToStep   = "move_to_step"
+	BulkSetSubStatus = "set_sub_status"
+	BulkAssignOwner  = "assign_owner"
+	BulkDelete       = "delete"
+)
+
+const (
+	// maxBulkObjects caps the objects of one bulk operation
+	maxBulkObjects = 10000
+	// bulkChunkSize is the number of objects changed per transaction.
+	// Selections of up to one chunk are applied within the request, larger
+	// ones in the background.
+	bulkChunkSize = 100
+)
+
+var errNotInStep = errors.New("object is not in the step")
+
+type BulkOperationHandler struct {
+	db            *sql.DB
+	queries       *database.Queries
+	objectService *service.ObjectService
+}
+
+func NewBulkOperationHandler(db *sql.DB, objectService *service.ObjectService) *BulkOperationHandler {
+	return &BulkOperationHandler{
+		db:            db,
+		queries:       database.New(db),
+		objectService: objectService,
+	}
+}
+
+// BulkFilter selects objects like the query parameters of /objects/advanced
+type BulkFilter struct {
+	Query             string            `json:"q"`
+	StepIDs           []uuid.UUID       `json:"step_ids"`
+	TagIDs            []uuid.UUID       `json:"tag_ids"`
+	TypeIDs           []uuid.UUID       `json:"type_ids"`
+	TypeValueCriteria []json.RawMessage `json:"type_value_criteria"`
+	SubStatus         []int32           `json:"sub_status"`
+	FactKinds         []string          `json:"fact_kinds"`
+	// Text is a text query, as the query of /objects/advanced
+	Text string `json:"query"`
+}
+
+// BulkOperationParams holds the arguments of an operation, each operation
+// uses its own
+type BulkOperationParams struct {
+	TagIDs     []uuid.UUID     `json:"tag_ids,omitempty"`
+	TypeID     *uuid.UUID      `json:"type_id,omitempty"`
+	TypeValues json.RawMessage `json:"type_values,omitempty"`
+	StepID     *uuid.UUID      `json:"step_id,omitempty"`
+	SubStatus  *int32          `json:"sub_status,omitempty"`
+	OwnerID    *uuid.UUID      `json:"owner_id,omitempty"`
+}
+
+// BulkOperationRequest applies an operation to the objects of ObjectIDs or
+// to those matching Filter
+type BulkOperationRequest struct {
+	ObjectIDs []uuid.UUID         `json:"object_ids"`
+	Filter    *BulkFilter         `json:"filter"`
+	Operation string              `json:"operation"`
+	Params    BulkOperationParams `json:"params"`
+}
+
+// BulkObjectResult is the outcome of the operation on one object
+type BulkObjectResult struct {
+	ObjectID uuid.UUID `json:"object_id"`
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// bulkJob is what a bulk operation needs once the request is gone
+type bulkJob struct {
+	operation database.BulkOperation
+	params    BulkOperationParams
+	objectIDs []uuid.UUID
+	viewer    schema.Viewer
+}
+
+func (h *BulkOperationHandler) CreateBulkOperation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	var req BulkOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if status, err := h.checkParams(ctx, orgID, req.Operation, req.Params); err != nil {
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+
+	objectIDs, status, err := h.selectObjects(ctx, claims, req)
+	if writeQueryError(w, err) {
+		return
+	}
+	if err != nil {
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+
+	params, _ := json.Marshal(req.Params)
+	operation, err := h.queries.CreateBulkOperation(ctx, database.CreateBulkOperationParams{
+		OrgID:     orgID,
+		CreatorID: creatorID,
+		Operation: req.Operation,
+		Params:    params,
+		TotalRows: int32(len(objectIDs)),
+	})
+	if err != nil {
+		http.Error(w, "Failed to create bulk operation", http.StatusInternalServerError)
+		return
+	}
+
+	job := bulkJob{
+		operation: operation,
+		params:    req.Params,
+		objectIDs: objectIDs,
+		viewer:    viewerFromClaims(claims),
+	}
+	// The job outlives the request, changes keep the source of the request
+	jobCtx := history.WithSource(context.Background(), history.SourceFrom(ctx))
+	if len(objectIDs) > bulkChunkSize {
+		go h.processBulkOperation(jobCtx, job)
+		writeJSON(w, http.StatusAccepted, operation)
+		return
+	}
+
+	h.processBulkOperation(jobCtx, job)
+	operation, err = h.queries.GetBulkOperation(ctx, database.GetBulkOperationParams{
+		ID:    operation.ID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to get bulk operation", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, operation)
+}
+
+// checkParams verifies the operation has its arguments and that they belong
+// to the org, returning the status to answer with otherwise
+func (h *BulkOperationHandler) checkParams(ctx context.Context, orgID uuid.UUID, operation string, params BulkOperationParams) (int, error) {
+	switch operation {
+	case BulkAddTags, BulkRemoveTags:
+		if len(params.TagIDs) == 0 {
+			return http.StatusBadRequest, errors.New("tag_ids is required")
+		}
+		count, err := h.queries.CountOrgTags(ctx, database.CountOrgTagsParams{
+			Column1: params.TagIDs,
+			OrgID:   orgID,
+		})
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("failed to check tags")
+		}
+		if int(count) != len(uniqueIDs(params.TagIDs)) {
+			return http.StatusNotFound, errors.New("tag not found")
+		}
+	case BulkSetTypeValue:
+		if params.TypeID == nil {
+			return http.StatusBadRequest, errors.New("type_id is required")
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(params.TypeValues, &values); err != nil || values == nil {
+			return http.StatusBadRequest, errors.New("type_values must be a JSON object")
+		}
+		found, err := h.queries.IsOrgObjectType(ctx, database.IsOrgObjectTypeParams{ID: *params.TypeID, OrgID: orgID})
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("failed to check object type")
+		}
+		if !found {
+			return http.StatusNotFound, errors.New("object type not found")
+		}
+	case BulkMoveToStep, BulkSetSubStatus:
+		if params.StepID == nil {
+			return http.StatusBadRequest, errors.New("step_id is required")
+		}
+		if operation == BulkSetSubStatus && params.SubStatus == nil {
+			return http.StatusBadRequest, errors.New("sub_status is required")
+		}
+		found, err := h.queries.IsOrgStep(ctx, database.IsOrgStepParams{ID: *params.StepID, OrgID: orgID})
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("failed to check step")
+		}
+		if !found {
+			return http.StatusNotFound, errors.New("step not found")
+		}
+	case BulkAssignOwner:
+		if params.OwnerID == nil {
+			return http.StatusBadRequest, errors.New("owner_id is required")
+		}
+		found, err := h.queries.IsOrgCreator(ctx, database.IsOrgCreatorParams{ID: *params.OwnerID, OrgID: orgID})
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("failed to check owner")
+		}
+		if !found {
+			return http.StatusNotFound, errors.New("owner not found")
+		}
+	case BulkDelete:
+	default:
+		return http.StatusBadRequest, fmt.Errorf("unknown operation %q", operation)
+	}
+	return 0, nil
+}
+
+// selectObjects returns the objects the request applies to
+func (h *BulkOperationHandler) selectObjects(ctx context.Context, claims *middleware.Claims, req BulkOperationRequest) ([]uuid.UUID, int, error) {
+	var objectIDs []uuid.UUID
+	switch {
+	case len(req.ObjectIDs) > 0 && req.Filter != nil:
+		return nil, http.StatusBadRequest, errors.New("object_ids and filter cannot be used together")
+	case len(req.ObjectIDs) > 0:
+		objectIDs = uniqueIDs(req.ObjectIDs)
+	case req.Filter != nil:
+		ids, err := h.objectService.ListObjectIDs(ctx, service.ListObjectsParams{
+			OrgID:             uuid.MustParse(claims.OrgID),
+			SearchQuery:       req.Filter.Query,
+			StepIDs:           req.Filter.StepIDs,
+			TagIDs:            req.Filter.TagIDs,
+			TypeIDs:           req.Filter.TypeIDs,
+			TypeValueCriteria: req.Filter.TypeValueCriteria,
+			SubStatusFilter:   req.Filter.SubStatus,
+			FactKinds:         req.Filter.FactKinds,
+			Viewer:            viewerFromClaims(claims),
+			Query:             req.Filter.Text,
+		}, maxBulkObjects)
+		if errors.Is(err, service.ErrRestrictedField) {
+			return nil, http.StatusForbidden, err
+		}
+		var qerr *query.Error
+		if errors.As(err, &qerr) || errors.Is(err, service.ErrComputedScanLimit) {
+			return nil, http.StatusBadRequest, err
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.New("failed to list objects")
+		}
+		objectIDs = ids
+	default:
+		return nil, http.StatusBadRequest, errors.New("object_ids or filter is required")
+	}
+	if len(objectIDs) == 0 {
+		return nil, http.StatusBadRequest, errors.New("no object selected")
+	}
+	if len(objectIDs) > maxBulkObjects {
+		return nil, http.StatusBadRequest, fmt.Errorf("a bulk operation is limited to %d objects", maxBulkObjects)
+	}
+	return objectIDs, 0, nil
+}
+
+func (h *BulkOperationHandler) GetBulkOperation(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	operationID, err := uuid.Parse(chi.URLParam(r, "bulkId"))
+	if err != nil {
+		http.Error(w, "Invalid bulk operation ID", http.StatusBadRequest)
+		return
+	}
+
+	operation, err := h.queries.GetBulkOperation(r.Context(), database.GetBulkOperationParams{
+		ID:    operationID,
+		OrgID: orgID,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Bulk operation not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to get bulk operation", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, operation)
+}
+
+func (h *BulkOperationHandler) ListBulkOperations(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	operations, err := h.queries.ListBulkOperations(r.Context(), database.ListBulkOperationsParams{
+		OrgID:  orgID,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		http.Error(w, "Failed to list bulk operations", http.StatusInternalServerError)
+		return
+	}
+	if operations == nil {
+		operations = []database.BulkOperation{}
+	}
+
+	writeJSON(w, http.StatusOK, operations)
+}
+
+func (h *BulkOperationHandler) processBulkOperation(ctx context.Context, job bulkJob) {
+	operationID := job.operation.ID
+	if err := h.queries.UpdateBulkOperationStatus(ctx, database.UpdateBulkOperationStatusParams{
+		ID:     operationID,
+		Status: "processing",
+	}); err != nil {
+		h.failBulkOperation(ctx, operationID, "Failed to update bulk operation status", err, nil)
+		return
+	}
+
+	var results []BulkObjectResult
+	failed := 0
+	for start := 0; start < len(job.objectIDs); start += bulkChunkSize {
+		end := start + bulkChunkSize
+		if end > len(job.objectIDs) {
+			end = len(job.objectIDs)
+		}
+		chunkResults, err := h.applyChunk(ctx, job, job.objectIDs[start:end])
+		if err != nil {
+			h.failBulkOperation(ctx, operationID, "Failed to apply chunk", err, results)
+			return
+		}
+		for _, result := range chunkResults {
+			if result.Status == "failed" {
+				failed++
+			}
+		}
+		results = append(results, chunkResults...)
+
+		if err := h.queries.UpdateBulkOperationProgress(ctx, database.UpdateBulkOperationProgressParams{
+			ID:            operationID,
+			Progress:      sql.NullInt32{Int32: int32(end * 100 / len(job.objectIDs)), Valid: true},
+			ProcessedRows: sql.NullInt32{Int32: int32(end), Valid: true},
+			FailedRows:    sql.NullInt32{Int32: int32(failed), Valid: true},
+		}); err != nil {
+			h.failBulkOperation(ctx, operationID, "Failed to update progress", err, results)
+			return
+		}
+	}
+
+	summary, _ := json.Marshal(map[string]interface{}{
+		"total_rows":     len(results),
+		"succeeded_rows": len(results) - failed,
+		"failed_rows":    failed,
+		"results":        results,
+	})
+	if err := h.queries.CompleteBulkOperation(ctx, database.CompleteBulkOperationParams{
+		ID:            operationID,
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summary, Valid: true},
+	}); err != nil {
+		fmt.Printf("Failed to complete bulk operation %s: %v\n", operationID, err)
+	}
+}
+
+// applyChunk applies the operation to a chunk of objects in one transaction.
+// Each object runs under a savepoint so a failing object is rolled back alone
+// and reported, the others of the chunk are kept.
+func (h *BulkOperationHandler) applyChunk(ctx context.Context, job bulkJob, objectIDs []uuid.UUID) ([]BulkObjectResult, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	// Unique field values are checked at each write instead of at commit, a
+	// taken value fails its object instead of the whole chunk
+	if _, err := tx.ExecContext(ctx, "SET CONSTRAINTS obj_type_value_unique IMMEDIATE"); err != nil {
+		return nil, fmt.Errorf("failed to check unique values immediately: %w", err)
+	}
+	qtx := h.queries.WithTx(tx)
+	model := models.NewObjectModel(qtx, nil)
+
+	active, err := qtx.FilterOrgObjectIDs(ctx, database.FilterOrgObjectIDsParams{
+		Column1: objectIDs,
+		OrgID:   job.operation.OrgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load objects: %w", err)
+	}
+	found := make(map[uuid.UUID]bool, len(active))
+	for _, id := range active {
+		found[id] = true
+	}
+
+	results := make([]BulkObjectResult, 0, len(objectIDs))
+	for _, objectID := range objectIDs {
+		if !found[objectID] {
+			results = append(results, BulkObjectResult{ObjectID: objectID, Status: "failed", Error: "object not found"})
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_object"); err != nil {
+			return nil, err
+		}
+		if err := h.applyToObject(ctx, model, qtx, job, objectID); err != nil {
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_object"); rbErr != nil {
+				return nil, rbErr
+			}
+			results = append(results, BulkObjectResult{ObjectID: objectID, Status: "failed", Error: bulkErrorMessage(err)})
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_object"); err != nil {
+			return nil, err
+		}
+		results = append(results, BulkObjectResult{ObjectID: objectID, Status: "succeeded"})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return results, nil
+}
+
+func (h *BulkOperationHandler) applyToObject(ctx context.Context, model *models.ObjectModel, qtx *database.Queries, job bulkJob, objectID uuid.UUID) error {
+	orgID := job.operation.OrgID
+	actorID := job.operation.CreatorID
+	params := job.params
+	switch job.operation.Operation {
+	case BulkAddTags:
+		for _, tagID := range params.TagIDs {
+			if err := model.AddTag(ctx, objectID, tagID, orgID, actorID); err != nil {
+				return err
+			}
+		}
+	case BulkRemoveTags:
+		for _, tagID := range params.TagIDs {
+			if err := model.RemoveTag(ctx, objectID, tagID, orgID, actorID); err != nil {
+				return err
+			}
+		}
+	case BulkSetTypeValue:
+		existing, err := qtx.GetObjectTypeValue(ctx, database.GetObjectTypeValueParams{
+			ObjID:  objectID,
+			TypeID: *params.TypeID,
+		})
+		if err == sql.ErrNoRows {
+			_, err = model.AddObjectTypeValue(ctx, objectID, *params.TypeID, params.TypeValues, orgID, actorID, job.viewer)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		// Only the given fields are set, the others keep their value
+		values := map[string]interface{}{}
+		if len(existing.TypeValues) > 0 {
+			if err := json.Unmarshal(existing.TypeValues, &values); err != nil || values == nil {
+				values = map[string]interface{}{}
+			}
+		}
+		var changes map[string]interface{}
+		json.Unmarshal(params.TypeValues, &changes)
+		for field, value := range changes {
+			values[field] = value
+		}
+		merged, _ := json.Marshal(values)
+		_, err = model.UpdateObjectTypeValue(ctx, existing.ID, orgID, actorID, merged, job.viewer, existing.Version)
+		return err
+	case BulkMoveToStep:
+		objStep, err := model.CreateObjStep(ctx, objectID, *params.StepID, actorID)
+		if err != nil {
+			return err
+		}
+		if params.SubStatus != nil {
+			return model.UpdateObjStepSubStatus(ctx, objStep.ID, *params.SubStatus, actorID)
+		}
+	case BulkSetSubStatus:
+		objStep, err := qtx.GetActiveObjStep(ctx, database.GetActiveObjStepParams{
+			ObjID:  objectID,
+			StepID: *params.StepID,
+		})
+		if err == sql.ErrNoRows {
+			return errNotInStep
+		}
+		if err != nil {
+			return err
+		}
+		return model.UpdateObjStepSubStatus(ctx, objStep.ID, *params.SubStatus, actorID)
+	case BulkAssignOwner:
+		return model.SetOwner(ctx, objectID, *params.OwnerID, actorID)
+	case BulkDelete:
+		return model.Delete(ctx, objectID, actorID)
+	}
+	return nil
+}
+
+// bulkErrorMessage is the error reported for one object
+func bulkErrorMessage(err error) string {
+	var conflict *schema.UniqueConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Error()
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		switch pqErr.Constraint {
+		case "obj_id_string_creator_id_key":
+			return "an object of the owner already uses this ID string"
+		case "obj_type_value_unique_field":
+			// Raised by migration 025, it names the field, value and object
+			return pqErr.Message
+		}
+		return "a unique value is already used"
+	}
+	if err == sql.ErrNoRows {
+		return "not found"
+	}
+	return err.Error()
+}
+
+func (h *BulkOperationHandler) failBulkOperation(ctx context.Context, operationID uuid.UUID, message string, err error, results []BulkObjectResult) {
+	summary, _ := json.Marshal(map[string]interface{}{"results": results})
+	updateErr := h.queries.FailBulkOperation(ctx, database.FailBulkOperationParams{
+		ID:            operationID,
+		ErrorMessage:  sql.NullString{String: fmt.Sprintf("%s: %v", message, err), Valid: true},
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summary, Valid: true},
+	})
+	if updateErr != nil {
+		fmt.Printf("Failed to update bulk operation error: %v\n", updateErr)
+	}
+}
+
+// uniqueIDs returns ids without duplicates, in their first order
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
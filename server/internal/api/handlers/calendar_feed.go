@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/calendar"
+	"github.com/crea8r/muninn/server/internal/database"
+)
+
+// calendarSyncTimeout bounds the sync of a feed started from a request
+const calendarSyncTimeout = 5 * time.Minute
+
+type CalendarFeedHandler struct {
+	db        *database.Queries
+	calendars *calendar.Importer
+}
+
+func NewCalendarFeedHandler(db *database.Queries, calendars *calendar.Importer) *CalendarFeedHandler {
+	return &CalendarFeedHandler{db: db, calendars: calendars}
+}
+
+// CalendarFeed is a calendar the org is subscribed to, its events are
+// imported as meeting facts as they start
+type CalendarFeed struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	URL          string     `json:"url"`
+	CreatorID    uuid.UUID  `json:"creatorId"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt"` // last successful sync
+	LastError    *string    `json:"lastError"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+func calendarFeedResponse(feed database.CalendarFeed) CalendarFeed {
+	response := CalendarFeed{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		URL:       feed.Url,
+		CreatorID: feed.CreatorID,
+		CreatedAt: feed.CreatedAt,
+	}
+	if feed.LastSyncedAt.Valid {
+		response.LastSyncedAt = &feed.LastSyncedAt.Time
+	}
+	if feed.LastError.Valid {
+		response.LastError = &feed.LastError.String
+	}
+	return response
+}
+
+type CreateCalendarFeedRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// List returns the calendar feeds of the org
+func (h *CalendarFeedHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	feeds, err := h.db.ListCalendarFeeds(r.Context(), uuid.MustParse(claims.OrgID))
+	if err != nil {
+		http.Error(w, "Failed to list calendar feeds", http.StatusInternalServerError)
+		return
+	}
+	response := make([]CalendarFeed, len(feeds))
+	for i, feed := range feeds {
+		response[i] = calendarFeedResponse(feed)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Create subscribes the org to a calendar URL and syncs it once in the
+// background. Its facts are created by the subscriber, or by the member
+// organizing the event.
+func (h *CalendarFeedHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	var req CreateCalendarFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Scheme == "" {
+		http.Error(w, "A calendar url is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.calendars.CheckURL(req.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = u.Host + u.Path
+	}
+
+	feed, err := h.db.CreateCalendarFeed(r.Context(), database.CreateCalendarFeedParams{
+		OrgID:     uuid.MustParse(claims.OrgID),
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Name:      name,
+		Url:       req.URL,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			http.Error(w, "The org is already subscribed to this calendar", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to create calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), calendarSyncTimeout)
+		defer cancel()
+		if _, err := h.calendars.SyncFeed(ctx, feed); err != nil {
+			log.Printf("Error syncing calendar feed %s: %v", feed.ID, err)
+		}
+	}()
+
+	writeJSON(w, http.StatusCreated, calendarFeedResponse(feed))
+}
+
+// Delete unsubscribes the org from a calendar, the facts already imported
+// stay. Only the subscriber and admins can.
+func (h *CalendarFeedHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid calendar feed ID", http.StatusBadRequest)
+		return
+	}
+	feed, err := h.db.GetCalendarFeed(r.Context(), database.GetCalendarFeedParams{ID: id, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Calendar feed not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load calendar feed", http.StatusInternalServerError)
+		return
+	}
+	if claims.Role != "admin" && feed.CreatorID.String() != claims.CreatorID {
+		http.Error(w, "Only the subscriber and admins can remove a calendar feed", http.StatusForbidden)
+		return
+	}
+	if _, err := h.db.DeleteCalendarFeed(r.Context(), database.DeleteCalendarFeedParams{ID: id, OrgID: orgID}); err != nil {
+		http.Error(w, "Failed to delete calendar feed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Sync imports the events of a calendar feed that started since its last
+// sync, without waiting for the task runner
+func (h *CalendarFeedHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid calendar feed ID", http.StatusBadRequest)
+		return
+	}
+	feed, err := h.db.GetCalendarFeed(r.Context(), database.GetCalendarFeedParams{ID: id, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Calendar feed not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), calendarSyncTimeout)
+	defer cancel()
+	result, err := h.calendars.SyncFeed(ctx, feed)
+	if err != nil {
+		// How it failed is kept as the last error of the feed
+		http.Error(w, "Failed to sync calendar feed", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
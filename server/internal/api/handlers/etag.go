@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Objects and type values carry a version bumped on every change. It is sent
+// as the ETag of a read, clients send it back in If-Match to only update
+// what they have seen.
+
+func setETag(w http.ResponseWriter, version int32) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(int(version))))
+}
+
+// ifMatchVersion returns the version of the If-Match header, 0 when there is
+// none or it is "*". ok is false when the header is not a version.
+func ifMatchVersion(r *http.Request) (version int32, ok bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		unquoted = value
+	}
+	n, err := strconv.ParseInt(unquoted, 10, 32)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// writeConflict answers a write made against an outdated version with the
+// current state, so the client can merge its changes into it
+func writeConflict(w http.ResponseWriter, version int32, current interface{}) {
+	if version != 0 {
+		setETag(w, version)
+	}
+	writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error":   "Changed by someone else since it was read",
+		"current": current,
+	})
+}
@@ -4,7 +4,6 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -12,6 +11,11 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/crea8r/muninn/server/internal/watch"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
@@ -30,12 +34,19 @@ type CreateExternalFactRequest struct {
 	Text        string    `json:"text"`         // Fact content
 	HappenedAt  time.Time `json:"happened_at"`  // When the fact occurred
 	Location    string    `json:"location"`     // Location of the fact
+	Kind        string          `json:"kind,omitempty"`     // Kind of fact, a note by default
+	Metadata    json.RawMessage `json:"metadata,omitempty"` // Checked against the schema of the kind
+	// Pick, create or skip mentions that are ambiguous or unknown, keyed by
+	// the alias or the mention without @
+	Mentions    map[string]service.MentionChoice `json:"mentions,omitempty"`
 }
 
 type CreateExternalFactResponse struct {
 	FactID      uuid.UUID   `json:"fact_id"`
 	ObjectIDs   []uuid.UUID `json:"object_ids"` // List of objects linked (both existing and newly created)
 	Text        string      `json:"text"`       // Final text with object references
+	Kind        string      `json:"kind"`
+	Mentions    []service.Mention `json:"mentions"` // How each alias and mention resolved
 }
 
 func (h *ExternalHandler) CreateFact(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +60,13 @@ func (h *ExternalHandler) CreateFact(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 	}
+	if req.Kind == "" {
+			req.Kind = factkind.Default
+	}
+	metadata, ok := checkFactMetadata(w, req.Kind, req.Metadata)
+	if !ok {
+			return
+	}
 
 	// Start transaction
 	tx, err := h.db.BeginTx(ctx, nil)
@@ -60,55 +78,40 @@ func (h *ExternalHandler) CreateFact(w http.ResponseWriter, r *http.Request) {
 
 	qtx := h.queries.WithTx(tx)
 
-	// Track objects we find or create
-	type objectRef struct {
-			ID      uuid.UUID
-			Name    string
-			Alias   string
+	// Resolve the aliases and the plain @mentions of the text. Mentions
+	// that match no object or several are reported back, unless the
+	// request says to pick, create or skip them.
+	resolution, err := service.ResolveMentions(ctx, qtx, uuid.MustParse(claims.OrgID), req.Text, req.Aliases, req.Mentions)
+	if err == service.ErrMentionChoice {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 	}
-	
-	var objects []objectRef
-	var objectMentions []string
-
-	// For each alias, find or create object
-	for _, alias := range req.Aliases {
-			// Try to find object by id_string or aliases
-			obj, err := qtx.FindObjectByAliasOrIDString(ctx, database.FindObjectByAliasOrIDStringParams{
-					IDString: alias,
-					OrgID:    uuid.MustParse(claims.OrgID),
+	if err != nil {
+			http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+			return
+	}
+	if unresolved := resolution.Unresolved(); len(unresolved) > 0 {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+					"error":    "Some mentions match no object or several, pick, create or skip them",
+					"mentions": unresolved,
 			})
-
-			if err != nil {
-					if err == sql.ErrNoRows {
-							// Create new object if not found
-							newObj, err := qtx.CreateObject(ctx, database.CreateObjectParams{
-									Name:        alias, // Use alias as initial name
-									Description: "",    // Empty initial description
-									IDString:   alias,
-									CreatorID:  creatorID,
-							})
-							if err != nil {
-									http.Error(w, "Failed to create new object", http.StatusInternalServerError)
-									return
-							}
-							objects = append(objects, objectRef{ID: newObj.ID, Name: alias, Alias: alias})
-					} else {
-							http.Error(w, "Database error", http.StatusInternalServerError)
-							return
-					}
-			} else {
-					objects = append(objects, objectRef{ID: obj.ID, Name: obj.Name, Alias: alias})
-			}
+			return
+	}
+	if err := resolution.CreateObjects(ctx, qtx, creatorID); err != nil {
+			http.Error(w, "Failed to create new object", http.StatusInternalServerError)
+			return
 	}
 
 	// Create object mentions for the fact text
-	for _, obj := range objects {
-			mention := fmt.Sprintf("@[%s](object:%s)", obj.Name, obj.ID)
-			objectMentions = append(objectMentions, mention)
+	var objectMentions []string
+	for _, alias := range req.Aliases {
+			if obj := resolution.Object(alias); obj != nil {
+					objectMentions = append(objectMentions, service.MentionMarker(obj.Name, obj.ID))
+			}
 	}
 
-	// Combine mentions with original text
-	fullText := strings.Join(objectMentions, " ") + " " + req.Text
+	// Combine mentions with the resolved text
+	fullText := strings.TrimSpace(strings.Join(objectMentions, " ") + " " + resolution.Text)
 
 	// Create fact
 	fact, err := qtx.CreateFact(ctx, database.CreateFactParams{
@@ -116,20 +119,34 @@ func (h *ExternalHandler) CreateFact(w http.ResponseWriter, r *http.Request) {
 			HappenedAt: sql.NullTime{Time: req.HappenedAt, Valid: !req.HappenedAt.IsZero()},
 			Location:   req.Location,
 			CreatorID:  creatorID,
+			Kind:       req.Kind,
+			Metadata:   metadata,
 	})
 	if err != nil {
 			http.Error(w, "Failed to create fact", http.StatusInternalServerError)
 			return
 	}
-	objectIDs := make([]uuid.UUID, len(objects))
-	for i, obj := range objects {
-		objectIDs[i] = obj.ID
-	}
+	objectIDs := resolution.ObjectIDs
 	qtx.AddObjectsToFact(ctx, database.AddObjectsToFactParams{
 			Column1:  objectIDs,
 			FactID:   fact.ID,
 			OrgID:    uuid.MustParse(claims.OrgID),
 	});
+	if err := history.RecordFact(ctx, qtx, creatorID, fact.ID, history.ActionCreated); err != nil {
+			http.Error(w, "Failed to create fact", http.StatusInternalServerError)
+			return
+	}
+	if err := watch.Notify(ctx, qtx, creatorID, watch.Event{
+		Kind:    watch.EventFact,
+		Action:  watch.ActionCreated,
+		ObjIDs:  objectIDs,
+		RefID:   fact.ID,
+		Summary: fact.Text,
+	}); err != nil {
+			log.Printf("Error notifying watchers of fact %s: %v", fact.ID, err)
+			http.Error(w, "Failed to create fact", http.StatusInternalServerError)
+			return
+	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
@@ -141,6 +158,8 @@ func (h *ExternalHandler) CreateFact(w http.ResponseWriter, r *http.Request) {
 			FactID:    fact.ID,
 			ObjectIDs: objectIDs,
 			Text:      fullText,
+			Kind:      fact.Kind,
+			Mentions:  resolution.Mentions,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -159,10 +178,25 @@ type UpsertObjectTypeValueResponse struct {
 	ObjectID    uuid.UUID       `json:"object_id"`
 	TypeID      uuid.UUID       `json:"type_id"`
 	TypeValues  json.RawMessage `json:"type_values"`
+	Version     int32           `json:"version"`
 	CreatedAt   time.Time       `json:"created_at"`
 	LastUpdated time.Time       `json:"last_updated"`
 }
 
+// conflictTypeValueResponse is the current state of a type value sent with
+// a 409, redacted for the viewer
+func conflictTypeValueResponse(tv database.ObjTypeValue, typeSchema schema.Schema, viewer schema.Viewer) UpsertObjectTypeValueResponse {
+	return UpsertObjectTypeValueResponse{
+		ID:          tv.ID,
+		ObjectID:    tv.ObjID,
+		TypeID:      tv.TypeID,
+		TypeValues:  typeSchema.RedactRaw(tv.TypeValues, viewer),
+		Version:     tv.Version,
+		CreatedAt:   tv.CreatedAt,
+		LastUpdated: tv.LastUpdated,
+	}
+}
+
 func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	// TODO: security check in the future make sure the user has access to the object
@@ -170,6 +204,14 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 	orgID := uuid.MustParse(claims.OrgID)
 	creatorID := uuid.MustParse(claims.CreatorID)
 
+	// With If-Match the values are only written over the version the
+	// client has seen
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
 	// Parse request body
 	var req UpsertObjectTypeValueRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -177,6 +219,34 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	objectTypeID, err := uuid.Parse(req.ObjectTypeID)
+	if err != nil {
+		http.Error(w, "Invalid object_type_id format", http.StatusBadRequest)
+		return
+	}
+
+	// Validate JSON format of type_values
+	if !json.Valid(req.TypeValues) {
+		http.Error(w, "Invalid type_values JSON format", http.StatusBadRequest)
+		return
+	}
+
+	typeSchema, err := schema.Load(ctx, h.queries, objectTypeID)
+	if err != nil {
+		http.Error(w, "Invalid object_type_id", http.StatusBadRequest)
+		return
+	}
+	viewer := viewerFromClaims(claims)
+
+	// The object, its type value and their history are saved together
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.queries.WithTx(tx)
+
 	// Validate and parse UUIDs
 	objectID, err := uuid.Parse(req.ObjectID)
 	if err != nil { // no objectId, now check for aliases
@@ -185,7 +255,7 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 			http.Error(w, "Invalid object_id format", http.StatusBadRequest)
 			return
 		}
-		r, err := h.queries.SyncObjectAliases(ctx, database.SyncObjectAliasesParams{
+		r, err := qtx.SyncObjectAliases(ctx, database.SyncObjectAliasesParams{
 			Column1: aliases,
 			OrgID:   orgID,
 		});
@@ -194,8 +264,13 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 			http.Error(w, "Failed to sync object aliases", http.StatusInternalServerError)
 			return
 		}else if err == sql.ErrNoRows {
+			// Check type_values against the object type schema before creating
+			// an object for them
+			if _, ok := prepareTypeValues(ctx, w, qtx, typeSchema, orgID, objectTypeID, req.TypeValues, nil, viewer); !ok {
+				return
+			}
 			idString := aliases[0]
-			newObj, errCreateObj := h.queries.CreateObject(ctx, database.CreateObjectParams{
+			newObj, errCreateObj := qtx.CreateObject(ctx, database.CreateObjectParams{
 				Name:        idString, // Use alias as initial name
 				Description: idString,
 				IDString:   idString,
@@ -208,40 +283,85 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 			objectID = newObj.ID
 			aliasesWithoutId := aliases[1:]
 			if len(aliases) > 1 {
-				h.queries.UpdateObject(ctx, database.UpdateObjectParams{
+				updated, errUpdate := qtx.UpdateObject(ctx, database.UpdateObjectParams{
 					ID: objectID,
 					Name: idString,
 					Description: strings.Join(aliasesWithoutId, ", "),
 					IDString: idString,
 					Aliases: pq.StringArray(aliasesWithoutId),
 				})
+				if errUpdate == nil {
+					newObj = updated
+				}
+			}
+			if err := history.Record(ctx, qtx, creatorID, history.Entry{
+				ObjID:   objectID,
+				Action:  history.ActionCreated,
+				Changes: history.DiffObject(database.Obj{}, newObj),
+			}); err != nil {
+				log.Printf("Error recording history of object %s: %v", objectID, err)
+				http.Error(w, "Failed to create object", http.StatusInternalServerError)
+				return
 			}
 		} else if err == nil {
 			objectID = r.ID
+			if err := history.RecordAliases(ctx, qtx, creatorID, r); err != nil {
+				log.Printf("Error recording history of object %s: %v", objectID, err)
+				http.Error(w, "Failed to sync object aliases", http.StatusInternalServerError)
+				return
+			}
 		}
 	}
 
-	objectTypeID, err := uuid.Parse(req.ObjectTypeID)
-	if err != nil {
-		http.Error(w, "Invalid object_type_id format", http.StatusBadRequest)
+	var stored json.RawMessage
+	existing, err := qtx.GetObjectTypeValue(ctx, database.GetObjectTypeValueParams{
+		ObjID:  objectID,
+		TypeID: objectTypeID,
+	})
+	if err == nil {
+		stored = existing.TypeValues
+	} else if err != sql.ErrNoRows {
+		http.Error(w, "Failed to load object type value", http.StatusInternalServerError)
 		return
 	}
-
-	// Validate JSON format of type_values
-	if !json.Valid(req.TypeValues) {
-		http.Error(w, "Invalid type_values JSON format", http.StatusBadRequest)
+	if version != 0 && (stored == nil || existing.Version != version) {
+		if stored == nil {
+			writeConflict(w, 0, nil)
+			return
+		}
+		writeConflict(w, existing.Version, conflictTypeValueResponse(existing, typeSchema, viewer))
+		return
+	}
+	typeValues, ok := prepareTypeValues(ctx, w, qtx, typeSchema, orgID, objectTypeID, req.TypeValues, stored, viewer, objectID)
+	if !ok {
 		return
 	}
 
 	// Perform upsert
-	result, err := h.queries.UpsertObjectTypeValue(ctx, database.UpsertObjectTypeValueParams{
+	result, err := qtx.UpsertObjectTypeValue(ctx, database.UpsertObjectTypeValueParams{
 		ObjID:      objectID,
 		TypeID:     objectTypeID,
-		TypeValues: req.TypeValues,
+		TypeValues: typeValues,
+		Column4:    existing.Version,
 	})
+	if err == sql.ErrNoRows {
+		// Changed since it was read above
+		if current, err := qtx.GetObjectTypeValue(ctx, database.GetObjectTypeValueParams{
+			ObjID:  objectID,
+			TypeID: objectTypeID,
+		}); err == nil {
+			writeConflict(w, current.Version, conflictTypeValueResponse(current, typeSchema, viewer))
+			return
+		}
+		writeConflict(w, 0, nil)
+		return
+	}
 	if err != nil {
 			// Log the error for debugging
 		log.Printf("Error upserting object type value: %v", err)
+		if writeValidationError(w, err) {
+			return
+		}
 		
 		if strings.Contains(err.Error(), "foreign key constraint") {
 			http.Error(w, "Invalid object_id or object_type_id", http.StatusBadRequest)
@@ -251,18 +371,43 @@ func (h *ExternalHandler) UpsertObjectTypeValue(w http.ResponseWriter, r *http.R
 		http.Error(w, "Failed to upsert object type value", http.StatusInternalServerError)
 		return
 	}
+	action := history.ActionTypeValueUpdated
+	if stored == nil {
+		action = history.ActionTypeValueAdded
+	}
+	if err := history.Record(ctx, qtx, creatorID, history.Entry{
+		ObjID:   objectID,
+		Action:  action,
+		TypeID:  objectTypeID,
+		Changes: history.DiffValuesRaw(stored, result.TypeValues),
+	}); err != nil {
+		log.Printf("Error recording history of object %s: %v", objectID, err)
+		http.Error(w, "Failed to upsert object type value", http.StatusInternalServerError)
+		return
+	}
+	// Unique fields are checked at commit
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error upserting object type value: %v", err)
+		if writeValidationError(w, err) {
+			return
+		}
+		http.Error(w, "Failed to upsert object type value", http.StatusInternalServerError)
+		return
+	}
 
 	// Prepare response
 	response := UpsertObjectTypeValueResponse{
 		ID:          result.ID,
 		ObjectID:    result.ObjID,
 		TypeID:      result.TypeID,
-		TypeValues:  result.TypeValues,
+		TypeValues:  typeSchema.RedactRaw(result.TypeValues, viewer),
+		Version:     result.Version,
 		CreatedAt:   result.CreatedAt,
 		LastUpdated: result.LastUpdated,
 	}
 
 	// Send response
+	setETag(w, result.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
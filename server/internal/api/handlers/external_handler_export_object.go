@@ -57,6 +57,7 @@ func (h *ExternalHandler) ListObjectsWithNormalizedData(w http.ResponseWriter, r
 			return time.Time{}
 		}(),
 		Column3:    objectIDs,
+		Column4:    claims.Role,
 	})
 	if err != nil {
 		log.Printf("Error fetching normalized objects: %v", err)
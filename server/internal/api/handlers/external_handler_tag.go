@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
@@ -47,6 +49,16 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Start transaction, the object and its tags are saved with their history
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := h.queries.WithTx(tx)
+
 	// Validate object ID
 	objectID, err := uuid.Parse(req.ObjectID)
 	if err != nil { // no objectId, now check for aliases
@@ -55,7 +67,7 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Invalid object_id format", http.StatusBadRequest)
 			return
 		}
-		r, err := h.queries.SyncObjectAliases(ctx, database.SyncObjectAliasesParams{
+		r, err := qtx.SyncObjectAliases(ctx, database.SyncObjectAliasesParams{
 			Column1: aliases,
 			OrgID:   orgID,
 		});
@@ -65,7 +77,7 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 			return
 		}else if err == sql.ErrNoRows {
 			idString := aliases[0]
-			newObj, errCreateObj := h.queries.CreateObject(ctx, database.CreateObjectParams{
+			newObj, errCreateObj := qtx.CreateObject(ctx, database.CreateObjectParams{
 				Name:        idString, // Use alias as initial name
 				Description: idString,
 				IDString:   idString,
@@ -77,29 +89,36 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 			}
 			objectID = newObj.ID
 			if len(aliases) > 1 {
-				h.queries.UpdateObject(ctx, database.UpdateObjectParams{
+				updated, errUpdate := qtx.UpdateObject(ctx, database.UpdateObjectParams{
 					ID: objectID,
 					Name: idString,
 					Description: strings.Join(aliases, ", "),
 					IDString: idString,
 					Aliases: pq.StringArray(aliases),
 				})
+				if errUpdate == nil {
+					newObj = updated
+				}
+			}
+			if err := history.Record(ctx, qtx, creatorID, history.Entry{
+				ObjID:   objectID,
+				Action:  history.ActionCreated,
+				Changes: history.DiffObject(database.Obj{}, newObj),
+			}); err != nil {
+				log.Printf("Error recording history of object %s: %v", objectID, err)
+				http.Error(w, "Failed to create object", http.StatusInternalServerError)
+				return
 			}
 		} else if err == nil {
 			objectID = r.ID
+			if err := history.RecordAliases(ctx, qtx, creatorID, r); err != nil {
+				log.Printf("Error recording history of object %s: %v", objectID, err)
+				http.Error(w, "Failed to sync object aliases", http.StatusInternalServerError)
+				return
+			}
 		}
 	}
 
-	// Start transaction
-	tx, err := h.db.BeginTx(ctx, nil)
-	if err != nil {
-		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
-		return
-	}
-	defer tx.Rollback()
-
-	qtx := h.queries.WithTx(tx)
-
 	// Process each tag
 	var processedTags []TagDetail
 	var colorPairs = []string{
@@ -157,7 +176,7 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Try to link tag to object
-		err = qtx.AddTagToObject(ctx, database.AddTagToObjectParams{
+		linked, err := qtx.AddTagToObject(ctx, database.AddTagToObjectParams{
 			ObjID:  objectID,
 			TagID:  tagID,
 			OrgID:  orgID,
@@ -168,6 +187,12 @@ func (h *ExternalHandler) TagObject(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, "Failed to link tag to object", http.StatusInternalServerError)
 				return
 			}
+		} else if linked > 0 {
+			if err := history.RecordTag(ctx, qtx, history.SourceFrom(ctx), creatorID, objectID, tagID, history.ActionTagAdded); err != nil {
+				log.Printf("Error recording history of object %s: %v", objectID, err)
+				http.Error(w, "Failed to link tag to object", http.StatusInternalServerError)
+				return
+			}
 		}
 
 		// Add to processed tags
@@ -3,6 +3,7 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,6 +12,11 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/crea8r/muninn/server/internal/watch"
 	"github.com/crea8r/muninn/server/pkg/ctype"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -19,7 +25,8 @@ import (
 var objectIDRegex = regexp.MustCompile(`\((?:object:)?([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\)`)
 
 type FactHandler struct {
-	db *database.Queries
+	db    *database.Queries
+	sqlDB *sql.DB
 }
 
 func extractObjectIDsFromText(text string) []uuid.UUID {
@@ -40,8 +47,8 @@ func extractObjectIDsFromText(text string) []uuid.UUID {
 	return ids
 }
 
-func NewFactHandler(db *database.Queries) *FactHandler {
-	return &FactHandler{db: db}
+func NewFactHandler(db *database.Queries, sqlDB *sql.DB) *FactHandler {
+	return &FactHandler{db: db, sqlDB: sqlDB}
 }
 
 type FactToCreate struct {
@@ -49,6 +56,65 @@ type FactToCreate struct {
 	HappenedAt ctype.NullTime `json:"happenedAt"`
 	Location   string         `json:"location"`
 	ObjectIDs  []string       `json:"objectIds"`
+	// Kind defaults to a note, Metadata is checked against its schema
+	Kind     string          `json:"kind"`
+	Metadata json.RawMessage `json:"metadata"`
+	// Mentions settles plain @mentions of the text, keyed by the mention
+	Mentions map[string]service.MentionChoice `json:"mentions"`
+}
+
+// factResponse is a created or updated fact with the plain @mentions of its
+// text left as written because they are ambiguous or unknown
+type factResponse struct {
+	database.Fact
+	UnresolvedMentions []service.Mention `json:"unresolvedMentions"`
+}
+
+// resolveMentions rewrites the plain @mentions of text that resolve, or
+// that the caller picked or chose to create, to canonical markers. The
+// others are left as written. Objects are created with q, the transaction
+// of the fact, so they go away if the fact cannot be saved.
+func (h *FactHandler) resolveMentions(w http.ResponseWriter, r *http.Request, q *database.Queries, text string, choices map[string]service.MentionChoice) (*service.MentionResolution, bool) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	resolution, err := service.ResolveMentions(r.Context(), q, uuid.MustParse(claims.OrgID), text, nil, choices)
+	if err == nil {
+		err = resolution.CreateObjects(r.Context(), q, uuid.MustParse(claims.CreatorID))
+	}
+	if err == service.ErrMentionChoice {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+		return nil, false
+	}
+	return resolution, true
+}
+
+// ResolveMentions previews how the plain @mentions of a text resolve, so the
+// caller can pick, create or skip the ambiguous and unknown ones before
+// saving the fact
+func (h *FactHandler) ResolveMentions(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Text     string                           `json:"text"`
+		Mentions map[string]service.MentionChoice `json:"mentions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	resolution, err := service.ResolveMentions(r.Context(), h.db, uuid.MustParse(claims.OrgID), input.Text, nil, input.Mentions)
+	if err == service.ErrMentionChoice {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, resolution)
 }
 
 func (h *FactHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -61,7 +127,29 @@ func (h *FactHandler) Create(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	creatorID := claims.CreatorID
 
-	fact, err := h.db.CreateFact(r.Context(), database.CreateFactParams{
+	if input.Kind == "" {
+		input.Kind = factkind.Default
+	}
+	metadata, ok := checkFactMetadata(w, input.Kind, input.Metadata)
+	if !ok {
+		return
+	}
+
+	tx, err := h.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.db.WithTx(tx)
+
+	resolution, ok := h.resolveMentions(w, r, qtx, input.Text, input.Mentions)
+	if !ok {
+		return
+	}
+	input.Text = resolution.Text
+
+	fact, err := qtx.CreateFact(r.Context(), database.CreateFactParams{
 		Text: input.Text,
 		HappenedAt: sql.NullTime{
 			Time:  input.HappenedAt.Time,
@@ -69,6 +157,8 @@ func (h *FactHandler) Create(w http.ResponseWriter, r *http.Request) {
 		},
 		Location:  input.Location,
 		CreatorID: uuid.MustParse(creatorID),
+		Kind:      input.Kind,
+		Metadata:  metadata,
 	})
 
 	if err != nil {
@@ -89,14 +179,13 @@ func (h *FactHandler) Create(w http.ResponseWriter, r *http.Request) {
 		uniqueIDs[id] = true
 	}
 
-	if len(uniqueIDs) > 0 {
-		objectIDs := make([]uuid.UUID, 0, len(uniqueIDs))
-		for id := range uniqueIDs {
-			objectIDs = append(objectIDs, id)
-		}
-
+	objectIDs := make([]uuid.UUID, 0, len(uniqueIDs))
+	for id := range uniqueIDs {
+		objectIDs = append(objectIDs, id)
+	}
+	if len(objectIDs) > 0 {
 		orgID := claims.OrgID
-		err = h.db.AddObjectsToFact(r.Context(), database.AddObjectsToFactParams{
+		err = qtx.AddObjectsToFact(r.Context(), database.AddObjectsToFactParams{
 			Column1: objectIDs,
 			FactID:  fact.ID,
 			OrgID:   uuid.MustParse(orgID),
@@ -107,8 +196,30 @@ func (h *FactHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if err := history.RecordFact(r.Context(), qtx, fact.CreatorID, fact.ID, history.ActionCreated); err != nil {
+		log.Printf("Error creating fact: %v", err)
+		http.Error(w, "Failed to record fact version", http.StatusInternalServerError)
+		return
+	}
+
+	if err := watch.Notify(r.Context(), qtx, fact.CreatorID, watch.Event{
+		Kind:    watch.EventFact,
+		Action:  watch.ActionCreated,
+		ObjIDs:  objectIDs,
+		RefID:   fact.ID,
+		Summary: fact.Text,
+	}); err != nil {
+		log.Printf("Error creating fact: %v", err)
+		http.Error(w, "Failed to notify watchers", http.StatusInternalServerError)
+		return
+	}
 
-	json.NewEncoder(w).Encode(fact)
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(factResponse{Fact: fact, UnresolvedMentions: resolution.Unresolved()})
 }
 
 func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -120,6 +231,11 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Location          string         `json:"location"`
 		ToAddObjectIDs    []string       `json:"toAddObjectIDs"`
 		ToRemoveObjectIDs []string       `json:"toRemoveObjectIDs"`
+		// Kind and Metadata are kept as they are when both are left out
+		Kind     string          `json:"kind"`
+		Metadata json.RawMessage `json:"metadata"`
+		// Mentions settles plain @mentions of the text, keyed by the mention
+		Mentions map[string]service.MentionChoice `json:"mentions"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -127,7 +243,45 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fact, err := h.db.UpdateFact(r.Context(), database.UpdateFactParams{
+	// The fact is locked until its new version is recorded
+	tx, err := h.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.db.WithTx(tx)
+
+	if err := qtx.LockFacts(r.Context(), []uuid.UUID{uuid.MustParse(factID)}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	current, err := qtx.GetFactByID(r.Context(), uuid.MustParse(factID))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if input.Kind == "" {
+		input.Kind = current.Kind
+		if input.Metadata == nil {
+			input.Metadata = current.Metadata
+		}
+	}
+	metadata, ok := checkFactMetadata(w, input.Kind, input.Metadata)
+	if !ok {
+		return
+	}
+	resolution, ok := h.resolveMentions(w, r, qtx, input.Text, input.Mentions)
+	if !ok {
+		return
+	}
+	input.Text = resolution.Text
+
+	fact, err := qtx.UpdateFact(r.Context(), database.UpdateFactParams{
 		ID:   uuid.MustParse(factID),
 		Text: input.Text,
 		HappenedAt: sql.NullTime{
@@ -135,6 +289,8 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 			Valid: input.HappenedAt.Valid,
 		},
 		Location: input.Location,
+		Kind:     input.Kind,
+		Metadata: metadata,
 	})
 
 	if err != nil {
@@ -149,7 +305,7 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 		for i, id := range input.ToRemoveObjectIDs {
 			removingObjectIDs[i] = uuid.MustParse(id)
 		}
-		err = h.db.RemoveObjectsFromFact(r.Context(), database.RemoveObjectsFromFactParams{
+		err = qtx.RemoveObjectsFromFact(r.Context(), database.RemoveObjectsFromFactParams{
 			FactID:  fact.ID,
 			Column2: removingObjectIDs,
 		})
@@ -178,7 +334,7 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 		for id := range uniqueAddingIDs {
 			addingObjectIDs = append(addingObjectIDs, id)
 		}
-		err = h.db.AddObjectsToFact(r.Context(), database.AddObjectsToFactParams{
+		err = qtx.AddObjectsToFact(r.Context(), database.AddObjectsToFactParams{
 			Column1: addingObjectIDs,
 			FactID:  fact.ID,
 			OrgID:   uuid.MustParse(orgID),
@@ -189,14 +345,33 @@ func (h *FactHandler) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if err := history.RecordFact(r.Context(), qtx, uuid.MustParse(claims.CreatorID), fact.ID, history.ActionUpdated); err != nil {
+		log.Printf("Error updating fact: %v", err)
+		http.Error(w, "Failed to record fact version", http.StatusInternalServerError)
+		return
+	}
 
-	json.NewEncoder(w).Encode(fact)
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(factResponse{Fact: fact, UnresolvedMentions: resolution.Unresolved()})
+}
+
+// Kinds lists the kinds of facts with the schema of their metadata
+func (h *FactHandler) Kinds(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, factkind.All())
 }
 
 func (h *FactHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	factID := chi.URLParam(r, "id")
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 
-	err := h.db.DeleteFact(r.Context(), uuid.MustParse(factID))
+	err := h.db.DeleteFact(r.Context(), database.DeleteFactParams{
+		ID:        uuid.MustParse(factID),
+		DeletedBy: uuid.NullUUID{UUID: uuid.MustParse(claims.CreatorID), Valid: true},
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -212,6 +387,12 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
 	search := r.URL.Query().Get("search")
+	// ?kinds=call,meeting keeps the facts of these kinds
+	kinds, err := parseFactKinds(r.URL.Query().Get("kinds"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	if page < 1 {
 		page = 1
@@ -230,6 +411,7 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 		Column2: search,
 		Limit:   int32(pageSize),
 		Offset:  int32((page - 1) * pageSize),
+		Column5: kinds,
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -239,6 +421,7 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 	totalCount, err := h.db.CountFactsByOrgID(r.Context(), database.CountFactsByOrgIDParams{
 		OrgID:   uuid.MustParse(orgID),
 		Column2: search,
+		Column3: kinds,
 	})
 
 	if err != nil {
@@ -253,10 +436,35 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 		CreatorID      uuid.UUID             `json:"creatorId"`
 		CreatorName    string                `json:"creatorName"`
 		CreatedAt      time.Time             `json:"createdAt"`
+		Kind           string                `json:"kind"`
+		Metadata       json.RawMessage       `json:"metadata"`
 		RelatedObjects []RelatedObjectStruct `json:"relatedObjects"`
+		Attachments    []models.Attachment   `json:"attachments"`
+		Comments       []models.FactComment  `json:"comments"`
+		Reactions      []models.FactReaction `json:"reactions"`
 	}
 	returningFacts := make([]FactType, len(facts))
 
+	factIDs := make([]uuid.UUID, len(facts))
+	for i, fact := range facts {
+		factIDs[i] = fact.ID
+	}
+	attachments, err := models.FactAttachments(r.Context(), h.db, uuid.MustParse(orgID), factIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	comments, err := models.FactComments(r.Context(), h.db, uuid.MustParse(orgID), factIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reactions, err := models.FactReactions(r.Context(), h.db, uuid.MustParse(orgID), factIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	for i, fact := range facts {
 		relatedObjects := make([]RelatedObjectStruct, 0)
 		relatedObjectsBytes, _ := fact.RelatedObjects.([]byte)
@@ -271,7 +479,21 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 			CreatorID:      fact.CreatorID,
 			CreatorName:    fact.CreatorName,
 			CreatedAt:      fact.CreatedAt,
+			Kind:           fact.Kind,
+			Metadata:       fact.Metadata,
 			RelatedObjects: relatedObjects,
+			Attachments:    attachments[fact.ID],
+			Comments:       comments[fact.ID],
+			Reactions:      reactions[fact.ID],
+		}
+		if returningFacts[i].Attachments == nil {
+			returningFacts[i].Attachments = []models.Attachment{}
+		}
+		if returningFacts[i].Comments == nil {
+			returningFacts[i].Comments = []models.FactComment{}
+		}
+		if returningFacts[i].Reactions == nil {
+			returningFacts[i].Reactions = []models.FactReaction{}
 		}
 	}
 
@@ -289,3 +511,163 @@ func (h *FactHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// FactVersion is a version of a fact with what changed since the previous one
+type FactVersion struct {
+	Version      int32            `json:"version"`
+	Action       string           `json:"action"`
+	Source       string           `json:"source"`
+	EditorID     uuid.UUID        `json:"editorId"`
+	EditorName   string           `json:"editorName"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	Text         string           `json:"text"`
+	HappenedAt   ctype.NullTime   `json:"happenedAt"`
+	Location     string           `json:"location"`
+	Kind         string           `json:"kind"`
+	Metadata     json.RawMessage  `json:"metadata"`
+	ObjectIDs    []uuid.UUID      `json:"objectIds"`
+	RestoredFrom *int32           `json:"restoredFrom,omitempty"`
+	Changes      []history.Change `json:"changes"`
+}
+
+// Versions lists every version of a fact, latest first, each with its diff
+// from the one before
+func (h *FactHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	factID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid fact ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	rows, err := h.db.ListFactVersions(r.Context(), database.ListFactVersionsParams{
+		FactID: factID,
+		OrgID:  uuid.MustParse(claims.OrgID),
+	})
+	if err != nil {
+		http.Error(w, "Failed to load fact versions", http.StatusInternalServerError)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return
+	}
+
+	snapshot := func(row database.ListFactVersionsRow) *database.FactVersion {
+		return &database.FactVersion{
+			Text:       row.Text,
+			HappenedAt: row.HappenedAt,
+			Location:   row.Location,
+			Kind:       row.Kind,
+			Metadata:   row.Metadata,
+			ObjIds:     row.ObjIds,
+		}
+	}
+	versions := make([]FactVersion, len(rows))
+	for i, row := range rows {
+		var previous *database.FactVersion
+		if i+1 < len(rows) {
+			previous = snapshot(rows[i+1])
+		}
+		versions[i] = FactVersion{
+			Version:    row.Version,
+			Action:     row.Action,
+			Source:     row.Source,
+			EditorID:   row.EditorID,
+			EditorName: row.EditorName,
+			CreatedAt:  row.CreatedAt,
+			Text:       row.Text,
+			HappenedAt: ctype.NullTime{NullTime: row.HappenedAt},
+			Location:   row.Location,
+			Kind:       row.Kind,
+			Metadata:   row.Metadata,
+			ObjectIDs:  row.ObjIds,
+			Changes:    history.DiffFact(previous, *snapshot(row)),
+		}
+		if row.RestoredFrom.Valid {
+			versions[i].RestoredFrom = &row.RestoredFrom.Int32
+		}
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+// RestoreVersion puts a fact back as it was in one of its versions, text,
+// date, location, kind, metadata and linked objects, as a new version. Only admins can.
+func (h *FactHandler) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	factID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid fact ID", http.StatusBadRequest)
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	if claims.Role != "admin" {
+		http.Error(w, "Only admin can restore a fact version", http.StatusForbidden)
+		return
+	}
+	ctx := r.Context()
+
+	tx, err := h.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.db.WithTx(tx)
+
+	if err := qtx.LockFacts(ctx, []uuid.UUID{factID}); err != nil {
+		http.Error(w, "Failed to lock fact", http.StatusInternalServerError)
+		return
+	}
+	target, err := qtx.GetFactVersion(ctx, database.GetFactVersionParams{
+		FactID:  factID,
+		Version: int32(version),
+		OrgID:   uuid.MustParse(claims.OrgID),
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Fact version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load fact version", http.StatusInternalServerError)
+		return
+	}
+	fact, err := qtx.UpdateFact(ctx, database.UpdateFactParams{
+		ID:         factID,
+		Text:       target.Text,
+		HappenedAt: target.HappenedAt,
+		Location:   target.Location,
+		Kind:       target.Kind,
+		Metadata:   target.Metadata,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Fact is deleted, restore it from the trash first", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to restore fact", http.StatusInternalServerError)
+		return
+	}
+	if err := qtx.SetFactObjects(ctx, database.SetFactObjectsParams{
+		FactID:  factID,
+		Column2: target.ObjIds,
+	}); err != nil {
+		http.Error(w, "Failed to restore fact objects", http.StatusInternalServerError)
+		return
+	}
+	if err := history.RecordFactRestored(ctx, qtx, uuid.MustParse(claims.CreatorID), factID, target.Version); err != nil {
+		log.Printf("Error restoring fact: %v", err)
+		http.Error(w, "Failed to record fact version", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, fact)
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxEmojiLength bounds a reaction, fact_reaction.emoji is a VARCHAR(32)
+const maxEmojiLength = 32
+
+// FactDiscussion is the comment threads of a fact and the reactions to it
+type FactDiscussion struct {
+	Comments  []models.FactComment  `json:"comments"`
+	Reactions []models.FactReaction `json:"reactions"`
+}
+
+// orgFact parses the fact of the URL and checks it belongs to the org of
+// the request. It writes the error and returns false when it does not.
+func (h *FactHandler) orgFact(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	factID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid fact ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	exists, err := h.db.IsOrgFact(r.Context(), database.IsOrgFactParams{
+		ID:    factID,
+		OrgID: uuid.MustParse(claims.OrgID),
+	})
+	if err != nil {
+		http.Error(w, "Failed to load fact", http.StatusInternalServerError)
+		return uuid.Nil, false
+	}
+	if !exists {
+		http.Error(w, "Fact not found", http.StatusNotFound)
+		return uuid.Nil, false
+	}
+	return factID, true
+}
+
+// Discussion returns the comment threads of a fact and its reactions
+func (h *FactHandler) Discussion(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	comments, err := models.FactComments(r.Context(), h.db, orgID, []uuid.UUID{factID})
+	if err != nil {
+		http.Error(w, "Failed to load comments", http.StatusInternalServerError)
+		return
+	}
+	reactions, err := models.FactReactions(r.Context(), h.db, orgID, []uuid.UUID{factID})
+	if err != nil {
+		http.Error(w, "Failed to load reactions", http.StatusInternalServerError)
+		return
+	}
+	discussion := FactDiscussion{Comments: comments[factID], Reactions: reactions[factID]}
+	if discussion.Comments == nil {
+		discussion.Comments = []models.FactComment{}
+	}
+	if discussion.Reactions == nil {
+		discussion.Reactions = []models.FactReaction{}
+	}
+	writeJSON(w, http.StatusOK, discussion)
+}
+
+// CreateComment comments on a fact, or replies to a comment of it with a
+// parentId. The members of the org @mentioned are notified in their feed.
+func (h *FactHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Text     string     `json:"text"`
+		ParentID *uuid.UUID `json:"parentId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		http.Error(w, "Comment text is required", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	text, mentioned, err := service.ResolveCreatorMentions(r.Context(), h.db, uuid.MustParse(claims.OrgID), input.Text)
+	if err != nil {
+		http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+		return
+	}
+	params := database.CreateFactCommentParams{
+		FactID:    factID,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Text:      text,
+	}
+	if input.ParentID != nil {
+		params.ParentID = uuid.NullUUID{UUID: *input.ParentID, Valid: true}
+	}
+	comment, err := h.db.CreateFactComment(r.Context(), params)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Parent comment not found on this fact", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+		return
+	}
+	service.NotifyCommentMentions(r.Context(), h.db, comment, mentioned)
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment changes the text of a comment, only its author can. Members
+// newly mentioned are notified, those already mentioned are not again.
+func (h *FactHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentId"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		http.Error(w, "Comment text is required", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	current, err := h.db.GetFactComment(r.Context(), database.GetFactCommentParams{
+		ID:     commentID,
+		FactID: factID,
+		OrgID:  orgID,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load comment", http.StatusInternalServerError)
+		return
+	}
+	if current.CreatorID != creatorID {
+		http.Error(w, "Only the author can edit a comment", http.StatusForbidden)
+		return
+	}
+
+	text, mentioned, err := service.ResolveCreatorMentions(r.Context(), h.db, orgID, input.Text)
+	if err != nil {
+		http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+		return
+	}
+	// Members already mentioned were notified when they were
+	_, before, err := service.ResolveCreatorMentions(r.Context(), h.db, orgID, current.Text)
+	if err != nil {
+		http.Error(w, "Failed to resolve mentions", http.StatusInternalServerError)
+		return
+	}
+	mentioned = newMentions(mentioned, before)
+
+	comment, err := h.db.UpdateFactComment(r.Context(), database.UpdateFactCommentParams{
+		ID:        commentID,
+		FactID:    factID,
+		CreatorID: creatorID,
+		Text:      text,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+	service.NotifyCommentMentions(r.Context(), h.db, comment, mentioned)
+
+	writeJSON(w, http.StatusOK, comment)
+}
+
+// newMentions returns the creators of after not in before
+func newMentions(after, before []uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	for _, id := range before {
+		seen[id] = true
+	}
+	var result []uuid.UUID
+	for _, id := range after {
+		if !seen[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// DeleteComment deletes a comment and the replies under it. Authors delete
+// their comments, admins any.
+func (h *FactHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentId"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	n, err := h.db.DeleteFactComment(r.Context(), database.DeleteFactCommentParams{
+		ID:        commentID,
+		FactID:    factID,
+		OrgID:     uuid.MustParse(claims.OrgID),
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Column5:   claims.Role == "admin",
+	})
+	if err != nil {
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Comment not found or not yours", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reactionEmoji reads and checks the emoji of a reaction request
+func reactionEmoji(w http.ResponseWriter, emoji string) (string, bool) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength || strings.ContainsAny(emoji, " \t\n") {
+		http.Error(w, "Invalid emoji", http.StatusBadRequest)
+		return "", false
+	}
+	return emoji, true
+}
+
+// AddReaction reacts to a fact with an emoji, reacting twice with the same
+// one changes nothing
+func (h *FactHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Emoji string `json:"emoji"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	emoji, ok := reactionEmoji(w, input.Emoji)
+	if !ok {
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	if err := h.db.AddFactReaction(r.Context(), database.AddFactReactionParams{
+		FactID:    factID,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Emoji:     emoji,
+	}); err != nil {
+		http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveReaction takes back a reaction of the creator, given as ?emoji=
+func (h *FactHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	factID, ok := h.orgFact(w, r)
+	if !ok {
+		return
+	}
+	emoji, ok := reactionEmoji(w, r.URL.Query().Get("emoji"))
+	if !ok {
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	n, err := h.db.RemoveFactReaction(r.Context(), database.RemoveFactReactionParams{
+		FactID:    factID,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Emoji:     emoji,
+	})
+	if err != nil {
+		http.Error(w, "Failed to remove reaction", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Reaction not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
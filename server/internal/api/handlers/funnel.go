@@ -153,7 +153,11 @@ func (h *FunnelHandler) DeleteFunnel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	err := h.db.DeleteFunnel(ctx, uuid.MustParse(funnelID))
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	err := h.db.DeleteFunnel(ctx, database.DeleteFunnelParams{
+		ID:        uuid.MustParse(funnelID),
+		DeletedBy: uuid.NullUUID{UUID: uuid.MustParse(claims.CreatorID), Valid: true},
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Funnel not found or cannot be deleted due to existing references", http.StatusNotFound)
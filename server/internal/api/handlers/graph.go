@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type GraphHandler struct {
+	graph   *service.GraphService
+	queries *database.Queries
+}
+
+func NewGraphHandler(graph *service.GraphService, queries *database.Queries) *GraphHandler {
+	return &GraphHandler{graph: graph, queries: queries}
+}
+
+// Network returns the objects most often mentioned together with an object,
+// recent facts weighing more, with the shared facts as evidence. With
+// ?format=graphml or ?format=dot the connections are exported instead.
+func (h *GraphHandler) Network(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != service.FormatGraphML && format != service.FormatDOT {
+		http.Error(w, service.ErrGraphFormat.Error(), http.StatusBadRequest)
+		return
+	}
+
+	connections, err := h.graph.Connections(r.Context(), orgID, id, int32(limit))
+	if err == service.ErrGraphNode {
+		http.Error(w, "Object not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load object network", http.StatusInternalServerError)
+		return
+	}
+	if format == "" || format == "json" {
+		writeJSON(w, http.StatusOK, connections)
+		return
+	}
+
+	center := service.NetworkNode{ID: id, Kind: service.NodeObject}
+	if names, err := h.queries.ListObjectNames(r.Context(), []uuid.UUID{id}); err == nil && len(names) > 0 {
+		center.Name = names[0].Name
+	}
+	writeGraph(w, format, "network-"+id.String(), service.ConnectionNetwork(center, connections))
+}
+
+// Path finds the warmest chain of introductions to an object. It starts from
+// the current creator unless ?from= names another team member or an object.
+func (h *GraphHandler) Path(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	toID, err := uuid.Parse(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to ID", http.StatusBadRequest)
+		return
+	}
+	fromID := uuid.MustParse(claims.CreatorID)
+	if from := r.URL.Query().Get("from"); from != "" {
+		fromID, err = uuid.Parse(from)
+		if err != nil {
+			http.Error(w, "Invalid from ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	path, err := h.graph.Path(r.Context(), orgID, fromID, toID)
+	switch err {
+	case nil:
+		writeJSON(w, http.StatusOK, path)
+	case service.ErrGraphNode:
+		http.Error(w, "No facts connect the requested nodes", http.StatusNotFound)
+	case service.ErrNoPath:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "Failed to find path", http.StatusInternalServerError)
+	}
+}
+
+// Export downloads the co-mention graph of the org as GraphML or DOT
+func (h *GraphHandler) Export(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.FormatGraphML
+	}
+	if format != service.FormatGraphML && format != service.FormatDOT {
+		http.Error(w, service.ErrGraphFormat.Error(), http.StatusBadRequest)
+		return
+	}
+	network, err := h.graph.Graph(r.Context(), orgID)
+	if err != nil {
+		http.Error(w, "Failed to build graph", http.StatusInternalServerError)
+		return
+	}
+	writeGraph(w, format, "graph", network)
+}
+
+// writeGraph sends a network as a GraphML or DOT attachment
+func writeGraph(w http.ResponseWriter, format, name string, network *service.Network) {
+	var buf bytes.Buffer
+	if err := network.Write(format, &buf); err != nil {
+		http.Error(w, "Failed to export graph", http.StatusInternalServerError)
+		return
+	}
+	contentType, ext := "application/graphml+xml", ".graphml"
+	if format == service.FormatDOT {
+		contentType, ext = "text/vnd.graphviz", ".dot"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+ext+`"`)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type HealthScoreHandler struct {
+	health *service.HealthService
+}
+
+func NewHealthScoreHandler(health *service.HealthService) *HealthScoreHandler {
+	return &HealthScoreHandler{health: health}
+}
+
+// Object returns the health score of an object, what it is made of and its
+// daily trend over the last ?days= (90 by default)
+func (h *HealthScoreHandler) Object(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days > 730 {
+		days = 730
+	}
+
+	health, err := h.health.Object(r.Context(), uuid.MustParse(claims.OrgID), id, days)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Object not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load health score", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, health)
+}
+
+// GetWeights returns the weights of the health score components of the org
+func (h *HealthScoreHandler) GetWeights(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	weights, err := h.health.Weights(r.Context(), uuid.MustParse(claims.OrgID))
+	if err != nil {
+		http.Error(w, "Failed to load health score weights", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, weights)
+}
+
+// UpdateWeights changes the weights of the org, only admins can. The objects
+// of the org are rescored in the background.
+func (h *HealthScoreHandler) UpdateWeights(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	if claims.Role != "admin" {
+		http.Error(w, "Only admin can change the health score weights", http.StatusForbidden)
+		return
+	}
+	orgID := uuid.MustParse(claims.OrgID)
+
+	var weights service.HealthWeights
+	if err := json.NewDecoder(r.Body).Decode(&weights); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err := h.health.SetWeights(r.Context(), orgID, uuid.MustParse(claims.CreatorID), weights)
+	if err == service.ErrHealthWeights {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update health score weights", http.StatusInternalServerError)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if _, err := h.health.Recompute(ctx, orgID, nil); err != nil {
+			log.Printf("Error rescoring org %s: %v", orgID, err)
+		}
+	}()
+	writeJSON(w, http.StatusAccepted, weights)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/calendar"
+	"github.com/crea8r/muninn/server/internal/database"
+)
+
+// CalendarImportRequest is the URL of a calendar to import once, for uploads
+// see CreateCalendarImportTask
+type CalendarImportRequest struct {
+	URL string `json:"url"`
+}
+
+// CreateCalendarImportTask imports the events of an iCalendar file as meeting
+// facts. The calendar is the "file" of a multipart form, or the "url" of a
+// JSON body: http(s) or webcal, on a public host, or file under the
+// CALENDAR_FILE_ROOT. Without that directory configured, local calendars
+// must be uploaded. Events already imported, by an earlier import or a
+// calendar feed, are skipped by UID, so importing again only adds the new
+// ones.
+func (h *ImportTaskHandler) CreateCalendarImportTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var data []byte
+	var fileName string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, calendar.MaxCalendarSize+1<<20)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				http.Error(w, calendar.ErrCalendarTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "No calendar file to import", http.StatusBadRequest)
+			return
+		}
+		data, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "Failed to read "+header.Filename, http.StatusBadRequest)
+			return
+		}
+		fileName = filepath.Base(header.Filename)
+	} else {
+		var req CalendarImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.URL) == "" {
+			http.Error(w, "Either a calendar file or a url is required", http.StatusBadRequest)
+			return
+		}
+		var err error
+		data, err = h.calendars.Fetch(ctx, req.URL)
+		if err != nil {
+			http.Error(w, "Failed to fetch calendar: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		fileName = strings.TrimSpace(req.URL)
+	}
+
+	events, err := calendar.Parse(bytes.NewReader(data))
+	if err != nil {
+		http.Error(w, "Invalid calendar: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	task, err := h.queries.CreateImportTask(ctx, database.CreateImportTaskParams{
+		OrgID:     orgID,
+		CreatorID: creatorID,
+		Status:    "pending",
+		TotalRows: int32(len(events)),
+		FileName:  fileName,
+		Mode:      importModeCalendar,
+	})
+	if err != nil {
+		http.Error(w, "Failed to create import task", http.StatusInternalServerError)
+		return
+	}
+
+	go h.processCalendarImportTask(task.ID, events, creatorID, orgID)
+
+	json.NewEncoder(w).Encode(map[string]string{"task_id": task.ID.String()})
+}
+
+func (h *ImportTaskHandler) processCalendarImportTask(taskID uuid.UUID, events []calendar.Event, creatorID uuid.UUID, orgID uuid.UUID) {
+	ctx := context.Background()
+
+	_, err := h.queries.UpdateImportTaskStatus(ctx, database.UpdateImportTaskStatusParams{
+		ID:     taskID,
+		Status: "processing",
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to update task status", err)
+		return
+	}
+
+	result, err := h.calendars.Import(ctx, events, calendar.Options{
+		OrgID:        orgID,
+		CreatorID:    creatorID,
+		ImportTaskID: uuid.NullUUID{UUID: taskID, Valid: true},
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to import calendar", err)
+		return
+	}
+
+	_, err = h.queries.UpdateImportTaskProgress(ctx, database.UpdateImportTaskProgressParams{
+		ID:            taskID,
+		Progress:      sql.NullInt32{Int32: 100, Valid: true},
+		ProcessedRows: sql.NullInt32{Int32: int32(len(events)), Valid: true},
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to update progress", err)
+		return
+	}
+
+	// total_rows counts events, imported and duplicate rows occurrences: a
+	// recurring event imports one fact each
+	summary := map[string]interface{}{
+		"total_rows":          len(events),
+		"imported_rows":       result.Imported,
+		"duplicate_rows":      result.Duplicates,
+		"upcoming_events":     result.Upcoming,
+		"cancelled_events":    result.Cancelled,
+		"failed_rows":         0,
+		"unmatched_addresses": result.Unmatched,
+		"errors":              []ImportRowError{},
+	}
+	summaryJSON, _ := json.Marshal(summary)
+	_, err = h.queries.CompleteImportTask(ctx, database.CompleteImportTaskParams{
+		ID:            taskID,
+		Status:        "completed",
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summaryJSON, Valid: true},
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to complete import task", err)
+	}
+}
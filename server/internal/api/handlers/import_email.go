@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/maildrop"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/crea8r/muninn/server/internal/service"
+)
+
+// maxEmailImportSize bounds the files of an email import together, in bytes
+const maxEmailImportSize = 100 << 20
+
+// importCodeInvalidMessage reports a message that could not be read
+const importCodeInvalidMessage = "invalid_message"
+
+// Statuses of the addresses in the review queue
+const (
+	reviewPending   = "pending"
+	reviewCreated   = "created"
+	reviewDismissed = "dismissed"
+)
+
+// emailImportMessage is a raw message of an import and the file it is from
+type emailImportMessage struct {
+	file string
+	data []byte
+}
+
+// CreateEmailImportTask imports the "files" of a multipart form, .eml files
+// or mbox archives, as facts of kind email. Messages already logged, by the
+// drop-box or an earlier import, are skipped by Message-ID, so importing
+// again changes nothing. Addresses matching no object nor member go to the
+// review queue.
+func (h *ImportTaskHandler) CreateEmailImportTask(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxEmailImportSize+1<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, fmt.Sprintf("Files are larger than %d MB", maxEmailImportSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		http.Error(w, "No files to import", http.StatusBadRequest)
+		return
+	}
+
+	var messages []emailImportMessage
+	var names []string
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			http.Error(w, "Failed to read "+header.Filename, http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "Failed to read "+header.Filename, http.StatusBadRequest)
+			return
+		}
+		name := filepath.Base(header.Filename)
+		names = append(names, name)
+		if maildrop.IsMbox(data) || strings.EqualFold(filepath.Ext(name), ".mbox") {
+			for _, raw := range maildrop.SplitMbox(data) {
+				messages = append(messages, emailImportMessage{file: name, data: raw})
+			}
+		} else {
+			messages = append(messages, emailImportMessage{file: name, data: data})
+		}
+	}
+	if len(messages) == 0 {
+		http.Error(w, "No messages found in the files", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	task, err := h.queries.CreateImportTask(ctx, database.CreateImportTaskParams{
+		OrgID:     orgID,
+		CreatorID: creatorID,
+		Status:    "pending",
+		TotalRows: int32(len(messages)),
+		FileName:  strings.Join(names, ", "),
+		Mode:      importModeEmail,
+	})
+	if err != nil {
+		http.Error(w, "Failed to create import task", http.StatusInternalServerError)
+		return
+	}
+
+	go h.processEmailImportTask(task.ID, messages, creatorID, orgID)
+
+	json.NewEncoder(w).Encode(map[string]string{"task_id": task.ID.String()})
+}
+
+func (h *ImportTaskHandler) processEmailImportTask(taskID uuid.UUID, messages []emailImportMessage, creatorID uuid.UUID, orgID uuid.UUID) {
+	ctx := context.Background()
+
+	_, err := h.queries.UpdateImportTaskStatus(ctx, database.UpdateImportTaskStatusParams{
+		ID:     taskID,
+		Status: "processing",
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to update task status", err)
+		return
+	}
+
+	// Copies of the imported emails sent to the drop-boxes are not contacts
+	tokens, err := h.queries.ListMailDropboxTokens(ctx, orgID)
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to load drop-boxes", err)
+		return
+	}
+	exclude := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		exclude = append(exclude, strings.ToLower(h.ingester.Address(token)))
+	}
+
+	taskRef := uuid.NullUUID{UUID: taskID, Valid: true}
+	var rowErrors []ImportRowError
+	imported, duplicates := 0, 0
+	unmatched := map[string]bool{}
+	for i, raw := range messages {
+		m, err := maildrop.Parse(bytes.NewReader(raw.data))
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      i + 1,
+				IDString: raw.file,
+				Errors: []schema.FieldError{{
+					Code:    importCodeInvalidMessage,
+					Message: err.Error(),
+				}},
+			})
+			continue
+		}
+		result, err := h.ingester.Ingest(ctx, m, maildrop.Options{
+			OrgID:         orgID,
+			CreatorID:     creatorID,
+			HistorySource: history.SourceImport,
+			Exclude:       exclude,
+		})
+		if err != nil {
+			h.logImportError(ctx, taskID, "Failed to import message "+m.MessageID, err)
+			return
+		}
+		if result.Duplicate {
+			duplicates++
+		} else {
+			imported++
+		}
+		for _, address := range result.Unmatched {
+			unmatched[address] = true
+			if err := service.QueueAddressReview(ctx, h.queries, orgID, taskRef, result.FactID, address, m.DisplayName(address)); err != nil {
+				h.logImportError(ctx, taskID, "Failed to queue address for review", err)
+				return
+			}
+		}
+
+		if processed := i + 1; processed%20 == 0 || processed == len(messages) {
+			_, err = h.queries.UpdateImportTaskProgress(ctx, database.UpdateImportTaskProgressParams{
+				ID:            taskID,
+				Progress:      sql.NullInt32{Int32: int32(processed * 100 / len(messages)), Valid: true},
+				ProcessedRows: sql.NullInt32{Int32: int32(processed), Valid: true},
+			})
+			if err != nil {
+				h.logImportError(ctx, taskID, "Failed to update progress", err)
+				return
+			}
+		}
+	}
+
+	summary := map[string]interface{}{
+		"total_rows":          len(messages),
+		"imported_rows":       imported,
+		"duplicate_rows":      duplicates,
+		"failed_rows":         len(rowErrors),
+		"unmatched_addresses": len(unmatched),
+		"errors":              rowErrors,
+	}
+	summaryJSON, _ := json.Marshal(summary)
+	_, err = h.queries.CompleteImportTask(ctx, database.CompleteImportTaskParams{
+		ID:            taskID,
+		Status:        "completed",
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summaryJSON, Valid: true},
+	})
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to complete import task", err)
+	}
+}
+
+// AddressReview is an address of imported emails matching no object nor
+// member of the org
+type AddressReview struct {
+	ID           uuid.UUID  `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	MessageCount int32      `json:"message_count"`
+	Status       string     `json:"status"`
+	ImportTaskID *uuid.UUID `json:"import_task_id"`
+	ObjID        *uuid.UUID `json:"obj_id"`
+}
+
+func addressReviewResponse(review database.AddressReview) AddressReview {
+	response := AddressReview{
+		ID:           review.ID,
+		Email:        review.Email,
+		Name:         review.Name,
+		MessageCount: review.MessageCount,
+		Status:       review.Status,
+	}
+	if review.ImportTaskID.Valid {
+		response.ImportTaskID = &review.ImportTaskID.UUID
+	}
+	if review.ObjID.Valid {
+		response.ObjID = &review.ObjID.UUID
+	}
+	return response
+}
+
+// ListAddressReviews returns the review queue, pending addresses by default
+// or those of ?status=, the most seen first
+func (h *ImportTaskHandler) ListAddressReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = reviewPending
+	case reviewPending, reviewCreated, reviewDismissed:
+	default:
+		http.Error(w, "status must be pending, created or dismissed", http.StatusBadRequest)
+		return
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	reviews, err := h.queries.ListAddressReviews(ctx, database.ListAddressReviewsParams{
+		OrgID:  orgID,
+		Status: status,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		http.Error(w, "Failed to list addresses to review", http.StatusInternalServerError)
+		return
+	}
+	totalCount, err := h.queries.CountAddressReviews(ctx, database.CountAddressReviewsParams{
+		OrgID:  orgID,
+		Status: status,
+	})
+	if err != nil {
+		http.Error(w, "Failed to get total count", http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Reviews    []AddressReview `json:"reviews"`
+		TotalCount int64           `json:"total_count"`
+		Page       int             `json:"page"`
+		PageSize   int             `json:"page_size"`
+	}{
+		Reviews:    make([]AddressReview, len(reviews)),
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i, review := range reviews {
+		response.Reviews[i] = addressReviewResponse(review)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// CreateObjectFromReview turns a pending address into an object with the
+// address as its id string and alias, linked to the facts of the emails it
+// was seen in. The name defaults to the one given with the address.
+func (h *ImportTaskHandler) CreateObjectFromReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	review, err := h.queries.GetAddressReview(ctx, database.GetAddressReviewParams{ID: reviewID, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Address not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load address", http.StatusInternalServerError)
+		return
+	}
+	if review.Status != reviewPending {
+		http.Error(w, "Address was already reviewed", http.StatusConflict)
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = review.Name
+	}
+	if name == "" {
+		name = review.Email
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, "Failed to create object", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.queries.WithTx(tx)
+
+	obj, err := qtx.CreateObject(ctx, database.CreateObjectParams{
+		Name:        name,
+		Description: input.Description,
+		IDString:    review.Email,
+		CreatorID:   creatorID,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		http.Error(w, "An object with this address as id string already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create object", http.StatusInternalServerError)
+		return
+	}
+	// The alias is what emails are matched by
+	obj, err = qtx.UpdateObject(ctx, database.UpdateObjectParams{
+		ID:          obj.ID,
+		Name:        obj.Name,
+		Description: obj.Description,
+		IDString:    obj.IDString,
+		Aliases:     []string{review.Email},
+	})
+	if err != nil {
+		http.Error(w, "Failed to create object", http.StatusInternalServerError)
+		return
+	}
+	factIDs, err := qtx.LinkAddressReviewFacts(ctx, database.LinkAddressReviewFactsParams{
+		ReviewID: review.ID,
+		ObjID:    obj.ID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to link facts", http.StatusInternalServerError)
+		return
+	}
+	if err := history.RecordFacts(ctx, qtx, history.SourceFrom(ctx), creatorID, factIDs, history.ActionUpdated); err != nil {
+		http.Error(w, "Failed to link facts", http.StatusInternalServerError)
+		return
+	}
+	review, err = qtx.ResolveAddressReview(ctx, database.ResolveAddressReviewParams{
+		ID:     review.ID,
+		OrgID:  orgID,
+		Status: reviewCreated,
+		ObjID:  uuid.NullUUID{UUID: obj.ID, Valid: true},
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Address was already reviewed", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve address", http.StatusInternalServerError)
+		return
+	}
+	if err := history.Record(ctx, qtx, creatorID, history.Entry{
+		ObjID:   obj.ID,
+		Action:  history.ActionCreated,
+		Changes: history.DiffObject(database.Obj{}, obj),
+	}); err != nil {
+		http.Error(w, "Failed to create object", http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to create object", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, addressReviewResponse(review))
+}
+
+// DismissReview takes an address out of the review queue without making an
+// object of it. It stays dismissed when seen again.
+func (h *ImportTaskHandler) DismissReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	_, err = h.queries.ResolveAddressReview(r.Context(), database.ResolveAddressReviewParams{
+		ID:     reviewID,
+		OrgID:  uuid.MustParse(claims.OrgID),
+		Status: reviewDismissed,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Address not found or already reviewed", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to dismiss address", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,27 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/calendar"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/maildrop"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/crea8r/muninn/server/internal/watch"
 )
 
 type ImportTaskHandler struct {
 	db *sql.DB
 	queries *database.Queries
+	// ingester makes facts of the messages of email imports
+	ingester *maildrop.Ingester
+	// calendars makes meeting facts of the events of calendar imports
+	calendars *calendar.Importer
 }
 
-func NewImportTaskHandler(db *sql.DB) *ImportTaskHandler {
+func NewImportTaskHandler(db *sql.DB, ingester *maildrop.Ingester, calendars *calendar.Importer) *ImportTaskHandler {
 	return &ImportTaskHandler{
 		db: db,
 		queries: database.New(db),
+		ingester: ingester,
+		calendars: calendars,
 	}
 }
 
@@ -33,6 +47,9 @@ type ImportRequest struct {
 	FileName  string          `json:"file_name"`
 	Rows      []ImportDataRow `json:"rows"`
 	Tags 		  []string        `json:"tags"`
+	// MatchField is a unique field used instead of id_string to find the
+	// object a row updates
+	MatchField string `json:"match_field,omitempty"`
 }
 
 type ImportDataRow struct {
@@ -42,6 +59,25 @@ type ImportDataRow struct {
 	Fact 	   FactToCreate      `json:"fact"`
 }
 
+// importCodeConflict reports a row whose type values changed between being
+// read and merged
+const importCodeConflict = "conflict"
+
+// Import modes: rows of objects of a type, emails, or calendar events
+const (
+	importModeObjects  = "objects"
+	importModeEmail    = "email"
+	importModeCalendar = "calendar"
+)
+
+// ImportRowError reports a row that was skipped because its values do not
+// match the object type schema
+type ImportRowError struct {
+	Row      int                 `json:"row"`
+	IDString string              `json:"id_string"`
+	Errors   []schema.FieldError `json:"errors"`
+}
+
 func (h *ImportTaskHandler) CreateImportTask(w http.ResponseWriter, r *http.Request) {
 	var req ImportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -54,6 +90,18 @@ func (h *ImportTaskHandler) CreateImportTask(w http.ResponseWriter, r *http.Requ
 	orgID := uuid.MustParse(params.OrgID)
 	creatorID := uuid.MustParse(params.CreatorID)
 
+	if req.MatchField != "" {
+		typeSchema, err := schema.Load(ctx, h.queries, uuid.MustParse(req.ObjTypeID))
+		if err != nil {
+			http.Error(w, "Invalid object type", http.StatusBadRequest)
+			return
+		}
+		if field, ok := typeSchema.Fields[req.MatchField]; !ok || !field.Validation.Unique {
+			http.Error(w, "match_field must be a unique field of the object type", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Check if there's an ongoing import for this organization
 	_, err := h.queries.GetOngoingImportTask(ctx, orgID)
 	if err != nil && err != sql.ErrNoRows {
@@ -65,10 +113,11 @@ func (h *ImportTaskHandler) CreateImportTask(w http.ResponseWriter, r *http.Requ
 	task, err := h.queries.CreateImportTask(ctx, database.CreateImportTaskParams{
 		OrgID:     orgID,
 		CreatorID: creatorID,
-		ObjTypeID: uuid.MustParse(req.ObjTypeID),
+		ObjTypeID: uuid.NullUUID{UUID: uuid.MustParse(req.ObjTypeID), Valid: true},
 		Status:    "pending",
 		TotalRows: int32(len(req.Rows)),
 		FileName:  req.FileName,
+		Mode:      importModeObjects,
 	})
 	if err != nil {
 		http.Error(w, "Failed to create import task", http.StatusInternalServerError)
@@ -76,13 +125,13 @@ func (h *ImportTaskHandler) CreateImportTask(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Start asynchronous processing
-	go h.processImportTask(task.ID, req, req.FileName, creatorID, orgID)
+	go h.processImportTask(task.ID, req, req.FileName, creatorID, orgID, viewerFromClaims(params))
 
 	// Return task ID to client
 	json.NewEncoder(w).Encode(map[string]string{"task_id": task.ID.String()})
 }
 
-func (h *ImportTaskHandler) processImportTask(taskID uuid.UUID, req ImportRequest, fileName string, creatorId uuid.UUID, orgId uuid.UUID) {
+func (h *ImportTaskHandler) processImportTask(taskID uuid.UUID, req ImportRequest, fileName string, creatorId uuid.UUID, orgId uuid.UUID, viewer schema.Viewer) {
 	ctx := context.Background()
 	
 	// Update task status to processing
@@ -95,16 +144,24 @@ func (h *ImportTaskHandler) processImportTask(taskID uuid.UUID, req ImportReques
 		return
 	}
 
+	typeSchema, err := schema.Load(ctx, h.queries, uuid.MustParse(req.ObjTypeID))
+	if err != nil {
+		h.logImportError(ctx, taskID, "Failed to load object type schema", err)
+		return
+	}
+
 	// Process the import in batches
 	batchSize := 50
 	totalRows := len(req.Rows)
+	var rowErrors []ImportRowError
 	for i := 0; i < totalRows; i += batchSize {
 		end := i + batchSize
 		if end > totalRows {
 			end = totalRows
 		}
 		batch := req.Rows[i:end]
-		err := h.processBatch(ctx, taskID, req.ObjTypeID, batch, fileName, creatorId, orgId, req.Tags)
+		batchErrors, err := h.processBatch(ctx, taskID, req.ObjTypeID, typeSchema, viewer, req.MatchField, i, batch, fileName, creatorId, orgId, req.Tags)
+		rowErrors = append(rowErrors, batchErrors...)
 		if err != nil {
 			h.logImportError(ctx, taskID, "Failed to process batch", err)
 			return
@@ -126,7 +183,9 @@ func (h *ImportTaskHandler) processImportTask(taskID uuid.UUID, req ImportReques
 	// Update task status to completed
 	summary := map[string]interface{}{
 		"total_rows": totalRows,
-		"imported_rows": totalRows,
+		"imported_rows": totalRows - len(rowErrors),
+		"failed_rows": len(rowErrors),
+		"errors": rowErrors,
 	}
 	summaryJSON, _ := json.Marshal(summary)
 	_, err = h.queries.CompleteImportTask(ctx, database.CompleteImportTaskParams{
@@ -140,12 +199,13 @@ func (h *ImportTaskHandler) processImportTask(taskID uuid.UUID, req ImportReques
 	}
 }
 
-func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTypeID string, batch []ImportDataRow, 
-	fileName string, creatorId uuid.UUID, OrgId uuid.UUID, tagIds []string) error {
+func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTypeID string, typeSchema schema.Schema, viewer schema.Viewer, matchField string, offset int, batch []ImportDataRow, 
+	fileName string, creatorId uuid.UUID, OrgId uuid.UUID, tagIds []string) ([]ImportRowError, error) {
+	var rowErrors []ImportRowError
     // Start a transaction
 	tx, err := h.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
@@ -153,41 +213,48 @@ func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTy
 	qtx := h.queries.WithTx(tx)
 
 	// Process each row in the batch
-	for _, row := range batch {
-		// Check if object exists
-		obj, err := qtx.GetObjectByIDString(ctx, row.IDString)
-		if err != nil && err != sql.ErrNoRows {
-			return fmt.Errorf("failed to check existing object: %w", err)
-		}
-
-		if err == sql.ErrNoRows {
-			// Create new object
-			obj, err = qtx.CreateObject(ctx, database.CreateObjectParams{
-				Name: 	row.Name,
-				IDString:    row.IDString,
-				Description: fmt.Sprintf("Imported from %s", fileName),
-				CreatorID:  creatorId,
-			})
+	for rowIndex, row := range batch {
+		// Check if object exists, by the match field when the row has a value
+		// for it, otherwise by id_string
+		var obj database.Obj
+		var objExists bool
+		if matchValue := row.Values[matchField]; matchField != "" && strings.TrimSpace(matchValue) != "" {
+			match, err := schema.FindByUniqueValue(ctx, qtx, OrgId, uuid.MustParse(objTypeID), matchField, matchValue)
 			if err != nil {
-				return fmt.Errorf("failed to create object: %w", err)
+				return rowErrors, fmt.Errorf("failed to match object by %s: %w", matchField, err)
+			}
+			if match != nil {
+				obj = database.Obj{ID: match.ID, Name: match.Name, IDString: match.IDString}
+				objExists = true
 			}
+		} else {
+			obj, err = qtx.GetObjectByIDString(ctx, row.IDString)
+			if err != nil && err != sql.ErrNoRows {
+				return rowErrors, fmt.Errorf("failed to check existing object: %w", err)
+			}
+			objExists = err == nil
 		}
 
-		// Fetch existing object type value
-		existingOTV, err := qtx.GetObjectTypeValue(ctx, database.GetObjectTypeValueParams{
-			ObjID: obj.ID,
-			TypeID: uuid.MustParse(objTypeID),
-		})
-		
 		var existingValues map[string]interface{}
-		if err == nil {
-			// If existing value found, unmarshal it
-			err = json.Unmarshal(existingOTV.TypeValues, &existingValues)
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal existing type values: %w", err)
+		var typeValueExists bool
+		var typeValueVersion int32
+		if objExists {
+			// Fetch existing object type value
+			existingOTV, err := qtx.GetObjectTypeValue(ctx, database.GetObjectTypeValueParams{
+				ObjID: obj.ID,
+				TypeID: uuid.MustParse(objTypeID),
+			})
+			if err == nil {
+				typeValueExists = true
+				typeValueVersion = existingOTV.Version
+				// If existing value found, unmarshal it
+				err = json.Unmarshal(existingOTV.TypeValues, &existingValues)
+				if err != nil {
+					return rowErrors, fmt.Errorf("failed to unmarshal existing type values: %w", err)
+				}
+			} else if err != sql.ErrNoRows {
+				return rowErrors, fmt.Errorf("failed to fetch existing object type value: %w", err)
 			}
-		} else if err != sql.ErrNoRows {
-			return fmt.Errorf("failed to fetch existing object type value: %w", err)
 		}
 
 		// If existingValues is nil, initialize it
@@ -195,15 +262,112 @@ func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTy
 			existingValues = make(map[string]interface{})
 		}
 
+		storedValues := make(map[string]interface{}, len(existingValues))
+		for k, v := range existingValues {
+			storedValues[k] = v
+		}
+
 		// Merge new values with existing values
 		for k, v := range row.Values {
 			existingValues[k] = v
 		}
 
+		// Restricted fields the importer cannot write keep their stored value
+		existingValues, err = typeSchema.GuardWrite(existingValues, storedValues, viewer)
+		if err == nil {
+			err = typeSchema.ValidateChange(existingValues, storedValues)
+		}
+
+		// Skip rows that do not match the schema and report them in the summary
+		if err != nil {
+			var verr *schema.ValidationError
+			if !errors.As(err, &verr) {
+				return rowErrors, err
+			}
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      offset + rowIndex + 1,
+				IDString: row.IDString,
+				Errors:   verr.Errors,
+			})
+			continue
+		}
+
+		// Skip rows whose fact metadata does not match the schema of its kind
+		factKind, err := factkind.Get(row.Fact.Kind)
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      offset + rowIndex + 1,
+				IDString: row.IDString,
+				Errors: []schema.FieldError{{
+					Field:   "kind",
+					Code:    schema.CodeInvalidOption,
+					Message: err.Error(),
+					Value:   row.Fact.Kind,
+				}},
+			})
+			continue
+		}
+		factMetadata, err := factKind.Validate(row.Fact.Metadata)
+		if err != nil {
+			var verr *schema.ValidationError
+			if !errors.As(err, &verr) {
+				return rowErrors, err
+			}
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      offset + rowIndex + 1,
+				IDString: row.IDString,
+				Errors:   verr.Errors,
+			})
+			continue
+		}
+
+		// Skip rows whose unique values belong to another object
+		var exclude []uuid.UUID
+		if objExists {
+			exclude = append(exclude, obj.ID)
+		}
+		if err := typeSchema.CheckUnique(ctx, qtx, OrgId, uuid.MustParse(objTypeID), existingValues, exclude...); err != nil {
+			var conflict *schema.UniqueConflictError
+			if !errors.As(err, &conflict) {
+				return rowErrors, err
+			}
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      offset + rowIndex + 1,
+				IDString: row.IDString,
+				Errors: []schema.FieldError{{
+					Field:   conflict.Field,
+					Code:    schema.CodeUnique,
+					Message: conflict.Error(),
+					Value:   conflict.Value,
+				}},
+			})
+			continue
+		}
+
+		if !objExists {
+			// Create new object
+			obj, err = qtx.CreateObject(ctx, database.CreateObjectParams{
+				Name: 	row.Name,
+				IDString:    row.IDString,
+				Description: fmt.Sprintf("Imported from %s", fileName),
+				CreatorID:  creatorId,
+			})
+			if err != nil {
+				return rowErrors, fmt.Errorf("failed to create object: %w", err)
+			}
+			if err := history.RecordFrom(ctx, qtx, history.SourceImport, creatorId, history.Entry{
+				ObjID:   obj.ID,
+				Action:  history.ActionCreated,
+				Changes: history.DiffObject(database.Obj{}, obj),
+			}); err != nil {
+				return rowErrors, err
+			}
+		}
+
 		// Marshal merged values back to JSON
 		mergedValuesJSON, err := json.Marshal(existingValues)
 		if err != nil {
-			return fmt.Errorf("failed to marshal merged values: %w", err)
+			return rowErrors, fmt.Errorf("failed to marshal merged values: %w", err)
 		}
 
 		// Create or update obj_type_value
@@ -211,9 +375,34 @@ func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTy
 			ObjID:    obj.ID,
 			TypeID:   uuid.MustParse(objTypeID),
 			TypeValues: mergedValuesJSON,
+			Column4:  typeValueVersion,
 		})
+		// Skip rows whose values were changed by someone else while merging
+		if err == sql.ErrNoRows {
+			rowErrors = append(rowErrors, ImportRowError{
+				Row:      offset + rowIndex + 1,
+				IDString: row.IDString,
+				Errors: []schema.FieldError{{
+					Code:    importCodeConflict,
+					Message: "type values were changed during the import",
+				}},
+			})
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("failed to upsert object type value: %w", err)
+			return rowErrors, fmt.Errorf("failed to upsert object type value: %w", err)
+		}
+		action := history.ActionTypeValueUpdated
+		if !typeValueExists {
+			action = history.ActionTypeValueAdded
+		}
+		if err := history.RecordFrom(ctx, qtx, history.SourceImport, creatorId, history.Entry{
+			ObjID:   obj.ID,
+			Action:  action,
+			TypeID:  uuid.MustParse(objTypeID),
+			Changes: history.DiffValues(storedValues, existingValues),
+		}); err != nil {
+			return rowErrors, err
 		}
 		fact := row.Fact
 		newFact, err := qtx.CreateFact(ctx, database.CreateFactParams{
@@ -224,9 +413,11 @@ func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTy
 			},
 			Location:   fact.Location,
 			CreatorID:  creatorId,
+			Kind:       factKind.Name,
+			Metadata:   factMetadata,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create fact: %w", err)
+			return rowErrors, fmt.Errorf("failed to create fact: %w", err)
 		}
 		
 		objectIds := make([]uuid.UUID, len(fact.ObjectIDs) + 1)
@@ -241,27 +432,47 @@ func (h *ImportTaskHandler) processBatch(ctx context.Context, _ uuid.UUID, objTy
 			FactID: newFact.ID,
 			OrgID: OrgId,
 		})
+		if err != nil {
+			return rowErrors, fmt.Errorf("failed to add objects to fact: %w", err)
+		}
+		if err := history.RecordFactFrom(ctx, qtx, history.SourceImport, creatorId, newFact.ID, history.ActionCreated); err != nil {
+			return rowErrors, err
+		}
 
 		for _, id := range tagIds {
 			tagUUID := uuid.MustParse(id)
-			qtx.AddTagToObject(ctx, database.AddTagToObjectParams{
+			linked, err := qtx.AddTagToObject(ctx, database.AddTagToObjectParams{
 				ObjID: obj.ID,
 				TagID: tagUUID,
 				OrgID: OrgId,
 			})
+			if err != nil {
+				return rowErrors, fmt.Errorf("failed to tag object: %w", err)
+			}
+			if linked > 0 {
+				if err := history.RecordTag(ctx, qtx, history.SourceImport, creatorId, obj.ID, tagUUID, history.ActionTagAdded); err != nil {
+					return rowErrors, err
+				}
+			}
 		}
-		
-		if err != nil {
-			return fmt.Errorf("failed to add objects to fact: %w", err)
+
+		if err := watch.Notify(ctx, qtx, creatorId, watch.Event{
+			Kind:    watch.EventFact,
+			Action:  watch.ActionCreated,
+			ObjIDs:  objectIds,
+			RefID:   newFact.ID,
+			Summary: newFact.Text,
+		}); err != nil {
+			return rowErrors, err
 		}
 	}
 
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return rowErrors, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return rowErrors, nil
 }
 
 func (h *ImportTaskHandler) logImportError(ctx context.Context, taskID uuid.UUID, message string, err error) {
@@ -338,7 +549,8 @@ func (h *ImportTaskHandler) GetImportHistory(w http.ResponseWriter, r *http.Requ
 		ID            uuid.UUID             `json:"id"`
 		OrgID         uuid.UUID             `json:"org_id"`
 		CreatorID     uuid.UUID             `json:"creator_id"`
-		ObjTypeID     uuid.UUID             `json:"obj_type_id"`
+		ObjTypeID     *uuid.UUID            `json:"obj_type_id"`
+		Mode          string                `json:"mode"`
 		Status        string                `json:"status"`
 		Progress      int         `json:"progress"`
 		TotalRows     int32                 `json:"total_rows"`
@@ -355,7 +567,10 @@ func (h *ImportTaskHandler) GetImportHistory(w http.ResponseWriter, r *http.Requ
 		returingTasks[i].ID = task.ID
 		returingTasks[i].OrgID = task.OrgID
 		returingTasks[i].CreatorID = task.CreatorID
-		returingTasks[i].ObjTypeID = task.ObjTypeID
+		if task.ObjTypeID.Valid {
+			returingTasks[i].ObjTypeID = &task.ObjTypeID.UUID
+		}
+		returingTasks[i].Mode = task.Mode
 		returingTasks[i].Status = task.Status
 		returingTasks[i].TotalRows = task.TotalRows
 		returingTasks[i].FileName = task.FileName
@@ -7,6 +7,7 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/query"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -32,6 +33,10 @@ func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkFilterSetting(w, r, input.FilterSetting) {
+		return
+	}
+
 	list, err := h.db.CreateList(r.Context(), database.CreateListParams{
 		Name:          input.Name,
 		Description:   input.Description,
@@ -81,6 +86,10 @@ func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkFilterSetting(w, r, input.FilterSetting) {
+		return
+	}
+
 	list, err := h.db.UpdateList(r.Context(), database.UpdateListParams{
 		ID:            uuid.MustParse(listID),
 		Name:          input.Name,
@@ -95,10 +104,30 @@ func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(list)
 }
 
+// checkFilterSetting checks the text query of a list filter and writes a 400
+// with the positions of its errors. It returns false when the request must
+// stop.
+func (h *ListHandler) checkFilterSetting(w http.ResponseWriter, r *http.Request, setting json.RawMessage) bool {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	err := query.CheckListFilter(r.Context(), h.db, uuid.MustParse(claims.OrgID), setting)
+	if writeQueryError(w, err) {
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Failed to check list filter", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
 func (h *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
 	listID := chi.URLParam(r, "id")
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 
-	err := h.db.DeleteList(r.Context(), uuid.MustParse(listID))
+	err := h.db.DeleteList(r.Context(), database.DeleteListParams{
+		ID:        uuid.MustParse(listID),
+		DeletedBy: uuid.NullUUID{UUID: uuid.MustParse(claims.CreatorID), Valid: true},
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/maildrop"
+	"github.com/google/uuid"
+)
+
+type MailDropboxHandler struct {
+	db  *database.Queries
+	cfg maildrop.Config
+}
+
+func NewMailDropboxHandler(db *database.Queries, cfg maildrop.Config) *MailDropboxHandler {
+	return &MailDropboxHandler{db: db, cfg: cfg}
+}
+
+// MailDropbox is the address a member bcc's or forwards emails to, to log
+// them as their facts
+type MailDropbox struct {
+	Address   string    `json:"address"`
+	CreatorID uuid.UUID `json:"creatorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (h *MailDropboxHandler) response(dropbox database.MailDropbox) MailDropbox {
+	return MailDropbox{
+		Address:   h.cfg.Address(dropbox.Token),
+		CreatorID: dropbox.CreatorID,
+		CreatedAt: dropbox.CreatedAt,
+	}
+}
+
+// Get returns the drop-box address of the member, giving them one on first
+// use. Every email it receives is logged as the member's.
+func (h *MailDropboxHandler) Get(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	dropbox, err := h.db.GetMailDropbox(r.Context(), database.GetMailDropboxParams{
+		OrgID:     orgID,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+	})
+	if err == sql.ErrNoRows {
+		token, err := maildrop.NewToken()
+		if err != nil {
+			http.Error(w, "Failed to create drop-box address", http.StatusInternalServerError)
+			return
+		}
+		dropbox, err = h.db.EnsureMailDropbox(r.Context(), database.EnsureMailDropboxParams{
+			OrgID:     orgID,
+			Token:     token,
+			CreatorID: uuid.MustParse(claims.CreatorID),
+		})
+	}
+	if err != nil {
+		http.Error(w, "Failed to load drop-box address", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.response(dropbox))
+}
+
+// Rotate gives the member a new drop-box address, the previous one stops
+// working
+func (h *MailDropboxHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	token, err := maildrop.NewToken()
+	if err != nil {
+		http.Error(w, "Failed to create drop-box address", http.StatusInternalServerError)
+		return
+	}
+	dropbox, err := h.db.UpsertMailDropbox(r.Context(), database.UpsertMailDropboxParams{
+		OrgID:     uuid.MustParse(claims.OrgID),
+		Token:     token,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+	})
+	if err != nil {
+		http.Error(w, "Failed to change drop-box address", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.response(dropbox))
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/google/uuid"
 )
 
@@ -68,6 +70,36 @@ func (h *MergeObjectsHandler) MergeObjects(w http.ResponseWriter, r *http.Reques
 			return
     }
 
+    // Check the merged type values before changing anything
+    storedValues := make(map[uuid.UUID]json.RawMessage, len(req.TypeValues))
+    for i, typeValue := range req.TypeValues {
+        typeSchema, err := schema.Load(r.Context(), h.queries, typeValue.TypeID)
+        if err != nil {
+            http.Error(w, "Invalid object type", http.StatusBadRequest)
+            return
+        }
+        // Restricted fields the caller cannot write keep the target's value
+        var stored json.RawMessage
+        existing, err := h.queries.GetObjectTypeValue(r.Context(), database.GetObjectTypeValueParams{
+            ObjID:  req.TargetObjectID,
+            TypeID: typeValue.TypeID,
+        })
+        if err == nil {
+            stored = existing.TypeValues
+            storedValues[typeValue.TypeID] = stored
+        } else if err != sql.ErrNoRows {
+            http.Error(w, "Error loading object type value", http.StatusInternalServerError)
+            return
+        }
+        // The merged objects are going away, only other objects can conflict
+        values, ok := prepareTypeValues(r.Context(), w, h.queries, typeSchema, uuid.MustParse(claims.OrgID), typeValue.TypeID,
+            typeValue.TypeValues, stored, viewerFromClaims(claims), allObjects...)
+        if !ok {
+            return
+        }
+        req.TypeValues[i].TypeValues = values
+    }
+
     // Begin transaction
     tx, err := h.db.BeginTx(r.Context(), nil)
     if err != nil {
@@ -75,8 +107,15 @@ func (h *MergeObjectsHandler) MergeObjects(w http.ResponseWriter, r *http.Reques
         return
     }
     defer tx.Rollback()
+    qtx := h.queries.WithTx(tx)
+
+    before, err := qtx.GetObjectByID(r.Context(), req.TargetObjectID)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading object: %v", err), http.StatusInternalServerError)
+        return
+    }
 
-    _,err = h.queries.UpdateObject(r.Context(), database.UpdateObjectParams{
+    updated, err := qtx.UpdateObject(r.Context(), database.UpdateObjectParams{
         ID:         req.TargetObjectID,
         Name:       req.Name,
         Description: req.Description,
@@ -88,10 +127,19 @@ func (h *MergeObjectsHandler) MergeObjects(w http.ResponseWriter, r *http.Reques
         http.Error(w, fmt.Sprintf("Error updating object: %v", err), http.StatusInternalServerError)
         return
     }
+    if err := history.Record(r.Context(), qtx, creatorID, history.Entry{
+        ObjID:   req.TargetObjectID,
+        Action:  history.ActionUpdated,
+        Changes: history.DiffObject(before, updated),
+    }); err != nil {
+        http.Error(w, fmt.Sprintf("Error recording history: %v", err), http.StatusInternalServerError)
+        return
+    }
 
     // Handle object type values if provided
     for _, typeValue := range req.TypeValues {
-        _, err := h.queries.UpsertObjectTypeValue(r.Context(), database.UpsertObjectTypeValueParams{
+        stored := storedValues[typeValue.TypeID]
+        result, err := qtx.UpsertObjectTypeValue(r.Context(), database.UpsertObjectTypeValueParams{
             ObjID:      req.TargetObjectID,
             TypeID:     typeValue.TypeID,
             TypeValues: typeValue.TypeValues,
@@ -100,10 +148,30 @@ func (h *MergeObjectsHandler) MergeObjects(w http.ResponseWriter, r *http.Reques
             http.Error(w, fmt.Sprintf("Error updating object type value: %v", err), http.StatusInternalServerError)
             return
         }
+        action := history.ActionTypeValueUpdated
+        if stored == nil {
+            action = history.ActionTypeValueAdded
+        }
+        if err := history.Record(r.Context(), qtx, creatorID, history.Entry{
+            ObjID:   req.TargetObjectID,
+            Action:  action,
+            TypeID:  typeValue.TypeID,
+            Changes: history.DiffValuesRaw(stored, result.TypeValues),
+        }); err != nil {
+            http.Error(w, fmt.Sprintf("Error recording history: %v", err), http.StatusInternalServerError)
+            return
+        }
+    }
+
+    // The facts of the sources move to the target, each gets a version
+    factIDs, err := qtx.ListObjectFactIDs(r.Context(), req.SourceObjectIDs)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading facts: %v", err), http.StatusInternalServerError)
+        return
     }
 
     // Perform merge
-    err = h.queries.MergeObjects(r.Context(), database.MergeObjectsParams{
+    err = qtx.MergeObjects(r.Context(), database.MergeObjectsParams{
         TargetObjectID:  req.TargetObjectID,
         SourceObjectIds: req.SourceObjectIDs,
         CreatorID:      creatorID,
@@ -112,9 +180,34 @@ func (h *MergeObjectsHandler) MergeObjects(w http.ResponseWriter, r *http.Reques
         http.Error(w, fmt.Sprintf("Error performing merge: %v", err), http.StatusInternalServerError)
         return
     }
+    if err := history.Record(r.Context(), qtx, creatorID, history.Entry{
+        ObjID:   req.TargetObjectID,
+        Action:  history.ActionMerged,
+        Details: map[string]interface{}{"sourceObjectIds": req.SourceObjectIDs},
+    }); err != nil {
+        http.Error(w, fmt.Sprintf("Error recording history: %v", err), http.StatusInternalServerError)
+        return
+    }
+    if err := history.RecordFacts(r.Context(), qtx, history.SourceFrom(r.Context()), creatorID, factIDs, history.ActionUpdated); err != nil {
+        http.Error(w, fmt.Sprintf("Error recording fact versions: %v", err), http.StatusInternalServerError)
+        return
+    }
+    for _, sourceID := range req.SourceObjectIDs {
+        if err := history.Record(r.Context(), qtx, creatorID, history.Entry{
+            ObjID:   sourceID,
+            Action:  history.ActionMergedInto,
+            Details: map[string]interface{}{"targetObjectId": req.TargetObjectID},
+        }); err != nil {
+            http.Error(w, fmt.Sprintf("Error recording history: %v", err), http.StatusInternalServerError)
+            return
+        }
+    }
 
     // Commit transaction
     if err = tx.Commit(); err != nil {
+        if writeValidationError(w, err) {
+            return
+        }
         http.Error(w, "Error committing transaction", http.StatusInternalServerError)
         return
     }
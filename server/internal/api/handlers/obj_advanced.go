@@ -3,12 +3,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/factkind"
 	"github.com/crea8r/muninn/server/internal/service"
 	"github.com/crea8r/muninn/server/pkg/pagination"
 	"github.com/google/uuid"
@@ -51,6 +53,21 @@ func parseUUIDs(input string) ([]uuid.UUID, error) {
 	return result, nil
 }
 
+// parseFactKinds parses a comma separated list of fact kinds
+func parseFactKinds(input string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(input, ",") {
+		if kind = strings.TrimSpace(kind); kind == "" {
+			continue
+		}
+		if !factkind.Valid(kind) {
+			return nil, fmt.Errorf("%w %q", factkind.ErrUnknownKind, kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
 // parseTypeValueCriteria helper function to parse type value criteria
 func parseTypeValueCriteria(r *http.Request) ([]json.RawMessage, error) {
 	var criteria []json.RawMessage
@@ -141,6 +158,13 @@ func (h *AdvancedObjectHandler) ListObjects(w http.ResponseWriter, r *http.Reque
 			return
 	}
 
+	// Parse fact kinds, objects with at least one fact of these kinds
+	factKinds, err := parseFactKinds(r.URL.Query().Get("fact_kinds"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
 	// Parse ordering parameters
 	orderBy := service.OrderBy(r.URL.Query().Get("order_by"))
 	typeValueField := r.URL.Query().Get("type_value_field")
@@ -163,15 +187,27 @@ func (h *AdvancedObjectHandler) ListObjects(w http.ResponseWriter, r *http.Reque
 		TypeValueField:    typeValueField,
 		Ascending:    ascending,
 		SubStatusFilter: subStatusFilter,
+		FactKinds:       factKinds,
+		Viewer:          viewerFromClaims(claims),
+		Query:           r.URL.Query().Get("query"),
 	}
 
 	// Get results from service
 	result, err := h.objectService.ListObjects(ctx, params)
+	if writeQueryError(w, err) {
+		return
+	}
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "invalid parameters" {
 			status = http.StatusBadRequest
 		}
+		if errors.Is(err, service.ErrRestrictedField) {
+			status = http.StatusForbidden
+		}
+		if errors.Is(err, service.ErrComputedScanLimit) {
+			status = http.StatusBadRequest
+		}
 		writeJSON(w, status, errorResponse{Error: err.Error()})
 		return
 	}
@@ -7,16 +7,18 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type ObjStepHandler struct {
 	ObjectModel *models.ObjectModel
+	Trash       *service.TrashService
 }
 
-func NewObjStepHandler(objectModel *models.ObjectModel) *ObjStepHandler {
-	return &ObjStepHandler{ObjectModel: objectModel}
+func NewObjStepHandler(objectModel *models.ObjectModel, trash *service.TrashService) *ObjStepHandler {
+	return &ObjStepHandler{ObjectModel: objectModel, Trash: trash}
 }
 
 func (h *ObjStepHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -42,7 +44,7 @@ func (h *ObjStepHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	fmt.Println("Created obj step ",objStep)
 	if input.SubStatus != 0 {
-		err = h.ObjectModel.UpdateObjStepSubStatus(r.Context(), objStep.ID, input.SubStatus)
+		err = h.ObjectModel.UpdateObjStepSubStatus(r.Context(), objStep.ID, input.SubStatus, uuid.MustParse(claims.CreatorID))
 		if err != nil {
 			fmt.Println("Error updating sub status ",err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -61,7 +63,9 @@ func (h *ObjStepHandler) SoftDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.ObjectModel.SoftDeleteObjStep(r.Context(), id)
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.ObjectModel.SoftDeleteObjStep(r.Context(), id, uuid.MustParse(claims.CreatorID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -77,7 +81,13 @@ func (h *ObjStepHandler) HardDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.ObjectModel.HardDeleteObjStep(r.Context(), id)
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.Trash.Purge(r.Context(), service.TrashObjStep, id, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID))
+	if err == service.ErrTrashNotFound {
+		http.Error(w, "Obj step not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -103,7 +113,9 @@ func (h *ObjStepHandler) UpdateSubStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.ObjectModel.UpdateObjStepSubStatus(r.Context(), id, input.SubStatus)
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.ObjectModel.UpdateObjStepSubStatus(r.Context(), id, input.SubStatus, uuid.MustParse(claims.CreatorID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -10,6 +10,7 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -49,6 +50,10 @@ func (h *ObjectTypeHandler) CreateObjectType(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if !checkFieldDefinitions(w, req.Fields) {
+		return
+	}
+
 	if req.Icon == "" {
 		req.Icon = "file"
 	}
@@ -145,6 +150,10 @@ func (h *ObjectTypeHandler) UpdateObjectType(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if !checkFieldDefinitions(w, req.Fields) {
+		return
+	}
+
 	if req.Icon == "" {
 		req.Icon = "file"
 	}
@@ -172,6 +181,8 @@ func (h *ObjectTypeHandler) UpdateObjectType(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Object type not found", http.StatusNotFound)
+		} else if schema.IsMigrating(err) {
+			http.Error(w, schema.ErrMigrating.Error(), http.StatusConflict)
 		} else {
 			fmt.Printf("Error updating object type: %v\n", err)
 			http.Error(w, "Failed to update object type", http.StatusInternalServerError)
@@ -5,12 +5,14 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"fmt"
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
 	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -18,10 +20,26 @@ import (
 type ObjectHandler struct {
 	ObjectModel *models.ObjectModel
 	DB          *database.Queries
+	// FuzzyThreshold is the default similarity of the fuzzy search
+	FuzzyThreshold float64
 }
 
-func NewObjectHandler(objectModel *models.ObjectModel, db *database.Queries) *ObjectHandler {
-	return &ObjectHandler{ObjectModel: objectModel, DB: db}
+func NewObjectHandler(objectModel *models.ObjectModel, db *database.Queries, fuzzyThreshold float64) *ObjectHandler {
+	return &ObjectHandler{ObjectModel: objectModel, DB: db, FuzzyThreshold: fuzzyThreshold}
+}
+
+// fuzzyThreshold is the threshold query parameter, the default one when it
+// is not set
+func (h *ObjectHandler) fuzzyThreshold(r *http.Request) (float64, bool) {
+	value := r.URL.Query().Get("threshold")
+	if value == "" {
+		return h.FuzzyThreshold, true
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || !models.ValidFuzzyThreshold(threshold) {
+		return 0, false
+	}
+	return threshold, true
 }
 
 func (h *ObjectHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -70,12 +88,30 @@ func (h *ObjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	object, err := h.ObjectModel.Update(r.Context(), id, input.Name, input.Description, input.IDString, input.Aliases)
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	object, err := h.ObjectModel.Update(r.Context(), id, input.Name, input.Description, input.IDString, input.Aliases, uuid.MustParse(claims.CreatorID), version)
+	if err == models.ErrVersionConflict {
+		current, err := h.ObjectModel.GetDetails(r.Context(), id, uuid.MustParse(claims.OrgID), viewerFromClaims(claims))
+		if err != nil {
+			http.Error(w, "Object not found", http.StatusNotFound)
+			return
+		}
+		writeConflict(w, current.Version, current)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, object.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(object)
 }
@@ -87,7 +123,9 @@ func (h *ObjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.ObjectModel.Delete(r.Context(), id)
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.ObjectModel.Delete(r.Context(), id, uuid.MustParse(claims.CreatorID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -110,10 +148,16 @@ func (h *ObjectHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 10
 	}
 
+	threshold, ok := h.fuzzyThreshold(r)
+	if !ok {
+		http.Error(w, "Invalid threshold, must be greater than 0 and at most 1", http.StatusBadRequest)
+		return
+	}
+
 	offset := int32((page - 1) * pageSize)
 	limit := int32(pageSize)
 
-	objects, totalCount, err := h.ObjectModel.List(r.Context(), orgId, search, limit, offset)
+	objects, totalCount, err := h.ObjectModel.List(r.Context(), orgId, search, threshold, limit, offset, viewerFromClaims(claims))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -134,6 +178,37 @@ func (h *ObjectHandler) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// Suggest lists the objects matching a mention being typed, for its
+// autocomplete
+func (h *ObjectHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgId := uuid.MustParse(claims.OrgID)
+
+	q := strings.TrimPrefix(strings.TrimSpace(r.URL.Query().Get("q")), "@")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+	threshold, ok := h.fuzzyThreshold(r)
+	if !ok {
+		http.Error(w, "Invalid threshold, must be greater than 0 and at most 1", http.StatusBadRequest)
+		return
+	}
+
+	suggestions := []database.SuggestObjectsRow{}
+	if q != "" {
+		var err error
+		suggestions, err = h.ObjectModel.Suggest(r.Context(), orgId, q, threshold, int32(limit), viewerFromClaims(claims))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
 func (h *ObjectHandler) GetDetails(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -143,16 +218,56 @@ func (h *ObjectHandler) GetDetails(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	orgId := uuid.MustParse(claims.OrgID)
 
-	objectDetails, err := h.ObjectModel.GetDetails(r.Context(), id, orgId)
+	objectDetails, err := h.ObjectModel.GetDetails(r.Context(), id, orgId, viewerFromClaims(claims))
 	if err != nil {
 		http.Error(w, "Object not found", http.StatusNotFound)
 		return
 	}
 
+	setETag(w, objectDetails.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(objectDetails)
 }
 
+func (h *ObjectHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgId := uuid.MustParse(claims.OrgID)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	entries, totalCount, err := h.ObjectModel.History(r.Context(), id, orgId, int32(pageSize), int32((page-1)*pageSize), viewerFromClaims(claims))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := struct {
+		History    []models.ObjectHistoryEntry `json:"history"`
+		TotalCount int64                       `json:"totalCount"`
+		Page       int                         `json:"page"`
+		PageSize   int                         `json:"pageSize"`
+	}{
+		History:    entries,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *ObjectHandler) AddTag(w http.ResponseWriter, r *http.Request) {
 	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -173,7 +288,7 @@ func (h *ObjectHandler) AddTag(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	orgId := uuid.MustParse(claims.OrgID)
 
-	err = h.ObjectModel.AddTag(r.Context(), objectID, input.TagID, orgId)
+	err = h.ObjectModel.AddTag(r.Context(), objectID, input.TagID, orgId, uuid.MustParse(claims.CreatorID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -198,7 +313,7 @@ func (h *ObjectHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	orgId := uuid.MustParse(claims.OrgID)
 
-	err = h.ObjectModel.RemoveTag(r.Context(), objectID, tagID, orgId)
+	err = h.ObjectModel.RemoveTag(r.Context(), objectID, tagID, orgId, uuid.MustParse(claims.CreatorID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -230,8 +345,11 @@ func (h *ObjectHandler) AddObjectTypeValue(w http.ResponseWriter, r *http.Reques
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	orgId := uuid.MustParse(claims.OrgID)
 	// TODO: orgId is unused in model, need to check it somewhere
-	typeValue, err := h.ObjectModel.AddObjectTypeValue(r.Context(), objectID, input.TypeID, input.Values, orgId)
+	typeValue, err := h.ObjectModel.AddObjectTypeValue(r.Context(), objectID, input.TypeID, input.Values, orgId, uuid.MustParse(claims.CreatorID), viewerFromClaims(claims))
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -249,8 +367,11 @@ func (h *ObjectHandler) RemoveObjectTypeValue(w http.ResponseWriter, r *http.Req
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	orgId := uuid.MustParse(claims.OrgID)
 
-	err = h.ObjectModel.RemoveObjectTypeValue(r.Context(), typeValueID, orgId)
+	err = h.ObjectModel.RemoveObjectTypeValue(r.Context(), typeValueID, orgId, uuid.MustParse(claims.CreatorID), viewerFromClaims(claims))
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -259,7 +380,7 @@ func (h *ObjectHandler) RemoveObjectTypeValue(w http.ResponseWriter, r *http.Req
 }
 
 func (h *ObjectHandler) UpdateObjectTypeValue(w http.ResponseWriter, r *http.Request) {
-	_, err := uuid.Parse(chi.URLParam(r, "id"))
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
 		http.Error(w, "Invalid object ID", http.StatusBadRequest)
 		return
@@ -281,19 +402,51 @@ func (h *ObjectHandler) UpdateObjectTypeValue(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
 	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
 	OrgID := uuid.MustParse(claims.OrgID)
 
-	updatedTypeValue, err := h.ObjectModel.UpdateObjectTypeValue(r.Context(), typeValueID, OrgID, input.Values)
+	updatedTypeValue, err := h.ObjectModel.UpdateObjectTypeValue(r.Context(), typeValueID, OrgID, uuid.MustParse(claims.CreatorID), input.Values, viewerFromClaims(claims), version)
+	if err == models.ErrVersionConflict {
+		h.writeTypeValueConflict(w, r, objectID, typeValueID)
+		return
+	}
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, updatedTypeValue.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedTypeValue)
 }
 
+// writeTypeValueConflict answers with the current state of the type value,
+// as the object details show it to the viewer
+func (h *ObjectHandler) writeTypeValueConflict(w http.ResponseWriter, r *http.Request, objectID, typeValueID uuid.UUID) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	details, err := h.ObjectModel.GetDetails(r.Context(), objectID, uuid.MustParse(claims.OrgID), viewerFromClaims(claims))
+	if err != nil {
+		http.Error(w, "Object not found", http.StatusNotFound)
+		return
+	}
+	for _, tv := range details.TypeValues {
+		if tv.ID == typeValueID {
+			writeConflict(w, tv.Version, tv)
+			return
+		}
+	}
+	http.Error(w, "Type value not found", http.StatusNotFound)
+}
+
 type ObjectWithTagsAndTypeValues struct {
 	ID          uuid.UUID       `json:"id"`
 	Name        string          `json:"name"`
@@ -358,6 +511,20 @@ func (h *ObjectHandler) ListObjectsByTypeWithAdvancedFilter(w http.ResponseWrite
 		}
 	}
 
+	// Restricted fields can be neither filtered on nor shown to the viewer
+	viewer := viewerFromClaims(claims)
+	typeSchema, err := schema.Load(ctx, h.DB, typeID)
+	if err != nil {
+		http.Error(w, "Failed to get object type", http.StatusInternalServerError)
+		return
+	}
+	for field := range filterParams.TypeValues {
+		if !typeSchema.CanRead(field, viewer) {
+			http.Error(w, "Cannot filter by restricted field "+field, http.StatusForbidden)
+			return
+		}
+	}
+
 	// Prepare type values filter
 	typeValuesFilter, err := json.Marshal(filterParams.TypeValues)
 	if err != nil {
@@ -405,7 +572,7 @@ func (h *ObjectHandler) ListObjectsByTypeWithAdvancedFilter(w http.ResponseWrite
 			Name:        object.Name,
 			Description: object.Description,
 			Tags:        objectTags,
-			TypeValues:  object.TypeValues,
+			TypeValues:  typeSchema.RedactRaw(object.TypeValues, viewer),
 		}
 		objectsWithTagsAndTypeValues = append(objectsWithTagsAndTypeValues, objectWithTagsAndTypeValues)
 	}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type PhotoHandler struct {
+	photos *service.PhotoService
+}
+
+func NewPhotoHandler(photos *service.PhotoService) *PhotoHandler {
+	return &PhotoHandler{photos: photos}
+}
+
+// Upload sets the photo of an object from the "photo" file of a multipart
+// form
+func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	// Leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxPhotoSize+1<<20)
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, service.ErrPhotoTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Missing photo file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	url, err := h.photos.Upload(r.Context(), objectID, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID), file)
+	switch {
+	case err == service.ErrPhotoTooLarge, err == service.ErrPhotoPixels:
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err == service.ErrPhotoType:
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	case err == service.ErrPhotoObject:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("Error uploading photo of object %s: %v", objectID, err)
+		http.Error(w, "Failed to upload photo", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"photo": url})
+}
+
+func (h *PhotoHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.photos.Remove(r.Context(), objectID, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID))
+	if err == service.ErrPhotoObject {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Serve sends a photo in the size given by ?size= (original, medium or
+// thumb). Only the current photo of an object of the caller's org is served.
+// Browsers keep a private copy but check the ETag every time, so a removed
+// photo stops loading at once.
+func (h *PhotoHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = service.PhotoOriginal
+	}
+
+	rc, info, err := h.photos.Open(r.Context(), id, uuid.MustParse(claims.OrgID), size)
+	if err == service.ErrPhotoNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load photo", http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+
+	etag := `"` + id.String() + "-" + size + `"`
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", info.ContentType)
+	if info.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if !info.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	io.Copy(w, rc)
+}
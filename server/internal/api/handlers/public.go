@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -187,51 +188,37 @@ func (h *PublicHandler) GetObjectDetail(w http.ResponseWriter, r *http.Request)
 		typeValues = []database.GetPublicObjectTypeValuesRow{}
 	}
 
-	// Extract linked object IDs from type values to fetch shared facts
-	var linkedObjectIDs []uuid.UUID
-	for _, tv := range typeValues {
-		var values map[string]interface{}
-		if err := json.Unmarshal(tv.TypeValues, &values); err == nil {
-			for _, v := range values {
-				// Handle string (deprecated but supported)
-				if s, ok := v.(string); ok {
-					if id, err := uuid.Parse(s); err == nil {
-						linkedObjectIDs = append(linkedObjectIDs, id)
-					}
-				}
-				// Handle object {id, name, ...}
-				if m, ok := v.(map[string]interface{}); ok {
-					if idStr, ok := m["id"].(string); ok {
-						if id, err := uuid.Parse(idStr); err == nil {
-							linkedObjectIDs = append(linkedObjectIDs, id)
-						}
-					}
-				}
-				// Handle array of strings or objects
-				if arr, ok := v.([]interface{}); ok {
-					for _, item := range arr {
-						if s, ok := item.(string); ok {
-							if id, err := uuid.Parse(s); err == nil {
-								linkedObjectIDs = append(linkedObjectIDs, id)
-							}
-						}
-						if m, ok := item.(map[string]interface{}); ok {
-							if idStr, ok := m["id"].(string); ok {
-								if id, err := uuid.Parse(idStr); err == nil {
-									linkedObjectIDs = append(linkedObjectIDs, id)
-								}
-							}
-						}
-					}
-				}
-			}
+	// Restricted fields are never public
+	for i, tv := range typeValues {
+		typeSchema, err := schema.Parse(tv.Fields)
+		if err != nil {
+			continue
 		}
+		typeValues[i].TypeValues = typeSchema.RedactRaw(tv.TypeValues, schema.PublicViewer)
+	}
+
+	// Related objects come from obj_relation instead of object ids stored in
+	// type values, limited to public objects
+	relations, err := h.db.ListPublicObjectRelations(r.Context(), database.ListPublicObjectRelationsParams{
+		ObjID: objectID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if relations == nil {
+		relations = []database.ListPublicObjectRelationsRow{}
 	}
 
 	// Fetch shared facts if any linked objects found
 	// REVERTED: To match protected route logic (irrelevant facts from linked objects removed)
 	/*
-		if len(linkedObjectIDs) > 0 {
+		if len(relations) > 0 {
+			var linkedObjectIDs []uuid.UUID
+			for _, relation := range relations {
+				linkedObjectIDs = append(linkedObjectIDs, relation.ObjectID)
+			}
 			sharedFacts, err := h.db.GetFactsByObjectIDs(r.Context(), linkedObjectIDs)
 			if err == nil {
 				existingFactIDs := make(map[uuid.UUID]bool)
@@ -259,6 +246,7 @@ func (h *PublicHandler) GetObjectDetail(w http.ResponseWriter, r *http.Request)
 		"object":      object,
 		"facts":       facts,
 		"type_values": typeValues,
+		"relations":   relations,
 	}
 
 	json.NewEncoder(w).Encode(response)
@@ -323,5 +311,15 @@ func (h *PublicHandler) GetObjectsByType(w http.ResponseWriter, r *http.Request)
 		objects = []database.GetPublicObjectsByTypeRow{}
 	}
 
+	// Restricted fields are never public
+	typeSchema, err := schema.Load(r.Context(), h.db, typeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range objects {
+		objects[i].TypeValues = typeSchema.RedactRaw(objects[i].TypeValues, schema.PublicViewer)
+	}
+
 	json.NewEncoder(w).Encode(objects)
 }
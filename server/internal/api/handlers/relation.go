@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultGraphDepth = 1
+	maxGraphDepth     = 3
+	maxRelationType   = 100
+)
+
+type RelationHandler struct {
+	ObjectModel *models.ObjectModel
+	DB          *database.Queries
+}
+
+func NewRelationHandler(objectModel *models.ObjectModel, db *database.Queries) *RelationHandler {
+	return &RelationHandler{ObjectModel: objectModel, DB: db}
+}
+
+// GraphNode is an object reached from the root of a graph query, Depth is
+// the number of relations between them
+type GraphNode struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Photo    string    `json:"photo"`
+	IDString string    `json:"idString"`
+	Depth    int32     `json:"depth"`
+}
+
+type GraphEdge struct {
+	ID           uuid.UUID       `json:"id"`
+	From         uuid.UUID       `json:"from"`
+	To           uuid.UUID       `json:"to"`
+	RelationType string          `json:"relationType"`
+	Metadata     json.RawMessage `json:"metadata"`
+}
+
+type GraphResponse struct {
+	Root  uuid.UUID   `json:"root"`
+	Depth int         `json:"depth"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func (h *RelationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		ToObjectID   uuid.UUID       `json:"toObjectId"`
+		RelationType string          `json:"relationType"`
+		Metadata     json.RawMessage `json:"metadata"`
+		// Direction "incoming" creates the relation from toObjectId to the object
+		Direction string `json:"direction"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input.RelationType = strings.TrimSpace(input.RelationType)
+	if input.RelationType == "" || len(input.RelationType) > maxRelationType {
+		http.Error(w, "relationType is required and must be at most 100 characters", http.StatusBadRequest)
+		return
+	}
+	if input.ToObjectID == uuid.Nil || input.ToObjectID == objectID {
+		http.Error(w, "toObjectId must be another object", http.StatusBadRequest)
+		return
+	}
+	if len(input.Metadata) == 0 || string(input.Metadata) == "null" {
+		input.Metadata = json.RawMessage("{}")
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(input.Metadata, &metadata); err != nil {
+		http.Error(w, "metadata must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	from, to := objectID, input.ToObjectID
+	switch input.Direction {
+	case "", "outgoing":
+	case "incoming":
+		from, to = to, from
+	default:
+		http.Error(w, "direction must be outgoing or incoming", http.StatusBadRequest)
+		return
+	}
+
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	relation, err := h.DB.CreateObjectRelation(r.Context(), database.CreateObjectRelationParams{
+		FromObjID:    from,
+		ToObjID:      to,
+		RelationType: input.RelationType,
+		Metadata:     input.Metadata,
+		CreatorID:    uuid.MustParse(claims.CreatorID),
+		OrgID:        uuid.MustParse(claims.OrgID),
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Object not found", http.StatusNotFound)
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			http.Error(w, "Relation already exists", http.StatusConflict)
+		default:
+			http.Error(w, "Failed to create relation", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(relation)
+}
+
+func (h *RelationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	relationID, err := uuid.Parse(chi.URLParam(r, "relationId"))
+	if err != nil {
+		http.Error(w, "Invalid relation ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	affected, err := h.DB.DeleteObjectRelation(r.Context(), database.DeleteObjectRelationParams{
+		ID:    relationID,
+		ObjID: objectID,
+		OrgID: uuid.MustParse(claims.OrgID),
+	})
+	if err != nil {
+		http.Error(w, "Failed to delete relation", http.StatusInternalServerError)
+		return
+	}
+	if affected == 0 {
+		http.Error(w, "Relation not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RelationHandler) List(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	relations, err := h.ObjectModel.ListRelations(r.Context(), objectID, uuid.MustParse(claims.OrgID))
+	if err != nil {
+		http.Error(w, "Failed to list relations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(relations)
+}
+
+// Graph returns the objects reachable from an object through relations, up
+// to ?depth= hops away, together with the relations between them
+func (h *RelationHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	objectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object ID", http.StatusBadRequest)
+		return
+	}
+	depth := defaultGraphDepth
+	if v := r.URL.Query().Get("depth"); v != "" {
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth < 1 || depth > maxGraphDepth {
+			http.Error(w, "depth must be between 1 and 3", http.StatusBadRequest)
+			return
+		}
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	rows, err := h.DB.ListObjectNeighbourhood(r.Context(), database.ListObjectNeighbourhoodParams{
+		ObjID:   objectID,
+		OrgID:   uuid.MustParse(claims.OrgID),
+		Column3: int32(depth),
+	})
+	if err != nil {
+		http.Error(w, "Failed to load graph", http.StatusInternalServerError)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "Object not found", http.StatusNotFound)
+		return
+	}
+
+	response := GraphResponse{Root: objectID, Depth: depth, Nodes: make([]GraphNode, len(rows)), Edges: []GraphEdge{}}
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		response.Nodes[i] = GraphNode{ID: row.ID, Name: row.Name, Photo: row.Photo, IDString: row.IDString, Depth: row.Depth}
+	}
+
+	relations, err := h.DB.ListRelationsBetweenObjects(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "Failed to load graph", http.StatusInternalServerError)
+		return
+	}
+	for _, relation := range relations {
+		response.Edges = append(response.Edges, GraphEdge{
+			ID:           relation.ID,
+			From:         relation.FromObjID,
+			To:           relation.ToObjID,
+			RelationType: relation.RelationType,
+			Metadata:     relation.Metadata,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListTypes returns the relation types already used in the org, most used
+// first, for autocomplete
+func (h *RelationHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	types, err := h.DB.ListRelationTypes(r.Context(), uuid.MustParse(claims.OrgID))
+	if err != nil {
+		http.Error(w, "Failed to list relation types", http.StatusInternalServerError)
+		return
+	}
+	if types == nil {
+		types = []database.ListRelationTypesRow{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types)
+}
@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
+)
+
+// maxReportedFailures caps the failures kept in result_summary
+const maxReportedFailures = 500
+
+type SchemaMigrationHandler struct {
+	db      *sql.DB
+	queries *database.Queries
+}
+
+func NewSchemaMigrationHandler(db *sql.DB) *SchemaMigrationHandler {
+	return &SchemaMigrationHandler{
+		db:      db,
+		queries: database.New(db),
+	}
+}
+
+type SchemaMigrationRequest struct {
+	Operations schema.Plan `json:"operations"`
+	DryRun     bool        `json:"dry_run"`
+}
+
+// SchemaMigrationFailure is a row that could not be migrated
+type SchemaMigrationFailure struct {
+	TypeValueID uuid.UUID           `json:"type_value_id"`
+	ObjectID    uuid.UUID           `json:"object_id"`
+	Skipped     bool                `json:"skipped"`
+	Errors      []schema.FieldError `json:"errors"`
+}
+
+func (h *SchemaMigrationHandler) CreateSchemaMigration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims := ctx.Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	typeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object type ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SchemaMigrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	typeSchema, err := schema.Load(ctx, h.queries, typeID)
+	if err != nil {
+		http.Error(w, "Object type not found", http.StatusNotFound)
+		return
+	}
+
+	var verr *schema.ValidationError
+	if errors.As(req.Operations.Check(typeSchema), &verr) {
+		writeJSON(w, http.StatusBadRequest, validationErrorResponse{
+			Error:  "invalid migration",
+			Fields: verr.Errors,
+		})
+		return
+	}
+
+	// Runs that died are reverted first, they would block this one forever
+	if err := h.revertStaleSchemaMigrations(ctx, typeID); err != nil {
+		http.Error(w, "Failed to revert interrupted migrations", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.queries.GetOngoingSchemaMigration(ctx, typeID)
+	if err == nil {
+		http.Error(w, "A migration is already running for this object type", http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		http.Error(w, "Failed to check ongoing migrations", http.StatusInternalServerError)
+		return
+	}
+
+	operations, _ := json.Marshal(req.Operations)
+	migration, err := h.queries.CreateSchemaMigration(ctx, database.CreateSchemaMigrationParams{
+		OrgID:      orgID,
+		CreatorID:  creatorID,
+		ObjTypeID:  typeID,
+		Operations: operations,
+		DryRun:     req.DryRun,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Object type not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to create migration", http.StatusInternalServerError)
+		return
+	}
+
+	go h.processSchemaMigration(migration, req.Operations)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(migration)
+}
+
+func (h *SchemaMigrationHandler) GetSchemaMigration(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	migrationID, err := uuid.Parse(chi.URLParam(r, "migrationId"))
+	if err != nil {
+		http.Error(w, "Invalid migration ID", http.StatusBadRequest)
+		return
+	}
+
+	migration, err := h.queries.GetSchemaMigration(r.Context(), database.GetSchemaMigrationParams{
+		ID:    migrationID,
+		OrgID: orgID,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Migration not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to get migration", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(migration)
+}
+
+func (h *SchemaMigrationHandler) ListSchemaMigrations(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	typeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid object type ID", http.StatusBadRequest)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	migrations, err := h.queries.ListSchemaMigrations(r.Context(), database.ListSchemaMigrationsParams{
+		OrgID:     orgID,
+		ObjTypeID: typeID,
+		Limit:     int32(pageSize),
+		Offset:    int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		http.Error(w, "Failed to list migrations", http.StatusInternalServerError)
+		return
+	}
+	if migrations == nil {
+		migrations = []database.SchemaMigration{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(migrations)
+}
+
+// processSchemaMigration rewrites the values of the type batch by batch, then
+// swaps the fields of the type. The type is locked for writes from the
+// creation of the migration until the swap, see migration 024, and a run that
+// fails is reverted.
+func (h *SchemaMigrationHandler) processSchemaMigration(migration database.SchemaMigration, plan schema.Plan) {
+	ctx := context.Background()
+
+	if err := h.queries.UpdateSchemaMigrationStatus(ctx, database.UpdateSchemaMigrationStatusParams{
+		ID:     migration.ID,
+		Status: "processing",
+	}); err != nil {
+		h.failSchemaMigration(ctx, migration.ID, "Failed to update migration status", err, nil)
+		return
+	}
+
+	objType, err := h.queries.GetObjectTypeByID(ctx, migration.ObjTypeID)
+	if err != nil {
+		h.failSchemaMigration(ctx, migration.ID, "Failed to load object type", err, nil)
+		return
+	}
+	typeSchema, err := schema.Parse(objType.Fields)
+	if err != nil {
+		h.failSchemaMigration(ctx, migration.ID, "Failed to parse object type fields", err, nil)
+		return
+	}
+
+	var failures []SchemaMigrationFailure
+	failedRows := 0
+	skippedRows := 0
+	processed := 0
+	lastID := uuid.Nil
+	batchSize := 100
+	for {
+		batch, batchFailures, err := h.migrateBatch(ctx, migration, plan, typeSchema, lastID, batchSize)
+		if err != nil {
+			h.failSchemaMigration(ctx, migration.ID, "Failed to migrate batch", err, failures)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		failedRows += len(batchFailures)
+		for _, f := range batchFailures {
+			if f.Skipped {
+				skippedRows++
+			}
+		}
+		if room := maxReportedFailures - len(failures); room > 0 {
+			if len(batchFailures) > room {
+				batchFailures = batchFailures[:room]
+			}
+			failures = append(failures, batchFailures...)
+		}
+
+		processed += len(batch)
+		lastID = batch[len(batch)-1].ID
+		progress := 100
+		if migration.TotalRows > 0 && processed < int(migration.TotalRows) {
+			progress = processed * 100 / int(migration.TotalRows)
+		}
+		// Also keeps the run live, see schema_migration_is_live
+		if err := h.queries.UpdateSchemaMigrationProgress(ctx, database.UpdateSchemaMigrationProgressParams{
+			ID:            migration.ID,
+			Progress:      sql.NullInt32{Int32: int32(progress), Valid: true},
+			ProcessedRows: sql.NullInt32{Int32: int32(processed), Valid: true},
+			FailedRows:    sql.NullInt32{Int32: int32(failedRows), Valid: true},
+		}); err != nil {
+			h.failSchemaMigration(ctx, migration.ID, "Failed to update progress", err, failures)
+			return
+		}
+
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	summary, _ := json.Marshal(map[string]interface{}{
+		"total_rows":    processed,
+		"migrated_rows": processed - skippedRows,
+		"failed_rows":   failedRows,
+		"skipped_rows":  skippedRows,
+		"dry_run":       migration.DryRun,
+		"failures":      failures,
+		"truncated":     failedRows > len(failures),
+	})
+	if err := h.completeSchemaMigration(ctx, migration, plan, objType.Fields, summary); err != nil {
+		h.failSchemaMigration(ctx, migration.ID, "Failed to complete migration", err, failures)
+	}
+}
+
+// migrateBatch rewrites in a transaction the batch of type values after the
+// one with id after, keeping their previous values to revert them. It
+// returns the batch and the rows that failed coercion.
+func (h *SchemaMigrationHandler) migrateBatch(ctx context.Context, migration database.SchemaMigration, plan schema.Plan, typeSchema schema.Schema,
+	after uuid.UUID, limit int) ([]database.ObjTypeValue, []SchemaMigrationFailure, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := h.queries.WithTx(tx)
+
+	if err := qtx.EnterSchemaMigration(ctx, migration.ID); err != nil {
+		return nil, nil, fmt.Errorf("failed to enter migration: %w", err)
+	}
+	// Locked until the commit, a write that started before the migration
+	// cannot be overwritten with values computed from the previous ones
+	batch, err := qtx.ListObjectTypeValuesForMigration(ctx, database.ListObjectTypeValuesForMigrationParams{
+		TypeID: migration.ObjTypeID,
+		ID:     after,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load type values: %w", err)
+	}
+
+	var failures []SchemaMigrationFailure
+	for _, row := range batch {
+		var values map[string]interface{}
+		if len(row.TypeValues) > 0 {
+			if err := json.Unmarshal(row.TypeValues, &values); err != nil {
+				failures = append(failures, SchemaMigrationFailure{
+					TypeValueID: row.ID,
+					ObjectID:    row.ObjID,
+					Skipped:     true,
+					Errors:      []schema.FieldError{{Code: schema.CodeInvalidType, Message: "stored type values are not a JSON object"}},
+				})
+				continue
+			}
+		}
+
+		result := plan.Apply(typeSchema, values)
+		if len(result.Errors) > 0 {
+			failures = append(failures, SchemaMigrationFailure{
+				TypeValueID: row.ID,
+				ObjectID:    row.ObjID,
+				Skipped:     result.Skipped,
+				Errors:      result.Errors,
+			})
+		}
+		if result.Skipped || migration.DryRun {
+			continue
+		}
+
+		migrated, err := json.Marshal(result.Values)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal type values: %w", err)
+		}
+		if err := qtx.SetObjectTypeValues(ctx, database.SetObjectTypeValuesParams{
+			ID:         row.ID,
+			TypeValues: migrated,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to update type values: %w", err)
+		}
+		if err := qtx.BackupObjectTypeValue(ctx, database.BackupObjectTypeValueParams{
+			MigrationID: migration.ID,
+			TypeValueID: row.ID,
+			TypeValues:  row.TypeValues,
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to back up type values: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return batch, failures, nil
+}
+
+// completeSchemaMigration swaps the fields of the type and marks the
+// migration completed in one transaction, which also unlocks the type
+func (h *SchemaMigrationHandler) completeSchemaMigration(ctx context.Context, migration database.SchemaMigration, plan schema.Plan,
+	fields json.RawMessage, summary json.RawMessage) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := h.queries.WithTx(tx)
+
+	if !migration.DryRun {
+		if err := qtx.EnterSchemaMigration(ctx, migration.ID); err != nil {
+			return fmt.Errorf("failed to enter migration: %w", err)
+		}
+		fields, err := plan.ApplyFields(fields)
+		if err != nil {
+			return fmt.Errorf("failed to rewrite object type fields: %w", err)
+		}
+		if err := qtx.UpdateObjectTypeFields(ctx, database.UpdateObjectTypeFieldsParams{
+			ID:     migration.ObjTypeID,
+			Fields: fields,
+		}); err != nil {
+			return fmt.Errorf("failed to update object type fields: %w", err)
+		}
+		if _, err := qtx.RebuildObjectTypeValueSearchVectors(ctx, migration.ObjTypeID); err != nil {
+			return fmt.Errorf("failed to rebuild search vectors: %w", err)
+		}
+		if err := qtx.DeleteSchemaMigrationBackup(ctx, migration.ID); err != nil {
+			return fmt.Errorf("failed to delete backup: %w", err)
+		}
+	}
+	if err := qtx.CompleteSchemaMigration(ctx, database.CompleteSchemaMigrationParams{
+		ID:            migration.ID,
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summary, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to complete migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// failSchemaMigration reverts a migration that could not complete. When the
+// revert fails too, the migration is only marked failed, the next migration
+// of the type retries the revert.
+func (h *SchemaMigrationHandler) failSchemaMigration(ctx context.Context, migrationID uuid.UUID, message string, err error, failures []SchemaMigrationFailure) {
+	message = fmt.Sprintf("%s: %v", message, err)
+	revertErr := h.revertSchemaMigration(ctx, migrationID, message, failures)
+	if revertErr == nil {
+		return
+	}
+	fmt.Printf("Failed to revert schema migration %s: %v\n", migrationID, revertErr)
+
+	summary, _ := json.Marshal(map[string]interface{}{"failures": failures})
+	updateErr := h.queries.FailSchemaMigration(ctx, database.FailSchemaMigrationParams{
+		ID:            migrationID,
+		ErrorMessage:  sql.NullString{String: fmt.Sprintf("%s, reverting failed: %v", message, revertErr), Valid: true},
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summary, Valid: true},
+	})
+	if updateErr != nil {
+		fmt.Printf("Failed to update schema migration error: %v\n", updateErr)
+	}
+}
+
+// revertSchemaMigration restores the values a migration rewrote and marks it
+// failed with message, in one transaction so that the type stays locked
+// until its values are back
+func (h *SchemaMigrationHandler) revertSchemaMigration(ctx context.Context, migrationID uuid.UUID, message string, failures []SchemaMigrationFailure) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := h.queries.WithTx(tx)
+
+	if err := qtx.EnterSchemaMigration(ctx, migrationID); err != nil {
+		return err
+	}
+	reverted, err := qtx.RevertSchemaMigration(ctx, migrationID)
+	if err != nil {
+		return err
+	}
+	if err := qtx.DeleteSchemaMigrationBackup(ctx, migrationID); err != nil {
+		return err
+	}
+	summary, _ := json.Marshal(map[string]interface{}{
+		"failures":      failures,
+		"reverted_rows": reverted,
+	})
+	if err := qtx.FailSchemaMigration(ctx, database.FailSchemaMigrationParams{
+		ID:            migrationID,
+		ErrorMessage:  sql.NullString{String: message + ", changes reverted", Valid: true},
+		ResultSummary: pqtype.NullRawMessage{RawMessage: summary, Valid: true},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// revertStaleSchemaMigrations reverts the migrations of a type whose process
+// died, and finishes the reverts that failed
+func (h *SchemaMigrationHandler) revertStaleSchemaMigrations(ctx context.Context, typeID uuid.UUID) error {
+	stale, err := h.queries.ListStaleSchemaMigrations(ctx, typeID)
+	if err != nil {
+		return err
+	}
+	for _, migration := range stale {
+		message := "Interrupted before completing"
+		if migration.ErrorMessage.Valid {
+			message, _, _ = strings.Cut(migration.ErrorMessage.String, ", reverting failed")
+		}
+		if err := h.revertSchemaMigration(ctx, migration.ID, message, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
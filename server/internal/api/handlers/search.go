@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/google/uuid"
+)
+
+type SearchHandler struct {
+	search *service.SearchService
+}
+
+func NewSearchHandler(search *service.SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// Search looks for ?q= in objects, facts, tasks, tags and lists at once and
+// returns the hits grouped by kind, best first, with the count of each kind.
+// ?kinds=object,fact restricts the groups returned, the other kinds are still
+// counted. ?limit= and ?offset= page the hits of each group.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	var kinds []string
+	if raw := r.URL.Query().Get("kinds"); raw != "" {
+		for _, kind := range strings.Split(raw, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 5
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	result, err := h.search.Search(r.Context(), service.SearchParams{
+		OrgID:    uuid.MustParse(claims.OrgID),
+		ViewerID: uuid.MustParse(claims.CreatorID),
+		Viewer:   viewerFromClaims(claims),
+		Query:    query,
+		Kinds:    kinds,
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if errors.Is(err, service.ErrSearchKind) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to search", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
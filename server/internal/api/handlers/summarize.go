@@ -26,6 +26,12 @@ func (h *SummarizeHandler) PersonalSummarize(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Failed to count unseen feed items", http.StatusInternalServerError)
 		return
 	}
+	// Comments mentioning the creator are part of unseen, told apart here
+	unseenMentions, err := h.db.CountUnseenMentions(r.Context(), uuid.MustParse(claims.CreatorID))
+	if err != nil {
+		http.Error(w, "Failed to count unseen mentions", http.StatusInternalServerError)
+		return
+	}
 	ongoingTask, err := h.db.CountOngoingTask(r.Context(), uuid.NullUUID{Valid: true, UUID: uuid.MustParse(claims.CreatorID)})
 	if err != nil {
 		http.Error(w, "Failed to count ongoing task", http.StatusInternalServerError)
@@ -35,11 +41,13 @@ func (h *SummarizeHandler) PersonalSummarize(w http.ResponseWriter, r *http.Requ
 	// Prepare response
 	type SummarizeResponse struct {
 		Unseen 		int64 `json:"unseen"`
+		UnseenMentions int64 `json:"unseenMentions"`
 		OngoingTask int64 `json:"ongoingTask"`
 	}
 
 	response := SummarizeResponse{
 		Unseen: unseen,
+		UnseenMentions: unseenMentions,
 		OngoingTask: ongoingTask,
 	}
 
@@ -101,7 +101,11 @@ func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rowsAffected, err := h.DB.DeleteTag(r.Context(), tagID)
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	rowsAffected, err := h.DB.DeleteTag(r.Context(), database.DeleteTagParams{
+		ID:        tagID,
+		DeletedBy: uuid.NullUUID{UUID: uuid.MustParse(claims.CreatorID), Valid: true},
+	})
 	if err != nil {
 		http.Error(w, "Failed to delete tag", http.StatusInternalServerError)
 		return
@@ -10,13 +10,15 @@ import (
 
 	"github.com/crea8r/muninn/server/internal/api/middleware"
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/watch"
 	"github.com/crea8r/muninn/server/pkg/ctype"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type TaskHandler struct {
-	db *database.Queries
+	db    *database.Queries
+	sqlDB *sql.DB
 }
 
 type BasicObject struct {
@@ -163,8 +165,8 @@ func ConvertObjectIDRowTask(task database.ListTasksByObjectIDRow) ResponseTask {
 	return responseTask
 }
 
-func NewTaskHandler(db *database.Queries) *TaskHandler {
-	return &TaskHandler{db: db}
+func NewTaskHandler(db *database.Queries, sqlDB *sql.DB) *TaskHandler {
+	return &TaskHandler{db: db, sqlDB: sqlDB}
 }
 
 type createTaskRequest struct {
@@ -209,7 +211,17 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	task, err := h.db.CreateTask(r.Context(), database.CreateTaskParams{
+	// The task, its objects and the notifications of their watchers are
+	// saved together
+	tx, err := h.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	qtx := h.db.WithTx(tx)
+
+	task, err := qtx.CreateTask(r.Context(), database.CreateTaskParams{
 		Content:    req.Content,
 		Deadline:   sql.NullTime{Time: req.Deadline.Time, Valid: req.Deadline.Valid},
 		RemindAt:   sql.NullTime{Time: req.RemindAt.Time, Valid: req.RemindAt.Valid},
@@ -226,7 +238,7 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Associate objects with the task
 	if len(req.ObjectIDs) > 0 {
-		err = h.db.AddObjectsToTask(r.Context(), database.AddObjectsToTaskParams{
+		err = qtx.AddObjectsToTask(r.Context(), database.AddObjectsToTaskParams{
 			Column1: req.ObjectIDs,
 			TaskID:  task.ID,
 			OrgID:   OrgID,
@@ -235,6 +247,20 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Error associating objects with task", http.StatusInternalServerError)
 			return
 		}
+		if err := watch.Notify(r.Context(), qtx, CreatorID, watch.Event{
+			Kind:    watch.EventTask,
+			Action:  watch.ActionCreated,
+			ObjIDs:  req.ObjectIDs,
+			RefID:   task.ID,
+			Summary: task.Content,
+		}); err != nil {
+			http.Error(w, "Error notifying watchers of task", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
 	}
 	objects, err := h.db.ListObjectsByTaskID(r.Context(), task.ID)
 	if err != nil {
@@ -364,7 +390,10 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.db.DeleteTask(r.Context(), taskID)
+	err = h.db.DeleteTask(r.Context(), database.DeleteTaskParams{
+		ID:        taskID,
+		DeletedBy: uuid.NullUUID{UUID: CreatorId, Valid: true},
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
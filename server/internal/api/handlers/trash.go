@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type TrashHandler struct {
+	trash *service.TrashService
+}
+
+func NewTrashHandler(trash *service.TrashService) *TrashHandler {
+	return &TrashHandler{trash: trash}
+}
+
+// List returns the soft-deleted items of the org, optionally of one kind
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, totalCount, err := h.trash.List(r.Context(), orgID, r.URL.Query().Get("kind"), int32(pageSize), int32((page-1)*pageSize))
+	if err == service.ErrTrashKind {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := struct {
+		Items      []service.TrashItem `json:"items"`
+		TotalCount int64               `json:"totalCount"`
+		Page       int                 `json:"page"`
+		PageSize   int                 `json:"pageSize"`
+	}{
+		Items:      items,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	err = h.trash.Restore(r.Context(), kind, id, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID))
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Purge removes an item from the trash for good, only admins can purge
+func (h *TrashHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	if claims.Role != "admin" {
+		http.Error(w, "Only admin can purge the trash", http.StatusForbidden)
+		return
+	}
+	if !service.IsTrashKind(kind) {
+		http.Error(w, service.ErrTrashKind.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.trash.Purge(r.Context(), kind, id, uuid.MustParse(claims.OrgID), uuid.MustParse(claims.CreatorID))
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTrashError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrTrashKind):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrTrashNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrTrashConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/query"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+type validationErrorResponse struct {
+	Error  string              `json:"error"`
+	Fields []schema.FieldError `json:"fields"`
+}
+
+type queryErrorResponse struct {
+	Error  string           `json:"error"`
+	Errors []query.PosError `json:"errors"`
+}
+
+type uniqueConflictResponse struct {
+	Error    string                      `json:"error"`
+	Conflict *schema.UniqueConflictError `json:"conflict"`
+}
+
+// writeValidationError writes a 400 with the offending fields when err is a
+// schema validation error, a 403 when only restricted fields were rejected,
+// or a 409 pointing at the existing object when a unique field value is
+// taken or while a schema migration rewrites the type. It returns false for
+// any other error so the caller can handle it as before.
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	if schema.IsMigrating(err) {
+		http.Error(w, schema.ErrMigrating.Error(), http.StatusConflict)
+		return true
+	}
+	if violation, ok := schema.UniqueViolation(err); ok {
+		err = violation
+	}
+	var conflict *schema.UniqueConflictError
+	if errors.As(err, &conflict) {
+		writeJSON(w, http.StatusConflict, uniqueConflictResponse{
+			Error:    conflict.Error(),
+			Conflict: conflict,
+		})
+		return true
+	}
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		return false
+	}
+	if verr.Forbidden() {
+		writeJSON(w, http.StatusForbidden, validationErrorResponse{
+			Error:  "restricted fields",
+			Fields: verr.Errors,
+		})
+		return true
+	}
+	writeJSON(w, http.StatusBadRequest, validationErrorResponse{
+		Error:  "invalid type values",
+		Fields: verr.Errors,
+	})
+	return true
+}
+
+// writeQueryError writes a 400 with the positions of the errors when err is
+// an error in a text query. It returns false for any other error.
+func writeQueryError(w http.ResponseWriter, err error) bool {
+	var qerr *query.Error
+	if !errors.As(err, &qerr) {
+		return false
+	}
+	writeJSON(w, http.StatusBadRequest, queryErrorResponse{
+		Error:  "invalid query",
+		Errors: qerr.Errors,
+	})
+	return true
+}
+
+// checkFieldDefinitions parses obj_type.fields and writes a 400 when the
+// definitions are invalid. It returns false when the request must stop.
+func checkFieldDefinitions(w http.ResponseWriter, fields json.RawMessage) bool {
+	s, err := schema.Parse(fields)
+	if err != nil {
+		http.Error(w, "Invalid fields: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	var verr *schema.ValidationError
+	if errors.As(s.Check(), &verr) {
+		writeJSON(w, http.StatusBadRequest, validationErrorResponse{
+			Error:  "invalid field definitions",
+			Fields: verr.Errors,
+		})
+		return false
+	}
+	return true
+}
+
+// checkFactMetadata checks the metadata of a fact against the schema of its
+// kind and writes a 400 when either is invalid. It returns the normalized
+// metadata, and false when the request must stop.
+func checkFactMetadata(w http.ResponseWriter, kind string, metadata json.RawMessage) (json.RawMessage, bool) {
+	k, err := factkind.Get(kind)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	metadata, err = k.Validate(metadata)
+	var verr *schema.ValidationError
+	if errors.As(err, &verr) {
+		writeJSON(w, http.StatusBadRequest, validationErrorResponse{
+			Error:  "invalid fact metadata",
+			Fields: verr.Errors,
+		})
+		return nil, false
+	}
+	return metadata, true
+}
+
+// viewerFromClaims is who restricted fields are checked for on a request
+func viewerFromClaims(claims *middleware.Claims) schema.Viewer {
+	return schema.Viewer{Role: claims.Role}
+}
+
+// prepareTypeValues runs the write checks of an object type schema on values
+// sent by viewer: restricted fields keep their stored value, then validation
+// and unique fields. The objects in exclude are ignored by the unique check.
+// It writes the error response and returns false when the write must stop.
+func prepareTypeValues(ctx context.Context, w http.ResponseWriter, db *database.Queries, typeSchema schema.Schema, orgID, typeID uuid.UUID,
+	values, stored json.RawMessage, viewer schema.Viewer, exclude ...uuid.UUID) (json.RawMessage, bool) {
+	err := schema.CheckNotMigrating(ctx, db, typeID)
+	if err == nil {
+		values, err = typeSchema.GuardWriteRaw(values, stored, viewer)
+	}
+	if err == nil {
+		err = typeSchema.ValidateRawChange(values, stored)
+	}
+	if err == nil {
+		err = typeSchema.CheckUniqueRaw(ctx, db, orgID, typeID, values, exclude...)
+	}
+	if err != nil {
+		if !writeValidationError(w, err) {
+			http.Error(w, "Failed to check type values", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return values, true
+}
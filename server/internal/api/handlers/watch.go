@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/watch"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WatchHandler struct {
+	db *database.Queries
+}
+
+func NewWatchHandler(db *database.Queries) *WatchHandler {
+	return &WatchHandler{db: db}
+}
+
+// WatchRequest names what to watch, exactly one of the targets, and the
+// events wanted. No events means all of them.
+type WatchRequest struct {
+	ObjectID *uuid.UUID `json:"object_id"`
+	ListID   *uuid.UUID `json:"list_id"`
+	StepID   *uuid.UUID `json:"step_id"`
+	Events   []string   `json:"events"`
+}
+
+// watchEvents checks the events of a request, defaulting to all
+func watchEvents(events []string) ([]string, bool) {
+	if len(events) == 0 {
+		return watch.Events, true
+	}
+	seen := map[string]bool{}
+	var unique []string
+	for _, e := range events {
+		if !watch.IsEvent(e) {
+			return nil, false
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+	return unique, true
+}
+
+// Create watches an object, a list or a funnel step of the org. Watching the
+// same target again replaces the events of the existing watch.
+func (h *WatchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+	orgID := uuid.MustParse(claims.OrgID)
+	creatorID := uuid.MustParse(claims.CreatorID)
+
+	var req WatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	events, ok := watchEvents(req.Events)
+	if !ok {
+		http.Error(w, "Unknown event, expected fact, task, step or type_value", http.StatusBadRequest)
+		return
+	}
+
+	var found bool
+	var err error
+	switch {
+	case req.ObjectID != nil && req.ListID == nil && req.StepID == nil:
+		var ids []uuid.UUID
+		ids, err = h.db.FilterOrgObjectIDs(r.Context(), database.FilterOrgObjectIDsParams{
+			Column1: []uuid.UUID{*req.ObjectID},
+			OrgID:   orgID,
+		})
+		found = len(ids) > 0
+	case req.ListID != nil && req.ObjectID == nil && req.StepID == nil:
+		found, err = h.db.IsOrgList(r.Context(), database.IsOrgListParams{ID: *req.ListID, OrgID: orgID})
+	case req.StepID != nil && req.ObjectID == nil && req.ListID == nil:
+		found, err = h.db.IsOrgStep(r.Context(), database.IsOrgStepParams{ID: *req.StepID, OrgID: orgID})
+	default:
+		http.Error(w, "Exactly one of object_id, list_id and step_id is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to check watch target", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Watch target not found", http.StatusNotFound)
+		return
+	}
+
+	target := database.FindWatchParams{
+		CreatorID: creatorID,
+		ObjID:     nullUUID(req.ObjectID),
+		ListID:    nullUUID(req.ListID),
+		StepID:    nullUUID(req.StepID),
+	}
+	existing, err := h.db.FindWatch(r.Context(), target)
+	if err == nil {
+		updated, err := h.db.UpdateWatchEvents(r.Context(), database.UpdateWatchEventsParams{
+			ID:        existing.ID,
+			CreatorID: creatorID,
+			Events:    events,
+		})
+		if err != nil {
+			http.Error(w, "Failed to update watch", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+		return
+	} else if err != sql.ErrNoRows {
+		http.Error(w, "Failed to check existing watch", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.db.CreateWatch(r.Context(), database.CreateWatchParams{
+		CreatorID: creatorID,
+		ObjID:     target.ObjID,
+		ListID:    target.ListID,
+		StepID:    target.StepID,
+		Events:    events,
+	})
+	if err != nil {
+		http.Error(w, "Failed to create watch", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// List returns the watches of the current creator
+func (h *WatchHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	watches, err := h.db.ListWatchesByCreator(r.Context(), uuid.MustParse(claims.CreatorID))
+	if err != nil {
+		http.Error(w, "Failed to list watches", http.StatusInternalServerError)
+		return
+	}
+	if watches == nil {
+		watches = []database.ListWatchesByCreatorRow{}
+	}
+	writeJSON(w, http.StatusOK, watches)
+}
+
+// Update changes the events of a watch
+func (h *WatchHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid watch ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	var req struct {
+		Events []string `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	events, ok := watchEvents(req.Events)
+	if !ok {
+		http.Error(w, "Unknown event, expected fact, task, step or type_value", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.db.UpdateWatchEvents(r.Context(), database.UpdateWatchEventsParams{
+		ID:        id,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+		Events:    events,
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Watch not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update watch", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *WatchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid watch ID", http.StatusBadRequest)
+		return
+	}
+	claims := r.Context().Value(middleware.UserClaimsKey).(*middleware.Claims)
+
+	rows, err := h.db.DeleteWatch(r.Context(), database.DeleteWatchParams{
+		ID:        id,
+		CreatorID: uuid.MustParse(claims.CreatorID),
+	})
+	if err != nil {
+		http.Error(w, "Failed to delete watch", http.StatusInternalServerError)
+		return
+	}
+	if rows == 0 {
+		http.Error(w, "Watch not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
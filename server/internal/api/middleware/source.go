@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/crea8r/muninn/server/internal/history"
+)
+
+// Source tags the object changes made by the requests of a route group with
+// where they come from. Requests without it are recorded as made in the webapp.
+func Source(source string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(history.WithSource(r.Context(), source)))
+		})
+	}
+}
@@ -3,13 +3,17 @@ package api
 import (
 	"database/sql"
 	"fmt"
-	"net/http"
+	"log"
 	"os"
 
 	"github.com/crea8r/muninn/server/internal/api/handlers"
 	"github.com/crea8r/muninn/server/internal/api/middleware"
+	"github.com/crea8r/muninn/server/internal/blob"
+	"github.com/crea8r/muninn/server/internal/calendar"
 	"github.com/crea8r/muninn/server/internal/database"
 	"github.com/crea8r/muninn/server/internal/features/auth"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/maildrop"
 	"github.com/crea8r/muninn/server/internal/models"
 	"github.com/crea8r/muninn/server/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -25,8 +29,8 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 		AllowedOrigins: []string{"http://localhost:3000", "http://localhost:5173", "http://127.0.0.1:3000", "http://127.0.0.1:5173", "*"}, // Allow all origins
 		//[]string{"http://localhost:3000", "https://yourdomain.com"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "If-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	})
@@ -38,37 +42,51 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 	tagHandler := handlers.NewTagHandler(queries)
 	objectTypeHandler := handlers.NewObjectTypeHandler(queries, db)
 	funnelHandler := handlers.NewFunnelHandler(queries)
-	objectModel := models.NewObjectModel(queries)
-	objectHandler := handlers.NewObjectHandler(objectModel, queries)
-	objStepHandler := handlers.NewObjStepHandler(objectModel)
-	factHandler := handlers.NewFactHandler(queries)
-	taskHandler := handlers.NewTaskHandler(queries)
+	objectModel := models.NewObjectModel(queries, db)
+	fuzzyThreshold, err := models.FuzzyThresholdFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load fuzzy search configuration: %v", err)
+	}
+	objectHandler := handlers.NewObjectHandler(objectModel, queries, fuzzyThreshold)
+	blobStore, err := blob.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up blob store: %v", err)
+	}
+	photoHandler := handlers.NewPhotoHandler(service.NewPhotoService(queries, db, blobStore))
+	attachmentService := service.NewAttachmentService(queries, blobStore)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	trashService := service.NewTrashService(queries, db)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	objStepHandler := handlers.NewObjStepHandler(objectModel, trashService)
+	relationHandler := handlers.NewRelationHandler(objectModel, queries)
+	factHandler := handlers.NewFactHandler(queries, db)
+	taskHandler := handlers.NewTaskHandler(queries, db)
 	feedHandler := handlers.NewFeedHandler(queries)
+	watchHandler := handlers.NewWatchHandler(queries)
 	summarizeHandler := handlers.NewSummarizeHandler(queries)
 	listHandler := handlers.NewListHandler(queries)
-	importHandler := handlers.NewImportTaskHandler(db)
 	mergeHandler := handlers.NewMergeObjectsHandler(db)
+	schemaMigrationHandler := handlers.NewSchemaMigrationHandler(db)
+	bulkOperationHandler := handlers.NewBulkOperationHandler(db, objectService)
 	metricsService := service.NewMetricsService(queries)
 	metricsHandler := handlers.NewMetricsHandler(metricsService)
 	externalHandler := handlers.NewExternalHandler(db, queries)
 	automationHandler := handlers.NewAutomationHandler(queries)
 	gdpHandler := handlers.NewGDPHandler(queries)
-	wrapWithFeed := func(handler http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			rw := middleware.NewResponseWriter(w)
-			handler.ServeHTTP(rw, r)
-
-			// Only create feed entry if the response was successful (status code < 400)
-			if rw.Status() < 400 {
-				// TODO: rethink this
-				// middleware.CreateFeedEntry(db, r, rw)
-			}
-		}
+	graphHandler := handlers.NewGraphHandler(service.NewGraphService(queries), queries)
+	healthScoreHandler := handlers.NewHealthScoreHandler(service.NewHealthService(queries))
+	searchHandler := handlers.NewSearchHandler(service.NewSearchService(queries))
+	mailConfig, err := maildrop.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load mail drop-box configuration: %v", err)
 	}
-
+	mailDropboxHandler := handlers.NewMailDropboxHandler(queries, mailConfig)
+	calendarImporter := calendar.NewImporter(calendar.ConfigFromEnv(), queries, db)
+	calendarFeedHandler := handlers.NewCalendarFeedHandler(queries, calendarImporter)
+	importHandler := handlers.NewImportTaskHandler(db, maildrop.NewIngester(mailConfig, queries, db, attachmentService), calendarImporter)
 	// Public routes
 	authHandler := *auth.NewHandler(queries)
-	authHandler.RegisterRoutes(r, wrapWithFeed)
+	authHandler.RegisterRoutes(r)
 
 	publicHandler := handlers.NewPublicHandler(queries)
 	r.Get("/public/stats", publicHandler.GetStats)
@@ -103,21 +121,32 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 
 		r.Route("/setting/object-types", func(r chi.Router) {
 			r.Use(middleware.Permission)
-			r.Post("/", wrapWithFeed(objectTypeHandler.CreateObjectType))
+			r.Post("/", objectTypeHandler.CreateObjectType)
 			r.Get("/", objectTypeHandler.ListObjectTypes)
 			r.Put("/{id}", objectTypeHandler.UpdateObjectType)
 			r.Delete("/{id}", objectTypeHandler.DeleteObjectType)
 			r.Post("/{typeID}/advance", objectHandler.ListObjectsByTypeWithAdvancedFilter)
 
+			// Schema migration routes
+			r.Post("/{id}/migrations", schemaMigrationHandler.CreateSchemaMigration)
+			r.Get("/{id}/migrations", schemaMigrationHandler.ListSchemaMigrations)
+			r.Get("/migrations/{migrationId}", schemaMigrationHandler.GetSchemaMigration)
+
 			// Access control routes
 			r.Post("/access", objectTypeHandler.GrantAccessToObjectType)
 			r.Delete("/access/{creatorID}/{objectTypeID}", objectTypeHandler.RevokeAccessToObjectType)
 			r.Get("/access/{creatorID}", objectTypeHandler.GetAccessibleObjectTypesForMember)
 		})
 
+		r.Route("/setting/health-score", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", healthScoreHandler.GetWeights)
+			r.Put("/", healthScoreHandler.UpdateWeights)
+		})
+
 		r.Route("/setting/funnels", func(r chi.Router) {
 			r.Use(middleware.Permission)
-			r.Post("/", wrapWithFeed(funnelHandler.CreateFunnel))
+			r.Post("/", funnelHandler.CreateFunnel)
 			r.Get("/", funnelHandler.ListFunnels)
 			r.Get("/{id}", funnelHandler.GetFunnel)
 			r.Put("/{id}", funnelHandler.UpdateFunnel)
@@ -128,22 +157,38 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 		r.Route("/objects", func(r chi.Router) {
 			r.Use(middleware.Permission)
 			// Object routes
-			r.Post("/", wrapWithFeed(objectHandler.Create))
+			r.Post("/", objectHandler.Create)
 			r.Get("/", objectHandler.List)
+			r.Get("/suggest", objectHandler.Suggest)
 			r.Get("/{id}", objectHandler.GetDetails)
-			r.Put("/{id}", wrapWithFeed(objectHandler.Update))
+			r.Put("/{id}", objectHandler.Update)
 			r.Delete("/{id}", objectHandler.Delete)
+			r.Get("/{id}/history", objectHandler.History)
 			// Tag routes
 			r.Post("/{id}/tags", objectHandler.AddTag)
 			r.Delete("/{id}/tags/{tagId}", objectHandler.RemoveTag)
 
 			// Object type value routes
-			r.Post("/{id}/type-values", wrapWithFeed(objectHandler.AddObjectTypeValue))
+			r.Post("/{id}/type-values", objectHandler.AddObjectTypeValue)
 			r.Put("/{id}/type-values/{typeValueId}", objectHandler.UpdateObjectTypeValue)
 			r.Delete("/{id}/type-values/{typeValueId}", objectHandler.RemoveObjectTypeValue)
 
+			// Photo routes
+			r.Post("/{id}/photo", photoHandler.Upload)
+			r.Delete("/{id}/photo", photoHandler.Remove)
+			r.Post("/{id}/attachments", attachmentHandler.UploadToObject)
+
+			// Relation routes
+			r.Get("/relation-types", relationHandler.ListTypes)
+			r.Get("/{id}/relations", relationHandler.List)
+			r.Post("/{id}/relations", relationHandler.Create)
+			r.Delete("/{id}/relations/{relationId}", relationHandler.Delete)
+			r.Get("/{id}/graph", relationHandler.Graph)
+			r.Get("/{id}/network", graphHandler.Network)
+			r.Get("/{id}/health", healthScoreHandler.Object)
+
 			// Object step routes
-			r.Post("/steps", wrapWithFeed(objStepHandler.Create))
+			r.Post("/steps", objStepHandler.Create)
 			r.Delete("/steps/{id}", objStepHandler.SoftDelete)
 			r.Delete("/steps/{id}/force", objStepHandler.HardDelete)
 			r.Put("/steps/{id}/sub-status", objStepHandler.UpdateSubStatus)
@@ -151,29 +196,56 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 			// Object Advanced routes
 			r.Get("/advanced", advancedObjectHandler.ListObjects)
 
+			// Bulk operations on selected objects
+			r.Post("/bulk", bulkOperationHandler.CreateBulkOperation)
+			r.Get("/bulk", bulkOperationHandler.ListBulkOperations)
+			r.Get("/bulk/{bulkId}", bulkOperationHandler.GetBulkOperation)
+
 			// Merge objects
-			r.Post("/merge", wrapWithFeed(mergeHandler.MergeObjects))
+			r.Post("/merge", mergeHandler.MergeObjects)
 		})
 
 		r.Route("/facts", func(r chi.Router) {
 			r.Post("/", factHandler.Create)
 			r.Get("/", factHandler.List)
+			r.Post("/mentions", factHandler.ResolveMentions)
+			r.Get("/kinds", factHandler.Kinds)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Put("/", factHandler.Update)
 				r.Delete("/", factHandler.Delete)
+				r.Post("/attachments", attachmentHandler.UploadToFact)
+				r.Get("/versions", factHandler.Versions)
+				r.Post("/versions/{version}/restore", factHandler.RestoreVersion)
+				r.Get("/comments", factHandler.Discussion)
+				r.Post("/comments", factHandler.CreateComment)
+				r.Put("/comments/{commentId}", factHandler.UpdateComment)
+				r.Delete("/comments/{commentId}", factHandler.DeleteComment)
+				r.Post("/reactions", factHandler.AddReaction)
+				r.Delete("/reactions", factHandler.RemoveReaction)
 			})
 		})
 
+		r.Route("/attachments", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/{id}", attachmentHandler.Download)
+			r.Delete("/{id}", attachmentHandler.Delete)
+		})
+
+		r.Route("/photos", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/{id}", photoHandler.Serve)
+		})
+
 		r.Route("/tasks", func(r chi.Router) {
 			r.Use(middleware.Permission)
 			// Only admin can access this route; later implement permission check
 			// r.Get("/", taskHandler.ListAllTasksInOrg)
 			r.Get("/", taskHandler.ListWithFilter)
-			r.Post("/", wrapWithFeed(taskHandler.Create))
+			r.Post("/", taskHandler.Create)
 			r.Get("/object/{objectID}", taskHandler.ListByObjectID)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Get("/", taskHandler.GetByID)
-				r.Put("/", wrapWithFeed(taskHandler.Update))
+				r.Put("/", taskHandler.Update)
 				r.Delete("/", taskHandler.Delete)
 			})
 		})
@@ -198,6 +270,24 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 			r.Post("/", importHandler.CreateImportTask)
 			r.Get("/status", importHandler.GetImportTaskStatus)
 			r.Get("/history", importHandler.GetImportHistory)
+			r.Post("/email", importHandler.CreateEmailImportTask)
+			r.Post("/calendar", importHandler.CreateCalendarImportTask)
+			r.Get("/reviews", importHandler.ListAddressReviews)
+			r.Post("/reviews/{id}/object", importHandler.CreateObjectFromReview)
+			r.Post("/reviews/{id}/dismiss", importHandler.DismissReview)
+		})
+
+		r.Route("/search", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", searchHandler.Search)
+		})
+
+		r.Route("/calendar-feeds", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", calendarFeedHandler.List)
+			r.Post("/", calendarFeedHandler.Create)
+			r.Delete("/{id}", calendarFeedHandler.Delete)
+			r.Post("/{id}/sync", calendarFeedHandler.Sync)
 		})
 
 		r.Route("/feeds", func(r chi.Router) {
@@ -205,9 +295,19 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 			// r.Get("/", feedHandler.ListFeeds)
 			// change to fact since feed logic is not clear
 			r.Get("/", factHandler.List)
+			// Unseen entries of watched objects, see /watches
+			r.Get("/unseen", feedHandler.ListFeeds)
 			r.Post("/seen", feedHandler.MarkFeedsAsSeen)
 		})
 
+		r.Route("/watches", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", watchHandler.List)
+			r.Post("/", watchHandler.Create)
+			r.Put("/{id}", watchHandler.Update)
+			r.Delete("/{id}", watchHandler.Delete)
+		})
+
 		r.Route("/summarize", func(r chi.Router) {
 			r.Use(middleware.Permission)
 			r.Get("/personal", summarizeHandler.PersonalSummarize)
@@ -215,6 +315,7 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 
 		r.Route("/external", func(r chi.Router) {
 			r.Use(middleware.Permission)
+			r.Use(middleware.Source(history.SourceExternalAPI))
 			r.Post("/facts", externalHandler.CreateFact)
 			r.Post("/type-values", externalHandler.UpsertObjectTypeValue)
 			r.Post("/tag-object", externalHandler.TagObject)
@@ -232,10 +333,29 @@ func SetupRouter(queries *database.Queries, db *sql.DB) *chi.Mux {
 			})
 		})
 
+		r.Route("/trash", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", trashHandler.List)
+			r.Post("/{kind}/{id}/restore", trashHandler.Restore)
+			r.Delete("/{kind}/{id}", trashHandler.Purge)
+		})
+
+		r.Route("/graph", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/path", graphHandler.Path)
+			r.Get("/export", graphHandler.Export)
+		})
+
 		r.Route("/gdp", func(r chi.Router) {
 			r.Use(middleware.Permission)
 			r.Get("/stats", gdpHandler.GetGDPStats)
 		})
+
+		r.Route("/mail-dropbox", func(r chi.Router) {
+			r.Use(middleware.Permission)
+			r.Get("/", mailDropboxHandler.Get)
+			r.Post("/rotate", mailDropboxHandler.Rotate)
+		})
 	})
 
 	return r
@@ -0,0 +1,59 @@
+// Package blob stores uploaded files under string keys.
+//
+// The local filesystem store is used by default. Setting BLOB_STORE=s3 keeps
+// the files in an S3-compatible bucket instead, see NewS3Store.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Info describes a stored blob
+type Info struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Store keeps blobs. Keys are slash separated paths such as
+// "photos/<id>/thumb.jpg".
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound when nothing is stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Delete does nothing when nothing is stored under key
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv returns the store configured by the environment:
+//
+//	BLOB_STORE   local (default) or s3
+//	BLOB_DIR     directory of the local store, "uploads" by default
+//	S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY
+func FromEnv() (Store, error) {
+	switch os.Getenv("BLOB_STORE") {
+	case "", "local":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir), nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", os.Getenv("BLOB_STORE"))
+	}
+}
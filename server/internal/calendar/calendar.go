@@ -0,0 +1,217 @@
+// Package calendar imports the events of iCalendar files as meeting facts.
+//
+// Each VEVENT that has started becomes a fact of kind meeting, happened at
+// its DTSTART, at its LOCATION and linked to the objects whose aliases or
+// email fields match its organizer and attendees. Occurrences of recurring
+// events are facts of their own. Facts are keyed by the UID of their event,
+// and the start of the occurrence for recurring ones, so importing a
+// calendar again only adds the events it did not have.
+//
+// Calendars are uploaded once, or subscribed to by URL: the task runner
+// syncs subscribed calendar feeds periodically, picking up the events that
+// started since. Recurring events are only expanded within a window around
+// the last sync, see Options.Since.
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// MaxCalendarSize bounds an iCalendar file, in bytes
+const MaxCalendarSize = 10 << 20
+
+var (
+	ErrCalendarTooLarge = fmt.Errorf("calendar is larger than %d MB", MaxCalendarSize>>20)
+	// ErrURL is returned for URLs that cannot be fetched: unsupported
+	// schemes, and hosts that are not public
+	ErrURL = errors.New("unsupported calendar URL")
+	// ErrFetch is returned when the server of a calendar cannot be reached
+	// or fails, without the details which would tell about the network
+	ErrFetch = errors.New("calendar could not be fetched")
+
+	errPrivateHost = errors.New("host is not public")
+)
+
+// Config is the calendar configuration read from the environment:
+//
+//	CALENDAR_ALLOW_PRIVATE_HOSTS   "true" to fetch calendars from loopback
+//	                               and private addresses, for development
+//	CALENDAR_FILE_ROOT             directory file:// calendar URLs are read
+//	                               from, nothing outside it is. Unset, local
+//	                               calendars must be uploaded.
+type Config struct {
+	AllowPrivateHosts bool
+	FileRoot          string
+}
+
+// ConfigFromEnv reads the calendar configuration
+func ConfigFromEnv() Config {
+	return Config{
+		AllowPrivateHosts: os.Getenv("CALENDAR_ALLOW_PRIVATE_HOSTS") == "true",
+		FileRoot:          os.Getenv("CALENDAR_FILE_ROOT"),
+	}
+}
+
+// client fetches calendars from public addresses only: the address is
+// checked once resolved, when dialing, so neither a DNS name nor a redirect
+// can lead to the internal network
+var client = newClient(publicOnly)
+
+// privateClient fetches calendars from any address
+var privateClient = newClient(nil)
+
+func newClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: control}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 20 * time.Second,
+		},
+	}
+}
+
+// publicOnly refuses to dial loopback, private, link-local and unspecified
+// addresses
+func publicOnly(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return errPrivateHost
+	}
+	return nil
+}
+
+// CheckURL returns an ErrURL when Fetch cannot fetch a calendar URL whatever
+// its host or file
+func (c Config) CheckURL(rawURL string) error {
+	_, err := c.parseURL(rawURL)
+	return err
+}
+
+func (c Config) parseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrURL, err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	case "file":
+		if c.FileRoot == "" {
+			return nil, fmt.Errorf("%w: local calendars must be uploaded", ErrURL)
+		}
+		if u.Host != "" && u.Host != "localhost" || !path.IsAbs(u.Path) {
+			return nil, fmt.Errorf("%w: file URL must be a local absolute path", ErrURL)
+		}
+		u.Scheme = "file"
+	default:
+		return nil, fmt.Errorf("%w: scheme %q", ErrURL, u.Scheme)
+	}
+	return u, nil
+}
+
+// Fetch returns the content of a calendar URL: http(s), webcal which is
+// https, or file under the FileRoot
+func (c Config) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := c.parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "file" {
+		return c.readFile(u.Path)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrURL, err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+	httpClient := client
+	if c.AllowPrivateHosts {
+		httpClient = privateClient
+	}
+	resp, err := httpClient.Do(req)
+	if errors.Is(err, errPrivateHost) {
+		return nil, fmt.Errorf("%w: %v", ErrURL, errPrivateHost)
+	}
+	if err != nil {
+		log.Printf("Error fetching calendar %s: %v", u.Redacted(), err)
+		return nil, ErrFetch
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrFetch, resp.Status)
+	}
+	return readLimited(resp.Body)
+}
+
+// readFile reads a local calendar. The path must lie under the FileRoot both
+// as written and once symbolic links are resolved, so that neither ".." nor
+// a link leads out of it.
+func (c Config) readFile(name string) ([]byte, error) {
+	root, err := filepath.Abs(c.FileRoot)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	name = filepath.Clean(filepath.FromSlash(name))
+	if !within(root, name) {
+		return nil, fmt.Errorf("%w: file is outside the calendar directory", ErrURL)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		log.Printf("Error resolving calendar directory %s: %v", root, err)
+		return nil, ErrFetch
+	}
+	realName, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: file not found", ErrFetch)
+	}
+	if !within(realRoot, realName) {
+		return nil, fmt.Errorf("%w: file is outside the calendar directory", ErrURL)
+	}
+	f, err := os.Open(realName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: file not found", ErrFetch)
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: not a file", ErrFetch)
+	}
+	return readLimited(f)
+}
+
+// within tells if the absolute, clean path name is dir or under it
+func within(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxCalendarSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxCalendarSize {
+		return nil, ErrCalendarTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchFile(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "calendars")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, "team.ics"), "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	write(filepath.Join(dir, "secret.ics"), "secret")
+	if err := os.Symlink(filepath.Join(dir, "secret.ics"), filepath.Join(root, "link.ics")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{FileRoot: root}
+	data, err := cfg.Fetch(context.Background(), "file://"+filepath.ToSlash(filepath.Join(root, "team.ics")))
+	if err != nil || string(data) != "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n" {
+		t.Errorf("Fetch = %q, %v", data, err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		url  string
+		err  error
+	}{
+		{"no root", Config{}, "file://" + filepath.ToSlash(filepath.Join(root, "team.ics")), ErrURL},
+		{"outside", cfg, "file://" + filepath.ToSlash(filepath.Join(dir, "secret.ics")), ErrURL},
+		{"dot dot", cfg, "file://" + filepath.ToSlash(root) + "/../secret.ics", ErrURL},
+		{"symlink out", cfg, "file://" + filepath.ToSlash(filepath.Join(root, "link.ics")), ErrURL},
+		{"relative", cfg, "file:team.ics", ErrURL},
+		{"remote host", cfg, "file://example.com" + filepath.ToSlash(filepath.Join(root, "team.ics")), ErrURL},
+		{"missing", cfg, "file://" + filepath.ToSlash(filepath.Join(root, "nope.ics")), ErrFetch},
+		{"directory", cfg, "file://" + filepath.ToSlash(root), ErrFetch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.cfg.Fetch(context.Background(), tt.url)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Fetch(%q) = %q, %v, want %v", tt.url, data, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://example.com/team.ics", true},
+		{"webcal://example.com/team.ics", true},
+		{"file:///srv/calendars/team.ics", false},
+		{"ftp://example.com/team.ics", false},
+	}
+	for _, tt := range tests {
+		err := Config{}.CheckURL(tt.url)
+		if (err == nil) != tt.ok || err != nil && !errors.Is(err, ErrURL) {
+			t.Errorf("CheckURL(%q) = %v", tt.url, err)
+		}
+	}
+	if err := (Config{FileRoot: "/srv/calendars"}).CheckURL("file:///srv/calendars/team.ics"); err != nil {
+		t.Errorf("CheckURL of a file with a root = %v", err)
+	}
+}
@@ -0,0 +1,519 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds the occurrences expanded from a recurring event
+// within a window
+const maxOccurrences = 500
+
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+// Attendee is an attendee, or the organizer, of an event
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+// Event is a VEVENT of a calendar
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	Start       time.Time
+	End         time.Time
+	// AllDay is set for events with a date and no time
+	AllDay    bool
+	Organizer *Attendee
+	Attendees []Attendee
+	// RecurrenceID is the start of the occurrence of a recurring event this
+	// one overrides
+	RecurrenceID time.Time
+	Rule         *Rule
+	ExDates      []time.Time
+
+	// duration is the DURATION of an event without DTEND, which may come
+	// before DTSTART
+	duration time.Duration
+}
+
+// Cancelled reports whether the event was cancelled
+func (e *Event) Cancelled() bool {
+	return strings.EqualFold(e.Status, "CANCELLED")
+}
+
+// Duration is the length of the event, zero when it has no end
+func (e *Event) Duration() time.Duration {
+	if e.End.After(e.Start) {
+		return e.End.Sub(e.Start)
+	}
+	return 0
+}
+
+// Addresses returns the lower cased emails of the organizer and attendees,
+// each once
+func (e *Event) Addresses() []string {
+	seen := map[string]bool{}
+	var addresses []string
+	people := e.Attendees
+	if e.Organizer != nil {
+		people = append([]Attendee{*e.Organizer}, people...)
+	}
+	for _, a := range people {
+		email := strings.ToLower(a.Email)
+		if email != "" && !seen[email] {
+			seen[email] = true
+			addresses = append(addresses, email)
+		}
+	}
+	return addresses
+}
+
+// DisplayName returns the name given with an email of the event
+func (e *Event) DisplayName(email string) string {
+	if e.Organizer != nil && e.Organizer.Name != "" && strings.EqualFold(e.Organizer.Email, email) {
+		return e.Organizer.Name
+	}
+	for _, a := range e.Attendees {
+		if a.Name != "" && strings.EqualFold(a.Email, email) {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// Rule is the part of an RRULE supported: a frequency with an interval,
+// ended by a count or a date, and the days of weekly rules
+type Rule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// property is a content line: NAME;PARAM=VALUE:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of an iCalendar file (RFC 5545)
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	var current *Event
+	depth := 0
+	sawCalendar := false
+	for _, line := range lines {
+		p, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch p.name {
+		case "BEGIN":
+			depth++
+			switch strings.ToUpper(p.value) {
+			case "VCALENDAR":
+				sawCalendar = true
+			case "VEVENT":
+				current = &Event{}
+			}
+			continue
+		case "END":
+			depth--
+			if strings.EqualFold(p.value, "VEVENT") && current != nil {
+				if current.End.IsZero() && current.duration > 0 {
+					current.End = current.Start.Add(current.duration)
+				}
+				if current.UID != "" && !current.Start.IsZero() {
+					events = append(events, *current)
+				}
+				current = nil
+			}
+			continue
+		}
+		// Properties of alarms and other components nested in the event are
+		// not the event's
+		if current == nil || depth != 2 {
+			continue
+		}
+		if err := current.set(p); err != nil {
+			return nil, fmt.Errorf("event %s: %w", current.UID, err)
+		}
+	}
+	if !sawCalendar {
+		return nil, ErrNotCalendar
+	}
+	return events, nil
+}
+
+func (e *Event) set(p property) error {
+	var err error
+	switch p.name {
+	case "UID":
+		e.UID = p.value
+	case "SUMMARY":
+		e.Summary = unescape(p.value)
+	case "DESCRIPTION":
+		e.Description = unescape(p.value)
+	case "LOCATION":
+		e.Location = unescape(p.value)
+	case "STATUS":
+		e.Status = p.value
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseTime(p)
+	case "DTEND":
+		e.End, _, err = parseTime(p)
+	case "DURATION":
+		e.duration, err = parseDuration(p.value)
+	case "RECURRENCE-ID":
+		e.RecurrenceID, _, err = parseTime(p)
+	case "ORGANIZER":
+		a := attendee(p)
+		e.Organizer = &a
+	case "ATTENDEE":
+		if a := attendee(p); a.Email != "" {
+			e.Attendees = append(e.Attendees, a)
+		}
+	case "RRULE":
+		e.Rule, err = parseRule(p.value)
+	case "EXDATE":
+		for _, value := range strings.Split(p.value, ",") {
+			t, _, err := parseTime(property{name: p.name, params: p.params, value: value})
+			if err != nil {
+				return err
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	}
+	return err
+}
+
+// unfold reads the content lines, joining those folded on the next lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line, parameter values may be quoted
+func parseLine(line string) (property, bool) {
+	p := property{params: map[string]string{}}
+	i, inQuotes := 0, false
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if !inQuotes && c == ':' {
+			break
+		}
+	}
+	if i == len(line) {
+		return p, false
+	}
+	head, value := line[:i], line[i+1:]
+	parts := splitUnquoted(head, ';')
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, v, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(key)] = strings.Trim(v, `"`)
+	}
+	p.value = value
+	return p, true
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	start, inQuotes := 0, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case sep:
+			if !inQuotes {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var textUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescape(s string) string {
+	return strings.TrimSpace(textUnescaper.Replace(s))
+}
+
+func attendee(p property) Attendee {
+	email := p.value
+	if len(email) >= 7 && strings.EqualFold(email[:7], "mailto:") {
+		email = email[7:]
+	}
+	if !strings.Contains(email, "@") {
+		email = ""
+	}
+	return Attendee{Name: p.params["CN"], Email: strings.TrimSpace(email)}
+}
+
+// parseTime reads a DATE or DATE-TIME value, in UTC, its TZID or local
+// floating time read as UTC. Unknown time zones are read as UTC.
+func parseTime(p property) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, location(p.params["TZID"]))
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location(p.params["TZID"]))
+	return t, false, err
+}
+
+func location(tzid string) *time.Location {
+	if tzid == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseDuration reads a DURATION value such as PT1H30M or P1D
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			num += string(c)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			num = ""
+			switch {
+			case c == 'W':
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case c == 'D':
+				d += time.Duration(n) * 24 * time.Hour
+			case c == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case c == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case c == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+		}
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRule(value string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(v)
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(v)
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(v)
+		case "UNTIL":
+			// UNTIL is in UTC unless DTSTART is a date or floating time
+			rule.Until, _, err = parseTime(property{params: map[string]string{}, value: v})
+		case "BYDAY":
+			for _, day := range strings.Split(v, ",") {
+				// Ordinals such as 1MO of monthly rules are not supported,
+				// the occurrences then follow DTSTART
+				if wd, ok := weekdays[strings.ToUpper(day)]; ok {
+					rule.ByDay = append(rule.ByDay, wd)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q", value)
+		}
+	}
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		// Other frequencies are read as a single event
+		return nil, nil
+	}
+	if rule.Interval < 1 {
+		rule.Interval = 1
+	}
+	return rule, nil
+}
+
+// Occurrences returns the starts of the occurrences of the event from from
+// up to until, in order, without its EXDATEs. An event without rule occurs
+// once, whatever from is.
+func (e *Event) Occurrences(from, until time.Time) []time.Time {
+	if e.Rule == nil {
+		if e.Start.After(until) {
+			return nil
+		}
+		return []time.Time{e.Start}
+	}
+	excluded := map[int64]bool{}
+	for _, t := range e.ExDates {
+		excluded[t.Unix()] = true
+	}
+	rule := e.Rule
+	var starts []time.Time
+	count := 0
+	add := func(t time.Time) bool {
+		if t.Before(e.Start) {
+			return true
+		}
+		if t.After(until) || (!rule.Until.IsZero() && t.After(rule.Until)) {
+			return false
+		}
+		count++
+		if rule.Count > 0 && count > rule.Count {
+			return false
+		}
+		if t.Before(from) {
+			return true
+		}
+		if !excluded[t.Unix()] {
+			starts = append(starts, t)
+		}
+		return len(starts) < maxOccurrences
+	}
+
+	// Periods before the window are skipped, unless the occurrences must be
+	// counted from DTSTART for COUNT
+	first := 0
+	if rule.Count == 0 && from.After(e.Start) {
+		first = rule.periodsBefore(e.Start, from)
+	}
+	for period := first; ; period++ {
+		var base time.Time
+		switch rule.Freq {
+		case "DAILY":
+			base = e.Start.AddDate(0, 0, period*rule.Interval)
+		case "WEEKLY":
+			base = e.Start.AddDate(0, 0, 7*period*rule.Interval)
+		case "MONTHLY":
+			base = e.Start.AddDate(0, period*rule.Interval, 0)
+			// Months without the day of DTSTART are skipped, as RFC 5545 asks
+			if base.Day() != e.Start.Day() {
+				continue
+			}
+		case "YEARLY":
+			base = e.Start.AddDate(period*rule.Interval, 0, 0)
+			if base.Day() != e.Start.Day() {
+				continue
+			}
+		}
+		if base.After(until) || (!rule.Until.IsZero() && base.After(rule.Until)) {
+			return starts
+		}
+		if rule.Freq == "WEEKLY" && len(rule.ByDay) > 0 {
+			// The days of the week of base, from its Monday as RFC 5545's
+			// default WKST
+			monday := base.AddDate(0, 0, -((int(base.Weekday()) + 6) % 7))
+			for offset := 0; offset < 7; offset++ {
+				day := monday.AddDate(0, 0, offset)
+				if !hasWeekday(rule.ByDay, day.Weekday()) {
+					continue
+				}
+				if !add(day) {
+					return starts
+				}
+			}
+			continue
+		}
+		if !add(base) {
+			return starts
+		}
+	}
+}
+
+// periodsBefore returns a number of periods of the rule from start that all
+// end before t, leaving one spare for a period starting on another weekday
+func (r *Rule) periodsBefore(start, t time.Time) int {
+	var periods int
+	switch r.Freq {
+	case "DAILY":
+		periods = int(t.Sub(start).Hours()/24) / r.Interval
+	case "WEEKLY":
+		periods = int(t.Sub(start).Hours()/24/7) / r.Interval
+	case "MONTHLY":
+		periods = ((t.Year()-start.Year())*12 + int(t.Month()-start.Month())) / r.Interval
+	case "YEARLY":
+		periods = (t.Year() - start.Year()) / r.Interval
+	}
+	return max(periods-1, 0)
+}
+
+func hasWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCalendar reports whether data looks like an iCalendar file
+func IsCalendar(data []byte) bool {
+	return bytes.Contains(bytes.ToUpper(data[:min(len(data), 1024)]), []byte("BEGIN:VCALENDAR"))
+}
@@ -0,0 +1,355 @@
+package calendar
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1@example.com\r\n" +
+	"DTSTART;TZID=Asia/Ho_Chi_Minh:20240105T090000\r\n" +
+	"DTEND;TZID=Asia/Ho_Chi_Minh:20240105T100000\r\n" +
+	"SUMMARY:Intro call\\, with Labs\r\n" +
+	"DESCRIPTION:Line one\\nLine two that is fol\r\n" +
+	" ded\r\n" +
+	"LOCATION:Room 1\\; floor 2\r\n" +
+	"ORGANIZER;CN=\"Doe: Jane\":mailto:Jane@Example.com\r\n" +
+	"ATTENDEE;CN=Bob;ROLE=REQ-PARTICIPANT:MAILTO:bob@example.com\r\n" +
+	"ATTENDEE;CN=Room:room-1\r\n" +
+	"ATTENDEE:mailto:jane@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20240106T090000Z\r\n" +
+	"SUMMARY:No UID\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20240110\r\n" +
+	"DURATION:P1D\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,we;COUNT=4\r\n" +
+	"EXDATE:20240117,20240124\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func utc(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Parse returned %d events, want 2", len(events))
+	}
+
+	meeting := events[0]
+	if meeting.UID != "1@example.com" {
+		t.Errorf("UID = %q", meeting.UID)
+	}
+	if !meeting.Start.Equal(utc(2024, 1, 5, 2)) || !meeting.End.Equal(utc(2024, 1, 5, 3)) {
+		t.Errorf("Start, End = %v, %v", meeting.Start, meeting.End)
+	}
+	if meeting.AllDay || meeting.Duration() != time.Hour {
+		t.Errorf("AllDay, Duration = %v, %v", meeting.AllDay, meeting.Duration())
+	}
+	if meeting.Summary != "Intro call, with Labs" {
+		t.Errorf("Summary = %q", meeting.Summary)
+	}
+	if meeting.Description != "Line one\nLine two that is folded" {
+		t.Errorf("Description = %q", meeting.Description)
+	}
+	if meeting.Location != "Room 1; floor 2" {
+		t.Errorf("Location = %q", meeting.Location)
+	}
+	if meeting.Organizer == nil || *meeting.Organizer != (Attendee{Name: "Doe: Jane", Email: "Jane@Example.com"}) {
+		t.Errorf("Organizer = %+v", meeting.Organizer)
+	}
+	wantAttendees := []Attendee{{Name: "Bob", Email: "bob@example.com"}, {Email: "jane@example.com"}}
+	if !reflect.DeepEqual(meeting.Attendees, wantAttendees) {
+		t.Errorf("Attendees = %+v, want %+v", meeting.Attendees, wantAttendees)
+	}
+	if got := meeting.Addresses(); !reflect.DeepEqual(got, []string{"jane@example.com", "bob@example.com"}) {
+		t.Errorf("Addresses = %v", got)
+	}
+	if got := meeting.DisplayName("JANE@example.com"); got != "Doe: Jane" {
+		t.Errorf("DisplayName = %q", got)
+	}
+	if meeting.Cancelled() || meeting.Rule != nil {
+		t.Errorf("Cancelled, Rule = %v, %+v", meeting.Cancelled(), meeting.Rule)
+	}
+
+	recurring := events[1]
+	if !recurring.AllDay || !recurring.Start.Equal(utc(2024, 1, 10, 0)) || recurring.Duration() != 24*time.Hour {
+		t.Errorf("AllDay, Start, Duration = %v, %v, %v", recurring.AllDay, recurring.Start, recurring.Duration())
+	}
+	if !recurring.Cancelled() {
+		t.Error("Cancelled = false")
+	}
+	wantRule := &Rule{Freq: "WEEKLY", Interval: 1, Count: 4, ByDay: []time.Weekday{time.Monday, time.Wednesday}}
+	if !reflect.DeepEqual(recurring.Rule, wantRule) {
+		t.Errorf("Rule = %+v, want %+v", recurring.Rule, wantRule)
+	}
+	if !reflect.DeepEqual(recurring.ExDates, []time.Time{utc(2024, 1, 17, 0), utc(2024, 1, 24, 0)}) {
+		t.Errorf("ExDates = %v", recurring.ExDates)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{
+			name: "not a calendar",
+			text: "BEGIN:VCARD\nFN:Jane\nEND:VCARD\n",
+			err:  ErrNotCalendar.Error(),
+		},
+		{
+			name: "invalid date",
+			text: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nDTSTART:2024-01-05\nEND:VEVENT\nEND:VCALENDAR\n",
+			err:  `event x: parsing time "2024-01-05" as "20060102T150405": cannot parse "-01-05" as "01"`,
+		},
+		{
+			name: "invalid rule",
+			text: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nRRULE:FREQ=DAILY;COUNT=many\nEND:VEVENT\nEND:VCALENDAR\n",
+			err:  `event x: invalid RRULE "FREQ=DAILY;COUNT=many"`,
+		},
+		{
+			name: "invalid duration",
+			text: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nDURATION:1H\nEND:VEVENT\nEND:VCALENDAR\n",
+			err:  `event x: invalid duration "1H"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.text))
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Parse error = %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseNotCalendar(t *testing.T) {
+	_, err := Parse(strings.NewReader("hello"))
+	if !errors.Is(err, ErrNotCalendar) {
+		t.Errorf("Parse error = %v, want ErrNotCalendar", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		err   bool
+	}{
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "P2W", want: 14 * 24 * time.Hour},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "pt45s", want: 45 * time.Second},
+		{value: "-PT15M", want: -15 * time.Minute},
+		{value: "+PT5M", want: 5 * time.Minute},
+		{value: "1H", err: true},
+		{value: "P1H", err: true},
+		{value: "PT1X", err: true},
+		{value: "PTH", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *Rule
+	}{
+		{
+			value: "FREQ=DAILY;INTERVAL=2;COUNT=5",
+			want:  &Rule{Freq: "DAILY", Interval: 2, Count: 5},
+		},
+		{
+			value: "freq=monthly;until=20240301T000000Z",
+			want:  &Rule{Freq: "MONTHLY", Interval: 1, Until: utc(2024, 3, 1, 0)},
+		},
+		{
+			value: "FREQ=WEEKLY;INTERVAL=0;BYDAY=1MO,FR",
+			want:  &Rule{Freq: "WEEKLY", Interval: 1, ByDay: []time.Weekday{time.Friday}},
+		},
+		{
+			value: "FREQ=HOURLY;COUNT=3",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseRule(tt.value)
+		if err != nil {
+			t.Errorf("parseRule(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRule(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	// 2024-01-01 is a Monday
+	start := utc(2024, 1, 1, 9)
+	tests := []struct {
+		name    string
+		start   time.Time
+		rule    string
+		exDates []time.Time
+		from    time.Time
+		until   time.Time
+		want    []time.Time
+	}{
+		{
+			name:  "single event",
+			start: start,
+			from:  utc(2024, 6, 1, 0),
+			until: utc(2024, 12, 1, 0),
+			want:  []time.Time{start},
+		},
+		{
+			name:  "single event after the window",
+			start: start,
+			from:  utc(2023, 1, 1, 0),
+			until: utc(2023, 12, 1, 0),
+			want:  nil,
+		},
+		{
+			name:  "daily count",
+			start: start,
+			rule:  "FREQ=DAILY;COUNT=3",
+			from:  start,
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{start, utc(2024, 1, 2, 9), utc(2024, 1, 3, 9)},
+		},
+		{
+			name:    "interval and exdate",
+			start:   start,
+			rule:    "FREQ=DAILY;INTERVAL=2",
+			exDates: []time.Time{utc(2024, 1, 3, 9)},
+			from:    start,
+			until:   utc(2024, 1, 7, 9),
+			want:    []time.Time{start, utc(2024, 1, 5, 9), utc(2024, 1, 7, 9)},
+		},
+		{
+			name:  "until",
+			start: start,
+			rule:  "FREQ=DAILY;UNTIL=20240103T090000Z",
+			from:  start,
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{start, utc(2024, 1, 2, 9), utc(2024, 1, 3, 9)},
+		},
+		{
+			name:  "weekly days",
+			start: start,
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			from:  start,
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{start, utc(2024, 1, 3, 9), utc(2024, 1, 8, 9), utc(2024, 1, 10, 9)},
+		},
+		{
+			name:  "weekly days before the start",
+			start: utc(2024, 1, 3, 9),
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=2",
+			from:  start,
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{utc(2024, 1, 3, 9), utc(2024, 1, 8, 9)},
+		},
+		{
+			name:  "months without the day",
+			start: utc(2024, 1, 31, 9),
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			from:  start,
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{utc(2024, 1, 31, 9), utc(2024, 3, 31, 9), utc(2024, 5, 31, 9)},
+		},
+		{
+			name:  "leap days",
+			start: utc(2024, 2, 29, 9),
+			rule:  "FREQ=YEARLY",
+			from:  start,
+			until: utc(2029, 1, 1, 0),
+			want:  []time.Time{utc(2024, 2, 29, 9), utc(2028, 2, 29, 9)},
+		},
+		{
+			name:  "window",
+			start: start,
+			rule:  "FREQ=DAILY",
+			from:  utc(2024, 1, 10, 9),
+			until: utc(2024, 1, 12, 9),
+			want:  []time.Time{utc(2024, 1, 10, 9), utc(2024, 1, 11, 9), utc(2024, 1, 12, 9)},
+		},
+		{
+			name:  "count from the start",
+			start: start,
+			rule:  "FREQ=DAILY;COUNT=5",
+			from:  utc(2024, 1, 3, 9),
+			until: utc(2025, 1, 1, 0),
+			want:  []time.Time{utc(2024, 1, 3, 9), utc(2024, 1, 4, 9), utc(2024, 1, 5, 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{UID: "x", Start: tt.start, ExDates: tt.exDates}
+			if tt.rule != "" {
+				rule, err := parseRule(tt.rule)
+				if err != nil {
+					t.Fatalf("parseRule(%q) error: %v", tt.rule, err)
+				}
+				event.Rule = rule
+			}
+			got := event.Occurrences(tt.from, tt.until)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesBound(t *testing.T) {
+	event := Event{UID: "x", Start: utc(2024, 1, 1, 9), Rule: &Rule{Freq: "DAILY", Interval: 1}}
+	got := event.Occurrences(event.Start, utc(2030, 1, 1, 0))
+	if len(got) != maxOccurrences {
+		t.Errorf("Occurrences returned %d starts, want %d", len(got), maxOccurrences)
+	}
+}
+
+func TestIsCalendar(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"BEGIN:VCALENDAR\r\nEND:VCALENDAR", true},
+		{"\ufeffbegin:vcalendar\n", true},
+		{"BEGIN:VCARD", false},
+		{strings.Repeat(" ", 1024) + "BEGIN:VCALENDAR", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsCalendar([]byte(tt.data)); got != tt.want {
+			t.Errorf("IsCalendar(%.20q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
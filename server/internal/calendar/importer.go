@@ -0,0 +1,396 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/crea8r/muninn/server/internal/watch"
+	"github.com/google/uuid"
+)
+
+// FactSource is the fact_source.source of facts made from calendar events,
+// keyed by UID
+const FactSource = "calendar"
+
+// maxDescription bounds the event description kept in the fact, in
+// characters
+const maxDescription = 1000
+
+const (
+	// importLookback is how far back the occurrences of recurring events are
+	// imported on the first import of a calendar
+	importLookback = 365 * 24 * time.Hour
+	// syncLookback is how far before the last sync they are imported again,
+	// for events added late to a calendar
+	syncLookback = 7 * 24 * time.Hour
+	// upcomingHorizon is how far ahead they are counted as upcoming
+	upcomingHorizon = 30 * 24 * time.Hour
+)
+
+// Importer makes meeting facts of calendar events
+type Importer struct {
+	cfg     Config
+	queries *database.Queries
+	db      *sql.DB
+}
+
+func NewImporter(cfg Config, queries *database.Queries, db *sql.DB) *Importer {
+	return &Importer{cfg: cfg, queries: queries, db: db}
+}
+
+// Options says whose a calendar is
+type Options struct {
+	OrgID uuid.UUID
+	// CreatorID creates the facts of events not organized by a member
+	CreatorID uuid.UUID
+	// ImportTaskID is the import the calendar is imported by, if any
+	ImportTaskID uuid.NullUUID
+	// Since is the last sync of a feed: recurring events are expanded from
+	// syncLookback before it, or importLookback before now when it is zero
+	Since time.Time
+}
+
+// Result is what became of the events of a calendar
+type Result struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	// Upcoming counts the events that have not started, and the occurrences
+	// of recurring ones within upcomingHorizon, imported by a later sync
+	// once they have
+	Upcoming  int `json:"upcoming"`
+	Cancelled int `json:"cancelled"`
+	// Unmatched counts the attendee addresses matching no object nor
+	// member, queued for review
+	Unmatched int `json:"unmatched"`
+}
+
+// occurrence is an occurrence of an event to import
+type occurrence struct {
+	event *Event
+	start time.Time
+	key   string
+}
+
+// occurrenceKey keys the facts of an event, by UID and, for recurring
+// events, the start of the occurrence
+func occurrenceKey(e *Event, start time.Time, recurring bool) string {
+	if !recurring {
+		return e.UID
+	}
+	return e.UID + "/" + start.UTC().Format("20060102T150405Z")
+}
+
+// Import makes a meeting fact of each occurrence of the events that has
+// started, unless it was already imported
+func (im *Importer) Import(ctx context.Context, events []Event, opts Options) (Result, error) {
+	now := time.Now()
+	var result Result
+	from := now.Add(-importLookback)
+	if !opts.Since.IsZero() {
+		from = opts.Since.Add(-syncLookback)
+	}
+
+	// Occurrences overridden by an event of their own, by UID
+	overridden := map[string]map[int64]bool{}
+	for _, e := range events {
+		if !e.RecurrenceID.IsZero() {
+			if overridden[e.UID] == nil {
+				overridden[e.UID] = map[int64]bool{}
+			}
+			overridden[e.UID][e.RecurrenceID.Unix()] = true
+		}
+	}
+
+	var occurrences []occurrence
+	for i := range events {
+		e := &events[i]
+		if e.Cancelled() {
+			result.Cancelled++
+			continue
+		}
+		if !e.RecurrenceID.IsZero() {
+			if e.Start.After(now) {
+				result.Upcoming++
+				continue
+			}
+			occurrences = append(occurrences, occurrence{event: e, start: e.Start, key: occurrenceKey(e, e.RecurrenceID, true)})
+			continue
+		}
+		if e.Rule == nil && e.Start.After(now) {
+			result.Upcoming++
+			continue
+		}
+		for _, start := range e.Occurrences(from, now.Add(upcomingHorizon)) {
+			if overridden[e.UID][start.Unix()] {
+				continue
+			}
+			if start.After(now) {
+				result.Upcoming++
+				continue
+			}
+			occurrences = append(occurrences, occurrence{event: e, start: start, key: occurrenceKey(e, start, e.Rule != nil)})
+		}
+	}
+
+	for _, o := range occurrences {
+		imported, unmatched, err := im.importOccurrence(ctx, o, opts)
+		if err != nil {
+			return result, fmt.Errorf("error importing event %s: %w", o.event.UID, err)
+		}
+		if imported {
+			result.Imported++
+		} else {
+			result.Duplicates++
+		}
+		result.Unmatched += unmatched
+	}
+	return result, nil
+}
+
+// importOccurrence makes the fact of an occurrence. It returns false when
+// the occurrence was already imported, and the number of addresses queued
+// for review.
+func (im *Importer) importOccurrence(ctx context.Context, o occurrence, opts Options) (bool, int, error) {
+	_, err := im.queries.GetFactSource(ctx, database.GetFactSourceParams{
+		OrgID:      opts.OrgID,
+		Source:     FactSource,
+		ExternalID: o.key,
+	})
+	if err == nil {
+		return false, 0, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, 0, err
+	}
+	e := o.event
+
+	// Members are neither linked nor queued, the organizer one creates the
+	// fact
+	addresses := e.Addresses()
+	members := map[string]bool{}
+	creatorID := opts.CreatorID
+	for _, address := range addresses {
+		id, err := im.queries.FindCreatorByEmail(ctx, database.FindCreatorByEmailParams{
+			OrgID:   opts.OrgID,
+			Column2: address,
+		})
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		members[address] = true
+		if e.Organizer != nil && strings.EqualFold(e.Organizer.Email, address) {
+			creatorID = id
+		}
+	}
+
+	var objectIDs []uuid.UUID
+	var unmatched []string
+	if len(addresses) > 0 {
+		objects, err := im.queries.ListObjectsByEmail(ctx, database.ListObjectsByEmailParams{
+			OrgID:   opts.OrgID,
+			Column2: addresses,
+		})
+		if err != nil {
+			return false, 0, err
+		}
+		matched := map[string]bool{}
+		linked := map[uuid.UUID]bool{}
+		for _, obj := range objects {
+			matched[obj.Email] = true
+			if !linked[obj.ID] {
+				linked[obj.ID] = true
+				objectIDs = append(objectIDs, obj.ID)
+			}
+		}
+		for _, address := range addresses {
+			if !matched[address] && !members[address] {
+				unmatched = append(unmatched, address)
+			}
+		}
+	}
+
+	metadata, err := meetingMetadata(e, objectIDs)
+	if err != nil {
+		return false, 0, err
+	}
+
+	tx, err := im.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+	qtx := im.queries.WithTx(tx)
+
+	fact, err := qtx.CreateFact(ctx, database.CreateFactParams{
+		Text:       factText(e),
+		HappenedAt: sql.NullTime{Time: o.start, Valid: true},
+		Location:   e.Location,
+		CreatorID:  creatorID,
+		Kind:       factkind.Meeting,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if len(objectIDs) > 0 {
+		if err := qtx.AddObjectsToFact(ctx, database.AddObjectsToFactParams{
+			Column1: objectIDs,
+			FactID:  fact.ID,
+			OrgID:   opts.OrgID,
+		}); err != nil {
+			return false, 0, err
+		}
+	}
+	n, err := qtx.CreateFactSource(ctx, database.CreateFactSourceParams{
+		OrgID:      opts.OrgID,
+		Source:     FactSource,
+		ExternalID: o.key,
+		FactID:     fact.ID,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if n == 0 {
+		// Imported meanwhile by another sync of the same calendar
+		return false, 0, nil
+	}
+	if err := history.RecordFactFrom(ctx, qtx, history.SourceImport, creatorID, fact.ID, history.ActionCreated); err != nil {
+		return false, 0, err
+	}
+	if err := watch.Notify(ctx, qtx, creatorID, watch.Event{
+		Kind:    watch.EventFact,
+		Action:  watch.ActionCreated,
+		ObjIDs:  objectIDs,
+		RefID:   fact.ID,
+		Summary: fact.Text,
+	}); err != nil {
+		return false, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+
+	for _, address := range unmatched {
+		if err := service.QueueAddressReview(ctx, im.queries, opts.OrgID, opts.ImportTaskID, fact.ID, address, e.DisplayName(address)); err != nil {
+			return true, 0, err
+		}
+	}
+	return true, len(unmatched), nil
+}
+
+// meetingMetadata is the duration of the event, in minutes, and the objects
+// attending it
+func meetingMetadata(e *Event, objectIDs []uuid.UUID) (json.RawMessage, error) {
+	kind, err := factkind.Get(factkind.Meeting)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if d := e.Duration(); d > 0 && !e.AllDay {
+		values["duration"] = d.Minutes()
+	}
+	if len(objectIDs) > 0 {
+		attendees := make([]string, len(objectIDs))
+		for i, id := range objectIDs {
+			attendees[i] = id.String()
+		}
+		values["attendees"] = attendees
+	}
+	raw, _ := json.Marshal(values)
+	return kind.Validate(raw)
+}
+
+// factText is the summary of an event followed by its description
+func factText(e *Event) string {
+	description := e.Description
+	if utf8.RuneCountInString(description) > maxDescription {
+		description = strings.TrimSpace(string([]rune(description)[:maxDescription])) + "…"
+	}
+	summary := e.Summary
+	if summary == "" {
+		summary = "(no title)"
+	}
+	if description == "" {
+		return summary
+	}
+	return summary + "\n\n" + description
+}
+
+// ImportData imports the events of an iCalendar file
+func (im *Importer) ImportData(ctx context.Context, data []byte, opts Options) (Result, error) {
+	events, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+	return im.Import(ctx, events, opts)
+}
+
+// Fetch returns the content of a calendar URL, see Config.Fetch
+func (im *Importer) Fetch(ctx context.Context, url string) ([]byte, error) {
+	return im.cfg.Fetch(ctx, url)
+}
+
+// CheckURL tells if a calendar URL can be fetched, see Config.CheckURL
+func (im *Importer) CheckURL(url string) error {
+	return im.cfg.CheckURL(url)
+}
+
+// SyncFeed imports the events of a subscribed calendar that were not yet,
+// and records when it was last synced and how it failed
+func (im *Importer) SyncFeed(ctx context.Context, feed database.CalendarFeed) (Result, error) {
+	data, err := im.cfg.Fetch(ctx, feed.Url)
+	var result Result
+	if err == nil {
+		result, err = im.ImportData(ctx, data, Options{
+			OrgID:     feed.OrgID,
+			CreatorID: feed.CreatorID,
+			Since:     feed.LastSyncedAt.Time,
+		})
+	}
+	lastError := sql.NullString{}
+	if err != nil {
+		lastError = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if updateErr := im.queries.UpdateCalendarFeedSync(ctx, database.UpdateCalendarFeedSyncParams{
+		ID:        feed.ID,
+		LastError: lastError,
+	}); updateErr != nil {
+		log.Printf("Error recording sync of calendar feed %s: %v", feed.ID, updateErr)
+	}
+	return result, err
+}
+
+// SyncAll syncs every subscribed calendar and returns the facts imported.
+// A feed failing does not stop the others, its error is kept on the feed.
+func (im *Importer) SyncAll(ctx context.Context) (int, error) {
+	feeds, err := im.queries.ListAllCalendarFeeds(ctx)
+	if err != nil {
+		return 0, err
+	}
+	imported := 0
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return imported, ctx.Err()
+		}
+		result, err := im.SyncFeed(ctx, feed)
+		imported += result.Imported
+		if err != nil {
+			log.Printf("Error syncing calendar feed %s: %v", feed.ID, err)
+		}
+	}
+	return imported, nil
+}
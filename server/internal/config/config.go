@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,8 +12,14 @@ import (
 type Config struct {
 	DatabaseURL string
 	JWTSecret   string
+	// TrashRetention is how long deleted items stay in the trash before they
+	// are purged, TRASH_RETENTION_DAYS (30 by default). 0 keeps them until
+	// they are purged by hand.
+	TrashRetention time.Duration
 }
 
+const defaultTrashRetentionDays = 30
+
 func Load() (*Config, error) {
 	_ = godotenv.Load() // Ignore error if .env file is missing
 	
@@ -23,8 +31,17 @@ func Load() (*Config, error) {
 	if jwtSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET environment variable is not set")
 	}
+	retentionDays := defaultTrashRetentionDays
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		var err error
+		retentionDays, err = strconv.Atoi(days)
+		if err != nil || retentionDays < 0 {
+			return nil, fmt.Errorf("TRASH_RETENTION_DAYS must be a number of days, 0 to never purge")
+		}
+	}
 	return &Config{
-		DatabaseURL: dbURL,
-		JWTSecret:   jwtSecret,
+		DatabaseURL:    dbURL,
+		JWTSecret:      jwtSecret,
+		TrashRetention: time.Duration(retentionDays) * 24 * time.Hour,
 	}, nil
 }
\ No newline at end of file
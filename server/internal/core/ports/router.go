@@ -1,11 +1,9 @@
 package ports
 
 import (
-	"net/http"
-
 	"github.com/go-chi/chi/v5"
 )
 
 type RouterRegistrar interface {
-	RegisterRoutes(r chi.Router, wrapWithFeed func(http.HandlerFunc) http.HandlerFunc)
+	RegisterRoutes(r chi.Router)
 }
\ No newline at end of file
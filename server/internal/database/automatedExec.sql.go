@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 
 	"github.com/google/uuid"
@@ -26,6 +27,7 @@ first_step AS (
 object_data AS (
     SELECT 
         o.id, 
+        o.created_at,
         array_agg(DISTINCT os.step_id) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_ids,
         array_agg(DISTINCT t.id) FILTER (WHERE t.id IS NOT NULL) as tag_ids,
         array_agg(DISTINCT otv.type_id) FILTER (WHERE otv.type_id IS NOT NULL) as type_ids,
@@ -77,6 +79,20 @@ filtered_objects AS (
         )) AND
         ($4::uuid[] IS NULL OR od.tag_ids && $4) AND
         ($5::uuid[] IS NULL OR od.type_ids && $5) AND
+        -- Filter out objects with any of the excluded steps, tags or types
+        ($15::uuid[] IS NULL OR NOT (COALESCE(od.step_ids, '{}') && $15)) AND
+        ($16::uuid[] IS NULL OR NOT (COALESCE(od.tag_ids, '{}') && $16)) AND
+        ($17::uuid[] IS NULL OR NOT (COALESCE(od.type_ids, '{}') && $17)) AND
+        -- Filter by creation date, from inclusive and before exclusive
+        ($18::timestamptz IS NULL OR od.created_at >= $18) AND
+        ($19::timestamptz IS NULL OR od.created_at < $19) AND
+        -- Filter by fact kinds if array is provided
+        ($20::text[] IS NULL OR EXISTS (
+            SELECT 1
+            FROM obj_fact xf
+            JOIN fact fk ON fk.id = xf.fact_id
+            WHERE xf.obj_id = od.id AND fk.deleted_at IS NULL AND fk.kind = ANY($20::text[])
+        )) AND
         ((COALESCE($6::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -87,9 +103,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($6) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($6) criteria
+                            FROM jsonb_each($6) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -104,9 +120,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($7) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($7) criteria
+                            FROM jsonb_each($7) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -121,9 +137,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($8) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($8) criteria
+                            FROM jsonb_each($8) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -132,6 +148,18 @@ filtered_objects AS (
          obj_search @@ websearch_to_tsquery('english', $2) OR
          fact_search @@ websearch_to_tsquery('english', $2) OR
          type_value_search @@ websearch_to_tsquery('english', $2)) AND
+        -- Words the query excludes, from every text at once
+        ($21::text = '' OR NOT COALESCE(
+         obj_search @@ websearch_to_tsquery('english', $21) OR
+         fact_search @@ websearch_to_tsquery('english', $21) OR
+         type_value_search @@ websearch_to_tsquery('english', $21), false)) AND
+        -- Filter by health score range, objects never scored match no range
+        ($13::real IS NULL OR EXISTS (
+            SELECT 1 FROM obj_health oh WHERE oh.obj_id = od.id AND oh.score >= $13::real
+        )) AND
+        ($14::real IS NULL OR EXISTS (
+            SELECT 1 FROM obj_health oh WHERE oh.obj_id = od.id AND oh.score <= $14::real
+        )) AND
         -- Additional filters to ensure we don't add duplicates
         (COALESCE($10::uuid, '00000000-0000-0000-0000-000000000000'::uuid) = '00000000-0000-0000-0000-000000000000'::uuid 
         OR NOT ($10 = ANY(od.tag_ids)) OR od.tag_ids IS NULL) AND
@@ -187,6 +215,15 @@ type AddTagAndStepToFilteredObjectsParams struct {
 	Column10  uuid.UUID       `json:"column_10"`
 	FunnelID  uuid.UUID       `json:"funnel_id"`
 	CreatorID uuid.UUID       `json:"creator_id"`
+	Column13  sql.NullFloat64 `json:"column_13"`
+	Column14  sql.NullFloat64 `json:"column_14"`
+	Column15  []uuid.UUID     `json:"column_15"`
+	Column16  []uuid.UUID     `json:"column_16"`
+	Column17  []uuid.UUID     `json:"column_17"`
+	Column18  sql.NullTime    `json:"column_18"`
+	Column19  sql.NullTime    `json:"column_19"`
+	Column20  []string        `json:"column_20"`
+	Column21  string          `json:"column_21"`
 }
 
 type AddTagAndStepToFilteredObjectsRow struct {
@@ -214,6 +251,15 @@ func (q *Queries) AddTagAndStepToFilteredObjects(ctx context.Context, arg AddTag
 		arg.Column10,
 		arg.FunnelID,
 		arg.CreatorID,
+		arg.Column13,
+		arg.Column14,
+		pq.Array(arg.Column15),
+		pq.Array(arg.Column16),
+		pq.Array(arg.Column17),
+		arg.Column18,
+		arg.Column19,
+		pq.Array(arg.Column20),
+		arg.Column21,
 	)
 	if err != nil {
 		return nil, err
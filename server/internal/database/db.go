@@ -24,6 +24,12 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.addAddressReviewFactStmt, err = db.PrepareContext(ctx, addAddressReviewFact); err != nil {
+		return nil, fmt.Errorf("error preparing query AddAddressReviewFact: %w", err)
+	}
+	if q.addFactReactionStmt, err = db.PrepareContext(ctx, addFactReaction); err != nil {
+		return nil, fmt.Errorf("error preparing query AddFactReaction: %w", err)
+	}
 	if q.addObjectTypeValueStmt, err = db.PrepareContext(ctx, addObjectTypeValue); err != nil {
 		return nil, fmt.Errorf("error preparing query AddObjectTypeValue: %w", err)
 	}
@@ -39,15 +45,27 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.addTagToObjectStmt, err = db.PrepareContext(ctx, addTagToObject); err != nil {
 		return nil, fmt.Errorf("error preparing query AddTagToObject: %w", err)
 	}
+	if q.backupObjectTypeValueStmt, err = db.PrepareContext(ctx, backupObjectTypeValue); err != nil {
+		return nil, fmt.Errorf("error preparing query BackupObjectTypeValue: %w", err)
+	}
+	if q.completeBulkOperationStmt, err = db.PrepareContext(ctx, completeBulkOperation); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteBulkOperation: %w", err)
+	}
 	if q.completeImportTaskStmt, err = db.PrepareContext(ctx, completeImportTask); err != nil {
 		return nil, fmt.Errorf("error preparing query CompleteImportTask: %w", err)
 	}
+	if q.completeSchemaMigrationStmt, err = db.PrepareContext(ctx, completeSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteSchemaMigration: %w", err)
+	}
 	if q.countAccessibleObjectTypesStmt, err = db.PrepareContext(ctx, countAccessibleObjectTypes); err != nil {
 		return nil, fmt.Errorf("error preparing query CountAccessibleObjectTypes: %w", err)
 	}
 	if q.countActionExecutionsStmt, err = db.PrepareContext(ctx, countActionExecutions); err != nil {
 		return nil, fmt.Errorf("error preparing query CountActionExecutions: %w", err)
 	}
+	if q.countAddressReviewsStmt, err = db.PrepareContext(ctx, countAddressReviews); err != nil {
+		return nil, fmt.Errorf("error preparing query CountAddressReviews: %w", err)
+	}
 	if q.countAutomatedActionsStmt, err = db.PrepareContext(ctx, countAutomatedActions); err != nil {
 		return nil, fmt.Errorf("error preparing query CountAutomatedActions: %w", err)
 	}
@@ -84,12 +102,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countOngoingTaskStmt, err = db.PrepareContext(ctx, countOngoingTask); err != nil {
 		return nil, fmt.Errorf("error preparing query CountOngoingTask: %w", err)
 	}
+	if q.countOrgTagsStmt, err = db.PrepareContext(ctx, countOrgTags); err != nil {
+		return nil, fmt.Errorf("error preparing query CountOrgTags: %w", err)
+	}
 	if q.countTagsStmt, err = db.PrepareContext(ctx, countTags); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTags: %w", err)
 	}
 	if q.countTasksByObjectIDStmt, err = db.PrepareContext(ctx, countTasksByObjectID); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTasksByObjectID: %w", err)
 	}
+	if q.countTasksByObjectIDsStmt, err = db.PrepareContext(ctx, countTasksByObjectIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query CountTasksByObjectIDs: %w", err)
+	}
 	if q.countTasksByOrgIDStmt, err = db.PrepareContext(ctx, countTasksByOrgID); err != nil {
 		return nil, fmt.Errorf("error preparing query CountTasksByOrgID: %w", err)
 	}
@@ -99,12 +123,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countUnseenFeedStmt, err = db.PrepareContext(ctx, countUnseenFeed); err != nil {
 		return nil, fmt.Errorf("error preparing query CountUnseenFeed: %w", err)
 	}
+	if q.countUnseenMentionsStmt, err = db.PrepareContext(ctx, countUnseenMentions); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUnseenMentions: %w", err)
+	}
 	if q.createActionExecutionStmt, err = db.PrepareContext(ctx, createActionExecution); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateActionExecution: %w", err)
 	}
+	if q.createAttachmentStmt, err = db.PrepareContext(ctx, createAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAttachment: %w", err)
+	}
 	if q.createAutomatedActionStmt, err = db.PrepareContext(ctx, createAutomatedAction); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAutomatedAction: %w", err)
 	}
+	if q.createBulkOperationStmt, err = db.PrepareContext(ctx, createBulkOperation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBulkOperation: %w", err)
+	}
+	if q.createCalendarFeedStmt, err = db.PrepareContext(ctx, createCalendarFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCalendarFeed: %w", err)
+	}
 	if q.createCreatorStmt, err = db.PrepareContext(ctx, createCreator); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCreator: %w", err)
 	}
@@ -114,6 +150,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createFactStmt, err = db.PrepareContext(ctx, createFact); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFact: %w", err)
 	}
+	if q.createFactCommentStmt, err = db.PrepareContext(ctx, createFactComment); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFactComment: %w", err)
+	}
+	if q.createFactSourceStmt, err = db.PrepareContext(ctx, createFactSource); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFactSource: %w", err)
+	}
 	if q.createFeedStmt, err = db.PrepareContext(ctx, createFeed); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFeed: %w", err)
 	}
@@ -126,18 +168,30 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createListStmt, err = db.PrepareContext(ctx, createList); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateList: %w", err)
 	}
+	if q.createObjPhotoStmt, err = db.PrepareContext(ctx, createObjPhoto); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateObjPhoto: %w", err)
+	}
 	if q.createObjStepStmt, err = db.PrepareContext(ctx, createObjStep); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateObjStep: %w", err)
 	}
 	if q.createObjectStmt, err = db.PrepareContext(ctx, createObject); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateObject: %w", err)
 	}
+	if q.createObjectHistoryStmt, err = db.PrepareContext(ctx, createObjectHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateObjectHistory: %w", err)
+	}
+	if q.createObjectRelationStmt, err = db.PrepareContext(ctx, createObjectRelation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateObjectRelation: %w", err)
+	}
 	if q.createObjectTypeStmt, err = db.PrepareContext(ctx, createObjectType); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateObjectType: %w", err)
 	}
 	if q.createOrganizationStmt, err = db.PrepareContext(ctx, createOrganization); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateOrganization: %w", err)
 	}
+	if q.createSchemaMigrationStmt, err = db.PrepareContext(ctx, createSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSchemaMigration: %w", err)
+	}
 	if q.createStepStmt, err = db.PrepareContext(ctx, createStep); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateStep: %w", err)
 	}
@@ -147,12 +201,21 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createTaskStmt, err = db.PrepareContext(ctx, createTask); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTask: %w", err)
 	}
+	if q.createWatchStmt, err = db.PrepareContext(ctx, createWatch); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWatch: %w", err)
+	}
 	if q.deleteActionOldExecutionsStmt, err = db.PrepareContext(ctx, deleteActionOldExecutions); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteActionOldExecutions: %w", err)
 	}
+	if q.deleteAttachmentStmt, err = db.PrepareContext(ctx, deleteAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAttachment: %w", err)
+	}
 	if q.deleteAutomatedActionStmt, err = db.PrepareContext(ctx, deleteAutomatedAction); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAutomatedAction: %w", err)
 	}
+	if q.deleteCalendarFeedStmt, err = db.PrepareContext(ctx, deleteCalendarFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCalendarFeed: %w", err)
+	}
 	if q.deleteCreatorStmt, err = db.PrepareContext(ctx, deleteCreator); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteCreator: %w", err)
 	}
@@ -162,18 +225,33 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteFactStmt, err = db.PrepareContext(ctx, deleteFact); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFact: %w", err)
 	}
+	if q.deleteFactCommentStmt, err = db.PrepareContext(ctx, deleteFactComment); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFactComment: %w", err)
+	}
 	if q.deleteFunnelStmt, err = db.PrepareContext(ctx, deleteFunnel); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFunnel: %w", err)
 	}
 	if q.deleteListStmt, err = db.PrepareContext(ctx, deleteList); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteList: %w", err)
 	}
+	if q.deleteObjPhotoStmt, err = db.PrepareContext(ctx, deleteObjPhoto); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteObjPhoto: %w", err)
+	}
 	if q.deleteObjectStmt, err = db.PrepareContext(ctx, deleteObject); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteObject: %w", err)
 	}
+	if q.deleteObjectRelationStmt, err = db.PrepareContext(ctx, deleteObjectRelation); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteObjectRelation: %w", err)
+	}
 	if q.deleteObjectTypeStmt, err = db.PrepareContext(ctx, deleteObjectType); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteObjectType: %w", err)
 	}
+	if q.deleteOrphanedAttachmentStmt, err = db.PrepareContext(ctx, deleteOrphanedAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteOrphanedAttachment: %w", err)
+	}
+	if q.deleteSchemaMigrationBackupStmt, err = db.PrepareContext(ctx, deleteSchemaMigrationBackup); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSchemaMigrationBackup: %w", err)
+	}
 	if q.deleteStepStmt, err = db.PrepareContext(ctx, deleteStep); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteStep: %w", err)
 	}
@@ -183,18 +261,63 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteTaskStmt, err = db.PrepareContext(ctx, deleteTask); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteTask: %w", err)
 	}
+	if q.deleteWatchStmt, err = db.PrepareContext(ctx, deleteWatch); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWatch: %w", err)
+	}
+	if q.ensureMailDropboxStmt, err = db.PrepareContext(ctx, ensureMailDropbox); err != nil {
+		return nil, fmt.Errorf("error preparing query EnsureMailDropbox: %w", err)
+	}
+	if q.enterSchemaMigrationStmt, err = db.PrepareContext(ctx, enterSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query EnterSchemaMigration: %w", err)
+	}
+	if q.failBulkOperationStmt, err = db.PrepareContext(ctx, failBulkOperation); err != nil {
+		return nil, fmt.Errorf("error preparing query FailBulkOperation: %w", err)
+	}
+	if q.failInterruptedBulkOperationsStmt, err = db.PrepareContext(ctx, failInterruptedBulkOperations); err != nil {
+		return nil, fmt.Errorf("error preparing query FailInterruptedBulkOperations: %w", err)
+	}
+	if q.failSchemaMigrationStmt, err = db.PrepareContext(ctx, failSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query FailSchemaMigration: %w", err)
+	}
+	if q.filterOrgObjectIDsStmt, err = db.PrepareContext(ctx, filterOrgObjectIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query FilterOrgObjectIDs: %w", err)
+	}
+	if q.findCreatorByEmailStmt, err = db.PrepareContext(ctx, findCreatorByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query FindCreatorByEmail: %w", err)
+	}
 	if q.findObjectByAliasOrIDStringStmt, err = db.PrepareContext(ctx, findObjectByAliasOrIDString); err != nil {
 		return nil, fmt.Errorf("error preparing query FindObjectByAliasOrIDString: %w", err)
 	}
+	if q.findObjectByTypeValueStmt, err = db.PrepareContext(ctx, findObjectByTypeValue); err != nil {
+		return nil, fmt.Errorf("error preparing query FindObjectByTypeValue: %w", err)
+	}
 	if q.findTagByNormalizedNameStmt, err = db.PrepareContext(ctx, findTagByNormalizedName); err != nil {
 		return nil, fmt.Errorf("error preparing query FindTagByNormalizedName: %w", err)
 	}
+	if q.findWatchStmt, err = db.PrepareContext(ctx, findWatch); err != nil {
+		return nil, fmt.Errorf("error preparing query FindWatch: %w", err)
+	}
 	if q.getAccessibleObjectTypesForMemberStmt, err = db.PrepareContext(ctx, getAccessibleObjectTypesForMember); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccessibleObjectTypesForMember: %w", err)
 	}
+	if q.getActiveObjStepStmt, err = db.PrepareContext(ctx, getActiveObjStep); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveObjStep: %w", err)
+	}
+	if q.getAddressReviewStmt, err = db.PrepareContext(ctx, getAddressReview); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAddressReview: %w", err)
+	}
+	if q.getAttachmentStmt, err = db.PrepareContext(ctx, getAttachment); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAttachment: %w", err)
+	}
 	if q.getAutomatedActionStmt, err = db.PrepareContext(ctx, getAutomatedAction); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAutomatedAction: %w", err)
 	}
+	if q.getBulkOperationStmt, err = db.PrepareContext(ctx, getBulkOperation); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBulkOperation: %w", err)
+	}
+	if q.getCalendarFeedStmt, err = db.PrepareContext(ctx, getCalendarFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCalendarFeed: %w", err)
+	}
 	if q.getCreatorByIDStmt, err = db.PrepareContext(ctx, getCreatorByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCreatorByID: %w", err)
 	}
@@ -210,6 +333,18 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getFactByIDStmt, err = db.PrepareContext(ctx, getFactByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFactByID: %w", err)
 	}
+	if q.getFactCommentStmt, err = db.PrepareContext(ctx, getFactComment); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFactComment: %w", err)
+	}
+	if q.getFactDatesByObjectIDsStmt, err = db.PrepareContext(ctx, getFactDatesByObjectIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFactDatesByObjectIDs: %w", err)
+	}
+	if q.getFactSourceStmt, err = db.PrepareContext(ctx, getFactSource); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFactSource: %w", err)
+	}
+	if q.getFactVersionStmt, err = db.PrepareContext(ctx, getFactVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFactVersion: %w", err)
+	}
 	if q.getFeedStmt, err = db.PrepareContext(ctx, getFeed); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFeed: %w", err)
 	}
@@ -219,6 +354,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getGDPStatsStmt, err = db.PrepareContext(ctx, getGDPStats); err != nil {
 		return nil, fmt.Errorf("error preparing query GetGDPStats: %w", err)
 	}
+	if q.getGraphNodeStmt, err = db.PrepareContext(ctx, getGraphNode); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGraphNode: %w", err)
+	}
+	if q.getHealthScoreSettingStmt, err = db.PrepareContext(ctx, getHealthScoreSetting); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHealthScoreSetting: %w", err)
+	}
 	if q.getImportTaskStmt, err = db.PrepareContext(ctx, getImportTask); err != nil {
 		return nil, fmt.Errorf("error preparing query GetImportTask: %w", err)
 	}
@@ -231,21 +372,39 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getListByIDStmt, err = db.PrepareContext(ctx, getListByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetListByID: %w", err)
 	}
+	if q.getMailDropboxStmt, err = db.PrepareContext(ctx, getMailDropbox); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMailDropbox: %w", err)
+	}
+	if q.getMailDropboxByTokenStmt, err = db.PrepareContext(ctx, getMailDropboxByToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMailDropboxByToken: %w", err)
+	}
+	if q.getObjPhotoStmt, err = db.PrepareContext(ctx, getObjPhoto); err != nil {
+		return nil, fmt.Errorf("error preparing query GetObjPhoto: %w", err)
+	}
 	if q.getObjStepStmt, err = db.PrepareContext(ctx, getObjStep); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjStep: %w", err)
 	}
+	if q.getObjectByIDStmt, err = db.PrepareContext(ctx, getObjectByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetObjectByID: %w", err)
+	}
 	if q.getObjectByIDStringStmt, err = db.PrepareContext(ctx, getObjectByIDString); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjectByIDString: %w", err)
 	}
 	if q.getObjectDetailsStmt, err = db.PrepareContext(ctx, getObjectDetails); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjectDetails: %w", err)
 	}
+	if q.getObjectHealthStmt, err = db.PrepareContext(ctx, getObjectHealth); err != nil {
+		return nil, fmt.Errorf("error preparing query GetObjectHealth: %w", err)
+	}
 	if q.getObjectTypeByIDStmt, err = db.PrepareContext(ctx, getObjectTypeByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjectTypeByID: %w", err)
 	}
 	if q.getObjectTypeValueStmt, err = db.PrepareContext(ctx, getObjectTypeValue); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjectTypeValue: %w", err)
 	}
+	if q.getObjectTypeValueByIDStmt, err = db.PrepareContext(ctx, getObjectTypeValueByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetObjectTypeValueByID: %w", err)
+	}
 	if q.getObjectsByTypeStatsStmt, err = db.PrepareContext(ctx, getObjectsByTypeStats); err != nil {
 		return nil, fmt.Errorf("error preparing query GetObjectsByTypeStats: %w", err)
 	}
@@ -255,6 +414,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getOngoingImportTaskStmt, err = db.PrepareContext(ctx, getOngoingImportTask); err != nil {
 		return nil, fmt.Errorf("error preparing query GetOngoingImportTask: %w", err)
 	}
+	if q.getOngoingSchemaMigrationStmt, err = db.PrepareContext(ctx, getOngoingSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOngoingSchemaMigration: %w", err)
+	}
 	if q.getOrgDetailsStmt, err = db.PrepareContext(ctx, getOrgDetails); err != nil {
 		return nil, fmt.Errorf("error preparing query GetOrgDetails: %w", err)
 	}
@@ -288,6 +450,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getPublicTopObjectsStmt, err = db.PrepareContext(ctx, getPublicTopObjects); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPublicTopObjects: %w", err)
 	}
+	if q.getRunningSchemaMigrationStmt, err = db.PrepareContext(ctx, getRunningSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRunningSchemaMigration: %w", err)
+	}
+	if q.getSchemaMigrationStmt, err = db.PrepareContext(ctx, getSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSchemaMigration: %w", err)
+	}
 	if q.getStepStmt, err = db.PrepareContext(ctx, getStep); err != nil {
 		return nil, fmt.Errorf("error preparing query GetStep: %w", err)
 	}
@@ -312,33 +480,141 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.healthCheckStmt, err = db.PrepareContext(ctx, healthCheck); err != nil {
 		return nil, fmt.Errorf("error preparing query HealthCheck: %w", err)
 	}
+	if q.isOrgCreatorStmt, err = db.PrepareContext(ctx, isOrgCreator); err != nil {
+		return nil, fmt.Errorf("error preparing query IsOrgCreator: %w", err)
+	}
+	if q.isOrgFactStmt, err = db.PrepareContext(ctx, isOrgFact); err != nil {
+		return nil, fmt.Errorf("error preparing query IsOrgFact: %w", err)
+	}
+	if q.isOrgListStmt, err = db.PrepareContext(ctx, isOrgList); err != nil {
+		return nil, fmt.Errorf("error preparing query IsOrgList: %w", err)
+	}
+	if q.isOrgObjectTypeStmt, err = db.PrepareContext(ctx, isOrgObjectType); err != nil {
+		return nil, fmt.Errorf("error preparing query IsOrgObjectType: %w", err)
+	}
+	if q.isOrgStepStmt, err = db.PrepareContext(ctx, isOrgStep); err != nil {
+		return nil, fmt.Errorf("error preparing query IsOrgStep: %w", err)
+	}
+	if q.linkAddressReviewFactsStmt, err = db.PrepareContext(ctx, linkAddressReviewFacts); err != nil {
+		return nil, fmt.Errorf("error preparing query LinkAddressReviewFacts: %w", err)
+	}
 	if q.listAccessibleObjectTypesStmt, err = db.PrepareContext(ctx, listAccessibleObjectTypes); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAccessibleObjectTypes: %w", err)
 	}
 	if q.listActionExecutionsStmt, err = db.PrepareContext(ctx, listActionExecutions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListActionExecutions: %w", err)
 	}
+	if q.listAddressReviewsStmt, err = db.PrepareContext(ctx, listAddressReviews); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAddressReviews: %w", err)
+	}
+	if q.listAllCalendarFeedsStmt, err = db.PrepareContext(ctx, listAllCalendarFeeds); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllCalendarFeeds: %w", err)
+	}
+	if q.listAttachmentsByFactIDsStmt, err = db.PrepareContext(ctx, listAttachmentsByFactIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAttachmentsByFactIDs: %w", err)
+	}
+	if q.listAttachmentsByObjectStmt, err = db.PrepareContext(ctx, listAttachmentsByObject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAttachmentsByObject: %w", err)
+	}
 	if q.listAutomatedActionsStmt, err = db.PrepareContext(ctx, listAutomatedActions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAutomatedActions: %w", err)
 	}
+	if q.listBulkOperationsStmt, err = db.PrepareContext(ctx, listBulkOperations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBulkOperations: %w", err)
+	}
+	if q.listCalendarFeedsStmt, err = db.PrepareContext(ctx, listCalendarFeeds); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCalendarFeeds: %w", err)
+	}
+	if q.listCoMentionEdgesStmt, err = db.PrepareContext(ctx, listCoMentionEdges); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCoMentionEdges: %w", err)
+	}
 	if q.listCreatorListsByCreatorIDStmt, err = db.PrepareContext(ctx, listCreatorListsByCreatorID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListCreatorListsByCreatorID: %w", err)
 	}
+	if q.listFactCommentsStmt, err = db.PrepareContext(ctx, listFactComments); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFactComments: %w", err)
+	}
+	if q.listFactReactionsStmt, err = db.PrepareContext(ctx, listFactReactions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFactReactions: %w", err)
+	}
+	if q.listFactVersionsStmt, err = db.PrepareContext(ctx, listFactVersions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFactVersions: %w", err)
+	}
 	if q.listFactsByOrgIDStmt, err = db.PrepareContext(ctx, listFactsByOrgID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListFactsByOrgID: %w", err)
 	}
 	if q.listFunnelsStmt, err = db.PrepareContext(ctx, listFunnels); err != nil {
 		return nil, fmt.Errorf("error preparing query ListFunnels: %w", err)
 	}
+	if q.listGraphNeighborsStmt, err = db.PrepareContext(ctx, listGraphNeighbors); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGraphNeighbors: %w", err)
+	}
+	if q.listHealthInputsStmt, err = db.PrepareContext(ctx, listHealthInputs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListHealthInputs: %w", err)
+	}
+	if q.listListWatchesStmt, err = db.PrepareContext(ctx, listListWatches); err != nil {
+		return nil, fmt.Errorf("error preparing query ListListWatches: %w", err)
+	}
 	if q.listListsByOrgIDStmt, err = db.PrepareContext(ctx, listListsByOrgID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListListsByOrgID: %w", err)
 	}
+	if q.listMailDropboxTokensStmt, err = db.PrepareContext(ctx, listMailDropboxTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMailDropboxTokens: %w", err)
+	}
+	if q.listMentionedCreatorsStmt, err = db.PrepareContext(ctx, listMentionedCreators); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMentionedCreators: %w", err)
+	}
+	if q.listObjectConnectionsStmt, err = db.PrepareContext(ctx, listObjectConnections); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectConnections: %w", err)
+	}
+	if q.listObjectFactIDsStmt, err = db.PrepareContext(ctx, listObjectFactIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectFactIDs: %w", err)
+	}
+	if q.listObjectHealthHistoryStmt, err = db.PrepareContext(ctx, listObjectHealthHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectHealthHistory: %w", err)
+	}
+	if q.listObjectHistoryStmt, err = db.PrepareContext(ctx, listObjectHistory); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectHistory: %w", err)
+	}
+	if q.listObjectNamesStmt, err = db.PrepareContext(ctx, listObjectNames); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectNames: %w", err)
+	}
+	if q.listObjectNeighbourhoodStmt, err = db.PrepareContext(ctx, listObjectNeighbourhood); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectNeighbourhood: %w", err)
+	}
+	if q.listObjectRelationsStmt, err = db.PrepareContext(ctx, listObjectRelations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectRelations: %w", err)
+	}
+	if q.listObjectStepsInFunnelStmt, err = db.PrepareContext(ctx, listObjectStepsInFunnel); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectStepsInFunnel: %w", err)
+	}
+	if q.listObjectTypeFieldsByOrgStmt, err = db.PrepareContext(ctx, listObjectTypeFieldsByOrg); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectTypeFieldsByOrg: %w", err)
+	}
+	if q.listObjectTypeValuesByObjectStmt, err = db.PrepareContext(ctx, listObjectTypeValuesByObject); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectTypeValuesByObject: %w", err)
+	}
+	if q.listObjectTypeValuesForMigrationStmt, err = db.PrepareContext(ctx, listObjectTypeValuesForMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectTypeValuesForMigration: %w", err)
+	}
 	if q.listObjectTypesStmt, err = db.PrepareContext(ctx, listObjectTypes); err != nil {
 		return nil, fmt.Errorf("error preparing query ListObjectTypes: %w", err)
 	}
+	if q.listObjectTypesByNamesStmt, err = db.PrepareContext(ctx, listObjectTypesByNames); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectTypesByNames: %w", err)
+	}
+	if q.listObjectWatchersStmt, err = db.PrepareContext(ctx, listObjectWatchers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectWatchers: %w", err)
+	}
 	if q.listObjectsAdvancedStmt, err = db.PrepareContext(ctx, listObjectsAdvanced); err != nil {
 		return nil, fmt.Errorf("error preparing query ListObjectsAdvanced: %w", err)
 	}
+	if q.listObjectsByAliasOrIDStringStmt, err = db.PrepareContext(ctx, listObjectsByAliasOrIDString); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectsByAliasOrIDString: %w", err)
+	}
+	if q.listObjectsByEmailStmt, err = db.PrepareContext(ctx, listObjectsByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query ListObjectsByEmail: %w", err)
+	}
 	if q.listObjectsByOrgIDStmt, err = db.PrepareContext(ctx, listObjectsByOrgID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListObjectsByOrgID: %w", err)
 	}
@@ -357,12 +633,42 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listOrganizationsStmt, err = db.PrepareContext(ctx, listOrganizations); err != nil {
 		return nil, fmt.Errorf("error preparing query ListOrganizations: %w", err)
 	}
+	if q.listOrphanedAttachmentsStmt, err = db.PrepareContext(ctx, listOrphanedAttachments); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOrphanedAttachments: %w", err)
+	}
+	if q.listOrphanedObjPhotosStmt, err = db.PrepareContext(ctx, listOrphanedObjPhotos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOrphanedObjPhotos: %w", err)
+	}
+	if q.listPublicObjectRelationsStmt, err = db.PrepareContext(ctx, listPublicObjectRelations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPublicObjectRelations: %w", err)
+	}
+	if q.listRelationTypesStmt, err = db.PrepareContext(ctx, listRelationTypes); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRelationTypes: %w", err)
+	}
+	if q.listRelationsBetweenObjectsStmt, err = db.PrepareContext(ctx, listRelationsBetweenObjects); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRelationsBetweenObjects: %w", err)
+	}
+	if q.listSchemaMigrationsStmt, err = db.PrepareContext(ctx, listSchemaMigrations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSchemaMigrations: %w", err)
+	}
+	if q.listSharedFactsStmt, err = db.PrepareContext(ctx, listSharedFacts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSharedFacts: %w", err)
+	}
+	if q.listStaleSchemaMigrationsStmt, err = db.PrepareContext(ctx, listStaleSchemaMigrations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStaleSchemaMigrations: %w", err)
+	}
 	if q.listStepsByFunnelStmt, err = db.PrepareContext(ctx, listStepsByFunnel); err != nil {
 		return nil, fmt.Errorf("error preparing query ListStepsByFunnel: %w", err)
 	}
+	if q.listStepsByNamesStmt, err = db.PrepareContext(ctx, listStepsByNames); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStepsByNames: %w", err)
+	}
 	if q.listTagsStmt, err = db.PrepareContext(ctx, listTags); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTags: %w", err)
 	}
+	if q.listTagsByNamesStmt, err = db.PrepareContext(ctx, listTagsByNames); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTagsByNames: %w", err)
+	}
 	if q.listTasksByObjectIDStmt, err = db.PrepareContext(ctx, listTasksByObjectID); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTasksByObjectID: %w", err)
 	}
@@ -372,12 +678,57 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listTasksWithFilterStmt, err = db.PrepareContext(ctx, listTasksWithFilter); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTasksWithFilter: %w", err)
 	}
+	if q.listTrashStmt, err = db.PrepareContext(ctx, listTrash); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTrash: %w", err)
+	}
+	if q.listWatchesByCreatorStmt, err = db.PrepareContext(ctx, listWatchesByCreator); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWatchesByCreator: %w", err)
+	}
+	if q.lockFactsStmt, err = db.PrepareContext(ctx, lockFacts); err != nil {
+		return nil, fmt.Errorf("error preparing query LockFacts: %w", err)
+	}
 	if q.markFeedAsSeenStmt, err = db.PrepareContext(ctx, markFeedAsSeen); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkFeedAsSeen: %w", err)
 	}
+	if q.matchListFiltersStmt, err = db.PrepareContext(ctx, matchListFilters); err != nil {
+		return nil, fmt.Errorf("error preparing query MatchListFilters: %w", err)
+	}
 	if q.mergeObjectsStmt, err = db.PrepareContext(ctx, mergeObjects); err != nil {
 		return nil, fmt.Errorf("error preparing query MergeObjects: %w", err)
 	}
+	if q.purgeExpiredTrashStmt, err = db.PrepareContext(ctx, purgeExpiredTrash); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeExpiredTrash: %w", err)
+	}
+	if q.purgeFactStmt, err = db.PrepareContext(ctx, purgeFact); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeFact: %w", err)
+	}
+	if q.purgeFunnelStmt, err = db.PrepareContext(ctx, purgeFunnel); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeFunnel: %w", err)
+	}
+	if q.purgeListStmt, err = db.PrepareContext(ctx, purgeList); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeList: %w", err)
+	}
+	if q.purgeObjStepStmt, err = db.PrepareContext(ctx, purgeObjStep); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeObjStep: %w", err)
+	}
+	if q.purgeObjectStmt, err = db.PrepareContext(ctx, purgeObject); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeObject: %w", err)
+	}
+	if q.purgeTagStmt, err = db.PrepareContext(ctx, purgeTag); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeTag: %w", err)
+	}
+	if q.purgeTaskStmt, err = db.PrepareContext(ctx, purgeTask); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeTask: %w", err)
+	}
+	if q.rebuildObjectTypeValueSearchVectorsStmt, err = db.PrepareContext(ctx, rebuildObjectTypeValueSearchVectors); err != nil {
+		return nil, fmt.Errorf("error preparing query RebuildObjectTypeValueSearchVectors: %w", err)
+	}
+	if q.recordFactVersionStmt, err = db.PrepareContext(ctx, recordFactVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordFactVersion: %w", err)
+	}
+	if q.removeFactReactionStmt, err = db.PrepareContext(ctx, removeFactReaction); err != nil {
+		return nil, fmt.Errorf("error preparing query RemoveFactReaction: %w", err)
+	}
 	if q.removeObjectTypeValueStmt, err = db.PrepareContext(ctx, removeObjectTypeValue); err != nil {
 		return nil, fmt.Errorf("error preparing query RemoveObjectTypeValue: %w", err)
 	}
@@ -390,12 +741,69 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.removeTagFromObjectStmt, err = db.PrepareContext(ctx, removeTagFromObject); err != nil {
 		return nil, fmt.Errorf("error preparing query RemoveTagFromObject: %w", err)
 	}
+	if q.resolveAddressReviewStmt, err = db.PrepareContext(ctx, resolveAddressReview); err != nil {
+		return nil, fmt.Errorf("error preparing query ResolveAddressReview: %w", err)
+	}
+	if q.restoreFactStmt, err = db.PrepareContext(ctx, restoreFact); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreFact: %w", err)
+	}
+	if q.restoreFunnelStmt, err = db.PrepareContext(ctx, restoreFunnel); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreFunnel: %w", err)
+	}
+	if q.restoreListStmt, err = db.PrepareContext(ctx, restoreList); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreList: %w", err)
+	}
+	if q.restoreObjectStmt, err = db.PrepareContext(ctx, restoreObject); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreObject: %w", err)
+	}
+	if q.restoreTagStmt, err = db.PrepareContext(ctx, restoreTag); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreTag: %w", err)
+	}
+	if q.restoreTaskStmt, err = db.PrepareContext(ctx, restoreTask); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreTask: %w", err)
+	}
+	if q.revertSchemaMigrationStmt, err = db.PrepareContext(ctx, revertSchemaMigration); err != nil {
+		return nil, fmt.Errorf("error preparing query RevertSchemaMigration: %w", err)
+	}
 	if q.revokeAccessToObjectTypeStmt, err = db.PrepareContext(ctx, revokeAccessToObjectType); err != nil {
 		return nil, fmt.Errorf("error preparing query RevokeAccessToObjectType: %w", err)
 	}
+	if q.searchFactsStmt, err = db.PrepareContext(ctx, searchFacts); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchFacts: %w", err)
+	}
+	if q.searchListsStmt, err = db.PrepareContext(ctx, searchLists); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchLists: %w", err)
+	}
+	if q.searchObjectsStmt, err = db.PrepareContext(ctx, searchObjects); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchObjects: %w", err)
+	}
+	if q.searchTagsStmt, err = db.PrepareContext(ctx, searchTags); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchTags: %w", err)
+	}
+	if q.searchTasksStmt, err = db.PrepareContext(ctx, searchTasks); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchTasks: %w", err)
+	}
+	if q.setFactObjectsStmt, err = db.PrepareContext(ctx, setFactObjects); err != nil {
+		return nil, fmt.Errorf("error preparing query SetFactObjects: %w", err)
+	}
+	if q.setFuzzyThresholdStmt, err = db.PrepareContext(ctx, setFuzzyThreshold); err != nil {
+		return nil, fmt.Errorf("error preparing query SetFuzzyThreshold: %w", err)
+	}
+	if q.setObjectOwnerStmt, err = db.PrepareContext(ctx, setObjectOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query SetObjectOwner: %w", err)
+	}
+	if q.setObjectPhotoStmt, err = db.PrepareContext(ctx, setObjectPhoto); err != nil {
+		return nil, fmt.Errorf("error preparing query SetObjectPhoto: %w", err)
+	}
+	if q.setObjectTypeValuesStmt, err = db.PrepareContext(ctx, setObjectTypeValues); err != nil {
+		return nil, fmt.Errorf("error preparing query SetObjectTypeValues: %w", err)
+	}
 	if q.softDeleteObjStepStmt, err = db.PrepareContext(ctx, softDeleteObjStep); err != nil {
 		return nil, fmt.Errorf("error preparing query SoftDeleteObjStep: %w", err)
 	}
+	if q.suggestObjectsStmt, err = db.PrepareContext(ctx, suggestObjects); err != nil {
+		return nil, fmt.Errorf("error preparing query SuggestObjects: %w", err)
+	}
 	if q.syncObjectAliasesStmt, err = db.PrepareContext(ctx, syncObjectAliases); err != nil {
 		return nil, fmt.Errorf("error preparing query SyncObjectAliases: %w", err)
 	}
@@ -408,6 +816,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateAutomatedActionStmt, err = db.PrepareContext(ctx, updateAutomatedAction); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAutomatedAction: %w", err)
 	}
+	if q.updateBulkOperationProgressStmt, err = db.PrepareContext(ctx, updateBulkOperationProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateBulkOperationProgress: %w", err)
+	}
+	if q.updateBulkOperationStatusStmt, err = db.PrepareContext(ctx, updateBulkOperationStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateBulkOperationStatus: %w", err)
+	}
+	if q.updateCalendarFeedSyncStmt, err = db.PrepareContext(ctx, updateCalendarFeedSync); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateCalendarFeedSync: %w", err)
+	}
 	if q.updateCreatorListStmt, err = db.PrepareContext(ctx, updateCreatorList); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateCreatorList: %w", err)
 	}
@@ -423,6 +840,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateFactStmt, err = db.PrepareContext(ctx, updateFact); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateFact: %w", err)
 	}
+	if q.updateFactCommentStmt, err = db.PrepareContext(ctx, updateFactComment); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateFactComment: %w", err)
+	}
 	if q.updateFunnelStmt, err = db.PrepareContext(ctx, updateFunnel); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateFunnel: %w", err)
 	}
@@ -450,12 +870,21 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateObjectTypeStmt, err = db.PrepareContext(ctx, updateObjectType); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateObjectType: %w", err)
 	}
+	if q.updateObjectTypeFieldsStmt, err = db.PrepareContext(ctx, updateObjectTypeFields); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateObjectTypeFields: %w", err)
+	}
 	if q.updateObjectTypeValueStmt, err = db.PrepareContext(ctx, updateObjectTypeValue); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateObjectTypeValue: %w", err)
 	}
 	if q.updateOrgDetailsStmt, err = db.PrepareContext(ctx, updateOrgDetails); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateOrgDetails: %w", err)
 	}
+	if q.updateSchemaMigrationProgressStmt, err = db.PrepareContext(ctx, updateSchemaMigrationProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSchemaMigrationProgress: %w", err)
+	}
+	if q.updateSchemaMigrationStatusStmt, err = db.PrepareContext(ctx, updateSchemaMigrationStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSchemaMigrationStatus: %w", err)
+	}
 	if q.updateStepStmt, err = db.PrepareContext(ctx, updateStep); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateStep: %w", err)
 	}
@@ -465,6 +894,21 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateTaskStmt, err = db.PrepareContext(ctx, updateTask); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateTask: %w", err)
 	}
+	if q.updateWatchEventsStmt, err = db.PrepareContext(ctx, updateWatchEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateWatchEvents: %w", err)
+	}
+	if q.upsertAddressReviewStmt, err = db.PrepareContext(ctx, upsertAddressReview); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertAddressReview: %w", err)
+	}
+	if q.upsertHealthScoreSettingStmt, err = db.PrepareContext(ctx, upsertHealthScoreSetting); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertHealthScoreSetting: %w", err)
+	}
+	if q.upsertMailDropboxStmt, err = db.PrepareContext(ctx, upsertMailDropbox); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMailDropbox: %w", err)
+	}
+	if q.upsertObjectHealthBatchStmt, err = db.PrepareContext(ctx, upsertObjectHealthBatch); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertObjectHealthBatch: %w", err)
+	}
 	if q.upsertObjectTypeValueStmt, err = db.PrepareContext(ctx, upsertObjectTypeValue); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertObjectTypeValue: %w", err)
 	}
@@ -476,6 +920,16 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.addAddressReviewFactStmt != nil {
+		if cerr := q.addAddressReviewFactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addAddressReviewFactStmt: %w", cerr)
+		}
+	}
+	if q.addFactReactionStmt != nil {
+		if cerr := q.addFactReactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addFactReactionStmt: %w", cerr)
+		}
+	}
 	if q.addObjectTypeValueStmt != nil {
 		if cerr := q.addObjectTypeValueStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing addObjectTypeValueStmt: %w", cerr)
@@ -501,11 +955,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing addTagToObjectStmt: %w", cerr)
 		}
 	}
+	if q.backupObjectTypeValueStmt != nil {
+		if cerr := q.backupObjectTypeValueStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing backupObjectTypeValueStmt: %w", cerr)
+		}
+	}
+	if q.completeBulkOperationStmt != nil {
+		if cerr := q.completeBulkOperationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeBulkOperationStmt: %w", cerr)
+		}
+	}
 	if q.completeImportTaskStmt != nil {
 		if cerr := q.completeImportTaskStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing completeImportTaskStmt: %w", cerr)
 		}
 	}
+	if q.completeSchemaMigrationStmt != nil {
+		if cerr := q.completeSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeSchemaMigrationStmt: %w", cerr)
+		}
+	}
 	if q.countAccessibleObjectTypesStmt != nil {
 		if cerr := q.countAccessibleObjectTypesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countAccessibleObjectTypesStmt: %w", cerr)
@@ -516,6 +985,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countActionExecutionsStmt: %w", cerr)
 		}
 	}
+	if q.countAddressReviewsStmt != nil {
+		if cerr := q.countAddressReviewsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countAddressReviewsStmt: %w", cerr)
+		}
+	}
 	if q.countAutomatedActionsStmt != nil {
 		if cerr := q.countAutomatedActionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countAutomatedActionsStmt: %w", cerr)
@@ -576,6 +1050,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countOngoingTaskStmt: %w", cerr)
 		}
 	}
+	if q.countOrgTagsStmt != nil {
+		if cerr := q.countOrgTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countOrgTagsStmt: %w", cerr)
+		}
+	}
 	if q.countTagsStmt != nil {
 		if cerr := q.countTagsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countTagsStmt: %w", cerr)
@@ -586,6 +1065,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countTasksByObjectIDStmt: %w", cerr)
 		}
 	}
+	if q.countTasksByObjectIDsStmt != nil {
+		if cerr := q.countTasksByObjectIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countTasksByObjectIDsStmt: %w", cerr)
+		}
+	}
 	if q.countTasksByOrgIDStmt != nil {
 		if cerr := q.countTasksByOrgIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countTasksByOrgIDStmt: %w", cerr)
@@ -601,16 +1085,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countUnseenFeedStmt: %w", cerr)
 		}
 	}
+	if q.countUnseenMentionsStmt != nil {
+		if cerr := q.countUnseenMentionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUnseenMentionsStmt: %w", cerr)
+		}
+	}
 	if q.createActionExecutionStmt != nil {
 		if cerr := q.createActionExecutionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createActionExecutionStmt: %w", cerr)
 		}
 	}
+	if q.createAttachmentStmt != nil {
+		if cerr := q.createAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAttachmentStmt: %w", cerr)
+		}
+	}
 	if q.createAutomatedActionStmt != nil {
 		if cerr := q.createAutomatedActionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAutomatedActionStmt: %w", cerr)
 		}
 	}
+	if q.createBulkOperationStmt != nil {
+		if cerr := q.createBulkOperationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBulkOperationStmt: %w", cerr)
+		}
+	}
+	if q.createCalendarFeedStmt != nil {
+		if cerr := q.createCalendarFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCalendarFeedStmt: %w", cerr)
+		}
+	}
 	if q.createCreatorStmt != nil {
 		if cerr := q.createCreatorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createCreatorStmt: %w", cerr)
@@ -626,6 +1130,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createFactStmt: %w", cerr)
 		}
 	}
+	if q.createFactCommentStmt != nil {
+		if cerr := q.createFactCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFactCommentStmt: %w", cerr)
+		}
+	}
+	if q.createFactSourceStmt != nil {
+		if cerr := q.createFactSourceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFactSourceStmt: %w", cerr)
+		}
+	}
 	if q.createFeedStmt != nil {
 		if cerr := q.createFeedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createFeedStmt: %w", cerr)
@@ -646,6 +1160,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createListStmt: %w", cerr)
 		}
 	}
+	if q.createObjPhotoStmt != nil {
+		if cerr := q.createObjPhotoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createObjPhotoStmt: %w", cerr)
+		}
+	}
 	if q.createObjStepStmt != nil {
 		if cerr := q.createObjStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createObjStepStmt: %w", cerr)
@@ -656,6 +1175,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createObjectStmt: %w", cerr)
 		}
 	}
+	if q.createObjectHistoryStmt != nil {
+		if cerr := q.createObjectHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createObjectHistoryStmt: %w", cerr)
+		}
+	}
+	if q.createObjectRelationStmt != nil {
+		if cerr := q.createObjectRelationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createObjectRelationStmt: %w", cerr)
+		}
+	}
 	if q.createObjectTypeStmt != nil {
 		if cerr := q.createObjectTypeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createObjectTypeStmt: %w", cerr)
@@ -666,6 +1195,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createOrganizationStmt: %w", cerr)
 		}
 	}
+	if q.createSchemaMigrationStmt != nil {
+		if cerr := q.createSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSchemaMigrationStmt: %w", cerr)
+		}
+	}
 	if q.createStepStmt != nil {
 		if cerr := q.createStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createStepStmt: %w", cerr)
@@ -681,16 +1215,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createTaskStmt: %w", cerr)
 		}
 	}
+	if q.createWatchStmt != nil {
+		if cerr := q.createWatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWatchStmt: %w", cerr)
+		}
+	}
 	if q.deleteActionOldExecutionsStmt != nil {
 		if cerr := q.deleteActionOldExecutionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteActionOldExecutionsStmt: %w", cerr)
 		}
 	}
+	if q.deleteAttachmentStmt != nil {
+		if cerr := q.deleteAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAttachmentStmt: %w", cerr)
+		}
+	}
 	if q.deleteAutomatedActionStmt != nil {
 		if cerr := q.deleteAutomatedActionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAutomatedActionStmt: %w", cerr)
 		}
 	}
+	if q.deleteCalendarFeedStmt != nil {
+		if cerr := q.deleteCalendarFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCalendarFeedStmt: %w", cerr)
+		}
+	}
 	if q.deleteCreatorStmt != nil {
 		if cerr := q.deleteCreatorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteCreatorStmt: %w", cerr)
@@ -706,6 +1255,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteFactStmt: %w", cerr)
 		}
 	}
+	if q.deleteFactCommentStmt != nil {
+		if cerr := q.deleteFactCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFactCommentStmt: %w", cerr)
+		}
+	}
 	if q.deleteFunnelStmt != nil {
 		if cerr := q.deleteFunnelStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteFunnelStmt: %w", cerr)
@@ -716,16 +1270,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteListStmt: %w", cerr)
 		}
 	}
+	if q.deleteObjPhotoStmt != nil {
+		if cerr := q.deleteObjPhotoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteObjPhotoStmt: %w", cerr)
+		}
+	}
 	if q.deleteObjectStmt != nil {
 		if cerr := q.deleteObjectStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteObjectStmt: %w", cerr)
 		}
 	}
+	if q.deleteObjectRelationStmt != nil {
+		if cerr := q.deleteObjectRelationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteObjectRelationStmt: %w", cerr)
+		}
+	}
 	if q.deleteObjectTypeStmt != nil {
 		if cerr := q.deleteObjectTypeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteObjectTypeStmt: %w", cerr)
 		}
 	}
+	if q.deleteOrphanedAttachmentStmt != nil {
+		if cerr := q.deleteOrphanedAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteOrphanedAttachmentStmt: %w", cerr)
+		}
+	}
+	if q.deleteSchemaMigrationBackupStmt != nil {
+		if cerr := q.deleteSchemaMigrationBackupStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSchemaMigrationBackupStmt: %w", cerr)
+		}
+	}
 	if q.deleteStepStmt != nil {
 		if cerr := q.deleteStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteStepStmt: %w", cerr)
@@ -741,26 +1315,101 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteTaskStmt: %w", cerr)
 		}
 	}
+	if q.deleteWatchStmt != nil {
+		if cerr := q.deleteWatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWatchStmt: %w", cerr)
+		}
+	}
+	if q.ensureMailDropboxStmt != nil {
+		if cerr := q.ensureMailDropboxStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing ensureMailDropboxStmt: %w", cerr)
+		}
+	}
+	if q.enterSchemaMigrationStmt != nil {
+		if cerr := q.enterSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enterSchemaMigrationStmt: %w", cerr)
+		}
+	}
+	if q.failBulkOperationStmt != nil {
+		if cerr := q.failBulkOperationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failBulkOperationStmt: %w", cerr)
+		}
+	}
+	if q.failInterruptedBulkOperationsStmt != nil {
+		if cerr := q.failInterruptedBulkOperationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failInterruptedBulkOperationsStmt: %w", cerr)
+		}
+	}
+	if q.failSchemaMigrationStmt != nil {
+		if cerr := q.failSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failSchemaMigrationStmt: %w", cerr)
+		}
+	}
+	if q.filterOrgObjectIDsStmt != nil {
+		if cerr := q.filterOrgObjectIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing filterOrgObjectIDsStmt: %w", cerr)
+		}
+	}
+	if q.findCreatorByEmailStmt != nil {
+		if cerr := q.findCreatorByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findCreatorByEmailStmt: %w", cerr)
+		}
+	}
 	if q.findObjectByAliasOrIDStringStmt != nil {
 		if cerr := q.findObjectByAliasOrIDStringStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing findObjectByAliasOrIDStringStmt: %w", cerr)
 		}
 	}
+	if q.findObjectByTypeValueStmt != nil {
+		if cerr := q.findObjectByTypeValueStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findObjectByTypeValueStmt: %w", cerr)
+		}
+	}
 	if q.findTagByNormalizedNameStmt != nil {
 		if cerr := q.findTagByNormalizedNameStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing findTagByNormalizedNameStmt: %w", cerr)
 		}
 	}
+	if q.findWatchStmt != nil {
+		if cerr := q.findWatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findWatchStmt: %w", cerr)
+		}
+	}
 	if q.getAccessibleObjectTypesForMemberStmt != nil {
 		if cerr := q.getAccessibleObjectTypesForMemberStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccessibleObjectTypesForMemberStmt: %w", cerr)
 		}
 	}
+	if q.getActiveObjStepStmt != nil {
+		if cerr := q.getActiveObjStepStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveObjStepStmt: %w", cerr)
+		}
+	}
+	if q.getAddressReviewStmt != nil {
+		if cerr := q.getAddressReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAddressReviewStmt: %w", cerr)
+		}
+	}
+	if q.getAttachmentStmt != nil {
+		if cerr := q.getAttachmentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAttachmentStmt: %w", cerr)
+		}
+	}
 	if q.getAutomatedActionStmt != nil {
 		if cerr := q.getAutomatedActionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAutomatedActionStmt: %w", cerr)
 		}
 	}
+	if q.getBulkOperationStmt != nil {
+		if cerr := q.getBulkOperationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBulkOperationStmt: %w", cerr)
+		}
+	}
+	if q.getCalendarFeedStmt != nil {
+		if cerr := q.getCalendarFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCalendarFeedStmt: %w", cerr)
+		}
+	}
 	if q.getCreatorByIDStmt != nil {
 		if cerr := q.getCreatorByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getCreatorByIDStmt: %w", cerr)
@@ -786,6 +1435,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getFactByIDStmt: %w", cerr)
 		}
 	}
+	if q.getFactCommentStmt != nil {
+		if cerr := q.getFactCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFactCommentStmt: %w", cerr)
+		}
+	}
+	if q.getFactDatesByObjectIDsStmt != nil {
+		if cerr := q.getFactDatesByObjectIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFactDatesByObjectIDsStmt: %w", cerr)
+		}
+	}
+	if q.getFactSourceStmt != nil {
+		if cerr := q.getFactSourceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFactSourceStmt: %w", cerr)
+		}
+	}
+	if q.getFactVersionStmt != nil {
+		if cerr := q.getFactVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFactVersionStmt: %w", cerr)
+		}
+	}
 	if q.getFeedStmt != nil {
 		if cerr := q.getFeedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFeedStmt: %w", cerr)
@@ -801,6 +1470,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getGDPStatsStmt: %w", cerr)
 		}
 	}
+	if q.getGraphNodeStmt != nil {
+		if cerr := q.getGraphNodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGraphNodeStmt: %w", cerr)
+		}
+	}
+	if q.getHealthScoreSettingStmt != nil {
+		if cerr := q.getHealthScoreSettingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHealthScoreSettingStmt: %w", cerr)
+		}
+	}
 	if q.getImportTaskStmt != nil {
 		if cerr := q.getImportTaskStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getImportTaskStmt: %w", cerr)
@@ -821,11 +1500,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getListByIDStmt: %w", cerr)
 		}
 	}
+	if q.getMailDropboxStmt != nil {
+		if cerr := q.getMailDropboxStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMailDropboxStmt: %w", cerr)
+		}
+	}
+	if q.getMailDropboxByTokenStmt != nil {
+		if cerr := q.getMailDropboxByTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMailDropboxByTokenStmt: %w", cerr)
+		}
+	}
+	if q.getObjPhotoStmt != nil {
+		if cerr := q.getObjPhotoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getObjPhotoStmt: %w", cerr)
+		}
+	}
 	if q.getObjStepStmt != nil {
 		if cerr := q.getObjStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getObjStepStmt: %w", cerr)
 		}
 	}
+	if q.getObjectByIDStmt != nil {
+		if cerr := q.getObjectByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getObjectByIDStmt: %w", cerr)
+		}
+	}
 	if q.getObjectByIDStringStmt != nil {
 		if cerr := q.getObjectByIDStringStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getObjectByIDStringStmt: %w", cerr)
@@ -836,6 +1535,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getObjectDetailsStmt: %w", cerr)
 		}
 	}
+	if q.getObjectHealthStmt != nil {
+		if cerr := q.getObjectHealthStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getObjectHealthStmt: %w", cerr)
+		}
+	}
 	if q.getObjectTypeByIDStmt != nil {
 		if cerr := q.getObjectTypeByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getObjectTypeByIDStmt: %w", cerr)
@@ -846,6 +1550,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getObjectTypeValueStmt: %w", cerr)
 		}
 	}
+	if q.getObjectTypeValueByIDStmt != nil {
+		if cerr := q.getObjectTypeValueByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getObjectTypeValueByIDStmt: %w", cerr)
+		}
+	}
 	if q.getObjectsByTypeStatsStmt != nil {
 		if cerr := q.getObjectsByTypeStatsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getObjectsByTypeStatsStmt: %w", cerr)
@@ -861,6 +1570,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getOngoingImportTaskStmt: %w", cerr)
 		}
 	}
+	if q.getOngoingSchemaMigrationStmt != nil {
+		if cerr := q.getOngoingSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOngoingSchemaMigrationStmt: %w", cerr)
+		}
+	}
 	if q.getOrgDetailsStmt != nil {
 		if cerr := q.getOrgDetailsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getOrgDetailsStmt: %w", cerr)
@@ -916,6 +1630,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getPublicTopObjectsStmt: %w", cerr)
 		}
 	}
+	if q.getRunningSchemaMigrationStmt != nil {
+		if cerr := q.getRunningSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRunningSchemaMigrationStmt: %w", cerr)
+		}
+	}
+	if q.getSchemaMigrationStmt != nil {
+		if cerr := q.getSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSchemaMigrationStmt: %w", cerr)
+		}
+	}
 	if q.getStepStmt != nil {
 		if cerr := q.getStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getStepStmt: %w", cerr)
@@ -956,6 +1680,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing healthCheckStmt: %w", cerr)
 		}
 	}
+	if q.isOrgCreatorStmt != nil {
+		if cerr := q.isOrgCreatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isOrgCreatorStmt: %w", cerr)
+		}
+	}
+	if q.isOrgFactStmt != nil {
+		if cerr := q.isOrgFactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isOrgFactStmt: %w", cerr)
+		}
+	}
+	if q.isOrgListStmt != nil {
+		if cerr := q.isOrgListStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isOrgListStmt: %w", cerr)
+		}
+	}
+	if q.isOrgObjectTypeStmt != nil {
+		if cerr := q.isOrgObjectTypeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isOrgObjectTypeStmt: %w", cerr)
+		}
+	}
+	if q.isOrgStepStmt != nil {
+		if cerr := q.isOrgStepStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isOrgStepStmt: %w", cerr)
+		}
+	}
+	if q.linkAddressReviewFactsStmt != nil {
+		if cerr := q.linkAddressReviewFactsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing linkAddressReviewFactsStmt: %w", cerr)
+		}
+	}
 	if q.listAccessibleObjectTypesStmt != nil {
 		if cerr := q.listAccessibleObjectTypesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAccessibleObjectTypesStmt: %w", cerr)
@@ -966,16 +1720,66 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listActionExecutionsStmt: %w", cerr)
 		}
 	}
+	if q.listAddressReviewsStmt != nil {
+		if cerr := q.listAddressReviewsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAddressReviewsStmt: %w", cerr)
+		}
+	}
+	if q.listAllCalendarFeedsStmt != nil {
+		if cerr := q.listAllCalendarFeedsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllCalendarFeedsStmt: %w", cerr)
+		}
+	}
+	if q.listAttachmentsByFactIDsStmt != nil {
+		if cerr := q.listAttachmentsByFactIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAttachmentsByFactIDsStmt: %w", cerr)
+		}
+	}
+	if q.listAttachmentsByObjectStmt != nil {
+		if cerr := q.listAttachmentsByObjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAttachmentsByObjectStmt: %w", cerr)
+		}
+	}
 	if q.listAutomatedActionsStmt != nil {
 		if cerr := q.listAutomatedActionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAutomatedActionsStmt: %w", cerr)
 		}
 	}
+	if q.listBulkOperationsStmt != nil {
+		if cerr := q.listBulkOperationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBulkOperationsStmt: %w", cerr)
+		}
+	}
+	if q.listCalendarFeedsStmt != nil {
+		if cerr := q.listCalendarFeedsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCalendarFeedsStmt: %w", cerr)
+		}
+	}
+	if q.listCoMentionEdgesStmt != nil {
+		if cerr := q.listCoMentionEdgesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCoMentionEdgesStmt: %w", cerr)
+		}
+	}
 	if q.listCreatorListsByCreatorIDStmt != nil {
 		if cerr := q.listCreatorListsByCreatorIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listCreatorListsByCreatorIDStmt: %w", cerr)
 		}
 	}
+	if q.listFactCommentsStmt != nil {
+		if cerr := q.listFactCommentsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFactCommentsStmt: %w", cerr)
+		}
+	}
+	if q.listFactReactionsStmt != nil {
+		if cerr := q.listFactReactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFactReactionsStmt: %w", cerr)
+		}
+	}
+	if q.listFactVersionsStmt != nil {
+		if cerr := q.listFactVersionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFactVersionsStmt: %w", cerr)
+		}
+	}
 	if q.listFactsByOrgIDStmt != nil {
 		if cerr := q.listFactsByOrgIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listFactsByOrgIDStmt: %w", cerr)
@@ -986,21 +1790,121 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listFunnelsStmt: %w", cerr)
 		}
 	}
+	if q.listGraphNeighborsStmt != nil {
+		if cerr := q.listGraphNeighborsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGraphNeighborsStmt: %w", cerr)
+		}
+	}
+	if q.listHealthInputsStmt != nil {
+		if cerr := q.listHealthInputsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listHealthInputsStmt: %w", cerr)
+		}
+	}
+	if q.listListWatchesStmt != nil {
+		if cerr := q.listListWatchesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listListWatchesStmt: %w", cerr)
+		}
+	}
 	if q.listListsByOrgIDStmt != nil {
 		if cerr := q.listListsByOrgIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listListsByOrgIDStmt: %w", cerr)
 		}
 	}
+	if q.listMailDropboxTokensStmt != nil {
+		if cerr := q.listMailDropboxTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMailDropboxTokensStmt: %w", cerr)
+		}
+	}
+	if q.listMentionedCreatorsStmt != nil {
+		if cerr := q.listMentionedCreatorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMentionedCreatorsStmt: %w", cerr)
+		}
+	}
+	if q.listObjectConnectionsStmt != nil {
+		if cerr := q.listObjectConnectionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectConnectionsStmt: %w", cerr)
+		}
+	}
+	if q.listObjectFactIDsStmt != nil {
+		if cerr := q.listObjectFactIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectFactIDsStmt: %w", cerr)
+		}
+	}
+	if q.listObjectHealthHistoryStmt != nil {
+		if cerr := q.listObjectHealthHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectHealthHistoryStmt: %w", cerr)
+		}
+	}
+	if q.listObjectHistoryStmt != nil {
+		if cerr := q.listObjectHistoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectHistoryStmt: %w", cerr)
+		}
+	}
+	if q.listObjectNamesStmt != nil {
+		if cerr := q.listObjectNamesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectNamesStmt: %w", cerr)
+		}
+	}
+	if q.listObjectNeighbourhoodStmt != nil {
+		if cerr := q.listObjectNeighbourhoodStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectNeighbourhoodStmt: %w", cerr)
+		}
+	}
+	if q.listObjectRelationsStmt != nil {
+		if cerr := q.listObjectRelationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectRelationsStmt: %w", cerr)
+		}
+	}
+	if q.listObjectStepsInFunnelStmt != nil {
+		if cerr := q.listObjectStepsInFunnelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectStepsInFunnelStmt: %w", cerr)
+		}
+	}
+	if q.listObjectTypeFieldsByOrgStmt != nil {
+		if cerr := q.listObjectTypeFieldsByOrgStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectTypeFieldsByOrgStmt: %w", cerr)
+		}
+	}
+	if q.listObjectTypeValuesByObjectStmt != nil {
+		if cerr := q.listObjectTypeValuesByObjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectTypeValuesByObjectStmt: %w", cerr)
+		}
+	}
+	if q.listObjectTypeValuesForMigrationStmt != nil {
+		if cerr := q.listObjectTypeValuesForMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectTypeValuesForMigrationStmt: %w", cerr)
+		}
+	}
 	if q.listObjectTypesStmt != nil {
 		if cerr := q.listObjectTypesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listObjectTypesStmt: %w", cerr)
 		}
 	}
+	if q.listObjectTypesByNamesStmt != nil {
+		if cerr := q.listObjectTypesByNamesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectTypesByNamesStmt: %w", cerr)
+		}
+	}
+	if q.listObjectWatchersStmt != nil {
+		if cerr := q.listObjectWatchersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectWatchersStmt: %w", cerr)
+		}
+	}
 	if q.listObjectsAdvancedStmt != nil {
 		if cerr := q.listObjectsAdvancedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listObjectsAdvancedStmt: %w", cerr)
 		}
 	}
+	if q.listObjectsByAliasOrIDStringStmt != nil {
+		if cerr := q.listObjectsByAliasOrIDStringStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectsByAliasOrIDStringStmt: %w", cerr)
+		}
+	}
+	if q.listObjectsByEmailStmt != nil {
+		if cerr := q.listObjectsByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listObjectsByEmailStmt: %w", cerr)
+		}
+	}
 	if q.listObjectsByOrgIDStmt != nil {
 		if cerr := q.listObjectsByOrgIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listObjectsByOrgIDStmt: %w", cerr)
@@ -1031,16 +1935,66 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listOrganizationsStmt: %w", cerr)
 		}
 	}
+	if q.listOrphanedAttachmentsStmt != nil {
+		if cerr := q.listOrphanedAttachmentsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOrphanedAttachmentsStmt: %w", cerr)
+		}
+	}
+	if q.listOrphanedObjPhotosStmt != nil {
+		if cerr := q.listOrphanedObjPhotosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOrphanedObjPhotosStmt: %w", cerr)
+		}
+	}
+	if q.listPublicObjectRelationsStmt != nil {
+		if cerr := q.listPublicObjectRelationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPublicObjectRelationsStmt: %w", cerr)
+		}
+	}
+	if q.listRelationTypesStmt != nil {
+		if cerr := q.listRelationTypesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRelationTypesStmt: %w", cerr)
+		}
+	}
+	if q.listRelationsBetweenObjectsStmt != nil {
+		if cerr := q.listRelationsBetweenObjectsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRelationsBetweenObjectsStmt: %w", cerr)
+		}
+	}
+	if q.listSchemaMigrationsStmt != nil {
+		if cerr := q.listSchemaMigrationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSchemaMigrationsStmt: %w", cerr)
+		}
+	}
+	if q.listSharedFactsStmt != nil {
+		if cerr := q.listSharedFactsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSharedFactsStmt: %w", cerr)
+		}
+	}
+	if q.listStaleSchemaMigrationsStmt != nil {
+		if cerr := q.listStaleSchemaMigrationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStaleSchemaMigrationsStmt: %w", cerr)
+		}
+	}
 	if q.listStepsByFunnelStmt != nil {
 		if cerr := q.listStepsByFunnelStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listStepsByFunnelStmt: %w", cerr)
 		}
 	}
+	if q.listStepsByNamesStmt != nil {
+		if cerr := q.listStepsByNamesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStepsByNamesStmt: %w", cerr)
+		}
+	}
 	if q.listTagsStmt != nil {
 		if cerr := q.listTagsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listTagsStmt: %w", cerr)
 		}
 	}
+	if q.listTagsByNamesStmt != nil {
+		if cerr := q.listTagsByNamesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTagsByNamesStmt: %w", cerr)
+		}
+	}
 	if q.listTasksByObjectIDStmt != nil {
 		if cerr := q.listTasksByObjectIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listTasksByObjectIDStmt: %w", cerr)
@@ -1056,16 +2010,91 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listTasksWithFilterStmt: %w", cerr)
 		}
 	}
+	if q.listTrashStmt != nil {
+		if cerr := q.listTrashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTrashStmt: %w", cerr)
+		}
+	}
+	if q.listWatchesByCreatorStmt != nil {
+		if cerr := q.listWatchesByCreatorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWatchesByCreatorStmt: %w", cerr)
+		}
+	}
+	if q.lockFactsStmt != nil {
+		if cerr := q.lockFactsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockFactsStmt: %w", cerr)
+		}
+	}
 	if q.markFeedAsSeenStmt != nil {
 		if cerr := q.markFeedAsSeenStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markFeedAsSeenStmt: %w", cerr)
 		}
 	}
+	if q.matchListFiltersStmt != nil {
+		if cerr := q.matchListFiltersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing matchListFiltersStmt: %w", cerr)
+		}
+	}
 	if q.mergeObjectsStmt != nil {
 		if cerr := q.mergeObjectsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing mergeObjectsStmt: %w", cerr)
 		}
 	}
+	if q.purgeExpiredTrashStmt != nil {
+		if cerr := q.purgeExpiredTrashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeExpiredTrashStmt: %w", cerr)
+		}
+	}
+	if q.purgeFactStmt != nil {
+		if cerr := q.purgeFactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeFactStmt: %w", cerr)
+		}
+	}
+	if q.purgeFunnelStmt != nil {
+		if cerr := q.purgeFunnelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeFunnelStmt: %w", cerr)
+		}
+	}
+	if q.purgeListStmt != nil {
+		if cerr := q.purgeListStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeListStmt: %w", cerr)
+		}
+	}
+	if q.purgeObjStepStmt != nil {
+		if cerr := q.purgeObjStepStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeObjStepStmt: %w", cerr)
+		}
+	}
+	if q.purgeObjectStmt != nil {
+		if cerr := q.purgeObjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeObjectStmt: %w", cerr)
+		}
+	}
+	if q.purgeTagStmt != nil {
+		if cerr := q.purgeTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeTagStmt: %w", cerr)
+		}
+	}
+	if q.purgeTaskStmt != nil {
+		if cerr := q.purgeTaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeTaskStmt: %w", cerr)
+		}
+	}
+	if q.rebuildObjectTypeValueSearchVectorsStmt != nil {
+		if cerr := q.rebuildObjectTypeValueSearchVectorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rebuildObjectTypeValueSearchVectorsStmt: %w", cerr)
+		}
+	}
+	if q.recordFactVersionStmt != nil {
+		if cerr := q.recordFactVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordFactVersionStmt: %w", cerr)
+		}
+	}
+	if q.removeFactReactionStmt != nil {
+		if cerr := q.removeFactReactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing removeFactReactionStmt: %w", cerr)
+		}
+	}
 	if q.removeObjectTypeValueStmt != nil {
 		if cerr := q.removeObjectTypeValueStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing removeObjectTypeValueStmt: %w", cerr)
@@ -1086,16 +2115,111 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing removeTagFromObjectStmt: %w", cerr)
 		}
 	}
+	if q.resolveAddressReviewStmt != nil {
+		if cerr := q.resolveAddressReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resolveAddressReviewStmt: %w", cerr)
+		}
+	}
+	if q.restoreFactStmt != nil {
+		if cerr := q.restoreFactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreFactStmt: %w", cerr)
+		}
+	}
+	if q.restoreFunnelStmt != nil {
+		if cerr := q.restoreFunnelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreFunnelStmt: %w", cerr)
+		}
+	}
+	if q.restoreListStmt != nil {
+		if cerr := q.restoreListStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreListStmt: %w", cerr)
+		}
+	}
+	if q.restoreObjectStmt != nil {
+		if cerr := q.restoreObjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreObjectStmt: %w", cerr)
+		}
+	}
+	if q.restoreTagStmt != nil {
+		if cerr := q.restoreTagStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreTagStmt: %w", cerr)
+		}
+	}
+	if q.restoreTaskStmt != nil {
+		if cerr := q.restoreTaskStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreTaskStmt: %w", cerr)
+		}
+	}
+	if q.revertSchemaMigrationStmt != nil {
+		if cerr := q.revertSchemaMigrationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revertSchemaMigrationStmt: %w", cerr)
+		}
+	}
 	if q.revokeAccessToObjectTypeStmt != nil {
 		if cerr := q.revokeAccessToObjectTypeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing revokeAccessToObjectTypeStmt: %w", cerr)
 		}
 	}
+	if q.searchFactsStmt != nil {
+		if cerr := q.searchFactsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchFactsStmt: %w", cerr)
+		}
+	}
+	if q.searchListsStmt != nil {
+		if cerr := q.searchListsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchListsStmt: %w", cerr)
+		}
+	}
+	if q.searchObjectsStmt != nil {
+		if cerr := q.searchObjectsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchObjectsStmt: %w", cerr)
+		}
+	}
+	if q.searchTagsStmt != nil {
+		if cerr := q.searchTagsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchTagsStmt: %w", cerr)
+		}
+	}
+	if q.searchTasksStmt != nil {
+		if cerr := q.searchTasksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchTasksStmt: %w", cerr)
+		}
+	}
+	if q.setFactObjectsStmt != nil {
+		if cerr := q.setFactObjectsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setFactObjectsStmt: %w", cerr)
+		}
+	}
+	if q.setFuzzyThresholdStmt != nil {
+		if cerr := q.setFuzzyThresholdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setFuzzyThresholdStmt: %w", cerr)
+		}
+	}
+	if q.setObjectOwnerStmt != nil {
+		if cerr := q.setObjectOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setObjectOwnerStmt: %w", cerr)
+		}
+	}
+	if q.setObjectPhotoStmt != nil {
+		if cerr := q.setObjectPhotoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setObjectPhotoStmt: %w", cerr)
+		}
+	}
+	if q.setObjectTypeValuesStmt != nil {
+		if cerr := q.setObjectTypeValuesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setObjectTypeValuesStmt: %w", cerr)
+		}
+	}
 	if q.softDeleteObjStepStmt != nil {
 		if cerr := q.softDeleteObjStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing softDeleteObjStepStmt: %w", cerr)
 		}
 	}
+	if q.suggestObjectsStmt != nil {
+		if cerr := q.suggestObjectsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing suggestObjectsStmt: %w", cerr)
+		}
+	}
 	if q.syncObjectAliasesStmt != nil {
 		if cerr := q.syncObjectAliasesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing syncObjectAliasesStmt: %w", cerr)
@@ -1116,6 +2240,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateAutomatedActionStmt: %w", cerr)
 		}
 	}
+	if q.updateBulkOperationProgressStmt != nil {
+		if cerr := q.updateBulkOperationProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateBulkOperationProgressStmt: %w", cerr)
+		}
+	}
+	if q.updateBulkOperationStatusStmt != nil {
+		if cerr := q.updateBulkOperationStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateBulkOperationStatusStmt: %w", cerr)
+		}
+	}
+	if q.updateCalendarFeedSyncStmt != nil {
+		if cerr := q.updateCalendarFeedSyncStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateCalendarFeedSyncStmt: %w", cerr)
+		}
+	}
 	if q.updateCreatorListStmt != nil {
 		if cerr := q.updateCreatorListStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateCreatorListStmt: %w", cerr)
@@ -1141,6 +2280,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateFactStmt: %w", cerr)
 		}
 	}
+	if q.updateFactCommentStmt != nil {
+		if cerr := q.updateFactCommentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateFactCommentStmt: %w", cerr)
+		}
+	}
 	if q.updateFunnelStmt != nil {
 		if cerr := q.updateFunnelStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateFunnelStmt: %w", cerr)
@@ -1186,6 +2330,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateObjectTypeStmt: %w", cerr)
 		}
 	}
+	if q.updateObjectTypeFieldsStmt != nil {
+		if cerr := q.updateObjectTypeFieldsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateObjectTypeFieldsStmt: %w", cerr)
+		}
+	}
 	if q.updateObjectTypeValueStmt != nil {
 		if cerr := q.updateObjectTypeValueStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateObjectTypeValueStmt: %w", cerr)
@@ -1196,6 +2345,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateOrgDetailsStmt: %w", cerr)
 		}
 	}
+	if q.updateSchemaMigrationProgressStmt != nil {
+		if cerr := q.updateSchemaMigrationProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSchemaMigrationProgressStmt: %w", cerr)
+		}
+	}
+	if q.updateSchemaMigrationStatusStmt != nil {
+		if cerr := q.updateSchemaMigrationStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSchemaMigrationStatusStmt: %w", cerr)
+		}
+	}
 	if q.updateStepStmt != nil {
 		if cerr := q.updateStepStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateStepStmt: %w", cerr)
@@ -1211,6 +2370,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateTaskStmt: %w", cerr)
 		}
 	}
+	if q.updateWatchEventsStmt != nil {
+		if cerr := q.updateWatchEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateWatchEventsStmt: %w", cerr)
+		}
+	}
+	if q.upsertAddressReviewStmt != nil {
+		if cerr := q.upsertAddressReviewStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertAddressReviewStmt: %w", cerr)
+		}
+	}
+	if q.upsertHealthScoreSettingStmt != nil {
+		if cerr := q.upsertHealthScoreSettingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertHealthScoreSettingStmt: %w", cerr)
+		}
+	}
+	if q.upsertMailDropboxStmt != nil {
+		if cerr := q.upsertMailDropboxStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMailDropboxStmt: %w", cerr)
+		}
+	}
+	if q.upsertObjectHealthBatchStmt != nil {
+		if cerr := q.upsertObjectHealthBatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertObjectHealthBatchStmt: %w", cerr)
+		}
+	}
 	if q.upsertObjectTypeValueStmt != nil {
 		if cerr := q.upsertObjectTypeValueStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertObjectTypeValueStmt: %w", cerr)
@@ -1260,14 +2444,20 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 type Queries struct {
 	db                                       DBTX
 	tx                                       *sql.Tx
+	addAddressReviewFactStmt                 *sql.Stmt
+	addFactReactionStmt                      *sql.Stmt
 	addObjectTypeValueStmt                   *sql.Stmt
 	addObjectsToFactStmt                     *sql.Stmt
 	addObjectsToTaskStmt                     *sql.Stmt
 	addTagAndStepToFilteredObjectsStmt       *sql.Stmt
 	addTagToObjectStmt                       *sql.Stmt
+	backupObjectTypeValueStmt                *sql.Stmt
+	completeBulkOperationStmt                *sql.Stmt
 	completeImportTaskStmt                   *sql.Stmt
+	completeSchemaMigrationStmt              *sql.Stmt
 	countAccessibleObjectTypesStmt           *sql.Stmt
 	countActionExecutionsStmt                *sql.Stmt
+	countAddressReviewsStmt                  *sql.Stmt
 	countAutomatedActionsStmt                *sql.Stmt
 	countFactsByOrgIDStmt                    *sql.Stmt
 	countFunnelsStmt                         *sql.Stmt
@@ -1280,63 +2470,111 @@ type Queries struct {
 	countObjectsByTypeWithAdvancedFilterStmt *sql.Stmt
 	countObjectsForStepStmt                  *sql.Stmt
 	countOngoingTaskStmt                     *sql.Stmt
+	countOrgTagsStmt                         *sql.Stmt
 	countTagsStmt                            *sql.Stmt
 	countTasksByObjectIDStmt                 *sql.Stmt
+	countTasksByObjectIDsStmt                *sql.Stmt
 	countTasksByOrgIDStmt                    *sql.Stmt
 	countTasksWithFilterStmt                 *sql.Stmt
 	countUnseenFeedStmt                      *sql.Stmt
+	countUnseenMentionsStmt                  *sql.Stmt
 	createActionExecutionStmt                *sql.Stmt
+	createAttachmentStmt                     *sql.Stmt
 	createAutomatedActionStmt                *sql.Stmt
+	createBulkOperationStmt                  *sql.Stmt
+	createCalendarFeedStmt                   *sql.Stmt
 	createCreatorStmt                        *sql.Stmt
 	createCreatorListStmt                    *sql.Stmt
 	createFactStmt                           *sql.Stmt
+	createFactCommentStmt                    *sql.Stmt
+	createFactSourceStmt                     *sql.Stmt
 	createFeedStmt                           *sql.Stmt
 	createFunnelStmt                         *sql.Stmt
 	createImportTaskStmt                     *sql.Stmt
 	createListStmt                           *sql.Stmt
+	createObjPhotoStmt                       *sql.Stmt
 	createObjStepStmt                        *sql.Stmt
 	createObjectStmt                         *sql.Stmt
+	createObjectHistoryStmt                  *sql.Stmt
+	createObjectRelationStmt                 *sql.Stmt
 	createObjectTypeStmt                     *sql.Stmt
 	createOrganizationStmt                   *sql.Stmt
+	createSchemaMigrationStmt                *sql.Stmt
 	createStepStmt                           *sql.Stmt
 	createTagStmt                            *sql.Stmt
 	createTaskStmt                           *sql.Stmt
+	createWatchStmt                          *sql.Stmt
 	deleteActionOldExecutionsStmt            *sql.Stmt
+	deleteAttachmentStmt                     *sql.Stmt
 	deleteAutomatedActionStmt                *sql.Stmt
+	deleteCalendarFeedStmt                   *sql.Stmt
 	deleteCreatorStmt                        *sql.Stmt
 	deleteCreatorListStmt                    *sql.Stmt
 	deleteFactStmt                           *sql.Stmt
+	deleteFactCommentStmt                    *sql.Stmt
 	deleteFunnelStmt                         *sql.Stmt
 	deleteListStmt                           *sql.Stmt
+	deleteObjPhotoStmt                       *sql.Stmt
 	deleteObjectStmt                         *sql.Stmt
+	deleteObjectRelationStmt                 *sql.Stmt
 	deleteObjectTypeStmt                     *sql.Stmt
+	deleteOrphanedAttachmentStmt             *sql.Stmt
+	deleteSchemaMigrationBackupStmt          *sql.Stmt
 	deleteStepStmt                           *sql.Stmt
 	deleteTagStmt                            *sql.Stmt
 	deleteTaskStmt                           *sql.Stmt
+	deleteWatchStmt                          *sql.Stmt
+	ensureMailDropboxStmt                    *sql.Stmt
+	enterSchemaMigrationStmt                 *sql.Stmt
+	failBulkOperationStmt                    *sql.Stmt
+	failInterruptedBulkOperationsStmt        *sql.Stmt
+	failSchemaMigrationStmt                  *sql.Stmt
+	filterOrgObjectIDsStmt                   *sql.Stmt
+	findCreatorByEmailStmt                   *sql.Stmt
 	findObjectByAliasOrIDStringStmt          *sql.Stmt
+	findObjectByTypeValueStmt                *sql.Stmt
 	findTagByNormalizedNameStmt              *sql.Stmt
+	findWatchStmt                            *sql.Stmt
 	getAccessibleObjectTypesForMemberStmt    *sql.Stmt
+	getActiveObjStepStmt                     *sql.Stmt
+	getAddressReviewStmt                     *sql.Stmt
+	getAttachmentStmt                        *sql.Stmt
 	getAutomatedActionStmt                   *sql.Stmt
+	getBulkOperationStmt                     *sql.Stmt
+	getCalendarFeedStmt                      *sql.Stmt
 	getCreatorByIDStmt                       *sql.Stmt
 	getCreatorByUsernameStmt                 *sql.Stmt
 	getCreatorDailyActivityStmt              *sql.Stmt
 	getCreatorListByIDStmt                   *sql.Stmt
 	getFactByIDStmt                          *sql.Stmt
+	getFactCommentStmt                       *sql.Stmt
+	getFactDatesByObjectIDsStmt              *sql.Stmt
+	getFactSourceStmt                        *sql.Stmt
+	getFactVersionStmt                       *sql.Stmt
 	getFeedStmt                              *sql.Stmt
 	getFunnelStmt                            *sql.Stmt
 	getGDPStatsStmt                          *sql.Stmt
+	getGraphNodeStmt                         *sql.Stmt
+	getHealthScoreSettingStmt                *sql.Stmt
 	getImportTaskStmt                        *sql.Stmt
 	getImportTaskHistoryStmt                 *sql.Stmt
 	getLatestExecutionStmt                   *sql.Stmt
 	getListByIDStmt                          *sql.Stmt
+	getMailDropboxStmt                       *sql.Stmt
+	getMailDropboxByTokenStmt                *sql.Stmt
+	getObjPhotoStmt                          *sql.Stmt
 	getObjStepStmt                           *sql.Stmt
+	getObjectByIDStmt                        *sql.Stmt
 	getObjectByIDStringStmt                  *sql.Stmt
 	getObjectDetailsStmt                     *sql.Stmt
+	getObjectHealthStmt                      *sql.Stmt
 	getObjectTypeByIDStmt                    *sql.Stmt
 	getObjectTypeValueStmt                   *sql.Stmt
+	getObjectTypeValueByIDStmt               *sql.Stmt
 	getObjectsByTypeStatsStmt                *sql.Stmt
 	getObjectsForStepStmt                    *sql.Stmt
 	getOngoingImportTaskStmt                 *sql.Stmt
+	getOngoingSchemaMigrationStmt            *sql.Stmt
 	getOrgDetailsStmt                        *sql.Stmt
 	getPendingActionsStmt                    *sql.Stmt
 	getPublicObjectStmt                      *sql.Stmt
@@ -1348,6 +2586,8 @@ type Queries struct {
 	getPublicRecentFactsByTypeStmt           *sql.Stmt
 	getPublicStatsStmt                       *sql.Stmt
 	getPublicTopObjectsStmt                  *sql.Stmt
+	getRunningSchemaMigrationStmt            *sql.Stmt
+	getSchemaMigrationStmt                   *sql.Stmt
 	getStepStmt                              *sql.Stmt
 	getTagByIDStmt                           *sql.Stmt
 	getTagsByIDsStmt                         *sql.Stmt
@@ -1356,43 +2596,127 @@ type Queries struct {
 	hardDeleteObjStepStmt                    *sql.Stmt
 	hasAccessToObjectTypeStmt                *sql.Stmt
 	healthCheckStmt                          *sql.Stmt
+	isOrgCreatorStmt                         *sql.Stmt
+	isOrgFactStmt                            *sql.Stmt
+	isOrgListStmt                            *sql.Stmt
+	isOrgObjectTypeStmt                      *sql.Stmt
+	isOrgStepStmt                            *sql.Stmt
+	linkAddressReviewFactsStmt               *sql.Stmt
 	listAccessibleObjectTypesStmt            *sql.Stmt
 	listActionExecutionsStmt                 *sql.Stmt
+	listAddressReviewsStmt                   *sql.Stmt
+	listAllCalendarFeedsStmt                 *sql.Stmt
+	listAttachmentsByFactIDsStmt             *sql.Stmt
+	listAttachmentsByObjectStmt              *sql.Stmt
 	listAutomatedActionsStmt                 *sql.Stmt
+	listBulkOperationsStmt                   *sql.Stmt
+	listCalendarFeedsStmt                    *sql.Stmt
+	listCoMentionEdgesStmt                   *sql.Stmt
 	listCreatorListsByCreatorIDStmt          *sql.Stmt
+	listFactCommentsStmt                     *sql.Stmt
+	listFactReactionsStmt                    *sql.Stmt
+	listFactVersionsStmt                     *sql.Stmt
 	listFactsByOrgIDStmt                     *sql.Stmt
 	listFunnelsStmt                          *sql.Stmt
+	listGraphNeighborsStmt                   *sql.Stmt
+	listHealthInputsStmt                     *sql.Stmt
+	listListWatchesStmt                      *sql.Stmt
 	listListsByOrgIDStmt                     *sql.Stmt
+	listMailDropboxTokensStmt                *sql.Stmt
+	listMentionedCreatorsStmt                *sql.Stmt
+	listObjectConnectionsStmt                *sql.Stmt
+	listObjectFactIDsStmt                    *sql.Stmt
+	listObjectHealthHistoryStmt              *sql.Stmt
+	listObjectHistoryStmt                    *sql.Stmt
+	listObjectNamesStmt                      *sql.Stmt
+	listObjectNeighbourhoodStmt              *sql.Stmt
+	listObjectRelationsStmt                  *sql.Stmt
+	listObjectStepsInFunnelStmt              *sql.Stmt
+	listObjectTypeFieldsByOrgStmt            *sql.Stmt
+	listObjectTypeValuesByObjectStmt         *sql.Stmt
+	listObjectTypeValuesForMigrationStmt     *sql.Stmt
 	listObjectTypesStmt                      *sql.Stmt
+	listObjectTypesByNamesStmt               *sql.Stmt
+	listObjectWatchersStmt                   *sql.Stmt
 	listObjectsAdvancedStmt                  *sql.Stmt
+	listObjectsByAliasOrIDStringStmt         *sql.Stmt
+	listObjectsByEmailStmt                   *sql.Stmt
 	listObjectsByOrgIDStmt                   *sql.Stmt
 	listObjectsByTaskIDStmt                  *sql.Stmt
 	listObjectsByTypeWithAdvancedFilterStmt  *sql.Stmt
 	listObjectsWithNormalizedDataStmt        *sql.Stmt
 	listOrgMembersStmt                       *sql.Stmt
 	listOrganizationsStmt                    *sql.Stmt
+	listOrphanedAttachmentsStmt              *sql.Stmt
+	listOrphanedObjPhotosStmt                *sql.Stmt
+	listPublicObjectRelationsStmt            *sql.Stmt
+	listRelationTypesStmt                    *sql.Stmt
+	listRelationsBetweenObjectsStmt          *sql.Stmt
+	listSchemaMigrationsStmt                 *sql.Stmt
+	listSharedFactsStmt                      *sql.Stmt
+	listStaleSchemaMigrationsStmt            *sql.Stmt
 	listStepsByFunnelStmt                    *sql.Stmt
+	listStepsByNamesStmt                     *sql.Stmt
 	listTagsStmt                             *sql.Stmt
+	listTagsByNamesStmt                      *sql.Stmt
 	listTasksByObjectIDStmt                  *sql.Stmt
 	listTasksByOrgIDStmt                     *sql.Stmt
 	listTasksWithFilterStmt                  *sql.Stmt
+	listTrashStmt                            *sql.Stmt
+	listWatchesByCreatorStmt                 *sql.Stmt
+	lockFactsStmt                            *sql.Stmt
 	markFeedAsSeenStmt                       *sql.Stmt
+	matchListFiltersStmt                     *sql.Stmt
 	mergeObjectsStmt                         *sql.Stmt
+	purgeExpiredTrashStmt                    *sql.Stmt
+	purgeFactStmt                            *sql.Stmt
+	purgeFunnelStmt                          *sql.Stmt
+	purgeListStmt                            *sql.Stmt
+	purgeObjStepStmt                         *sql.Stmt
+	purgeObjectStmt                          *sql.Stmt
+	purgeTagStmt                             *sql.Stmt
+	purgeTaskStmt                            *sql.Stmt
+	rebuildObjectTypeValueSearchVectorsStmt  *sql.Stmt
+	recordFactVersionStmt                    *sql.Stmt
+	removeFactReactionStmt                   *sql.Stmt
 	removeObjectTypeValueStmt                *sql.Stmt
 	removeObjectsFromFactStmt                *sql.Stmt
 	removeObjectsFromTaskStmt                *sql.Stmt
 	removeTagFromObjectStmt                  *sql.Stmt
+	resolveAddressReviewStmt                 *sql.Stmt
+	restoreFactStmt                          *sql.Stmt
+	restoreFunnelStmt                        *sql.Stmt
+	restoreListStmt                          *sql.Stmt
+	restoreObjectStmt                        *sql.Stmt
+	restoreTagStmt                           *sql.Stmt
+	restoreTaskStmt                          *sql.Stmt
+	revertSchemaMigrationStmt                *sql.Stmt
 	revokeAccessToObjectTypeStmt             *sql.Stmt
+	searchFactsStmt                          *sql.Stmt
+	searchListsStmt                          *sql.Stmt
+	searchObjectsStmt                        *sql.Stmt
+	searchTagsStmt                           *sql.Stmt
+	searchTasksStmt                          *sql.Stmt
+	setFactObjectsStmt                       *sql.Stmt
+	setFuzzyThresholdStmt                    *sql.Stmt
+	setObjectOwnerStmt                       *sql.Stmt
+	setObjectPhotoStmt                       *sql.Stmt
+	setObjectTypeValuesStmt                  *sql.Stmt
 	softDeleteObjStepStmt                    *sql.Stmt
+	suggestObjectsStmt                       *sql.Stmt
 	syncObjectAliasesStmt                    *sql.Stmt
 	updateActionExecutionStmt                *sql.Stmt
 	updateActionLastRunStmt                  *sql.Stmt
 	updateAutomatedActionStmt                *sql.Stmt
+	updateBulkOperationProgressStmt          *sql.Stmt
+	updateBulkOperationStatusStmt            *sql.Stmt
+	updateCalendarFeedSyncStmt               *sql.Stmt
 	updateCreatorListStmt                    *sql.Stmt
 	updateCreatorPasswordStmt                *sql.Stmt
 	updateCreatorProfileStmt                 *sql.Stmt
 	updateCreatorRoleAndStatusStmt           *sql.Stmt
 	updateFactStmt                           *sql.Stmt
+	updateFactCommentStmt                    *sql.Stmt
 	updateFunnelStmt                         *sql.Stmt
 	updateImportTaskErrorStmt                *sql.Stmt
 	updateImportTaskProgressStmt             *sql.Stmt
@@ -1402,11 +2726,19 @@ type Queries struct {
 	updateObjStepSubStatusStmt               *sql.Stmt
 	updateObjectStmt                         *sql.Stmt
 	updateObjectTypeStmt                     *sql.Stmt
+	updateObjectTypeFieldsStmt               *sql.Stmt
 	updateObjectTypeValueStmt                *sql.Stmt
 	updateOrgDetailsStmt                     *sql.Stmt
+	updateSchemaMigrationProgressStmt        *sql.Stmt
+	updateSchemaMigrationStatusStmt          *sql.Stmt
 	updateStepStmt                           *sql.Stmt
 	updateTagStmt                            *sql.Stmt
 	updateTaskStmt                           *sql.Stmt
+	updateWatchEventsStmt                    *sql.Stmt
+	upsertAddressReviewStmt                  *sql.Stmt
+	upsertHealthScoreSettingStmt             *sql.Stmt
+	upsertMailDropboxStmt                    *sql.Stmt
+	upsertObjectHealthBatchStmt              *sql.Stmt
 	upsertObjectTypeValueStmt                *sql.Stmt
 	validateMergeObjectsStmt                 *sql.Stmt
 }
@@ -1415,14 +2747,20 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db:                                       tx,
 		tx:                                       tx,
+		addAddressReviewFactStmt:                 q.addAddressReviewFactStmt,
+		addFactReactionStmt:                      q.addFactReactionStmt,
 		addObjectTypeValueStmt:                   q.addObjectTypeValueStmt,
 		addObjectsToFactStmt:                     q.addObjectsToFactStmt,
 		addObjectsToTaskStmt:                     q.addObjectsToTaskStmt,
 		addTagAndStepToFilteredObjectsStmt:       q.addTagAndStepToFilteredObjectsStmt,
 		addTagToObjectStmt:                       q.addTagToObjectStmt,
+		backupObjectTypeValueStmt:                q.backupObjectTypeValueStmt,
+		completeBulkOperationStmt:                q.completeBulkOperationStmt,
 		completeImportTaskStmt:                   q.completeImportTaskStmt,
+		completeSchemaMigrationStmt:              q.completeSchemaMigrationStmt,
 		countAccessibleObjectTypesStmt:           q.countAccessibleObjectTypesStmt,
 		countActionExecutionsStmt:                q.countActionExecutionsStmt,
+		countAddressReviewsStmt:                  q.countAddressReviewsStmt,
 		countAutomatedActionsStmt:                q.countAutomatedActionsStmt,
 		countFactsByOrgIDStmt:                    q.countFactsByOrgIDStmt,
 		countFunnelsStmt:                         q.countFunnelsStmt,
@@ -1435,63 +2773,111 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		countObjectsByTypeWithAdvancedFilterStmt: q.countObjectsByTypeWithAdvancedFilterStmt,
 		countObjectsForStepStmt:                  q.countObjectsForStepStmt,
 		countOngoingTaskStmt:                     q.countOngoingTaskStmt,
+		countOrgTagsStmt:                         q.countOrgTagsStmt,
 		countTagsStmt:                            q.countTagsStmt,
 		countTasksByObjectIDStmt:                 q.countTasksByObjectIDStmt,
+		countTasksByObjectIDsStmt:                q.countTasksByObjectIDsStmt,
 		countTasksByOrgIDStmt:                    q.countTasksByOrgIDStmt,
 		countTasksWithFilterStmt:                 q.countTasksWithFilterStmt,
 		countUnseenFeedStmt:                      q.countUnseenFeedStmt,
+		countUnseenMentionsStmt:                  q.countUnseenMentionsStmt,
 		createActionExecutionStmt:                q.createActionExecutionStmt,
+		createAttachmentStmt:                     q.createAttachmentStmt,
 		createAutomatedActionStmt:                q.createAutomatedActionStmt,
+		createBulkOperationStmt:                  q.createBulkOperationStmt,
+		createCalendarFeedStmt:                   q.createCalendarFeedStmt,
 		createCreatorStmt:                        q.createCreatorStmt,
 		createCreatorListStmt:                    q.createCreatorListStmt,
 		createFactStmt:                           q.createFactStmt,
+		createFactCommentStmt:                    q.createFactCommentStmt,
+		createFactSourceStmt:                     q.createFactSourceStmt,
 		createFeedStmt:                           q.createFeedStmt,
 		createFunnelStmt:                         q.createFunnelStmt,
 		createImportTaskStmt:                     q.createImportTaskStmt,
 		createListStmt:                           q.createListStmt,
+		createObjPhotoStmt:                       q.createObjPhotoStmt,
 		createObjStepStmt:                        q.createObjStepStmt,
 		createObjectStmt:                         q.createObjectStmt,
+		createObjectHistoryStmt:                  q.createObjectHistoryStmt,
+		createObjectRelationStmt:                 q.createObjectRelationStmt,
 		createObjectTypeStmt:                     q.createObjectTypeStmt,
 		createOrganizationStmt:                   q.createOrganizationStmt,
+		createSchemaMigrationStmt:                q.createSchemaMigrationStmt,
 		createStepStmt:                           q.createStepStmt,
 		createTagStmt:                            q.createTagStmt,
 		createTaskStmt:                           q.createTaskStmt,
+		createWatchStmt:                          q.createWatchStmt,
 		deleteActionOldExecutionsStmt:            q.deleteActionOldExecutionsStmt,
+		deleteAttachmentStmt:                     q.deleteAttachmentStmt,
 		deleteAutomatedActionStmt:                q.deleteAutomatedActionStmt,
+		deleteCalendarFeedStmt:                   q.deleteCalendarFeedStmt,
 		deleteCreatorStmt:                        q.deleteCreatorStmt,
 		deleteCreatorListStmt:                    q.deleteCreatorListStmt,
 		deleteFactStmt:                           q.deleteFactStmt,
+		deleteFactCommentStmt:                    q.deleteFactCommentStmt,
 		deleteFunnelStmt:                         q.deleteFunnelStmt,
 		deleteListStmt:                           q.deleteListStmt,
+		deleteObjPhotoStmt:                       q.deleteObjPhotoStmt,
 		deleteObjectStmt:                         q.deleteObjectStmt,
+		deleteObjectRelationStmt:                 q.deleteObjectRelationStmt,
 		deleteObjectTypeStmt:                     q.deleteObjectTypeStmt,
+		deleteOrphanedAttachmentStmt:             q.deleteOrphanedAttachmentStmt,
+		deleteSchemaMigrationBackupStmt:          q.deleteSchemaMigrationBackupStmt,
 		deleteStepStmt:                           q.deleteStepStmt,
 		deleteTagStmt:                            q.deleteTagStmt,
 		deleteTaskStmt:                           q.deleteTaskStmt,
+		deleteWatchStmt:                          q.deleteWatchStmt,
+		ensureMailDropboxStmt:                    q.ensureMailDropboxStmt,
+		enterSchemaMigrationStmt:                 q.enterSchemaMigrationStmt,
+		failBulkOperationStmt:                    q.failBulkOperationStmt,
+		failInterruptedBulkOperationsStmt:        q.failInterruptedBulkOperationsStmt,
+		failSchemaMigrationStmt:                  q.failSchemaMigrationStmt,
+		filterOrgObjectIDsStmt:                   q.filterOrgObjectIDsStmt,
+		findCreatorByEmailStmt:                   q.findCreatorByEmailStmt,
 		findObjectByAliasOrIDStringStmt:          q.findObjectByAliasOrIDStringStmt,
+		findObjectByTypeValueStmt:                q.findObjectByTypeValueStmt,
 		findTagByNormalizedNameStmt:              q.findTagByNormalizedNameStmt,
+		findWatchStmt:                            q.findWatchStmt,
 		getAccessibleObjectTypesForMemberStmt:    q.getAccessibleObjectTypesForMemberStmt,
+		getActiveObjStepStmt:                     q.getActiveObjStepStmt,
+		getAddressReviewStmt:                     q.getAddressReviewStmt,
+		getAttachmentStmt:                        q.getAttachmentStmt,
 		getAutomatedActionStmt:                   q.getAutomatedActionStmt,
+		getBulkOperationStmt:                     q.getBulkOperationStmt,
+		getCalendarFeedStmt:                      q.getCalendarFeedStmt,
 		getCreatorByIDStmt:                       q.getCreatorByIDStmt,
 		getCreatorByUsernameStmt:                 q.getCreatorByUsernameStmt,
 		getCreatorDailyActivityStmt:              q.getCreatorDailyActivityStmt,
 		getCreatorListByIDStmt:                   q.getCreatorListByIDStmt,
 		getFactByIDStmt:                          q.getFactByIDStmt,
+		getFactCommentStmt:                       q.getFactCommentStmt,
+		getFactDatesByObjectIDsStmt:              q.getFactDatesByObjectIDsStmt,
+		getFactSourceStmt:                        q.getFactSourceStmt,
+		getFactVersionStmt:                       q.getFactVersionStmt,
 		getFeedStmt:                              q.getFeedStmt,
 		getFunnelStmt:                            q.getFunnelStmt,
 		getGDPStatsStmt:                          q.getGDPStatsStmt,
+		getGraphNodeStmt:                         q.getGraphNodeStmt,
+		getHealthScoreSettingStmt:                q.getHealthScoreSettingStmt,
 		getImportTaskStmt:                        q.getImportTaskStmt,
 		getImportTaskHistoryStmt:                 q.getImportTaskHistoryStmt,
 		getLatestExecutionStmt:                   q.getLatestExecutionStmt,
 		getListByIDStmt:                          q.getListByIDStmt,
+		getMailDropboxStmt:                       q.getMailDropboxStmt,
+		getMailDropboxByTokenStmt:                q.getMailDropboxByTokenStmt,
+		getObjPhotoStmt:                          q.getObjPhotoStmt,
 		getObjStepStmt:                           q.getObjStepStmt,
+		getObjectByIDStmt:                        q.getObjectByIDStmt,
 		getObjectByIDStringStmt:                  q.getObjectByIDStringStmt,
 		getObjectDetailsStmt:                     q.getObjectDetailsStmt,
+		getObjectHealthStmt:                      q.getObjectHealthStmt,
 		getObjectTypeByIDStmt:                    q.getObjectTypeByIDStmt,
 		getObjectTypeValueStmt:                   q.getObjectTypeValueStmt,
+		getObjectTypeValueByIDStmt:               q.getObjectTypeValueByIDStmt,
 		getObjectsByTypeStatsStmt:                q.getObjectsByTypeStatsStmt,
 		getObjectsForStepStmt:                    q.getObjectsForStepStmt,
 		getOngoingImportTaskStmt:                 q.getOngoingImportTaskStmt,
+		getOngoingSchemaMigrationStmt:            q.getOngoingSchemaMigrationStmt,
 		getOrgDetailsStmt:                        q.getOrgDetailsStmt,
 		getPendingActionsStmt:                    q.getPendingActionsStmt,
 		getPublicObjectStmt:                      q.getPublicObjectStmt,
@@ -1503,6 +2889,8 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getPublicRecentFactsByTypeStmt:           q.getPublicRecentFactsByTypeStmt,
 		getPublicStatsStmt:                       q.getPublicStatsStmt,
 		getPublicTopObjectsStmt:                  q.getPublicTopObjectsStmt,
+		getRunningSchemaMigrationStmt:            q.getRunningSchemaMigrationStmt,
+		getSchemaMigrationStmt:                   q.getSchemaMigrationStmt,
 		getStepStmt:                              q.getStepStmt,
 		getTagByIDStmt:                           q.getTagByIDStmt,
 		getTagsByIDsStmt:                         q.getTagsByIDsStmt,
@@ -1511,43 +2899,127 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		hardDeleteObjStepStmt:                    q.hardDeleteObjStepStmt,
 		hasAccessToObjectTypeStmt:                q.hasAccessToObjectTypeStmt,
 		healthCheckStmt:                          q.healthCheckStmt,
+		isOrgCreatorStmt:                         q.isOrgCreatorStmt,
+		isOrgFactStmt:                            q.isOrgFactStmt,
+		isOrgListStmt:                            q.isOrgListStmt,
+		isOrgObjectTypeStmt:                      q.isOrgObjectTypeStmt,
+		isOrgStepStmt:                            q.isOrgStepStmt,
+		linkAddressReviewFactsStmt:               q.linkAddressReviewFactsStmt,
 		listAccessibleObjectTypesStmt:            q.listAccessibleObjectTypesStmt,
 		listActionExecutionsStmt:                 q.listActionExecutionsStmt,
+		listAddressReviewsStmt:                   q.listAddressReviewsStmt,
+		listAllCalendarFeedsStmt:                 q.listAllCalendarFeedsStmt,
+		listAttachmentsByFactIDsStmt:             q.listAttachmentsByFactIDsStmt,
+		listAttachmentsByObjectStmt:              q.listAttachmentsByObjectStmt,
 		listAutomatedActionsStmt:                 q.listAutomatedActionsStmt,
+		listBulkOperationsStmt:                   q.listBulkOperationsStmt,
+		listCalendarFeedsStmt:                    q.listCalendarFeedsStmt,
+		listCoMentionEdgesStmt:                   q.listCoMentionEdgesStmt,
 		listCreatorListsByCreatorIDStmt:          q.listCreatorListsByCreatorIDStmt,
+		listFactCommentsStmt:                     q.listFactCommentsStmt,
+		listFactReactionsStmt:                    q.listFactReactionsStmt,
+		listFactVersionsStmt:                     q.listFactVersionsStmt,
 		listFactsByOrgIDStmt:                     q.listFactsByOrgIDStmt,
 		listFunnelsStmt:                          q.listFunnelsStmt,
+		listGraphNeighborsStmt:                   q.listGraphNeighborsStmt,
+		listHealthInputsStmt:                     q.listHealthInputsStmt,
+		listListWatchesStmt:                      q.listListWatchesStmt,
 		listListsByOrgIDStmt:                     q.listListsByOrgIDStmt,
+		listMailDropboxTokensStmt:                q.listMailDropboxTokensStmt,
+		listMentionedCreatorsStmt:                q.listMentionedCreatorsStmt,
+		listObjectConnectionsStmt:                q.listObjectConnectionsStmt,
+		listObjectFactIDsStmt:                    q.listObjectFactIDsStmt,
+		listObjectHealthHistoryStmt:              q.listObjectHealthHistoryStmt,
+		listObjectHistoryStmt:                    q.listObjectHistoryStmt,
+		listObjectNamesStmt:                      q.listObjectNamesStmt,
+		listObjectNeighbourhoodStmt:              q.listObjectNeighbourhoodStmt,
+		listObjectRelationsStmt:                  q.listObjectRelationsStmt,
+		listObjectStepsInFunnelStmt:              q.listObjectStepsInFunnelStmt,
+		listObjectTypeFieldsByOrgStmt:            q.listObjectTypeFieldsByOrgStmt,
+		listObjectTypeValuesByObjectStmt:         q.listObjectTypeValuesByObjectStmt,
+		listObjectTypeValuesForMigrationStmt:     q.listObjectTypeValuesForMigrationStmt,
 		listObjectTypesStmt:                      q.listObjectTypesStmt,
+		listObjectTypesByNamesStmt:               q.listObjectTypesByNamesStmt,
+		listObjectWatchersStmt:                   q.listObjectWatchersStmt,
 		listObjectsAdvancedStmt:                  q.listObjectsAdvancedStmt,
+		listObjectsByAliasOrIDStringStmt:         q.listObjectsByAliasOrIDStringStmt,
+		listObjectsByEmailStmt:                   q.listObjectsByEmailStmt,
 		listObjectsByOrgIDStmt:                   q.listObjectsByOrgIDStmt,
 		listObjectsByTaskIDStmt:                  q.listObjectsByTaskIDStmt,
 		listObjectsByTypeWithAdvancedFilterStmt:  q.listObjectsByTypeWithAdvancedFilterStmt,
 		listObjectsWithNormalizedDataStmt:        q.listObjectsWithNormalizedDataStmt,
 		listOrgMembersStmt:                       q.listOrgMembersStmt,
 		listOrganizationsStmt:                    q.listOrganizationsStmt,
+		listOrphanedAttachmentsStmt:              q.listOrphanedAttachmentsStmt,
+		listOrphanedObjPhotosStmt:                q.listOrphanedObjPhotosStmt,
+		listPublicObjectRelationsStmt:            q.listPublicObjectRelationsStmt,
+		listRelationTypesStmt:                    q.listRelationTypesStmt,
+		listRelationsBetweenObjectsStmt:          q.listRelationsBetweenObjectsStmt,
+		listSchemaMigrationsStmt:                 q.listSchemaMigrationsStmt,
+		listSharedFactsStmt:                      q.listSharedFactsStmt,
+		listStaleSchemaMigrationsStmt:            q.listStaleSchemaMigrationsStmt,
 		listStepsByFunnelStmt:                    q.listStepsByFunnelStmt,
+		listStepsByNamesStmt:                     q.listStepsByNamesStmt,
 		listTagsStmt:                             q.listTagsStmt,
+		listTagsByNamesStmt:                      q.listTagsByNamesStmt,
 		listTasksByObjectIDStmt:                  q.listTasksByObjectIDStmt,
 		listTasksByOrgIDStmt:                     q.listTasksByOrgIDStmt,
 		listTasksWithFilterStmt:                  q.listTasksWithFilterStmt,
+		listTrashStmt:                            q.listTrashStmt,
+		listWatchesByCreatorStmt:                 q.listWatchesByCreatorStmt,
+		lockFactsStmt:                            q.lockFactsStmt,
 		markFeedAsSeenStmt:                       q.markFeedAsSeenStmt,
+		matchListFiltersStmt:                     q.matchListFiltersStmt,
 		mergeObjectsStmt:                         q.mergeObjectsStmt,
+		purgeExpiredTrashStmt:                    q.purgeExpiredTrashStmt,
+		purgeFactStmt:                            q.purgeFactStmt,
+		purgeFunnelStmt:                          q.purgeFunnelStmt,
+		purgeListStmt:                            q.purgeListStmt,
+		purgeObjStepStmt:                         q.purgeObjStepStmt,
+		purgeObjectStmt:                          q.purgeObjectStmt,
+		purgeTagStmt:                             q.purgeTagStmt,
+		purgeTaskStmt:                            q.purgeTaskStmt,
+		rebuildObjectTypeValueSearchVectorsStmt:  q.rebuildObjectTypeValueSearchVectorsStmt,
+		recordFactVersionStmt:                    q.recordFactVersionStmt,
+		removeFactReactionStmt:                   q.removeFactReactionStmt,
 		removeObjectTypeValueStmt:                q.removeObjectTypeValueStmt,
 		removeObjectsFromFactStmt:                q.removeObjectsFromFactStmt,
 		removeObjectsFromTaskStmt:                q.removeObjectsFromTaskStmt,
 		removeTagFromObjectStmt:                  q.removeTagFromObjectStmt,
+		resolveAddressReviewStmt:                 q.resolveAddressReviewStmt,
+		restoreFactStmt:                          q.restoreFactStmt,
+		restoreFunnelStmt:                        q.restoreFunnelStmt,
+		restoreListStmt:                          q.restoreListStmt,
+		restoreObjectStmt:                        q.restoreObjectStmt,
+		restoreTagStmt:                           q.restoreTagStmt,
+		restoreTaskStmt:                          q.restoreTaskStmt,
+		revertSchemaMigrationStmt:                q.revertSchemaMigrationStmt,
 		revokeAccessToObjectTypeStmt:             q.revokeAccessToObjectTypeStmt,
+		searchFactsStmt:                          q.searchFactsStmt,
+		searchListsStmt:                          q.searchListsStmt,
+		searchObjectsStmt:                        q.searchObjectsStmt,
+		searchTagsStmt:                           q.searchTagsStmt,
+		searchTasksStmt:                          q.searchTasksStmt,
+		setFactObjectsStmt:                       q.setFactObjectsStmt,
+		setFuzzyThresholdStmt:                    q.setFuzzyThresholdStmt,
+		setObjectOwnerStmt:                       q.setObjectOwnerStmt,
+		setObjectPhotoStmt:                       q.setObjectPhotoStmt,
+		setObjectTypeValuesStmt:                  q.setObjectTypeValuesStmt,
 		softDeleteObjStepStmt:                    q.softDeleteObjStepStmt,
+		suggestObjectsStmt:                       q.suggestObjectsStmt,
 		syncObjectAliasesStmt:                    q.syncObjectAliasesStmt,
 		updateActionExecutionStmt:                q.updateActionExecutionStmt,
 		updateActionLastRunStmt:                  q.updateActionLastRunStmt,
 		updateAutomatedActionStmt:                q.updateAutomatedActionStmt,
+		updateBulkOperationProgressStmt:          q.updateBulkOperationProgressStmt,
+		updateBulkOperationStatusStmt:            q.updateBulkOperationStatusStmt,
+		updateCalendarFeedSyncStmt:               q.updateCalendarFeedSyncStmt,
 		updateCreatorListStmt:                    q.updateCreatorListStmt,
 		updateCreatorPasswordStmt:                q.updateCreatorPasswordStmt,
 		updateCreatorProfileStmt:                 q.updateCreatorProfileStmt,
 		updateCreatorRoleAndStatusStmt:           q.updateCreatorRoleAndStatusStmt,
 		updateFactStmt:                           q.updateFactStmt,
+		updateFactCommentStmt:                    q.updateFactCommentStmt,
 		updateFunnelStmt:                         q.updateFunnelStmt,
 		updateImportTaskErrorStmt:                q.updateImportTaskErrorStmt,
 		updateImportTaskProgressStmt:             q.updateImportTaskProgressStmt,
@@ -1557,11 +3029,19 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		updateObjStepSubStatusStmt:               q.updateObjStepSubStatusStmt,
 		updateObjectStmt:                         q.updateObjectStmt,
 		updateObjectTypeStmt:                     q.updateObjectTypeStmt,
+		updateObjectTypeFieldsStmt:               q.updateObjectTypeFieldsStmt,
 		updateObjectTypeValueStmt:                q.updateObjectTypeValueStmt,
 		updateOrgDetailsStmt:                     q.updateOrgDetailsStmt,
+		updateSchemaMigrationProgressStmt:        q.updateSchemaMigrationProgressStmt,
+		updateSchemaMigrationStatusStmt:          q.updateSchemaMigrationStatusStmt,
 		updateStepStmt:                           q.updateStepStmt,
 		updateTagStmt:                            q.updateTagStmt,
 		updateTaskStmt:                           q.updateTaskStmt,
+		updateWatchEventsStmt:                    q.updateWatchEventsStmt,
+		upsertAddressReviewStmt:                  q.upsertAddressReviewStmt,
+		upsertHealthScoreSettingStmt:             q.upsertHealthScoreSettingStmt,
+		upsertMailDropboxStmt:                    q.upsertMailDropboxStmt,
+		upsertObjectHealthBatchStmt:              q.upsertObjectHealthBatchStmt,
 		upsertObjectTypeValueStmt:                q.upsertObjectTypeValueStmt,
 		validateMergeObjectsStmt:                 q.validateMergeObjectsStmt,
 	}
@@ -31,12 +31,12 @@ func (q *Queries) CountObjectsAfterCreatedAt(ctx context.Context, createdAt time
 }
 
 const findObjectByAliasOrIDString = `-- name: FindObjectByAliasOrIDString :one
-SELECT obj.id, obj.name, obj.photo, obj.description, obj.id_string, obj.creator_id, obj.created_at, obj.deleted_at, obj.aliases FROM obj
+SELECT o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at, o.deleted_at, o.aliases, o.deleted_by, o.version FROM obj o
 JOIN creator c ON o.creator_id = c.id
-WHERE c.org_id = $2 AND 
-id_string = $1 OR $1 = ANY(aliases)
-AND deleted_at IS NULL
-ORDER BY (id_string = $1) DESC
+WHERE c.org_id = $2
+AND (o.id_string = $1 OR $1 = ANY(o.aliases))
+AND o.deleted_at IS NULL
+ORDER BY (o.id_string = $1) DESC
 LIMIT 1
 `
 
@@ -58,12 +58,14 @@ func (q *Queries) FindObjectByAliasOrIDString(ctx context.Context, arg FindObjec
 		&i.CreatedAt,
 		&i.DeletedAt,
 		pq.Array(&i.Aliases),
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
 
 const findTagByNormalizedName = `-- name: FindTagByNormalizedName :one
-SELECT id, name, description, color_schema, org_id, created_at, deleted_at FROM tag 
+SELECT id, name, description, color_schema, org_id, created_at, deleted_at, deleted_by FROM tag 
 WHERE lower(name) = lower($1) 
 AND org_id = $2
 AND deleted_at IS NULL
@@ -86,6 +88,7 @@ func (q *Queries) FindTagByNormalizedName(ctx context.Context, arg FindTagByNorm
 		&i.OrgID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -128,6 +131,7 @@ raw_values AS (
     SELECT jsonb_object_keys(otv.type_values) as key_name
   ) k2 ON true
   LEFT JOIN valid_keys k ON k.key_name = k2.key_name
+  LEFT JOIN obj_type ot ON otv.type_id = ot.id
   LEFT JOIN creator c ON o.creator_id = c.id
   LEFT JOIN org org ON c.org_id = org.id
   WHERE o.deleted_at IS NULL
@@ -137,6 +141,13 @@ raw_values AS (
     AND (
       $3::uuid[] IS NULL OR o.id = ANY($3::uuid[])
     )
+    -- Restricted fields are left out unless the caller's role ($4) may read them
+    AND (
+      $4::text = 'admin'
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' ->> 'restricted', '') <> 'true'
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' -> 'readRoles', '[]'::jsonb) ? $4::text
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' -> 'writeRoles', '[]'::jsonb) ? $4::text
+    )
 ),
 aggregated_values AS (
   SELECT 
@@ -180,6 +191,7 @@ type ListObjectsWithNormalizedDataParams struct {
 	ID      uuid.UUID   `json:"id"`
 	Column2 time.Time   `json:"column_2"`
 	Column3 []uuid.UUID `json:"column_3"`
+	Column4 string      `json:"column_4"`
 }
 
 type ListObjectsWithNormalizedDataRow struct {
@@ -194,7 +206,12 @@ type ListObjectsWithNormalizedDataRow struct {
 // Second level: Aggregate values by key
 // Third level: Create contact data object
 func (q *Queries) ListObjectsWithNormalizedData(ctx context.Context, arg ListObjectsWithNormalizedDataParams) ([]ListObjectsWithNormalizedDataRow, error) {
-	rows, err := q.query(ctx, q.listObjectsWithNormalizedDataStmt, listObjectsWithNormalizedData, arg.ID, arg.Column2, pq.Array(arg.Column3))
+	rows, err := q.query(ctx, q.listObjectsWithNormalizedDataStmt, listObjectsWithNormalizedData,
+		arg.ID,
+		arg.Column2,
+		pq.Array(arg.Column3),
+		arg.Column4,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -34,6 +35,24 @@ daily_fact_metrics AS (
         AND f.deleted_at IS NULL
     GROUP BY date_trunc('day', f.created_at)
 ),
+daily_fact_kind_metrics AS (
+    -- Facts created per day by kind, as {"call": 2, "meeting": 1}
+    SELECT 
+        day,
+        jsonb_object_agg(kind, fact_count) as facts_by_kind
+    FROM (
+        SELECT 
+            date_trunc('day', f.created_at) as day,
+            f.kind,
+            COUNT(f.id) as fact_count
+        FROM fact f
+        WHERE f.creator_id = $1
+            AND f.created_at > NOW() - INTERVAL '30 days'
+            AND f.deleted_at IS NULL
+        GROUP BY date_trunc('day', f.created_at), f.kind
+    ) kinds
+    GROUP BY day
+),
 daily_task_metrics AS (
     -- Tasks activity per day
     SELECT 
@@ -138,6 +157,7 @@ SELECT
     -- Facts metrics
     COALESCE(dfm.fact_count, 0) as facts_created,
     COALESCE(dfm.fact_objects_count, 0) as fact_objects_involved,
+    COALESCE(dfkm.facts_by_kind, '{}'::jsonb) as facts_by_kind,
     -- Task metrics
     COALESCE(dtm.task_count, 0) as tasks_total,
     COALESCE(dtm.completed_tasks, 0) as tasks_completed,
@@ -180,6 +200,7 @@ SELECT
   ) AS double precision) as daily_activity_score
 FROM dates d
 LEFT JOIN daily_fact_metrics dfm ON d.day = dfm.day
+LEFT JOIN daily_fact_kind_metrics dfkm ON d.day = dfkm.day
 LEFT JOIN daily_task_metrics dtm ON d.day = dtm.day
 LEFT JOIN daily_object_metrics dom ON d.day = dom.day
 LEFT JOIN daily_funnel_metrics dfnm ON d.day = dfnm.day
@@ -189,25 +210,26 @@ ORDER BY d.day DESC
 `
 
 type GetCreatorDailyActivityRow struct {
-	ActivityDate          time.Time `json:"activity_date"`
-	FactsCreated          int64     `json:"facts_created"`
-	FactObjectsInvolved   int64     `json:"fact_objects_involved"`
-	TasksTotal            int64     `json:"tasks_total"`
-	TasksCompleted        int64     `json:"tasks_completed"`
-	TaskObjectsInvolved   int64     `json:"task_objects_involved"`
-	ObjectsCreated        int64     `json:"objects_created"`
-	TypeValuesAdded       int64     `json:"type_values_added"`
-	TagsAdded             int64     `json:"tags_added"`
-	ObjectsMovedInFunnels int64     `json:"objects_moved_in_funnels"`
-	FunnelStepsInvolved   int64     `json:"funnel_steps_involved"`
-	FunnelsCreated        int64     `json:"funnels_created"`
-	StepsCreated          int64     `json:"steps_created"`
-	StepsUpdated          int64     `json:"steps_updated"`
-	StepsModified         int64     `json:"steps_modified"`
-	TypesCreated          int64     `json:"types_created"`
-	TypesUsed             int64     `json:"types_used"`
-	TypesUpdated          int64     `json:"types_updated"`
-	DailyActivityScore    float64   `json:"daily_activity_score"`
+	ActivityDate          time.Time       `json:"activity_date"`
+	FactsCreated          int64           `json:"facts_created"`
+	FactObjectsInvolved   int64           `json:"fact_objects_involved"`
+	FactsByKind           json.RawMessage `json:"facts_by_kind"`
+	TasksTotal            int64           `json:"tasks_total"`
+	TasksCompleted        int64           `json:"tasks_completed"`
+	TaskObjectsInvolved   int64           `json:"task_objects_involved"`
+	ObjectsCreated        int64           `json:"objects_created"`
+	TypeValuesAdded       int64           `json:"type_values_added"`
+	TagsAdded             int64           `json:"tags_added"`
+	ObjectsMovedInFunnels int64           `json:"objects_moved_in_funnels"`
+	FunnelStepsInvolved   int64           `json:"funnel_steps_involved"`
+	FunnelsCreated        int64           `json:"funnels_created"`
+	StepsCreated          int64           `json:"steps_created"`
+	StepsUpdated          int64           `json:"steps_updated"`
+	StepsModified         int64           `json:"steps_modified"`
+	TypesCreated          int64           `json:"types_created"`
+	TypesUsed             int64           `json:"types_used"`
+	TypesUpdated          int64           `json:"types_updated"`
+	DailyActivityScore    float64         `json:"daily_activity_score"`
 }
 
 func (q *Queries) GetCreatorDailyActivity(ctx context.Context, creatorID uuid.UUID) ([]GetCreatorDailyActivityRow, error) {
@@ -223,6 +245,7 @@ func (q *Queries) GetCreatorDailyActivity(ctx context.Context, creatorID uuid.UU
 			&i.ActivityDate,
 			&i.FactsCreated,
 			&i.FactObjectsInvolved,
+			&i.FactsByKind,
 			&i.TasksTotal,
 			&i.TasksCompleted,
 			&i.TaskObjectsInvolved,
@@ -19,7 +19,7 @@ const completeImportTask = `-- name: CompleteImportTask :one
 UPDATE import_task
 SET status = $2, result_summary = $3, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at
+RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode
 `
 
 type CompleteImportTaskParams struct {
@@ -45,6 +45,7 @@ func (q *Queries) CompleteImportTask(ctx context.Context, arg CompleteImportTask
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -63,20 +64,21 @@ func (q *Queries) CountImportTasks(ctx context.Context, orgID uuid.UUID) (int64,
 
 const createImportTask = `-- name: CreateImportTask :one
 INSERT INTO import_task (
-    org_id, creator_id, obj_type_id, status, total_rows, file_name
+    org_id, creator_id, obj_type_id, status, total_rows, file_name, mode
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at
+RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode
 `
 
 type CreateImportTaskParams struct {
-	OrgID     uuid.UUID `json:"org_id"`
-	CreatorID uuid.UUID `json:"creator_id"`
-	ObjTypeID uuid.UUID `json:"obj_type_id"`
-	Status    string    `json:"status"`
-	TotalRows int32     `json:"total_rows"`
-	FileName  string    `json:"file_name"`
+	OrgID     uuid.UUID     `json:"org_id"`
+	CreatorID uuid.UUID     `json:"creator_id"`
+	ObjTypeID uuid.NullUUID `json:"obj_type_id"`
+	Status    string        `json:"status"`
+	TotalRows int32         `json:"total_rows"`
+	FileName  string        `json:"file_name"`
+	Mode      string        `json:"mode"`
 }
 
 func (q *Queries) CreateImportTask(ctx context.Context, arg CreateImportTaskParams) (ImportTask, error) {
@@ -87,6 +89,7 @@ func (q *Queries) CreateImportTask(ctx context.Context, arg CreateImportTaskPara
 		arg.Status,
 		arg.TotalRows,
 		arg.FileName,
+		arg.Mode,
 	)
 	var i ImportTask
 	err := row.Scan(
@@ -103,12 +106,13 @@ func (q *Queries) CreateImportTask(ctx context.Context, arg CreateImportTaskPara
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
 
 const getImportTask = `-- name: GetImportTask :one
-SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at FROM import_task
+SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode FROM import_task
 WHERE id = $1
 `
 
@@ -129,12 +133,13 @@ func (q *Queries) GetImportTask(ctx context.Context, id uuid.UUID) (ImportTask,
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
 
 const getImportTaskHistory = `-- name: GetImportTaskHistory :many
-SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at FROM import_task
+SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode FROM import_task
 WHERE org_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -169,6 +174,7 @@ func (q *Queries) GetImportTaskHistory(ctx context.Context, arg GetImportTaskHis
 			&i.FileName,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Mode,
 		); err != nil {
 			return nil, err
 		}
@@ -184,7 +190,7 @@ func (q *Queries) GetImportTaskHistory(ctx context.Context, arg GetImportTaskHis
 }
 
 const getObjectByIDString = `-- name: GetObjectByIDString :one
-SELECT id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases FROM obj
+SELECT id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases, deleted_by, version FROM obj
 WHERE id_string = $1 OR $1 = ANY(aliases)
 AND deleted_at IS NULL
 ORDER BY (id_string = $1) DESC
@@ -204,12 +210,14 @@ func (q *Queries) GetObjectByIDString(ctx context.Context, idString string) (Obj
 		&i.CreatedAt,
 		&i.DeletedAt,
 		pq.Array(&i.Aliases),
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getObjectTypeValue = `-- name: GetObjectTypeValue :one
-SELECT id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector FROM obj_type_value
+SELECT id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector, version, contact_text, restricted_contacts FROM obj_type_value
 WHERE obj_id = $1 AND type_id = $2
 LIMIT 1
 `
@@ -231,12 +239,15 @@ func (q *Queries) GetObjectTypeValue(ctx context.Context, arg GetObjectTypeValue
 		&i.LastUpdated,
 		&i.DeletedAt,
 		&i.SearchVector,
+		&i.Version,
+		&i.ContactText,
+		&i.RestrictedContacts,
 	)
 	return i, err
 }
 
 const getOngoingImportTask = `-- name: GetOngoingImportTask :one
-SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at FROM import_task
+SELECT id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode FROM import_task
 WHERE org_id = $1 AND status IN ('pending', 'processing')
 ORDER BY created_at DESC
 LIMIT 1
@@ -259,6 +270,7 @@ func (q *Queries) GetOngoingImportTask(ctx context.Context, orgID uuid.UUID) (Im
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -267,7 +279,7 @@ const updateImportTaskError = `-- name: UpdateImportTaskError :one
 UPDATE import_task
 SET status = $2, error_message = $3, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at
+RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode
 `
 
 type UpdateImportTaskErrorParams struct {
@@ -293,6 +305,7 @@ func (q *Queries) UpdateImportTaskError(ctx context.Context, arg UpdateImportTas
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -301,7 +314,7 @@ const updateImportTaskProgress = `-- name: UpdateImportTaskProgress :one
 UPDATE import_task
 SET progress = $2, processed_rows = $3, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at
+RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode
 `
 
 type UpdateImportTaskProgressParams struct {
@@ -327,6 +340,7 @@ func (q *Queries) UpdateImportTaskProgress(ctx context.Context, arg UpdateImport
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -335,7 +349,7 @@ const updateImportTaskStatus = `-- name: UpdateImportTaskStatus :one
 UPDATE import_task
 SET status = $2, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at
+RETURNING id, org_id, creator_id, obj_type_id, status, progress, total_rows, processed_rows, error_message, result_summary, file_name, created_at, updated_at, mode
 `
 
 type UpdateImportTaskStatusParams struct {
@@ -360,6 +374,7 @@ func (q *Queries) UpdateImportTaskStatus(ctx context.Context, arg UpdateImportTa
 		&i.FileName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -375,17 +390,24 @@ ON CONFLICT (obj_id, type_id)
 DO UPDATE SET 
     type_values = EXCLUDED.type_values,
     last_updated = CURRENT_TIMESTAMP
-RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector
+WHERE ($4::int = 0 OR obj_type_value.version = $4)
+RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector, version, contact_text, restricted_contacts
 `
 
 type UpsertObjectTypeValueParams struct {
 	ObjID      uuid.UUID       `json:"obj_id"`
 	TypeID     uuid.UUID       `json:"type_id"`
 	TypeValues json.RawMessage `json:"type_values"`
+	Column4    int32           `json:"column_4"`
 }
 
 func (q *Queries) UpsertObjectTypeValue(ctx context.Context, arg UpsertObjectTypeValueParams) (ObjTypeValue, error) {
-	row := q.queryRow(ctx, q.upsertObjectTypeValueStmt, upsertObjectTypeValue, arg.ObjID, arg.TypeID, arg.TypeValues)
+	row := q.queryRow(ctx, q.upsertObjectTypeValueStmt, upsertObjectTypeValue,
+		arg.ObjID,
+		arg.TypeID,
+		arg.TypeValues,
+		arg.Column4,
+	)
 	var i ObjTypeValue
 	err := row.Scan(
 		&i.ID,
@@ -396,6 +418,9 @@ func (q *Queries) UpsertObjectTypeValue(ctx context.Context, arg UpsertObjectTyp
 		&i.LastUpdated,
 		&i.DeletedAt,
 		&i.SearchVector,
+		&i.Version,
+		&i.ContactText,
+		&i.RestrictedContacts,
 	)
 	return i, err
 }
@@ -56,7 +56,7 @@ func (q *Queries) CreateCreatorList(ctx context.Context, arg CreateCreatorListPa
 const createList = `-- name: CreateList :one
 INSERT INTO list (name, description, filter_setting, creator_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, description, filter_setting, creator_id, created_at, last_updated, deleted_at
+RETURNING id, name, description, filter_setting, creator_id, created_at, last_updated, deleted_at, deleted_by
 `
 
 type CreateListParams struct {
@@ -83,6 +83,7 @@ func (q *Queries) CreateList(ctx context.Context, arg CreateListParams) (List, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -98,8 +99,10 @@ func (q *Queries) DeleteCreatorList(ctx context.Context, id uuid.UUID) error {
 }
 
 const deleteList = `-- name: DeleteList :exec
-DELETE FROM list
+UPDATE list
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE list.id = $1
+AND deleted_at IS NULL
 AND NOT EXISTS (
   SELECT 1
   FROM creator_list
@@ -107,8 +110,13 @@ AND NOT EXISTS (
 )
 `
 
-func (q *Queries) DeleteList(ctx context.Context, id uuid.UUID) error {
-	_, err := q.exec(ctx, q.deleteListStmt, deleteList, id)
+type DeleteListParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteList(ctx context.Context, arg DeleteListParams) error {
+	_, err := q.exec(ctx, q.deleteListStmt, deleteList, arg.ID, arg.DeletedBy)
 	return err
 }
 
@@ -214,7 +222,7 @@ func (q *Queries) ListCreatorListsByCreatorID(ctx context.Context, creatorID uui
 }
 
 const listListsByOrgID = `-- name: ListListsByOrgID :many
-SELECT l.id, l.name, l.description, l.filter_setting, l.creator_id, l.created_at, l.last_updated, l.deleted_at, c.username as creator_name
+SELECT l.id, l.name, l.description, l.filter_setting, l.creator_id, l.created_at, l.last_updated, l.deleted_at, l.deleted_by, c.username as creator_name
 FROM list l
 JOIN creator c ON l.creator_id = c.id
 WHERE c.org_id = $1 AND l.deleted_at IS NULL
@@ -237,6 +245,7 @@ type ListListsByOrgIDRow struct {
 	CreatedAt     time.Time       `json:"created_at"`
 	LastUpdated   time.Time       `json:"last_updated"`
 	DeletedAt     sql.NullTime    `json:"deleted_at"`
+	DeletedBy     uuid.NullUUID   `json:"deleted_by"`
 	CreatorName   string          `json:"creator_name"`
 }
 
@@ -258,6 +267,7 @@ func (q *Queries) ListListsByOrgID(ctx context.Context, arg ListListsByOrgIDPara
 			&i.CreatedAt,
 			&i.LastUpdated,
 			&i.DeletedAt,
+			&i.DeletedBy,
 			&i.CreatorName,
 		); err != nil {
 			return nil, err
@@ -303,7 +313,7 @@ const updateList = `-- name: UpdateList :one
 UPDATE list
 SET name = $2, description = $3, filter_setting = $4, last_updated = CURRENT_TIMESTAMP
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, name, description, filter_setting, creator_id, created_at, last_updated, deleted_at
+RETURNING id, name, description, filter_setting, creator_id, created_at, last_updated, deleted_at, deleted_by
 `
 
 type UpdateListParams struct {
@@ -330,6 +340,7 @@ func (q *Queries) UpdateList(ctx context.Context, arg UpdateListParams) (List, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -14,12 +14,8 @@ import (
 
 const countObjectsByOrgID = `-- name: CountObjectsByOrgID :one
 WITH objs AS (
-    SELECT o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at, o.deleted_at, o.aliases, 
-    (
-        SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
-        FROM obj_type_value otv 
-        WHERE otv.obj_id = o.id
-    ) AS type_value_search,
+    SELECT o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at, o.deleted_at, o.aliases, o.deleted_by, 
+    obj_type_value_search_for(o.id, $4::text) AS type_value_search,
     (
         SELECT string_agg(DISTINCT COALESCE(f.text, ''), ' ') 
         FROM obj_fact of
@@ -44,18 +40,24 @@ LEFT JOIN obj_fact of ON o.id = of.obj_id
 LEFT JOIN fact f ON of.fact_id = f.id
 WHERE c.org_id = $1 AND o.deleted_at IS NULL
   AND ($2 = '' OR
-       to_tsvector('english', o.name || ' ' || o.description || ' ' || o.id_string) @@ to_tsquery('english', $2) OR
-       to_tsvector('english', fact_text) @@ to_tsquery('english', $2) OR
-       type_value_search @@ to_tsquery('english', $2))
+       to_tsvector('english', o.name || ' ' || o.description || ' ' || o.id_string) @@ websearch_to_tsquery('english', $2) OR
+       to_tsvector('english', fact_text) @@ websearch_to_tsquery('english', $2) OR
+       type_value_search @@ websearch_to_tsquery('english', $2) OR
+       EXISTS (
+           SELECT 1 FROM obj_fuzzy_matches($1, $2, $4::text) fm
+           WHERE fm.obj_id = o.id AND fm.similarity >= $3::float8
+       ))
 `
 
 type CountObjectsByOrgIDParams struct {
 	OrgID   uuid.UUID   `json:"org_id"`
 	Column2 interface{} `json:"column_2"`
+	Column3 float64     `json:"column_3"`
+	Column4 string      `json:"column_4"`
 }
 
 func (q *Queries) CountObjectsByOrgID(ctx context.Context, arg CountObjectsByOrgIDParams) (int64, error) {
-	row := q.queryRow(ctx, q.countObjectsByOrgIDStmt, countObjectsByOrgID, arg.OrgID, arg.Column2)
+	row := q.queryRow(ctx, q.countObjectsByOrgIDStmt, countObjectsByOrgID, arg.OrgID, arg.Column2, arg.Column3, arg.Column4)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -73,19 +75,16 @@ WITH object_data AS (
         o.creator_id,
         o.created_at, 
         o.deleted_at,
+        o.aliases,
         -- Create separate tsvector fields for different search sources
         to_tsvector('english', o.name || ' ' || o.description || ' ' || o.id_string) AS obj_search,
         to_tsvector('english', string_agg(DISTINCT COALESCE(f.text, ''), ' ')) AS fact_search,
         -- Combine all obj_type_value search vectors into one
-        (
-            SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
-            FROM obj_type_value otv 
-            WHERE otv.obj_id = o.id
-        ) AS type_value_search,
+        obj_type_value_search_for(o.id, $6::text) AS type_value_search,
         -- Store original text for highlighting
         o.name || ' ' || o.description || ' ' || o.id_string AS obj_text,
         string_agg(DISTINCT COALESCE(f.text, ''), ' ') AS fact_text,
-        string_agg(DISTINCT COALESCE(otv.type_values::text, ''), ' ') AS type_value_text,
+        obj_type_value_text_for(o.id, $6::text) AS type_value_text,
         -- Store IDs for later joins
         array_agg(DISTINCT t.id) AS tag_ids,
         array_agg(DISTINCT otv.id) AS type_value_ids
@@ -97,7 +96,7 @@ WITH object_data AS (
     LEFT JOIN obj_fact of ON o.id = of.obj_id
     LEFT JOIN fact f ON of.fact_id = f.id
     WHERE c.org_id = $1 AND o.deleted_at IS NULL
-    GROUP BY o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at, o.deleted_at
+    GROUP BY o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at, o.deleted_at, o.aliases
 ),
 ranked_results AS (
     -- Calculate search ranking and highlighting for each source
@@ -132,18 +131,26 @@ ranked_results AS (
             WHEN obj_search @@ websearch_to_tsquery('english', $2) THEN 'object_content'
             WHEN type_value_search @@ websearch_to_tsquery('english', $2) THEN 'type_values'
             WHEN fact_search @@ websearch_to_tsquery('english', $2) THEN 'related_facts'
+            WHEN fm.similarity >= $5::float8 THEN 'fuzzy'
             ELSE 'type_values'
         END AS match_source,
+        coalesce(fm.similarity, 0)::float8 AS fuzzy_rank,
+        -- Typos in names are ranked by similarity next to the full text rank
         CASE WHEN $2 = '' THEN 0
              ELSE (ts_rank(obj_search, websearch_to_tsquery('english', $2)) * 3 + 
                 ts_rank(type_value_search, websearch_to_tsquery('english', $2)) * 2 +
-                ts_rank(fact_search, websearch_to_tsquery('english', $2)))
+                ts_rank(fact_search, websearch_to_tsquery('english', $2)) +
+                CASE WHEN fm.similarity >= $5::float8 THEN fm.similarity ELSE 0 END)
         END AS final_rank
     FROM object_data od
+    -- Trigram similarity of the search to the names and the contact values
+    -- the role may read, none for an empty search
+    LEFT JOIN obj_fuzzy_matches($1, $2, $6::text) fm ON fm.obj_id = od.id
     WHERE $2 = '' OR
           obj_search @@ websearch_to_tsquery('english', $2) OR
           fact_search @@ websearch_to_tsquery('english', $2) OR
-          type_value_search @@ websearch_to_tsquery('english', $2)
+          type_value_search @@ websearch_to_tsquery('english', $2) OR
+          fm.similarity >= $5::float8
 )
 SELECT 
     rr.id, 
@@ -157,6 +164,7 @@ SELECT
     rr.fact_headline,
     rr.type_value_headline,
     rr.final_rank as search_rank,
+    rr.fuzzy_rank AS similarity,
     coalesce(
         (SELECT jsonb_agg(jsonb_build_object('id', t.id, 'name', t.name, 'color_schema', t.color_schema))
         FROM tag t
@@ -181,6 +189,8 @@ type ListObjectsByOrgIDParams struct {
 	Column2 interface{} `json:"column_2"`
 	Limit   int32       `json:"limit"`
 	Offset  int32       `json:"offset"`
+	Column5 float64     `json:"column_5"`
+	Column6 string      `json:"column_6"`
 }
 
 type ListObjectsByOrgIDRow struct {
@@ -195,6 +205,7 @@ type ListObjectsByOrgIDRow struct {
 	FactHeadline      interface{} `json:"fact_headline"`
 	TypeValueHeadline interface{} `json:"type_value_headline"`
 	SearchRank        interface{} `json:"search_rank"`
+	Similarity        float64     `json:"similarity"`
 	Tags              interface{} `json:"tags"`
 	TypeValues        interface{} `json:"type_values"`
 }
@@ -205,6 +216,8 @@ func (q *Queries) ListObjectsByOrgID(ctx context.Context, arg ListObjectsByOrgID
 		arg.Column2,
 		arg.Limit,
 		arg.Offset,
+		arg.Column5,
+		arg.Column6,
 	)
 	if err != nil {
 		return nil, err
@@ -225,6 +238,7 @@ func (q *Queries) ListObjectsByOrgID(ctx context.Context, arg ListObjectsByOrgID
 			&i.FactHeadline,
 			&i.TypeValueHeadline,
 			&i.SearchRank,
+			&i.Similarity,
 			&i.Tags,
 			&i.TypeValues,
 		); err != nil {
@@ -13,6 +13,37 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AddressReview struct {
+	ID           uuid.UUID     `json:"id"`
+	OrgID        uuid.UUID     `json:"org_id"`
+	Email        string        `json:"email"`
+	Name         string        `json:"name"`
+	ImportTaskID uuid.NullUUID `json:"import_task_id"`
+	MessageCount int32         `json:"message_count"`
+	Status       string        `json:"status"`
+	ObjID        uuid.NullUUID `json:"obj_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+type AddressReviewFact struct {
+	ReviewID uuid.UUID `json:"review_id"`
+	FactID   uuid.UUID `json:"fact_id"`
+}
+
+type Attachment struct {
+	ID          uuid.UUID     `json:"id"`
+	OrgID       uuid.UUID     `json:"org_id"`
+	FactID      uuid.NullUUID `json:"fact_id"`
+	ObjID       uuid.NullUUID `json:"obj_id"`
+	CreatorID   uuid.NullUUID `json:"creator_id"`
+	Filename    string        `json:"filename"`
+	ContentType string        `json:"content_type"`
+	Size        int64         `json:"size"`
+	TextContent string        `json:"text_content"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
 type AutomatedAction struct {
 	ID           uuid.UUID       `json:"id"`
 	OrgID        uuid.UUID       `json:"org_id"`
@@ -39,6 +70,34 @@ type AutomatedActionExecution struct {
 	ExecutionLog    pqtype.NullRawMessage `json:"execution_log"`
 }
 
+type BulkOperation struct {
+	ID            uuid.UUID             `json:"id"`
+	OrgID         uuid.UUID             `json:"org_id"`
+	CreatorID     uuid.UUID             `json:"creator_id"`
+	Operation     string                `json:"operation"`
+	Params        json.RawMessage       `json:"params"`
+	Status        string                `json:"status"`
+	Progress      sql.NullInt32         `json:"progress"`
+	TotalRows     int32                 `json:"total_rows"`
+	ProcessedRows sql.NullInt32         `json:"processed_rows"`
+	FailedRows    sql.NullInt32         `json:"failed_rows"`
+	ErrorMessage  sql.NullString        `json:"error_message"`
+	ResultSummary pqtype.NullRawMessage `json:"result_summary"`
+	CreatedAt     sql.NullTime          `json:"created_at"`
+	UpdatedAt     sql.NullTime          `json:"updated_at"`
+}
+
+type CalendarFeed struct {
+	ID           uuid.UUID      `json:"id"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	CreatorID    uuid.UUID      `json:"creator_id"`
+	Name         string         `json:"name"`
+	Url          string         `json:"url"`
+	LastSyncedAt sql.NullTime   `json:"last_synced_at"`
+	LastError    sql.NullString `json:"last_error"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
 type Creator struct {
 	ID        uuid.UUID       `json:"id"`
 	Username  string          `json:"username"`
@@ -75,14 +134,60 @@ type CreatorSession struct {
 }
 
 type Fact struct {
-	ID          uuid.UUID    `json:"id"`
-	Text        string       `json:"text"`
-	HappenedAt  sql.NullTime `json:"happened_at"`
-	Location    string       `json:"location"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	LastUpdated time.Time    `json:"last_updated"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
+	ID          uuid.UUID       `json:"id"`
+	Text        string          `json:"text"`
+	HappenedAt  sql.NullTime    `json:"happened_at"`
+	Location    string          `json:"location"`
+	CreatorID   uuid.UUID       `json:"creator_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	LastUpdated time.Time       `json:"last_updated"`
+	DeletedAt   sql.NullTime    `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID   `json:"deleted_by"`
+	Kind        string          `json:"kind"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+type FactComment struct {
+	ID          uuid.UUID     `json:"id"`
+	FactID      uuid.UUID     `json:"fact_id"`
+	ParentID    uuid.NullUUID `json:"parent_id"`
+	CreatorID   uuid.UUID     `json:"creator_id"`
+	Text        string        `json:"text"`
+	CreatedAt   time.Time     `json:"created_at"`
+	LastUpdated time.Time     `json:"last_updated"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+}
+
+type FactReaction struct {
+	FactID    uuid.UUID `json:"fact_id"`
+	CreatorID uuid.UUID `json:"creator_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FactSource struct {
+	OrgID      uuid.UUID `json:"org_id"`
+	Source     string    `json:"source"`
+	ExternalID string    `json:"external_id"`
+	FactID     uuid.UUID `json:"fact_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FactVersion struct {
+	ID           uuid.UUID       `json:"id"`
+	FactID       uuid.UUID       `json:"fact_id"`
+	Version      int32           `json:"version"`
+	Text         string          `json:"text"`
+	HappenedAt   sql.NullTime    `json:"happened_at"`
+	Location     string          `json:"location"`
+	ObjIds       []uuid.UUID     `json:"obj_ids"`
+	EditorID     uuid.UUID       `json:"editor_id"`
+	Source       string          `json:"source"`
+	Action       string          `json:"action"`
+	RestoredFrom sql.NullInt32   `json:"restored_from"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Kind         string          `json:"kind"`
+	Metadata     json.RawMessage `json:"metadata"`
 }
 
 type Feed struct {
@@ -95,19 +200,27 @@ type Feed struct {
 }
 
 type Funnel struct {
-	ID          uuid.UUID    `json:"id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	CreatorID   uuid.UUID     `json:"creator_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID `json:"deleted_by"`
+}
+
+type HealthScoreSetting struct {
+	OrgID     uuid.UUID       `json:"org_id"`
+	Weights   json.RawMessage `json:"weights"`
+	UpdatedBy uuid.NullUUID   `json:"updated_by"`
+	UpdatedAt time.Time       `json:"updated_at"`
 }
 
 type ImportTask struct {
 	ID            uuid.UUID             `json:"id"`
 	OrgID         uuid.UUID             `json:"org_id"`
 	CreatorID     uuid.UUID             `json:"creator_id"`
-	ObjTypeID     uuid.UUID             `json:"obj_type_id"`
+	ObjTypeID     uuid.NullUUID         `json:"obj_type_id"`
 	Status        string                `json:"status"`
 	Progress      sql.NullInt32         `json:"progress"`
 	TotalRows     int32                 `json:"total_rows"`
@@ -117,6 +230,7 @@ type ImportTask struct {
 	FileName      string                `json:"file_name"`
 	CreatedAt     sql.NullTime          `json:"created_at"`
 	UpdatedAt     sql.NullTime          `json:"updated_at"`
+	Mode          string                `json:"mode"`
 }
 
 type List struct {
@@ -128,18 +242,28 @@ type List struct {
 	CreatedAt     time.Time       `json:"created_at"`
 	LastUpdated   time.Time       `json:"last_updated"`
 	DeletedAt     sql.NullTime    `json:"deleted_at"`
+	DeletedBy     uuid.NullUUID   `json:"deleted_by"`
+}
+
+type MailDropbox struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	Token     string    `json:"token"`
+	CreatorID uuid.UUID `json:"creator_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Obj struct {
-	ID          uuid.UUID    `json:"id"`
-	Name        string       `json:"name"`
-	Photo       string       `json:"photo"`
-	Description string       `json:"description"`
-	IDString    string       `json:"id_string"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
-	Aliases     []string     `json:"aliases"`
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Photo       string        `json:"photo"`
+	Description string        `json:"description"`
+	IDString    string        `json:"id_string"`
+	CreatorID   uuid.UUID     `json:"creator_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+	Aliases     []string      `json:"aliases"`
+	DeletedBy   uuid.NullUUID `json:"deleted_by"`
+	Version     int32         `json:"version"`
 }
 
 type ObjFact struct {
@@ -147,6 +271,52 @@ type ObjFact struct {
 	FactID uuid.UUID `json:"fact_id"`
 }
 
+type ObjHealth struct {
+	ObjID      uuid.UUID       `json:"obj_id"`
+	Score      float32         `json:"score"`
+	Components json.RawMessage `json:"components"`
+	ComputedAt time.Time       `json:"computed_at"`
+}
+
+type ObjHealthHistory struct {
+	ObjID    uuid.UUID `json:"obj_id"`
+	ScoredOn time.Time `json:"scored_on"`
+	Score    float32   `json:"score"`
+}
+
+type ObjHistory struct {
+	ID        uuid.UUID       `json:"id"`
+	ObjID     uuid.UUID       `json:"obj_id"`
+	CreatorID uuid.UUID       `json:"creator_id"`
+	Source    string          `json:"source"`
+	Action    string          `json:"action"`
+	TypeID    uuid.NullUUID   `json:"type_id"`
+	Changes   json.RawMessage `json:"changes"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ObjPhoto struct {
+	ID          uuid.UUID     `json:"id"`
+	ObjID       uuid.NullUUID `json:"obj_id"`
+	CreatorID   uuid.NullUUID `json:"creator_id"`
+	ContentType string        `json:"content_type"`
+	Size        int64         `json:"size"`
+	Width       int32         `json:"width"`
+	Height      int32         `json:"height"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type ObjRelation struct {
+	ID           uuid.UUID       `json:"id"`
+	FromObjID    uuid.UUID       `json:"from_obj_id"`
+	ToObjID      uuid.UUID       `json:"to_obj_id"`
+	RelationType string          `json:"relation_type"`
+	Metadata     json.RawMessage `json:"metadata"`
+	CreatorID    uuid.UUID       `json:"creator_id"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
 type ObjStep struct {
 	ID          uuid.UUID    `json:"id"`
 	ObjID       uuid.UUID    `json:"obj_id"`
@@ -193,6 +363,11 @@ type ObjTypeValue struct {
 	DeletedAt   sql.NullTime    `json:"deleted_at"`
 	// This column contains the tsvector for full-text search
 	SearchVector interface{} `json:"search_vector"`
+	Version      int32       `json:"version"`
+	// The values of the public email, phone and url fields, lower case
+	ContactText string `json:"contact_text"`
+	// The values of the restricted contact fields, by field
+	RestrictedContacts json.RawMessage `json:"restricted_contacts"`
 }
 
 type ObjectMergeHistory struct {
@@ -212,6 +387,24 @@ type Org struct {
 	DeletedAt sql.NullTime    `json:"deleted_at"`
 }
 
+type SchemaMigration struct {
+	ID            uuid.UUID             `json:"id"`
+	OrgID         uuid.UUID             `json:"org_id"`
+	CreatorID     uuid.UUID             `json:"creator_id"`
+	ObjTypeID     uuid.UUID             `json:"obj_type_id"`
+	Operations    json.RawMessage       `json:"operations"`
+	DryRun        bool                  `json:"dry_run"`
+	Status        string                `json:"status"`
+	Progress      sql.NullInt32         `json:"progress"`
+	TotalRows     int32                 `json:"total_rows"`
+	ProcessedRows sql.NullInt32         `json:"processed_rows"`
+	FailedRows    sql.NullInt32         `json:"failed_rows"`
+	ErrorMessage  sql.NullString        `json:"error_message"`
+	ResultSummary pqtype.NullRawMessage `json:"result_summary"`
+	CreatedAt     sql.NullTime          `json:"created_at"`
+	UpdatedAt     sql.NullTime          `json:"updated_at"`
+}
+
 type Step struct {
 	ID          uuid.UUID    `json:"id"`
 	FunnelID    uuid.UUID    `json:"funnel_id"`
@@ -233,6 +426,7 @@ type Tag struct {
 	OrgID       uuid.UUID       `json:"org_id"`
 	CreatedAt   time.Time       `json:"created_at"`
 	DeletedAt   sql.NullTime    `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID   `json:"deleted_by"`
 }
 
 type Task struct {
@@ -247,4 +441,15 @@ type Task struct {
 	CreatedAt   time.Time     `json:"created_at"`
 	LastUpdated time.Time     `json:"last_updated"`
 	DeletedAt   sql.NullTime  `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID `json:"deleted_by"`
+}
+
+type Watch struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatorID uuid.UUID     `json:"creator_id"`
+	ObjID     uuid.NullUUID `json:"obj_id"`
+	ListID    uuid.NullUUID `json:"list_id"`
+	StepID    uuid.NullUUID `json:"step_id"`
+	Events    []string      `json:"events"`
+	CreatedAt time.Time     `json:"created_at"`
 }
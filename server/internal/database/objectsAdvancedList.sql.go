@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -41,11 +42,7 @@ WITH object_data AS (
         ) AS obj_search,
         to_tsvector('english', string_agg(DISTINCT COALESCE(f.text, ''), ' ')) AS fact_search,
         -- Add type_value_search using search_vector
-        (
-            SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
-            FROM obj_type_value otv 
-            WHERE otv.obj_id = o.id
-        ) AS type_value_search,
+        obj_type_value_search_for(o.id, $21::text) AS type_value_search,
         -- Get all steps for filtering
         array_agg(DISTINCT os.step_id) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_ids,
         -- Store steps and their sub_status for filtering
@@ -100,9 +97,23 @@ filtered_objects AS (
         )) AND
         -- Filter by tags if array is provided
         ($4::uuid[] IS NULL OR od.tag_ids && $4) AND
+        -- Filter by fact kinds if array is provided
+        ($15::text[] IS NULL OR EXISTS (
+            SELECT 1
+            FROM obj_fact xf
+            JOIN fact fk ON fk.id = xf.fact_id
+            WHERE xf.obj_id = od.id AND fk.deleted_at IS NULL AND fk.kind = ANY($15::text[])
+        )) AND
         -- Filter by object types if array is provided
         ($5::uuid[] IS NULL OR od.type_ids && $5) AND
-        -- Filter by type value criteria 1 with LIKE
+        -- Filter out objects with any of the excluded steps, tags or types
+        ($16::uuid[] IS NULL OR NOT (COALESCE(od.step_ids, '{}') && $16)) AND
+        ($17::uuid[] IS NULL OR NOT (COALESCE(od.tag_ids, '{}') && $17)) AND
+        ($18::uuid[] IS NULL OR NOT (COALESCE(od.type_ids, '{}') && $18)) AND
+        -- Filter by creation date, from inclusive and before exclusive
+        ($19::timestamptz IS NULL OR od.created_at >= $19) AND
+        ($20::timestamptz IS NULL OR od.created_at < $20) AND
+        -- Filter by type value criteria 1
         ((COALESCE($6::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -113,14 +124,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($6) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($6) criteria
+                            FROM jsonb_each($6) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 2 with LIKE
+        -- Filter by type value criteria 2
         ((COALESCE($7::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -131,14 +142,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($7) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($7) criteria
+                            FROM jsonb_each($7) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 3 with LIKE
+        -- Filter by type value criteria 3
         ((COALESCE($8::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -149,9 +160,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($8) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($8) criteria
+                            FROM jsonb_each($8) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -160,7 +171,12 @@ filtered_objects AS (
         ($2 = '' OR
          obj_search @@ websearch_to_tsquery('english', $2) OR
          fact_search @@ websearch_to_tsquery('english', $2) OR
-         type_value_search @@ websearch_to_tsquery('english', $2))
+         type_value_search @@ websearch_to_tsquery('english', $2)) AND
+        -- Words the query excludes, from every text at once
+        ($22::text = '' OR NOT COALESCE(
+         obj_search @@ websearch_to_tsquery('english', $22) OR
+         fact_search @@ websearch_to_tsquery('english', $22) OR
+         type_value_search @@ websearch_to_tsquery('english', $22), false))
 )
 SELECT 
     fo.id, 
@@ -209,8 +225,10 @@ SELECT
         JOIN funnel f ON s.funnel_id = f.id
         WHERE os.id = ANY(fo.obj_step_ids) AND os.deleted_at IS NULL),
         '[]'
-    ) AS steps
+    ) AS steps,
+    oh.score AS health_score
 FROM filtered_objects fo
+LEFT JOIN obj_health oh ON oh.obj_id = fo.id
 ORDER BY
     -- First by search rank if searching
     CASE WHEN $2 = '' THEN 0 ELSE (fo.obj_rank + fo.fact_rank + fo.type_value_rank) END DESC,
@@ -221,6 +239,13 @@ ORDER BY
     CASE WHEN $9 = 'fact_count' THEN 
         CASE WHEN NOT COALESCE($10, false) THEN fo.fact_count END
     END DESC NULLS LAST,
+    -- Handle health score, objects never scored last
+    CASE WHEN $9 = 'health_score' THEN
+        CASE WHEN COALESCE($10, false) THEN oh.score END
+    END ASC NULLS LAST,
+    CASE WHEN $9 = 'health_score' THEN
+        CASE WHEN NOT COALESCE($10, false) THEN oh.score END
+    END DESC NULLS LAST,
     -- Handle timestamp types
     CASE WHEN $9 IN ('created_at', 'first_fact_date', 'last_fact_date') THEN 
         CASE WHEN COALESCE($10, false) THEN
@@ -335,6 +360,14 @@ type ListObjectsAdvancedParams struct {
 	Limit    int32           `json:"limit"`
 	Offset   int32           `json:"offset"`
 	Column14 []int32         `json:"column_14"`
+	Column15 []string        `json:"column_15"`
+	Column16 []uuid.UUID     `json:"column_16"`
+	Column17 []uuid.UUID     `json:"column_17"`
+	Column18 []uuid.UUID     `json:"column_18"`
+	Column19 sql.NullTime    `json:"column_19"`
+	Column20 sql.NullTime    `json:"column_20"`
+	Column21 string          `json:"column_21"`
+	Column22 string          `json:"column_22"`
 }
 
 type ListObjectsAdvancedRow struct {
@@ -352,6 +385,7 @@ type ListObjectsAdvancedRow struct {
 	Tags          interface{} `json:"tags"`
 	TypeValues    interface{} `json:"type_values"`
 	Steps         interface{} `json:"steps"`
+	HealthScore   interface{} `json:"health_score"`
 }
 
 func (q *Queries) ListObjectsAdvanced(ctx context.Context, arg ListObjectsAdvancedParams) ([]ListObjectsAdvancedRow, error) {
@@ -370,6 +404,14 @@ func (q *Queries) ListObjectsAdvanced(ctx context.Context, arg ListObjectsAdvanc
 		arg.Limit,
 		arg.Offset,
 		pq.Array(arg.Column14),
+		pq.Array(arg.Column15),
+		pq.Array(arg.Column16),
+		pq.Array(arg.Column17),
+		pq.Array(arg.Column18),
+		arg.Column19,
+		arg.Column20,
+		arg.Column21,
+		arg.Column22,
 	)
 	if err != nil {
 		return nil, err
@@ -393,6 +435,7 @@ func (q *Queries) ListObjectsAdvanced(ctx context.Context, arg ListObjectsAdvanc
 			&i.Tags,
 			&i.TypeValues,
 			&i.Steps,
+			&i.HealthScore,
 		); err != nil {
 			return nil, err
 		}
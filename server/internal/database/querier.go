@@ -6,6 +6,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 )
 
 type Querier interface {
+	AddAddressReviewFact(ctx context.Context, arg AddAddressReviewFactParams) error
+	AddFactReaction(ctx context.Context, arg AddFactReactionParams) error
 	AddObjectTypeValue(ctx context.Context, arg AddObjectTypeValueParams) (ObjTypeValue, error)
 	AddObjectsToFact(ctx context.Context, arg AddObjectsToFactParams) error
 	AddObjectsToTask(ctx context.Context, arg AddObjectsToTaskParams) error
@@ -22,10 +25,14 @@ type Querier interface {
 	// Insert step relations if funnel_id is provided
 	// Return affected object IDs and what was done to them
 	AddTagAndStepToFilteredObjects(ctx context.Context, arg AddTagAndStepToFilteredObjectsParams) ([]AddTagAndStepToFilteredObjectsRow, error)
-	AddTagToObject(ctx context.Context, arg AddTagToObjectParams) error
+	AddTagToObject(ctx context.Context, arg AddTagToObjectParams) (int64, error)
+	BackupObjectTypeValue(ctx context.Context, arg BackupObjectTypeValueParams) error
+	CompleteBulkOperation(ctx context.Context, arg CompleteBulkOperationParams) error
 	CompleteImportTask(ctx context.Context, arg CompleteImportTaskParams) (ImportTask, error)
+	CompleteSchemaMigration(ctx context.Context, arg CompleteSchemaMigrationParams) error
 	CountAccessibleObjectTypes(ctx context.Context, arg CountAccessibleObjectTypesParams) (int64, error)
 	CountActionExecutions(ctx context.Context, actionID uuid.UUID) (int64, error)
+	CountAddressReviews(ctx context.Context, arg CountAddressReviewsParams) (int64, error)
 	CountAutomatedActions(ctx context.Context, arg CountAutomatedActionsParams) (int64, error)
 	CountFactsByOrgID(ctx context.Context, arg CountFactsByOrgIDParams) (int64, error)
 	CountFunnels(ctx context.Context, arg CountFunnelsParams) (int64, error)
@@ -38,66 +45,114 @@ type Querier interface {
 	CountObjectsByTypeWithAdvancedFilter(ctx context.Context, arg CountObjectsByTypeWithAdvancedFilterParams) (int64, error)
 	CountObjectsForStep(ctx context.Context, arg CountObjectsForStepParams) (int64, error)
 	CountOngoingTask(ctx context.Context, assignedID uuid.NullUUID) (int64, error)
+	CountOrgTags(ctx context.Context, arg CountOrgTagsParams) (int64, error)
 	CountTags(ctx context.Context, arg CountTagsParams) (int64, error)
 	CountTasksByObjectID(ctx context.Context, arg CountTasksByObjectIDParams) (int64, error)
+	CountTasksByObjectIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]CountTasksByObjectIDsRow, error)
 	CountTasksByOrgID(ctx context.Context, arg CountTasksByOrgIDParams) (int64, error)
 	CountTasksWithFilter(ctx context.Context, arg CountTasksWithFilterParams) (int64, error)
 	CountUnseenFeed(ctx context.Context, creatorID uuid.UUID) (int64, error)
+	CountUnseenMentions(ctx context.Context, creatorID uuid.UUID) (int64, error)
 	CreateActionExecution(ctx context.Context, actionID uuid.UUID) (AutomatedActionExecution, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
 	CreateAutomatedAction(ctx context.Context, arg CreateAutomatedActionParams) (AutomatedAction, error)
+	CreateBulkOperation(ctx context.Context, arg CreateBulkOperationParams) (BulkOperation, error)
+	CreateCalendarFeed(ctx context.Context, arg CreateCalendarFeedParams) (CalendarFeed, error)
 	CreateCreator(ctx context.Context, arg CreateCreatorParams) (Creator, error)
 	CreateCreatorList(ctx context.Context, arg CreateCreatorListParams) (CreatorList, error)
 	// Add these new queries to your existing queries.sql file
 	CreateFact(ctx context.Context, arg CreateFactParams) (Fact, error)
+	CreateFactComment(ctx context.Context, arg CreateFactCommentParams) (FactComment, error)
+	CreateFactSource(ctx context.Context, arg CreateFactSourceParams) (int64, error)
 	CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error)
 	CreateFunnel(ctx context.Context, arg CreateFunnelParams) (Funnel, error)
 	CreateImportTask(ctx context.Context, arg CreateImportTaskParams) (ImportTask, error)
 	CreateList(ctx context.Context, arg CreateListParams) (List, error)
+	CreateObjPhoto(ctx context.Context, arg CreateObjPhotoParams) (ObjPhoto, error)
 	CreateObjStep(ctx context.Context, arg CreateObjStepParams) (CreateObjStepRow, error)
 	CreateObject(ctx context.Context, arg CreateObjectParams) (Obj, error)
+	CreateObjectHistory(ctx context.Context, arg CreateObjectHistoryParams) error
+	CreateObjectRelation(ctx context.Context, arg CreateObjectRelationParams) (ObjRelation, error)
 	CreateObjectType(ctx context.Context, arg CreateObjectTypeParams) (ObjType, error)
 	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Org, error)
+	CreateSchemaMigration(ctx context.Context, arg CreateSchemaMigrationParams) (SchemaMigration, error)
 	CreateStep(ctx context.Context, arg CreateStepParams) (Step, error)
 	// Setting/Tag section
 	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	// Existing queries...
 	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
+	CreateWatch(ctx context.Context, arg CreateWatchParams) (Watch, error)
 	DeleteActionOldExecutions(ctx context.Context, startedAt time.Time) error
+	DeleteAttachment(ctx context.Context, arg DeleteAttachmentParams) (int64, error)
 	DeleteAutomatedAction(ctx context.Context, id uuid.UUID) error
+	DeleteCalendarFeed(ctx context.Context, arg DeleteCalendarFeedParams) (int64, error)
 	DeleteCreator(ctx context.Context, id uuid.UUID) error
 	DeleteCreatorList(ctx context.Context, id uuid.UUID) error
-	DeleteFact(ctx context.Context, id uuid.UUID) error
-	DeleteFunnel(ctx context.Context, id uuid.UUID) error
-	DeleteList(ctx context.Context, id uuid.UUID) error
-	DeleteObject(ctx context.Context, id uuid.UUID) error
+	DeleteFact(ctx context.Context, arg DeleteFactParams) error
+	DeleteFactComment(ctx context.Context, arg DeleteFactCommentParams) (int64, error)
+	DeleteFunnel(ctx context.Context, arg DeleteFunnelParams) error
+	DeleteList(ctx context.Context, arg DeleteListParams) error
+	DeleteObjPhoto(ctx context.Context, id uuid.UUID) error
+	DeleteObject(ctx context.Context, arg DeleteObjectParams) error
+	DeleteObjectRelation(ctx context.Context, arg DeleteObjectRelationParams) (int64, error)
 	DeleteObjectType(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteOrphanedAttachment(ctx context.Context, id uuid.UUID) error
+	DeleteSchemaMigrationBackup(ctx context.Context, migrationID uuid.UUID) error
 	DeleteStep(ctx context.Context, id uuid.UUID) error
-	DeleteTag(ctx context.Context, id uuid.UUID) (int64, error)
-	DeleteTask(ctx context.Context, id uuid.UUID) error
+	DeleteTag(ctx context.Context, arg DeleteTagParams) (int64, error)
+	DeleteTask(ctx context.Context, arg DeleteTaskParams) error
+	DeleteWatch(ctx context.Context, arg DeleteWatchParams) (int64, error)
+	EnsureMailDropbox(ctx context.Context, arg EnsureMailDropboxParams) (MailDropbox, error)
+	EnterSchemaMigration(ctx context.Context, dollar_1 uuid.UUID) error
+	FailBulkOperation(ctx context.Context, arg FailBulkOperationParams) error
+	FailInterruptedBulkOperations(ctx context.Context, errorMessage sql.NullString) (int64, error)
+	FailSchemaMigration(ctx context.Context, arg FailSchemaMigrationParams) error
+	FilterOrgObjectIDs(ctx context.Context, arg FilterOrgObjectIDsParams) ([]uuid.UUID, error)
+	FindCreatorByEmail(ctx context.Context, arg FindCreatorByEmailParams) (uuid.UUID, error)
 	FindObjectByAliasOrIDString(ctx context.Context, arg FindObjectByAliasOrIDStringParams) (Obj, error)
+	FindObjectByTypeValue(ctx context.Context, arg FindObjectByTypeValueParams) (FindObjectByTypeValueRow, error)
 	FindTagByNormalizedName(ctx context.Context, arg FindTagByNormalizedNameParams) (Tag, error)
+	FindWatch(ctx context.Context, arg FindWatchParams) (Watch, error)
 	GetAccessibleObjectTypesForMember(ctx context.Context, creatorID uuid.UUID) ([]uuid.UUID, error)
+	GetActiveObjStep(ctx context.Context, arg GetActiveObjStepParams) (ObjStep, error)
+	GetAddressReview(ctx context.Context, arg GetAddressReviewParams) (AddressReview, error)
+	GetAttachment(ctx context.Context, arg GetAttachmentParams) (Attachment, error)
 	GetAutomatedAction(ctx context.Context, id uuid.UUID) (AutomatedAction, error)
+	GetBulkOperation(ctx context.Context, arg GetBulkOperationParams) (BulkOperation, error)
+	GetCalendarFeed(ctx context.Context, arg GetCalendarFeedParams) (CalendarFeed, error)
 	GetCreatorByID(ctx context.Context, id uuid.UUID) (Creator, error)
 	GetCreatorByUsername(ctx context.Context, arg GetCreatorByUsernameParams) (GetCreatorByUsernameRow, error)
 	GetCreatorDailyActivity(ctx context.Context, creatorID uuid.UUID) ([]GetCreatorDailyActivityRow, error)
 	GetCreatorListByID(ctx context.Context, id uuid.UUID) (GetCreatorListByIDRow, error)
 	GetFactByID(ctx context.Context, id uuid.UUID) (GetFactByIDRow, error)
+	GetFactComment(ctx context.Context, arg GetFactCommentParams) (FactComment, error)
+	GetFactDatesByObjectIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]GetFactDatesByObjectIDsRow, error)
+	GetFactSource(ctx context.Context, arg GetFactSourceParams) (uuid.UUID, error)
+	GetFactVersion(ctx context.Context, arg GetFactVersionParams) (FactVersion, error)
 	GetFeed(ctx context.Context, creatorID uuid.UUID) ([]Feed, error)
 	GetFunnel(ctx context.Context, id uuid.UUID) (GetFunnelRow, error)
 	GetGDPStats(ctx context.Context, arg GetGDPStatsParams) ([]GetGDPStatsRow, error)
+	GetGraphNode(ctx context.Context, arg GetGraphNodeParams) (GetGraphNodeRow, error)
+	GetHealthScoreSetting(ctx context.Context, orgID uuid.UUID) (HealthScoreSetting, error)
 	GetImportTask(ctx context.Context, id uuid.UUID) (ImportTask, error)
 	GetImportTaskHistory(ctx context.Context, arg GetImportTaskHistoryParams) ([]ImportTask, error)
 	GetLatestExecution(ctx context.Context, actionID uuid.UUID) (AutomatedActionExecution, error)
 	GetListByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	GetMailDropbox(ctx context.Context, arg GetMailDropboxParams) (MailDropbox, error)
+	GetMailDropboxByToken(ctx context.Context, token string) (MailDropbox, error)
+	GetObjPhoto(ctx context.Context, arg GetObjPhotoParams) (ObjPhoto, error)
 	GetObjStep(ctx context.Context, id uuid.UUID) (ObjStep, error)
+	GetObjectByID(ctx context.Context, id uuid.UUID) (Obj, error)
 	GetObjectByIDString(ctx context.Context, idString string) (Obj, error)
 	GetObjectDetails(ctx context.Context, arg GetObjectDetailsParams) (GetObjectDetailsRow, error)
+	GetObjectHealth(ctx context.Context, arg GetObjectHealthParams) (ObjHealth, error)
 	GetObjectTypeByID(ctx context.Context, id uuid.UUID) (ObjType, error)
 	GetObjectTypeValue(ctx context.Context, arg GetObjectTypeValueParams) (ObjTypeValue, error)
+	GetObjectTypeValueByID(ctx context.Context, arg GetObjectTypeValueByIDParams) (ObjTypeValue, error)
 	GetObjectsByTypeStats(ctx context.Context, orgID uuid.UUID) ([]GetObjectsByTypeStatsRow, error)
 	GetObjectsForStep(ctx context.Context, arg GetObjectsForStepParams) ([]GetObjectsForStepRow, error)
 	GetOngoingImportTask(ctx context.Context, orgID uuid.UUID) (ImportTask, error)
+	GetOngoingSchemaMigration(ctx context.Context, objTypeID uuid.UUID) (SchemaMigration, error)
 	GetOrgDetails(ctx context.Context, id uuid.UUID) (Org, error)
 	GetPendingActions(ctx context.Context) ([]AutomatedAction, error)
 	GetPublicObject(ctx context.Context, arg GetPublicObjectParams) (GetPublicObjectRow, error)
@@ -109,6 +164,8 @@ type Querier interface {
 	GetPublicRecentFactsByType(ctx context.Context, arg GetPublicRecentFactsByTypeParams) ([]GetPublicRecentFactsByTypeRow, error)
 	GetPublicStats(ctx context.Context, orgID uuid.UUID) (GetPublicStatsRow, error)
 	GetPublicTopObjects(ctx context.Context, orgID uuid.UUID) ([]GetPublicTopObjectsRow, error)
+	GetRunningSchemaMigration(ctx context.Context, typeID uuid.UUID) (uuid.UUID, error)
+	GetSchemaMigration(ctx context.Context, arg GetSchemaMigrationParams) (SchemaMigration, error)
 	GetStep(ctx context.Context, id uuid.UUID) (GetStepRow, error)
 	GetTagByID(ctx context.Context, id uuid.UUID) (Tag, error)
 	GetTagsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Tag, error)
@@ -117,15 +174,51 @@ type Querier interface {
 	HardDeleteObjStep(ctx context.Context, id uuid.UUID) error
 	HasAccessToObjectType(ctx context.Context, arg HasAccessToObjectTypeParams) (bool, error)
 	HealthCheck(ctx context.Context) (int32, error)
+	IsOrgCreator(ctx context.Context, arg IsOrgCreatorParams) (bool, error)
+	IsOrgFact(ctx context.Context, arg IsOrgFactParams) (bool, error)
+	IsOrgList(ctx context.Context, arg IsOrgListParams) (bool, error)
+	IsOrgObjectType(ctx context.Context, arg IsOrgObjectTypeParams) (bool, error)
+	IsOrgStep(ctx context.Context, arg IsOrgStepParams) (bool, error)
+	LinkAddressReviewFacts(ctx context.Context, arg LinkAddressReviewFactsParams) ([]uuid.UUID, error)
 	ListAccessibleObjectTypes(ctx context.Context, arg ListAccessibleObjectTypesParams) ([]ListAccessibleObjectTypesRow, error)
 	ListActionExecutions(ctx context.Context, arg ListActionExecutionsParams) ([]AutomatedActionExecution, error)
+	ListAddressReviews(ctx context.Context, arg ListAddressReviewsParams) ([]AddressReview, error)
+	ListAllCalendarFeeds(ctx context.Context) ([]CalendarFeed, error)
+	ListAttachmentsByFactIDs(ctx context.Context, arg ListAttachmentsByFactIDsParams) ([]Attachment, error)
+	ListAttachmentsByObject(ctx context.Context, arg ListAttachmentsByObjectParams) ([]Attachment, error)
 	ListAutomatedActions(ctx context.Context, arg ListAutomatedActionsParams) ([]AutomatedAction, error)
+	ListBulkOperations(ctx context.Context, arg ListBulkOperationsParams) ([]BulkOperation, error)
+	ListCalendarFeeds(ctx context.Context, orgID uuid.UUID) ([]CalendarFeed, error)
+	ListCoMentionEdges(ctx context.Context, arg ListCoMentionEdgesParams) ([]ListCoMentionEdgesRow, error)
 	ListCreatorListsByCreatorID(ctx context.Context, creatorID uuid.UUID) ([]ListCreatorListsByCreatorIDRow, error)
+	ListFactComments(ctx context.Context, arg ListFactCommentsParams) ([]ListFactCommentsRow, error)
+	ListFactReactions(ctx context.Context, arg ListFactReactionsParams) ([]ListFactReactionsRow, error)
+	ListFactVersions(ctx context.Context, arg ListFactVersionsParams) ([]ListFactVersionsRow, error)
 	ListFactsByOrgID(ctx context.Context, arg ListFactsByOrgIDParams) ([]ListFactsByOrgIDRow, error)
 	ListFunnels(ctx context.Context, arg ListFunnelsParams) ([]ListFunnelsRow, error)
+	ListGraphNeighbors(ctx context.Context, arg ListGraphNeighborsParams) ([]ListGraphNeighborsRow, error)
+	ListHealthInputs(ctx context.Context, arg ListHealthInputsParams) ([]ListHealthInputsRow, error)
+	ListListWatches(ctx context.Context, arg ListListWatchesParams) ([]ListListWatchesRow, error)
 	ListListsByOrgID(ctx context.Context, arg ListListsByOrgIDParams) ([]ListListsByOrgIDRow, error)
+	ListMailDropboxTokens(ctx context.Context, orgID uuid.UUID) ([]string, error)
+	ListMentionedCreators(ctx context.Context, arg ListMentionedCreatorsParams) ([]ListMentionedCreatorsRow, error)
+	ListObjectConnections(ctx context.Context, arg ListObjectConnectionsParams) ([]ListObjectConnectionsRow, error)
+	ListObjectFactIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]uuid.UUID, error)
+	ListObjectHealthHistory(ctx context.Context, arg ListObjectHealthHistoryParams) ([]ObjHealthHistory, error)
+	ListObjectHistory(ctx context.Context, arg ListObjectHistoryParams) ([]ListObjectHistoryRow, error)
+	ListObjectNames(ctx context.Context, dollar_1 []uuid.UUID) ([]ListObjectNamesRow, error)
+	ListObjectNeighbourhood(ctx context.Context, arg ListObjectNeighbourhoodParams) ([]ListObjectNeighbourhoodRow, error)
+	ListObjectRelations(ctx context.Context, arg ListObjectRelationsParams) ([]ListObjectRelationsRow, error)
+	ListObjectStepsInFunnel(ctx context.Context, arg ListObjectStepsInFunnelParams) ([]ListObjectStepsInFunnelRow, error)
+	ListObjectTypeFieldsByOrg(ctx context.Context, orgID uuid.UUID) ([]ListObjectTypeFieldsByOrgRow, error)
+	ListObjectTypeValuesByObject(ctx context.Context, objID uuid.UUID) ([]ListObjectTypeValuesByObjectRow, error)
+	ListObjectTypeValuesForMigration(ctx context.Context, arg ListObjectTypeValuesForMigrationParams) ([]ObjTypeValue, error)
 	ListObjectTypes(ctx context.Context, arg ListObjectTypesParams) ([]ListObjectTypesRow, error)
+	ListObjectTypesByNames(ctx context.Context, arg ListObjectTypesByNamesParams) ([]ListObjectTypesByNamesRow, error)
+	ListObjectWatchers(ctx context.Context, arg ListObjectWatchersParams) ([]ListObjectWatchersRow, error)
 	ListObjectsAdvanced(ctx context.Context, arg ListObjectsAdvancedParams) ([]ListObjectsAdvancedRow, error)
+	ListObjectsByAliasOrIDString(ctx context.Context, arg ListObjectsByAliasOrIDStringParams) ([]ListObjectsByAliasOrIDStringRow, error)
+	ListObjectsByEmail(ctx context.Context, arg ListObjectsByEmailParams) ([]ListObjectsByEmailRow, error)
 	ListObjectsByOrgID(ctx context.Context, arg ListObjectsByOrgIDParams) ([]ListObjectsByOrgIDRow, error)
 	ListObjectsByTaskID(ctx context.Context, taskID uuid.UUID) ([]ListObjectsByTaskIDRow, error)
 	ListObjectsByTypeWithAdvancedFilter(ctx context.Context, arg ListObjectsByTypeWithAdvancedFilterParams) ([]ListObjectsByTypeWithAdvancedFilterRow, error)
@@ -136,13 +229,27 @@ type Querier interface {
 	ListObjectsWithNormalizedData(ctx context.Context, arg ListObjectsWithNormalizedDataParams) ([]ListObjectsWithNormalizedDataRow, error)
 	ListOrgMembers(ctx context.Context, arg ListOrgMembersParams) ([]ListOrgMembersRow, error)
 	ListOrganizations(ctx context.Context) ([]ListOrganizationsRow, error)
+	ListOrphanedAttachments(ctx context.Context, limit int32) ([]Attachment, error)
+	ListOrphanedObjPhotos(ctx context.Context, arg ListOrphanedObjPhotosParams) ([]ObjPhoto, error)
+	ListPublicObjectRelations(ctx context.Context, arg ListPublicObjectRelationsParams) ([]ListPublicObjectRelationsRow, error)
+	ListRelationTypes(ctx context.Context, orgID uuid.UUID) ([]ListRelationTypesRow, error)
+	ListRelationsBetweenObjects(ctx context.Context, dollar_1 []uuid.UUID) ([]ObjRelation, error)
+	ListSchemaMigrations(ctx context.Context, arg ListSchemaMigrationsParams) ([]SchemaMigration, error)
+	ListSharedFacts(ctx context.Context, arg ListSharedFactsParams) ([]ListSharedFactsRow, error)
+	ListStaleSchemaMigrations(ctx context.Context, objTypeID uuid.UUID) ([]SchemaMigration, error)
 	ListStepsByFunnel(ctx context.Context, funnelID uuid.UUID) ([]ListStepsByFunnelRow, error)
+	ListStepsByNames(ctx context.Context, arg ListStepsByNamesParams) ([]ListStepsByNamesRow, error)
 	ListTags(ctx context.Context, arg ListTagsParams) ([]ListTagsRow, error)
+	ListTagsByNames(ctx context.Context, arg ListTagsByNamesParams) ([]ListTagsByNamesRow, error)
 	ListTasksByObjectID(ctx context.Context, arg ListTasksByObjectIDParams) ([]ListTasksByObjectIDRow, error)
 	ListTasksByOrgID(ctx context.Context, arg ListTasksByOrgIDParams) ([]ListTasksByOrgIDRow, error)
 	// Add this new query to your existing queries.sql file
 	ListTasksWithFilter(ctx context.Context, arg ListTasksWithFilterParams) ([]ListTasksWithFilterRow, error)
+	ListTrash(ctx context.Context, arg ListTrashParams) ([]ListTrashRow, error)
+	ListWatchesByCreator(ctx context.Context, creatorID uuid.UUID) ([]ListWatchesByCreatorRow, error)
+	LockFacts(ctx context.Context, dollar_1 []uuid.UUID) error
 	MarkFeedAsSeen(ctx context.Context, dollar_1 []uuid.UUID) error
+	MatchListFilters(ctx context.Context, arg MatchListFiltersParams) ([]MatchListFiltersRow, error)
 	// Update fact references
 	// Update task references
 	// Copy tags
@@ -153,22 +260,56 @@ type Querier interface {
 	// Mark source objects as deleted
 	// Create merge history record
 	MergeObjects(ctx context.Context, arg MergeObjectsParams) error
+	PurgeExpiredTrash(ctx context.Context, dollar_1 time.Time) (PurgeExpiredTrashRow, error)
+	PurgeFact(ctx context.Context, arg PurgeFactParams) (int64, error)
+	PurgeFunnel(ctx context.Context, arg PurgeFunnelParams) (int64, error)
+	PurgeList(ctx context.Context, arg PurgeListParams) (int64, error)
+	PurgeObjStep(ctx context.Context, arg PurgeObjStepParams) (int64, error)
+	PurgeObject(ctx context.Context, arg PurgeObjectParams) (int64, error)
+	PurgeTag(ctx context.Context, arg PurgeTagParams) (int64, error)
+	PurgeTask(ctx context.Context, arg PurgeTaskParams) (int64, error)
+	RebuildObjectTypeValueSearchVectors(ctx context.Context, typeID uuid.UUID) (int64, error)
+	RecordFactVersion(ctx context.Context, arg RecordFactVersionParams) (FactVersion, error)
+	RemoveFactReaction(ctx context.Context, arg RemoveFactReactionParams) (int64, error)
 	RemoveObjectTypeValue(ctx context.Context, arg RemoveObjectTypeValueParams) error
 	RemoveObjectsFromFact(ctx context.Context, arg RemoveObjectsFromFactParams) error
 	RemoveObjectsFromTask(ctx context.Context, arg RemoveObjectsFromTaskParams) error
-	RemoveTagFromObject(ctx context.Context, arg RemoveTagFromObjectParams) error
+	RemoveTagFromObject(ctx context.Context, arg RemoveTagFromObjectParams) (int64, error)
+	ResolveAddressReview(ctx context.Context, arg ResolveAddressReviewParams) (AddressReview, error)
+	RestoreFact(ctx context.Context, arg RestoreFactParams) (int64, error)
+	RestoreFunnel(ctx context.Context, arg RestoreFunnelParams) (int64, error)
+	RestoreList(ctx context.Context, arg RestoreListParams) (int64, error)
+	RestoreObject(ctx context.Context, arg RestoreObjectParams) (int64, error)
+	RestoreTag(ctx context.Context, arg RestoreTagParams) (int64, error)
+	RestoreTask(ctx context.Context, arg RestoreTaskParams) (int64, error)
+	RevertSchemaMigration(ctx context.Context, migrationID uuid.UUID) (int64, error)
 	RevokeAccessToObjectType(ctx context.Context, arg RevokeAccessToObjectTypeParams) error
+	SearchFacts(ctx context.Context, arg SearchFactsParams) ([]SearchFactsRow, error)
+	SearchLists(ctx context.Context, arg SearchListsParams) ([]SearchListsRow, error)
+	SearchObjects(ctx context.Context, arg SearchObjectsParams) ([]SearchObjectsRow, error)
+	SearchTags(ctx context.Context, arg SearchTagsParams) ([]SearchTagsRow, error)
+	SearchTasks(ctx context.Context, arg SearchTasksParams) ([]SearchTasksRow, error)
+	SetFactObjects(ctx context.Context, arg SetFactObjectsParams) error
+	SetFuzzyThreshold(ctx context.Context, dollar_1 float64) error
+	SetObjectOwner(ctx context.Context, arg SetObjectOwnerParams) (uuid.UUID, error)
+	SetObjectPhoto(ctx context.Context, arg SetObjectPhotoParams) (string, error)
+	SetObjectTypeValues(ctx context.Context, arg SetObjectTypeValuesParams) error
 	// Ensure we only get one row
 	SoftDeleteObjStep(ctx context.Context, id uuid.UUID) error
+	SuggestObjects(ctx context.Context, arg SuggestObjectsParams) ([]SuggestObjectsRow, error)
 	SyncObjectAliases(ctx context.Context, arg SyncObjectAliasesParams) (SyncObjectAliasesRow, error)
 	UpdateActionExecution(ctx context.Context, arg UpdateActionExecutionParams) (AutomatedActionExecution, error)
 	UpdateActionLastRun(ctx context.Context, id uuid.UUID) error
 	UpdateAutomatedAction(ctx context.Context, arg UpdateAutomatedActionParams) (AutomatedAction, error)
+	UpdateBulkOperationProgress(ctx context.Context, arg UpdateBulkOperationProgressParams) error
+	UpdateBulkOperationStatus(ctx context.Context, arg UpdateBulkOperationStatusParams) error
+	UpdateCalendarFeedSync(ctx context.Context, arg UpdateCalendarFeedSyncParams) error
 	UpdateCreatorList(ctx context.Context, arg UpdateCreatorListParams) (CreatorList, error)
 	UpdateCreatorPassword(ctx context.Context, arg UpdateCreatorPasswordParams) error
 	UpdateCreatorProfile(ctx context.Context, arg UpdateCreatorProfileParams) (Creator, error)
 	UpdateCreatorRoleAndStatus(ctx context.Context, arg UpdateCreatorRoleAndStatusParams) (Creator, error)
 	UpdateFact(ctx context.Context, arg UpdateFactParams) (Fact, error)
+	UpdateFactComment(ctx context.Context, arg UpdateFactCommentParams) (FactComment, error)
 	UpdateFunnel(ctx context.Context, arg UpdateFunnelParams) (Funnel, error)
 	UpdateImportTaskError(ctx context.Context, arg UpdateImportTaskErrorParams) (ImportTask, error)
 	UpdateImportTaskProgress(ctx context.Context, arg UpdateImportTaskProgressParams) (ImportTask, error)
@@ -178,11 +319,19 @@ type Querier interface {
 	UpdateObjStepSubStatus(ctx context.Context, arg UpdateObjStepSubStatusParams) error
 	UpdateObject(ctx context.Context, arg UpdateObjectParams) (Obj, error)
 	UpdateObjectType(ctx context.Context, arg UpdateObjectTypeParams) (ObjType, error)
+	UpdateObjectTypeFields(ctx context.Context, arg UpdateObjectTypeFieldsParams) error
 	UpdateObjectTypeValue(ctx context.Context, arg UpdateObjectTypeValueParams) (ObjTypeValue, error)
 	UpdateOrgDetails(ctx context.Context, arg UpdateOrgDetailsParams) (Org, error)
+	UpdateSchemaMigrationProgress(ctx context.Context, arg UpdateSchemaMigrationProgressParams) error
+	UpdateSchemaMigrationStatus(ctx context.Context, arg UpdateSchemaMigrationStatusParams) error
 	UpdateStep(ctx context.Context, arg UpdateStepParams) (Step, error)
 	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
 	UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error)
+	UpdateWatchEvents(ctx context.Context, arg UpdateWatchEventsParams) (Watch, error)
+	UpsertAddressReview(ctx context.Context, arg UpsertAddressReviewParams) (uuid.UUID, error)
+	UpsertHealthScoreSetting(ctx context.Context, arg UpsertHealthScoreSettingParams) (HealthScoreSetting, error)
+	UpsertMailDropbox(ctx context.Context, arg UpsertMailDropboxParams) (MailDropbox, error)
+	UpsertObjectHealthBatch(ctx context.Context, dollar_1 json.RawMessage) error
 	UpsertObjectTypeValue(ctx context.Context, arg UpsertObjectTypeValueParams) (ObjTypeValue, error)
 	ValidateMergeObjects(ctx context.Context, arg ValidateMergeObjectsParams) (ValidateMergeObjectsRow, error)
 }
@@ -36,7 +36,7 @@ INSERT INTO obj_type_value (obj_id, type_id, type_values)
 SELECT $1, $2, $3::jsonb
 FROM
   org_check
-RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector
+RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector, version, contact_text, restricted_contacts
 `
 
 type AddObjectTypeValueParams struct {
@@ -57,6 +57,9 @@ func (q *Queries) AddObjectTypeValue(ctx context.Context, arg AddObjectTypeValue
 		&i.LastUpdated,
 		&i.DeletedAt,
 		&i.SearchVector,
+		&i.Version,
+		&i.ContactText,
+		&i.RestrictedContacts,
 	)
 	return i, err
 }
@@ -111,13 +114,13 @@ func (q *Queries) AddObjectsToTask(ctx context.Context, arg AddObjectsToTaskPara
 	return err
 }
 
-const addTagToObject = `-- name: AddTagToObject :exec
+const addTagToObject = `-- name: AddTagToObject :execrows
 INSERT INTO obj_tag (obj_id, tag_id)
 SELECT $1, $2
 FROM obj o
 JOIN creator c ON o.creator_id = c.id
 JOIN tag t ON t.org_id = c.org_id
-WHERE o.id = $1 AND t.id = $2 AND c.org_id = $3
+WHERE o.id = $1 AND t.id = $2 AND c.org_id = $3 AND t.deleted_at IS NULL
 ON CONFLICT DO NOTHING
 `
 
@@ -127,9 +130,12 @@ type AddTagToObjectParams struct {
 	OrgID uuid.UUID `json:"org_id"`
 }
 
-func (q *Queries) AddTagToObject(ctx context.Context, arg AddTagToObjectParams) error {
-	_, err := q.exec(ctx, q.addTagToObjectStmt, addTagToObject, arg.ObjID, arg.TagID, arg.OrgID)
-	return err
+func (q *Queries) AddTagToObject(ctx context.Context, arg AddTagToObjectParams) (int64, error) {
+	result, err := q.exec(ctx, q.addTagToObjectStmt, addTagToObject, arg.ObjID, arg.TagID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const countAccessibleObjectTypes = `-- name: CountAccessibleObjectTypes :one
@@ -171,16 +177,21 @@ JOIN
 WHERE 
     c.org_id = $1 
     AND f.deleted_at IS NULL
-    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%')
+    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%' OR EXISTS (
+        SELECT 1 FROM attachment a
+        WHERE a.fact_id = f.id AND a.text_content ILIKE '%' || $2 || '%'
+    ))
+    AND ($3::text[] IS NULL OR f.kind = ANY($3::text[]))
 `
 
 type CountFactsByOrgIDParams struct {
 	OrgID   uuid.UUID `json:"org_id"`
 	Column2 string    `json:"column_2"`
+	Column3 []string  `json:"column_3"`
 }
 
 func (q *Queries) CountFactsByOrgID(ctx context.Context, arg CountFactsByOrgIDParams) (int64, error) {
-	row := q.queryRow(ctx, q.countFactsByOrgIDStmt, countFactsByOrgID, arg.OrgID, arg.Column2)
+	row := q.queryRow(ctx, q.countFactsByOrgIDStmt, countFactsByOrgID, arg.OrgID, arg.Column2, pq.Array(arg.Column3))
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -447,16 +458,18 @@ func (q *Queries) CreateCreator(ctx context.Context, arg CreateCreatorParams) (C
 
 const createFact = `-- name: CreateFact :one
 
-INSERT INTO fact (text, happened_at, location, creator_id)
-VALUES ($1, $2, $3, $4)
-RETURNING id, text, happened_at, location, creator_id, created_at, last_updated, deleted_at
+INSERT INTO fact (text, happened_at, location, creator_id, kind, metadata)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, text, happened_at, location, creator_id, created_at, last_updated, deleted_at, deleted_by, kind, metadata
 `
 
 type CreateFactParams struct {
-	Text       string       `json:"text"`
-	HappenedAt sql.NullTime `json:"happened_at"`
-	Location   string       `json:"location"`
-	CreatorID  uuid.UUID    `json:"creator_id"`
+	Text       string          `json:"text"`
+	HappenedAt sql.NullTime    `json:"happened_at"`
+	Location   string          `json:"location"`
+	CreatorID  uuid.UUID       `json:"creator_id"`
+	Kind       string          `json:"kind"`
+	Metadata   json.RawMessage `json:"metadata"`
 }
 
 // Add these new queries to your existing queries.sql file
@@ -466,6 +479,8 @@ func (q *Queries) CreateFact(ctx context.Context, arg CreateFactParams) (Fact, e
 		arg.HappenedAt,
 		arg.Location,
 		arg.CreatorID,
+		arg.Kind,
+		arg.Metadata,
 	)
 	var i Fact
 	err := row.Scan(
@@ -477,6 +492,9 @@ func (q *Queries) CreateFact(ctx context.Context, arg CreateFactParams) (Fact, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Kind,
+		&i.Metadata,
 	)
 	return i, err
 }
@@ -510,7 +528,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 const createFunnel = `-- name: CreateFunnel :one
 INSERT INTO funnel (id, name, description, creator_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, description, creator_id, created_at, deleted_at
+RETURNING id, name, description, creator_id, created_at, deleted_at, deleted_by
 `
 
 type CreateFunnelParams struct {
@@ -535,6 +553,7 @@ func (q *Queries) CreateFunnel(ctx context.Context, arg CreateFunnelParams) (Fun
 		&i.CreatorID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -611,7 +630,7 @@ func (q *Queries) CreateObjStep(ctx context.Context, arg CreateObjStepParams) (C
 const createObject = `-- name: CreateObject :one
 INSERT INTO obj (name, description, id_string, creator_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases
+RETURNING id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases, deleted_by, version
 `
 
 type CreateObjectParams struct {
@@ -639,6 +658,8 @@ func (q *Queries) CreateObject(ctx context.Context, arg CreateObjectParams) (Obj
 		&i.CreatedAt,
 		&i.DeletedAt,
 		pq.Array(&i.Aliases),
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
@@ -756,7 +777,7 @@ const createTask = `-- name: CreateTask :one
 
 INSERT INTO task (content, deadline, remind_at, status, creator_id, assigned_id, parent_id)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, content, deadline, remind_at, status, creator_id, assigned_id, parent_id, created_at, last_updated, deleted_at
+RETURNING id, content, deadline, remind_at, status, creator_id, assigned_id, parent_id, created_at, last_updated, deleted_at, deleted_by
 `
 
 type CreateTaskParams struct {
@@ -793,6 +814,7 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -808,18 +830,23 @@ func (q *Queries) DeleteCreator(ctx context.Context, id uuid.UUID) error {
 
 const deleteFact = `-- name: DeleteFact :exec
 UPDATE fact
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteFact(ctx context.Context, id uuid.UUID) error {
-	_, err := q.exec(ctx, q.deleteFactStmt, deleteFact, id)
+type DeleteFactParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteFact(ctx context.Context, arg DeleteFactParams) error {
+	_, err := q.exec(ctx, q.deleteFactStmt, deleteFact, arg.ID, arg.DeletedBy)
 	return err
 }
 
 const deleteFunnel = `-- name: DeleteFunnel :exec
 UPDATE funnel
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE funnel.id = $1 AND deleted_at IS NULL
   AND NOT EXISTS (
     SELECT 1 FROM obj_step os
@@ -829,17 +856,38 @@ WHERE funnel.id = $1 AND deleted_at IS NULL
   )
 `
 
-func (q *Queries) DeleteFunnel(ctx context.Context, id uuid.UUID) error {
-	_, err := q.exec(ctx, q.deleteFunnelStmt, deleteFunnel, id)
+type DeleteFunnelParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteFunnel(ctx context.Context, arg DeleteFunnelParams) error {
+	_, err := q.exec(ctx, q.deleteFunnelStmt, deleteFunnel, arg.ID, arg.DeletedBy)
 	return err
 }
 
 const deleteObject = `-- name: DeleteObject :exec
-UPDATE obj SET deleted_at = NOW() WHERE id = $1
+-- Funnel steps are detached with the same deleted_at, restoring the object
+-- re-links them
+WITH deleted AS (
+    UPDATE obj
+    SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING id, deleted_at
+)
+UPDATE obj_step os
+SET deleted_at = d.deleted_at
+FROM deleted d
+WHERE os.obj_id = d.id AND os.deleted_at IS NULL
 `
 
-func (q *Queries) DeleteObject(ctx context.Context, id uuid.UUID) error {
-	_, err := q.exec(ctx, q.deleteObjectStmt, deleteObject, id)
+type DeleteObjectParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteObject(ctx context.Context, arg DeleteObjectParams) error {
+	_, err := q.exec(ctx, q.deleteObjectStmt, deleteObject, arg.ID, arg.DeletedBy)
 	return err
 }
 
@@ -876,12 +924,17 @@ func (q *Queries) DeleteStep(ctx context.Context, id uuid.UUID) error {
 
 const deleteTask = `-- name: DeleteTask :exec
 UPDATE task
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	_, err := q.exec(ctx, q.deleteTaskStmt, deleteTask, id)
+type DeleteTaskParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg DeleteTaskParams) error {
+	_, err := q.exec(ctx, q.deleteTaskStmt, deleteTask, arg.ID, arg.DeletedBy)
 	return err
 }
 
@@ -978,22 +1031,25 @@ func (q *Queries) GetCreatorByUsername(ctx context.Context, arg GetCreatorByUser
 }
 
 const getFactByID = `-- name: GetFactByID :one
-SELECT f.id, f.text, f.happened_at, f.location, f.creator_id, f.created_at, f.last_updated, f.deleted_at, c.username as creator_name
+SELECT f.id, f.text, f.happened_at, f.location, f.creator_id, f.created_at, f.last_updated, f.deleted_at, f.deleted_by, f.kind, f.metadata, c.username as creator_name
 FROM fact f
 JOIN creator c ON f.creator_id = c.id
 WHERE f.id = $1 AND f.deleted_at IS NULL
 `
 
 type GetFactByIDRow struct {
-	ID          uuid.UUID    `json:"id"`
-	Text        string       `json:"text"`
-	HappenedAt  sql.NullTime `json:"happened_at"`
-	Location    string       `json:"location"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	LastUpdated time.Time    `json:"last_updated"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
-	CreatorName string       `json:"creator_name"`
+	ID          uuid.UUID       `json:"id"`
+	Text        string          `json:"text"`
+	HappenedAt  sql.NullTime    `json:"happened_at"`
+	Location    string          `json:"location"`
+	CreatorID   uuid.UUID       `json:"creator_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	LastUpdated time.Time       `json:"last_updated"`
+	DeletedAt   sql.NullTime    `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID   `json:"deleted_by"`
+	Kind        string          `json:"kind"`
+	Metadata    json.RawMessage `json:"metadata"`
+	CreatorName string          `json:"creator_name"`
 }
 
 func (q *Queries) GetFactByID(ctx context.Context, id uuid.UUID) (GetFactByIDRow, error) {
@@ -1008,6 +1064,9 @@ func (q *Queries) GetFactByID(ctx context.Context, id uuid.UUID) (GetFactByIDRow
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Kind,
+		&i.Metadata,
 		&i.CreatorName,
 	)
 	return i, err
@@ -1051,7 +1110,7 @@ func (q *Queries) GetFeed(ctx context.Context, creatorID uuid.UUID) ([]Feed, err
 }
 
 const getFunnel = `-- name: GetFunnel :one
-SELECT f.id, f.name, f.description, f.creator_id, f.created_at, f.deleted_at, c.org_id,
+SELECT f.id, f.name, f.description, f.creator_id, f.created_at, f.deleted_at, f.deleted_by, c.org_id,
   (SELECT COUNT(*) FROM obj_step os
   JOIN step s ON s.id = os.step_id
   WHERE s.funnel_id = f.id) AS object_count
@@ -1061,14 +1120,15 @@ WHERE f.id = $1 AND f.deleted_at IS NULL
 `
 
 type GetFunnelRow struct {
-	ID          uuid.UUID    `json:"id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
-	OrgID       uuid.UUID    `json:"org_id"`
-	ObjectCount int64        `json:"object_count"`
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	CreatorID   uuid.UUID     `json:"creator_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID `json:"deleted_by"`
+	OrgID       uuid.UUID     `json:"org_id"`
+	ObjectCount int64         `json:"object_count"`
 }
 
 func (q *Queries) GetFunnel(ctx context.Context, id uuid.UUID) (GetFunnelRow, error) {
@@ -1081,6 +1141,7 @@ func (q *Queries) GetFunnel(ctx context.Context, id uuid.UUID) (GetFunnelRow, er
 		&i.CreatorID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 		&i.OrgID,
 		&i.ObjectCount,
 	)
@@ -1111,7 +1172,7 @@ func (q *Queries) GetObjStep(ctx context.Context, id uuid.UUID) (ObjStep, error)
 const getObjectDetails = `-- name: GetObjectDetails :one
 WITH object_data AS (
     SELECT o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at,
-           c.org_id, o.aliases,
+           c.org_id, o.aliases, o.version,
            coalesce(
             json_agg(DISTINCT jsonb_build_object('id', t.id, 'name', t.name, 'color_schema', t.color_schema)) FILTER (WHERE t.id IS NOT NULL), '[]') 
            AS tags,
@@ -1121,7 +1182,8 @@ WITH object_data AS (
                'objectTypeName', ot.name,
                'objectTypeIcon', ot.icon,
                'objectTypeFields', ot.fields,
-               'type_values', otv.type_values
+               'type_values', otv.type_values,
+               'version', otv.version
            )) FILTER (WHERE otv.id IS NOT NULL), '[]')
            AS type_values,
            coalesce(json_agg(DISTINCT jsonb_build_object(
@@ -1167,7 +1229,7 @@ WITH object_data AS (
     LEFT JOIN obj_fact of ON o.id = of.obj_id
     LEFT JOIN fact ON of.fact_id = fact.id
     WHERE o.id = $1 AND c.org_id = $2
-    GROUP BY o.id, o.name, o.description, o.id_string, o.creator_id, o.created_at, c.org_id, o.aliases
+    GROUP BY o.id, o.name, o.description, o.id_string, o.creator_id, o.created_at, c.org_id, o.aliases, o.version
 )
 SELECT id, name, photo, description, id_string, creator_id, created_at, org_id, aliases, tags, type_values, tasks, steps_and_funnels, facts
 FROM object_data
@@ -1188,6 +1250,7 @@ type GetObjectDetailsRow struct {
 	CreatedAt       time.Time   `json:"created_at"`
 	OrgID           uuid.UUID   `json:"org_id"`
 	Aliases         []string    `json:"aliases"`
+	Version         int32       `json:"version"`
 	Tags            interface{} `json:"tags"`
 	TypeValues      interface{} `json:"type_values"`
 	Tasks           interface{} `json:"tasks"`
@@ -1208,6 +1271,7 @@ func (q *Queries) GetObjectDetails(ctx context.Context, arg GetObjectDetailsPara
 		&i.CreatedAt,
 		&i.OrgID,
 		pq.Array(&i.Aliases),
+		&i.Version,
 		&i.Tags,
 		&i.TypeValues,
 		&i.Tasks,
@@ -1242,6 +1306,35 @@ func (q *Queries) GetObjectTypeByID(ctx context.Context, id uuid.UUID) (ObjType,
 	return i, err
 }
 
+const getObjectTypeValueByID = `-- name: GetObjectTypeValueByID :one
+SELECT otv.id, otv.obj_id, otv.type_id, otv.type_values, otv.created_at, otv.last_updated, otv.deleted_at, otv.search_vector, otv.version FROM obj_type_value otv
+JOIN obj o ON o.id = otv.obj_id
+JOIN creator c ON o.creator_id = c.id
+WHERE otv.id = $1 AND c.org_id = $2
+`
+
+type GetObjectTypeValueByIDParams struct {
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+func (q *Queries) GetObjectTypeValueByID(ctx context.Context, arg GetObjectTypeValueByIDParams) (ObjTypeValue, error) {
+	row := q.queryRow(ctx, q.getObjectTypeValueByIDStmt, getObjectTypeValueByID, arg.ID, arg.OrgID)
+	var i ObjTypeValue
+	err := row.Scan(
+		&i.ID,
+		&i.ObjID,
+		&i.TypeID,
+		&i.TypeValues,
+		&i.CreatedAt,
+		&i.LastUpdated,
+		&i.DeletedAt,
+		&i.SearchVector,
+		&i.Version,
+	)
+	return i, err
+}
+
 const getObjectsForStep = `-- name: GetObjectsForStep :many
 SELECT o.id, o.name, o.description,
        coalesce(json_agg(json_build_object('id', t.id, 'name', t.name, 'color_schema', t.color_schema)) FILTER (WHERE t.id IS NOT NULL), '[]') AS tags
@@ -1364,7 +1457,7 @@ func (q *Queries) GetStep(ctx context.Context, id uuid.UUID) (GetStepRow, error)
 }
 
 const getTaskByID = `-- name: GetTaskByID :one
-SELECT t.id, t.content, t.deadline, t.remind_at, t.status, t.creator_id, t.assigned_id, t.parent_id, t.created_at, t.last_updated, t.deleted_at, c.username as creator_name, a.username as assigned_name
+SELECT t.id, t.content, t.deadline, t.remind_at, t.status, t.creator_id, t.assigned_id, t.parent_id, t.created_at, t.last_updated, t.deleted_at, t.deleted_by, c.username as creator_name, a.username as assigned_name
 FROM task t
 JOIN creator c ON t.creator_id = c.id
 LEFT JOIN creator a ON t.assigned_id = a.id
@@ -1383,6 +1476,7 @@ type GetTaskByIDRow struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	LastUpdated  time.Time      `json:"last_updated"`
 	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	DeletedBy    uuid.NullUUID  `json:"deleted_by"`
 	CreatorName  string         `json:"creator_name"`
 	AssignedName sql.NullString `json:"assigned_name"`
 }
@@ -1402,6 +1496,7 @@ func (q *Queries) GetTaskByID(ctx context.Context, id uuid.UUID) (GetTaskByIDRow
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
 		&i.CreatorName,
 		&i.AssignedName,
 	)
@@ -1537,6 +1632,8 @@ SELECT
     f.creator_id,
     c.username AS creator_name,
     f.created_at,
+    f.kind,
+    f.metadata,
     COALESCE(
         json_agg(
             json_build_object(
@@ -1558,7 +1655,11 @@ LEFT JOIN
 WHERE 
     c.org_id = $1 
     AND f.deleted_at IS NULL
-    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%')
+    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%' OR EXISTS (
+        SELECT 1 FROM attachment a
+        WHERE a.fact_id = f.id AND a.text_content ILIKE '%' || $2 || '%'
+    ))
+    AND ($5::text[] IS NULL OR f.kind = ANY($5::text[]))
 GROUP BY 
     f.id, c.username
 ORDER BY 
@@ -1571,17 +1672,20 @@ type ListFactsByOrgIDParams struct {
 	Column2 string    `json:"column_2"`
 	Limit   int32     `json:"limit"`
 	Offset  int32     `json:"offset"`
+	Column5 []string  `json:"column_5"`
 }
 
 type ListFactsByOrgIDRow struct {
-	ID             uuid.UUID    `json:"id"`
-	Text           string       `json:"text"`
-	HappenedAt     sql.NullTime `json:"happened_at"`
-	Location       string       `json:"location"`
-	CreatorID      uuid.UUID    `json:"creator_id"`
-	CreatorName    string       `json:"creator_name"`
-	CreatedAt      time.Time    `json:"created_at"`
-	RelatedObjects interface{}  `json:"related_objects"`
+	ID             uuid.UUID       `json:"id"`
+	Text           string          `json:"text"`
+	HappenedAt     sql.NullTime    `json:"happened_at"`
+	Location       string          `json:"location"`
+	CreatorID      uuid.UUID       `json:"creator_id"`
+	CreatorName    string          `json:"creator_name"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Kind           string          `json:"kind"`
+	Metadata       json.RawMessage `json:"metadata"`
+	RelatedObjects interface{}     `json:"related_objects"`
 }
 
 func (q *Queries) ListFactsByOrgID(ctx context.Context, arg ListFactsByOrgIDParams) ([]ListFactsByOrgIDRow, error) {
@@ -1590,6 +1694,7 @@ func (q *Queries) ListFactsByOrgID(ctx context.Context, arg ListFactsByOrgIDPara
 		arg.Column2,
 		arg.Limit,
 		arg.Offset,
+		pq.Array(arg.Column5),
 	)
 	if err != nil {
 		return nil, err
@@ -1606,6 +1711,8 @@ func (q *Queries) ListFactsByOrgID(ctx context.Context, arg ListFactsByOrgIDPara
 			&i.CreatorID,
 			&i.CreatorName,
 			&i.CreatedAt,
+			&i.Kind,
+			&i.Metadata,
 			&i.RelatedObjects,
 		); err != nil {
 			return nil, err
@@ -1622,7 +1729,7 @@ func (q *Queries) ListFactsByOrgID(ctx context.Context, arg ListFactsByOrgIDPara
 }
 
 const listFunnels = `-- name: ListFunnels :many
-SELECT f.id, f.name, f.description, f.creator_id, f.created_at, f.deleted_at, c.org_id,
+SELECT f.id, f.name, f.description, f.creator_id, f.created_at, f.deleted_at, f.deleted_by, c.org_id,
     (SELECT COUNT(*) FROM obj_step os
     JOIN step s ON s.id = os.step_id
     WHERE s.funnel_id = f.id) AS object_count
@@ -1654,14 +1761,15 @@ type ListFunnelsParams struct {
 }
 
 type ListFunnelsRow struct {
-	ID          uuid.UUID    `json:"id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	CreatorID   uuid.UUID    `json:"creator_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at"`
-	OrgID       uuid.UUID    `json:"org_id"`
-	ObjectCount int64        `json:"object_count"`
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	CreatorID   uuid.UUID     `json:"creator_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+	DeletedBy   uuid.NullUUID `json:"deleted_by"`
+	OrgID       uuid.UUID     `json:"org_id"`
+	ObjectCount int64         `json:"object_count"`
 }
 
 func (q *Queries) ListFunnels(ctx context.Context, arg ListFunnelsParams) ([]ListFunnelsRow, error) {
@@ -1685,6 +1793,7 @@ func (q *Queries) ListFunnels(ctx context.Context, arg ListFunnelsParams) ([]Lis
 			&i.CreatorID,
 			&i.CreatedAt,
 			&i.DeletedAt,
+			&i.DeletedBy,
 			&i.OrgID,
 			&i.ObjectCount,
 		); err != nil {
@@ -2038,7 +2147,7 @@ func (q *Queries) ListTasksByObjectID(ctx context.Context, arg ListTasksByObject
 }
 
 const listTasksByOrgID = `-- name: ListTasksByOrgID :many
-SELECT t.id, t.content, t.deadline, t.remind_at, t.status, t.creator_id, t.assigned_id, t.parent_id, t.created_at, t.last_updated, t.deleted_at, c.username as creator_name, a.username as assigned_name
+SELECT t.id, t.content, t.deadline, t.remind_at, t.status, t.creator_id, t.assigned_id, t.parent_id, t.created_at, t.last_updated, t.deleted_at, t.deleted_by, c.username as creator_name, a.username as assigned_name
 FROM task t
 JOIN creator c ON t.creator_id = c.id
 LEFT JOIN creator a ON t.assigned_id = a.id
@@ -2071,6 +2180,7 @@ type ListTasksByOrgIDRow struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	LastUpdated  time.Time      `json:"last_updated"`
 	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	DeletedBy    uuid.NullUUID  `json:"deleted_by"`
 	CreatorName  string         `json:"creator_name"`
 	AssignedName sql.NullString `json:"assigned_name"`
 }
@@ -2101,6 +2211,7 @@ func (q *Queries) ListTasksByOrgID(ctx context.Context, arg ListTasksByOrgIDPara
 			&i.CreatedAt,
 			&i.LastUpdated,
 			&i.DeletedAt,
+			&i.DeletedBy,
 			&i.CreatorName,
 			&i.AssignedName,
 		); err != nil {
@@ -2324,7 +2435,7 @@ func (q *Queries) RemoveObjectsFromTask(ctx context.Context, arg RemoveObjectsFr
 	return err
 }
 
-const removeTagFromObject = `-- name: RemoveTagFromObject :exec
+const removeTagFromObject = `-- name: RemoveTagFromObject :execrows
 DELETE FROM obj_tag
 WHERE obj_id = $1 AND tag_id = $2
 AND EXISTS (
@@ -2340,9 +2451,12 @@ type RemoveTagFromObjectParams struct {
 	OrgID uuid.UUID `json:"org_id"`
 }
 
-func (q *Queries) RemoveTagFromObject(ctx context.Context, arg RemoveTagFromObjectParams) error {
-	_, err := q.exec(ctx, q.removeTagFromObjectStmt, removeTagFromObject, arg.ObjID, arg.TagID, arg.OrgID)
-	return err
+func (q *Queries) RemoveTagFromObject(ctx context.Context, arg RemoveTagFromObjectParams) (int64, error) {
+	result, err := q.exec(ctx, q.removeTagFromObjectStmt, removeTagFromObject, arg.ObjID, arg.TagID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const revokeAccessToObjectType = `-- name: RevokeAccessToObjectType :exec
@@ -2456,16 +2570,18 @@ func (q *Queries) UpdateCreatorRoleAndStatus(ctx context.Context, arg UpdateCrea
 
 const updateFact = `-- name: UpdateFact :one
 UPDATE fact
-SET text = $2, happened_at = $3, location = $4
+SET text = $2, happened_at = $3, location = $4, kind = $5, metadata = $6
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, text, happened_at, location, creator_id, created_at, last_updated, deleted_at
+RETURNING id, text, happened_at, location, creator_id, created_at, last_updated, deleted_at, deleted_by, kind, metadata
 `
 
 type UpdateFactParams struct {
-	ID         uuid.UUID    `json:"id"`
-	Text       string       `json:"text"`
-	HappenedAt sql.NullTime `json:"happened_at"`
-	Location   string       `json:"location"`
+	ID         uuid.UUID       `json:"id"`
+	Text       string          `json:"text"`
+	HappenedAt sql.NullTime    `json:"happened_at"`
+	Location   string          `json:"location"`
+	Kind       string          `json:"kind"`
+	Metadata   json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) UpdateFact(ctx context.Context, arg UpdateFactParams) (Fact, error) {
@@ -2474,6 +2590,8 @@ func (q *Queries) UpdateFact(ctx context.Context, arg UpdateFactParams) (Fact, e
 		arg.Text,
 		arg.HappenedAt,
 		arg.Location,
+		arg.Kind,
+		arg.Metadata,
 	)
 	var i Fact
 	err := row.Scan(
@@ -2485,6 +2603,9 @@ func (q *Queries) UpdateFact(ctx context.Context, arg UpdateFactParams) (Fact, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Kind,
+		&i.Metadata,
 	)
 	return i, err
 }
@@ -2493,7 +2614,7 @@ const updateFunnel = `-- name: UpdateFunnel :one
 UPDATE funnel
 SET name = $2, description = $3
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, name, description, creator_id, created_at, deleted_at
+RETURNING id, name, description, creator_id, created_at, deleted_at, deleted_by
 `
 
 type UpdateFunnelParams struct {
@@ -2512,6 +2633,7 @@ func (q *Queries) UpdateFunnel(ctx context.Context, arg UpdateFunnelParams) (Fun
 		&i.CreatorID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
@@ -2552,7 +2674,8 @@ const updateObject = `-- name: UpdateObject :one
 UPDATE obj
 SET name = $2, description = $3, id_string = $4, aliases = $5
 WHERE id = $1
-RETURNING id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases
+  AND ($6::int = 0 OR version = $6)
+RETURNING id, name, photo, description, id_string, creator_id, created_at, deleted_at, aliases, deleted_by, version
 `
 
 type UpdateObjectParams struct {
@@ -2561,6 +2684,7 @@ type UpdateObjectParams struct {
 	Description string    `json:"description"`
 	IDString    string    `json:"id_string"`
 	Aliases     []string  `json:"aliases"`
+	Column6     int32     `json:"column_6"`
 }
 
 func (q *Queries) UpdateObject(ctx context.Context, arg UpdateObjectParams) (Obj, error) {
@@ -2570,6 +2694,7 @@ func (q *Queries) UpdateObject(ctx context.Context, arg UpdateObjectParams) (Obj
 		arg.Description,
 		arg.IDString,
 		pq.Array(arg.Aliases),
+		arg.Column6,
 	)
 	var i Obj
 	err := row.Scan(
@@ -2582,6 +2707,8 @@ func (q *Queries) UpdateObject(ctx context.Context, arg UpdateObjectParams) (Obj
 		&i.CreatedAt,
 		&i.DeletedAt,
 		pq.Array(&i.Aliases),
+		&i.DeletedBy,
+		&i.Version,
 	)
 	return i, err
 }
@@ -2639,17 +2766,24 @@ WHERE obj_type_value.id = $1
     JOIN creator c ON o.creator_id = c.id
     WHERE o.id = obj_type_value.obj_id AND c.org_id = $2
   )
-RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector
+  AND ($4::int = 0 OR obj_type_value.version = $4)
+RETURNING id, obj_id, type_id, type_values, created_at, last_updated, deleted_at, search_vector, version, contact_text, restricted_contacts
 `
 
 type UpdateObjectTypeValueParams struct {
 	ID      uuid.UUID       `json:"id"`
 	OrgID   uuid.UUID       `json:"org_id"`
 	Column3 json.RawMessage `json:"column_3"`
+	Column4 int32           `json:"column_4"`
 }
 
 func (q *Queries) UpdateObjectTypeValue(ctx context.Context, arg UpdateObjectTypeValueParams) (ObjTypeValue, error) {
-	row := q.queryRow(ctx, q.updateObjectTypeValueStmt, updateObjectTypeValue, arg.ID, arg.OrgID, arg.Column3)
+	row := q.queryRow(ctx, q.updateObjectTypeValueStmt, updateObjectTypeValue,
+		arg.ID,
+		arg.OrgID,
+		arg.Column3,
+		arg.Column4,
+	)
 	var i ObjTypeValue
 	err := row.Scan(
 		&i.ID,
@@ -2660,6 +2794,9 @@ func (q *Queries) UpdateObjectTypeValue(ctx context.Context, arg UpdateObjectTyp
 		&i.LastUpdated,
 		&i.DeletedAt,
 		&i.SearchVector,
+		&i.Version,
+		&i.ContactText,
+		&i.RestrictedContacts,
 	)
 	return i, err
 }
@@ -2741,7 +2878,7 @@ SET content = $2,
     parent_id = $7,
     last_updated = CURRENT_TIMESTAMP
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, content, deadline, remind_at, status, creator_id, assigned_id, parent_id, created_at, last_updated, deleted_at
+RETURNING id, content, deadline, remind_at, status, creator_id, assigned_id, parent_id, created_at, last_updated, deleted_at, deleted_by
 `
 
 type UpdateTaskParams struct {
@@ -2777,6 +2914,7 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.CreatedAt,
 		&i.LastUpdated,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
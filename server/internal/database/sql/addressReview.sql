@@ -0,0 +1,48 @@
+-- name: UpsertAddressReview :one
+-- Queues an unmatched address, counting the messages it was seen in. The
+-- first name seen is kept and a dismissed address stays dismissed.
+INSERT INTO address_review (org_id, email, name, import_task_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (org_id, email) DO UPDATE
+SET message_count = address_review.message_count + 1,
+    name = COALESCE(NULLIF(address_review.name, ''), EXCLUDED.name),
+    import_task_id = EXCLUDED.import_task_id,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING id;
+
+-- name: AddAddressReviewFact :exec
+INSERT INTO address_review_fact (review_id, fact_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ListAddressReviews :many
+SELECT * FROM address_review
+WHERE org_id = $1 AND status = $2
+ORDER BY message_count DESC, email
+LIMIT $3 OFFSET $4;
+
+-- name: CountAddressReviews :one
+SELECT COUNT(*) FROM address_review
+WHERE org_id = $1 AND status = $2;
+
+-- name: GetAddressReview :one
+SELECT * FROM address_review
+WHERE id = $1 AND org_id = $2;
+
+-- name: ResolveAddressReview :one
+-- Only pending addresses are resolved, once
+UPDATE address_review
+SET status = $3, obj_id = $4, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND org_id = $2 AND status = 'pending'
+RETURNING *;
+
+-- name: LinkAddressReviewFacts :many
+-- Links the active facts an address was seen in to the object it became,
+-- returning the facts newly linked
+INSERT INTO obj_fact (obj_id, fact_id)
+SELECT $2, arf.fact_id
+FROM address_review_fact arf
+JOIN fact f ON f.id = arf.fact_id
+WHERE arf.review_id = $1 AND f.deleted_at IS NULL
+ON CONFLICT DO NOTHING
+RETURNING fact_id;
@@ -0,0 +1,43 @@
+-- name: CreateAttachment :one
+-- Nothing is inserted when the fact or object is not an active one of the org
+INSERT INTO attachment (id, org_id, fact_id, obj_id, creator_id, filename, content_type, size, text_content)
+SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+WHERE ($3::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM fact f
+        JOIN creator c ON f.creator_id = c.id
+        WHERE f.id = $3 AND c.org_id = $2 AND f.deleted_at IS NULL
+    ))
+  AND ($4::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM obj o
+        JOIN creator c ON o.creator_id = c.id
+        WHERE o.id = $4 AND c.org_id = $2 AND o.deleted_at IS NULL
+    ))
+RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachment
+WHERE id = $1 AND org_id = $2;
+
+-- name: DeleteAttachment :execrows
+DELETE FROM attachment
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListAttachmentsByFactIDs :many
+SELECT * FROM attachment
+WHERE fact_id = ANY($1::uuid[]) AND org_id = $2
+ORDER BY created_at;
+
+-- name: ListAttachmentsByObject :many
+SELECT * FROM attachment
+WHERE obj_id = $1 AND org_id = $2
+ORDER BY created_at;
+
+-- name: ListOrphanedAttachments :many
+SELECT * FROM attachment
+WHERE fact_id IS NULL AND obj_id IS NULL
+ORDER BY created_at
+LIMIT $1;
+
+-- name: DeleteOrphanedAttachment :exec
+DELETE FROM attachment
+WHERE id = $1 AND fact_id IS NULL AND obj_id IS NULL;
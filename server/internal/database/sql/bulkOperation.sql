@@ -0,0 +1,90 @@
+-- name: CreateBulkOperation :one
+INSERT INTO bulk_operation (
+    org_id, creator_id, operation, params, status, total_rows
+)
+VALUES ($1, $2, $3, $4, 'pending', $5)
+RETURNING *;
+
+-- name: GetBulkOperation :one
+SELECT * FROM bulk_operation
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListBulkOperations :many
+SELECT * FROM bulk_operation
+WHERE org_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateBulkOperationStatus :exec
+UPDATE bulk_operation
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateBulkOperationProgress :exec
+UPDATE bulk_operation
+SET progress = $2, processed_rows = $3, failed_rows = $4, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CompleteBulkOperation :exec
+UPDATE bulk_operation
+SET status = 'completed', progress = 100, result_summary = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: FailBulkOperation :exec
+UPDATE bulk_operation
+SET status = 'failed', error_message = $2, result_summary = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: FailInterruptedBulkOperations :execrows
+-- The operations a stopped server left unfinished, run when it starts
+UPDATE bulk_operation
+SET status = 'failed', error_message = $1, updated_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'processing');
+
+-- name: FilterOrgObjectIDs :many
+SELECT o.id FROM obj o
+JOIN creator c ON o.creator_id = c.id
+WHERE o.id = ANY($1::uuid[]) AND c.org_id = $2 AND o.deleted_at IS NULL;
+
+-- name: CountOrgTags :one
+SELECT COUNT(*) FROM tag
+WHERE id = ANY($1::uuid[]) AND org_id = $2 AND deleted_at IS NULL;
+
+-- name: IsOrgStep :one
+SELECT EXISTS (
+    SELECT 1 FROM step s
+    JOIN funnel f ON s.funnel_id = f.id
+    JOIN creator c ON f.creator_id = c.id
+    WHERE s.id = $1 AND c.org_id = $2 AND s.deleted_at IS NULL AND f.deleted_at IS NULL
+);
+
+-- name: IsOrgCreator :one
+SELECT EXISTS (
+    SELECT 1 FROM creator
+    WHERE id = $1 AND org_id = $2 AND active AND deleted_at IS NULL
+);
+
+-- name: IsOrgObjectType :one
+SELECT EXISTS (
+    SELECT 1 FROM obj_type ot
+    JOIN creator c ON ot.creator_id = c.id
+    WHERE ot.id = $1 AND c.org_id = $2
+);
+
+-- name: GetActiveObjStep :one
+SELECT * FROM obj_step
+WHERE obj_id = $1 AND step_id = $2 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: SetObjectOwner :one
+-- Returns the previous owner
+WITH previous AS (
+    SELECT id, creator_id FROM obj
+    WHERE id = $1 AND deleted_at IS NULL
+    FOR UPDATE
+)
+UPDATE obj o
+SET creator_id = $2
+FROM previous p
+WHERE o.id = p.id
+RETURNING p.creator_id;
@@ -0,0 +1,31 @@
+-- name: CreateCalendarFeed :one
+INSERT INTO calendar_feed (org_id, creator_id, name, url)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetCalendarFeed :one
+SELECT * FROM calendar_feed
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListCalendarFeeds :many
+SELECT * FROM calendar_feed
+WHERE org_id = $1
+ORDER BY created_at;
+
+-- name: ListAllCalendarFeeds :many
+-- Feeds to sync, those of deactivated creators are left alone
+SELECT cf.* FROM calendar_feed cf
+JOIN creator c ON cf.creator_id = c.id
+WHERE c.active AND c.deleted_at IS NULL
+ORDER BY cf.last_synced_at NULLS FIRST;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feed
+WHERE id = $1 AND org_id = $2;
+
+-- name: UpdateCalendarFeedSync :exec
+-- Records how a sync went, last_synced_at is the last one that succeeded
+UPDATE calendar_feed
+SET last_synced_at = CASE WHEN $2::text IS NULL THEN CURRENT_TIMESTAMP ELSE last_synced_at END,
+    last_error = $2::text
+WHERE id = $1;
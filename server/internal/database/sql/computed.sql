@@ -0,0 +1,28 @@
+-- name: ListObjectTypeFieldsByOrg :many
+SELECT ot.id, ot.fields
+FROM obj_type ot
+JOIN creator c ON ot.creator_id = c.id
+WHERE c.org_id = $1 AND ot.deleted_at IS NULL;
+
+-- name: CountTasksByObjectIDs :many
+SELECT
+    ota.obj_id,
+    COUNT(t.id) AS task_count,
+    COUNT(t.id) FILTER (WHERE t.status <> 'completed') AS open_tasks,
+    COUNT(t.id) FILTER (WHERE t.status <> 'completed' AND t.deadline < CURRENT_TIMESTAMP) AS overdue_tasks
+FROM obj_task ota
+JOIN task t ON t.id = ota.task_id
+WHERE ota.obj_id = ANY($1::uuid[]) AND t.deleted_at IS NULL
+GROUP BY ota.obj_id;
+
+-- name: GetFactDatesByObjectIDs :many
+-- When the first and the last facts of objects happened, facts without a
+-- date count from when they were logged
+SELECT
+    ofa.obj_id,
+    MIN(COALESCE(f.happened_at, f.created_at))::timestamptz AS first_fact_at,
+    MAX(COALESCE(f.happened_at, f.created_at))::timestamptz AS last_fact_at
+FROM obj_fact ofa
+JOIN fact f ON f.id = ofa.fact_id
+WHERE ofa.obj_id = ANY($1::uuid[]) AND f.deleted_at IS NULL
+GROUP BY ofa.obj_id;
@@ -1,10 +1,10 @@
 -- name: FindObjectByAliasOrIDString :one
-SELECT obj.* FROM obj
+SELECT o.* FROM obj o
 JOIN creator c ON o.creator_id = c.id
-WHERE c.org_id = $2 AND 
-id_string = $1 OR $1 = ANY(aliases)
-AND deleted_at IS NULL
-ORDER BY (id_string = $1) DESC
+WHERE c.org_id = $2
+AND (o.id_string = $1 OR $1 = ANY(o.aliases))
+AND o.deleted_at IS NULL
+ORDER BY (o.id_string = $1) DESC
 LIMIT 1;
 
 -- name: FindTagByNormalizedName :one
@@ -54,6 +54,7 @@ raw_values AS (
     SELECT jsonb_object_keys(otv.type_values) as key_name
   ) k2 ON true
   LEFT JOIN valid_keys k ON k.key_name = k2.key_name
+  LEFT JOIN obj_type ot ON otv.type_id = ot.id
   LEFT JOIN creator c ON o.creator_id = c.id
   LEFT JOIN org org ON c.org_id = org.id
   WHERE o.deleted_at IS NULL
@@ -63,6 +64,13 @@ raw_values AS (
     AND (
       $3::uuid[] IS NULL OR o.id = ANY($3::uuid[])
     )
+    -- Restricted fields are left out unless the caller's role ($4) may read them
+    AND (
+      $4::text = 'admin'
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' ->> 'restricted', '') <> 'true'
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' -> 'readRoles', '[]'::jsonb) ? $4::text
+      OR COALESCE(ot.fields -> k2.key_name -> 'access' -> 'writeRoles', '[]'::jsonb) ? $4::text
+    )
 ),
 -- Second level: Aggregate values by key
 aggregated_values AS (
@@ -0,0 +1,84 @@
+-- name: IsOrgFact :one
+SELECT EXISTS (
+    SELECT 1 FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    WHERE f.id = $1 AND c.org_id = $2 AND f.deleted_at IS NULL
+);
+
+-- name: CreateFactComment :one
+-- A reply must answer a comment of the same fact
+INSERT INTO fact_comment (fact_id, parent_id, creator_id, text)
+SELECT $1::uuid, $2::uuid, $3::uuid, $4::text
+WHERE $2::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM fact_comment p
+    WHERE p.id = $2::uuid AND p.fact_id = $1::uuid AND p.deleted_at IS NULL
+)
+RETURNING *;
+
+-- name: GetFactComment :one
+SELECT fc.* FROM fact_comment fc
+JOIN creator c ON fc.creator_id = c.id
+WHERE fc.id = $1 AND fc.fact_id = $2 AND c.org_id = $3 AND fc.deleted_at IS NULL;
+
+-- name: UpdateFactComment :one
+-- Only the author edits a comment
+UPDATE fact_comment
+SET text = $4, last_updated = CURRENT_TIMESTAMP
+WHERE id = $1 AND fact_id = $2 AND creator_id = $3 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteFactComment :execrows
+-- Deletes a comment with the replies under it, by its author or an admin
+WITH RECURSIVE thread AS (
+    SELECT fc.id FROM fact_comment fc
+    JOIN creator c ON fc.creator_id = c.id
+    WHERE fc.id = $1 AND fc.fact_id = $2 AND c.org_id = $3
+      AND fc.deleted_at IS NULL
+      AND (fc.creator_id = $4 OR $5::bool)
+    UNION ALL
+    SELECT r.id FROM fact_comment r
+    JOIN thread t ON r.parent_id = t.id
+    WHERE r.deleted_at IS NULL
+)
+UPDATE fact_comment
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT id FROM thread);
+
+-- name: ListFactComments :many
+SELECT fc.id, fc.fact_id, fc.parent_id, fc.creator_id, fc.text, fc.created_at, fc.last_updated,
+    c.username AS creator_name
+FROM fact_comment fc
+JOIN creator c ON fc.creator_id = c.id
+WHERE fc.fact_id = ANY($1::uuid[]) AND c.org_id = $2 AND fc.deleted_at IS NULL
+ORDER BY fc.created_at, fc.id;
+
+-- name: AddFactReaction :exec
+INSERT INTO fact_reaction (fact_id, creator_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveFactReaction :execrows
+DELETE FROM fact_reaction
+WHERE fact_id = $1 AND creator_id = $2 AND emoji = $3;
+
+-- name: ListFactReactions :many
+-- Reactions grouped by emoji, in the order they were first used
+SELECT r.fact_id, r.emoji, COUNT(*) AS count,
+    array_agg(r.creator_id ORDER BY r.created_at)::uuid[] AS creator_ids
+FROM fact_reaction r
+JOIN creator c ON r.creator_id = c.id
+WHERE r.fact_id = ANY($1::uuid[]) AND c.org_id = $2
+GROUP BY r.fact_id, r.emoji
+ORDER BY r.fact_id, MIN(r.created_at);
+
+-- name: ListMentionedCreators :many
+-- Active members of the org mentioned by username, ignoring case, or by id
+SELECT id, username FROM creator
+WHERE org_id = $1 AND active AND deleted_at IS NULL
+  AND (lower(username) = ANY($2::text[]) OR id = ANY($3::uuid[]));
+
+-- name: CountUnseenMentions :one
+-- Unseen feed entries of comments mentioning the creator, also counted in
+-- CountUnseenFeed
+SELECT COUNT(*) c FROM feed
+WHERE creator_id = $1 AND seen = false AND content->>'type' = 'mention';
@@ -0,0 +1,68 @@
+-- name: RecordFactVersion :one
+-- Snapshots the current state of a fact as its next version, unless it is
+-- the same as the latest one
+WITH current AS (
+    SELECT f.id, f.text, f.happened_at, f.location, f.kind, f.metadata,
+        ARRAY(SELECT of.obj_id FROM obj_fact of WHERE of.fact_id = f.id ORDER BY of.obj_id)::uuid[] AS obj_ids
+    FROM fact f
+    WHERE f.id = $1
+),
+latest AS (
+    SELECT v.* FROM fact_version v
+    WHERE v.fact_id = $1
+    ORDER BY v.version DESC
+    LIMIT 1
+)
+INSERT INTO fact_version (fact_id, version, text, happened_at, location, obj_ids, editor_id, source, action, restored_from, kind, metadata)
+SELECT cur.id, COALESCE((SELECT version FROM latest), 0) + 1, cur.text, cur.happened_at, cur.location, cur.obj_ids,
+    $2, $3, $4, $5, cur.kind, cur.metadata
+FROM current cur
+WHERE NOT EXISTS (
+    SELECT 1 FROM latest l
+    WHERE l.text = cur.text
+      AND l.happened_at IS NOT DISTINCT FROM cur.happened_at
+      AND l.location = cur.location
+      AND l.obj_ids = cur.obj_ids
+      AND l.kind = cur.kind
+      AND l.metadata = cur.metadata
+)
+RETURNING *;
+
+-- name: ListFactVersions :many
+SELECT v.*, e.username AS editor_name
+FROM fact_version v
+JOIN fact f ON f.id = v.fact_id
+JOIN creator c ON f.creator_id = c.id
+JOIN creator e ON v.editor_id = e.id
+WHERE v.fact_id = $1 AND c.org_id = $2
+ORDER BY v.version DESC;
+
+-- name: GetFactVersion :one
+SELECT v.* FROM fact_version v
+JOIN fact f ON f.id = v.fact_id
+JOIN creator c ON f.creator_id = c.id
+WHERE v.fact_id = $1 AND v.version = $2 AND c.org_id = $3;
+
+-- name: SetFactObjects :exec
+-- Links a fact to exactly the objects of $2 that still exist
+WITH removed AS (
+    DELETE FROM obj_fact
+    WHERE fact_id = $1 AND NOT (obj_id = ANY($2::uuid[]))
+)
+INSERT INTO obj_fact (obj_id, fact_id)
+SELECT o.id, $1 FROM obj o
+WHERE o.id = ANY($2::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: LockFacts :exec
+-- Locks facts about to be snapshotted until the end of the transaction, so
+-- that concurrent changes of a fact get consecutive versions
+SELECT id FROM fact
+WHERE id = ANY($1::uuid[])
+ORDER BY id
+FOR UPDATE;
+
+-- name: ListObjectFactIDs :many
+-- The facts linked to any of the objects
+SELECT DISTINCT fact_id FROM obj_fact
+WHERE obj_id = ANY($1::uuid[]);
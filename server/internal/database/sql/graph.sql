@@ -0,0 +1,126 @@
+-- name: ListCoMentionEdges :many
+WITH facts AS (
+    -- Facts of the org linking a handful of objects, those mentioning many
+    -- at once (newsletters, imports) say little about each pair
+    SELECT f.id, f.creator_id, f.text, f.happened_at, f.created_at,
+        POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - COALESCE(f.happened_at, f.created_at))), 0) / $2::float8) AS decay,
+        COALESCE(f.happened_at, f.created_at) AS mentioned_at
+    FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    WHERE c.org_id = $1 AND f.deleted_at IS NULL
+      AND (SELECT COUNT(*) FROM obj_fact x WHERE x.fact_id = f.id) <= $3::int
+)
+SELECT o1.id AS from_id, o1.name AS from_name, o2.id AS to_id, o2.name AS to_name,
+    SUM(f.decay)::float8 AS weight,
+    COUNT(*) AS fact_count,
+    MAX(f.mentioned_at)::timestamptz AS last_mentioned_at
+FROM facts f
+JOIN obj_fact of1 ON of1.fact_id = f.id
+JOIN obj_fact of2 ON of2.fact_id = f.id AND of1.obj_id < of2.obj_id
+JOIN obj o1 ON o1.id = of1.obj_id AND o1.deleted_at IS NULL
+JOIN obj o2 ON o2.id = of2.obj_id AND o2.deleted_at IS NULL
+GROUP BY o1.id, o1.name, o2.id, o2.name
+HAVING SUM(f.decay) >= $4::float8
+ORDER BY weight DESC
+LIMIT $5;
+
+-- name: ListObjectConnections :many
+WITH facts AS (
+    -- Facts of the org linking a handful of objects, those mentioning many
+    -- at once (newsletters, imports) say little about each pair
+    SELECT f.id, f.creator_id, f.text, f.happened_at, f.created_at,
+        POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - COALESCE(f.happened_at, f.created_at))), 0) / $2::float8) AS decay,
+        COALESCE(f.happened_at, f.created_at) AS mentioned_at
+    FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    WHERE c.org_id = $1 AND f.deleted_at IS NULL
+      AND (SELECT COUNT(*) FROM obj_fact x WHERE x.fact_id = f.id) <= $3::int
+)
+SELECT o.id, o.name, o.photo, o.id_string,
+    SUM(f.decay)::float8 AS weight,
+    COUNT(*) AS fact_count,
+    MAX(f.mentioned_at)::timestamptz AS last_mentioned_at
+FROM facts f
+JOIN obj_fact root ON root.fact_id = f.id AND root.obj_id = $4
+JOIN obj_fact of ON of.fact_id = f.id AND of.obj_id <> $4
+JOIN obj o ON o.id = of.obj_id AND o.deleted_at IS NULL
+GROUP BY o.id, o.name, o.photo, o.id_string
+ORDER BY weight DESC
+LIMIT $5;
+
+-- name: ListSharedFacts :many
+-- The latest $4 facts mentioning the object together with each of the
+-- others, newest first
+SELECT other_id, fact_id, text, happened_at, created_at, creator_id
+FROM (
+    SELECT other.obj_id AS other_id, f.id AS fact_id, f.text, f.happened_at, f.created_at, f.creator_id,
+        ROW_NUMBER() OVER (
+            PARTITION BY other.obj_id
+            ORDER BY COALESCE(f.happened_at, f.created_at) DESC, f.id
+        ) AS rank
+    FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    JOIN obj_fact root ON root.fact_id = f.id AND root.obj_id = $1
+    JOIN obj_fact other ON other.fact_id = f.id AND other.obj_id = ANY($2::uuid[])
+    WHERE c.org_id = $3 AND f.deleted_at IS NULL
+) shared
+WHERE rank <= $4::int
+ORDER BY COALESCE(happened_at, created_at) DESC;
+
+-- name: GetGraphNode :one
+-- An object or an active team member of the org
+SELECT o.id, 'object'::text AS kind, o.name::text AS name
+FROM obj o
+JOIN creator c ON o.creator_id = c.id
+WHERE o.id = $1 AND c.org_id = $2 AND o.deleted_at IS NULL
+UNION ALL
+SELECT c.id, 'creator'::text, c.username::text
+FROM creator c
+WHERE c.id = $1 AND c.org_id = $2 AND c.active AND c.deleted_at IS NULL;
+
+-- name: ListGraphNeighbors :many
+-- The links of the nodes $4 (objects and team members) to their neighbors,
+-- weighted like ListCoMentionEdges and ListCreatorMentionEdges, for path
+-- finding one level at a time
+WITH facts AS (
+    SELECT f.id, f.creator_id,
+        POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - COALESCE(f.happened_at, f.created_at))), 0) / $2::float8) AS decay,
+        COALESCE(f.happened_at, f.created_at) AS mentioned_at
+    FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    WHERE c.org_id = $1 AND f.deleted_at IS NULL
+      AND (f.creator_id = ANY($4::uuid[]) OR EXISTS (
+        SELECT 1 FROM obj_fact x WHERE x.fact_id = f.id AND x.obj_id = ANY($4::uuid[])
+      ))
+      AND (SELECT COUNT(*) FROM obj_fact x WHERE x.fact_id = f.id) <= $3::int
+),
+links AS (
+    -- Objects mentioned together
+    SELECT of1.obj_id AS from_id, of2.obj_id AS to_id, f.decay, f.mentioned_at
+    FROM facts f
+    JOIN obj_fact of1 ON of1.fact_id = f.id AND of1.obj_id = ANY($4::uuid[])
+    JOIN obj_fact of2 ON of2.fact_id = f.id AND of2.obj_id <> of1.obj_id
+    UNION ALL
+    -- Team members know the objects they wrote facts about
+    SELECT f.creator_id, of.obj_id, f.decay, f.mentioned_at
+    FROM facts f
+    JOIN obj_fact of ON of.fact_id = f.id
+    WHERE f.creator_id = ANY($4::uuid[])
+    UNION ALL
+    SELECT of.obj_id, f.creator_id, f.decay, f.mentioned_at
+    FROM facts f
+    JOIN obj_fact of ON of.fact_id = f.id AND of.obj_id = ANY($4::uuid[])
+)
+SELECT l.from_id, l.to_id,
+    (CASE WHEN o.id IS NULL THEN 'creator' ELSE 'object' END)::text AS to_kind,
+    COALESCE(o.name, c.username)::text AS to_name,
+    SUM(l.decay)::float8 AS weight,
+    COUNT(*) AS fact_count,
+    MAX(l.mentioned_at)::timestamptz AS last_mentioned_at
+FROM links l
+LEFT JOIN obj o ON o.id = l.to_id AND o.deleted_at IS NULL
+LEFT JOIN creator c ON c.id = l.to_id AND c.active AND c.deleted_at IS NULL
+WHERE o.id IS NOT NULL OR c.id IS NOT NULL
+GROUP BY l.from_id, l.to_id, o.id, o.name, c.username
+ORDER BY weight DESC
+LIMIT $5;
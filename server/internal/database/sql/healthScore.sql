@@ -0,0 +1,94 @@
+-- name: GetHealthScoreSetting :one
+SELECT * FROM health_score_setting
+WHERE org_id = $1;
+
+-- name: UpsertHealthScoreSetting :one
+INSERT INTO health_score_setting (org_id, weights, updated_by, updated_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+ON CONFLICT (org_id) DO UPDATE
+SET weights = EXCLUDED.weights,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: ListHealthInputs :many
+-- What the health score of each active object of the org is made of, for the
+-- objects of $2 only when given. $3 is the window of recent facts in days.
+SELECT o.id AS obj_id,
+    facts.last_fact_at,
+    COALESCE(facts.recent_facts, 0)::bigint AS recent_facts,
+    COALESCE(tasks.total_tasks, 0)::bigint AS total_tasks,
+    COALESCE(tasks.open_tasks, 0)::bigint AS open_tasks,
+    COALESCE(tasks.overdue_tasks, 0)::bigint AS overdue_tasks,
+    COALESCE(steps.step_count, 0)::bigint AS step_count,
+    COALESCE(steps.progression, 0)::float8 AS progression,
+    COALESCE(steps.sub_status, 0)::float8 AS sub_status
+FROM obj o
+JOIN creator c ON o.creator_id = c.id
+LEFT JOIN LATERAL (
+    SELECT MAX(COALESCE(f.happened_at, f.created_at))::timestamptz AS last_fact_at,
+        COUNT(*) FILTER (WHERE COALESCE(f.happened_at, f.created_at) >= CURRENT_TIMESTAMP - make_interval(days => $3::int)) AS recent_facts
+    FROM obj_fact of
+    JOIN fact f ON f.id = of.fact_id AND f.deleted_at IS NULL
+    WHERE of.obj_id = o.id
+) facts ON true
+LEFT JOIN LATERAL (
+    SELECT COUNT(*) AS total_tasks,
+        COUNT(*) FILTER (WHERE t.status <> 'completed') AS open_tasks,
+        COUNT(*) FILTER (WHERE t.status <> 'completed' AND t.deadline < CURRENT_TIMESTAMP) AS overdue_tasks
+    FROM obj_task ot
+    JOIN task t ON t.id = ot.task_id AND t.deleted_at IS NULL
+    WHERE ot.obj_id = o.id
+) tasks ON true
+LEFT JOIN LATERAL (
+    -- Progression is how far along its funnel each step is, the last step
+    -- counting as 1. Sub-statuses score proceeding 1, to engage 0.5 and
+    -- drop out 0.
+    SELECT COUNT(*) AS step_count,
+        AVG(
+            (SELECT COUNT(*) FROM step s2
+             WHERE s2.funnel_id = s.funnel_id AND s2.deleted_at IS NULL AND s2.step_order <= s.step_order)::float8
+            / GREATEST((SELECT COUNT(*) FROM step s2
+             WHERE s2.funnel_id = s.funnel_id AND s2.deleted_at IS NULL), 1)
+        ) AS progression,
+        AVG(CASE os.sub_status WHEN 1 THEN 1.0 WHEN 0 THEN 0.5 ELSE 0.0 END) AS sub_status
+    FROM obj_step os
+    JOIN step s ON s.id = os.step_id AND s.deleted_at IS NULL
+    JOIN funnel fu ON fu.id = s.funnel_id AND fu.deleted_at IS NULL
+    WHERE os.obj_id = o.id AND os.deleted_at IS NULL
+) steps ON true
+WHERE c.org_id = $1 AND o.deleted_at IS NULL
+  AND ($2::uuid[] IS NULL OR o.id = ANY($2::uuid[]));
+
+-- name: UpsertObjectHealthBatch :exec
+-- Stores the current scores and the scores of the day of the objects of $1,
+-- an array of {obj_id, score, components}. The last one computed in a day
+-- wins.
+WITH scores AS (
+    SELECT s.obj_id, s.score, s.components
+    FROM jsonb_to_recordset($1::jsonb) AS s(obj_id uuid, score real, components jsonb)
+),
+current AS (
+    INSERT INTO obj_health (obj_id, score, components, computed_at)
+    SELECT obj_id, score, components, CURRENT_TIMESTAMP FROM scores
+    ON CONFLICT (obj_id) DO UPDATE
+    SET score = EXCLUDED.score,
+        components = EXCLUDED.components,
+        computed_at = EXCLUDED.computed_at
+    RETURNING obj_id, score
+)
+INSERT INTO obj_health_history (obj_id, scored_on, score)
+SELECT obj_id, CURRENT_DATE, score FROM current
+ON CONFLICT (obj_id, scored_on) DO UPDATE
+SET score = EXCLUDED.score;
+
+-- name: GetObjectHealth :one
+SELECT oh.* FROM obj_health oh
+JOIN obj o ON o.id = oh.obj_id
+JOIN creator c ON o.creator_id = c.id
+WHERE oh.obj_id = $1 AND c.org_id = $2;
+
+-- name: ListObjectHealthHistory :many
+SELECT * FROM obj_health_history
+WHERE obj_id = $1 AND scored_on >= $2::date
+ORDER BY scored_on;
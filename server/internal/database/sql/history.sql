@@ -0,0 +1,28 @@
+-- name: CreateObjectHistory :exec
+INSERT INTO obj_history (obj_id, creator_id, source, action, type_id, changes, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListObjectHistory :many
+SELECT h.id, h.source, h.action, h.type_id, h.changes, h.details, h.created_at,
+    h.creator_id, ac.username AS creator_name,
+    COUNT(*) OVER() AS total_count
+FROM obj_history h
+JOIN obj o ON o.id = h.obj_id
+JOIN creator c ON o.creator_id = c.id
+JOIN creator ac ON ac.id = h.creator_id
+WHERE h.obj_id = $1 AND c.org_id = $2
+ORDER BY h.created_at DESC, h.id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetObjectByID :one
+SELECT * FROM obj
+WHERE id = $1;
+
+-- name: ListObjectStepsInFunnel :many
+SELECT os.step_id, s.name AS step_name
+FROM obj_step os
+JOIN step s ON s.id = os.step_id
+WHERE os.obj_id = $1
+  AND os.deleted_at IS NULL
+  AND s.funnel_id = (SELECT funnel_id FROM step WHERE id = $2)
+ORDER BY os.created_at;
@@ -20,6 +20,24 @@ daily_fact_metrics AS (
         AND f.deleted_at IS NULL
     GROUP BY date_trunc('day', f.created_at)
 ),
+daily_fact_kind_metrics AS (
+    -- Facts created per day by kind, as {"call": 2, "meeting": 1}
+    SELECT 
+        day,
+        jsonb_object_agg(kind, fact_count) as facts_by_kind
+    FROM (
+        SELECT 
+            date_trunc('day', f.created_at) as day,
+            f.kind,
+            COUNT(f.id) as fact_count
+        FROM fact f
+        WHERE f.creator_id = $1
+            AND f.created_at > NOW() - INTERVAL '30 days'
+            AND f.deleted_at IS NULL
+        GROUP BY date_trunc('day', f.created_at), f.kind
+    ) kinds
+    GROUP BY day
+),
 daily_task_metrics AS (
     -- Tasks activity per day
     SELECT 
@@ -124,6 +142,7 @@ SELECT
     -- Facts metrics
     COALESCE(dfm.fact_count, 0) as facts_created,
     COALESCE(dfm.fact_objects_count, 0) as fact_objects_involved,
+    COALESCE(dfkm.facts_by_kind, '{}'::jsonb) as facts_by_kind,
     -- Task metrics
     COALESCE(dtm.task_count, 0) as tasks_total,
     COALESCE(dtm.completed_tasks, 0) as tasks_completed,
@@ -166,6 +185,7 @@ SELECT
   ) AS double precision) as daily_activity_score
 FROM dates d
 LEFT JOIN daily_fact_metrics dfm ON d.day = dfm.day
+LEFT JOIN daily_fact_kind_metrics dfkm ON d.day = dfkm.day
 LEFT JOIN daily_task_metrics dtm ON d.day = dtm.day
 LEFT JOIN daily_object_metrics dom ON d.day = dom.day
 LEFT JOIN daily_funnel_metrics dfnm ON d.day = dfnm.day
@@ -6,9 +6,9 @@ LIMIT 1;
 
 -- name: CreateImportTask :one
 INSERT INTO import_task (
-    org_id, creator_id, obj_type_id, status, total_rows, file_name
+    org_id, creator_id, obj_type_id, status, total_rows, file_name, mode
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
@@ -68,6 +68,7 @@ ON CONFLICT (obj_id, type_id)
 DO UPDATE SET 
     type_values = EXCLUDED.type_values,
     last_updated = CURRENT_TIMESTAMP
+WHERE ($4::int = 0 OR obj_type_value.version = $4)
 RETURNING *;
 
 -- name: GetObjectTypeValue :one
@@ -15,8 +15,10 @@ WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteList :exec
-DELETE FROM list
+UPDATE list
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE list.id = $1
+AND deleted_at IS NULL
 AND NOT EXISTS (
   SELECT 1
   FROM creator_list
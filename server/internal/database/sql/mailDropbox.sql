@@ -0,0 +1,78 @@
+-- name: GetMailDropbox :one
+-- The drop-box of a member
+SELECT * FROM mail_dropbox
+WHERE org_id = $1 AND creator_id = $2;
+
+-- name: GetMailDropboxByToken :one
+-- The drop-box of a token, while its member is active
+SELECT d.* FROM mail_dropbox d
+JOIN creator c ON c.id = d.creator_id
+WHERE d.token = $1 AND c.active AND c.deleted_at IS NULL;
+
+-- name: ListMailDropboxTokens :many
+-- Tokens of the drop-boxes of the members of the org
+SELECT token FROM mail_dropbox
+WHERE org_id = $1
+ORDER BY token;
+
+-- name: EnsureMailDropbox :one
+-- Creates the drop-box of the member unless they have one, returning the one
+-- kept
+INSERT INTO mail_dropbox (org_id, token, creator_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (creator_id) DO UPDATE SET creator_id = mail_dropbox.creator_id
+RETURNING *;
+
+-- name: UpsertMailDropbox :one
+-- Sets the token of the member, their previous address stops working
+INSERT INTO mail_dropbox (org_id, token, creator_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (creator_id) DO UPDATE
+SET token = EXCLUDED.token, created_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: FindCreatorByEmail :one
+-- Active member of the org whose profile has the email, ignoring case
+SELECT id FROM creator
+WHERE org_id = $1 AND active AND deleted_at IS NULL
+  AND lower(profile->>'email') = lower($2::text)
+ORDER BY created_at
+LIMIT 1;
+
+-- name: ListObjectsByEmail :many
+-- Objects of the org with one of the emails, lower case, as an alias or as
+-- the value of an email field of their type values
+SELECT DISTINCT o.id, o.name, e.email::text AS email
+FROM obj o
+JOIN creator c ON o.creator_id = c.id
+CROSS JOIN unnest($2::text[]) AS e(email)
+WHERE c.org_id = $1 AND o.deleted_at IS NULL
+  AND (
+    EXISTS (SELECT 1 FROM unnest(o.aliases) a WHERE lower(a) = e.email)
+    OR EXISTS (
+        SELECT 1
+        FROM obj_type_value otv
+        JOIN obj_type ot ON ot.id = otv.type_id
+        CROSS JOIN LATERAL jsonb_each(ot.fields) AS f(name, def)
+        WHERE otv.obj_id = o.id
+          AND (f.def->>'type' = 'email' OR f.def #>> '{}' = 'email')
+          AND (
+            lower(otv.type_values->>f.name) = e.email
+            OR (jsonb_typeof(otv.type_values->f.name) = 'array' AND EXISTS (
+                SELECT 1 FROM jsonb_array_elements_text(otv.type_values->f.name) v
+                WHERE lower(v) = e.email
+            ))
+          )
+    )
+  )
+ORDER BY e.email::text, o.name;
+
+-- name: GetFactSource :one
+SELECT fact_id FROM fact_source
+WHERE org_id = $1 AND source = $2 AND external_id = $3;
+
+-- name: CreateFactSource :execrows
+-- Nothing is inserted when the message was already logged
+INSERT INTO fact_source (org_id, source, external_id, fact_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
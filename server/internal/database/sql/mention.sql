@@ -0,0 +1,54 @@
+-- name: ListObjectsByAliasOrIDString :many
+-- Objects a plain @mention may refer to, like FindObjectByAliasOrIDString
+-- but ignoring case and returning every candidate, exact id_string first
+SELECT o.id, o.name, o.id_string FROM obj o
+JOIN creator c ON o.creator_id = c.id
+WHERE c.org_id = $2 AND o.deleted_at IS NULL
+  AND (
+    lower(o.id_string) = lower($1::text)
+    OR EXISTS (SELECT 1 FROM unnest(o.aliases) a WHERE lower(a) = lower($1::text))
+  )
+ORDER BY (o.id_string = $1::text) DESC, (lower(o.id_string) = lower($1::text)) DESC, o.name
+LIMIT $3;
+
+-- name: SetFuzzyThreshold :exec
+-- How close an object must be to a search to be a candidate of
+-- obj_fuzzy_matches, for the rest of the transaction
+SELECT set_config('pg_trgm.similarity_threshold', $1::float8::text, true),
+       set_config('pg_trgm.word_similarity_threshold', $1::float8::text, true);
+
+-- name: SuggestObjects :many
+-- Objects a mention being typed may refer to, for autocomplete: the ones
+-- whose id_string or an alias starts with it first, then the ones close
+-- enough to it by trigram similarity as the role sees them, see
+-- obj_fuzzy_matches
+WITH p AS (
+    SELECT lower($2::text) AS prefix,
+           replace(replace(replace(lower($2::text), '\', '\\'), '%', '\%'), '_', '\_') AS pattern
+),
+prefixed AS (
+    SELECT o.id FROM p, obj o
+    WHERE lower(o.id_string) LIKE p.pattern || '%'
+    UNION
+    SELECT o.id FROM p, obj o
+    WHERE obj_aliases_text(o.aliases) LIKE '%' || p.pattern || '%'
+      AND EXISTS (SELECT 1 FROM unnest(o.aliases) a WHERE starts_with(lower(a), p.prefix))
+),
+fuzzy AS (
+    SELECT fm.obj_id, fm.similarity FROM obj_fuzzy_matches($1, $2::text, $5::text) fm
+)
+SELECT o.id, o.name, o.id_string, o.photo, s.similarity
+FROM (SELECT id FROM prefixed UNION SELECT obj_id FROM fuzzy) m
+JOIN obj o ON o.id = m.id
+JOIN creator c ON o.creator_id = c.id
+LEFT JOIN fuzzy f ON f.obj_id = o.id
+CROSS JOIN LATERAL (
+    SELECT coalesce(f.similarity, obj_fuzzy_score($2::text, o.name, o.id_string, o.aliases, obj_contact_text(o.id, $5::text))) AS similarity
+) s
+CROSS JOIN LATERAL (
+    SELECT EXISTS (SELECT 1 FROM prefixed pr WHERE pr.id = o.id) AS prefix
+) px
+WHERE c.org_id = $1 AND o.deleted_at IS NULL
+  AND (px.prefix OR s.similarity >= $3::float8)
+ORDER BY px.prefix DESC, s.similarity DESC, o.name
+LIMIT $4;
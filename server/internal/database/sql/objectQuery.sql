@@ -0,0 +1,25 @@
+-- name: ListTagsByNames :many
+-- Tags of the org named, ignoring case, by any of the lower-cased names
+SELECT t.id, t.name
+FROM tag t
+WHERE t.org_id = $1 AND t.deleted_at IS NULL
+  AND lower(t.name) = ANY($2::text[]);
+
+-- name: ListStepsByNames :many
+-- Steps of the org's funnels named, ignoring case, by any of the lower-cased
+-- names. Funnels may share step names, all of them are returned.
+SELECT s.id, s.name
+FROM step s
+JOIN funnel f ON s.funnel_id = f.id
+JOIN creator c ON f.creator_id = c.id
+WHERE c.org_id = $1 AND s.deleted_at IS NULL AND f.deleted_at IS NULL
+  AND lower(s.name) = ANY($2::text[]);
+
+-- name: ListObjectTypesByNames :many
+-- Object types of the org named, ignoring case, by any of the lower-cased
+-- names
+SELECT ot.id, ot.name
+FROM obj_type ot
+JOIN creator c ON ot.creator_id = c.id
+WHERE c.org_id = $1 AND ot.deleted_at IS NULL
+  AND lower(ot.name) = ANY($2::text[]);
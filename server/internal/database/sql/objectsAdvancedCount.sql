@@ -2,6 +2,7 @@
 WITH object_data AS (
     SELECT 
         o.id,
+        o.created_at,
         -- Enhanced search vector including tag names (matching ListObjectsAdvanced)
         to_tsvector('english', 
             o.name || ' ' || 
@@ -11,11 +12,7 @@ WITH object_data AS (
             COALESCE(string_agg(DISTINCT t.name, ' '), '') || ' '  -- Add tag names to search
         ) AS obj_search,
         to_tsvector('english', string_agg(DISTINCT COALESCE(f.text, ''), ' ')) AS fact_search,
-        (
-            SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
-            FROM obj_type_value otv 
-            WHERE otv.obj_id = o.id
-        ) AS type_value_search,
+        obj_type_value_search_for(o.id, $16::text) AS type_value_search,
         -- Get all steps for filtering with their sub_status
         array_agg(DISTINCT os.step_id) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_ids,
         -- Store steps and their sub_status for filtering
@@ -55,9 +52,23 @@ filtered_objects AS (
         )) AND
         -- Filter by tags
         ($4::uuid[] IS NULL OR od.tag_ids && $4) AND
+        -- Filter by fact kinds if array is provided
+        ($10::text[] IS NULL OR EXISTS (
+            SELECT 1
+            FROM obj_fact xf
+            JOIN fact fk ON fk.id = xf.fact_id
+            WHERE xf.obj_id = od.id AND fk.deleted_at IS NULL AND fk.kind = ANY($10::text[])
+        )) AND
         -- Filter by object types
         ($5::uuid[] IS NULL OR od.type_ids && $5) AND
-        -- Filter by type value criteria 1 with LIKE
+        -- Filter out objects with any of the excluded steps, tags or types
+        ($11::uuid[] IS NULL OR NOT (COALESCE(od.step_ids, '{}') && $11)) AND
+        ($12::uuid[] IS NULL OR NOT (COALESCE(od.tag_ids, '{}') && $12)) AND
+        ($13::uuid[] IS NULL OR NOT (COALESCE(od.type_ids, '{}') && $13)) AND
+        -- Filter by creation date, from inclusive and before exclusive
+        ($14::timestamptz IS NULL OR od.created_at >= $14) AND
+        ($15::timestamptz IS NULL OR od.created_at < $15) AND
+        -- Filter by type value criteria 1
         ((COALESCE($6::jsonb, 'null'::jsonb) = 'null'::jsonb) OR
          EXISTS (
              SELECT 1 
@@ -68,14 +79,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($6) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($6) criteria
+                            FROM jsonb_each($6) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 2 with LIKE
+        -- Filter by type value criteria 2
         ((COALESCE($7::jsonb, 'null'::jsonb) = 'null'::jsonb) OR
          EXISTS (
              SELECT 1 
@@ -86,14 +97,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($7) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($7) criteria
+                            FROM jsonb_each($7) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 3 with LIKE
+        -- Filter by type value criteria 3
         ((COALESCE($8::jsonb, 'null'::jsonb) = 'null'::jsonb) OR
          EXISTS (
              SELECT 1 
@@ -104,9 +115,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($8) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($8) criteria
+                            FROM jsonb_each($8) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -115,7 +126,12 @@ filtered_objects AS (
         ($2 = '' OR
          obj_search @@ websearch_to_tsquery('english', $2) OR
          fact_search @@ websearch_to_tsquery('english', $2) OR
-         type_value_search @@ websearch_to_tsquery('english', $2))
+         type_value_search @@ websearch_to_tsquery('english', $2)) AND
+        -- Words the query excludes, from every text at once
+        ($17::text = '' OR NOT COALESCE(
+         obj_search @@ websearch_to_tsquery('english', $17) OR
+         fact_search @@ websearch_to_tsquery('english', $17) OR
+         type_value_search @@ websearch_to_tsquery('english', $17), false))
 ),
 step_counts AS (
     -- Get counts for each step
@@ -25,11 +25,7 @@ WITH object_data AS (
         ) AS obj_search,
         to_tsvector('english', string_agg(DISTINCT COALESCE(f.text, ''), ' ')) AS fact_search,
         -- Add type_value_search using search_vector
-        (
-            SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
-            FROM obj_type_value otv 
-            WHERE otv.obj_id = o.id
-        ) AS type_value_search,
+        obj_type_value_search_for(o.id, $21::text) AS type_value_search,
         -- Get all steps for filtering
         array_agg(DISTINCT os.step_id) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_ids,
         -- Store steps and their sub_status for filtering
@@ -84,9 +80,23 @@ filtered_objects AS (
         )) AND
         -- Filter by tags if array is provided
         ($4::uuid[] IS NULL OR od.tag_ids && $4) AND
+        -- Filter by fact kinds if array is provided
+        ($15::text[] IS NULL OR EXISTS (
+            SELECT 1
+            FROM obj_fact xf
+            JOIN fact fk ON fk.id = xf.fact_id
+            WHERE xf.obj_id = od.id AND fk.deleted_at IS NULL AND fk.kind = ANY($15::text[])
+        )) AND
         -- Filter by object types if array is provided
         ($5::uuid[] IS NULL OR od.type_ids && $5) AND
-        -- Filter by type value criteria 1 with LIKE
+        -- Filter out objects with any of the excluded steps, tags or types
+        ($16::uuid[] IS NULL OR NOT (COALESCE(od.step_ids, '{}') && $16)) AND
+        ($17::uuid[] IS NULL OR NOT (COALESCE(od.tag_ids, '{}') && $17)) AND
+        ($18::uuid[] IS NULL OR NOT (COALESCE(od.type_ids, '{}') && $18)) AND
+        -- Filter by creation date, from inclusive and before exclusive
+        ($19::timestamptz IS NULL OR od.created_at >= $19) AND
+        ($20::timestamptz IS NULL OR od.created_at < $20) AND
+        -- Filter by type value criteria 1
         ((COALESCE($6::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -97,14 +107,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($6) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($6) criteria
+                            FROM jsonb_each($6) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 2 with LIKE
+        -- Filter by type value criteria 2
         ((COALESCE($7::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -115,14 +125,14 @@ filtered_objects AS (
                     WHEN jsonb_typeof($7) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($7) criteria
+                            FROM jsonb_each($7) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
          )) AND
-        -- Filter by type value criteria 3 with LIKE
+        -- Filter by type value criteria 3
         ((COALESCE($8::jsonb, 'null'::jsonb) = 'null'::jsonb) OR 
          EXISTS (
              SELECT 1 
@@ -133,9 +143,9 @@ filtered_objects AS (
                     WHEN jsonb_typeof($8) = 'object' THEN
                         EXISTS (
                             SELECT 1
-                            FROM jsonb_each_text($8) criteria
+                            FROM jsonb_each($8) criteria
                             WHERE fields.key = criteria.key 
-                            AND fields.value ILIKE '%' || criteria.value || '%'
+                            AND type_value_matches(fields.value, criteria.value)
                         )
                     ELSE false
                 END
@@ -144,7 +154,12 @@ filtered_objects AS (
         ($2 = '' OR
          obj_search @@ websearch_to_tsquery('english', $2) OR
          fact_search @@ websearch_to_tsquery('english', $2) OR
-         type_value_search @@ websearch_to_tsquery('english', $2))
+         type_value_search @@ websearch_to_tsquery('english', $2)) AND
+        -- Words the query excludes, from every text at once
+        ($22::text = '' OR NOT COALESCE(
+         obj_search @@ websearch_to_tsquery('english', $22) OR
+         fact_search @@ websearch_to_tsquery('english', $22) OR
+         type_value_search @@ websearch_to_tsquery('english', $22), false))
 )
 SELECT 
     fo.id, 
@@ -193,8 +208,10 @@ SELECT
         JOIN funnel f ON s.funnel_id = f.id
         WHERE os.id = ANY(fo.obj_step_ids) AND os.deleted_at IS NULL),
         '[]'
-    ) AS steps
+    ) AS steps,
+    oh.score AS health_score
 FROM filtered_objects fo
+LEFT JOIN obj_health oh ON oh.obj_id = fo.id
 ORDER BY
     -- First by search rank if searching
     CASE WHEN $2 = '' THEN 0 ELSE (fo.obj_rank + fo.fact_rank + fo.type_value_rank) END DESC,
@@ -205,6 +222,13 @@ ORDER BY
     CASE WHEN $9 = 'fact_count' THEN 
         CASE WHEN NOT COALESCE($10, false) THEN fo.fact_count END
     END DESC NULLS LAST,
+    -- Handle health score, objects never scored last
+    CASE WHEN $9 = 'health_score' THEN
+        CASE WHEN COALESCE($10, false) THEN oh.score END
+    END ASC NULLS LAST,
+    CASE WHEN $9 = 'health_score' THEN
+        CASE WHEN NOT COALESCE($10, false) THEN oh.score END
+    END DESC NULLS LAST,
     -- Handle timestamp types
     CASE WHEN $9 IN ('created_at', 'first_fact_date', 'last_fact_date') THEN 
         CASE WHEN COALESCE($10, false) THEN
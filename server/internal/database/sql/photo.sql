@@ -0,0 +1,42 @@
+-- name: CreateObjPhoto :one
+INSERT INTO obj_photo (id, obj_id, creator_id, content_type, size, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetObjPhoto :one
+-- Only the photo an object of the org shows now, a replaced or removed photo
+-- is gone at once
+SELECT p.* FROM obj_photo p
+JOIN obj o ON o.id = p.obj_id
+JOIN creator c ON o.creator_id = c.id
+WHERE p.id = $1 AND c.org_id = $2
+  AND o.photo = '/photos/' || p.id::text;
+
+-- name: DeleteObjPhoto :exec
+DELETE FROM obj_photo
+WHERE id = $1;
+
+-- name: SetObjectPhoto :one
+-- Returns the photo the object had before
+WITH old AS (
+    SELECT o.id, o.photo
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    WHERE o.id = $1 AND c.org_id = $2 AND o.deleted_at IS NULL
+    FOR UPDATE OF o
+)
+UPDATE obj
+SET photo = $3
+FROM old
+WHERE obj.id = old.id
+RETURNING old.photo AS old_photo;
+
+-- name: ListOrphanedObjPhotos :many
+-- Photos no object shows any more. Recent ones are skipped, their upload may
+-- still be in progress.
+SELECT p.* FROM obj_photo p
+LEFT JOIN obj o ON o.id = p.obj_id
+WHERE p.created_at < $1
+  AND (o.id IS NULL OR o.photo <> '/photos/' || p.id::text)
+ORDER BY p.created_at
+LIMIT $2;
@@ -113,7 +113,7 @@ RETURNING *;
 
 -- name: DeleteFunnel :exec
 UPDATE funnel
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE funnel.id = $1 AND deleted_at IS NULL
   AND NOT EXISTS (
     SELECT 1 FROM obj_step os
@@ -188,15 +188,27 @@ RETURNING *;
 UPDATE obj
 SET name = $2, description = $3, id_string = $4, aliases = $5
 WHERE id = $1
+  AND ($6::int = 0 OR version = $6)
 RETURNING *;
 
 -- name: DeleteObject :exec
-UPDATE obj SET deleted_at = NOW() WHERE id = $1;
+-- Funnel steps are detached with the same deleted_at, restoring the object
+-- re-links them
+WITH deleted AS (
+    UPDATE obj
+    SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING id, deleted_at
+)
+UPDATE obj_step os
+SET deleted_at = d.deleted_at
+FROM deleted d
+WHERE os.obj_id = d.id AND os.deleted_at IS NULL;
 
 -- name: GetObjectDetails :one
 WITH object_data AS (
     SELECT o.id, o.name, o.photo, o.description, o.id_string, o.creator_id, o.created_at,
-           c.org_id, o.aliases,
+           c.org_id, o.aliases, o.version,
            coalesce(
             json_agg(DISTINCT jsonb_build_object('id', t.id, 'name', t.name, 'color_schema', t.color_schema)) FILTER (WHERE t.id IS NOT NULL), '[]') 
            AS tags,
@@ -206,7 +218,8 @@ WITH object_data AS (
                'objectTypeName', ot.name,
                'objectTypeIcon', ot.icon,
                'objectTypeFields', ot.fields,
-               'type_values', otv.type_values
+               'type_values', otv.type_values,
+               'version', otv.version
            )) FILTER (WHERE otv.id IS NOT NULL), '[]')
            AS type_values,
            coalesce(json_agg(DISTINCT jsonb_build_object(
@@ -252,21 +265,21 @@ WITH object_data AS (
     LEFT JOIN obj_fact of ON o.id = of.obj_id
     LEFT JOIN fact ON of.fact_id = fact.id
     WHERE o.id = $1 AND c.org_id = $2
-    GROUP BY o.id, o.name, o.description, o.id_string, o.creator_id, o.created_at, c.org_id, o.aliases
+    GROUP BY o.id, o.name, o.description, o.id_string, o.creator_id, o.created_at, c.org_id, o.aliases, o.version
 )
 SELECT *
 FROM object_data;
 
--- name: AddTagToObject :exec
+-- name: AddTagToObject :execrows
 INSERT INTO obj_tag (obj_id, tag_id)
 SELECT $1, $2
 FROM obj o
 JOIN creator c ON o.creator_id = c.id
 JOIN tag t ON t.org_id = c.org_id
-WHERE o.id = $1 AND t.id = $2 AND c.org_id = $3
+WHERE o.id = $1 AND t.id = $2 AND c.org_id = $3 AND t.deleted_at IS NULL
 ON CONFLICT DO NOTHING;
 
--- name: RemoveTagFromObject :exec
+-- name: RemoveTagFromObject :execrows
 DELETE FROM obj_tag
 WHERE obj_id = $1 AND tag_id = $2
 AND EXISTS (
@@ -316,8 +329,15 @@ WHERE obj_type_value.id = $1
     JOIN creator c ON o.creator_id = c.id
     WHERE o.id = obj_type_value.obj_id AND c.org_id = $2
   )
+  AND ($4::int = 0 OR obj_type_value.version = $4)
 RETURNING *;
 
+-- name: GetObjectTypeValueByID :one
+SELECT otv.* FROM obj_type_value otv
+JOIN obj o ON o.id = otv.obj_id
+JOIN creator c ON o.creator_id = c.id
+WHERE otv.id = $1 AND c.org_id = $2;
+
 -- name: CreateObjStep :one
 WITH existing_step AS (
   -- First check if the step already exists
@@ -392,7 +412,7 @@ RETURNING *;
 
 -- name: DeleteTask :exec
 UPDATE task
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: ListTasksByOrgID :many
@@ -648,19 +668,19 @@ AND status in ('todo', 'doing');
 -- Add these new queries to your existing queries.sql file
 
 -- name: CreateFact :one
-INSERT INTO fact (text, happened_at, location, creator_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO fact (text, happened_at, location, creator_id, kind, metadata)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: UpdateFact :one
 UPDATE fact
-SET text = $2, happened_at = $3, location = $4
+SET text = $2, happened_at = $3, location = $4, kind = $5, metadata = $6
 WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteFact :exec
 UPDATE fact
-SET deleted_at = CURRENT_TIMESTAMP
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetFactByID :one
@@ -678,6 +698,8 @@ SELECT
     f.creator_id,
     c.username AS creator_name,
     f.created_at,
+    f.kind,
+    f.metadata,
     COALESCE(
         json_agg(
             json_build_object(
@@ -699,7 +721,11 @@ LEFT JOIN
 WHERE 
     c.org_id = $1 
     AND f.deleted_at IS NULL
-    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%')
+    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%' OR EXISTS (
+        SELECT 1 FROM attachment a
+        WHERE a.fact_id = f.id AND a.text_content ILIKE '%' || $2 || '%'
+    ))
+    AND ($5::text[] IS NULL OR f.kind = ANY($5::text[]))
 GROUP BY 
     f.id, c.username
 ORDER BY 
@@ -715,7 +741,11 @@ JOIN
 WHERE 
     c.org_id = $1 
     AND f.deleted_at IS NULL
-    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%');
+    AND ($2::text = '' OR f.text ILIKE '%' || $2 || '%' OR EXISTS (
+        SELECT 1 FROM attachment a
+        WHERE a.fact_id = f.id AND a.text_content ILIKE '%' || $2 || '%'
+    ))
+    AND ($3::text[] IS NULL OR f.kind = ANY($3::text[]));
 
 -- name: AddObjectsToFact :exec
 INSERT INTO obj_fact (obj_id, fact_id)
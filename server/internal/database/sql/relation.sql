@@ -0,0 +1,84 @@
+-- name: CreateObjectRelation :one
+INSERT INTO obj_relation (from_obj_id, to_obj_id, relation_type, metadata, creator_id)
+SELECT $1, $2, $3, $4, $5
+WHERE (
+    SELECT COUNT(*) FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    WHERE o.id IN ($1, $2) AND c.org_id = $6 AND o.deleted_at IS NULL
+) = 2
+RETURNING *;
+
+-- name: DeleteObjectRelation :execrows
+DELETE FROM obj_relation r
+USING obj o, creator c
+WHERE r.id = $1
+  AND (r.from_obj_id = $2 OR r.to_obj_id = $2)
+  AND o.id = $2
+  AND o.creator_id = c.id
+  AND c.org_id = $3;
+
+-- name: ListObjectRelations :many
+SELECT r.id, r.relation_type, r.metadata, r.created_at,
+    (CASE WHEN r.from_obj_id = $1 THEN 'outgoing' ELSE 'incoming' END)::text AS direction,
+    other.id AS object_id, other.name AS object_name, other.photo AS object_photo,
+    other.id_string AS object_id_string
+FROM obj_relation r
+JOIN obj other ON other.id = (CASE WHEN r.from_obj_id = $1 THEN r.to_obj_id ELSE r.from_obj_id END)
+JOIN creator c ON other.creator_id = c.id
+WHERE (r.from_obj_id = $1 OR r.to_obj_id = $1)
+  AND c.org_id = $2
+  AND other.deleted_at IS NULL
+ORDER BY r.relation_type, other.name;
+
+-- name: ListPublicObjectRelations :many
+-- Relations the public pages show: only to objects of a public type, and
+-- without the relation metadata
+SELECT r.id, r.relation_type, r.created_at,
+    (CASE WHEN r.from_obj_id = $1 THEN 'outgoing' ELSE 'incoming' END)::text AS direction,
+    other.id AS object_id, other.name AS object_name, other.photo AS object_photo,
+    other.id_string AS object_id_string
+FROM obj_relation r
+JOIN obj other ON other.id = (CASE WHEN r.from_obj_id = $1 THEN r.to_obj_id ELSE r.from_obj_id END)
+JOIN creator c ON other.creator_id = c.id
+WHERE (r.from_obj_id = $1 OR r.to_obj_id = $1)
+  AND c.org_id = $2
+  AND other.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM obj_type_value otv
+      JOIN obj_type ot ON ot.id = otv.type_id
+      WHERE otv.obj_id = other.id AND otv.deleted_at IS NULL
+        AND ot.is_public AND ot.deleted_at IS NULL)
+ORDER BY r.relation_type, other.name;
+
+-- name: ListObjectNeighbourhood :many
+WITH RECURSIVE walk(obj_id, depth) AS (
+    SELECT o.id, 0
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    WHERE o.id = $1 AND c.org_id = $2 AND o.deleted_at IS NULL
+  UNION
+    SELECT next.id, w.depth + 1
+    FROM walk w
+    JOIN obj_relation r ON r.from_obj_id = w.obj_id OR r.to_obj_id = w.obj_id
+    JOIN obj next ON next.id = (CASE WHEN r.from_obj_id = w.obj_id THEN r.to_obj_id ELSE r.from_obj_id END)
+    WHERE w.depth < $3::int AND next.deleted_at IS NULL
+)
+SELECT o.id, MIN(w.depth)::int AS depth, o.name, o.photo, o.id_string
+FROM walk w
+JOIN obj o ON o.id = w.obj_id
+GROUP BY o.id, o.name, o.photo, o.id_string
+ORDER BY depth, o.name;
+
+-- name: ListRelationsBetweenObjects :many
+SELECT * FROM obj_relation r
+WHERE r.from_obj_id = ANY($1::uuid[]) AND r.to_obj_id = ANY($1::uuid[])
+ORDER BY r.created_at;
+
+-- name: ListRelationTypes :many
+SELECT r.relation_type, COUNT(*) AS count
+FROM obj_relation r
+JOIN obj o ON r.from_obj_id = o.id
+JOIN creator c ON o.creator_id = c.id
+WHERE c.org_id = $1
+GROUP BY r.relation_type
+ORDER BY count DESC, r.relation_type;
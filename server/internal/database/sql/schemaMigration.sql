@@ -0,0 +1,105 @@
+-- name: CreateSchemaMigration :one
+INSERT INTO schema_migration (
+    org_id, creator_id, obj_type_id, operations, dry_run, status, total_rows
+)
+SELECT $1, $2, $3, $4, $5, 'pending',
+    (SELECT COUNT(*) FROM obj_type_value WHERE type_id = $3)
+WHERE EXISTS (
+    SELECT 1 FROM obj_type ot
+    JOIN creator c ON ot.creator_id = c.id
+    WHERE ot.id = $3 AND c.org_id = $1
+)
+RETURNING *;
+
+-- name: GetOngoingSchemaMigration :one
+SELECT * FROM schema_migration
+WHERE obj_type_id = $1 AND status IN ('pending', 'processing')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetSchemaMigration :one
+SELECT * FROM schema_migration
+WHERE id = $1 AND org_id = $2;
+
+-- name: ListSchemaMigrations :many
+SELECT * FROM schema_migration
+WHERE org_id = $1 AND obj_type_id = $2
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4;
+
+-- name: UpdateSchemaMigrationStatus :exec
+UPDATE schema_migration
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UpdateSchemaMigrationProgress :exec
+UPDATE schema_migration
+SET progress = $2, processed_rows = $3, failed_rows = $4, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CompleteSchemaMigration :exec
+UPDATE schema_migration
+SET status = 'completed', progress = 100, result_summary = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: FailSchemaMigration :exec
+UPDATE schema_migration
+SET status = 'failed', error_message = $2, result_summary = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: ListObjectTypeValuesForMigration :many
+SELECT * FROM obj_type_value
+WHERE type_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+FOR UPDATE;
+
+-- name: SetObjectTypeValues :exec
+UPDATE obj_type_value
+SET type_values = $2
+WHERE id = $1;
+
+-- name: RebuildObjectTypeValueSearchVectors :execrows
+UPDATE obj_type_value
+SET search_vector = generate_obj_type_value_search_vector(obj_type_value)
+WHERE type_id = $1;
+
+-- name: UpdateObjectTypeFields :exec
+UPDATE obj_type
+SET fields = $2
+WHERE id = $1;
+
+-- name: EnterSchemaMigration :exec
+-- Lets the transaction write the values and the fields of the type locked by
+-- the migration, see schema_migration_lock_trigger
+SELECT set_config('muninn.schema_migration', $1::uuid::text, true);
+
+-- name: GetRunningSchemaMigration :one
+SELECT id FROM schema_migration
+WHERE id = schema_migration_running($1);
+
+-- name: ListStaleSchemaMigrations :many
+-- Runs of an object type that died without finishing, and failed runs whose
+-- revert did not complete
+SELECT * FROM schema_migration sm
+WHERE obj_type_id = $1 AND (
+    (status IN ('pending', 'processing') AND NOT schema_migration_is_live(status, updated_at))
+    OR (status = 'failed' AND EXISTS (SELECT 1 FROM schema_migration_backup b WHERE b.migration_id = sm.id))
+);
+
+-- name: BackupObjectTypeValue :exec
+INSERT INTO schema_migration_backup (migration_id, type_value_id, type_values, migrated_version)
+SELECT $1, id, $3, version FROM obj_type_value WHERE id = $2
+ON CONFLICT DO NOTHING;
+
+-- name: RevertSchemaMigration :execrows
+-- Restores the values rewritten by a migration, but for the rows changed
+-- again since
+UPDATE obj_type_value v
+SET type_values = b.type_values
+FROM schema_migration_backup b
+WHERE b.migration_id = $1 AND b.type_value_id = v.id AND v.version = b.migrated_version;
+
+-- name: DeleteSchemaMigrationBackup :exec
+DELETE FROM schema_migration_backup
+WHERE migration_id = $1;
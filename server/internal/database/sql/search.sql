@@ -0,0 +1,117 @@
+-- name: SearchObjects :many
+-- Objects visible to the viewer matching by name, description, id_string or
+-- aliases, or by the values of the types the viewer can see. Type values
+-- match, and come with their headline, by the fields the role $6 may read
+-- only, so that hits and totals agree.
+WITH q AS (SELECT websearch_to_tsquery('english', $2::text) AS query),
+matches AS (
+    SELECT o.id, o.name, o.description, o.id_string, o.photo, o.created_at,
+        o.name || ' ' || o.description || ' ' || o.id_string || ' ' ||
+            coalesce(array_to_string(o.aliases, ' '), '') AS obj_text,
+        obj_ts_vector(o.name, o.description, o.id_string, o.aliases) @@ q.query AS obj_match,
+        ts_rank(obj_ts_vector(o.name, o.description, o.id_string, o.aliases), q.query) AS obj_rank,
+        tv.rank AS type_value_rank,
+        tv.type_values
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    CROSS JOIN q
+    LEFT JOIN LATERAL (
+        SELECT max(ts_rank(v.search_vector, q.query)) AS rank,
+            jsonb_agg(jsonb_build_object(
+                'type_id', v.type_id,
+                'type_values', v.type_values,
+                'headline', ts_headline('english', jsonb_to_text(v.type_values), q.query,
+                    'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5'))) AS type_values
+        FROM (
+            SELECT otv.type_id,
+                obj_type_value_readable(otv.type_values, ot.fields, $6::text) AS type_values,
+                CASE WHEN $6::text = 'admin' OR NOT obj_type_has_restricted(ot.fields) THEN otv.search_vector
+                     ELSE to_tsvector('english', jsonb_to_text(obj_type_value_readable(otv.type_values, ot.fields, $6::text)))
+                END AS search_vector
+            FROM obj_type_value otv
+            JOIN obj_type ot ON ot.id = otv.type_id
+            WHERE otv.obj_id = o.id AND otv.deleted_at IS NULL
+              AND obj_type_visible_to(otv.type_id, $3)
+        ) v
+        WHERE v.search_vector @@ q.query
+    ) tv ON true
+    WHERE c.org_id = $1 AND o.deleted_at IS NULL
+      AND (obj_ts_vector(o.name, o.description, o.id_string, o.aliases) @@ q.query
+           OR tv.type_values IS NOT NULL)
+      AND obj_visible_to(o.id, $3)
+)
+SELECT m.id, m.name, m.description, m.id_string, m.photo, m.created_at,
+    CASE WHEN m.obj_match
+         THEN ts_headline('english', m.obj_text, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5')
+         ELSE ''
+    END::text AS headline,
+    (m.obj_rank * 3 + coalesce(m.type_value_rank, 0) * 2)::float8 AS rank,
+    coalesce(m.type_values, '[]'::jsonb) AS type_values,
+    COUNT(*) OVER () AS total
+FROM matches m
+CROSS JOIN q
+ORDER BY rank DESC, m.name
+LIMIT $4 OFFSET $5;
+
+-- name: SearchFacts :many
+-- Facts matching by text or location, unless every object they are about is
+-- hidden from the viewer
+WITH q AS (SELECT websearch_to_tsquery('english', $2::text) AS query)
+SELECT f.id, f.text, f.kind, f.happened_at, f.created_at, f.creator_id,
+    ts_headline('english', f.text || ' ' || f.location, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5')::text AS headline,
+    ts_rank(to_tsvector('english', f.text || ' ' || f.location), q.query)::float8 AS rank,
+    COUNT(*) OVER () AS total
+FROM fact f
+JOIN creator c ON f.creator_id = c.id
+CROSS JOIN q
+WHERE c.org_id = $1 AND f.deleted_at IS NULL
+  AND to_tsvector('english', f.text || ' ' || f.location) @@ q.query
+  AND (NOT EXISTS (SELECT 1 FROM obj_fact of WHERE of.fact_id = f.id)
+       OR EXISTS (SELECT 1 FROM obj_fact of WHERE of.fact_id = f.id AND obj_visible_to(of.obj_id, $3)))
+ORDER BY rank DESC, coalesce(f.happened_at, f.created_at) DESC
+LIMIT $4 OFFSET $5;
+
+-- name: SearchTasks :many
+-- Tasks matching by content, unless every object they are about is hidden
+-- from the viewer
+WITH q AS (SELECT websearch_to_tsquery('english', $2::text) AS query)
+SELECT t.id, t.content, t.status, t.deadline, t.assigned_id, t.created_at,
+    ts_headline('english', t.content, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5')::text AS headline,
+    ts_rank(to_tsvector('english', t.content), q.query)::float8 AS rank,
+    COUNT(*) OVER () AS total
+FROM task t
+JOIN creator c ON t.creator_id = c.id
+CROSS JOIN q
+WHERE c.org_id = $1 AND t.deleted_at IS NULL
+  AND to_tsvector('english', t.content) @@ q.query
+  AND (NOT EXISTS (SELECT 1 FROM obj_task ot WHERE ot.task_id = t.id)
+       OR EXISTS (SELECT 1 FROM obj_task ot WHERE ot.task_id = t.id AND obj_visible_to(ot.obj_id, $3)))
+ORDER BY rank DESC, t.created_at DESC
+LIMIT $4 OFFSET $5;
+
+-- name: SearchTags :many
+WITH q AS (SELECT websearch_to_tsquery('english', $2::text) AS query)
+SELECT t.id, t.name, t.description, t.color_schema,
+    ts_headline('english', t.name || ' ' || t.description, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5')::text AS headline,
+    ts_rank(to_tsvector('english', t.name || ' ' || t.description), q.query)::float8 AS rank,
+    COUNT(*) OVER () AS total
+FROM tag t
+CROSS JOIN q
+WHERE t.org_id = $1 AND t.deleted_at IS NULL
+  AND to_tsvector('english', t.name || ' ' || t.description) @@ q.query
+ORDER BY rank DESC, t.name
+LIMIT $3 OFFSET $4;
+
+-- name: SearchLists :many
+WITH q AS (SELECT websearch_to_tsquery('english', $2::text) AS query)
+SELECT l.id, l.name, l.description, l.creator_id,
+    ts_headline('english', l.name || ' ' || l.description, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=10, MinWords=5')::text AS headline,
+    ts_rank(to_tsvector('english', l.name || ' ' || l.description), q.query)::float8 AS rank,
+    COUNT(*) OVER () AS total
+FROM list l
+JOIN creator c ON l.creator_id = c.id
+CROSS JOIN q
+WHERE c.org_id = $1 AND l.deleted_at IS NULL
+  AND to_tsvector('english', l.name || ' ' || l.description) @@ q.query
+ORDER BY rank DESC, l.name
+LIMIT $3 OFFSET $4;
@@ -20,6 +20,7 @@ updated_rows AS (
     id, 
     id_string, 
     aliases || new_aliases_to_add AS updated_aliases,
+    aliases AS old_aliases,
     has_id_string_match
   FROM matched_rows
   ORDER BY has_id_string_match DESC, id -- Prioritize rows with id_string match
@@ -30,6 +31,6 @@ update_result AS (
   SET aliases = u.updated_aliases
   FROM updated_rows u
   WHERE obj.id = u.id
-  RETURNING obj.id, obj.id_string, obj.aliases
+  RETURNING obj.id, obj.id_string, obj.aliases, u.old_aliases
 )
 SELECT * FROM update_result;
\ No newline at end of file
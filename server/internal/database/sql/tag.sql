@@ -12,7 +12,8 @@ WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteTag :execrows
-DELETE FROM tag 
+UPDATE tag
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL
   AND NOT EXISTS (
     SELECT 1 FROM obj_tag WHERE tag_id = $1
@@ -0,0 +1,188 @@
+-- name: ListTrash :many
+-- Objects merged into another one are deleted by the merge, they are not in
+-- the trash
+WITH trash AS (
+    SELECT 'object' AS kind, o.id, o.name::text AS name, o.deleted_at, o.deleted_by
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    WHERE c.org_id = $1 AND o.deleted_at IS NOT NULL
+      AND NOT EXISTS (
+        SELECT 1 FROM object_merge_history m WHERE o.id = ANY(m.source_object_ids)
+      )
+    UNION ALL
+    SELECT 'fact', f.id, LEFT(f.text, 200), f.deleted_at, f.deleted_by
+    FROM fact f
+    JOIN creator c ON f.creator_id = c.id
+    WHERE c.org_id = $1 AND f.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'task', t.id, LEFT(t.content, 200), t.deleted_at, t.deleted_by
+    FROM task t
+    JOIN creator c ON t.creator_id = c.id
+    WHERE c.org_id = $1 AND t.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'funnel', fu.id, fu.name::text, fu.deleted_at, fu.deleted_by
+    FROM funnel fu
+    JOIN creator c ON fu.creator_id = c.id
+    WHERE c.org_id = $1 AND fu.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'tag', tg.id, tg.name::text, tg.deleted_at, tg.deleted_by
+    FROM tag tg
+    WHERE tg.org_id = $1 AND tg.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'list', l.id, l.name::text, l.deleted_at, l.deleted_by
+    FROM list l
+    JOIN creator c ON l.creator_id = c.id
+    WHERE c.org_id = $1 AND l.deleted_at IS NOT NULL
+)
+SELECT tr.kind::text AS kind, tr.id, tr.name::text AS name, tr.deleted_at::timestamptz AS deleted_at,
+    tr.deleted_by, d.username AS deleted_by_name,
+    COUNT(*) OVER() AS total_count
+FROM trash tr
+LEFT JOIN creator d ON d.id = tr.deleted_by
+WHERE $2::text = '' OR tr.kind = $2::text
+ORDER BY tr.deleted_at DESC, tr.id
+LIMIT $3 OFFSET $4;
+
+-- name: RestoreObject :execrows
+-- The funnel steps detached by DeleteObject share its deleted_at, they are
+-- re-linked unless their step or funnel is gone. Merged objects cannot be
+-- restored.
+WITH target AS (
+    SELECT o.id, o.deleted_at
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    WHERE o.id = $1 AND c.org_id = $2 AND o.deleted_at IS NOT NULL
+      AND NOT EXISTS (
+        SELECT 1 FROM object_merge_history m WHERE o.id = ANY(m.source_object_ids)
+      )
+),
+relinked AS (
+    UPDATE obj_step os
+    SET deleted_at = NULL
+    FROM target t, step s, funnel f
+    WHERE os.obj_id = t.id AND os.deleted_at = t.deleted_at
+      AND s.id = os.step_id AND s.deleted_at IS NULL
+      AND f.id = s.funnel_id AND f.deleted_at IS NULL
+)
+UPDATE obj o
+SET deleted_at = NULL, deleted_by = NULL
+FROM target t
+WHERE o.id = t.id;
+
+-- name: RestoreFact :execrows
+UPDATE fact f
+SET deleted_at = NULL, deleted_by = NULL
+FROM creator c
+WHERE f.id = $1 AND f.creator_id = c.id AND c.org_id = $2
+  AND f.deleted_at IS NOT NULL;
+
+-- name: PurgeFact :execrows
+DELETE FROM fact f
+USING creator c
+WHERE f.id = $1 AND f.creator_id = c.id AND c.org_id = $2
+  AND f.deleted_at IS NOT NULL;
+
+-- name: RestoreTask :execrows
+UPDATE task t
+SET deleted_at = NULL, deleted_by = NULL
+FROM creator c
+WHERE t.id = $1 AND t.creator_id = c.id AND c.org_id = $2
+  AND t.deleted_at IS NOT NULL;
+
+-- name: PurgeTask :execrows
+DELETE FROM task t
+USING creator c
+WHERE t.id = $1 AND t.creator_id = c.id AND c.org_id = $2
+  AND t.deleted_at IS NOT NULL;
+
+-- name: RestoreFunnel :execrows
+UPDATE funnel fu
+SET deleted_at = NULL, deleted_by = NULL
+FROM creator c
+WHERE fu.id = $1 AND fu.creator_id = c.id AND c.org_id = $2
+  AND fu.deleted_at IS NOT NULL;
+
+-- name: PurgeFunnel :execrows
+DELETE FROM funnel fu
+USING creator c
+WHERE fu.id = $1 AND fu.creator_id = c.id AND c.org_id = $2
+  AND fu.deleted_at IS NOT NULL;
+
+-- name: RestoreTag :execrows
+UPDATE tag
+SET deleted_at = NULL, deleted_by = NULL
+WHERE id = $1 AND org_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: PurgeTag :execrows
+DELETE FROM tag
+WHERE id = $1 AND org_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: RestoreList :execrows
+UPDATE list l
+SET deleted_at = NULL, deleted_by = NULL
+FROM creator c
+WHERE l.id = $1 AND l.creator_id = c.id AND c.org_id = $2
+  AND l.deleted_at IS NOT NULL;
+
+-- name: PurgeList :execrows
+DELETE FROM list l
+USING creator c
+WHERE l.id = $1 AND l.creator_id = c.id AND c.org_id = $2
+  AND l.deleted_at IS NOT NULL;
+
+-- name: PurgeObject :execrows
+DELETE FROM obj o
+USING creator c
+WHERE o.id = $1 AND o.creator_id = c.id AND c.org_id = $2
+  AND o.deleted_at IS NOT NULL;
+
+-- name: PurgeObjStep :execrows
+DELETE FROM obj_step os
+USING obj o, creator c
+WHERE os.id = $1 AND os.obj_id = o.id AND o.creator_id = c.id AND c.org_id = $2;
+
+-- name: ListObjectTypeValuesByObject :many
+SELECT type_id, type_values FROM obj_type_value
+WHERE obj_id = $1 AND deleted_at IS NULL;
+
+-- name: PurgeExpiredTrash :one
+-- Only the items deleted through the trash have deleted_by, older deletes
+-- and the sources of a merge are left alone. The history of purged objects
+-- stays in obj_history.
+WITH objects AS (
+    DELETE FROM obj
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+),
+facts AS (
+    DELETE FROM fact
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+),
+tasks AS (
+    DELETE FROM task
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+),
+funnels AS (
+    DELETE FROM funnel
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+),
+tags AS (
+    DELETE FROM tag
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+),
+lists AS (
+    DELETE FROM list
+    WHERE deleted_at < $1::timestamptz AND deleted_by IS NOT NULL
+    RETURNING id
+)
+SELECT
+    (SELECT COUNT(*) FROM objects) AS objects,
+    (SELECT COUNT(*) FROM facts) AS facts,
+    (SELECT COUNT(*) FROM tasks) AS tasks,
+    (SELECT COUNT(*) FROM funnels) AS funnels,
+    (SELECT COUNT(*) FROM tags) AS tags,
+    (SELECT COUNT(*) FROM lists) AS lists;
@@ -0,0 +1,12 @@
+-- name: FindObjectByTypeValue :one
+SELECT o.id, o.name, o.id_string
+FROM obj_type_value otv
+JOIN obj o ON o.id = otv.obj_id
+JOIN creator c ON o.creator_id = c.id
+WHERE otv.type_id = $1
+  AND c.org_id = $2
+  AND o.deleted_at IS NULL
+  AND NOT (o.id = ANY($3::uuid[]))
+  AND LOWER(TRIM(otv.type_values ->> $4::text)) = LOWER(TRIM($5::text))
+ORDER BY o.created_at
+LIMIT 1;
@@ -0,0 +1,211 @@
+-- name: CreateWatch :one
+INSERT INTO watch (creator_id, obj_id, list_id, step_id, events)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: FindWatch :one
+SELECT * FROM watch
+WHERE creator_id = $1
+  AND obj_id IS NOT DISTINCT FROM $2
+  AND list_id IS NOT DISTINCT FROM $3
+  AND step_id IS NOT DISTINCT FROM $4;
+
+-- name: UpdateWatchEvents :one
+UPDATE watch
+SET events = $3
+WHERE id = $1 AND creator_id = $2
+RETURNING *;
+
+-- name: DeleteWatch :execrows
+DELETE FROM watch
+WHERE id = $1 AND creator_id = $2;
+
+-- name: ListWatchesByCreator :many
+SELECT w.id, w.obj_id, w.list_id, w.step_id, w.events, w.created_at,
+    COALESCE(o.name, l.name, s.name, '')::text AS target_name
+FROM watch w
+LEFT JOIN obj o ON o.id = w.obj_id
+LEFT JOIN list l ON l.id = w.list_id
+LEFT JOIN step s ON s.id = w.step_id
+WHERE w.creator_id = $1
+ORDER BY w.created_at DESC;
+
+-- name: IsOrgList :one
+SELECT EXISTS (
+    SELECT 1 FROM list l
+    JOIN creator c ON l.creator_id = c.id
+    WHERE l.id = $1 AND c.org_id = $2 AND l.deleted_at IS NULL
+);
+
+-- name: ListObjectWatchers :many
+-- Watches of the objects and of the steps they are in, for an event made by
+-- the actor: only watchers of the org of the actor other than the actor
+SELECT w.id, w.creator_id, o.id AS obj_id
+FROM obj o
+JOIN creator oc ON o.creator_id = oc.id
+JOIN watch w ON w.obj_id = o.id
+    OR w.step_id IN (
+        SELECT os.step_id FROM obj_step os
+        WHERE os.obj_id = o.id AND os.deleted_at IS NULL
+    )
+JOIN creator wc ON wc.id = w.creator_id
+WHERE o.id = ANY($1::uuid[])
+  AND $2::text = ANY(w.events)
+  AND w.creator_id <> $3
+  AND wc.org_id = oc.org_id
+  AND oc.org_id = (SELECT org_id FROM creator WHERE id = $3)
+  AND wc.active AND wc.deleted_at IS NULL;
+
+-- name: ListListWatches :many
+-- Watches of the lists of the org of the actor, other than the actor's
+SELECT w.id, w.creator_id, l.id AS list_id, wc.org_id, l.filter_setting
+FROM watch w
+JOIN list l ON l.id = w.list_id
+JOIN creator wc ON wc.id = w.creator_id
+WHERE $1::text = ANY(w.events)
+  AND w.creator_id <> $2
+  AND wc.org_id = (SELECT org_id FROM creator WHERE id = $2)
+  AND wc.active AND wc.deleted_at IS NULL
+  AND l.deleted_at IS NULL;
+
+-- name: MatchListFilters :many
+-- The filters of lists, as applied by AddTagAndStepToFilteredObjects, on the
+-- given objects only: one row per list and matching object. $3 is an array
+-- of filters, one per list, matched together so that an event costs one
+-- query however many lists are watched.
+WITH object_data AS (
+    SELECT 
+        o.id, 
+        o.created_at,
+        array_agg(DISTINCT os.step_id) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_ids,
+        array_agg(DISTINCT t.id) FILTER (WHERE t.id IS NOT NULL) as tag_ids,
+        array_agg(DISTINCT otv.type_id) FILTER (WHERE otv.type_id IS NOT NULL) as type_ids,
+        array_agg(DISTINCT s.funnel_id) FILTER (WHERE s.funnel_id IS NOT NULL) as funnel_ids,
+        jsonb_object_agg(
+            os.step_id, 
+            os.sub_status
+        ) FILTER (WHERE os.step_id IS NOT NULL AND os.deleted_at IS NULL) as step_substatus,
+        jsonb_agg(DISTINCT otv.type_values) FILTER (WHERE otv.type_values IS NOT NULL) as all_type_values,
+        to_tsvector('english', 
+            o.name || ' ' || 
+            o.description || ' ' || 
+            o.id_string || ' ' || 
+            array_to_string(o.aliases, ' ') || ' ' ||
+            COALESCE(string_agg(DISTINCT t.name, ' '), '')
+        ) AS obj_search,
+        to_tsvector('english', string_agg(DISTINCT COALESCE(f.text, ''), ' ')) AS fact_search,
+        (
+            SELECT string_agg(otv.search_vector::text, ' ')::tsvector 
+            FROM obj_type_value otv 
+            WHERE otv.obj_id = o.id
+        ) AS type_value_search
+    FROM obj o
+    JOIN creator c ON o.creator_id = c.id
+    LEFT JOIN obj_tag ot ON o.id = ot.obj_id
+    LEFT JOIN tag t ON ot.tag_id = t.id
+    LEFT JOIN obj_type_value otv ON o.id = otv.obj_id
+    LEFT JOIN obj_fact of ON o.id = of.obj_id
+    LEFT JOIN fact f ON of.fact_id = f.id
+    LEFT JOIN obj_step os ON o.id = os.obj_id AND os.deleted_at IS NULL
+    LEFT JOIN step s ON os.step_id = s.id AND s.deleted_at IS NULL
+    WHERE c.org_id = $1 AND o.deleted_at IS NULL AND o.id = ANY($2::uuid[])
+    GROUP BY o.id
+)
+SELECT lf.list_id, od.id AS obj_id
+FROM jsonb_to_recordset($3::jsonb) AS lf(
+    list_id uuid, search text, exclude_search text, step_ids uuid[], tag_ids uuid[], type_ids uuid[],
+    criteria1 jsonb, criteria2 jsonb, criteria3 jsonb, sub_statuses int[],
+    exclude_step_ids uuid[], exclude_tag_ids uuid[], exclude_type_ids uuid[],
+    created_after timestamptz, created_before timestamptz, fact_kinds text[]
+)
+CROSS JOIN object_data od
+WHERE 
+    (lf.step_ids IS NULL OR od.step_ids && lf.step_ids) AND
+    (lf.step_ids IS NULL OR lf.sub_statuses IS NULL OR (
+        NOT EXISTS (
+            SELECT 1
+            FROM unnest(lf.step_ids) AS step_id
+            WHERE step_id = ANY(od.step_ids)
+              AND NOT (od.step_substatus->step_id::text)::int = ANY(lf.sub_statuses)
+        )
+    )) AND
+    (lf.tag_ids IS NULL OR od.tag_ids && lf.tag_ids) AND
+    (lf.type_ids IS NULL OR od.type_ids && lf.type_ids) AND
+    -- Filter out objects with any of the excluded steps, tags or types
+    (lf.exclude_step_ids IS NULL OR NOT (COALESCE(od.step_ids, '{}') && lf.exclude_step_ids)) AND
+    (lf.exclude_tag_ids IS NULL OR NOT (COALESCE(od.tag_ids, '{}') && lf.exclude_tag_ids)) AND
+    (lf.exclude_type_ids IS NULL OR NOT (COALESCE(od.type_ids, '{}') && lf.exclude_type_ids)) AND
+    -- Filter by creation date, from inclusive and before exclusive
+    (lf.created_after IS NULL OR od.created_at >= lf.created_after) AND
+    (lf.created_before IS NULL OR od.created_at < lf.created_before) AND
+    -- Filter by fact kinds if array is provided
+    (lf.fact_kinds IS NULL OR EXISTS (
+        SELECT 1
+        FROM obj_fact xf
+        JOIN fact fk ON fk.id = xf.fact_id
+        WHERE xf.obj_id = od.id AND fk.deleted_at IS NULL AND fk.kind = ANY(lf.fact_kinds)
+    )) AND
+    ((COALESCE(lf.criteria1, 'null'::jsonb) = 'null'::jsonb) OR 
+     EXISTS (
+         SELECT 1 
+         FROM jsonb_array_elements(od.all_type_values) tv,
+              jsonb_each_text(tv) fields
+         WHERE 
+            CASE 
+                WHEN jsonb_typeof(lf.criteria1) = 'object' THEN
+                    EXISTS (
+                        SELECT 1
+                        FROM jsonb_each(lf.criteria1) criteria
+                        WHERE fields.key = criteria.key 
+                        AND type_value_matches(fields.value, criteria.value)
+                    )
+                ELSE false
+            END
+     )) AND
+    ((COALESCE(lf.criteria2, 'null'::jsonb) = 'null'::jsonb) OR 
+     EXISTS (
+         SELECT 1 
+         FROM jsonb_array_elements(od.all_type_values) tv,
+              jsonb_each_text(tv) fields
+         WHERE 
+            CASE 
+                WHEN jsonb_typeof(lf.criteria2) = 'object' THEN
+                    EXISTS (
+                        SELECT 1
+                        FROM jsonb_each(lf.criteria2) criteria
+                        WHERE fields.key = criteria.key 
+                        AND type_value_matches(fields.value, criteria.value)
+                    )
+                ELSE false
+            END
+     )) AND
+    ((COALESCE(lf.criteria3, 'null'::jsonb) = 'null'::jsonb) OR 
+     EXISTS (
+         SELECT 1 
+         FROM jsonb_array_elements(od.all_type_values) tv,
+              jsonb_each_text(tv) fields
+         WHERE 
+            CASE 
+                WHEN jsonb_typeof(lf.criteria3) = 'object' THEN
+                    EXISTS (
+                        SELECT 1
+                        FROM jsonb_each(lf.criteria3) criteria
+                        WHERE fields.key = criteria.key 
+                        AND type_value_matches(fields.value, criteria.value)
+                    )
+                ELSE false
+            END
+     )) AND
+    (COALESCE(lf.search, '') = '' OR
+     obj_search @@ websearch_to_tsquery('english', lf.search) OR
+     fact_search @@ websearch_to_tsquery('english', lf.search) OR
+     type_value_search @@ websearch_to_tsquery('english', lf.search)) AND
+    -- Words the query excludes, from every text at once
+    (COALESCE(lf.exclude_search, '') = '' OR NOT COALESCE(
+     obj_search @@ websearch_to_tsquery('english', lf.exclude_search) OR
+     fact_search @@ websearch_to_tsquery('english', lf.exclude_search) OR
+     type_value_search @@ websearch_to_tsquery('english', lf.exclude_search), false));
+
+-- name: ListObjectNames :many
+SELECT id, name FROM obj
+WHERE id = ANY($1::uuid[]);
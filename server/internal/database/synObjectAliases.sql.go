@@ -34,6 +34,7 @@ updated_rows AS (
     id, 
     id_string, 
     aliases || new_aliases_to_add AS updated_aliases,
+    aliases AS old_aliases,
     has_id_string_match
   FROM matched_rows
   ORDER BY has_id_string_match DESC, id -- Prioritize rows with id_string match
@@ -44,9 +45,9 @@ update_result AS (
   SET aliases = u.updated_aliases
   FROM updated_rows u
   WHERE obj.id = u.id
-  RETURNING obj.id, obj.id_string, obj.aliases
+  RETURNING obj.id, obj.id_string, obj.aliases, u.old_aliases
 )
-SELECT id, id_string, aliases FROM update_result
+SELECT id, id_string, aliases, old_aliases FROM update_result
 `
 
 type SyncObjectAliasesParams struct {
@@ -55,14 +56,20 @@ type SyncObjectAliasesParams struct {
 }
 
 type SyncObjectAliasesRow struct {
-	ID       uuid.UUID `json:"id"`
-	IDString string    `json:"id_string"`
-	Aliases  []string  `json:"aliases"`
+	ID         uuid.UUID `json:"id"`
+	IDString   string    `json:"id_string"`
+	Aliases    []string  `json:"aliases"`
+	OldAliases []string  `json:"old_aliases"`
 }
 
 func (q *Queries) SyncObjectAliases(ctx context.Context, arg SyncObjectAliasesParams) (SyncObjectAliasesRow, error) {
 	row := q.queryRow(ctx, q.syncObjectAliasesStmt, syncObjectAliases, pq.Array(arg.Column1), arg.OrgID)
 	var i SyncObjectAliasesRow
-	err := row.Scan(&i.ID, &i.IDString, pq.Array(&i.Aliases))
+	err := row.Scan(
+		&i.ID,
+		&i.IDString,
+		pq.Array(&i.Aliases),
+		pq.Array(&i.OldAliases),
+	)
 	return i, err
 }
@@ -38,7 +38,7 @@ const createTag = `-- name: CreateTag :one
 
 INSERT INTO tag (name, description, color_schema, org_id)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, description, color_schema, org_id, created_at, deleted_at
+RETURNING id, name, description, color_schema, org_id, created_at, deleted_at, deleted_by
 `
 
 type CreateTagParams struct {
@@ -65,20 +65,27 @@ func (q *Queries) CreateTag(ctx context.Context, arg CreateTagParams) (Tag, erro
 		&i.OrgID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
 
 const deleteTag = `-- name: DeleteTag :execrows
-DELETE FROM tag 
+UPDATE tag
+SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
 WHERE id = $1 AND deleted_at IS NULL
   AND NOT EXISTS (
     SELECT 1 FROM obj_tag WHERE tag_id = $1
   )
 `
 
-func (q *Queries) DeleteTag(ctx context.Context, id uuid.UUID) (int64, error) {
-	result, err := q.exec(ctx, q.deleteTagStmt, deleteTag, id)
+type DeleteTagParams struct {
+	ID        uuid.UUID     `json:"id"`
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteTag(ctx context.Context, arg DeleteTagParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteTagStmt, deleteTag, arg.ID, arg.DeletedBy)
 	if err != nil {
 		return 0, err
 	}
@@ -86,7 +93,7 @@ func (q *Queries) DeleteTag(ctx context.Context, id uuid.UUID) (int64, error) {
 }
 
 const getTagByID = `-- name: GetTagByID :one
-SELECT id, name, description, color_schema, org_id, created_at, deleted_at FROM tag
+SELECT id, name, description, color_schema, org_id, created_at, deleted_at, deleted_by FROM tag
 WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -102,12 +109,13 @@ func (q *Queries) GetTagByID(ctx context.Context, id uuid.UUID) (Tag, error) {
 		&i.OrgID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
 
 const getTagsByIDs = `-- name: GetTagsByIDs :many
-SELECT id, name, description, color_schema, org_id, created_at, deleted_at FROM tag
+SELECT id, name, description, color_schema, org_id, created_at, deleted_at, deleted_by FROM tag
 WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
 `
 
@@ -128,6 +136,7 @@ func (q *Queries) GetTagsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Tag
 			&i.OrgID,
 			&i.CreatedAt,
 			&i.DeletedAt,
+			&i.DeletedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -205,7 +214,7 @@ const updateTag = `-- name: UpdateTag :one
 UPDATE tag
 SET description = $2, color_schema = $3
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, name, description, color_schema, org_id, created_at, deleted_at
+RETURNING id, name, description, color_schema, org_id, created_at, deleted_at, deleted_by
 `
 
 type UpdateTagParams struct {
@@ -225,6 +234,7 @@ func (q *Queries) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, erro
 		&i.OrgID,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
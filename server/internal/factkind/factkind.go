@@ -0,0 +1,118 @@
+// Package factkind defines the kinds of facts (meeting, call, email...) and
+// the metadata each kind may carry.
+//
+// The metadata of a fact is a JSON object checked against the schema of its
+// kind, written in the same format as obj_type.fields (see package schema).
+// Notes are free form: their schema is empty and accepts any object.
+package factkind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/crea8r/muninn/server/internal/schema"
+)
+
+// Kinds of facts, kept in sync with the CHECK constraint on fact.kind
+const (
+	Meeting   = "meeting"
+	Call      = "call"
+	Email     = "email"
+	Intro     = "intro"
+	DealEvent = "deal_event"
+	Note      = "note"
+)
+
+// Default is the kind of facts created without one
+const Default = Note
+
+var ErrUnknownKind = errors.New("unknown fact kind")
+
+// Kind is a kind of fact and the schema of its metadata
+type Kind struct {
+	Name   string          `json:"name"`
+	Label  string          `json:"label"`
+	Fields json.RawMessage `json:"fields"`
+
+	schema schema.Schema
+}
+
+var kinds = []Kind{
+	{Name: Meeting, Label: "Meeting", Fields: json.RawMessage(`{
+		"duration": {"type": "number", "validation": {"min": 0}, "meta": {"unit": "minutes"}},
+		"attendees": {"type": "object", "validation": {"multiple": true}},
+		"outcome": {"type": "string", "validation": {"maxLength": 500}}
+	}`)},
+	{Name: Call, Label: "Call", Fields: json.RawMessage(`{
+		"duration": {"type": "number", "validation": {"min": 0}, "meta": {"unit": "minutes"}},
+		"direction": {"type": "enum", "validation": {"options": ["inbound", "outbound"]}},
+		"outcome": {"type": "string", "validation": {"maxLength": 500}}
+	}`)},
+	{Name: Email, Label: "Email", Fields: json.RawMessage(`{
+		"direction": {"type": "enum", "validation": {"options": ["inbound", "outbound"]}},
+		"subject": {"type": "string", "validation": {"maxLength": 500}}
+	}`)},
+	{Name: Intro, Label: "Introduction", Fields: json.RawMessage(`{
+		"introducer": {"type": "object"},
+		"outcome": {"type": "string", "validation": {"maxLength": 500}}
+	}`)},
+	{Name: DealEvent, Label: "Deal event", Fields: json.RawMessage(`{
+		"amount": {"type": "number"},
+		"currency": {"type": "string", "validation": {"regex": "^[A-Z]{3}$", "regexMessage": "must be an ISO 4217 code such as USD"}},
+		"outcome": {"type": "string", "validation": {"maxLength": 500}}
+	}`)},
+	{Name: Note, Label: "Note", Fields: json.RawMessage(`{}`)},
+}
+
+var byName = map[string]*Kind{}
+
+func init() {
+	for i := range kinds {
+		s, err := schema.Parse(kinds[i].Fields)
+		if err != nil {
+			panic(fmt.Sprintf("fact kind %s: %v", kinds[i].Name, err))
+		}
+		if err := s.Check(); err != nil {
+			panic(fmt.Sprintf("fact kind %s: %v", kinds[i].Name, err))
+		}
+		kinds[i].schema = s
+		byName[kinds[i].Name] = &kinds[i]
+	}
+}
+
+// All returns the kinds in display order
+func All() []Kind {
+	return append([]Kind(nil), kinds...)
+}
+
+// Get returns a kind by name, the default kind for an empty name
+func Get(name string) (*Kind, error) {
+	if name == "" {
+		name = Default
+	}
+	kind, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKind, name)
+	}
+	return kind, nil
+}
+
+// Valid reports whether name is a kind
+func Valid(name string) bool {
+	_, ok := byName[name]
+	return ok
+}
+
+// Validate checks metadata against the schema of the kind and returns it
+// normalized, an empty object when there is none. Errors are
+// *schema.ValidationError.
+func (k *Kind) Validate(metadata json.RawMessage) (json.RawMessage, error) {
+	if len(metadata) == 0 || string(metadata) == "null" {
+		metadata = json.RawMessage(`{}`)
+	}
+	if err := k.schema.ValidateRaw(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -22,7 +22,7 @@ func NewHandler(db *database.Queries) *Handler {
 	}
 }
 
-func (h *Handler) RegisterRoutes(r chi.Router, wrapWithFeed func(http.HandlerFunc) http.HandlerFunc) {
+func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/auth/signup", h.SignUp)
 	r.Post("/auth/login", h.Login)
 	r.Post("/auth/robotlogin", h.RobotLogin)
@@ -31,7 +31,7 @@ func (h *Handler) RegisterRoutes(r chi.Router, wrapWithFeed func(http.HandlerFun
 		r.Use(middleware.Permission)
 		r.Get("/members", h.ListOrgCreators)
 		r.Put("/details", h.UpdateOrgDetails)
-		r.Post("/members", wrapWithFeed(h.AddNewOrgCreator))
+		r.Post("/members", h.AddNewOrgCreator)
 		r.Put("/members/{userID}/permission", h.UpdateCreatorRoleAndStatus)
 		r.Put("/members/{userID}/password", h.UpdateCreatorPassword)
 		r.Put("/members/{userID}/profile", h.UpdateCreatorProfile)
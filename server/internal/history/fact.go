@@ -0,0 +1,109 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// RecordFact snapshots a fact after a change as its next version in
+// fact_version, with the source of ctx, skipping changes that changed
+// nothing. Unlike Record it fails the write: db must be the transaction of
+// the change, which is not saved without its version. Facts changed by other
+// means than UpdateFact must be locked first, see RecordFacts.
+func RecordFact(ctx context.Context, db *database.Queries, actorID, factID uuid.UUID, action string) error {
+	return RecordFactFrom(ctx, db, SourceFrom(ctx), actorID, factID, action)
+}
+
+// RecordFactFrom is RecordFact with an explicit source, for background jobs
+func RecordFactFrom(ctx context.Context, db *database.Queries, source string, actorID, factID uuid.UUID, action string) error {
+	return recordFact(ctx, db, source, actorID, factID, action, 0)
+}
+
+// RecordFactRestored snapshots a fact restored from one of its versions
+func RecordFactRestored(ctx context.Context, db *database.Queries, actorID, factID uuid.UUID, version int32) error {
+	return recordFact(ctx, db, SourceFrom(ctx), actorID, factID, ActionRestored, version)
+}
+
+// RecordFacts locks facts whose objects changed and snapshots them, so every
+// writer of obj_fact leaves a version behind
+func RecordFacts(ctx context.Context, db *database.Queries, source string, actorID uuid.UUID, factIDs []uuid.UUID, action string) error {
+	if len(factIDs) == 0 {
+		return nil
+	}
+	if err := db.LockFacts(ctx, factIDs); err != nil {
+		return fmt.Errorf("error locking facts: %w", err)
+	}
+	for _, factID := range factIDs {
+		if err := recordFact(ctx, db, source, actorID, factID, action, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordFact(ctx context.Context, db *database.Queries, source string, actorID, factID uuid.UUID, action string, restoredFrom int32) error {
+	_, err := db.RecordFactVersion(ctx, database.RecordFactVersionParams{
+		FactID:       factID,
+		EditorID:     actorID,
+		Source:       source,
+		Action:       action,
+		RestoredFrom: sql.NullInt32{Int32: restoredFrom, Valid: restoredFrom != 0},
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error recording %s version of fact %s: %w", action, factID, err)
+	}
+	return nil
+}
+
+// DiffFact compares two versions of a fact, a nil before is the fact being
+// created
+func DiffFact(before *database.FactVersion, after database.FactVersion) []Change {
+	if before == nil {
+		before = &database.FactVersion{}
+	}
+	var changes []Change
+	if before.Text != after.Text {
+		changes = append(changes, Change{Field: "text", From: before.Text, To: after.Text})
+	}
+	if before.HappenedAt.Valid != after.HappenedAt.Valid || !before.HappenedAt.Time.Equal(after.HappenedAt.Time) {
+		changes = append(changes, Change{Field: "happened_at", From: nullTime(before.HappenedAt), To: nullTime(after.HappenedAt)})
+	}
+	if before.Location != after.Location {
+		changes = append(changes, Change{Field: "location", From: before.Location, To: after.Location})
+	}
+	if before.Kind != after.Kind {
+		changes = append(changes, Change{Field: "kind", From: before.Kind, To: after.Kind})
+	}
+	for _, c := range DiffValuesRaw(before.Metadata, after.Metadata) {
+		c.Field = "metadata." + c.Field
+		changes = append(changes, c)
+	}
+	// Versions keep their object ids sorted
+	if !sameIDs(before.ObjIds, after.ObjIds) {
+		changes = append(changes, Change{Field: "objects", From: before.ObjIds, To: after.ObjIds})
+	}
+	return changes
+}
+
+func nullTime(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time
+}
+
+func sameIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,266 @@
+// Package history records the changes made to objects in obj_history, and
+// the versions of facts in fact_version.
+//
+// Every write path records what it changed in the transaction of the write:
+// the object fields, tags, type values with field-level diffs, funnel steps
+// and merges. Each entry keeps the acting creator and the source of the change.
+// HTTP requests carry their source in the context (see WithSource), background
+// jobs such as imports and automations pass theirs explicitly.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/watch"
+	"github.com/google/uuid"
+)
+
+// Sources of a change
+const (
+	SourceUI          = "ui"
+	SourceExternalAPI = "external_api"
+	SourceAutomation  = "automation"
+	SourceImport      = "import"
+	// SourceEmail is mail received by the drop-box of an org
+	SourceEmail = "email"
+)
+
+// Actions recorded on an object
+const (
+	ActionCreated          = "created"
+	ActionUpdated          = "updated"
+	ActionDeleted          = "deleted"
+	ActionRestored         = "restored"
+	ActionTagAdded         = "tag_added"
+	ActionTagRemoved       = "tag_removed"
+	ActionTypeValueAdded   = "type_value_added"
+	ActionTypeValueUpdated = "type_value_updated"
+	ActionTypeValueRemoved = "type_value_removed"
+	ActionStepAdded        = "step_added"
+	ActionStepMoved        = "step_moved"
+	ActionStepRemoved      = "step_removed"
+	ActionSubStatusChanged = "sub_status_changed"
+	// ActionMerged is recorded on the object other objects were merged into,
+	// ActionMergedInto on each of the merged objects
+	ActionMerged     = "merged"
+	ActionMergedInto = "merged_into"
+)
+
+// Change is the diff of a single field
+type Change struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Entry is one change of an object. TypeID is set for type value changes.
+type Entry struct {
+	ObjID   uuid.UUID
+	Action  string
+	TypeID  uuid.UUID
+	Changes []Change
+	Details map[string]interface{}
+}
+
+type sourceKey struct{}
+
+// WithSource returns a context whose changes are recorded with source
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFrom returns the source set by WithSource, changes made without one
+// come from the webapp
+func SourceFrom(ctx context.Context) string {
+	if source, ok := ctx.Value(sourceKey{}).(string); ok {
+		return source
+	}
+	return SourceUI
+}
+
+// Record stores the entry made by actorID with the source of ctx. It must run
+// in the transaction of the write it describes, which is not saved without
+// its history. Updates that changed nothing are skipped.
+func Record(ctx context.Context, db *database.Queries, actorID uuid.UUID, e Entry) error {
+	return RecordFrom(ctx, db, SourceFrom(ctx), actorID, e)
+}
+
+// RecordFrom is Record with an explicit source, for background jobs. Step
+// moves and type value changes are also sent to the watchers of the object.
+func RecordFrom(ctx context.Context, db *database.Queries, source string, actorID uuid.UUID, e Entry) error {
+	if len(e.Changes) == 0 && (e.Action == ActionUpdated || e.Action == ActionTypeValueUpdated) {
+		return nil
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil || e.Changes == nil {
+		changes = json.RawMessage("[]")
+	}
+	details, err := json.Marshal(e.Details)
+	if err != nil || e.Details == nil {
+		details = json.RawMessage("{}")
+	}
+	err = db.CreateObjectHistory(ctx, database.CreateObjectHistoryParams{
+		ObjID:     e.ObjID,
+		CreatorID: actorID,
+		Source:    source,
+		Action:    e.Action,
+		TypeID:    uuid.NullUUID{UUID: e.TypeID, Valid: e.TypeID != uuid.Nil},
+		Changes:   changes,
+		Details:   details,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording %s history of object %s: %w", e.Action, e.ObjID, err)
+	}
+	if kind := watchEvent(e.Action); kind != "" {
+		return watch.Notify(ctx, db, actorID, watch.Event{
+			Kind:   kind,
+			Action: e.Action,
+			ObjIDs: []uuid.UUID{e.ObjID},
+			TypeID: e.TypeID,
+		})
+	}
+	return nil
+}
+
+// watchEvent is the watch event of a history action, if any
+func watchEvent(action string) string {
+	switch action {
+	case ActionStepAdded, ActionStepMoved:
+		return watch.EventStep
+	case ActionTypeValueAdded, ActionTypeValueUpdated, ActionTypeValueRemoved:
+		return watch.EventTypeValue
+	}
+	return ""
+}
+
+// DiffObject compares the fields of an object edited by users
+func DiffObject(before, after database.Obj) []Change {
+	var changes []Change
+	if before.Name != after.Name {
+		changes = append(changes, Change{Field: "name", From: before.Name, To: after.Name})
+	}
+	if before.Description != after.Description {
+		changes = append(changes, Change{Field: "description", From: before.Description, To: after.Description})
+	}
+	if before.IDString != after.IDString {
+		changes = append(changes, Change{Field: "id_string", From: before.IDString, To: after.IDString})
+	}
+	if !sameStrings(before.Aliases, after.Aliases) {
+		changes = append(changes, Change{Field: "aliases", From: before.Aliases, To: after.Aliases})
+	}
+	return changes
+}
+
+// DiffValues compares type values field by field, in field name order.
+// Missing and null fields are the same.
+func DiffValues(before, after map[string]interface{}) []Change {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, name := range sorted {
+		from, to := before[name], after[name]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, From: from, To: to})
+	}
+	return changes
+}
+
+// DiffValuesRaw is DiffValues for raw type_values JSON, an empty before is a
+// type value being added and an empty after one being removed
+func DiffValuesRaw(before, after json.RawMessage) []Change {
+	return DiffValues(decode(before), decode(after))
+}
+
+func decode(raw json.RawMessage) map[string]interface{} {
+	var values map[string]interface{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &values)
+	}
+	return values
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordTag records a tag added to or removed from an object, with the tag
+// name at the time of the change
+func RecordTag(ctx context.Context, db *database.Queries, source string, actorID, objID, tagID uuid.UUID, action string) error {
+	details := map[string]interface{}{"tagId": tagID}
+	if tag, err := db.GetTagByID(ctx, tagID); err == nil {
+		details["tagName"] = tag.Name
+	}
+	return RecordFrom(ctx, db, source, actorID, Entry{ObjID: objID, Action: action, Details: details})
+}
+
+// RecordStep records an object added to or removed from a funnel step
+func RecordStep(ctx context.Context, db *database.Queries, source string, actorID, objID, stepID uuid.UUID, action string) error {
+	return RecordFrom(ctx, db, source, actorID, StepEntry(ctx, db, objID, stepID, action))
+}
+
+// RecordStepMove records an object moved into stepID. current holds the
+// steps of the funnel the object was in before, nothing is recorded when it
+// was already in stepID.
+func RecordStepMove(ctx context.Context, db *database.Queries, source string, actorID, objID, stepID uuid.UUID, current []database.ListObjectStepsInFunnelRow) error {
+	var from []string
+	for _, step := range current {
+		if step.StepID == stepID {
+			return nil
+		}
+		from = append(from, step.StepName)
+	}
+	entry := StepEntry(ctx, db, objID, stepID, ActionStepAdded)
+	if len(from) > 0 {
+		entry.Action = ActionStepMoved
+		entry.Changes = []Change{{Field: "step", From: strings.Join(from, ", "), To: entry.Details["stepName"]}}
+	}
+	return RecordFrom(ctx, db, source, actorID, entry)
+}
+
+// StepEntry is an entry about a funnel step, with the step and funnel names
+// at the time of the change
+func StepEntry(ctx context.Context, db *database.Queries, objID, stepID uuid.UUID, action string) Entry {
+	details := map[string]interface{}{"stepId": stepID}
+	if step, err := db.GetStep(ctx, stepID); err == nil {
+		details["stepName"] = step.Name
+		details["funnelId"] = step.FunnelID
+		details["funnelName"] = step.FunnelName
+	}
+	return Entry{ObjID: objID, Action: action, Details: details}
+}
+
+// RecordAliases records the aliases SyncObjectAliases added to an object
+func RecordAliases(ctx context.Context, db *database.Queries, actorID uuid.UUID, row database.SyncObjectAliasesRow) error {
+	return Record(ctx, db, actorID, Entry{
+		ObjID:   row.ID,
+		Action:  ActionUpdated,
+		Changes: DiffObject(database.Obj{Aliases: row.OldAliases}, database.Obj{Aliases: row.Aliases}),
+	})
+}
@@ -0,0 +1,313 @@
+package maildrop
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/service"
+	"github.com/crea8r/muninn/server/internal/watch"
+	"github.com/google/uuid"
+)
+
+// FactSource is the fact_source.source of facts made from emails, keyed by
+// Message-ID
+const FactSource = "email"
+
+// Ingester makes facts of messages
+type Ingester struct {
+	cfg         Config
+	queries     *database.Queries
+	db          *sql.DB
+	attachments *service.AttachmentService
+}
+
+func NewIngester(cfg Config, queries *database.Queries, db *sql.DB, attachments *service.AttachmentService) *Ingester {
+	return &Ingester{cfg: cfg, queries: queries, db: db, attachments: attachments}
+}
+
+// Address is the drop-box address of a token
+func (ig *Ingester) Address(token string) string {
+	return ig.cfg.Address(token)
+}
+
+// Options says whose a message is
+type Options struct {
+	OrgID uuid.UUID
+	// CreatorID creates the fact, the member whose drop-box received the
+	// message or who imported it
+	CreatorID uuid.UUID
+	// HistorySource is the source of the history of the facts created
+	HistorySource string
+	// Exclude lists addresses, lower case, neither linked nor reported as
+	// unmatched, such as the drop-boxes of the org
+	Exclude []string
+}
+
+// Result is what became of a message
+type Result struct {
+	FactID uuid.UUID
+	// Duplicate is set when the message was already logged, FactID is then
+	// the fact it was logged as
+	Duplicate bool
+	// Linked lists the objects the fact was linked to
+	Linked []uuid.UUID
+	// Unmatched lists the addresses of the message matching neither an
+	// object nor a member of the org
+	Unmatched []string
+}
+
+// Accept checks that address is a drop-box, for Server.Accept
+func (ig *Ingester) Accept(ctx context.Context, address string) error {
+	token, ok := ig.cfg.Token(address)
+	if !ok {
+		return ErrUnknownRecipient
+	}
+	_, err := ig.queries.GetMailDropboxByToken(ctx, token)
+	if err == sql.ErrNoRows {
+		return ErrUnknownRecipient
+	}
+	return err
+}
+
+// Deliver logs a message received by the drop-boxes of its recipients, once
+// per org as a fact of the member of the first drop-box, for Server.Deliver
+func (ig *Ingester) Deliver(ctx context.Context, env Envelope) error {
+	m, err := Parse(bytes.NewReader(env.Data))
+	if err != nil {
+		// Sending it again would not help, it is dropped
+		log.Printf("Dropping unreadable mail from %s: %v", env.From, err)
+		return nil
+	}
+	done := map[uuid.UUID]bool{}
+	for _, to := range env.To {
+		token, ok := ig.cfg.Token(to)
+		if !ok {
+			continue
+		}
+		dropbox, err := ig.queries.GetMailDropboxByToken(ctx, token)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if done[dropbox.OrgID] {
+			continue
+		}
+		done[dropbox.OrgID] = true
+
+		tokens, err := ig.queries.ListMailDropboxTokens(ctx, dropbox.OrgID)
+		if err != nil {
+			return err
+		}
+		exclude := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			exclude = append(exclude, strings.ToLower(ig.cfg.Address(t)))
+		}
+		result, err := ig.Ingest(ctx, m, Options{
+			OrgID:         dropbox.OrgID,
+			CreatorID:     dropbox.CreatorID,
+			HistorySource: history.SourceEmail,
+			Exclude:       exclude,
+		})
+		if err != nil {
+			return fmt.Errorf("error logging message %s: %w", m.MessageID, err)
+		}
+		if !result.Duplicate {
+			log.Printf("Logged message %s as fact %s, linked to %d objects", m.MessageID, result.FactID, len(result.Linked))
+		}
+	}
+	return nil
+}
+
+// Ingest makes a fact of kind email of a message in the org, linked to the
+// objects matching its addresses, unless it was already logged. The fact is
+// created by opts.CreatorID: the From: header of a message can be forged, it
+// only tells an outbound email, sent by a member, from an inbound one.
+func (ig *Ingester) Ingest(ctx context.Context, m *Message, opts Options) (Result, error) {
+	excluded := map[string]bool{}
+	for _, address := range opts.Exclude {
+		excluded[address] = true
+	}
+	var addresses []string
+	for _, address := range m.Addresses() {
+		if !excluded[address] {
+			addresses = append(addresses, address)
+		}
+	}
+
+	existing, err := ig.queries.GetFactSource(ctx, database.GetFactSourceParams{
+		OrgID:      opts.OrgID,
+		Source:     FactSource,
+		ExternalID: m.MessageID,
+	})
+	if err == nil {
+		return Result{FactID: existing, Duplicate: true}, nil
+	}
+	if err != sql.ErrNoRows {
+		return Result{}, err
+	}
+
+	// Members are neither linked nor unmatched
+	members := map[string]uuid.UUID{}
+	for _, address := range addresses {
+		id, err := ig.queries.FindCreatorByEmail(ctx, database.FindCreatorByEmailParams{
+			OrgID:   opts.OrgID,
+			Column2: address,
+		})
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		members[address] = id
+	}
+	creatorID, direction := opts.CreatorID, "inbound"
+	if m.From != nil {
+		if _, ok := members[strings.ToLower(m.From.Address)]; ok {
+			direction = "outbound"
+		}
+	}
+
+	var result Result
+	var objectIDs []uuid.UUID
+	if len(addresses) > 0 {
+		objects, err := ig.queries.ListObjectsByEmail(ctx, database.ListObjectsByEmailParams{
+			OrgID:   opts.OrgID,
+			Column2: addresses,
+		})
+		if err != nil {
+			return Result{}, err
+		}
+		matched := map[string]bool{}
+		linked := map[uuid.UUID]bool{}
+		for _, o := range objects {
+			matched[o.Email] = true
+			if !linked[o.ID] {
+				linked[o.ID] = true
+				objectIDs = append(objectIDs, o.ID)
+			}
+		}
+		for _, address := range addresses {
+			if !matched[address] && members[address] == uuid.Nil {
+				result.Unmatched = append(result.Unmatched, address)
+			}
+		}
+	}
+
+	kind, err := factkind.Get(factkind.Email)
+	if err != nil {
+		return Result{}, err
+	}
+	raw, _ := json.Marshal(map[string]string{"direction": direction, "subject": m.Subject})
+	metadata, err := kind.Validate(raw)
+	if err != nil {
+		// A subject too long for the schema is not worth losing the email
+		raw, _ = json.Marshal(map[string]string{"direction": direction})
+		if metadata, err = kind.Validate(raw); err != nil {
+			return Result{}, err
+		}
+	}
+	happenedAt := m.Date
+	if happenedAt.IsZero() {
+		happenedAt = time.Now()
+	}
+
+	tx, err := ig.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+	qtx := ig.queries.WithTx(tx)
+
+	fact, err := qtx.CreateFact(ctx, database.CreateFactParams{
+		Text:       factText(m),
+		HappenedAt: sql.NullTime{Time: happenedAt, Valid: true},
+		CreatorID:  creatorID,
+		Kind:       factkind.Email,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if len(objectIDs) > 0 {
+		if err := qtx.AddObjectsToFact(ctx, database.AddObjectsToFactParams{
+			Column1: objectIDs,
+			FactID:  fact.ID,
+			OrgID:   opts.OrgID,
+		}); err != nil {
+			return Result{}, err
+		}
+	}
+	n, err := qtx.CreateFactSource(ctx, database.CreateFactSourceParams{
+		OrgID:      opts.OrgID,
+		Source:     FactSource,
+		ExternalID: m.MessageID,
+		FactID:     fact.ID,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if n == 0 {
+		// Logged meanwhile by another delivery of the same message
+		tx.Rollback()
+		existing, err := ig.queries.GetFactSource(ctx, database.GetFactSourceParams{
+			OrgID:      opts.OrgID,
+			Source:     FactSource,
+			ExternalID: m.MessageID,
+		})
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{FactID: existing, Duplicate: true}, nil
+	}
+	if err := history.RecordFactFrom(ctx, qtx, opts.HistorySource, creatorID, fact.ID, history.ActionCreated); err != nil {
+		return Result{}, err
+	}
+	if err := watch.Notify(ctx, qtx, creatorID, watch.Event{
+		Kind:    watch.EventFact,
+		Action:  watch.ActionCreated,
+		ObjIDs:  objectIDs,
+		RefID:   fact.ID,
+		Summary: fact.Text,
+	}); err != nil {
+		return Result{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Result{}, err
+	}
+	result.FactID = fact.ID
+	result.Linked = objectIDs
+
+	for _, a := range m.Attachments {
+		if _, err := ig.attachments.Upload(ctx, service.AttachmentTarget{FactID: fact.ID}, opts.OrgID, creatorID, a.Filename, bytes.NewReader(a.Data)); err != nil {
+			log.Printf("Error attaching %s to fact %s: %v", a.Filename, fact.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// factText is the subject of a message followed by the excerpt of its body
+func factText(m *Message) string {
+	excerpt := m.Excerpt()
+	switch {
+	case m.Subject == "" && excerpt == "":
+		return "(no subject)"
+	case m.Subject == "":
+		return excerpt
+	case excerpt == "":
+		return m.Subject
+	default:
+		return m.Subject + "\n\n" + excerpt
+	}
+}
@@ -0,0 +1,84 @@
+// Package maildrop logs emails as facts.
+//
+// Each member has a drop-box address, <token>@<MAIL_DOMAIN>. They bcc or
+// forward their emails to it, and a small SMTP server (see Server) receives
+// them: each message becomes a fact of kind email of the member, linked to
+// the objects whose aliases or email fields match its sender and
+// recipients, with its attachments. A message is logged once per org, by
+// Message-ID.
+//
+// The token authenticates the member: the From: header of a message is
+// never trusted to tell who logs it. The server runs inside the API when
+// SMTP_ADDR is set, or on its own with cmd/maildrop. It has no TLS and
+// relays nothing, so put it behind the MX of MAIL_DOMAIN. To try it locally:
+//
+//	SMTP_ADDR=:2525 go run ./cmd/api
+//	swaks --server localhost:2525 --to <token>@localhost --attach file.pdf
+package maildrop
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config is the drop-box configuration read from the environment:
+//
+//	SMTP_ADDR          address the SMTP server listens on, off when empty
+//	MAIL_DOMAIN        domain of the drop-box addresses, "localhost" by default
+//	MAIL_MAX_SIZE_MB   largest message accepted, 25 MB by default
+type Config struct {
+	Addr    string
+	Domain  string
+	MaxSize int64
+}
+
+const defaultMaxSizeMB = 25
+
+// ConfigFromEnv reads the drop-box configuration
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Addr:    os.Getenv("SMTP_ADDR"),
+		Domain:  strings.ToLower(os.Getenv("MAIL_DOMAIN")),
+		MaxSize: defaultMaxSizeMB << 20,
+	}
+	if cfg.Domain == "" {
+		cfg.Domain = "localhost"
+	}
+	if size := os.Getenv("MAIL_MAX_SIZE_MB"); size != "" {
+		mb, err := strconv.Atoi(size)
+		if err != nil || mb < 1 {
+			return Config{}, fmt.Errorf("MAIL_MAX_SIZE_MB must be a positive number of megabytes")
+		}
+		cfg.MaxSize = int64(mb) << 20
+	}
+	return cfg, nil
+}
+
+// Address is the drop-box address of a token
+func (c Config) Address(token string) string {
+	return token + "@" + c.Domain
+}
+
+// Token returns the token of a drop-box address, false for an address of
+// another domain
+func (c Config) Token(address string) (string, bool) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || !strings.EqualFold(address[at+1:], c.Domain) {
+		return "", false
+	}
+	return strings.ToLower(address[:at]), true
+}
+
+// NewToken returns a random drop-box token, hard to guess as anyone knowing
+// it can log facts as its member
+func NewToken() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
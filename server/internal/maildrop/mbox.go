@@ -0,0 +1,50 @@
+package maildrop
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// escapedFrom matches the body lines mbox writers escape with ">" so they
+// are not taken for the start of a message
+var escapedFrom = regexp.MustCompile(`^>+From `)
+
+// IsMbox reports whether data looks like an mbox archive rather than a
+// single message: it starts with a "From " separator line
+func IsMbox(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("From "))
+}
+
+// SplitMbox returns the raw messages of an mbox archive. A message starts at
+// each "From " line, which is dropped, and one ">" is removed from the
+// escaped ">From " lines of the bodies (mboxrd).
+func SplitMbox(data []byte) [][]byte {
+	var messages [][]byte
+	var current []byte
+	started := false
+	flush := func() {
+		if started {
+			messages = append(messages, bytes.TrimRight(current, "\r\n"))
+		}
+		current = nil
+	}
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i+1], data[i+1:]
+		} else {
+			data = nil
+		}
+		if bytes.HasPrefix(line, []byte("From ")) {
+			flush()
+			started = true
+			continue
+		}
+		if escapedFrom.Match(line) {
+			line = line[1:]
+		}
+		current = append(current, line...)
+	}
+	flush()
+	return messages
+}
@@ -0,0 +1,77 @@
+package maildrop
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsMbox(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"From jane@example.com Fri Jan  5 09:00:00 2024\nSubject: a\n", true},
+		{"From: jane@example.com\nSubject: a\n", false},
+		{"Subject: a\n\nFrom here\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsMbox([]byte(tt.data)); got != tt.want {
+			t.Errorf("IsMbox(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMbox(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: nil,
+		},
+		{
+			name: "messages",
+			data: "From a@example.com Fri Jan  5 09:00:00 2024\n" +
+				"Subject: one\n\nFirst\n\n" +
+				"From b@example.com Fri Jan  5 10:00:00 2024\r\n" +
+				"Subject: two\r\n\r\nSecond\r\n",
+			want: []string{
+				"Subject: one\n\nFirst",
+				"Subject: two\r\n\r\nSecond",
+			},
+		},
+		{
+			name: "escaped from lines",
+			data: "From a@example.com Fri Jan  5 09:00:00 2024\n" +
+				"Subject: one\n\n>From the start\n>>From twice\n> From quoted\n",
+			want: []string{
+				"Subject: one\n\nFrom the start\n>From twice\n> From quoted",
+			},
+		},
+		{
+			name: "text before the first separator",
+			data: "garbage\nFrom a@example.com Fri Jan  5 09:00:00 2024\nSubject: one\n",
+			want: []string{"Subject: one"},
+		},
+		{
+			name: "no trailing newline",
+			data: "From a@example.com Fri Jan  5 09:00:00 2024\nSubject: one",
+			want: []string{"Subject: one"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range SplitMbox([]byte(tt.data)) {
+				got = append(got, string(m))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitMbox =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
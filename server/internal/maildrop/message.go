@@ -0,0 +1,289 @@
+package maildrop
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxExcerpt bounds the body text kept in the fact, in characters
+const maxExcerpt = 1000
+
+// maxParts bounds the MIME parts walked in a message
+const maxParts = 100
+
+// Message is what a fact is made of from an email
+type Message struct {
+	// MessageID identifies the message when deduplicating, a hash of the
+	// sender, date and subject for messages without a Message-ID
+	MessageID   string
+	From        *mail.Address
+	To          []*mail.Address
+	Cc          []*mail.Address
+	Subject     string
+	Date        time.Time
+	Text        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Addresses returns the lower cased addresses of the sender and recipients,
+// each once
+func (m *Message) Addresses() []string {
+	seen := map[string]bool{}
+	var addresses []string
+	add := func(list ...*mail.Address) {
+		for _, a := range list {
+			if a == nil {
+				continue
+			}
+			address := strings.ToLower(a.Address)
+			if address != "" && !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	add(m.From)
+	add(m.To...)
+	add(m.Cc...)
+	return addresses
+}
+
+// DisplayName returns the name given with an address of the message, empty
+// when there is none
+func (m *Message) DisplayName(address string) string {
+	for _, list := range [][]*mail.Address{{m.From}, m.To, m.Cc} {
+		for _, a := range list {
+			if a != nil && a.Name != "" && strings.EqualFold(a.Address, address) {
+				return a.Name
+			}
+		}
+	}
+	return ""
+}
+
+// Excerpt is the start of the body without quoted replies and signature
+func (m *Message) Excerpt() string {
+	var lines []string
+	for _, line := range strings.Split(m.Text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "--" || line == "-- " {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	excerpt := strings.TrimSpace(strings.Join(lines, "\n"))
+	if utf8.RuneCountInString(excerpt) > maxExcerpt {
+		excerpt = strings.TrimSpace(string([]rune(excerpt)[:maxExcerpt])) + "…"
+	}
+	return excerpt
+}
+
+// Parse reads a MIME message: its headers, the plain text of the body (the
+// HTML one stripped of tags when there is no plain text) and its
+// attachments
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	h := raw.Header
+	dec := new(mime.WordDecoder)
+	m := &Message{}
+	if m.Subject, err = dec.DecodeHeader(h.Get("Subject")); err != nil {
+		m.Subject = h.Get("Subject")
+	}
+	m.Subject = strings.TrimSpace(m.Subject)
+	if list, err := h.AddressList("From"); err == nil && len(list) > 0 {
+		m.From = list[0]
+	}
+	m.To, _ = h.AddressList("To")
+	m.Cc, _ = h.AddressList("Cc")
+	if date, err := h.Date(); err == nil {
+		m.Date = date
+	}
+
+	body := &bodyParts{}
+	if err := body.walk(h, raw.Body, 0); err != nil {
+		return nil, err
+	}
+	m.Text = body.plain
+	if m.Text == "" {
+		m.Text = htmlText(body.html)
+	}
+	m.Attachments = body.attachments
+
+	m.MessageID = strings.Trim(strings.TrimSpace(h.Get("Message-Id")), "<>")
+	if m.MessageID == "" {
+		sum := sha256.Sum256([]byte(h.Get("From") + "\n" + h.Get("Date") + "\n" + m.Subject))
+		m.MessageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return m, nil
+}
+
+// partHeader is the part of a header the body walk needs, mail.Header for
+// the message and textproto.MIMEHeader for its parts
+type partHeader interface {
+	Get(key string) string
+}
+
+type bodyParts struct {
+	plain       string
+	html        string
+	attachments []Attachment
+	parts       int
+}
+
+func (b *bodyParts) walk(h partHeader, r io.Reader, depth int) error {
+	b.parts++
+	if b.parts > maxParts || depth > 10 {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if err := b.walk(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), r))
+	if err != nil {
+		return fmt.Errorf("invalid message body: %w", err)
+	}
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if filename != "" {
+		if decoded, err := new(mime.WordDecoder).DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+	}
+
+	switch {
+	case disposition == "attachment" || filename != "" || !strings.HasPrefix(mediaType, "text/"):
+		if filename == "" {
+			filename = "attachment"
+			if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+				filename += exts[0]
+			}
+		}
+		b.attachments = append(b.attachments, Attachment{Filename: filename, ContentType: mediaType, Data: data})
+	case mediaType == "text/html":
+		if b.html == "" {
+			b.html = toUTF8(data, params["charset"])
+		}
+	default:
+		if b.plain == "" {
+			b.plain = strings.ReplaceAll(toUTF8(data, params["charset"]), "\r\n", "\n")
+		}
+	}
+	return nil
+}
+
+// decodeTransfer undoes the Content-Transfer-Encoding of a part. Parts read
+// by mime/multipart come with quoted-printable already decoded.
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+// base64Cleaner drops the line breaks and spaces base64 bodies are wrapped
+// with
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+// toUTF8 converts the Latin-1 family of charsets, the others are kept as
+// they are
+func toUTF8(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "us-ascii", "ascii":
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(bytes.ToValidUTF8(data, []byte("�")))
+	}
+}
+
+var (
+	htmlDrop  = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreak = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])[^>]*>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+	blankRuns = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+)
+
+// htmlText is the text of an HTML body
+func htmlText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = htmlDrop.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = blankRuns.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,328 @@
+package maildrop
+
+import (
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// message joins the lines of a raw message with CRLF
+func message(lines ...string) string {
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		subject     string
+		text        string
+		attachments []Attachment
+	}{
+		{
+			name: "plain text",
+			raw: message(
+				"Subject: =?UTF-8?B?Q2jDoG8gYuG6oW4=?=",
+				"",
+				"Hello,",
+				"see you soon",
+			),
+			subject: "Chào bạn",
+			text:    "Hello,\nsee you soon\n",
+		},
+		{
+			name: "quoted-printable",
+			raw: message(
+				"Subject: QP",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Transfer-Encoding: quoted-printable",
+				"",
+				"caf=C3=A9 au l=",
+				"ait",
+			),
+			subject: "QP",
+			text:    "café au lait\n",
+		},
+		{
+			name: "latin-1",
+			raw: message(
+				"Content-Type: text/plain; charset=iso-8859-1",
+				"",
+				"caf\xe9",
+			),
+			text: "café\n",
+		},
+		{
+			name: "invalid content type is text",
+			raw: message(
+				"Content-Type: ;;;",
+				"",
+				"plain",
+			),
+			text: "plain\n",
+		},
+		{
+			name: "alternative keeps the plain text",
+			raw: message(
+				`Content-Type: multipart/alternative; boundary="b1"`,
+				"",
+				"--b1",
+				"Content-Type: text/html",
+				"",
+				"<p>HTML</p>",
+				"--b1",
+				"Content-Type: text/plain",
+				"",
+				"Plain",
+				"--b1--",
+			),
+			text: "Plain",
+		},
+		{
+			name: "html only",
+			raw: message(
+				"Content-Type: text/html; charset=utf-8",
+				"",
+				"<html><head><title>T</title></head><body>",
+				"<script>alert(1)</script><p>Hi &amp; welcome</p><br>",
+				"",
+				"<div>Next</div></body></html>",
+			),
+			text: "Hi & welcome\n\nNext",
+		},
+		{
+			name: "attachments",
+			raw: message(
+				`Content-Type: multipart/mixed; boundary="outer"`,
+				"",
+				"--outer",
+				"Content-Type: text/plain",
+				"",
+				"See attached",
+				"--outer",
+				`Content-Type: application/pdf; name="ignored.pdf"`,
+				`Content-Disposition: attachment; filename="=?UTF-8?B?YsOhbyBjw6FvLnBkZg==?="`,
+				"Content-Transfer-Encoding: base64",
+				"",
+				"JVBERi0xLjQgZmFrZSBkb2N1bWVu",
+				"dCBib2R5IGZvciB0aGUgdGVzdA==",
+				"--outer",
+				"Content-Type: image/png",
+				"",
+				"PNG",
+				"--outer",
+				"Content-Type: text/csv; name=data.csv",
+				"",
+				"a,b",
+				"--outer--",
+			),
+			text: "See attached",
+			attachments: []Attachment{
+				{Filename: "báo cáo.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4 fake document body for the test")},
+				{Filename: "attachment.png", ContentType: "image/png", Data: []byte("PNG")},
+				{Filename: "data.csv", ContentType: "text/csv", Data: []byte("a,b")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(strings.NewReader(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if m.Subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", m.Subject, tt.subject)
+			}
+			if m.Text != tt.text {
+				t.Errorf("Text = %q, want %q", m.Text, tt.text)
+			}
+			if !reflect.DeepEqual(m.Attachments, tt.attachments) {
+				t.Errorf("Attachments = %+v, want %+v", m.Attachments, tt.attachments)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	raw := message(
+		`From: "Jane Doe" <Jane@Example.com>`,
+		"To: bob@example.com, Ann <ann@example.com>",
+		"Cc: jane@example.com, Carl <carl@example.com>",
+		"Subject:  Intro ",
+		"Date: Fri, 05 Jan 2024 09:00:00 +0700",
+		"Message-Id: <abc@example.com>",
+		"",
+		"Body",
+	)
+	m, err := Parse(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if m.MessageID != "abc@example.com" {
+		t.Errorf("MessageID = %q", m.MessageID)
+	}
+	if m.Subject != "Intro" {
+		t.Errorf("Subject = %q", m.Subject)
+	}
+	if !m.Date.Equal(time.Date(2024, 1, 5, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v", m.Date)
+	}
+	if m.From == nil || *m.From != (mail.Address{Name: "Jane Doe", Address: "Jane@Example.com"}) {
+		t.Errorf("From = %+v", m.From)
+	}
+	want := []string{"jane@example.com", "bob@example.com", "ann@example.com", "carl@example.com"}
+	if got := m.Addresses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Addresses = %v, want %v", got, want)
+	}
+	names := map[string]string{
+		"JANE@example.com": "Jane Doe",
+		"ann@example.com":  "Ann",
+		"carl@example.com": "Carl",
+		"bob@example.com":  "",
+		"eve@example.com":  "",
+	}
+	for address, name := range names {
+		if got := m.DisplayName(address); got != name {
+			t.Errorf("DisplayName(%q) = %q, want %q", address, got, name)
+		}
+	}
+}
+
+func TestParseMessageIDFallback(t *testing.T) {
+	raw := func(subject string) string {
+		return message(
+			"From: jane@example.com",
+			"Date: Fri, 05 Jan 2024 09:00:00 +0700",
+			"Subject: "+subject,
+			"",
+			"Body",
+		)
+	}
+	first, err := Parse(strings.NewReader(raw("Intro")))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	again, _ := Parse(strings.NewReader(raw("Intro")))
+	other, _ := Parse(strings.NewReader(raw("Follow-up")))
+	if !strings.HasPrefix(first.MessageID, "sha256:") || len(first.MessageID) != len("sha256:")+64 {
+		t.Errorf("MessageID = %q, want a sha256 hash", first.MessageID)
+	}
+	if first.MessageID != again.MessageID {
+		t.Errorf("MessageID of the same message = %q and %q", first.MessageID, again.MessageID)
+	}
+	if first.MessageID == other.MessageID {
+		t.Errorf("MessageID of another subject = %q", other.MessageID)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		err  string
+	}{
+		{
+			name: "no header",
+			raw:  "just a line\r\n",
+			err:  "invalid message: ",
+		},
+		{
+			name: "unterminated multipart",
+			raw: message(
+				`Content-Type: multipart/mixed; boundary="b"`,
+				"",
+				"--b",
+				"Content-Type: text/plain",
+				"",
+				"never closed",
+			),
+			err: "invalid message body: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.raw))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("Parse error = %v, want %q...", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("é", maxExcerpt+10)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "quoted reply",
+			text: "Thanks!\n\n> On Monday Jane wrote:\n> hello\n",
+			want: "Thanks!",
+		},
+		{
+			name: "signature",
+			text: "See you\n-- \nJane\nCEO",
+			want: "See you",
+		},
+		{
+			name: "blank lines",
+			text: "\n\nFirst\n\n\n\nSecond  \n",
+			want: "First\n\nSecond",
+		},
+		{
+			name: "long text",
+			text: long,
+			want: strings.Repeat("é", maxExcerpt) + "…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Message{Text: tt.text}
+			if got := m.Excerpt(); got != tt.want {
+				t.Errorf("Excerpt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLText(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"<p>One</p><p>Two</p>", "One\nTwo"},
+		{"a<BR/>b", "a\nb"},
+		{"<style>p { color: red }</style>Text", "Text"},
+		{"<ul><li>x</li><li>y</li></ul>", "x\ny"},
+		{"&lt;tag&gt; &quot;q&quot;", `<tag> "q"`},
+		{"<div>a</div>\n \n\n<div>b</div>", "a\n\nb"},
+		{"<p>a</p>\r\n\r\n<p>b</p>", "a\n\nb"},
+	}
+	for _, tt := range tests {
+		if got := htmlText(tt.html); got != tt.want {
+			t.Errorf("htmlText(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		data    string
+		charset string
+		want    string
+	}{
+		{"caf\xe9", "ISO-8859-1", "café"},
+		{"café", "iso-8859-1", "café"},
+		{"caf\xe9", "utf-8", "caf�"},
+		{"caf\xe9", "", "caf�"},
+	}
+	for _, tt := range tests {
+		if got := toUTF8([]byte(tt.data), tt.charset); got != tt.want {
+			t.Errorf("toUTF8(%q, %q) = %q, want %q", tt.data, tt.charset, got, tt.want)
+		}
+	}
+}
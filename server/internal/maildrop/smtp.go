@@ -0,0 +1,392 @@
+package maildrop
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecipients bounds the RCPT TO of a message, as RFC 5321 asks servers
+// to accept at least this many
+const maxRecipients = 100
+
+// maxLineLength bounds a command line, as the text lines of RFC 5321 are
+// 1000 bytes at most with their CRLF
+const maxLineLength = 1000
+
+// defaultMaxConns bounds the sessions served at once when Server.MaxConns
+// is not set
+const defaultMaxConns = 100
+
+const (
+	// commandTimeout bounds the wait for the next command and its reply,
+	// and for the data of a message
+	commandTimeout = 5 * time.Minute
+	// sessionTimeout bounds a whole session, so a client cannot keep a
+	// connection by sending a command now and then
+	sessionTimeout = 30 * time.Minute
+	// deliverTimeout bounds checking a recipient and storing a message
+	deliverTimeout = 2 * time.Minute
+)
+
+var (
+	// ErrUnknownRecipient is returned by Accept for an address that is no
+	// drop-box, the server answers 550 as it relays nothing
+	ErrUnknownRecipient = errors.New("no such mailbox")
+	// ErrServerClosed is returned by Serve once Close was called
+	ErrServerClosed = errors.New("maildrop: server closed")
+)
+
+// Envelope is a message received: the envelope sender and recipients and
+// the raw message
+type Envelope struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Server is a minimal SMTP server receiving mail for drop-boxes. It speaks
+// enough of RFC 5321 for mail servers and tools like swaks to deliver
+// messages, without TLS nor SMTP authentication: it accepts mail for its
+// drop-boxes only, whose secret addresses say whose the mail is, and relays
+// nothing.
+type Server struct {
+	Addr string
+	// Hostname is announced in the greeting
+	Hostname string
+	// MaxSize bounds a message, in bytes
+	MaxSize int64
+	// MaxConns bounds the sessions served at once, defaultMaxConns when 0.
+	// Connections over it are answered 421 and closed.
+	MaxConns int
+	// Accept checks a recipient, returning ErrUnknownRecipient for one that
+	// is not a drop-box
+	Accept func(ctx context.Context, address string) error
+	// Deliver handles a message received. An error is answered as a
+	// temporary failure so the sender tries again later.
+	Deliver func(ctx context.Context, env Envelope) error
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// ListenAndServe listens on Addr and serves connections until Close
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves the connections of l until Close
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.conns = map[net.Conn]bool{}
+	s.mu.Unlock()
+
+	maxConns := s.MaxConns
+	if maxConns <= 0 {
+		maxConns = defaultMaxConns
+	}
+	sem := make(chan struct{}, maxConns)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.wg.Done()
+			select {
+			case sem <- struct{}{}:
+				s.serveConn(conn)
+				<-sem
+			default:
+				conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				fmt.Fprintf(conn, "421 4.3.2 %s Too many connections, try again later\r\n", s.Hostname)
+				conn.Close()
+			}
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops listening and waits for the sessions under way to end, up to
+// the deadline of ctx after which their connections are closed
+func (s *Server) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		<-done
+	}
+	return err
+}
+
+// session is the state of an SMTP conversation
+type session struct {
+	helo string
+	from string
+	to   []string
+}
+
+func (s *session) reset() {
+	s.from = ""
+	s.to = nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	// The buffer is the longest line read, a longer one ends the session
+	br := bufio.NewReaderSize(conn, maxLineLength)
+	tr := textproto.NewReader(br)
+	tw := textproto.NewWriter(bufio.NewWriter(conn))
+	end := time.Now().Add(sessionTimeout)
+	// deadline is the one of a command, never past the end of the session
+	deadline := func(d time.Duration) time.Time {
+		if t := time.Now().Add(d); t.Before(end) {
+			return t
+		}
+		return end
+	}
+	reply := func(code int, msg string) bool {
+		conn.SetWriteDeadline(deadline(commandTimeout))
+		return tw.PrintfLine("%d %s", code, msg) == nil
+	}
+	// call runs Accept or Deliver within deliverTimeout
+	call := func(f func(ctx context.Context) error) error {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline(deliverTimeout))
+		defer cancel()
+		return f(ctx)
+	}
+
+	if !reply(220, s.Hostname+" ESMTP muninn maildrop") {
+		return
+	}
+	var sess session
+	for {
+		conn.SetReadDeadline(deadline(commandTimeout))
+		line, err := readLine(br)
+		if err == bufio.ErrBufferFull {
+			reply(500, "5.5.6 Line too long")
+			return
+		}
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess.helo = arg
+			sess.reset()
+			reply(250, s.Hostname)
+		case "EHLO":
+			sess.helo = arg
+			sess.reset()
+			conn.SetWriteDeadline(deadline(commandTimeout))
+			tw.PrintfLine("250-%s", s.Hostname)
+			tw.PrintfLine("250-8BITMIME")
+			tw.PrintfLine("250-SIZE %d", s.MaxSize)
+			tw.PrintfLine("250 PIPELINING")
+		case "MAIL":
+			if sess.helo == "" {
+				reply(503, "5.5.1 Send HELO or EHLO first")
+				continue
+			}
+			if sess.from != "" {
+				reply(503, "5.5.1 Sender already given")
+				continue
+			}
+			from, params, ok := pathArg(arg, "FROM:")
+			if !ok {
+				reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			if size := sizeParam(params); size > s.MaxSize {
+				reply(552, "5.3.4 Message too big")
+				continue
+			}
+			// The null reverse-path of bounces is kept as "<>"
+			if from == "" {
+				from = "<>"
+			}
+			sess.from = from
+			reply(250, "2.1.0 OK")
+		case "RCPT":
+			if sess.from == "" {
+				reply(503, "5.5.1 Send MAIL first")
+				continue
+			}
+			to, _, ok := pathArg(arg, "TO:")
+			if !ok || to == "" {
+				reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			if len(sess.to) >= maxRecipients {
+				reply(452, "4.5.3 Too many recipients")
+				continue
+			}
+			err := call(func(ctx context.Context) error { return s.Accept(ctx, to) })
+			if err != nil {
+				if errors.Is(err, ErrUnknownRecipient) {
+					reply(550, "5.1.1 No such mailbox")
+				} else {
+					log.Printf("Error checking mail recipient %s: %v", to, err)
+					reply(451, "4.3.0 Try again later")
+				}
+				continue
+			}
+			sess.to = append(sess.to, to)
+			reply(250, "2.1.5 OK")
+		case "DATA":
+			if len(sess.to) == 0 {
+				reply(503, "5.5.1 Send RCPT first")
+				continue
+			}
+			if !reply(354, "End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			conn.SetReadDeadline(deadline(commandTimeout))
+			dr := tr.DotReader()
+			data, err := io.ReadAll(io.LimitReader(dr, s.MaxSize+1))
+			if err != nil {
+				return
+			}
+			if int64(len(data)) > s.MaxSize {
+				// Drain the rest so the conversation can go on
+				if _, err := io.Copy(io.Discard, dr); err != nil {
+					return
+				}
+				reply(552, "5.3.4 Message too big")
+				sess.reset()
+				continue
+			}
+			env := Envelope{From: sess.from, To: sess.to, Data: data}
+			sess.reset()
+			if err := call(func(ctx context.Context) error { return s.Deliver(ctx, env) }); err != nil {
+				log.Printf("Error delivering mail from %s: %v", env.From, err)
+				reply(451, "4.3.0 Failed to store the message, try again later")
+				continue
+			}
+			reply(250, "2.0.0 OK")
+		case "RSET":
+			sess.reset()
+			reply(250, "2.0.0 OK")
+		case "NOOP":
+			reply(250, "2.0.0 OK")
+		case "VRFY":
+			reply(252, "2.5.2 Cannot verify, send the message")
+		case "QUIT":
+			reply(221, "2.0.0 Bye")
+			return
+		default:
+			reply(502, "5.5.2 Command not implemented")
+		}
+	}
+}
+
+// readLine reads a line without its CRLF, bufio.ErrBufferFull when it is
+// longer than the buffer of br
+func readLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// pathArg parses the "FROM:<address> PARAMS" argument of MAIL, and the TO:
+// one of RCPT. The address is empty for the null path "<>".
+func pathArg(arg, prefix string) (string, string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", "", false
+	}
+	end := strings.Index(arg, ">")
+	if end < 0 {
+		return "", "", false
+	}
+	path, params := arg[1:end], strings.TrimSpace(arg[end+1:])
+	// Source routes, @a,@b:user@domain, are ignored as RFC 5321 asks
+	if i := strings.LastIndex(path, ":"); i >= 0 && strings.HasPrefix(path, "@") {
+		path = path[i+1:]
+	}
+	if path == "" {
+		return "", params, true
+	}
+	if _, err := mail.ParseAddress(path); err != nil {
+		return "", "", false
+	}
+	return path, params, true
+}
+
+// sizeParam reads the SIZE= parameter of MAIL, 0 when there is none
+func sizeParam(params string) int64 {
+	for _, p := range strings.Fields(params) {
+		key, value, _ := strings.Cut(p, "=")
+		if strings.EqualFold(key, "SIZE") {
+			var size int64
+			fmt.Sscan(value, &size)
+			return size
+		}
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID          uuid.UUID  `json:"id"`
+	FactID      *uuid.UUID `json:"factId,omitempty"`
+	ObjectID    *uuid.UUID `json:"objectId,omitempty"`
+	CreatorID   *uuid.UUID `json:"creatorId"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	URL         string     `json:"url"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+// NewAttachment is the API view of an attachment, URL is where its file is
+// downloaded
+func NewAttachment(row database.Attachment) Attachment {
+	return Attachment{
+		ID:          row.ID,
+		FactID:      nullUUIDPtr(row.FactID),
+		ObjectID:    nullUUIDPtr(row.ObjID),
+		CreatorID:   nullUUIDPtr(row.CreatorID),
+		Filename:    row.Filename,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		URL:         "/attachments/" + row.ID.String(),
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+// FactAttachments returns the attachments of the facts, by fact
+func FactAttachments(ctx context.Context, db *database.Queries, orgID uuid.UUID, factIDs []uuid.UUID) (map[uuid.UUID][]Attachment, error) {
+	byFact := map[uuid.UUID][]Attachment{}
+	if len(factIDs) == 0 {
+		return byFact, nil
+	}
+	rows, err := db.ListAttachmentsByFactIDs(ctx, database.ListAttachmentsByFactIDsParams{
+		Column1: factIDs,
+		OrgID:   orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		byFact[row.FactID.UUID] = append(byFact[row.FactID.UUID], NewAttachment(row))
+	}
+	return byFact, nil
+}
+
+// ObjectAttachments returns the files attached to the object itself
+func ObjectAttachments(ctx context.Context, db *database.Queries, orgID, objectID uuid.UUID) ([]Attachment, error) {
+	rows, err := db.ListAttachmentsByObject(ctx, database.ListAttachmentsByObjectParams{
+		ObjID: uuid.NullUUID{UUID: objectID, Valid: true},
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]Attachment, len(rows))
+	for i, row := range rows {
+		attachments[i] = NewAttachment(row)
+	}
+	return attachments, nil
+}
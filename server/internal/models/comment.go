@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// FactComment is a comment on a fact with the replies to it, oldest first
+type FactComment struct {
+	ID          uuid.UUID     `json:"id"`
+	FactID      uuid.UUID     `json:"factId"`
+	ParentID    *uuid.UUID    `json:"parentId,omitempty"`
+	CreatorID   uuid.UUID     `json:"creatorId"`
+	CreatorName string        `json:"creatorName"`
+	Text        string        `json:"text"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	LastUpdated time.Time     `json:"lastUpdated"`
+	Replies     []FactComment `json:"replies"`
+}
+
+// FactReaction is an emoji and the creators who reacted with it
+type FactReaction struct {
+	Emoji      string      `json:"emoji"`
+	Count      int64       `json:"count"`
+	CreatorIDs []uuid.UUID `json:"creatorIds"`
+}
+
+// FactComments returns the comment threads of the facts, by fact
+func FactComments(ctx context.Context, db *database.Queries, orgID uuid.UUID, factIDs []uuid.UUID) (map[uuid.UUID][]FactComment, error) {
+	byFact := map[uuid.UUID][]FactComment{}
+	if len(factIDs) == 0 {
+		return byFact, nil
+	}
+	rows, err := db.ListFactComments(ctx, database.ListFactCommentsParams{
+		Column1: factIDs,
+		OrgID:   orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows come oldest first, so do replies under each comment
+	ids := map[uuid.UUID]bool{}
+	for _, row := range rows {
+		ids[row.ID] = true
+	}
+	replies := map[uuid.UUID][]database.ListFactCommentsRow{}
+	var roots []database.ListFactCommentsRow
+	for _, row := range rows {
+		if row.ParentID.Valid && ids[row.ParentID.UUID] {
+			replies[row.ParentID.UUID] = append(replies[row.ParentID.UUID], row)
+		} else {
+			roots = append(roots, row)
+		}
+	}
+	var thread func(row database.ListFactCommentsRow) FactComment
+	thread = func(row database.ListFactCommentsRow) FactComment {
+		comment := FactComment{
+			ID:          row.ID,
+			FactID:      row.FactID,
+			ParentID:    nullUUIDPtr(row.ParentID),
+			CreatorID:   row.CreatorID,
+			CreatorName: row.CreatorName,
+			Text:        row.Text,
+			CreatedAt:   row.CreatedAt,
+			LastUpdated: row.LastUpdated,
+			Replies:     []FactComment{},
+		}
+		for _, reply := range replies[row.ID] {
+			comment.Replies = append(comment.Replies, thread(reply))
+		}
+		return comment
+	}
+	for _, row := range roots {
+		byFact[row.FactID] = append(byFact[row.FactID], thread(row))
+	}
+	return byFact, nil
+}
+
+// FactReactions returns the reactions to the facts, by fact
+func FactReactions(ctx context.Context, db *database.Queries, orgID uuid.UUID, factIDs []uuid.UUID) (map[uuid.UUID][]FactReaction, error) {
+	byFact := map[uuid.UUID][]FactReaction{}
+	if len(factIDs) == 0 {
+		return byFact, nil
+	}
+	rows, err := db.ListFactReactions(ctx, database.ListFactReactionsParams{
+		Column1: factIDs,
+		OrgID:   orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		byFact[row.FactID] = append(byFact[row.FactID], FactReaction{
+			Emoji:      row.Emoji,
+			Count:      row.Count,
+			CreatorIDs: row.CreatorIds,
+		})
+	}
+	return byFact, nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DefaultFuzzyThreshold is the trigram similarity from which a search
+// matches an object by its name, aliases, id_string or contact values. Low
+// enough for swapped letters: "Jonh Smtih" is about 0.22 from "john smith".
+const DefaultFuzzyThreshold = 0.2
+
+// FuzzyThresholdFromEnv reads the fuzzy search threshold from
+// FUZZY_SEARCH_THRESHOLD, DefaultFuzzyThreshold when it is not set
+func FuzzyThresholdFromEnv() (float64, error) {
+	value := os.Getenv("FUZZY_SEARCH_THRESHOLD")
+	if value == "" {
+		return DefaultFuzzyThreshold, nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || !ValidFuzzyThreshold(threshold) {
+		return 0, fmt.Errorf("FUZZY_SEARCH_THRESHOLD must be a number greater than 0 and at most 1")
+	}
+	return threshold, nil
+}
+
+// ValidFuzzyThreshold tells if a threshold is a similarity in (0, 1], 0
+// would match every object
+func ValidFuzzyThreshold(threshold float64) bool {
+	return threshold > 0 && threshold <= 1
+}
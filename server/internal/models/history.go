@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+type ObjectHistoryEntry struct {
+	ID          uuid.UUID              `json:"id"`
+	Action      string                 `json:"action"`
+	Source      string                 `json:"source"`
+	CreatorID   uuid.UUID              `json:"creatorId"`
+	CreatorName string                 `json:"creatorName"`
+	TypeID      *uuid.UUID             `json:"typeId,omitempty"`
+	Changes     []history.Change       `json:"changes"`
+	Details     map[string]interface{} `json:"details"`
+	Redacted    []string               `json:"redacted,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+}
+
+// History lists the changes of an object, newest first. Type value changes
+// to fields the viewer may not read are left out and named in Redacted.
+func (m *ObjectModel) History(ctx context.Context, id, orgID uuid.UUID, limit, offset int32, viewer schema.Viewer) ([]ObjectHistoryEntry, int64, error) {
+	rows, err := m.DB.ListObjectHistory(ctx, database.ListObjectHistoryParams{
+		ObjID:  id,
+		OrgID:  orgID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	schemas := map[uuid.UUID]schema.Schema{}
+	entries := make([]ObjectHistoryEntry, 0, len(rows))
+	var total int64
+	for _, row := range rows {
+		total = row.TotalCount
+		entry := ObjectHistoryEntry{
+			ID:          row.ID,
+			Action:      row.Action,
+			Source:      row.Source,
+			CreatorID:   row.CreatorID,
+			CreatorName: row.CreatorName,
+			Changes:     []history.Change{},
+			Details:     map[string]interface{}{},
+			CreatedAt:   row.CreatedAt,
+		}
+		json.Unmarshal(row.Changes, &entry.Changes)
+		json.Unmarshal(row.Details, &entry.Details)
+		if row.TypeID.Valid {
+			typeID := row.TypeID.UUID
+			entry.TypeID = &typeID
+			if !viewer.IsAdmin() {
+				typeSchema, ok := schemas[typeID]
+				if !ok {
+					// A type that cannot be loaded any more has no restricted fields
+					typeSchema, _ = schema.Load(ctx, m.DB, typeID)
+					schemas[typeID] = typeSchema
+				}
+				entry.Changes, entry.Redacted = redactChanges(typeSchema, entry.Changes, viewer)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, nil
+}
+
+func redactChanges(typeSchema schema.Schema, changes []history.Change, viewer schema.Viewer) ([]history.Change, []string) {
+	if !typeSchema.HasRestricted() {
+		return changes, nil
+	}
+	var redacted []string
+	visible := make([]history.Change, 0, len(changes))
+	for _, change := range changes {
+		if !typeSchema.CanRead(change.Field, viewer) {
+			redacted = append(redacted, change.Field)
+			continue
+		}
+		visible = append(visible, change)
+	}
+	return visible, redacted
+}
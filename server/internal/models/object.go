@@ -2,15 +2,23 @@ package models
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/crea8r/muninn/server/pkg/ctype"
 	"github.com/google/uuid"
 )
 
+// ErrVersionConflict is returned by updates made against a version of an
+// object or a type value that is no longer the current one
+var ErrVersionConflict = errors.New("changed by someone else since it was read")
+
 type Object struct {
 	ID          uuid.UUID         `json:"id"`
 	Name        string            `json:"name"`
@@ -18,6 +26,7 @@ type Object struct {
 	IDString    string            `json:"idString"`
 	CreatorID   uuid.UUID         `json:"creatorId"`
 	CreatedAt   time.Time         `json:"createdAt"`
+	Version     int32             `json:"version"`
 	DeletedAt   ctype.NullTime    `json:"-"`
 	Tags        []database.Tag    `json:"tags"`
 	TypeValues  []ObjectTypeValue `json:"typeValues"`
@@ -35,6 +44,7 @@ type ListObjectsByOrgIdRow struct {
 	FactHeadline      string      `json:"factHeadline"`
 	TypeValueHeadline string      `json:"typeValueHeadline"`
 	SearchRank        float64     `json:"searchRank"`
+	Similarity        float64     `json:"similarity"`
 	Tags              interface{} `json:"tags"`
 	TypeValues        interface{} `json:"typeValues"`
 }
@@ -46,10 +56,15 @@ type ObjectTypeValue struct {
 	ObjectTypeIcon   string                 `json:"objectTypeIcon"`
 	ObjectTypeFields map[string]interface{} `json:"objectTypeFields"`
 	TypeValues       map[string]interface{} `json:"type_values"`
+	Version          int32                  `json:"version"`
+	Computed         map[string]interface{} `json:"computed,omitempty"`
+	// Redacted lists the restricted fields hidden from the viewer
+	Redacted []string `json:"redacted,omitempty"`
 }
 
 type ObjectModel struct {
-	DB *database.Queries
+	DB    *database.Queries
+	sqlDB *sql.DB
 }
 
 type ObjectDetail struct {
@@ -59,12 +74,29 @@ type ObjectDetail struct {
 	IDString        string            `json:"idString"`
 	CreatorID       uuid.UUID         `json:"creatorId"`
 	CreatedAt       time.Time         `json:"createdAt"`
+	Version         int32             `json:"version"`
 	Tags            []database.Tag    `json:"tags"`
 	TypeValues      []ObjectTypeValue `json:"typeValues"`
 	Tasks           []Task            `json:"tasks"`
 	StepsAndFunnels []StepAndFunnel   `json:"stepsAndFunnels"`
 	Facts           []Fact            `json:"facts"`
 	Aliases         []string          `json:"aliases"`
+	Relations       []ObjectRelation  `json:"relations"`
+	Attachments     []Attachment      `json:"attachments"`
+}
+
+// ObjectRelation is a relation seen from one object. Direction is "outgoing"
+// when the object is the source of the relation and "incoming" otherwise.
+type ObjectRelation struct {
+	ID             uuid.UUID       `json:"id"`
+	RelationType   string          `json:"relationType"`
+	Direction      string          `json:"direction"`
+	Metadata       json.RawMessage `json:"metadata"`
+	ObjectID       uuid.UUID       `json:"objectId"`
+	ObjectName     string          `json:"objectName"`
+	ObjectPhoto    string          `json:"objectPhoto"`
+	ObjectIDString string          `json:"objectIdString"`
+	CreatedAt      time.Time       `json:"createdAt"`
 }
 
 type Task struct {
@@ -89,23 +121,69 @@ type StepAndFunnel struct {
 }
 
 type Fact struct {
-	ID         uuid.UUID      `json:"id"`
-	Text       string         `json:"text"`
-	HappenedAt ctype.NullTime `json:"happenedAt"`
-	Location   string         `json:"location"`
-	CreatedAt  time.Time      `json:"createdAt"`
+	ID          uuid.UUID      `json:"id"`
+	Text        string         `json:"text"`
+	HappenedAt  ctype.NullTime `json:"happenedAt"`
+	Location    string         `json:"location"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	Attachments []Attachment   `json:"attachments"`
+	Comments    []FactComment  `json:"comments"`
+	Reactions   []FactReaction `json:"reactions"`
 }
 
-func NewObjectModel(db *database.Queries) *ObjectModel {
-	return &ObjectModel{DB: db}
+// NewObjectModel returns a model writing through db. A nil sqlDB is for db
+// bound to a transaction of the caller, writes then run in it.
+func NewObjectModel(db *database.Queries, sqlDB *sql.DB) *ObjectModel {
+	return &ObjectModel{DB: db, sqlDB: sqlDB}
+}
+
+// inTx runs fn in a transaction, so that a write and its history are saved
+// together or not at all
+func (m *ObjectModel) inTx(ctx context.Context, opts *sql.TxOptions, fn func(q *database.Queries) error) error {
+	if m.sqlDB == nil {
+		return fn(m.DB)
+	}
+	tx, err := m.sqlDB.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(m.DB.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withFuzzyThreshold runs fn in a read only transaction in which the trigram
+// indexes return the objects with a similarity of at least threshold, see
+// obj_fuzzy_matches
+func (m *ObjectModel) withFuzzyThreshold(ctx context.Context, threshold float64, fn func(q *database.Queries) error) error {
+	return m.inTx(ctx, &sql.TxOptions{ReadOnly: true}, func(q *database.Queries) error {
+		if err := q.SetFuzzyThreshold(ctx, threshold); err != nil {
+			return err
+		}
+		return fn(q)
+	})
 }
 
 func (m *ObjectModel) Create(ctx context.Context, name, description, idString string, creatorID uuid.UUID) (*Object, error) {
-	obj, err := m.DB.CreateObject(ctx, database.CreateObjectParams{
-		Name:        name,
-		Description: description,
-		IDString:    idString,
-		CreatorID:   creatorID,
+	var obj database.Obj
+	err := m.inTx(ctx, nil, func(q *database.Queries) error {
+		var err error
+		obj, err = q.CreateObject(ctx, database.CreateObjectParams{
+			Name:        name,
+			Description: description,
+			IDString:    idString,
+			CreatorID:   creatorID,
+		})
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, creatorID, history.Entry{
+			ObjID:   obj.ID,
+			Action:  history.ActionCreated,
+			Changes: history.DiffObject(database.Obj{}, obj),
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -118,16 +196,44 @@ func (m *ObjectModel) Create(ctx context.Context, name, description, idString st
 		IDString:    obj.IDString,
 		CreatorID:   obj.CreatorID,
 		CreatedAt:   obj.CreatedAt,
+		Version:     obj.Version,
 	}, nil
 }
 
-func (m *ObjectModel) Update(ctx context.Context, id uuid.UUID, name, description, idString string, aliases []string) (*Object, error) {
-	obj, err := m.DB.UpdateObject(ctx, database.UpdateObjectParams{
-		ID:          id,
-		Name:        name,
-		Description: description,
-		IDString:    idString,
-		Aliases:     aliases,
+// Update overwrites the fields of an object. With a version other than 0 it
+// only does so if the object is still at that version, and returns
+// ErrVersionConflict otherwise.
+func (m *ObjectModel) Update(ctx context.Context, id uuid.UUID, name, description, idString string, aliases []string, actorID uuid.UUID, version int32) (*Object, error) {
+	before, err := m.DB.GetObjectByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && before.Version != version {
+		return nil, ErrVersionConflict
+	}
+	var obj database.Obj
+	err = m.inTx(ctx, nil, func(q *database.Queries) error {
+		var err error
+		obj, err = q.UpdateObject(ctx, database.UpdateObjectParams{
+			ID:          id,
+			Name:        name,
+			Description: description,
+			IDString:    idString,
+			Aliases:     aliases,
+			Column6:     version,
+		})
+		if err == sql.ErrNoRows {
+			// Changed between the read and the update
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   id,
+			Action:  history.ActionUpdated,
+			Changes: history.DiffObject(before, obj),
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -140,27 +246,74 @@ func (m *ObjectModel) Update(ctx context.Context, id uuid.UUID, name, descriptio
 		IDString:    obj.IDString,
 		CreatorID:   obj.CreatorID,
 		CreatedAt:   obj.CreatedAt,
+		Version:     obj.Version,
 	}, nil
 }
 
-func (m *ObjectModel) Delete(ctx context.Context, id uuid.UUID) error {
-	return m.DB.DeleteObject(ctx, id)
+func (m *ObjectModel) Delete(ctx context.Context, id, actorID uuid.UUID) error {
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		err := q.DeleteObject(ctx, database.DeleteObjectParams{
+			ID:        id,
+			DeletedBy: uuid.NullUUID{UUID: actorID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:  id,
+			Action: history.ActionDeleted,
+		})
+	})
+}
+
+// SetOwner makes ownerID the creator of the object
+func (m *ObjectModel) SetOwner(ctx context.Context, id, ownerID, actorID uuid.UUID) error {
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		previous, err := q.SetObjectOwner(ctx, database.SetObjectOwnerParams{
+			ID:        id,
+			CreatorID: ownerID,
+		})
+		if err != nil || previous == ownerID {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   id,
+			Action:  history.ActionUpdated,
+			Changes: []history.Change{{Field: "owner", From: previous, To: ownerID}},
+		})
+	})
 }
 
-func (m *ObjectModel) List(ctx context.Context, orgID uuid.UUID, search string, limit, offset int32) ([]ListObjectsByOrgIdRow, int64, error) {
-	objects, err := m.DB.ListObjectsByOrgID(ctx, database.ListObjectsByOrgIDParams{
-		OrgID:   orgID,
-		Column2: search,
-		Limit:   limit,
-		Offset:  offset,
+// List lists the objects of an org matching search, by full text or by a
+// trigram similarity of at least threshold, see DefaultFuzzyThreshold
+func (m *ObjectModel) List(ctx context.Context, orgID uuid.UUID, search string, threshold float64, limit, offset int32, viewer schema.Viewer) ([]ListObjectsByOrgIdRow, int64, error) {
+	var objects []database.ListObjectsByOrgIDRow
+	var count int64
+	err := m.withFuzzyThreshold(ctx, threshold, func(q *database.Queries) error {
+		var err error
+		objects, err = q.ListObjectsByOrgID(ctx, database.ListObjectsByOrgIDParams{
+			OrgID:   orgID,
+			Column2: search,
+			Limit:   limit,
+			Offset:  offset,
+			Column5: threshold,
+			Column6: string(viewer.Role),
+		})
+		if err != nil {
+			return err
+		}
+		count, err = q.CountObjectsByOrgID(ctx, database.CountObjectsByOrgIDParams{
+			OrgID:   orgID,
+			Column2: search,
+			Column3: threshold,
+			Column4: string(viewer.Role),
+		})
+		return err
 	})
 	if err != nil {
 		return nil, 0, err
 	}
-	count, err := m.DB.CountObjectsByOrgID(ctx, database.CountObjectsByOrgIDParams{
-		OrgID:   orgID,
-		Column2: search,
-	})
+	restricted, err := m.restrictedSchemas(ctx, orgID, viewer)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -232,6 +385,18 @@ func (m *ObjectModel) List(ctx context.Context, orgID uuid.UUID, search string,
 			typeValueHeadline = string(v)
 		}
 
+		// The headline may quote a restricted value
+		for j, tv := range typeValues {
+			typeSchema, ok := restricted[tv.ObjectTypeID]
+			if !ok {
+				continue
+			}
+			typeValues[j].TypeValues, typeValues[j].Redacted = typeSchema.Redact(tv.TypeValues, viewer)
+			if len(typeValues[j].Redacted) > 0 {
+				typeValueHeadline = ""
+			}
+		}
+
 		result[i] = ListObjectsByOrgIdRow{
 			ID:                obj.ID,
 			Name:              obj.Name,
@@ -244,6 +409,7 @@ func (m *ObjectModel) List(ctx context.Context, orgID uuid.UUID, search string,
 			FactHeadline:      factHeadline,
 			TypeValueHeadline: typeValueHeadline,
 			SearchRank:        finalSearchRank,
+			Similarity:        obj.Similarity,
 			Tags:              tags,
 			TypeValues:        typeValues,
 		}
@@ -252,7 +418,33 @@ func (m *ObjectModel) List(ctx context.Context, orgID uuid.UUID, search string,
 	return result, count, nil
 }
 
-func (m *ObjectModel) GetDetails(ctx context.Context, id, orgID uuid.UUID) (*ObjectDetail, error) {
+// Suggest lists the objects a mention being typed may refer to, the ones
+// whose id_string or an alias starts with prefix first, then the ones with a
+// trigram similarity of at least threshold, not matching the contact values
+// the viewer may not read
+func (m *ObjectModel) Suggest(ctx context.Context, orgID uuid.UUID, prefix string, threshold float64, limit int32, viewer schema.Viewer) ([]database.SuggestObjectsRow, error) {
+	var suggestions []database.SuggestObjectsRow
+	err := m.withFuzzyThreshold(ctx, threshold, func(q *database.Queries) error {
+		var err error
+		suggestions, err = q.SuggestObjects(ctx, database.SuggestObjectsParams{
+			OrgID:   orgID,
+			Column2: prefix,
+			Column3: threshold,
+			Limit:   limit,
+			Column5: string(viewer.Role),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if suggestions == nil {
+		suggestions = []database.SuggestObjectsRow{}
+	}
+	return suggestions, nil
+}
+
+func (m *ObjectModel) GetDetails(ctx context.Context, id, orgID uuid.UUID, viewer schema.Viewer) (*ObjectDetail, error) {
 	data, err := m.DB.GetObjectDetails(ctx, database.GetObjectDetailsParams{
 		ID:    id,
 		OrgID: orgID,
@@ -422,6 +614,49 @@ func (m *ObjectModel) GetDetails(ctx context.Context, id, orgID uuid.UUID) (*Obj
 		}
 	*/
 
+	computeTypeValues(typeValues, detailAggregates(data.CreatedAt, facts, tasks))
+	redactTypeValues(typeValues, viewer)
+
+	relations, err := m.ListRelations(ctx, data.ID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	factIDs := make([]uuid.UUID, len(facts))
+	for i, fact := range facts {
+		factIDs[i] = fact.ID
+	}
+	factAttachments, err := FactAttachments(ctx, m.DB, orgID, factIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing fact attachments: %w", err)
+	}
+	factComments, err := FactComments(ctx, m.DB, orgID, factIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing fact comments: %w", err)
+	}
+	factReactions, err := FactReactions(ctx, m.DB, orgID, factIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing fact reactions: %w", err)
+	}
+	for i := range facts {
+		facts[i].Attachments = factAttachments[facts[i].ID]
+		if facts[i].Attachments == nil {
+			facts[i].Attachments = []Attachment{}
+		}
+		facts[i].Comments = factComments[facts[i].ID]
+		if facts[i].Comments == nil {
+			facts[i].Comments = []FactComment{}
+		}
+		facts[i].Reactions = factReactions[facts[i].ID]
+		if facts[i].Reactions == nil {
+			facts[i].Reactions = []FactReaction{}
+		}
+	}
+	attachments, err := ObjectAttachments(ctx, m.DB, orgID, data.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing attachments: %w", err)
+	}
+
 	return &ObjectDetail{
 		ID:              data.ID,
 		Name:            data.Name,
@@ -429,36 +664,185 @@ func (m *ObjectModel) GetDetails(ctx context.Context, id, orgID uuid.UUID) (*Obj
 		IDString:        data.IDString,
 		CreatorID:       data.CreatorID,
 		CreatedAt:       data.CreatedAt,
+		Version:         data.Version,
 		Tags:            tags,
 		TypeValues:      typeValues,
 		Tasks:           tasks,
 		StepsAndFunnels: stepsAndFunnels,
 		Facts:           facts,
 		Aliases:         data.Aliases,
+		Relations:       relations,
+		Attachments:     attachments,
 	}, nil
 }
 
-func (m *ObjectModel) AddTag(ctx context.Context, objectID, tagID, orgID uuid.UUID) error {
-	return m.DB.AddTagToObject(ctx, database.AddTagToObjectParams{
+func (m *ObjectModel) ListRelations(ctx context.Context, objectID, orgID uuid.UUID) ([]ObjectRelation, error) {
+	rows, err := m.DB.ListObjectRelations(ctx, database.ListObjectRelationsParams{
 		ObjID: objectID,
-		TagID: tagID,
 		OrgID: orgID,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing relations: %w", err)
+	}
+	relations := make([]ObjectRelation, len(rows))
+	for i, row := range rows {
+		relations[i] = ObjectRelation{
+			ID:             row.ID,
+			RelationType:   row.RelationType,
+			Direction:      row.Direction,
+			Metadata:       row.Metadata,
+			ObjectID:       row.ObjectID,
+			ObjectName:     row.ObjectName,
+			ObjectPhoto:    row.ObjectPhoto,
+			ObjectIDString: row.ObjectIDString,
+			CreatedAt:      row.CreatedAt,
+		}
+	}
+	return relations, nil
 }
 
-func (m *ObjectModel) RemoveTag(ctx context.Context, objectID, tagID, orgID uuid.UUID) error {
-	return m.DB.RemoveTagFromObject(ctx, database.RemoveTagFromObjectParams{
-		ObjID: objectID,
-		TagID: tagID,
-		OrgID: orgID,
+// detailAggregates builds the obj. values of computed fields from the facts
+// and tasks already loaded for the object detail
+func detailAggregates(createdAt time.Time, facts []Fact, tasks []Task) schema.Aggregates {
+	aggs := schema.Aggregates{CreatedAt: createdAt}
+	for i := range facts {
+		// Facts count from when they happened, or were logged without a date
+		at := facts[i].CreatedAt
+		if facts[i].HappenedAt.Valid {
+			at = facts[i].HappenedAt.Time
+		}
+		aggs.FactCount++
+		if aggs.FirstFactAt == nil || at.Before(*aggs.FirstFactAt) {
+			aggs.FirstFactAt = &at
+		}
+		if aggs.LastFactAt == nil || at.After(*aggs.LastFactAt) {
+			aggs.LastFactAt = &at
+		}
+	}
+	now := time.Now()
+	for _, task := range tasks {
+		if task.DeletedAt.Valid {
+			continue
+		}
+		aggs.TaskCount++
+		if task.Status != "completed" {
+			aggs.OpenTasks++
+			if task.Deadline.Valid && task.Deadline.Time.Before(now) {
+				aggs.OverdueTasks++
+			}
+		}
+	}
+	return aggs
+}
+
+// computeTypeValues evaluates the computed fields of each type value
+func computeTypeValues(typeValues []ObjectTypeValue, aggs schema.Aggregates) {
+	now := time.Now()
+	for i, tv := range typeValues {
+		raw, err := json.Marshal(tv.ObjectTypeFields)
+		if err != nil {
+			continue
+		}
+		typeSchema, err := schema.Parse(raw)
+		if err != nil || !typeSchema.HasComputed() {
+			continue
+		}
+		typeValues[i].Computed = typeSchema.Compute(tv.TypeValues, aggs, now)
+	}
+}
+
+// restrictedSchemas returns the schemas of the org's object types that hide
+// fields from the viewer, keyed by object type
+func (m *ObjectModel) restrictedSchemas(ctx context.Context, orgID uuid.UUID, viewer schema.Viewer) (map[uuid.UUID]schema.Schema, error) {
+	if viewer.IsAdmin() {
+		return nil, nil
+	}
+	rows, err := m.DB.ListObjectTypeFieldsByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	schemas := map[uuid.UUID]schema.Schema{}
+	for _, row := range rows {
+		typeSchema, err := schema.Parse(row.Fields)
+		if err == nil && typeSchema.HasRestricted() {
+			schemas[row.ID] = typeSchema
+		}
+	}
+	return schemas, nil
+}
+
+// redactTypeValues hides the restricted fields the viewer may not read,
+// computed values included
+func redactTypeValues(typeValues []ObjectTypeValue, viewer schema.Viewer) {
+	if viewer.IsAdmin() {
+		return
+	}
+	for i, tv := range typeValues {
+		raw, err := json.Marshal(tv.ObjectTypeFields)
+		if err != nil {
+			continue
+		}
+		typeSchema, err := schema.Parse(raw)
+		if err != nil || !typeSchema.HasRestricted() {
+			continue
+		}
+		typeValues[i].TypeValues, typeValues[i].Redacted = typeSchema.Redact(tv.TypeValues, viewer)
+		computed, redacted := typeSchema.Redact(tv.Computed, viewer)
+		typeValues[i].Computed = computed
+		typeValues[i].Redacted = append(typeValues[i].Redacted, redacted...)
+	}
+}
+
+func (m *ObjectModel) AddTag(ctx context.Context, objectID, tagID, orgID, actorID uuid.UUID) error {
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		rows, err := q.AddTagToObject(ctx, database.AddTagToObjectParams{
+			ObjID: objectID,
+			TagID: tagID,
+			OrgID: orgID,
+		})
+		if err != nil || rows == 0 {
+			return err
+		}
+		return history.RecordTag(ctx, q, history.SourceFrom(ctx), actorID, objectID, tagID, history.ActionTagAdded)
+	})
+}
+
+func (m *ObjectModel) RemoveTag(ctx context.Context, objectID, tagID, orgID, actorID uuid.UUID) error {
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		rows, err := q.RemoveTagFromObject(ctx, database.RemoveTagFromObjectParams{
+			ObjID: objectID,
+			TagID: tagID,
+			OrgID: orgID,
+		})
+		if err != nil || rows == 0 {
+			return err
+		}
+		return history.RecordTag(ctx, q, history.SourceFrom(ctx), actorID, objectID, tagID, history.ActionTagRemoved)
 	})
 }
 
-func (m *ObjectModel) AddObjectTypeValue(ctx context.Context, objectID, typeID uuid.UUID, values json.RawMessage, orgID uuid.UUID) (*ObjectTypeValue, error) {
-	result, err := m.DB.AddObjectTypeValue(ctx, database.AddObjectTypeValueParams{
-		ObjID:   objectID,
-		TypeID:  typeID,
-		Column3: values,
+func (m *ObjectModel) AddObjectTypeValue(ctx context.Context, objectID, typeID uuid.UUID, values json.RawMessage, orgID, actorID uuid.UUID, viewer schema.Viewer) (*ObjectTypeValue, error) {
+	values, typeSchema, err := m.validateTypeValues(ctx, orgID, objectID, typeID, values, nil, viewer)
+	if err != nil {
+		return nil, err
+	}
+	var result database.ObjTypeValue
+	err = m.inTx(ctx, nil, func(q *database.Queries) error {
+		var err error
+		result, err = q.AddObjectTypeValue(ctx, database.AddObjectTypeValueParams{
+			ObjID:   objectID,
+			TypeID:  typeID,
+			Column3: values,
+		})
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   objectID,
+			Action:  history.ActionTypeValueAdded,
+			TypeID:  typeID,
+			Changes: history.DiffValuesRaw(nil, result.TypeValues),
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -468,26 +852,91 @@ func (m *ObjectModel) AddObjectTypeValue(ctx context.Context, objectID, typeID u
 	if err != nil {
 		return nil, err
 	}
+	parsedValues, redacted := typeSchema.Redact(parsedValues, viewer)
 
 	return &ObjectTypeValue{
 		ID:           result.ID,
 		ObjectTypeID: result.TypeID,
 		TypeValues:   parsedValues,
+		Version:      result.Version,
+		Redacted:     redacted,
 	}, nil
 }
 
-func (m *ObjectModel) RemoveObjectTypeValue(ctx context.Context, typeValueID, orgID uuid.UUID) error {
-	return m.DB.RemoveObjectTypeValue(ctx, database.RemoveObjectTypeValueParams{
+func (m *ObjectModel) RemoveObjectTypeValue(ctx context.Context, typeValueID, orgID, actorID uuid.UUID, viewer schema.Viewer) error {
+	existing, err := m.DB.GetObjectTypeValueByID(ctx, database.GetObjectTypeValueByIDParams{
 		ID:    typeValueID,
 		OrgID: orgID,
 	})
+	if err != nil {
+		return err
+	}
+	if !viewer.IsAdmin() {
+		typeSchema, err := schema.Load(ctx, m.DB, existing.TypeID)
+		if err != nil {
+			return err
+		}
+		if err := typeSchema.CheckRemoveRaw(existing.TypeValues, viewer); err != nil {
+			return err
+		}
+	}
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		err := q.RemoveObjectTypeValue(ctx, database.RemoveObjectTypeValueParams{
+			ID:    typeValueID,
+			OrgID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   existing.ObjID,
+			Action:  history.ActionTypeValueRemoved,
+			TypeID:  existing.TypeID,
+			Changes: history.DiffValuesRaw(existing.TypeValues, nil),
+		})
+	})
 }
 
-func (m *ObjectModel) UpdateObjectTypeValue(ctx context.Context, typeValueID, orgID uuid.UUID, values json.RawMessage) (*ObjectTypeValue, error) {
-	result, err := m.DB.UpdateObjectTypeValue(ctx, database.UpdateObjectTypeValueParams{
-		ID:      typeValueID,
-		OrgID:   orgID,
-		Column3: values,
+// UpdateObjectTypeValue overwrites the values of a type value, only if it is
+// still at version when that is not 0, see Update
+func (m *ObjectModel) UpdateObjectTypeValue(ctx context.Context, typeValueID, orgID, actorID uuid.UUID, values json.RawMessage, viewer schema.Viewer, version int32) (*ObjectTypeValue, error) {
+	existing, err := m.DB.GetObjectTypeValueByID(ctx, database.GetObjectTypeValueByIDParams{
+		ID:    typeValueID,
+		OrgID: orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && existing.Version != version {
+		return nil, ErrVersionConflict
+	}
+	values, typeSchema, err := m.validateTypeValues(ctx, orgID, existing.ObjID, existing.TypeID, values, existing.TypeValues, viewer)
+	if err != nil {
+		return nil, err
+	}
+	// The stored values merged above must not have changed meanwhile,
+	// whatever version the caller asked for
+	var result database.ObjTypeValue
+	err = m.inTx(ctx, nil, func(q *database.Queries) error {
+		var err error
+		result, err = q.UpdateObjectTypeValue(ctx, database.UpdateObjectTypeValueParams{
+			ID:      typeValueID,
+			OrgID:   orgID,
+			Column3: values,
+			Column4: existing.Version,
+		})
+		if err == sql.ErrNoRows {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		return history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   existing.ObjID,
+			Action:  history.ActionTypeValueUpdated,
+			TypeID:  existing.TypeID,
+			Changes: history.DiffValuesRaw(existing.TypeValues, result.TypeValues),
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -498,14 +947,39 @@ func (m *ObjectModel) UpdateObjectTypeValue(ctx context.Context, typeValueID, or
 	if err != nil {
 		return nil, err
 	}
+	parsedValues, redacted := typeSchema.Redact(parsedValues, viewer)
 
 	return &ObjectTypeValue{
 		ID:           result.ID,
 		ObjectTypeID: result.TypeID,
 		TypeValues:   parsedValues,
+		Version:      result.Version,
+		Redacted:     redacted,
 	}, nil
 }
 
+// validateTypeValues checks values written by viewer against the field schema
+// of the object type, including unique fields used by other objects of the
+// org. Restricted fields the viewer cannot write keep their stored value, the
+// values to save are returned.
+func (m *ObjectModel) validateTypeValues(ctx context.Context, orgID, objectID, typeID uuid.UUID, values, stored json.RawMessage, viewer schema.Viewer) (json.RawMessage, schema.Schema, error) {
+	s, err := schema.Load(ctx, m.DB, typeID)
+	if err != nil {
+		return nil, s, err
+	}
+	if err := schema.CheckNotMigrating(ctx, m.DB, typeID); err != nil {
+		return nil, s, err
+	}
+	values, err = s.GuardWriteRaw(values, stored, viewer)
+	if err != nil {
+		return nil, s, err
+	}
+	if err := s.ValidateRawChange(values, stored); err != nil {
+		return nil, s, err
+	}
+	return values, s, s.CheckUniqueRaw(ctx, m.DB, orgID, typeID, values, objectID)
+}
+
 type ObjStep struct {
 	ID        uuid.UUID
 	ObjID     uuid.UUID
@@ -516,10 +990,26 @@ type ObjStep struct {
 }
 
 func (m *ObjectModel) CreateObjStep(ctx context.Context, objID, stepID, creatorID uuid.UUID) (*ObjStep, error) {
-	row, err := m.DB.CreateObjStep(ctx, database.CreateObjStepParams{
-		ObjID:     objID,
-		StepID:    stepID,
-		CreatorID: creatorID,
+	// Moving into a step leaves the other steps of its funnel
+	current, err := m.DB.ListObjectStepsInFunnel(ctx, database.ListObjectStepsInFunnelParams{
+		ObjID:  objID,
+		StepID: stepID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var row database.CreateObjStepRow
+	err = m.inTx(ctx, nil, func(q *database.Queries) error {
+		var err error
+		row, err = q.CreateObjStep(ctx, database.CreateObjStepParams{
+			ObjID:     objID,
+			StepID:    stepID,
+			CreatorID: creatorID,
+		})
+		if err != nil {
+			return err
+		}
+		return history.RecordStepMove(ctx, q, history.SourceFrom(ctx), creatorID, objID, stepID, current)
 	})
 	if err != nil {
 		return nil, err
@@ -537,12 +1027,17 @@ func (m *ObjectModel) CreateObjStep(ctx context.Context, objID, stepID, creatorI
 	}, nil
 }
 
-func (m *ObjectModel) SoftDeleteObjStep(ctx context.Context, id uuid.UUID) error {
-	return m.DB.SoftDeleteObjStep(ctx, id)
-}
-
-func (m *ObjectModel) HardDeleteObjStep(ctx context.Context, id uuid.UUID) error {
-	return m.DB.HardDeleteObjStep(ctx, id)
+func (m *ObjectModel) SoftDeleteObjStep(ctx context.Context, id, actorID uuid.UUID) error {
+	objStep, err := m.DB.GetObjStep(ctx, id)
+	if err != nil {
+		return err
+	}
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		if err := q.SoftDeleteObjStep(ctx, id); err != nil || objStep.DeletedAt.Valid {
+			return err
+		}
+		return history.RecordStep(ctx, q, history.SourceFrom(ctx), actorID, objStep.ObjID, objStep.StepID, history.ActionStepRemoved)
+	})
 }
 
 type ObjStepResponse struct {
@@ -576,9 +1071,21 @@ func (m *ObjectModel) GetObjStep(ctx context.Context, id uuid.UUID) (*ObjStepRes
 	}, nil
 }
 
-func (m *ObjectModel) UpdateObjStepSubStatus(ctx context.Context, id uuid.UUID, subStatus int32) error {
-	return m.DB.UpdateObjStepSubStatus(ctx, database.UpdateObjStepSubStatusParams{
-		ID:        id,
-		SubStatus: subStatus,
+func (m *ObjectModel) UpdateObjStepSubStatus(ctx context.Context, id uuid.UUID, subStatus int32, actorID uuid.UUID) error {
+	objStep, err := m.DB.GetObjStep(ctx, id)
+	if err != nil {
+		return err
+	}
+	return m.inTx(ctx, nil, func(q *database.Queries) error {
+		err := q.UpdateObjStepSubStatus(ctx, database.UpdateObjStepSubStatusParams{
+			ID:        id,
+			SubStatus: subStatus,
+		})
+		if err != nil || objStep.DeletedAt.Valid || objStep.SubStatus == subStatus {
+			return err
+		}
+		entry := history.StepEntry(ctx, q, objStep.ObjID, objStep.StepID, history.ActionSubStatusChanged)
+		entry.Changes = []history.Change{{Field: "sub_status", From: objStep.SubStatus, To: subStatus}}
+		return history.Record(ctx, q, actorID, entry)
 	})
 }
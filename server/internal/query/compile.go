@@ -0,0 +1,450 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/factkind"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+// Filter is a compiled query, in the terms of the advanced list filter
+type Filter struct {
+	Search string
+	// ExcludeSearch holds the negated words, the objects matching any of
+	// them by any of their texts are left out
+	ExcludeSearch  string
+	StepIDs        []uuid.UUID
+	TagIDs         []uuid.UUID
+	TypeIDs        []uuid.UUID
+	ExcludeStepIDs []uuid.UUID
+	ExcludeTagIDs  []uuid.UUID
+	ExcludeTypeIDs []uuid.UUID
+	// Criteria are type value criteria of a field each, all of them must
+	// match
+	Criteria []map[string]Criterion
+	// CreatedAfter is inclusive, CreatedBefore exclusive
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	FactKinds     []string
+
+	// terms holds the first term of each key, criteriaTerms the term of
+	// each criterion, for the errors of the callers
+	terms         map[string]Term
+	criteriaTerms []Term
+}
+
+// Criterion is a type value criterion. A comparison is encoded as
+// {"op": ">=", "value": "100"}, apart from the criteria saved as strings,
+// which match the values containing them even when they start with an
+// operator; a match is encoded as such a string.
+type Criterion struct {
+	Op    string
+	Value string
+}
+
+func (c Criterion) MarshalJSON() ([]byte, error) {
+	if c.Op == "" {
+		return json.Marshal(c.Value)
+	}
+	return json.Marshal(map[string]string{"op": c.Op, "value": c.Value})
+}
+
+// Uses reports whether the query filters on key, tag, step, type, kind or
+// created, to include objects
+func (f *Filter) Uses(key string) bool {
+	_, ok := f.terms[key]
+	return ok
+}
+
+// Errorf returns an *Error at the first term filtering on key
+func (f *Filter) Errorf(key string, format string, args ...interface{}) error {
+	term := f.terms[key]
+	var errs errorList
+	errs.add(term.Pos, term.End, format, args...)
+	return errs.err()
+}
+
+// MergeIDs returns the tag, step or type IDs of a filter set outside the
+// query, or the ones of the query. Filtering on the same key both ways is an
+// *Error: the names of either match any of them.
+func (f *Filter) MergeIDs(key string, ids []uuid.UUID) ([]uuid.UUID, error) {
+	var queryIDs []uuid.UUID
+	switch key {
+	case FieldTag:
+		queryIDs = f.TagIDs
+	case FieldStep:
+		queryIDs = f.StepIDs
+	case FieldType:
+		queryIDs = f.TypeIDs
+	}
+	if !f.Uses(key) {
+		return ids, nil
+	}
+	if len(ids) > 0 {
+		return nil, f.Errorf(key, "%s is also filtered outside the query, use one or the other", key)
+	}
+	return queryIDs, nil
+}
+
+// MergeSearch returns the full text search of a filter set outside the query
+// and the words of the query, all of which must match
+func (f *Filter) MergeSearch(search string) string {
+	search = strings.TrimSpace(search)
+	if search == "" || f.Search == "" {
+		return search + f.Search
+	}
+	return search + " " + f.Search
+}
+
+// CheckCriteria returns an *Error at the criteria beyond max, for the
+// filters that only take a few of them
+func (f *Filter) CheckCriteria(max int) error {
+	var errs errorList
+	for i := max; i < len(f.criteriaTerms); i++ {
+		term := f.criteriaTerms[i]
+		errs.add(term.Pos, term.End, "too many field criteria, the filter has room for %d", max)
+	}
+	return errs.err()
+}
+
+var amountRegex = regexp.MustCompile(`^(-?[0-9]+(?:\.[0-9]+)?)([kKmMbB])$`)
+
+var amountUnits = map[string]float64{"k": 1e3, "m": 1e6, "b": 1e9}
+
+// negatedOps is the criterion operator of each negated term operator
+var negatedOps = map[string]string{
+	OpMatch: "!=",
+	OpEq:    "!=",
+	OpGt:    "<=",
+	OpGte:   "<",
+	OpLt:    ">=",
+	OpLte:   ">",
+}
+
+// Compile parses a query and resolves the names it uses among the tags,
+// funnel steps, object types and fields of the org. Errors in the query are
+// returned as an *Error.
+func Compile(ctx context.Context, db *database.Queries, orgID uuid.UUID, text string) (*Filter, error) {
+	terms, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{
+		ctx:    ctx,
+		db:     db,
+		orgID:  orgID,
+		filter: &Filter{terms: map[string]Term{}},
+	}
+	if err := c.compile(terms); err != nil {
+		return nil, err
+	}
+	if err := c.errs.err(); err != nil {
+		return nil, err
+	}
+	return c.filter, nil
+}
+
+type compiler struct {
+	ctx    context.Context
+	db     *database.Queries
+	orgID  uuid.UUID
+	filter *Filter
+	errs   errorList
+	fields map[string]string
+}
+
+func (c *compiler) compile(terms []Term) error {
+	var search, excluded []string
+	named := map[string][]Term{}
+	for _, term := range terms {
+		switch term.Field {
+		case "":
+			word := term.Values[0].Text
+			if term.Values[0].Quoted {
+				word = `"` + word + `"`
+			}
+			// A negated word in the search would only be left out of the
+			// text it is matched against, not of the others
+			if term.Negated {
+				excluded = append(excluded, word)
+			} else {
+				search = append(search, word)
+			}
+		case FieldTag, FieldStep, FieldType:
+			if c.checkNames(term) {
+				named[term.Field] = append(named[term.Field], term)
+			}
+		case FieldKind:
+			c.kind(term)
+		case FieldCreated:
+			c.created(term)
+		default:
+			if err := c.criterion(term); err != nil {
+				return err
+			}
+		}
+	}
+	c.filter.Search = strings.Join(search, " ")
+	c.filter.ExcludeSearch = strings.Join(excluded, " or ")
+
+	f := c.filter
+	resolvers := []struct {
+		key              string
+		ids, excludedIDs *[]uuid.UUID
+		list             func([]string) (map[string][]uuid.UUID, error)
+	}{
+		{FieldTag, &f.TagIDs, &f.ExcludeTagIDs, c.tags},
+		{FieldStep, &f.StepIDs, &f.ExcludeStepIDs, c.steps},
+		{FieldType, &f.TypeIDs, &f.ExcludeTypeIDs, c.types},
+	}
+	for _, r := range resolvers {
+		terms := named[r.key]
+		if len(terms) == 0 {
+			continue
+		}
+		var names []string
+		for _, term := range terms {
+			for _, v := range term.Values {
+				names = append(names, strings.ToLower(v.Text))
+			}
+		}
+		byName, err := r.list(names)
+		if err != nil {
+			return fmt.Errorf("error resolving %s names: %w", r.key, err)
+		}
+		for _, term := range terms {
+			ids := r.ids
+			if term.Negated {
+				ids = r.excludedIDs
+			}
+			for _, v := range term.Values {
+				found, ok := byName[strings.ToLower(v.Text)]
+				if !ok {
+					c.errs.add(v.Pos, v.End, "unknown %s %q", r.key, v.Text)
+					continue
+				}
+				*ids = append(*ids, found...)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNames checks a tag, step or type term. The names of a term match any
+// of them, like the dropdowns of the list filter, so a second term to
+// include objects is refused rather than read as either.
+func (c *compiler) checkNames(term Term) bool {
+	if term.Op != OpMatch && term.Op != OpEq {
+		c.errs.add(term.Pos, term.End, "%s only takes %s:name", term.Field, term.Field)
+		return false
+	}
+	if term.Negated {
+		return true
+	}
+	if first, ok := c.filter.terms[term.Field]; ok {
+		c.errs.add(term.Pos, term.End, "%s is already filtered at position %d, list the names as %s:a,b to match any of them",
+			term.Field, first.Pos, term.Field)
+		return false
+	}
+	c.filter.terms[term.Field] = term
+	return true
+}
+
+func (c *compiler) kind(term Term) {
+	if term.Negated {
+		c.errs.add(term.Pos, term.End, "kind cannot be negated")
+		return
+	}
+	if term.Op != OpMatch && term.Op != OpEq {
+		c.errs.add(term.Pos, term.End, "kind only takes kind:name")
+		return
+	}
+	if first, ok := c.filter.terms[FieldKind]; ok {
+		c.errs.add(term.Pos, term.End, "kind is already filtered at position %d, list the kinds as kind:a,b to match any of them", first.Pos)
+		return
+	}
+	c.filter.terms[FieldKind] = term
+	for _, v := range term.Values {
+		name := strings.ToLower(v.Text)
+		if !factkind.Valid(name) {
+			c.errs.add(v.Pos, v.End, "unknown fact kind %q", v.Text)
+			continue
+		}
+		c.filter.FactKinds = append(c.filter.FactKinds, name)
+	}
+}
+
+// created narrows the creation dates to the period of the term
+func (c *compiler) created(term Term) {
+	if term.Negated {
+		c.errs.add(term.Pos, term.End, "created cannot be negated, use created:< or created:>= instead")
+		return
+	}
+	if len(term.Values) != 1 {
+		c.errs.add(term.Pos, term.End, "created takes a single date")
+		return
+	}
+	v := term.Values[0]
+	start, end, ok := period(v.Text)
+	if !ok {
+		c.errs.add(v.Pos, v.End, "invalid date %q, use YYYY, YYYY-MM or YYYY-MM-DD", v.Text)
+		return
+	}
+	if _, ok := c.filter.terms[FieldCreated]; !ok {
+		c.filter.terms[FieldCreated] = term
+	}
+	var after, before time.Time
+	switch term.Op {
+	case OpMatch, OpEq:
+		after, before = start, end
+	case OpGt:
+		after = end
+	case OpGte:
+		after = start
+	case OpLt:
+		before = start
+	case OpLte:
+		before = end
+	}
+	f := c.filter
+	if !after.IsZero() && (!f.CreatedAfter.Valid || after.After(f.CreatedAfter.Time)) {
+		f.CreatedAfter = sql.NullTime{Time: after, Valid: true}
+	}
+	if !before.IsZero() && (!f.CreatedBefore.Valid || before.Before(f.CreatedBefore.Time)) {
+		f.CreatedBefore = sql.NullTime{Time: before, Valid: true}
+	}
+}
+
+// period returns the year, month or day of a date, in UTC
+func period(text string) (time.Time, time.Time, bool) {
+	for _, p := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006", 1, 0, 0},
+		{"2006-01", 0, 1, 0},
+		{"2006-01-02", 0, 0, 1},
+	} {
+		if start, err := time.Parse(p.layout, text); err == nil {
+			return start, start.AddDate(p.years, p.months, p.days), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// criterion turns a field term into a type value criterion, see
+// schema.MatchCriterion for the operators
+func (c *compiler) criterion(term Term) error {
+	if c.fields == nil {
+		fields, err := c.loadFields()
+		if err != nil {
+			return err
+		}
+		c.fields = fields
+	}
+	field, ok := c.fields[strings.ToLower(term.Field)]
+	if !ok {
+		c.errs.add(term.Pos, term.Pos+len([]rune(term.Field)), "unknown field %q", term.Field)
+		return nil
+	}
+	if len(term.Values) != 1 {
+		c.errs.add(term.Pos, term.End, "%s takes a single value, quote it if it contains a comma", term.Field)
+		return nil
+	}
+	value := term.Values[0].Text
+	op := term.Op
+	if term.Negated {
+		op = negatedOps[op]
+	}
+	if op == OpMatch {
+		op = ""
+	} else {
+		value = amount(value)
+	}
+	c.filter.Criteria = append(c.filter.Criteria, map[string]Criterion{field: {Op: op, Value: value}})
+	c.filter.criteriaTerms = append(c.filter.criteriaTerms, term)
+	return nil
+}
+
+// amount expands the K, M and B suffixes of a number
+func amount(text string) string {
+	m := amountRegex.FindStringSubmatch(text)
+	if m == nil {
+		return text
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return text
+	}
+	return strconv.FormatFloat(n*amountUnits[strings.ToLower(m[2])], 'f', -1, 64)
+}
+
+// loadFields returns the names of the fields of the org's object types, by
+// lower-cased name
+func (c *compiler) loadFields() (map[string]string, error) {
+	rows, err := c.db.ListObjectTypeFieldsByOrg(c.ctx, c.orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading object type fields: %w", err)
+	}
+	fields := map[string]string{}
+	for _, row := range rows {
+		typeSchema, err := schema.Parse(row.Fields)
+		if err != nil {
+			continue
+		}
+		for name := range typeSchema.Fields {
+			fields[strings.ToLower(name)] = name
+		}
+	}
+	return fields, nil
+}
+
+func (c *compiler) tags(names []string) (map[string][]uuid.UUID, error) {
+	rows, err := c.db.ListTagsByNames(c.ctx, database.ListTagsByNamesParams{OrgID: c.orgID, Column2: names})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]uuid.UUID{}
+	for _, row := range rows {
+		name := strings.ToLower(row.Name)
+		byName[name] = append(byName[name], row.ID)
+	}
+	return byName, nil
+}
+
+func (c *compiler) steps(names []string) (map[string][]uuid.UUID, error) {
+	rows, err := c.db.ListStepsByNames(c.ctx, database.ListStepsByNamesParams{OrgID: c.orgID, Column2: names})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]uuid.UUID{}
+	for _, row := range rows {
+		name := strings.ToLower(row.Name)
+		byName[name] = append(byName[name], row.ID)
+	}
+	return byName, nil
+}
+
+func (c *compiler) types(names []string) (map[string][]uuid.UUID, error) {
+	rows, err := c.db.ListObjectTypesByNames(c.ctx, database.ListObjectTypesByNamesParams{OrgID: c.orgID, Column2: names})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]uuid.UUID{}
+	for _, row := range rows {
+		name := strings.ToLower(row.Name)
+		byName[name] = append(byName[name], row.ID)
+	}
+	return byName, nil
+}
@@ -0,0 +1,326 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// compileTerms compiles a query without a database: the fields are given
+// and the query must not name tags, steps or types
+func compileTerms(t *testing.T, text string, fields map[string]string) (*Filter, error) {
+	t.Helper()
+	terms, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", text, err)
+	}
+	c := &compiler{
+		ctx:    context.Background(),
+		fields: fields,
+		filter: &Filter{terms: map[string]Term{}},
+	}
+	if err := c.compile(terms); err != nil {
+		return nil, err
+	}
+	if err := c.errs.err(); err != nil {
+		return nil, err
+	}
+	return c.filter, nil
+}
+
+var testFields = map[string]string{"stage_size": "Stage_Size", "city": "city"}
+
+func date(year int, month time.Month, day int) sql.NullTime {
+	return sql.NullTime{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Valid: true}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		check func(t *testing.T, f *Filter)
+	}{
+		{
+			name: "words and phrases",
+			text: `solana "labs inc" -churned -"lost deal"`,
+			check: func(t *testing.T, f *Filter) {
+				if f.Search != `solana "labs inc"` {
+					t.Errorf("Search = %q", f.Search)
+				}
+				if f.ExcludeSearch != `churned or "lost deal"` {
+					t.Errorf("ExcludeSearch = %q", f.ExcludeSearch)
+				}
+			},
+		},
+		{
+			name: "fact kinds",
+			text: "kind:Meeting,call",
+			check: func(t *testing.T, f *Filter) {
+				if !reflect.DeepEqual(f.FactKinds, []string{"meeting", "call"}) {
+					t.Errorf("FactKinds = %v", f.FactKinds)
+				}
+				if !f.Uses(FieldKind) {
+					t.Error("Uses(kind) = false")
+				}
+			},
+		},
+		{
+			name: "created in a month",
+			text: "created:2024-02",
+			check: func(t *testing.T, f *Filter) {
+				if f.CreatedAfter != date(2024, 2, 1) || f.CreatedBefore != date(2024, 3, 1) {
+					t.Errorf("created = %v, %v", f.CreatedAfter, f.CreatedBefore)
+				}
+			},
+		},
+		{
+			name: "created after a year",
+			text: "created:>2023",
+			check: func(t *testing.T, f *Filter) {
+				if f.CreatedAfter != date(2024, 1, 1) || f.CreatedBefore.Valid {
+					t.Errorf("created = %v, %v", f.CreatedAfter, f.CreatedBefore)
+				}
+			},
+		},
+		{
+			name: "created ranges narrow each other",
+			text: "created>=2024-01-10 created<2024-02 created<=2024-01-20 created>2024-01-05",
+			check: func(t *testing.T, f *Filter) {
+				if f.CreatedAfter != date(2024, 1, 10) || f.CreatedBefore != date(2024, 1, 21) {
+					t.Errorf("created = %v, %v", f.CreatedAfter, f.CreatedBefore)
+				}
+			},
+		},
+		{
+			name: "criteria",
+			text: "stage_size>1.5M City:hanoi -city:paris -STAGE_SIZE>=2k",
+			check: func(t *testing.T, f *Filter) {
+				want := []map[string]Criterion{
+					{"Stage_Size": {Op: ">", Value: "1500000"}},
+					{"city": {Value: "hanoi"}},
+					{"city": {Op: "!=", Value: "paris"}},
+					{"Stage_Size": {Op: "<", Value: "2000"}},
+				}
+				if !reflect.DeepEqual(f.Criteria, want) {
+					t.Errorf("Criteria = %v, want %v", f.Criteria, want)
+				}
+			},
+		},
+		{
+			name: "matched values keep their suffix",
+			text: "city:1M",
+			check: func(t *testing.T, f *Filter) {
+				if got := f.Criteria[0]["city"]; got != (Criterion{Value: "1M"}) {
+					t.Errorf("criterion = %+v", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := compileTerms(t, tt.text, testFields)
+			if err != nil {
+				t.Fatalf("compile(%q) error: %v", tt.text, err)
+			}
+			tt.check(t, f)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		errors []PosError
+	}{
+		{
+			name:   "unknown field",
+			text:   "a nope:1",
+			errors: []PosError{{Pos: 2, End: 6, Message: `unknown field "nope"`}},
+		},
+		{
+			name:   "several values of a field",
+			text:   "city:a,b",
+			errors: []PosError{{Pos: 0, End: 8, Message: "city takes a single value, quote it if it contains a comma"}},
+		},
+		{
+			name:   "tag comparison",
+			text:   "tag>a",
+			errors: []PosError{{Pos: 0, End: 5, Message: "tag only takes tag:name"}},
+		},
+		{
+			name:   "unknown kind",
+			text:   "kind:meeting,lunch",
+			errors: []PosError{{Pos: 13, End: 18, Message: `unknown fact kind "lunch"`}},
+		},
+		{
+			name:   "negated kind",
+			text:   "-kind:call",
+			errors: []PosError{{Pos: 0, End: 10, Message: "kind cannot be negated"}},
+		},
+		{
+			name:   "kind comparison",
+			text:   "kind>call",
+			errors: []PosError{{Pos: 0, End: 9, Message: "kind only takes kind:name"}},
+		},
+		{
+			name: "second kind term",
+			text: "kind:call kind:note",
+			errors: []PosError{{Pos: 10, End: 19,
+				Message: "kind is already filtered at position 0, list the kinds as kind:a,b to match any of them"}},
+		},
+		{
+			name:   "negated created",
+			text:   "-created:2024",
+			errors: []PosError{{Pos: 0, End: 13, Message: "created cannot be negated, use created:< or created:>= instead"}},
+		},
+		{
+			name:   "several created dates",
+			text:   "created:2024,2025",
+			errors: []PosError{{Pos: 0, End: 17, Message: "created takes a single date"}},
+		},
+		{
+			name:   "invalid date",
+			text:   "created:2024-13",
+			errors: []PosError{{Pos: 8, End: 15, Message: `invalid date "2024-13", use YYYY, YYYY-MM or YYYY-MM-DD`}},
+		},
+		{
+			name: "every error is reported",
+			text: "nope:1 created:x",
+			errors: []PosError{
+				{Pos: 0, End: 4, Message: `unknown field "nope"`},
+				{Pos: 15, End: 16, Message: `invalid date "x", use YYYY, YYYY-MM or YYYY-MM-DD`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileTerms(t, tt.text, testFields)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("compile(%q) error = %v, want an *Error", tt.text, err)
+			}
+			if !reflect.DeepEqual(qerr.Errors, tt.errors) {
+				t.Errorf("compile(%q) errors =\n%+v\nwant\n%+v", tt.text, qerr.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"1M", "1000000"},
+		{"2.5k", "2500"},
+		{"-3B", "-3000000000"},
+		{"12", "12"},
+		{"1.5", "1.5"},
+		{"M", "M"},
+		{"1MB", "1MB"},
+		{"2024-01", "2024-01"},
+	}
+	for _, tt := range tests {
+		if got := amount(tt.text); got != tt.want {
+			t.Errorf("amount(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCriterionJSON(t *testing.T) {
+	tests := []struct {
+		criterion Criterion
+		want      string
+	}{
+		{Criterion{Value: ">5"}, `">5"`},
+		{Criterion{Op: ">=", Value: "100"}, `{"op":">=","value":"100"}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.criterion)
+		if err != nil {
+			t.Fatalf("Marshal(%+v) error: %v", tt.criterion, err)
+		}
+		var gotValue, wantValue interface{}
+		json.Unmarshal(got, &gotValue)
+		json.Unmarshal([]byte(tt.want), &wantValue)
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("Marshal(%+v) = %s, want %s", tt.criterion, got, tt.want)
+		}
+	}
+}
+
+func TestMergeSearch(t *testing.T) {
+	tests := []struct {
+		query  string
+		search string
+		want   string
+	}{
+		{"", "", ""},
+		{"solana", "", "solana"},
+		{"", " labs ", "labs"},
+		{"solana", "labs", "labs solana"},
+	}
+	for _, tt := range tests {
+		f, err := compileTerms(t, tt.query, nil)
+		if err != nil {
+			t.Fatalf("compile(%q) error: %v", tt.query, err)
+		}
+		if got := f.MergeSearch(tt.search); got != tt.want {
+			t.Errorf("MergeSearch(%q) of %q = %q, want %q", tt.search, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMergeIDs(t *testing.T) {
+	ids := []uuid.UUID{uuid.New()}
+	queryIDs := []uuid.UUID{uuid.New()}
+	used := &Filter{
+		TagIDs: queryIDs,
+		terms:  map[string]Term{FieldTag: {Field: FieldTag, Pos: 3, End: 10}},
+	}
+
+	if got, err := (&Filter{}).MergeIDs(FieldTag, ids); err != nil || !reflect.DeepEqual(got, ids) {
+		t.Errorf("MergeIDs without tag term = %v, %v", got, err)
+	}
+	if got, err := used.MergeIDs(FieldTag, nil); err != nil || !reflect.DeepEqual(got, queryIDs) {
+		t.Errorf("MergeIDs with tag term = %v, %v", got, err)
+	}
+	_, err := used.MergeIDs(FieldTag, ids)
+	var qerr *Error
+	if !errors.As(err, &qerr) {
+		t.Fatalf("MergeIDs both ways error = %v, want an *Error", err)
+	}
+	want := []PosError{{Pos: 3, End: 10, Message: "tag is also filtered outside the query, use one or the other"}}
+	if !reflect.DeepEqual(qerr.Errors, want) {
+		t.Errorf("MergeIDs both ways errors = %+v, want %+v", qerr.Errors, want)
+	}
+}
+
+func TestCheckCriteria(t *testing.T) {
+	f, err := compileTerms(t, "city:a stage_size>1 city:b", testFields)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if err := f.CheckCriteria(3); err != nil {
+		t.Errorf("CheckCriteria(3) = %v", err)
+	}
+	err = f.CheckCriteria(1)
+	var qerr *Error
+	if !errors.As(err, &qerr) {
+		t.Fatalf("CheckCriteria(1) = %v, want an *Error", err)
+	}
+	want := []PosError{
+		{Pos: 7, End: 19, Message: "too many field criteria, the filter has room for 1"},
+		{Pos: 20, End: 26, Message: "too many field criteria, the filter has room for 1"},
+	}
+	if !reflect.DeepEqual(qerr.Errors, want) {
+		t.Errorf("CheckCriteria(1) errors = %+v, want %+v", qerr.Errors, want)
+	}
+}
@@ -0,0 +1,149 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// listFilter is the filter_setting of a list, the same configuration as the
+// filter of automations
+type listFilter struct {
+	Search            string      `json:"search"`
+	TagIDs            []uuid.UUID `json:"tagIds"`
+	TypeIDs           []uuid.UUID `json:"typeIds"`
+	TypeValueCriteria *struct {
+		Criteria1 map[string]string `json:"criteria1"`
+		Criteria2 map[string]string `json:"criteria2"`
+		Criteria3 map[string]string `json:"criteria3"`
+	} `json:"typeValueCriteria"`
+	FunnelStepFilter *struct {
+		StepIDs     []uuid.UUID `json:"stepIds"`
+		SubStatuses []int32     `json:"subStatuses"`
+	} `json:"funnelStepFilter"`
+	// Query is a text query added to the filters above
+	Query string `json:"query"`
+}
+
+// ListMatch is the filter of a list as MatchListFilters reads it, nil
+// slices and times match everything
+type ListMatch struct {
+	ListID         uuid.UUID       `json:"list_id"`
+	Search         string          `json:"search"`
+	ExcludeSearch  string          `json:"exclude_search"`
+	StepIDs        []uuid.UUID     `json:"step_ids"`
+	TagIDs         []uuid.UUID     `json:"tag_ids"`
+	TypeIDs        []uuid.UUID     `json:"type_ids"`
+	Criteria1      json.RawMessage `json:"criteria1"`
+	Criteria2      json.RawMessage `json:"criteria2"`
+	Criteria3      json.RawMessage `json:"criteria3"`
+	SubStatuses    []int32         `json:"sub_statuses"`
+	ExcludeStepIDs []uuid.UUID     `json:"exclude_step_ids"`
+	ExcludeTagIDs  []uuid.UUID     `json:"exclude_tag_ids"`
+	ExcludeTypeIDs []uuid.UUID     `json:"exclude_type_ids"`
+	CreatedAfter   *time.Time      `json:"created_after"`
+	CreatedBefore  *time.Time      `json:"created_before"`
+	FactKinds      []string        `json:"fact_kinds"`
+}
+
+// CheckListFilter checks the text query of the filter_setting of a list.
+// Errors in the query are *Error.
+func CheckListFilter(ctx context.Context, db *database.Queries, orgID uuid.UUID, setting json.RawMessage) error {
+	var filter struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(setting, &filter); err != nil || filter.Query == "" {
+		return nil
+	}
+	_, err := CompileListFilter(ctx, db, orgID, setting)
+	return err
+}
+
+// CompileListFilter returns the filter of a list as MatchListFilters reads it
+func CompileListFilter(ctx context.Context, db *database.Queries, orgID uuid.UUID, setting json.RawMessage) (ListMatch, error) {
+	var filter listFilter
+	if err := json.Unmarshal(setting, &filter); err != nil {
+		return ListMatch{}, err
+	}
+	match := ListMatch{
+		Search:    filter.Search,
+		Criteria1: json.RawMessage("null"),
+		Criteria2: json.RawMessage("null"),
+		Criteria3: json.RawMessage("null"),
+	}
+	if len(filter.TagIDs) > 0 {
+		match.TagIDs = filter.TagIDs
+	}
+	if len(filter.TypeIDs) > 0 {
+		match.TypeIDs = filter.TypeIDs
+	}
+	if f := filter.FunnelStepFilter; f != nil {
+		if len(f.StepIDs) > 0 {
+			match.StepIDs = f.StepIDs
+		}
+		if len(f.SubStatuses) > 0 {
+			match.SubStatuses = f.SubStatuses
+		}
+	}
+	if c := filter.TypeValueCriteria; c != nil {
+		match.Criteria1 = criteria(c.Criteria1)
+		match.Criteria2 = criteria(c.Criteria2)
+		match.Criteria3 = criteria(c.Criteria3)
+	}
+	if filter.Query == "" {
+		return match, nil
+	}
+
+	q, err := Compile(ctx, db, orgID, filter.Query)
+	if err != nil {
+		return match, err
+	}
+	if match.StepIDs, err = q.MergeIDs(FieldStep, match.StepIDs); err != nil {
+		return match, err
+	}
+	if match.TagIDs, err = q.MergeIDs(FieldTag, match.TagIDs); err != nil {
+		return match, err
+	}
+	if match.TypeIDs, err = q.MergeIDs(FieldType, match.TypeIDs); err != nil {
+		return match, err
+	}
+	match.Search = q.MergeSearch(match.Search)
+	match.ExcludeSearch = q.ExcludeSearch
+	match.ExcludeStepIDs = q.ExcludeStepIDs
+	match.ExcludeTagIDs = q.ExcludeTagIDs
+	match.ExcludeTypeIDs = q.ExcludeTypeIDs
+	if q.CreatedAfter.Valid {
+		match.CreatedAfter = &q.CreatedAfter.Time
+	}
+	if q.CreatedBefore.Valid {
+		match.CreatedBefore = &q.CreatedBefore.Time
+	}
+	match.FactKinds = q.FactKinds
+
+	// The criteria of the query take the slots the list leaves empty
+	var free []*json.RawMessage
+	for _, slot := range []*json.RawMessage{&match.Criteria1, &match.Criteria2, &match.Criteria3} {
+		if string(*slot) == "null" {
+			free = append(free, slot)
+		}
+	}
+	if err := q.CheckCriteria(len(free)); err != nil {
+		return match, err
+	}
+	for i, criterion := range q.Criteria {
+		*free[i], _ = json.Marshal(criterion)
+	}
+	return match, nil
+}
+
+// criteria turns a {field, value} criteria of the webapp into {field: value}
+func criteria(c map[string]string) json.RawMessage {
+	if c["field"] == "" {
+		return json.RawMessage("null")
+	}
+	data, _ := json.Marshal(map[string]string{c["field"]: c["value"]})
+	return data
+}
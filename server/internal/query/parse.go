@@ -0,0 +1,270 @@
+// Package query parses the text queries of objects, a compact form of the
+// advanced list filters:
+//
+//	tag:vip step:"Negotiation" type:Investor stage_size>1M created:>2024-01 -tag:churned "solana"
+//
+// A query is a list of terms, all of which must match:
+//
+//	tag:a,b       objects with any of the tags, step: and type: alike
+//	kind:meeting  objects with facts of any of the kinds
+//	created:2024  objects created in 2024, or in a month (2024-01) or on a day
+//	              (2024-01-31); created:>2024-01 after January, also >=, < and <=
+//	field:value   objects with a type value containing value, field>1M compares
+//	              numbers (K, M and B suffixes) and dates, also >=, <, <= and =
+//	word "phrase" the full text search of the list
+//
+// A leading - negates a term: -tag:churned excludes the objects with the tag,
+// -field:value the ones whose value is value and -word the ones matching the
+// word in any of their texts, facts included. Names and values with spaces or
+// commas are quoted.
+//
+// Parse only reads the text, Compile resolves the names of an org into the
+// parameters of the advanced list filter.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Keys of the terms that are not type value criteria
+const (
+	FieldTag     = "tag"
+	FieldStep    = "step"
+	FieldType    = "type"
+	FieldKind    = "kind"
+	FieldCreated = "created"
+)
+
+// Operators of a term, field>x and field:>x are the same
+const (
+	OpMatch = ":"
+	OpEq    = "="
+	OpGt    = ">"
+	OpGte   = ">="
+	OpLt    = "<"
+	OpLte   = "<="
+)
+
+// Value is a value of a term and where it is in the query
+type Value struct {
+	Text   string
+	Quoted bool
+	Pos    int
+	End    int
+}
+
+// Term is a term of a query. Field is empty for the words and phrases of the
+// full text search. Pos and End are offsets in characters.
+type Term struct {
+	Negated bool
+	Field   string
+	Op      string
+	Values  []Value
+	Pos     int
+	End     int
+}
+
+// PosError is an error in a query, between the characters Pos and End
+type PosError struct {
+	Pos     int    `json:"pos"`
+	End     int    `json:"end"`
+	Message string `json:"message"`
+}
+
+// Error lists the errors of a query
+type Error struct {
+	Errors []PosError `json:"errors"`
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, pe := range e.Errors {
+		msgs[i] = fmt.Sprintf("at position %d: %s", pe.Pos, pe.Message)
+	}
+	return "invalid query: " + strings.Join(msgs, "; ")
+}
+
+// errorList collects the errors of a query, nil when there is none
+type errorList []PosError
+
+func (l *errorList) add(pos, end int, format string, args ...interface{}) {
+	*l = append(*l, PosError{Pos: pos, End: end, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l errorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return &Error{Errors: l}
+}
+
+// Parse splits a query into its terms. It reads the whole query and reports
+// every error found, as an *Error.
+func Parse(text string) ([]Term, error) {
+	p := &parser{src: []rune(text)}
+	var terms []Term
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+		if term, ok := p.term(); ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms, p.errs.err()
+}
+
+type parser struct {
+	src  []rune
+	pos  int
+	errs errorList
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// term reads a term, it returns false when the term is invalid and was
+// skipped
+func (p *parser) term() (Term, bool) {
+	term := Term{Pos: p.pos}
+	if p.peek() == '-' && p.pos+1 < len(p.src) && !unicode.IsSpace(p.src[p.pos+1]) {
+		term.Negated = true
+		p.pos++
+	}
+
+	if p.peek() == '"' {
+		value, ok := p.quoted()
+		if !ok {
+			return Term{}, false
+		}
+		term.Values = []Value{value}
+		term.End = p.pos
+		return term, true
+	}
+
+	// A field name is followed by an operator, anything else is a word
+	start := p.pos
+	for !p.eof() && isNameRune(p.peek()) {
+		p.pos++
+	}
+	if p.pos > start && isOpRune(p.peek()) {
+		term.Field = string(p.src[start:p.pos])
+		term.Op = p.operator()
+		values, ok := p.values()
+		term.End = p.pos
+		if !ok {
+			return Term{}, false
+		}
+		if len(values) == 0 {
+			p.errs.add(term.Pos, term.End, "%s%s needs a value", term.Field, term.Op)
+			return Term{}, false
+		}
+		term.Values = values
+		return term, true
+	}
+
+	p.pos = start
+	for !p.eof() && !unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+	term.Values = []Value{{Text: string(p.src[start:p.pos]), Pos: start, End: p.pos}}
+	term.End = p.pos
+	return term, true
+}
+
+// operator reads the operator after a field name, field:>x reads as field>x
+func (p *parser) operator() string {
+	op := ""
+	if p.peek() == ':' {
+		p.pos++
+		op = OpMatch
+	}
+	switch p.peek() {
+	case '>', '<':
+		op = string(p.peek())
+		p.pos++
+		if p.peek() == '=' {
+			op += "="
+			p.pos++
+		}
+	case '=':
+		op = OpEq
+		p.pos++
+	}
+	return op
+}
+
+// values reads the comma separated values of a term
+func (p *parser) values() ([]Value, bool) {
+	var values []Value
+	for !p.eof() && !unicode.IsSpace(p.peek()) {
+		if p.peek() == '"' {
+			value, ok := p.quoted()
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value)
+		} else {
+			start := p.pos
+			for !p.eof() && !unicode.IsSpace(p.peek()) && p.peek() != ',' {
+				p.pos++
+			}
+			if p.pos > start {
+				values = append(values, Value{Text: string(p.src[start:p.pos]), Pos: start, End: p.pos})
+			}
+		}
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if !p.eof() && !unicode.IsSpace(p.peek()) {
+		start := p.pos
+		for !p.eof() && !unicode.IsSpace(p.peek()) {
+			p.pos++
+		}
+		p.errs.add(start, p.pos, "unexpected %q, separate values with commas", string(p.src[start:p.pos]))
+		return nil, false
+	}
+	return values, true
+}
+
+// quoted reads a quoted value, the quotes excluded
+func (p *parser) quoted() (Value, bool) {
+	start := p.pos
+	p.pos++
+	for !p.eof() && p.peek() != '"' {
+		p.pos++
+	}
+	if p.eof() {
+		p.errs.add(start, p.pos, "unterminated quote")
+		return Value{}, false
+	}
+	p.pos++
+	return Value{Text: string(p.src[start+1 : p.pos-1]), Quoted: true, Pos: start, End: p.pos}, true
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+func isOpRune(r rune) bool {
+	return r == ':' || r == '>' || r == '<' || r == '='
+}
@@ -0,0 +1,195 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []Term
+	}{
+		{
+			name:  "empty",
+			text:  "   ",
+			terms: nil,
+		},
+		{
+			name: "words",
+			text: "solana  labs",
+			terms: []Term{
+				{Values: []Value{{Text: "solana", Pos: 0, End: 6}}, Pos: 0, End: 6},
+				{Values: []Value{{Text: "labs", Pos: 8, End: 12}}, Pos: 8, End: 12},
+			},
+		},
+		{
+			name: "phrase",
+			text: `"solana labs"`,
+			terms: []Term{
+				{Values: []Value{{Text: "solana labs", Quoted: true, Pos: 0, End: 13}}, Pos: 0, End: 13},
+			},
+		},
+		{
+			name: "negated word",
+			text: "-churned",
+			terms: []Term{
+				{Negated: true, Values: []Value{{Text: "churned", Pos: 1, End: 8}}, Pos: 0, End: 8},
+			},
+		},
+		{
+			name: "lone dash is a word",
+			text: "a - b",
+			terms: []Term{
+				{Values: []Value{{Text: "a", Pos: 0, End: 1}}, Pos: 0, End: 1},
+				{Values: []Value{{Text: "-", Pos: 2, End: 3}}, Pos: 2, End: 3},
+				{Values: []Value{{Text: "b", Pos: 4, End: 5}}, Pos: 4, End: 5},
+			},
+		},
+		{
+			name: "field with values",
+			text: "tag:vip,lead",
+			terms: []Term{
+				{Field: "tag", Op: OpMatch, Values: []Value{
+					{Text: "vip", Pos: 4, End: 7},
+					{Text: "lead", Pos: 8, End: 12},
+				}, Pos: 0, End: 12},
+			},
+		},
+		{
+			name: "quoted values",
+			text: `step:"Closed won","A, B"`,
+			terms: []Term{
+				{Field: "step", Op: OpMatch, Values: []Value{
+					{Text: "Closed won", Quoted: true, Pos: 5, End: 17},
+					{Text: "A, B", Quoted: true, Pos: 18, End: 24},
+				}, Pos: 0, End: 24},
+			},
+		},
+		{
+			name: "negated field",
+			text: "-tag:churned",
+			terms: []Term{
+				{Negated: true, Field: "tag", Op: OpMatch, Values: []Value{{Text: "churned", Pos: 5, End: 12}}, Pos: 0, End: 12},
+			},
+		},
+		{
+			name: "comparisons",
+			text: "size>1M size>=2 size<3 size<=4 size=5",
+			terms: []Term{
+				{Field: "size", Op: OpGt, Values: []Value{{Text: "1M", Pos: 5, End: 7}}, Pos: 0, End: 7},
+				{Field: "size", Op: OpGte, Values: []Value{{Text: "2", Pos: 14, End: 15}}, Pos: 8, End: 15},
+				{Field: "size", Op: OpLt, Values: []Value{{Text: "3", Pos: 21, End: 22}}, Pos: 16, End: 22},
+				{Field: "size", Op: OpLte, Values: []Value{{Text: "4", Pos: 29, End: 30}}, Pos: 23, End: 30},
+				{Field: "size", Op: OpEq, Values: []Value{{Text: "5", Pos: 36, End: 37}}, Pos: 31, End: 37},
+			},
+		},
+		{
+			name: "colon before comparison",
+			text: "created:>2024-01",
+			terms: []Term{
+				{Field: "created", Op: OpGt, Values: []Value{{Text: "2024-01", Pos: 9, End: 16}}, Pos: 0, End: 16},
+			},
+		},
+		{
+			name: "dotted field name",
+			text: "a.b:c",
+			terms: []Term{
+				{Field: "a.b", Op: OpMatch, Values: []Value{{Text: "c", Pos: 4, End: 5}}, Pos: 0, End: 5},
+			},
+		},
+		{
+			name: "operator without name is a word",
+			text: ":x",
+			terms: []Term{
+				{Values: []Value{{Text: ":x", Pos: 0, End: 2}}, Pos: 0, End: 2},
+			},
+		},
+		{
+			name: "positions count characters",
+			text: "é tag:ü",
+			terms: []Term{
+				{Values: []Value{{Text: "é", Pos: 0, End: 1}}, Pos: 0, End: 1},
+				{Field: "tag", Op: OpMatch, Values: []Value{{Text: "ü", Pos: 6, End: 7}}, Pos: 2, End: 7},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(terms, tt.terms) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.text, terms, tt.terms)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		errors []PosError
+	}{
+		{
+			name:   "unterminated quote",
+			text:   `a "solana`,
+			errors: []PosError{{Pos: 2, End: 9, Message: "unterminated quote"}},
+		},
+		{
+			name:   "unterminated quoted value",
+			text:   `tag:"vip`,
+			errors: []PosError{{Pos: 4, End: 8, Message: "unterminated quote"}},
+		},
+		{
+			name:   "missing value",
+			text:   "tag: vip",
+			errors: []PosError{{Pos: 0, End: 4, Message: "tag: needs a value"}},
+		},
+		{
+			name:   "missing value after operator",
+			text:   "size>=",
+			errors: []PosError{{Pos: 0, End: 6, Message: "size>= needs a value"}},
+		},
+		{
+			name:   "text after a quoted value",
+			text:   `tag:"a"b`,
+			errors: []PosError{{Pos: 7, End: 8, Message: `unexpected "b", separate values with commas`}},
+		},
+		{
+			name: "every error is reported",
+			text: `tag: step:"x`,
+			errors: []PosError{
+				{Pos: 0, End: 4, Message: "tag: needs a value"},
+				{Pos: 10, End: 12, Message: "unterminated quote"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse(%q) error = %v, want an *Error", tt.text, err)
+			}
+			if !reflect.DeepEqual(qerr.Errors, tt.errors) {
+				t.Errorf("Parse(%q) errors =\n%+v\nwant\n%+v", tt.text, qerr.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := &Error{Errors: []PosError{
+		{Pos: 0, End: 4, Message: "tag: needs a value"},
+		{Pos: 10, End: 12, Message: "unterminated quote"},
+	}}
+	want := "invalid query: at position 0: tag: needs a value; at position 10: unterminated quote"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RoleAdmin can always read and write restricted fields
+const RoleAdmin = "admin"
+
+// CodeForbidden is reported when a viewer writes a field it may not write
+const CodeForbidden = "forbidden"
+
+// Access restricts a field to admins and the listed roles. Roles in
+// WriteRoles can also read the field.
+type Access struct {
+	Restricted bool     `json:"restricted,omitempty"`
+	ReadRoles  []string `json:"readRoles,omitempty"`
+	WriteRoles []string `json:"writeRoles,omitempty"`
+}
+
+// Viewer is who type values are read or written for. The zero Viewer is an
+// anonymous visitor of the public endpoints and never sees restricted fields.
+type Viewer struct {
+	Role string
+}
+
+// PublicViewer reads through the public endpoints
+var PublicViewer = Viewer{}
+
+// IsAdmin reports whether the viewer bypasses field restrictions
+func (v Viewer) IsAdmin() bool {
+	return v.Role == RoleAdmin
+}
+
+// HasRestricted reports whether any field of the schema is restricted
+func (s Schema) HasRestricted() bool {
+	for _, field := range s.Fields {
+		if field.Access.Restricted {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRead reports whether the viewer may read a field. Computed fields are
+// readable only when every field their expression uses is readable, so a
+// computed value cannot leak a restricted one. Fields missing from the
+// schema are readable.
+func (s Schema) CanRead(name string, v Viewer) bool {
+	return s.canRead(name, v, map[string]bool{})
+}
+
+func (s Schema) canRead(name string, v Viewer, seen map[string]bool) bool {
+	field, ok := s.Fields[name]
+	if !ok || v.IsAdmin() {
+		return true
+	}
+	if field.Access.Restricted && !contains(field.Access.ReadRoles, v.Role) && !contains(field.Access.WriteRoles, v.Role) {
+		return false
+	}
+	if field.Type != TypeComputed || seen[name] {
+		return true
+	}
+	seen[name] = true
+	expr, err := ParseExpr(field.Expression)
+	if err != nil {
+		return true
+	}
+	for _, ref := range expr.Refs() {
+		if !s.canRead(ref, v, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanWrite reports whether the viewer may write a field
+func (s Schema) CanWrite(name string, v Viewer) bool {
+	field, ok := s.Fields[name]
+	if !ok || v.IsAdmin() || !field.Access.Restricted {
+		return true
+	}
+	return contains(field.Access.WriteRoles, v.Role)
+}
+
+// Redact returns the values without the fields the viewer may not read, and
+// the names of the fields that were removed
+func (s Schema) Redact(values map[string]interface{}, v Viewer) (map[string]interface{}, []string) {
+	if values == nil || v.IsAdmin() {
+		return values, nil
+	}
+	var redacted []string
+	out := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if !s.CanRead(name, v) {
+			redacted = append(redacted, name)
+			continue
+		}
+		out[name] = value
+	}
+	return out, redacted
+}
+
+// RedactRaw is Redact for raw type_values JSON
+func (s Schema) RedactRaw(raw json.RawMessage, v Viewer) json.RawMessage {
+	if v.IsAdmin() || !s.HasRestricted() {
+		return raw
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return raw
+	}
+	values, redacted := s.Redact(values, v)
+	if len(redacted) == 0 {
+		return raw
+	}
+	out, err := json.Marshal(values)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// GuardWrite prepares values written by the viewer on top of the stored
+// ones. Fields the viewer may not write keep their stored value, so a client
+// that never saw them does not wipe them. Changing such a field is rejected.
+func (s Schema) GuardWrite(values, stored map[string]interface{}, v Viewer) (map[string]interface{}, error) {
+	if v.IsAdmin() || !s.HasRestricted() {
+		return values, nil
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	var errs []FieldError
+	for _, name := range s.Names() {
+		if s.CanWrite(name, v) {
+			continue
+		}
+		old, hasOld := stored[name]
+		value, present := values[name]
+		if !present {
+			if hasOld {
+				values[name] = old
+			}
+			continue
+		}
+		if isBlank(value) && isBlank(old) || equal(value, old) {
+			continue
+		}
+		errs = append(errs, FieldError{
+			Field:   name,
+			Code:    CodeForbidden,
+			Message: fmt.Sprintf("field %s is restricted and cannot be changed by %s", name, describeViewer(v)),
+		})
+	}
+	if len(errs) > 0 {
+		return values, &ValidationError{Errors: errs}
+	}
+	return values, nil
+}
+
+// GuardWriteRaw is GuardWrite for raw type_values JSON
+func (s Schema) GuardWriteRaw(raw, stored json.RawMessage, v Viewer) (json.RawMessage, error) {
+	if v.IsAdmin() || !s.HasRestricted() {
+		return raw, nil
+	}
+	var values, storedValues map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		// Left to ValidateRaw to report
+		return raw, nil
+	}
+	if len(stored) > 0 {
+		json.Unmarshal(stored, &storedValues)
+	}
+	values, err := s.GuardWrite(values, storedValues, v)
+	if err != nil {
+		return raw, err
+	}
+	out, err := json.Marshal(values)
+	if err != nil {
+		return raw, err
+	}
+	return out, nil
+}
+
+// CheckRemoveRaw rejects removing stored type values that hold restricted
+// fields the viewer may not write
+func (s Schema) CheckRemoveRaw(stored json.RawMessage, v Viewer) error {
+	if v.IsAdmin() || !s.HasRestricted() {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(stored, &values); err != nil {
+		return nil
+	}
+	var errs []FieldError
+	for _, name := range s.Names() {
+		if s.CanWrite(name, v) || isBlank(values[name]) {
+			continue
+		}
+		errs = append(errs, FieldError{
+			Field:   name,
+			Code:    CodeForbidden,
+			Message: fmt.Sprintf("field %s is restricted and cannot be removed by %s", name, describeViewer(v)),
+		})
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Forbidden reports whether every error is about a restricted field
+func (e *ValidationError) Forbidden() bool {
+	for _, fe := range e.Errors {
+		if fe.Code != CodeForbidden {
+			return false
+		}
+	}
+	return len(e.Errors) > 0
+}
+
+func describeViewer(v Viewer) string {
+	if v.Role == "" {
+		return "this caller"
+	}
+	return "role " + v.Role
+}
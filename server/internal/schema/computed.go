@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Aggregates are the per-object values computed fields can use through the
+// obj. prefix
+type Aggregates struct {
+	FactCount    int64
+	FirstFactAt  *time.Time
+	LastFactAt   *time.Time
+	CreatedAt    time.Time
+	TaskCount    int64
+	OpenTasks    int64
+	OverdueTasks int64
+}
+
+func (a Aggregates) value(name string) interface{} {
+	switch name {
+	case "fact_count":
+		return float64(a.FactCount)
+	case "first_fact_at":
+		if a.FirstFactAt == nil {
+			return nil
+		}
+		return *a.FirstFactAt
+	case "last_fact_at":
+		if a.LastFactAt == nil {
+			return nil
+		}
+		return *a.LastFactAt
+	case "created_at":
+		return a.CreatedAt
+	case "task_count":
+		return float64(a.TaskCount)
+	case "open_tasks":
+		return float64(a.OpenTasks)
+	case "overdue_tasks":
+		return float64(a.OverdueTasks)
+	}
+	return nil
+}
+
+// HasComputed reports whether the schema declares any computed field
+func (s Schema) HasComputed() bool {
+	for _, field := range s.Fields {
+		if field.Type == TypeComputed {
+			return true
+		}
+	}
+	return false
+}
+
+// IsComputed reports whether name is a computed field of the schema
+func (s Schema) IsComputed(name string) bool {
+	field, ok := s.Fields[name]
+	return ok && field.Type == TypeComputed
+}
+
+// Compute evaluates every computed field of the schema for one object.
+// Computed fields may refer to each other, cycles are rejected by Check and
+// evaluate to null here.
+func (s Schema) Compute(values map[string]interface{}, aggs Aggregates, now time.Time) map[string]interface{} {
+	if !s.HasComputed() {
+		return nil
+	}
+
+	result := map[string]interface{}{}
+	visiting := map[string]bool{}
+	env := &evalEnv{now: now, aggs: aggs}
+	env.lookup = func(name string) interface{} {
+		if !s.IsComputed(name) {
+			return values[name]
+		}
+		if v, ok := result[name]; ok {
+			return v
+		}
+		if visiting[name] {
+			return nil
+		}
+		visiting[name] = true
+		v := s.evalField(name, env)
+		visiting[name] = false
+		result[name] = v
+		return v
+	}
+
+	for _, name := range s.Names() {
+		if s.IsComputed(name) {
+			env.lookup(name)
+		}
+	}
+	return result
+}
+
+func (s Schema) evalField(name string, env *evalEnv) interface{} {
+	expr, err := ParseExpr(s.Fields[name].Expression)
+	if err != nil {
+		return nil
+	}
+	v := expr.root.eval(env)
+	if f, ok := v.(float64); ok && (f != f || f > 1e300 || f < -1e300) {
+		// NaN or overflow
+		return nil
+	}
+	return v
+}
+
+// checkComputed validates the expression of a computed field and makes sure
+// it only refers to known fields without cycles
+func (s Schema) checkComputed(name string) []FieldError {
+	field := s.Fields[name]
+	if field.Expression == "" {
+		return []FieldError{{Field: name, Code: CodeInvalidDefinition, Message: "computed field must declare an expression"}}
+	}
+	expr, err := ParseExpr(field.Expression)
+	if err != nil {
+		return []FieldError{{Field: name, Code: CodeInvalidDefinition, Message: fmt.Sprintf("invalid expression %v", err)}}
+	}
+
+	var errs []FieldError
+	for _, ref := range expr.Refs() {
+		if len(ref) > 4 && ref[:4] == "obj." {
+			continue
+		}
+		if _, ok := s.Fields[ref]; !ok {
+			errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: fmt.Sprintf("expression refers to unknown field %q", ref)})
+		}
+	}
+	if s.hasCycle(name, map[string]bool{}) {
+		errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: "computed fields refer to each other in a cycle"})
+	}
+	return errs
+}
+
+func (s Schema) hasCycle(name string, path map[string]bool) bool {
+	if path[name] {
+		return true
+	}
+	expr, err := ParseExpr(s.Fields[name].Expression)
+	if err != nil {
+		return false
+	}
+	path[name] = true
+	defer delete(path, name)
+	for _, ref := range expr.Refs() {
+		if s.IsComputed(ref) && s.hasCycle(ref, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchCriterion checks a computed value against a filter criterion. The
+// criterion may start with a comparison operator (">1000", "<=5", "!=0"),
+// otherwise it is a case-insensitive substring match like type value filters.
+func MatchCriterion(v interface{}, criterion string) bool {
+	criterion = strings.TrimSpace(criterion)
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if !strings.HasPrefix(criterion, op) {
+			continue
+		}
+		operand := strings.TrimSpace(strings.TrimPrefix(criterion, op))
+		if op == "=" || op == "!=" {
+			eq := equal(v, operand)
+			return eq == (op == "=")
+		}
+		c, ok := compare(v, operand)
+		if !ok {
+			return false
+		}
+		switch op {
+		case ">=":
+			return c >= 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c < 0
+	}
+	if v == nil {
+		return criterion == ""
+	}
+	return strings.Contains(strings.ToLower(stringify(v)), strings.ToLower(criterion))
+}
+
+// Less orders two field values the way expressions compare them: numerically,
+// chronologically, then as strings
+func Less(a, b interface{}) bool {
+	c, ok := compare(a, b)
+	return ok && c < 0
+}
@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		pos     int
+		message string
+	}{
+		{"", 0, "unexpected end of expression"},
+		{"a +", 3, "unexpected end of expression"},
+		{"a b", 2, `unexpected "b"`},
+		{"a # b", 2, `unexpected character '#'`},
+		{"1..2", 0, `invalid number "1..2"`},
+		{"(a + 1", 6, "expected )"},
+		{"`a b", 0, "unterminated quoted name"},
+		{"a + 'x", 4, "unterminated string"},
+		{"foo(1)", 0, `unknown function "foo"`},
+		{"round()", 0, "wrong number of arguments for round"},
+		{"1 + if(a, b)", 4, "wrong number of arguments for if"},
+		{"min(1 2)", 6, "expected ) after arguments"},
+		{"obj.nope * 2", 0, `unknown aggregate "obj.nope"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseExpr(tt.source)
+		var exprErr *ExprError
+		if !errors.As(err, &exprErr) {
+			t.Errorf("ParseExpr(%q) error = %v, want an *ExprError", tt.source, err)
+			continue
+		}
+		if exprErr.Pos != tt.pos || exprErr.Message != tt.message {
+			t.Errorf("ParseExpr(%q) error = %d %q, want %d %q", tt.source, exprErr.Pos, exprErr.Message, tt.pos, tt.message)
+		}
+	}
+}
+
+func TestExprErrorMessage(t *testing.T) {
+	_, err := ParseExpr("a +")
+	if err == nil || err.Error() != "at position 3: unexpected end of expression" {
+		t.Errorf("ParseExpr error = %v", err)
+	}
+}
+
+func TestExprRefs(t *testing.T) {
+	expr, err := ParseExpr("a + `deal value` * obj.fact_count + max(a, obj.open_tasks, 2)")
+	if err != nil {
+		t.Fatalf("ParseExpr error: %v", err)
+	}
+	want := []string{"a", "deal value", "obj.fact_count", "obj.open_tasks"}
+	if got := expr.Refs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Refs = %v, want %v", got, want)
+	}
+}
+
+func TestComputeExpressions(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	lastFact := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	aggs := Aggregates{
+		FactCount:    3,
+		LastFactAt:   &lastFact,
+		CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TaskCount:    2,
+		OpenTasks:    4,
+		OverdueTasks: 1,
+	}
+	tests := []struct {
+		expression string
+		values     map[string]interface{}
+		want       interface{}
+	}{
+		{"a * b / 100", map[string]interface{}{"a": 200000.0, "b": 25.0}, 50000.0},
+		{"a + b", map[string]interface{}{"a": "3", "b": 2.0}, 5.0},
+		{"a + b", map[string]interface{}{"a": 3.0}, nil},
+		{"a + b", map[string]interface{}{"a": 3.0, "b": "many"}, nil},
+		{"a + true", map[string]interface{}{"a": 1.0}, 2.0},
+		{"a / 0", map[string]interface{}{"a": 1.0}, nil},
+		{"a % 3", map[string]interface{}{"a": 10.0}, 1.0},
+		{"a % 0", map[string]interface{}{"a": 10.0}, nil},
+		{"-a", map[string]interface{}{"a": 2.0}, -2.0},
+		{"-a", map[string]interface{}{"a": "x"}, nil},
+		{"!a", map[string]interface{}{"a": "no"}, true},
+		{"2 + 3 * 4", nil, 14.0},
+		{"(2 + 3) * 4", nil, 20.0},
+		{"a * a", map[string]interface{}{"a": 1e200}, nil},
+		{"`deal value` * 2", map[string]interface{}{"deal value": 3.0}, 6.0},
+		{"a > 1 && b", map[string]interface{}{"a": 2.0, "b": "yes"}, true},
+		{"a > 1 || b", map[string]interface{}{"a": 0.0, "b": ""}, false},
+		{"a == 'ACME'", map[string]interface{}{"a": "acme"}, true},
+		{"a == 3", map[string]interface{}{"a": "3.0"}, true},
+		{"a != null", nil, false},
+		{"a < b", map[string]interface{}{"a": "2024-01-01", "b": "2024-02-01"}, true},
+		{"a > b", map[string]interface{}{"a": "x"}, nil},
+		{"days_since(obj.last_fact_at)", nil, 9.0},
+		{"days_since(obj.first_fact_at)", nil, nil},
+		{"days_until(a)", map[string]interface{}{"a": "2024-03-20"}, 9.0},
+		{"days_until(a)", map[string]interface{}{"a": "soon"}, nil},
+		{"days_between(obj.created_at, a)", map[string]interface{}{"a": "2024-01-31"}, 30.0},
+		{"round(a, 2)", map[string]interface{}{"a": 3.14159}, 3.14},
+		{"round(a)", map[string]interface{}{"a": 2.5}, 3.0},
+		{"abs(a)", map[string]interface{}{"a": -4.0}, 4.0},
+		{"min(a, b, 'x')", map[string]interface{}{"a": 3.0, "b": 1.0}, 1.0},
+		{"max(a, b)", map[string]interface{}{"a": 3.0, "b": 1.0}, 3.0},
+		{"max(c)", nil, nil},
+		{"coalesce(a, b, 5)", map[string]interface{}{"a": " "}, 5.0},
+		{"coalesce(a, b)", map[string]interface{}{"b": "x"}, "x"},
+		{"if(obj.open_tasks > 0, obj.overdue_tasks / obj.open_tasks, 0)", nil, 0.25},
+		{"if(a, 'yes', 'no')", map[string]interface{}{"a": "false"}, "no"},
+		{"len(a)", map[string]interface{}{"a": []interface{}{"x", "y"}}, 2.0},
+		{"len(a)", map[string]interface{}{"a": "héllo"}, 5.0},
+		{"len(a)", nil, 0.0},
+		{"len(a)", map[string]interface{}{"a": 12.0}, 1.0},
+		{"obj.fact_count + obj.task_count", nil, 5.0},
+	}
+	for _, tt := range tests {
+		s := Schema{Fields: map[string]Field{"x": {Name: "x", Type: TypeComputed, Expression: tt.expression}}}
+		got := s.Compute(tt.values, aggs, now)["x"]
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s with %v = %#v, want %#v", tt.expression, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	s, err := Parse(json.RawMessage(`{
+		"value": {"type": "number"},
+		"weighted": {"type": "computed", "expression": "value * 2"},
+		"double": {"type": "computed", "expression": "weighted * 2"},
+		"loop1": {"type": "computed", "expression": "loop2"},
+		"loop2": {"type": "computed", "expression": "loop1"},
+		"broken": {"type": "computed", "expression": "value +"}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got := s.Compute(map[string]interface{}{"value": 5.0, "weighted": 1.0}, Aggregates{}, time.Now())
+	want := map[string]interface{}{"weighted": 10.0, "double": 20.0, "loop1": nil, "loop2": nil, "broken": nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute = %v, want %v", got, want)
+	}
+
+	plain := Schema{Fields: map[string]Field{"value": {Name: "value", Type: TypeNumber}}}
+	if got := plain.Compute(map[string]interface{}{"value": 5.0}, Aggregates{}, time.Now()); got != nil {
+		t.Errorf("Compute without computed fields = %v, want nil", got)
+	}
+}
+
+func TestCheckComputed(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		errors []FieldError
+	}{
+		{
+			name:   "valid",
+			fields: `{"b": {"type": "number"}, "a": {"type": "computed", "expression": "b * obj.fact_count"}}`,
+		},
+		{
+			name:   "no expression",
+			fields: `{"a": {"type": "computed"}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "computed field must declare an expression"}},
+		},
+		{
+			name:   "invalid expression",
+			fields: `{"a": {"type": "computed", "expression": "b +"}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "invalid expression at position 3: unexpected end of expression"}},
+		},
+		{
+			name:   "unknown field",
+			fields: `{"a": {"type": "computed", "expression": "nope * 2"}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: `expression refers to unknown field "nope"`}},
+		},
+		{
+			name:   "cycle",
+			fields: `{"a": {"type": "computed", "expression": "b + 1"}, "b": {"type": "computed", "expression": "a"}}`,
+			errors: []FieldError{
+				{Field: "a", Code: CodeInvalidDefinition, Message: "computed fields refer to each other in a cycle"},
+				{Field: "b", Code: CodeInvalidDefinition, Message: "computed fields refer to each other in a cycle"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(json.RawMessage(tt.fields))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			checkErrors(t, s.Check(), tt.errors)
+		})
+	}
+}
+
+func TestMatchCriterion(t *testing.T) {
+	tests := []struct {
+		value     interface{}
+		criterion string
+		want      bool
+	}{
+		{1500.0, ">1000", true},
+		{1000.0, ">1000", false},
+		{1000.0, ">= 1000", true},
+		{5.0, "<=4", false},
+		{3.0, "<4", true},
+		{0.0, "!=0", false},
+		{nil, "!=0", true},
+		{2.0, "=2", true},
+		{true, "=1", true},
+		{nil, ">1", false},
+		{nil, "", true},
+		{nil, "x", false},
+		{"Acme Corp", "acme", true},
+		{"Acme Corp", "globex", false},
+		{12.5, "2.5", true},
+		{"2024-05-01", ">2024-01-01", true},
+		{"abc", "<b", true},
+	}
+	for _, tt := range tests {
+		if got := MatchCriterion(tt.value, tt.criterion); got != tt.want {
+			t.Errorf("MatchCriterion(%#v, %q) = %v, want %v", tt.value, tt.criterion, got, tt.want)
+		}
+	}
+}
+
+func TestLess(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{1.0, 2.0, true},
+		{"10", "9", false},
+		{"2024-01-02", "2024-01-10", true},
+		{"b", "a", false},
+		{nil, 1.0, false},
+		{1.0, nil, false},
+	}
+	for _, tt := range tests {
+		if got := Less(tt.a, tt.b); got != tt.want {
+			t.Errorf("Less(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// checkErrors compares the field errors of a *ValidationError, nil when
+// none is expected
+func checkErrors(t *testing.T, err error, want []FieldError) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		return
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	if !reflect.DeepEqual(verr.Errors, want) {
+		t.Errorf("errors =\n%+v\nwant\n%+v", verr.Errors, want)
+	}
+}
@@ -0,0 +1,632 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Expressions of computed fields. The language is deliberately small:
+//
+//	deal_value * probability / 100
+//	days_since(obj.last_fact_at)
+//	if(obj.open_tasks > 0, obj.overdue_tasks / obj.open_tasks, 0)
+//
+// Identifiers refer to fields of the same object type, `quoted names` allow
+// spaces, and the obj. prefix refers to aggregates of the object (see
+// Aggregates). Missing or non-numeric values make arithmetic return null
+// instead of failing, so one empty field does not break a whole list.
+
+// Expr is a parsed computed field expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// ExprError is a parse error with the byte offset where it happened
+type ExprError struct {
+	Pos     int
+	Message string
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Message)
+}
+
+type node interface {
+	eval(env *evalEnv) interface{}
+}
+
+type (
+	literalNode struct{ value interface{} }
+	identNode   struct {
+		name      string
+		aggregate bool
+	}
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	callNode struct {
+		name string
+		args []node
+	}
+)
+
+// Aggregate identifiers available under the obj. prefix
+var aggregateNames = map[string]bool{
+	"fact_count":    true,
+	"first_fact_at": true,
+	"last_fact_at":  true,
+	"created_at":    true,
+	"task_count":    true,
+	"open_tasks":    true,
+	"overdue_tasks": true,
+}
+
+// functions maps names to their allowed argument counts, -1 means variadic
+var functions = map[string][2]int{
+	"days_since":   {1, 1},
+	"days_until":   {1, 1},
+	"days_between": {2, 2},
+	"round":        {1, 2},
+	"abs":          {1, 1},
+	"min":          {1, -1},
+	"max":          {1, -1},
+	"coalesce":     {1, -1},
+	"if":           {3, 3},
+	"len":          {1, 1},
+}
+
+// ParseExpr parses a computed field expression
+func ParseExpr(source string) (*Expr, error) {
+	p := &exprParser{src: source}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	switch p.tok.kind {
+	case tokEOF:
+		return &Expr{source: source, root: root}, nil
+	case tokError:
+		return nil, &ExprError{Pos: p.tok.pos, Message: p.tok.text}
+	}
+	return nil, &ExprError{Pos: p.tok.pos, Message: fmt.Sprintf("unexpected %q", p.tok.text)}
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Refs returns the field names and aggregates (with the obj. prefix) the expression uses
+func (e *Expr) Refs() []string {
+	var refs []string
+	seen := map[string]bool{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch t := n.(type) {
+		case *identNode:
+			name := t.name
+			if t.aggregate {
+				name = "obj." + name
+			}
+			if !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+		case *unaryNode:
+			walk(t.operand)
+		case *binaryNode:
+			walk(t.left)
+			walk(t.right)
+		case *callNode:
+			for _, arg := range t.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return refs
+}
+
+// ---- lexer ----
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+	tokError
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = token{kind: tokNumber, text: p.src[start:p.pos], pos: start}
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || p.src[p.pos] == '.' ||
+			unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+
+	case c == '`':
+		end := strings.IndexByte(p.src[p.pos+1:], '`')
+		if end < 0 {
+			p.tok = token{kind: tokError, text: "unterminated quoted name", pos: start}
+			return
+		}
+		p.tok = token{kind: tokIdent, text: p.src[p.pos+1 : p.pos+1+end], pos: start}
+		p.pos += end + 2
+
+	case c == '"' || c == '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], c)
+		if end < 0 {
+			p.tok = token{kind: tokError, text: "unterminated string", pos: start}
+			return
+		}
+		p.tok = token{kind: tokString, text: p.src[p.pos+1 : p.pos+1+end], pos: start}
+		p.pos += end + 2
+
+	case c == '(':
+		p.pos++
+		p.tok = token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		p.pos++
+		p.tok = token{kind: tokRParen, text: ")", pos: start}
+	case c == ',':
+		p.pos++
+		p.tok = token{kind: tokComma, text: ",", pos: start}
+
+	default:
+		for _, op := range []string{"&&", "||", ">=", "<=", "==", "!=", "+", "-", "*", "/", "%", ">", "<", "!"} {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				p.pos += len(op)
+				p.tok = token{kind: tokOp, text: op, pos: start}
+				return
+			}
+		}
+		p.tok = token{kind: tokError, text: fmt.Sprintf("unexpected character %q", c), pos: start}
+	}
+}
+
+// ---- parser ----
+
+func (p *exprParser) binary(ops []string, operand func() (node, error)) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && contains(ops, p.tok.text) {
+		op := p.tok.text
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	return p.binary([]string{"||"}, p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	return p.binary([]string{"&&"}, p.parseComparison)
+}
+
+func (p *exprParser) parseComparison() (node, error) {
+	return p.binary([]string{"==", "!=", ">", ">=", "<", "<="}, p.parseAdditive)
+}
+
+func (p *exprParser) parseAdditive() (node, error) {
+	return p.binary([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+func (p *exprParser) parseMultiplicative() (node, error) {
+	return p.binary([]string{"*", "/", "%"}, p.parseUnary)
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	if p.tok.kind == tokOp && (p.tok.text == "-" || p.tok.text == "!") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, &ExprError{Pos: tok.pos, Message: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		p.next()
+		return &literalNode{value: n}, nil
+
+	case tokString:
+		p.next()
+		return &literalNode{value: tok.text}, nil
+
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.tok.kind == tokLParen {
+			return p.parseCall(tok)
+		}
+		if strings.HasPrefix(tok.text, "obj.") {
+			name := strings.TrimPrefix(tok.text, "obj.")
+			if !aggregateNames[name] {
+				return nil, &ExprError{Pos: tok.pos, Message: fmt.Sprintf("unknown aggregate %q", tok.text)}
+			}
+			return &identNode{name: name, aggregate: true}, nil
+		}
+		return &identNode{name: tok.text}, nil
+
+	case tokLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, &ExprError{Pos: p.tok.pos, Message: "expected )"}
+		}
+		p.next()
+		return inner, nil
+
+	case tokError:
+		return nil, &ExprError{Pos: tok.pos, Message: tok.text}
+
+	case tokEOF:
+		return nil, &ExprError{Pos: tok.pos, Message: "unexpected end of expression"}
+	}
+	return nil, &ExprError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+func (p *exprParser) parseCall(name token) (node, error) {
+	arity, ok := functions[name.text]
+	if !ok {
+		return nil, &ExprError{Pos: name.pos, Message: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	p.next() // (
+	var args []node
+	if p.tok.kind != tokRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.tok.kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.tok.kind != tokRParen {
+		return nil, &ExprError{Pos: p.tok.pos, Message: "expected ) after arguments"}
+	}
+	p.next()
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, &ExprError{Pos: name.pos, Message: fmt.Sprintf("wrong number of arguments for %s", name.text)}
+	}
+	return &callNode{name: name.text, args: args}, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- evaluation ----
+
+type evalEnv struct {
+	now    time.Time
+	lookup func(name string) interface{}
+	aggs   Aggregates
+}
+
+func (n *literalNode) eval(_ *evalEnv) interface{} {
+	return n.value
+}
+
+func (n *identNode) eval(env *evalEnv) interface{} {
+	if n.aggregate {
+		return env.aggs.value(n.name)
+	}
+	return env.lookup(n.name)
+}
+
+func (n *unaryNode) eval(env *evalEnv) interface{} {
+	v := n.operand.eval(env)
+	if n.op == "!" {
+		return !truthy(v)
+	}
+	if f, ok := number(v); ok {
+		return -f
+	}
+	return nil
+}
+
+func (n *binaryNode) eval(env *evalEnv) interface{} {
+	switch n.op {
+	case "&&":
+		return truthy(n.left.eval(env)) && truthy(n.right.eval(env))
+	case "||":
+		return truthy(n.left.eval(env)) || truthy(n.right.eval(env))
+	}
+
+	l, r := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==", "!=":
+		eq := equal(l, r)
+		if n.op == "==" {
+			return eq
+		}
+		return !eq
+	case ">", ">=", "<", "<=":
+		c, ok := compare(l, r)
+		if !ok {
+			return nil
+		}
+		switch n.op {
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		case "<":
+			return c < 0
+		}
+		return c <= 0
+	}
+
+	a, ok1 := number(l)
+	b, ok2 := number(r)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	switch n.op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return nil
+		}
+		return a / b
+	case "%":
+		if b == 0 {
+			return nil
+		}
+		return math.Mod(a, b)
+	}
+	return nil
+}
+
+func (n *callNode) eval(env *evalEnv) interface{} {
+	switch n.name {
+	case "if":
+		if truthy(n.args[0].eval(env)) {
+			return n.args[1].eval(env)
+		}
+		return n.args[2].eval(env)
+	case "coalesce":
+		for _, arg := range n.args {
+			if v := arg.eval(env); !isBlank(v) {
+				return v
+			}
+		}
+		return nil
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(env)
+	}
+
+	switch n.name {
+	case "days_since", "days_until":
+		t, ok := timeValue(args[0])
+		if !ok {
+			return nil
+		}
+		days := env.now.Sub(t).Hours() / 24
+		if n.name == "days_until" {
+			days = -days
+		}
+		return math.Floor(days)
+	case "days_between":
+		a, ok1 := timeValue(args[0])
+		b, ok2 := timeValue(args[1])
+		if !ok1 || !ok2 {
+			return nil
+		}
+		return math.Floor(b.Sub(a).Hours() / 24)
+	case "round":
+		f, ok := number(args[0])
+		if !ok {
+			return nil
+		}
+		digits := 0.0
+		if len(args) > 1 {
+			digits, _ = number(args[1])
+		}
+		scale := math.Pow(10, digits)
+		return math.Round(f*scale) / scale
+	case "abs":
+		if f, ok := number(args[0]); ok {
+			return math.Abs(f)
+		}
+		return nil
+	case "min", "max":
+		var result interface{}
+		for _, arg := range args {
+			f, ok := number(arg)
+			if !ok {
+				continue
+			}
+			if result == nil || (n.name == "min" && f < result.(float64)) || (n.name == "max" && f > result.(float64)) {
+				result = f
+			}
+		}
+		return result
+	case "len":
+		switch v := args[0].(type) {
+		case []interface{}:
+			return float64(len(v))
+		case nil:
+			return 0.0
+		case string:
+			return float64(len([]rune(v)))
+		}
+		return 1.0
+	}
+	return nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case bool:
+		if t {
+			return 1, true
+		}
+		return 0, true
+	case int64:
+		return float64(t), true
+	case int:
+		return float64(t), true
+	}
+	return toNumber(v)
+}
+
+func timeValue(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	case string:
+		return ParseTime(t)
+	}
+	return time.Time{}, false
+}
+
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		if b, ok := ToBool(t); ok {
+			return b
+		}
+		return t != ""
+	}
+	return true
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return strings.EqualFold(stringify(a), stringify(b))
+}
+
+func compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if x, ok := timeValue(a); ok {
+		if y, ok := timeValue(b); ok {
+			return x.Compare(y), true
+		}
+	}
+	return strings.Compare(stringify(a), stringify(b)), true
+}
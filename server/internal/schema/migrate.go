@@ -0,0 +1,464 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration operations
+const (
+	OpRename  = "rename"
+	OpDrop    = "drop"
+	OpConvert = "convert"
+	OpSplit   = "split"
+	OpMerge   = "merge"
+)
+
+// Coercion rules used when converting a field to another type
+const (
+	// CoerceStrict leaves the whole row untouched when a value cannot be converted
+	CoerceStrict = "strict"
+	// CoerceClear removes values that cannot be converted
+	CoerceClear = "clear"
+	// CoerceDefault replaces values that cannot be converted with Operation.Default
+	CoerceDefault = "default"
+)
+
+// CodeCoercionFailed is reported for values a migration could not convert
+const CodeCoercionFailed = "coercion_failed"
+
+// Operation is a single step of a schema migration
+//
+//	{"op": "rename", "field": "company", "to": "organisation"}
+//	{"op": "drop", "field": "fax"}
+//	{"op": "convert", "field": "stage_size", "type": "number", "rule": "clear"}
+//	{"op": "split", "field": "full_name", "into": ["first_name", "last_name"], "separator": " "}
+//	{"op": "merge", "fields": ["street", "city"], "to": "address", "separator": ", "}
+type Operation struct {
+	Op        string      `json:"op"`
+	Field     string      `json:"field,omitempty"`
+	Fields    []string    `json:"fields,omitempty"`
+	To        string      `json:"to,omitempty"`
+	Into      []string    `json:"into,omitempty"`
+	Type      string      `json:"type,omitempty"`
+	Options   []string    `json:"options,omitempty"`
+	Rule      string      `json:"rule,omitempty"`
+	Default   interface{} `json:"default,omitempty"`
+	Separator string      `json:"separator,omitempty"`
+	// Keep leaves the source fields of a split or merge in place
+	Keep bool `json:"keep,omitempty"`
+}
+
+// Plan is an ordered list of operations applied to every value of an object type
+type Plan []Operation
+
+// MigrationResult is the outcome of applying a plan to one set of type values
+type MigrationResult struct {
+	Values map[string]interface{}
+	Errors []FieldError
+	// Skipped is set when a strict conversion failed and the row must be left as is
+	Skipped bool
+}
+
+// Check validates the plan against the current schema, simulating each
+// operation so later steps can refer to fields created by earlier ones.
+func (p Plan) Check(s Schema) error {
+	if len(p) == 0 {
+		return &ValidationError{Errors: []FieldError{{Code: CodeInvalidDefinition, Message: "at least one operation is required"}}}
+	}
+
+	names := map[string]bool{}
+	for name := range s.Fields {
+		names[name] = true
+	}
+
+	var errs []FieldError
+	fail := func(i int, field, msg string) {
+		errs = append(errs, FieldError{Field: field, Code: CodeInvalidDefinition, Message: fmt.Sprintf("operation %d (%s): %s", i+1, p[i].Op, msg)})
+	}
+
+	for i, op := range p {
+		switch op.Op {
+		case OpRename:
+			if !names[op.Field] {
+				fail(i, op.Field, "field does not exist")
+			} else if op.To == "" || names[op.To] {
+				fail(i, op.To, "target field is empty or already exists")
+			} else {
+				delete(names, op.Field)
+				names[op.To] = true
+			}
+
+		case OpDrop:
+			if !names[op.Field] {
+				fail(i, op.Field, "field does not exist")
+			} else {
+				delete(names, op.Field)
+			}
+
+		case OpConvert:
+			switch {
+			case !names[op.Field]:
+				fail(i, op.Field, "field does not exist")
+			case !knownTypes[op.Type] || op.Type == TypeComputed:
+				fail(i, op.Field, fmt.Sprintf("cannot convert to field type %q", op.Type))
+			case s.IsComputed(op.Field):
+				fail(i, op.Field, "computed fields hold no stored values")
+			case op.Type == TypeEnum && len(op.Options) == 0:
+				fail(i, op.Field, "converting to enum requires options")
+			case op.Rule != "" && op.Rule != CoerceStrict && op.Rule != CoerceClear && op.Rule != CoerceDefault:
+				fail(i, op.Field, fmt.Sprintf("unknown coercion rule %q", op.Rule))
+			case op.Rule == CoerceDefault && op.Default == nil:
+				fail(i, op.Field, "the default rule requires a default value")
+			}
+
+		case OpSplit:
+			if !names[op.Field] {
+				fail(i, op.Field, "field does not exist")
+				continue
+			}
+			if len(op.Into) < 2 {
+				fail(i, op.Field, "split requires at least two target fields")
+				continue
+			}
+			if !op.Keep {
+				delete(names, op.Field)
+			}
+			for _, target := range op.Into {
+				if target == "" || names[target] {
+					fail(i, target, "target field is empty or already exists")
+				}
+				names[target] = true
+			}
+
+		case OpMerge:
+			if len(op.Fields) < 2 {
+				fail(i, op.To, "merge requires at least two source fields")
+				continue
+			}
+			for _, source := range op.Fields {
+				if !names[source] {
+					fail(i, source, "field does not exist")
+				}
+			}
+			if !op.Keep {
+				for _, source := range op.Fields {
+					delete(names, source)
+				}
+			}
+			if op.To == "" || names[op.To] {
+				fail(i, op.To, "target field is empty or already exists")
+			}
+			names[op.To] = true
+
+		default:
+			fail(i, op.Field, "unknown operation")
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Apply runs the plan over one set of type values. The input map is not modified.
+func (p Plan) Apply(s Schema, values map[string]interface{}) MigrationResult {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		out[k] = v
+	}
+	result := MigrationResult{Values: out}
+
+	for _, op := range p {
+		switch op.Op {
+		case OpRename:
+			if v, ok := out[op.Field]; ok {
+				out[op.To] = v
+				delete(out, op.Field)
+			}
+
+		case OpDrop:
+			delete(out, op.Field)
+
+		case OpConvert:
+			v, ok := out[op.Field]
+			if !ok || isBlank(v) {
+				continue
+			}
+			multiple := s.Fields[op.Field].Validation.Multiple
+			converted, err := convertValue(v, op.Type, op.Options, multiple)
+			if err == nil {
+				out[op.Field] = converted
+				continue
+			}
+			result.Errors = append(result.Errors, FieldError{Field: op.Field, Code: CodeCoercionFailed, Message: err.Error(), Value: v})
+			switch op.Rule {
+			case CoerceClear:
+				delete(out, op.Field)
+			case CoerceDefault:
+				out[op.Field] = op.Default
+			default:
+				result.Skipped = true
+			}
+
+		case OpSplit:
+			v, ok := out[op.Field]
+			if !ok {
+				continue
+			}
+			parts := splitValue(stringify(v), op.separator(), len(op.Into))
+			for i, target := range op.Into {
+				if i < len(parts) && parts[i] != "" {
+					out[target] = parts[i]
+				}
+			}
+			if !op.Keep {
+				delete(out, op.Field)
+			}
+
+		case OpMerge:
+			var parts []string
+			for _, source := range op.Fields {
+				if v, ok := out[source]; ok && !isBlank(v) {
+					parts = append(parts, stringify(v))
+				}
+				if !op.Keep {
+					delete(out, source)
+				}
+			}
+			if len(parts) > 0 {
+				out[op.To] = strings.Join(parts, op.separator())
+			}
+		}
+
+		if result.Skipped {
+			break
+		}
+	}
+
+	return result
+}
+
+// ApplyFields rewrites obj_type.fields to match the plan. Unknown keys in each
+// field config are kept so the webapp metadata survives the migration.
+func (p Plan) ApplyFields(raw json.RawMessage) (json.RawMessage, error) {
+	entries := map[string]map[string]interface{}{}
+	if len(raw) > 0 && string(raw) != "null" && string(raw) != "[]" {
+		var decoded map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, fmt.Errorf("fields must be an object keyed by field name: %w", err)
+		}
+		for name, entry := range decoded {
+			config := map[string]interface{}{}
+			var typeName string
+			if err := json.Unmarshal(entry, &typeName); err == nil {
+				config["type"] = typeName
+			} else if err := json.Unmarshal(entry, &config); err != nil {
+				config = map[string]interface{}{"type": TypeString}
+			}
+			entries[name] = config
+		}
+	}
+
+	nextOrder := 0.0
+	for _, config := range entries {
+		if meta, ok := config["meta"].(map[string]interface{}); ok {
+			if order, ok := meta["order"].(float64); ok && order >= nextOrder {
+				nextOrder = order + 1
+			}
+		}
+	}
+	newField := func(name string) map[string]interface{} {
+		config := map[string]interface{}{
+			"type": TypeString,
+			"meta": map[string]interface{}{"label": name, "order": nextOrder},
+		}
+		nextOrder++
+		return config
+	}
+
+	for _, op := range p {
+		switch op.Op {
+		case OpRename:
+			if config, ok := entries[op.Field]; ok {
+				entries[op.To] = config
+				delete(entries, op.Field)
+			}
+
+		case OpDrop:
+			delete(entries, op.Field)
+
+		case OpConvert:
+			config, ok := entries[op.Field]
+			if !ok {
+				continue
+			}
+			config["type"] = op.Type
+			if op.Type == TypeEnum {
+				validation, _ := config["validation"].(map[string]interface{})
+				if validation == nil {
+					validation = map[string]interface{}{}
+				}
+				validation["options"] = op.Options
+				config["validation"] = validation
+			}
+
+		case OpSplit:
+			for _, target := range op.Into {
+				entries[target] = newField(target)
+			}
+			if !op.Keep {
+				delete(entries, op.Field)
+			}
+
+		case OpMerge:
+			if !op.Keep {
+				for _, source := range op.Fields {
+					delete(entries, source)
+				}
+			}
+			entries[op.To] = newField(op.To)
+		}
+	}
+
+	return json.Marshal(entries)
+}
+
+func (op Operation) separator() string {
+	if op.Separator == "" {
+		return " "
+	}
+	return op.Separator
+}
+
+// splitValue splits s into at most n parts, the last part keeps the remainder
+func splitValue(s, sep string, n int) []string {
+	parts := strings.SplitN(strings.TrimSpace(s), sep, n)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func stringify(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			parts = append(parts, stringify(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		if name, ok := t["name"].(string); ok {
+			return name
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func convertValue(v interface{}, toType string, options []string, multiple bool) (interface{}, error) {
+	if items, ok := v.([]interface{}); ok && multiple {
+		converted := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			c, err := Coerce(item, toType, options)
+			if err != nil {
+				return nil, err
+			}
+			converted = append(converted, c)
+		}
+		return converted, nil
+	}
+	return Coerce(v, toType, options)
+}
+
+// Coerce converts a stored value to the given field type
+func Coerce(v interface{}, toType string, options []string) (interface{}, error) {
+	switch toType {
+	case TypeNumber, TypePercentage:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+		s := strings.TrimSpace(stringify(v))
+		s = strings.TrimLeft(s, "$€£¥")
+		s = strings.TrimSuffix(s, "%")
+		s = strings.ReplaceAll(s, ",", "")
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", stringify(v))
+		}
+		return n, nil
+
+	case TypeDate, TypeDateTime:
+		t, ok := ParseTime(stringify(v))
+		if !ok {
+			return nil, fmt.Errorf("%q is not a date", stringify(v))
+		}
+		if toType == TypeDate {
+			return t.Format("2006-01-02"), nil
+		}
+		return t.Format(time.RFC3339), nil
+
+	case TypeBoolean:
+		b, ok := ToBool(v)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a yes/no value", stringify(v))
+		}
+		return b, nil
+
+	case TypeYesNo:
+		b, ok := ToBool(v)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a yes/no value", stringify(v))
+		}
+		if b {
+			return "yes", nil
+		}
+		return "no", nil
+
+	case TypeEmail:
+		s := strings.TrimSpace(stringify(v))
+		if !IsEmail(s) {
+			return nil, fmt.Errorf("%q is not an email address", s)
+		}
+		return s, nil
+
+	case TypeURL:
+		s := strings.TrimSpace(stringify(v))
+		if !IsURL(s) {
+			return nil, fmt.Errorf("%q is not a URL", s)
+		}
+		return s, nil
+
+	case TypePhone:
+		s := strings.TrimSpace(stringify(v))
+		if !IsPhone(s) {
+			return nil, fmt.Errorf("%q is not a phone number", s)
+		}
+		return s, nil
+
+	case TypeEnum:
+		s := strings.TrimSpace(stringify(v))
+		for _, option := range options {
+			if strings.EqualFold(option, s) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of: %s", s, strings.Join(options, ", "))
+
+	case TypeObject:
+		if _, ok := ReferenceID(v); !ok {
+			return nil, fmt.Errorf("%q is not an object reference", stringify(v))
+		}
+		return v, nil
+	}
+
+	return stringify(v), nil
+}
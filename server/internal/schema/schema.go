@@ -0,0 +1,226 @@
+// Package schema describes the fields of an object type and checks
+// obj_type_value.type_values against them.
+//
+// obj_type.fields is stored as a JSON object keyed by field name. Each entry
+// is either the legacy bare type string ("string") or the full config written
+// by the webapp:
+//
+//	{"type": "enum", "validation": {"required": true, "options": ["a", "b"]}, "meta": {...}}
+//
+// A field may also be restricted to admins and named roles with
+// "access": {"restricted": true, "readRoles": [...], "writeRoles": [...]}.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Supported field types
+const (
+	TypeString     = "string"
+	TypeNumber     = "number"
+	TypePercentage = "percentage"
+	TypeDate       = "date"
+	TypeDateTime   = "datetime"
+	TypeBoolean    = "boolean"
+	TypeYesNo      = "yesno"
+	TypeEmail      = "email"
+	TypeURL        = "url"
+	TypePhone      = "phone"
+	TypeEnum       = "enum"
+	TypeObject     = "object"
+	TypeImage      = "image"
+	// TypeComputed fields are not stored, they are evaluated from Expression on read
+	TypeComputed = "computed"
+)
+
+var knownTypes = map[string]bool{
+	TypeString:     true,
+	TypeNumber:     true,
+	TypePercentage: true,
+	TypeDate:       true,
+	TypeDateTime:   true,
+	TypeBoolean:    true,
+	TypeYesNo:      true,
+	TypeEmail:      true,
+	TypeURL:        true,
+	TypePhone:      true,
+	TypeEnum:       true,
+	TypeObject:     true,
+	TypeImage:      true,
+	TypeComputed:   true,
+}
+
+// Validation holds the rules of a field. Min and Max are kept raw because
+// their meaning depends on the field type: a value bound for numbers, a date
+// for datetimes and an item count for multi-value fields.
+type Validation struct {
+	Required     bool            `json:"required,omitempty"`
+	Multiple     bool            `json:"multiple,omitempty"`
+	Options      []string        `json:"options,omitempty"`
+	Min          json.RawMessage `json:"min,omitempty"`
+	Max          json.RawMessage `json:"max,omitempty"`
+	MinLength    int             `json:"minLength,omitempty"`
+	MaxLength    int             `json:"maxLength,omitempty"`
+	Regex        string          `json:"regex,omitempty"`
+	RegexMessage string          `json:"regexMessage,omitempty"`
+	// Unique values may only be used by one object of the org
+	Unique bool `json:"unique,omitempty"`
+	// Type narrows a datetime field to "date", "datetime" or "time"
+	Type string `json:"type,omitempty"`
+}
+
+// Field is the definition of a single object type field
+type Field struct {
+	Name       string          `json:"-"`
+	Type       string          `json:"type"`
+	Expression string          `json:"expression,omitempty"`
+	Validation Validation      `json:"validation"`
+	Access     Access          `json:"access"`
+	Meta       json.RawMessage `json:"meta,omitempty"`
+}
+
+// Schema is the parsed form of obj_type.fields
+type Schema struct {
+	Fields map[string]Field
+}
+
+// Parse reads obj_type.fields. An empty object, an empty array or null all
+// produce an empty schema, which accepts any type values.
+func Parse(raw json.RawMessage) (Schema, error) {
+	s := Schema{Fields: map[string]Field{}}
+	if len(raw) == 0 || string(raw) == "null" {
+		return s, nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		if len(list) > 0 {
+			return s, fmt.Errorf("fields must be an object keyed by field name")
+		}
+		return s, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return s, fmt.Errorf("fields must be an object keyed by field name: %w", err)
+	}
+
+	for name, entry := range entries {
+		field := Field{Name: name}
+		// Legacy format: the entry is just the type name
+		var typeName string
+		if err := json.Unmarshal(entry, &typeName); err == nil {
+			field.Type = typeName
+		} else if len(entry) > 0 && entry[0] != '{' {
+			// Very old rows hold example values instead of a type
+			field.Type = TypeString
+		} else if err := json.Unmarshal(entry, &field); err != nil {
+			return s, fmt.Errorf("field %q: %w", name, err)
+		}
+		if field.Type == "" {
+			field.Type = TypeString
+		}
+		s.Fields[name] = field
+	}
+
+	return s, nil
+}
+
+// Load fetches and parses the schema of an object type
+func Load(ctx context.Context, db *database.Queries, typeID uuid.UUID) (Schema, error) {
+	objType, err := db.GetObjectTypeByID(ctx, typeID)
+	if err != nil {
+		return Schema{}, err
+	}
+	return Parse(objType.Fields)
+}
+
+// ErrMigrating is returned for writes to the values or the fields of an
+// object type while a schema migration rewrites them
+var ErrMigrating = errors.New("object type is being migrated, try again once the migration is done")
+
+// CheckNotMigrating returns ErrMigrating while a schema migration locks the
+// object type
+func CheckNotMigrating(ctx context.Context, db *database.Queries, typeID uuid.UUID) error {
+	_, err := db.GetRunningSchemaMigration(ctx, typeID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrMigrating
+}
+
+// IsMigrating tells if err is ErrMigrating, or the error of a write the
+// database refused because a schema migration locks the type
+func IsMigrating(err error) bool {
+	var pqErr *pq.Error
+	return errors.Is(err, ErrMigrating) || (errors.As(err, &pqErr) && pqErr.Code == "55006")
+}
+
+// IsEmpty reports whether the object type declares no fields at all
+func (s Schema) IsEmpty() bool {
+	return len(s.Fields) == 0
+}
+
+// Names returns the field names in a stable order
+func (s Schema) Names() []string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check validates the field definitions themselves, it is run when an object
+// type is created or updated.
+func (s Schema) Check() error {
+	var errs []FieldError
+	for _, name := range s.Names() {
+		field := s.Fields[name]
+		if name == "" {
+			errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: "field name must not be empty"})
+			continue
+		}
+		if !knownTypes[field.Type] {
+			errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: fmt.Sprintf("unknown field type %q", field.Type)})
+			continue
+		}
+		if field.Type == TypeComputed {
+			errs = append(errs, s.checkComputed(name)...)
+		}
+		if field.Validation.Unique && !canBeUnique(field) {
+			errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: fmt.Sprintf("%s fields cannot be unique", field.Type)})
+		}
+		for _, role := range append(append([]string{}, field.Access.ReadRoles...), field.Access.WriteRoles...) {
+			if role == "" {
+				errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: "access roles must not be empty"})
+				break
+			}
+		}
+		if field.Type == TypeEnum && len(field.Validation.Options) == 0 {
+			errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: "enum field must declare options"})
+		}
+		if field.Validation.Regex != "" {
+			if _, err := compileRegex(field.Validation.Regex); err != nil {
+				errs = append(errs, FieldError{Field: name, Code: CodeInvalidDefinition, Message: fmt.Sprintf("invalid regex: %v", err)})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
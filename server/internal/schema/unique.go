@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// UniqueConflictError is returned when a unique field value is already used
+// by another object of the org
+type UniqueConflictError struct {
+	Field    string    `json:"field"`
+	Value    string    `json:"value"`
+	ObjectID uuid.UUID `json:"object_id"`
+	Name     string    `json:"object_name"`
+	IDString string    `json:"object_id_string"`
+}
+
+func (e *UniqueConflictError) Error() string {
+	return fmt.Sprintf("%s %q is already used by object %s (%s)", e.Field, e.Value, e.Name, e.ObjectID)
+}
+
+func canBeUnique(field Field) bool {
+	if field.Validation.Multiple {
+		return false
+	}
+	switch field.Type {
+	case TypeComputed, TypeObject, TypeImage, TypeBoolean, TypeYesNo:
+		return false
+	}
+	return true
+}
+
+// UniqueFields returns the names of the fields marked unique
+func (s Schema) UniqueFields() []string {
+	var names []string
+	for _, name := range s.Names() {
+		if s.Fields[name].Validation.Unique {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// UniqueKey normalizes a value the way unique fields are compared: trimmed
+// and case-insensitive. It returns false for blank values, which are never
+// checked.
+func UniqueKey(v interface{}) (string, bool) {
+	if isBlank(v) {
+		return "", false
+	}
+	key := strings.ToLower(strings.TrimSpace(stringify(v)))
+	return key, key != ""
+}
+
+// FindByUniqueValue returns the object of the org whose type value holds
+// value in field, ignoring the objects in exclude
+func FindByUniqueValue(ctx context.Context, db *database.Queries, orgID, typeID uuid.UUID, field string, value interface{}, exclude ...uuid.UUID) (*database.FindObjectByTypeValueRow, error) {
+	key, ok := UniqueKey(value)
+	if !ok {
+		return nil, nil
+	}
+	if exclude == nil {
+		exclude = []uuid.UUID{}
+	}
+	row, err := db.FindObjectByTypeValue(ctx, database.FindObjectByTypeValueParams{
+		TypeID:  typeID,
+		OrgID:   orgID,
+		Column3: exclude,
+		Column4: field,
+		Column5: key,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// CheckUnique makes sure no other object of the org uses the values of the
+// unique fields. The objects in exclude, usually the one being written, are
+// ignored. The database checks again on commit for the writes racing this
+// check, see UniqueViolation.
+func (s Schema) CheckUnique(ctx context.Context, db *database.Queries, orgID, typeID uuid.UUID, values map[string]interface{}, exclude ...uuid.UUID) error {
+	for _, name := range s.UniqueFields() {
+		existing, err := FindByUniqueValue(ctx, db, orgID, typeID, name, values[name], exclude...)
+		if err != nil {
+			return fmt.Errorf("error checking unique field %s: %w", name, err)
+		}
+		if existing != nil {
+			return &UniqueConflictError{
+				Field:    name,
+				Value:    stringify(values[name]),
+				ObjectID: existing.ID,
+				Name:     existing.Name,
+				IDString: existing.IDString,
+			}
+		}
+	}
+	return nil
+}
+
+// CheckUniqueRaw is CheckUnique for raw type_values JSON
+func (s Schema) CheckUniqueRaw(ctx context.Context, db *database.Queries, orgID, typeID uuid.UUID, raw json.RawMessage, exclude ...uuid.UUID) error {
+	if len(s.UniqueFields()) == 0 {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+	return s.CheckUnique(ctx, db, orgID, typeID, values, exclude...)
+}
+
+// UniqueViolation returns the conflict of a write the database refused
+// because another object took the value of a unique field since it was
+// checked, see migration 025. It returns false for any other error.
+func UniqueViolation(err error) (*UniqueConflictError, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" || pqErr.Constraint != "obj_type_value_unique_field" {
+		return nil, false
+	}
+	objectID, _ := uuid.Parse(pqErr.Detail)
+	return &UniqueConflictError{Field: pqErr.Column, ObjectID: objectID}, true
+}
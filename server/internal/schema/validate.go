@@ -0,0 +1,485 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Error codes reported in FieldError.Code
+const (
+	CodeRequired          = "required"
+	CodeUnknownField      = "unknown_field"
+	CodeInvalidType       = "invalid_type"
+	CodeInvalidNumber     = "invalid_number"
+	CodeInvalidDate       = "invalid_date"
+	CodeInvalidBoolean    = "invalid_boolean"
+	CodeInvalidEmail      = "invalid_email"
+	CodeInvalidURL        = "invalid_url"
+	CodeInvalidPhone      = "invalid_phone"
+	CodeInvalidOption     = "invalid_option"
+	CodeInvalidReference  = "invalid_reference"
+	CodeOutOfRange        = "out_of_range"
+	CodeInvalidLength     = "invalid_length"
+	CodeInvalidFormat     = "invalid_format"
+	CodeInvalidDefinition = "invalid_definition"
+	CodeReadOnly          = "read_only"
+	CodeUnique            = "unique"
+)
+
+// FieldError describes why a single field was rejected
+type FieldError struct {
+	Field   string      `json:"field"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+// ValidationError is returned when type values do not match the schema
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+	}
+	return "invalid type values: " + strings.Join(msgs, "; ")
+}
+
+var (
+	phoneRegex     = regexp.MustCompile(`^\+?[0-9\-\(\)\.\s]+$`)
+	regexCache     = map[string]*regexp.Regexp{}
+	regexCacheLock sync.Mutex
+)
+
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	regexCacheLock.Lock()
+	defer regexCacheLock.Unlock()
+	if re, ok := regexCache[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache[expr] = re
+	return re, nil
+}
+
+// ValidateRaw is a convenience wrapper around Validate for JSON input
+func (s Schema) ValidateRaw(raw json.RawMessage) error {
+	return s.ValidateRawChange(raw, nil)
+}
+
+// ValidateRawChange is ValidateChange for JSON input
+func (s Schema) ValidateRawChange(raw, stored json.RawMessage) error {
+	var values, storedValues map[string]interface{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return &ValidationError{Errors: []FieldError{{
+				Code:    CodeInvalidType,
+				Message: "type values must be a JSON object",
+			}}}
+		}
+	}
+	if len(stored) > 0 {
+		json.Unmarshal(stored, &storedValues)
+	}
+	return s.ValidateChange(values, storedValues)
+}
+
+// Validate checks a full set of type values. An empty schema accepts
+// anything so object types created before schemas existed keep working.
+func (s Schema) Validate(values map[string]interface{}) error {
+	return s.ValidateChange(values, nil)
+}
+
+// ValidateChange is Validate for values replacing stored ones. Keys no longer
+// defined on the type are only rejected when they are written, the ones
+// carrying their stored value through are kept so that objects holding them
+// can still be updated.
+func (s Schema) ValidateChange(values, stored map[string]interface{}) error {
+	if s.IsEmpty() {
+		return nil
+	}
+
+	var errs []FieldError
+	for _, name := range s.Names() {
+		field := s.Fields[name]
+		value, ok := values[name]
+		if field.Type == TypeComputed {
+			if ok {
+				errs = append(errs, FieldError{Field: name, Code: CodeReadOnly, Message: "computed fields cannot be written"})
+			}
+			continue
+		}
+		if !ok || isBlank(value) {
+			if field.Validation.Required {
+				errs = append(errs, FieldError{Field: name, Code: CodeRequired, Message: "this field is required"})
+			}
+			continue
+		}
+		if fe := field.ValidateValue(value); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+
+	for name, value := range values {
+		if _, ok := s.Fields[name]; ok {
+			continue
+		}
+		if storedValue, ok := stored[name]; !ok || !reflect.DeepEqual(storedValue, value) {
+			errs = append(errs, FieldError{Field: name, Code: CodeUnknownField, Message: "field is not defined on this object type", Value: value})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateValue checks a single non-blank value against the field
+func (f Field) ValidateValue(value interface{}) *FieldError {
+	if f.Validation.Multiple {
+		items, ok := value.([]interface{})
+		if !ok {
+			// A single value is accepted for a multi-value field
+			items = []interface{}{value}
+		}
+		if fe := f.checkCount(len(items)); fe != nil {
+			return fe
+		}
+		for _, item := range items {
+			if fe := f.validateOne(item); fe != nil {
+				return fe
+			}
+		}
+		return nil
+	}
+
+	if _, ok := value.([]interface{}); ok {
+		return f.fail(CodeInvalidType, "a single value is expected", value)
+	}
+	return f.validateOne(value)
+}
+
+func (f Field) validateOne(value interface{}) *FieldError {
+	switch f.Type {
+	case TypeNumber, TypePercentage:
+		n, ok := toNumber(value)
+		if !ok {
+			return f.fail(CodeInvalidNumber, "must be a number", value)
+		}
+		return f.checkNumberRange(n, value)
+
+	case TypeDate, TypeDateTime:
+		s, ok := value.(string)
+		if !ok {
+			return f.fail(CodeInvalidDate, "must be a date string", value)
+		}
+		t, ok := ParseTime(s)
+		if !ok {
+			return f.fail(CodeInvalidDate, "must be a valid date (YYYY-MM-DD or RFC 3339)", value)
+		}
+		return f.checkDateRange(t, value)
+
+	case TypeBoolean, TypeYesNo:
+		if _, ok := ToBool(value); !ok {
+			return f.fail(CodeInvalidBoolean, "must be yes/no or true/false", value)
+		}
+
+	case TypeEmail:
+		s, ok := value.(string)
+		if !ok || !IsEmail(s) {
+			return f.fail(CodeInvalidEmail, "must be a valid email address", value)
+		}
+
+	case TypeURL:
+		s, ok := value.(string)
+		if !ok || !IsURL(s) {
+			return f.fail(CodeInvalidURL, "must be a valid URL", value)
+		}
+
+	case TypePhone:
+		s, ok := value.(string)
+		if !ok || !IsPhone(s) {
+			return f.fail(CodeInvalidPhone, "must be a valid phone number", value)
+		}
+
+	case TypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return f.fail(CodeInvalidOption, "must be one of the allowed options", value)
+		}
+		for _, option := range f.Validation.Options {
+			if option == s {
+				return nil
+			}
+		}
+		msg := fmt.Sprintf("must be one of: %s", strings.Join(f.Validation.Options, ", "))
+		if suggestion := closestOption(s, f.Validation.Options); suggestion != "" {
+			msg = fmt.Sprintf("%s (did you mean %q?)", msg, suggestion)
+		}
+		return f.fail(CodeInvalidOption, msg, value)
+
+	case TypeObject:
+		if _, ok := ReferenceID(value); !ok {
+			return f.fail(CodeInvalidReference, "must reference an object by id", value)
+		}
+
+	default:
+		// string, image and anything the webapp adds later are free text
+		s, ok := value.(string)
+		if !ok {
+			if _, isNumber := value.(float64); !isNumber {
+				return f.fail(CodeInvalidType, "must be text", value)
+			}
+			s = fmt.Sprint(value)
+		}
+		return f.checkString(s, value)
+	}
+
+	return nil
+}
+
+func (f Field) fail(code, message string, value interface{}) *FieldError {
+	return &FieldError{Field: f.Name, Code: code, Message: message, Value: value}
+}
+
+func (f Field) checkCount(n int) *FieldError {
+	if min, ok := rawNumber(f.Validation.Min); ok && f.Type == TypeObject && float64(n) < min {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("select at least %v items", min), n)
+	}
+	if max, ok := rawNumber(f.Validation.Max); ok && f.Type == TypeObject && float64(n) > max {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("select at most %v items", max), n)
+	}
+	return nil
+}
+
+func (f Field) checkNumberRange(n float64, value interface{}) *FieldError {
+	if min, ok := rawNumber(f.Validation.Min); ok && n < min {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("must be at least %v", min), value)
+	}
+	if max, ok := rawNumber(f.Validation.Max); ok && n > max {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("must be at most %v", max), value)
+	}
+	return nil
+}
+
+func (f Field) checkDateRange(t time.Time, value interface{}) *FieldError {
+	if min, ok := rawTime(f.Validation.Min); ok && t.Before(min) {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("must be after %s", min.Format(time.RFC3339)), value)
+	}
+	if max, ok := rawTime(f.Validation.Max); ok && t.After(max) {
+		return f.fail(CodeOutOfRange, fmt.Sprintf("must be before %s", max.Format(time.RFC3339)), value)
+	}
+	return nil
+}
+
+func (f Field) checkString(s string, value interface{}) *FieldError {
+	v := f.Validation
+	if v.MinLength > 0 && len([]rune(s)) < v.MinLength {
+		return f.fail(CodeInvalidLength, fmt.Sprintf("minimum length is %d characters", v.MinLength), value)
+	}
+	if v.MaxLength > 0 && len([]rune(s)) > v.MaxLength {
+		return f.fail(CodeInvalidLength, fmt.Sprintf("maximum length is %d characters", v.MaxLength), value)
+	}
+	if v.Regex != "" {
+		re, err := compileRegex(v.Regex)
+		if err == nil && !re.MatchString(s) {
+			msg := v.RegexMessage
+			if msg == "" {
+				msg = "invalid format"
+			}
+			return f.fail(CodeInvalidFormat, msg, value)
+		}
+	}
+	return nil
+}
+
+func isBlank(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func rawNumber(raw json.RawMessage) (float64, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, false
+	}
+	return toNumber(v)
+}
+
+func rawTime(raw json.RawMessage) (time.Time, bool) {
+	if len(raw) == 0 {
+		return time.Time{}, false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil || s == "" {
+		return time.Time{}, false
+	}
+	return ParseTime(s)
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"15:04",
+	"15:04:05",
+}
+
+// ParseTime accepts the date formats the webapp and importers produce
+func ParseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ToBool accepts JSON booleans and the yes/no strings used by the webapp
+func ToBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "true", "y", "1":
+			return true, true
+		case "no", "false", "n", "0":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// IsEmail reports whether s is a bare email address
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == strings.TrimSpace(s)
+}
+
+// IsURL accepts absolute http(s) URLs as well as bare domains like example.com/path
+func IsURL(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return strings.Contains(u.Hostname(), ".") || u.Hostname() == "localhost"
+}
+
+// IsPhone accepts digits with the usual separators and at least 6 digits
+func IsPhone(s string) bool {
+	if !phoneRegex.MatchString(s) {
+		return false
+	}
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 6 && digits <= 15
+}
+
+// ReferenceID extracts the object id from an object field value, which is
+// either the id itself or {"id": ..., "name": ...}
+func ReferenceID(value interface{}) (uuid.UUID, bool) {
+	switch v := value.(type) {
+	case string:
+		id, err := uuid.Parse(v)
+		return id, err == nil
+	case map[string]interface{}:
+		if s, ok := v["id"].(string); ok {
+			id, err := uuid.Parse(s)
+			return id, err == nil
+		}
+	}
+	return uuid.Nil, false
+}
+
+// closestOption suggests an enum option for a likely typo
+func closestOption(value string, options []string) string {
+	best := ""
+	bestDistance := 3 // only suggest close matches
+	lower := strings.ToLower(value)
+	for _, option := range options {
+		if strings.ToLower(option) == lower {
+			return option
+		}
+		if d := levenshtein(lower, strings.ToLower(option)); d < bestDistance {
+			best = option
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,347 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFields = `{
+	"name": {"type": "string", "validation": {"required": true, "minLength": 2, "maxLength": 5}},
+	"code": {"type": "string", "validation": {"regex": "^[A-Z]+$", "regexMessage": "upper case letters only"}},
+	"slug": {"type": "string", "validation": {"regex": "^[a-z]+$"}},
+	"amount": {"type": "number", "validation": {"min": 0, "max": 100}},
+	"due": {"type": "datetime", "validation": {"min": "2024-01-01", "max": "2024-12-31"}},
+	"active": {"type": "yesno"},
+	"email": {"type": "email"},
+	"site": {"type": "url"},
+	"phone": {"type": "phone"},
+	"stage": {"type": "enum", "validation": {"options": ["Lead", "Customer"]}},
+	"owner": {"type": "object"},
+	"members": {"type": "object", "validation": {"multiple": true, "min": 1, "max": 2}},
+	"tags": {"type": "string", "validation": {"multiple": true}},
+	"score": {"type": "computed", "expression": "amount * 2"}
+}`
+
+const (
+	id1 = "7b1e5a52-3f0c-4d1b-9a43-2c6f0e8d1a01"
+	id2 = "7b1e5a52-3f0c-4d1b-9a43-2c6f0e8d1a02"
+	id3 = "7b1e5a52-3f0c-4d1b-9a43-2c6f0e8d1a03"
+)
+
+func testSchema(t *testing.T) Schema {
+	t.Helper()
+	s, err := Parse(json.RawMessage(testFields))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	return s
+}
+
+func TestValidateValue(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		field   string
+		value   interface{}
+		code    string
+		message string
+	}{
+		{field: "name", value: "héllo"},
+		{field: "name", value: 12.0},
+		{field: "name", value: "a", code: CodeInvalidLength, message: "minimum length is 2 characters"},
+		{field: "name", value: "abcdef", code: CodeInvalidLength, message: "maximum length is 5 characters"},
+		{field: "name", value: true, code: CodeInvalidType, message: "must be text"},
+		{field: "name", value: []interface{}{"ab"}, code: CodeInvalidType, message: "a single value is expected"},
+		{field: "code", value: "ABC"},
+		{field: "code", value: "abc", code: CodeInvalidFormat, message: "upper case letters only"},
+		{field: "slug", value: "ABC", code: CodeInvalidFormat, message: "invalid format"},
+		{field: "amount", value: "42"},
+		{field: "amount", value: 100.0},
+		{field: "amount", value: "x", code: CodeInvalidNumber, message: "must be a number"},
+		{field: "amount", value: -1.0, code: CodeOutOfRange, message: "must be at least 0"},
+		{field: "amount", value: 100.5, code: CodeOutOfRange, message: "must be at most 100"},
+		{field: "due", value: "2024-06-01"},
+		{field: "due", value: "2024-06-01T10:00:00+07:00"},
+		{field: "due", value: "2023-12-31", code: CodeOutOfRange, message: "must be after 2024-01-01T00:00:00Z"},
+		{field: "due", value: "2025-01-01T00:00:00Z", code: CodeOutOfRange, message: "must be before 2024-12-31T00:00:00Z"},
+		{field: "due", value: "June", code: CodeInvalidDate, message: "must be a valid date (YYYY-MM-DD or RFC 3339)"},
+		{field: "due", value: 5.0, code: CodeInvalidDate, message: "must be a date string"},
+		{field: "active", value: "Yes"},
+		{field: "active", value: false},
+		{field: "active", value: "maybe", code: CodeInvalidBoolean, message: "must be yes/no or true/false"},
+		{field: "email", value: "jane@example.com"},
+		{field: "email", value: "Jane <jane@example.com>", code: CodeInvalidEmail, message: "must be a valid email address"},
+		{field: "email", value: 1.0, code: CodeInvalidEmail, message: "must be a valid email address"},
+		{field: "site", value: "example.com/path"},
+		{field: "site", value: "http://localhost:3000"},
+		{field: "site", value: "ftp://example.com", code: CodeInvalidURL, message: "must be a valid URL"},
+		{field: "site", value: "intranet", code: CodeInvalidURL, message: "must be a valid URL"},
+		{field: "phone", value: "+84 (28) 123-456"},
+		{field: "phone", value: "12345", code: CodeInvalidPhone, message: "must be a valid phone number"},
+		{field: "phone", value: "call 123456", code: CodeInvalidPhone, message: "must be a valid phone number"},
+		{field: "stage", value: "Lead"},
+		{field: "stage", value: "lead", code: CodeInvalidOption, message: `must be one of: Lead, Customer (did you mean "Lead"?)`},
+		{field: "stage", value: "Custmer", code: CodeInvalidOption, message: `must be one of: Lead, Customer (did you mean "Customer"?)`},
+		{field: "stage", value: "Prospect", code: CodeInvalidOption, message: "must be one of: Lead, Customer"},
+		{field: "stage", value: 1.0, code: CodeInvalidOption, message: "must be one of the allowed options"},
+		{field: "owner", value: id1},
+		{field: "owner", value: map[string]interface{}{"id": id1, "name": "Acme"}},
+		{field: "owner", value: "Acme", code: CodeInvalidReference, message: "must reference an object by id"},
+		{field: "members", value: id1},
+		{field: "members", value: []interface{}{id1, id2}},
+		{field: "members", value: []interface{}{}, code: CodeOutOfRange, message: "select at least 1 items"},
+		{field: "members", value: []interface{}{id1, id2, id3}, code: CodeOutOfRange, message: "select at most 2 items"},
+		{field: "members", value: []interface{}{id1, "Acme"}, code: CodeInvalidReference, message: "must reference an object by id"},
+		{field: "tags", value: []interface{}{"a", 1.0}},
+		{field: "tags", value: []interface{}{"a", true}, code: CodeInvalidType, message: "must be text"},
+	}
+	for _, tt := range tests {
+		fe := s.Fields[tt.field].ValidateValue(tt.value)
+		switch {
+		case tt.code == "" && fe != nil:
+			t.Errorf("%s %#v: %+v, want no error", tt.field, tt.value, *fe)
+		case tt.code != "" && fe == nil:
+			t.Errorf("%s %#v: no error, want %s %q", tt.field, tt.value, tt.code, tt.message)
+		case fe != nil && (fe.Field != tt.field || fe.Code != tt.code || fe.Message != tt.message):
+			t.Errorf("%s %#v: %+v, want %s %q", tt.field, tt.value, *fe, tt.code, tt.message)
+		}
+	}
+}
+
+func TestValidateChange(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		stored map[string]interface{}
+		errors []FieldError
+	}{
+		{
+			name:   "valid",
+			values: map[string]interface{}{"name": "Acme", "amount": 5.0, "owner": nil, "tags": []interface{}{}},
+		},
+		{
+			name:   "required",
+			values: map[string]interface{}{"name": "  "},
+			errors: []FieldError{{Field: "name", Code: CodeRequired, Message: "this field is required"}},
+		},
+		{
+			name:   "missing required",
+			values: nil,
+			errors: []FieldError{{Field: "name", Code: CodeRequired, Message: "this field is required"}},
+		},
+		{
+			name:   "computed",
+			values: map[string]interface{}{"name": "Acme", "score": 1.0},
+			errors: []FieldError{{Field: "score", Code: CodeReadOnly, Message: "computed fields cannot be written"}},
+		},
+		{
+			name:   "errors in field order",
+			values: map[string]interface{}{"name": "Acme", "stage": "x", "amount": "x"},
+			errors: []FieldError{
+				{Field: "amount", Code: CodeInvalidNumber, Message: "must be a number", Value: "x"},
+				{Field: "stage", Code: CodeInvalidOption, Message: "must be one of: Lead, Customer", Value: "x"},
+			},
+		},
+		{
+			name:   "unknown field",
+			values: map[string]interface{}{"name": "Acme", "legacy": "x"},
+			errors: []FieldError{{Field: "legacy", Code: CodeUnknownField, Message: "field is not defined on this object type", Value: "x"}},
+		},
+		{
+			name:   "unknown field kept",
+			values: map[string]interface{}{"name": "Acme", "legacy": "x"},
+			stored: map[string]interface{}{"legacy": "x"},
+		},
+		{
+			name:   "unknown field changed",
+			values: map[string]interface{}{"name": "Acme", "legacy": "y"},
+			stored: map[string]interface{}{"legacy": "x"},
+			errors: []FieldError{{Field: "legacy", Code: CodeUnknownField, Message: "field is not defined on this object type", Value: "y"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErrors(t, s.ValidateChange(tt.values, tt.stored), tt.errors)
+		})
+	}
+}
+
+func TestValidateEmptySchema(t *testing.T) {
+	s, err := Parse(json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := s.Validate(map[string]interface{}{"anything": []interface{}{1.0}}); err != nil {
+		t.Errorf("Validate = %v, want nil", err)
+	}
+}
+
+func TestValidateRaw(t *testing.T) {
+	s := testSchema(t)
+	checkErrors(t, s.ValidateRaw(json.RawMessage(`[1]`)), []FieldError{
+		{Code: CodeInvalidType, Message: "type values must be a JSON object"},
+	})
+	checkErrors(t, s.ValidateRaw(json.RawMessage(`null`)), []FieldError{
+		{Field: "name", Code: CodeRequired, Message: "this field is required"},
+	})
+	checkErrors(t, s.ValidateRawChange(json.RawMessage(`{"name": "Acme", "legacy": 1}`), json.RawMessage(`{"legacy": 1}`)), nil)
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Errors: []FieldError{
+		{Field: "name", Code: CodeRequired, Message: "this field is required"},
+		{Field: "amount", Code: CodeInvalidNumber, Message: "must be a number"},
+	}}
+	want := "invalid type values: name: this field is required; amount: must be a number"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	for _, raw := range []string{``, `null`, `[]`, `{}`} {
+		s, err := Parse(json.RawMessage(raw))
+		if err != nil || !s.IsEmpty() {
+			t.Errorf("Parse(%q) = %v, %v, want an empty schema", raw, s.Fields, err)
+		}
+	}
+
+	s, err := Parse(json.RawMessage(`{"a": "number", "b": 12, "c": {"validation": {"required": true}}}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	types := map[string]string{}
+	for name, field := range s.Fields {
+		types[name] = field.Type
+	}
+	if want := map[string]string{"a": TypeNumber, "b": TypeString, "c": TypeString}; !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %v, want %v", types, want)
+	}
+	if !s.Fields["c"].Validation.Required || s.Fields["c"].Name != "c" {
+		t.Errorf("field c = %+v", s.Fields["c"])
+	}
+	if got := s.Names(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Names = %v", got)
+	}
+
+	errs := []struct {
+		raw    string
+		prefix string
+	}{
+		{`[1]`, "fields must be an object keyed by field name"},
+		{`"x"`, "fields must be an object keyed by field name: "},
+		{`{"a": {"type": 1}}`, `field "a": `},
+	}
+	for _, tt := range errs {
+		if _, err := Parse(json.RawMessage(tt.raw)); err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Errorf("Parse(%q) error = %v, want %q...", tt.raw, err, tt.prefix)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		errors []FieldError
+	}{
+		{
+			name:   "valid",
+			fields: testFields,
+		},
+		{
+			name:   "empty name",
+			fields: `{"": {"type": "string"}}`,
+			errors: []FieldError{{Field: "", Code: CodeInvalidDefinition, Message: "field name must not be empty"}},
+		},
+		{
+			name:   "unknown type",
+			fields: `{"a": {"type": "money"}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: `unknown field type "money"`}},
+		},
+		{
+			name:   "enum without options",
+			fields: `{"a": {"type": "enum"}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "enum field must declare options"}},
+		},
+		{
+			name:   "invalid regex",
+			fields: `{"a": {"type": "string", "validation": {"regex": "("}}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "invalid regex: error parsing regexp: missing closing ): `(`"}},
+		},
+		{
+			name:   "unique boolean",
+			fields: `{"a": {"type": "boolean", "validation": {"unique": true}}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "boolean fields cannot be unique"}},
+		},
+		{
+			name:   "unique multiple",
+			fields: `{"a": {"type": "string", "validation": {"unique": true, "multiple": true}}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "string fields cannot be unique"}},
+		},
+		{
+			name:   "empty role",
+			fields: `{"a": {"type": "string", "access": {"restricted": true, "readRoles": ["sales", ""], "writeRoles": [""]}}}`,
+			errors: []FieldError{{Field: "a", Code: CodeInvalidDefinition, Message: "access roles must not be empty"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(json.RawMessage(tt.fields))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			checkErrors(t, s.Check(), tt.errors)
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), true},
+		{" 2024-01-31T10:30 ", time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC), true},
+		{"2024-01-31 10:30:15", time.Date(2024, 1, 31, 10, 30, 15, 0, time.UTC), true},
+		{"2024-01-31T10:30:15Z", time.Date(2024, 1, 31, 10, 30, 15, 0, time.UTC), true},
+		{"2024-01-31T10:30:15+07:00", time.Date(2024, 1, 31, 3, 30, 15, 0, time.UTC), true},
+		{"09:15", time.Date(0, 1, 1, 9, 15, 0, 0, time.UTC), true},
+		{"31/01/2024", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.value)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestToBool(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+		ok    bool
+	}{
+		{true, true, true},
+		{" YES ", true, true},
+		{"y", true, true},
+		{"1", true, true},
+		{"No", false, true},
+		{"false", false, true},
+		{"maybe", false, false},
+		{1.0, false, false},
+		{nil, false, false},
+	}
+	for _, tt := range tests {
+		got, ok := ToBool(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ToBool(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+// ErrRestrictedField is returned when a list is filtered or sorted on a
+// field the viewer may not read
+var ErrRestrictedField = errors.New("restricted field")
+
+// canRead reports whether the viewer may read a field in every object type
+// that declares it
+func (c *orgSchemas) canRead(name string, viewer schema.Viewer) bool {
+	for _, typeSchema := range c.schemas {
+		if !typeSchema.CanRead(name, viewer) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkReadable rejects criteria and ordering on restricted fields, they
+// would reveal the hidden values
+func (c *orgSchemas) checkReadable(params ListObjectsParams) error {
+	if params.OrderBy == OrderByTypeValue && !c.canRead(params.TypeValueField, params.Viewer) {
+		return fmt.Errorf("%w: cannot order by %s", ErrRestrictedField, params.TypeValueField)
+	}
+	for _, raw := range params.TypeValueCriteria {
+		for _, key := range criteriaKeys(raw) {
+			if !c.canRead(key, params.Viewer) {
+				return fmt.Errorf("%w: cannot filter by %s", ErrRestrictedField, key)
+			}
+		}
+	}
+	return nil
+}
+
+// redact removes the fields the viewer may not read from the type values and
+// computed values of every item, listing them under "redacted"
+func (c *orgSchemas) redact(items []database.ListObjectsAdvancedRow, viewer schema.Viewer) {
+	if viewer.IsAdmin() {
+		return
+	}
+	for _, item := range items {
+		typeValues, ok := item.TypeValues.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, tv := range typeValues {
+			entry, ok := tv.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			typeID, err := uuid.Parse(fmt.Sprint(entry["objectTypeId"]))
+			if err != nil {
+				continue
+			}
+			typeSchema, ok := c.schemas[typeID]
+			if !ok || !typeSchema.HasRestricted() {
+				continue
+			}
+			var redacted []string
+			for _, key := range []string{"type_values", "computed"} {
+				values, ok := entry[key].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				values, hidden := typeSchema.Redact(values, viewer)
+				entry[key] = values
+				redacted = append(redacted, hidden...)
+			}
+			if len(redacted) > 0 {
+				entry["redacted"] = redacted
+			}
+		}
+	}
+}
+
+func criteriaKeys(raw json.RawMessage) []string {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(decoded))
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,33 @@
+// service/address_review.go
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// QueueAddressReview puts an address seen in a fact, matching no object nor
+// member of the org, in the review queue, where it can be turned into an
+// object linked to the facts it was seen in. importTaskID is the import it
+// was seen in, if any.
+func QueueAddressReview(ctx context.Context, q *database.Queries, orgID uuid.UUID, importTaskID uuid.NullUUID, factID uuid.UUID, email, name string) error {
+	reviewID, err := q.UpsertAddressReview(ctx, database.UpsertAddressReviewParams{
+		OrgID:        orgID,
+		Email:        email,
+		Name:         name,
+		ImportTaskID: importTaskID,
+	})
+	if err != nil {
+		return fmt.Errorf("error queueing %s for review: %w", email, err)
+	}
+	if err := q.AddAddressReviewFact(ctx, database.AddAddressReviewFactParams{
+		ReviewID: reviewID,
+		FactID:   factID,
+	}); err != nil {
+		return fmt.Errorf("error queueing %s for review: %w", email, err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// service/attachment.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/blob"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// MaxAttachmentSize is the largest file accepted, in bytes
+const MaxAttachmentSize = 25 << 20
+
+var (
+	ErrAttachmentTooLarge = fmt.Errorf("file is larger than %d MB", MaxAttachmentSize>>20)
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTarget is returned when the fact or object to attach to
+	// is not an active one of the org
+	ErrAttachmentTarget = errors.New("fact or object not found")
+)
+
+// AttachmentTarget is what a file is attached to, either a fact or an object
+type AttachmentTarget struct {
+	FactID uuid.UUID
+	ObjID  uuid.UUID
+}
+
+type AttachmentService struct {
+	queries *database.Queries
+	store   blob.Store
+}
+
+func NewAttachmentService(queries *database.Queries, store blob.Store) *AttachmentService {
+	return &AttachmentService{queries: queries, store: store}
+}
+
+func attachmentKey(id uuid.UUID) string {
+	return "attachments/" + id.String()
+}
+
+// attachmentContentType sniffs the content, falling back to the file
+// extension for the formats sniffing cannot tell apart
+func attachmentContentType(filename string, data []byte) string {
+	contentType := http.DetectContentType(data)
+	if contentType == "application/octet-stream" || strings.HasPrefix(contentType, "text/plain") {
+		if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExt != "" {
+			return byExt
+		}
+	}
+	return contentType
+}
+
+// Upload stores a file and attaches it to the target. Text is extracted from
+// PDF and plain text files so facts can be found by their attachments.
+func (s *AttachmentService) Upload(ctx context.Context, target AttachmentTarget, orgID, actorID uuid.UUID, filename string, r io.Reader) (database.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return database.Attachment{}, err
+	}
+	if len(data) > MaxAttachmentSize {
+		return database.Attachment{}, ErrAttachmentTooLarge
+	}
+	filename = filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if filename == "." || filename == "/" {
+		filename = "file"
+	}
+	contentType := attachmentContentType(filename, data)
+
+	id := uuid.New()
+	if err := s.store.Put(ctx, attachmentKey(id), bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return database.Attachment{}, fmt.Errorf("error storing attachment: %w", err)
+	}
+	attachment, err := s.queries.CreateAttachment(ctx, database.CreateAttachmentParams{
+		ID:          id,
+		OrgID:       orgID,
+		FactID:      uuid.NullUUID{UUID: target.FactID, Valid: target.FactID != uuid.Nil},
+		ObjID:       uuid.NullUUID{UUID: target.ObjID, Valid: target.ObjID != uuid.Nil},
+		CreatorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		TextContent: extractText(contentType, data),
+	})
+	if err != nil {
+		s.store.Delete(ctx, attachmentKey(id))
+		if err == sql.ErrNoRows {
+			return database.Attachment{}, ErrAttachmentTarget
+		}
+		return database.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// Open returns an attachment of the org with its content
+func (s *AttachmentService) Open(ctx context.Context, id, orgID uuid.UUID) (database.Attachment, io.ReadCloser, error) {
+	attachment, err := s.queries.GetAttachment(ctx, database.GetAttachmentParams{ID: id, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		return database.Attachment{}, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return database.Attachment{}, nil, err
+	}
+	rc, _, err := s.store.Get(ctx, attachmentKey(id))
+	if err == blob.ErrNotFound {
+		return database.Attachment{}, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return database.Attachment{}, nil, err
+	}
+	return attachment, rc, nil
+}
+
+// Delete removes an attachment of the org and its file
+func (s *AttachmentService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	rows, err := s.queries.DeleteAttachment(ctx, database.DeleteAttachmentParams{ID: id, OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAttachmentNotFound
+	}
+	if err := s.store.Delete(ctx, attachmentKey(id)); err != nil {
+		// The row is gone, the file is only wasted space
+		log.Printf("Error deleting file of attachment %s: %v", id, err)
+	}
+	return nil
+}
+
+// CleanupOrphans deletes the attachments left without fact and object once
+// those were purged, files included
+func (s *AttachmentService) CleanupOrphans(ctx context.Context) (int, error) {
+	attachments, err := s.queries.ListOrphanedAttachments(ctx, 500)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, attachment := range attachments {
+		if err := s.store.Delete(ctx, attachmentKey(attachment.ID)); err != nil {
+			log.Printf("Error deleting file of attachment %s: %v", attachment.ID, err)
+			continue
+		}
+		if err := s.queries.DeleteOrphanedAttachment(ctx, attachment.ID); err != nil {
+			log.Printf("Error deleting orphaned attachment %s: %v", attachment.ID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
@@ -18,6 +20,16 @@ type FilterConfig struct {
 	TypeIDs           []uuid.UUID      `json:"typeIds,omitempty"`
 	TypeValueCriteria *TypeValueFilter `json:"typeValueCriteria,omitempty"`
 	FunnelStepFilter  *FunnelStepFilter `json:"funnelStepFilter,omitempty"`
+	HealthScore       *HealthScoreFilter `json:"healthScore,omitempty"`
+	// Query is a text query, see package query, added to the filters above
+	Query string `json:"query,omitempty"`
+}
+
+// HealthScoreFilter matches the objects whose health score is within the
+// bounds, objects not scored yet never match
+type HealthScoreFilter struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
 }
 
 type FunnelStepFilter struct {
@@ -43,7 +55,8 @@ type ActionConfig struct {
 
 // AutomationService handles the execution of automated actions
 type AutomationService struct {
-    db *database.Queries
+    db    *database.Queries
+    sqlDB *sql.DB
 }
 
 // ExecuteAction represents the result of an action execution
@@ -54,9 +67,10 @@ type ExecuteAction struct {
 }
 
 // NewAutomationService creates a new automation service
-func NewAutomationService(db *database.Queries) *AutomationService {
+func NewAutomationService(db *database.Queries, sqlDB *sql.DB) *AutomationService {
     return &AutomationService{
-        db: db,
+        db:    db,
+        sqlDB: sqlDB,
     }
 }
 
@@ -142,7 +156,19 @@ func (s *AutomationService) ExecuteAction(
         FunnelID: funnelId,
         CreatorID: action.CreatedBy,
     }
-    rows, err := s.db.AddTagAndStepToFilteredObjects(ctx, params)
+    if filterConfig.HealthScore != nil {
+        if filterConfig.HealthScore.Min != nil {
+            params.Column13 = sql.NullFloat64{Float64: *filterConfig.HealthScore.Min, Valid: true}
+        }
+        if filterConfig.HealthScore.Max != nil {
+            params.Column14 = sql.NullFloat64{Float64: *filterConfig.HealthScore.Max, Valid: true}
+        }
+    }
+    var rows []database.AddTagAndStepToFilteredObjectsRow
+    err = applyFilterQuery(ctx, s.db, action.OrgID, filterConfig.Query, &params)
+    if err == nil {
+        rows, err = s.apply(ctx, action, params, tagId, funnelId)
+    }
     status := "completed"
     var noOfAffectedObjects int32 = 0
     var executionLog pqtype.NullRawMessage
@@ -168,6 +194,52 @@ func (s *AutomationService) ExecuteAction(
     return err
 }
 
+// apply tags the filtered objects and adds them to the funnel, with their
+// history, in one transaction
+func (s *AutomationService) apply(ctx context.Context, action database.AutomatedAction, params database.AddTagAndStepToFilteredObjectsParams, tagID, funnelID uuid.UUID) ([]database.AddTagAndStepToFilteredObjectsRow, error) {
+    tx, err := s.sqlDB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+    q := s.db.WithTx(tx)
+    rows, err := q.AddTagAndStepToFilteredObjects(ctx, params)
+    if err != nil {
+        return nil, err
+    }
+    if err := recordHistory(ctx, q, action, rows, tagID, funnelID); err != nil {
+        return nil, err
+    }
+    return rows, tx.Commit()
+}
+
+// recordHistory records the tags and funnel steps the action added to each
+// object, on behalf of the creator of the action
+func recordHistory(ctx context.Context, q *database.Queries, action database.AutomatedAction, rows []database.AddTagAndStepToFilteredObjectsRow, tagID, funnelID uuid.UUID) error {
+    // Objects are added to the first step of the funnel
+    var firstStepID uuid.UUID
+    steps, err := q.ListStepsByFunnel(ctx, funnelID)
+    if err != nil {
+        return err
+    }
+    if len(steps) > 0 {
+        firstStepID = steps[0].ID
+    }
+    for _, row := range rows {
+        if row.TagAdded {
+            if err := history.RecordTag(ctx, q, history.SourceAutomation, action.CreatedBy, row.ID, tagID, history.ActionTagAdded); err != nil {
+                return err
+            }
+        }
+        if row.StepAdded && firstStepID != uuid.Nil {
+            if err := history.RecordStep(ctx, q, history.SourceAutomation, action.CreatedBy, row.ID, firstStepID, history.ActionStepAdded); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
 type CriteriaInputFormat struct {
     Field string `json:"field"`
     Value string `json:"value"`
@@ -0,0 +1,90 @@
+// service/automation_query.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/query"
+	"github.com/google/uuid"
+)
+
+// CheckFilterQuery checks the text query of the filter config of an
+// automation. Errors in the query are *query.Error.
+func CheckFilterQuery(ctx context.Context, db *database.Queries, orgID uuid.UUID, raw json.RawMessage) error {
+	var filterConfig FilterConfig
+	if err := json.Unmarshal(raw, &filterConfig); err != nil || filterConfig.Query == "" {
+		return nil
+	}
+	params := database.AddTagAndStepToFilteredObjectsParams{
+		Column4: filterConfig.TagIDs,
+		Column5: filterConfig.TypeIDs,
+		Column6: json.RawMessage("null"),
+		Column7: json.RawMessage("null"),
+		Column8: json.RawMessage("null"),
+	}
+	if f := filterConfig.FunnelStepFilter; f != nil {
+		params.Column3 = f.StepIDs
+	}
+	// Only the slots in use matter, not their criteria
+	if c := filterConfig.TypeValueCriteria; c != nil {
+		for _, slot := range []struct {
+			criteria map[string]string
+			param    *json.RawMessage
+		}{
+			{c.Criteria1, &params.Column6},
+			{c.Criteria2, &params.Column7},
+			{c.Criteria3, &params.Column8},
+		} {
+			if len(slot.criteria) > 0 {
+				*slot.param = json.RawMessage("{}")
+			}
+		}
+	}
+	return applyFilterQuery(ctx, db, orgID, filterConfig.Query, &params)
+}
+
+// applyFilterQuery compiles the text query of an automation into the
+// parameters of its filter
+func applyFilterQuery(ctx context.Context, db *database.Queries, orgID uuid.UUID, text string, params *database.AddTagAndStepToFilteredObjectsParams) error {
+	if text == "" {
+		return nil
+	}
+	q, err := query.Compile(ctx, db, orgID, text)
+	if err != nil {
+		return err
+	}
+	if params.Column3, err = q.MergeIDs(query.FieldStep, params.Column3); err != nil {
+		return err
+	}
+	if params.Column4, err = q.MergeIDs(query.FieldTag, params.Column4); err != nil {
+		return err
+	}
+	if params.Column5, err = q.MergeIDs(query.FieldType, params.Column5); err != nil {
+		return err
+	}
+	params.Column2 = q.MergeSearch(params.Column2)
+	params.Column21 = q.ExcludeSearch
+	params.Column15 = q.ExcludeStepIDs
+	params.Column16 = q.ExcludeTagIDs
+	params.Column17 = q.ExcludeTypeIDs
+	params.Column18 = q.CreatedAfter
+	params.Column19 = q.CreatedBefore
+	params.Column20 = q.FactKinds
+
+	// The criteria of the query take the slots the filter leaves empty
+	var free []*json.RawMessage
+	for _, slot := range []*json.RawMessage{&params.Column6, &params.Column7, &params.Column8} {
+		if string(*slot) == "null" {
+			free = append(free, slot)
+		}
+	}
+	if err := q.CheckCriteria(len(free)); err != nil {
+		return err
+	}
+	for i, criterion := range q.Criteria {
+		*free[i], _ = json.Marshal(criterion)
+	}
+	return nil
+}
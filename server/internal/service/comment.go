@@ -0,0 +1,125 @@
+// service/comment.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// creatorMarker is the canonical @[username](creator:uuid) mention of a
+// member of the org in a comment
+var creatorMarker = regexp.MustCompile(`@\[(?:[^\]\\]|\\.)*\]\(creator:([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\)`)
+
+// maxCommentSummary bounds the comment text copied into a feed entry
+const maxCommentSummary = 200
+
+// CommentMention is the content of the feed entry of a creator mentioned in
+// a comment on a fact
+type CommentMention struct {
+	Type      string    `json:"type"`
+	FactID    uuid.UUID `json:"factId"`
+	CommentID uuid.UUID `json:"commentId"`
+	ActorID   uuid.UUID `json:"actorId"`
+	Summary   string    `json:"summary"`
+}
+
+// CreatorMarker is the canonical mention of a member of the org in a comment
+func CreatorMarker(username string, id uuid.UUID) string {
+	return fmt.Sprintf("@[%s](creator:%s)", username, id)
+}
+
+// ResolveCreatorMentions rewrites the plain @username mentions of a comment
+// that match an active member of the org, ignoring case, to canonical
+// markers. It returns the text and the members it mentions, by marker or
+// username. Mentions matching no member are left as written.
+func ResolveCreatorMentions(ctx context.Context, q *database.Queries, orgID uuid.UUID, text string) (string, []uuid.UUID, error) {
+	var usernames []string
+	for _, span := range findMentions(text) {
+		usernames = append(usernames, strings.ToLower(span.mention))
+	}
+	var ids []uuid.UUID
+	marked := map[uuid.UUID]bool{}
+	for _, match := range creatorMarker.FindAllStringSubmatch(text, -1) {
+		id := uuid.MustParse(match[1])
+		ids = append(ids, id)
+		marked[id] = true
+	}
+	if len(usernames) == 0 && len(ids) == 0 {
+		return text, nil, nil
+	}
+
+	creators, err := q.ListMentionedCreators(ctx, database.ListMentionedCreatorsParams{
+		OrgID:   orgID,
+		Column2: usernames,
+		Column3: ids,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error resolving creator mentions: %w", err)
+	}
+	byUsername := map[string]database.ListMentionedCreatorsRow{}
+	for _, c := range creators {
+		byUsername[strings.ToLower(c.Username)] = c
+	}
+
+	mentioned := map[uuid.UUID]bool{}
+	var out strings.Builder
+	last := 0
+	for _, span := range findMentions(text) {
+		c, ok := byUsername[strings.ToLower(span.mention)]
+		if !ok {
+			continue
+		}
+		mentioned[c.ID] = true
+		out.WriteString(text[last:span.start])
+		out.WriteString(CreatorMarker(c.Username, c.ID))
+		last = span.end
+	}
+	out.WriteString(text[last:])
+
+	// Markers of creators outside the org are not mentions, they are not
+	// among the creators found
+	result := make([]uuid.UUID, 0, len(creators))
+	for _, c := range creators {
+		if mentioned[c.ID] || marked[c.ID] {
+			result = append(result, c.ID)
+		}
+	}
+	return out.String(), result, nil
+}
+
+// NotifyCommentMentions writes a feed entry for each creator mentioned in a
+// comment, other than its author, so it counts in their unseen feed. Like
+// watch notifications it never fails the comment, errors are only logged.
+func NotifyCommentMentions(ctx context.Context, q *database.Queries, comment database.FactComment, creatorIDs []uuid.UUID) {
+	summary := comment.Text
+	if utf8.RuneCountInString(summary) > maxCommentSummary {
+		summary = string([]rune(summary)[:maxCommentSummary]) + "…"
+	}
+	data, _ := json.Marshal(CommentMention{
+		Type:      "mention",
+		FactID:    comment.FactID,
+		CommentID: comment.ID,
+		ActorID:   comment.CreatorID,
+		Summary:   summary,
+	})
+	for _, creatorID := range creatorIDs {
+		if creatorID == comment.CreatorID {
+			continue
+		}
+		if _, err := q.CreateFeed(ctx, database.CreateFeedParams{
+			CreatorID: creatorID,
+			Content:   data,
+			Seen:      false,
+		}); err != nil {
+			log.Printf("Error notifying %s of comment %s: %v", creatorID, comment.ID, err)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+// maxComputedScan bounds how many objects are evaluated in memory when a list
+// is sorted or filtered on a computed field
+const maxComputedScan = 5000
+
+// ErrComputedScanLimit is returned when more than maxComputedScan objects
+// would have to be evaluated in memory, the pages and the total would be
+// wrong
+var ErrComputedScanLimit = errors.New("too many objects to filter or sort on a computed field")
+
+// orgSchemas holds the schemas of an org's object types that declare
+// computed or restricted fields, the ones that need work after the SQL query
+type orgSchemas struct {
+	schemas map[uuid.UUID]schema.Schema
+}
+
+func (s *ObjectService) loadOrgSchemas(ctx context.Context, orgID uuid.UUID) (*orgSchemas, error) {
+	rows, err := s.db.ListObjectTypeFieldsByOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading object type fields: %w", err)
+	}
+	c := &orgSchemas{schemas: map[uuid.UUID]schema.Schema{}}
+	for _, row := range rows {
+		typeSchema, err := schema.Parse(row.Fields)
+		if err != nil || !typeSchema.HasComputed() && !typeSchema.HasRestricted() {
+			continue
+		}
+		c.schemas[row.ID] = typeSchema
+	}
+	return c, nil
+}
+
+func (c *orgSchemas) empty() bool {
+	return len(c.schemas) == 0
+}
+
+func (c *orgSchemas) hasComputed() bool {
+	for _, typeSchema := range c.schemas {
+		if typeSchema.HasComputed() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *orgSchemas) isComputed(name string) bool {
+	for _, typeSchema := range c.schemas {
+		if typeSchema.IsComputed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlCriteriaSlots is how many type value criteria the SQL query takes
+const sqlCriteriaSlots = 3
+
+// splitCriteria separates the type value criteria the SQL query can handle
+// from the ones that must be matched in memory: the ones that refer to
+// computed fields, and the ones beyond the slots of the query
+func (c *orgSchemas) splitCriteria(criteria []json.RawMessage) ([]json.RawMessage, []map[string]string) {
+	if c.empty() && len(criteria) <= sqlCriteriaSlots {
+		return criteria, nil
+	}
+	var sqlCriteria []json.RawMessage
+	var memCriteria []map[string]string
+	for _, raw := range criteria {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			sqlCriteria = append(sqlCriteria, raw)
+			continue
+		}
+		usesComputed := false
+		for key := range decoded {
+			if c.isComputed(key) {
+				usesComputed = true
+				break
+			}
+		}
+		if !usesComputed && len(sqlCriteria) < sqlCriteriaSlots {
+			sqlCriteria = append(sqlCriteria, raw)
+			continue
+		}
+		criterion := make(map[string]string, len(decoded))
+		for key, value := range decoded {
+			// Comparisons of text queries are {"op", "value"} objects,
+			// which MatchCriterion reads as the operator and its operand
+			if c, ok := value.(map[string]interface{}); ok {
+				criterion[key] = fmt.Sprint(c["op"]) + fmt.Sprint(c["value"])
+				continue
+			}
+			criterion[key] = fmt.Sprint(value)
+		}
+		memCriteria = append(memCriteria, criterion)
+	}
+	return sqlCriteria, memCriteria
+}
+
+// attach evaluates the computed fields of every item and stores them under
+// "computed" next to the stored type_values
+func (c *orgSchemas) attach(ctx context.Context, db *database.Queries, items []database.ListObjectsAdvancedRow) error {
+	if !c.hasComputed() || len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	taskRows, err := db.CountTasksByObjectIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error counting tasks: %w", err)
+	}
+	taskCounts := make(map[uuid.UUID]database.CountTasksByObjectIDsRow, len(taskRows))
+	for _, row := range taskRows {
+		taskCounts[row.ObjID] = row
+	}
+	// The list dates facts by when they were logged, computed fields by
+	// when they happened
+	dateRows, err := db.GetFactDatesByObjectIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error loading fact dates: %w", err)
+	}
+	factDates := make(map[uuid.UUID]database.GetFactDatesByObjectIDsRow, len(dateRows))
+	for _, row := range dateRows {
+		factDates[row.ObjID] = row
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		typeValues, ok := item.TypeValues.([]interface{})
+		if !ok {
+			continue
+		}
+		tasks := taskCounts[item.ID]
+		aggs := schema.Aggregates{
+			FactCount:    item.FactCount,
+			CreatedAt:    item.CreatedAt,
+			TaskCount:    tasks.TaskCount,
+			OpenTasks:    tasks.OpenTasks,
+			OverdueTasks: tasks.OverdueTasks,
+		}
+		if dates, ok := factDates[item.ID]; ok {
+			aggs.FirstFactAt = &dates.FirstFactAt
+			aggs.LastFactAt = &dates.LastFactAt
+		}
+		for _, tv := range typeValues {
+			entry, ok := tv.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			typeID, err := uuid.Parse(fmt.Sprint(entry["objectTypeId"]))
+			if err != nil {
+				continue
+			}
+			typeSchema, ok := c.schemas[typeID]
+			if !ok || !typeSchema.HasComputed() {
+				continue
+			}
+			values, _ := entry["type_values"].(map[string]interface{})
+			entry["computed"] = typeSchema.Compute(values, aggs, now)
+		}
+	}
+	return nil
+}
+
+// itemValue returns the stored or computed value of a field for an item
+func itemValue(item database.ListObjectsAdvancedRow, field string) interface{} {
+	typeValues, _ := item.TypeValues.([]interface{})
+	for _, tv := range typeValues {
+		entry, ok := tv.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if computed, ok := entry["computed"].(map[string]interface{}); ok {
+			if v, ok := computed[field]; ok && v != nil {
+				return v
+			}
+		}
+		if values, ok := entry["type_values"].(map[string]interface{}); ok {
+			if v, ok := values[field]; ok && v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// filterComputed keeps the items matching every criterion. Like the SQL
+// filter, a criterion matches when any of its keys matches.
+func filterComputed(items []database.ListObjectsAdvancedRow, criteria []map[string]string) []database.ListObjectsAdvancedRow {
+	if len(criteria) == 0 {
+		return items
+	}
+	filtered := items[:0]
+	for _, item := range items {
+		matches := true
+		for _, criterion := range criteria {
+			any := false
+			for field, value := range criterion {
+				if schema.MatchCriterion(itemValue(item, field), value) {
+					any = true
+					break
+				}
+			}
+			if !any {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// sortComputed orders items by a computed field, nulls last
+func sortComputed(items []database.ListObjectsAdvancedRow, field string, ascending bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := itemValue(items[i], field), itemValue(items[j], field)
+		if a == nil || b == nil {
+			return a != nil
+		}
+		if ascending {
+			return schema.Less(a, b)
+		}
+		return schema.Less(b, a)
+	})
+}
+
+// countComputed is count, the result of CountObjectsAdvanced, recounted over
+// items, the objects left once computed fields are filtered. Steps keep the
+// keys of count, an item counts in a step it is in with one of subStatuses
+// when they are given.
+func countComputed(count json.RawMessage, items []database.ListObjectsAdvancedRow, subStatuses []int32) (json.RawMessage, error) {
+	var counts struct {
+		TotalCount int64            `json:"total_count"`
+		StepCounts map[string]int64 `json:"step_counts"`
+	}
+	if err := json.Unmarshal(count, &counts); err != nil {
+		return nil, fmt.Errorf("error reading object count: %w", err)
+	}
+	if counts.StepCounts == nil {
+		counts.StepCounts = map[string]int64{}
+	}
+	for stepID := range counts.StepCounts {
+		counts.StepCounts[stepID] = 0
+	}
+	counts.TotalCount = int64(len(items))
+	for _, item := range items {
+		steps, _ := item.Steps.([]interface{})
+		seen := map[string]bool{}
+		for _, s := range steps {
+			step, _ := s.(map[string]interface{})
+			stepID, _ := step["stepId"].(string)
+			if _, ok := counts.StepCounts[stepID]; !ok || seen[stepID] {
+				continue
+			}
+			if len(subStatuses) > 0 && !hasSubStatus(subStatuses, step["subStatus"]) {
+				continue
+			}
+			seen[stepID] = true
+			counts.StepCounts[stepID]++
+		}
+	}
+	return json.Marshal(counts)
+}
+
+func hasSubStatus(subStatuses []int32, value interface{}) bool {
+	n, ok := value.(float64)
+	if !ok {
+		return false
+	}
+	for _, s := range subStatuses {
+		if float64(s) == n {
+			return true
+		}
+	}
+	return false
+}
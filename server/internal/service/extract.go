@@ -0,0 +1,166 @@
+// service/extract.go
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxExtractedText bounds the text kept from a file for search
+const maxExtractedText = 1 << 20
+
+// extractText returns the searchable text of a file: plain text files as
+// they are, PDFs through their text drawing operators. Other files, and PDFs
+// whose text cannot be read, have none.
+func extractText(contentType string, data []byte) string {
+	var text string
+	switch {
+	case strings.HasPrefix(contentType, "text/"), contentType == "application/json":
+		if !utf8.Valid(data) {
+			return ""
+		}
+		text = string(data)
+	case contentType == "application/pdf":
+		text = extractPDFText(data)
+	default:
+		return ""
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\x00", ""))
+	if len(text) > maxExtractedText {
+		text = strings.ToValidUTF8(text[:maxExtractedText], "")
+	}
+	return text
+}
+
+var (
+	pdfStream     = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfTextObject = regexp.MustCompile(`(?s)BT(.*?)ET`)
+)
+
+// extractPDFText reads the strings shown by Tj, TJ, ' and " in the content
+// streams of a PDF. It handles uncompressed and FlateDecode streams with
+// simple font encodings, which covers most generated documents; scanned
+// pages and CID fonts yield nothing useful.
+func extractPDFText(data []byte) string {
+	var out strings.Builder
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[start : start+end]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			r, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			stream, err = io.ReadAll(io.LimitReader(r, 16<<20))
+			r.Close()
+			if err != nil && len(stream) == 0 {
+				continue
+			}
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// Images and other encodings hold no text
+			continue
+		}
+		for _, block := range pdfTextObject.FindAllSubmatch(stream, -1) {
+			writePDFStrings(&out, block[1])
+			out.WriteByte('\n')
+		}
+		if out.Len() > maxExtractedText {
+			break
+		}
+	}
+	return out.String()
+}
+
+// writePDFStrings writes the literal strings of a text object, a space
+// between the strings of a TJ array is only added for wide kerning gaps
+func writePDFStrings(out *strings.Builder, block []byte) {
+	for i := 0; i < len(block); i++ {
+		switch block[i] {
+		case '(':
+			s, next := readPDFString(block, i+1)
+			for _, r := range s {
+				if unicode.IsPrint(r) || r == '\n' {
+					out.WriteRune(r)
+				}
+			}
+			i = next
+		case 'T':
+			if i+1 < len(block) && (block[i+1] == 'd' || block[i+1] == 'D' || block[i+1] == '*') {
+				out.WriteByte(' ')
+			}
+		case ']':
+			out.WriteByte(' ')
+		case '-':
+			// Kerning of more than a space width in a TJ array
+			j := i + 1
+			for j < len(block) && block[j] >= '0' && block[j] <= '9' {
+				j++
+			}
+			if j-i > 3 {
+				out.WriteByte(' ')
+			}
+			i = j - 1
+		}
+	}
+}
+
+// readPDFString reads a literal string whose opening parenthesis is before
+// i, returning it and the index of its closing parenthesis
+func readPDFString(b []byte, i int) (string, int) {
+	var s []rune
+	depth := 1
+	for ; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(b) {
+				return string(s), i
+			}
+			switch e := b[i]; e {
+			case 'n':
+				s = append(s, '\n')
+			case 'r', 't', 'b', 'f':
+				s = append(s, ' ')
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					j := i
+					for ; j < len(b) && j < i+3 && b[j] >= '0' && b[j] <= '7'; j++ {
+						v = v*8 + int(b[j]-'0')
+					}
+					s = append(s, rune(v))
+					i = j - 1
+				} else {
+					s = append(s, rune(e))
+				}
+			}
+		case '(':
+			depth++
+			s = append(s, '(')
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(s), i
+			}
+			s = append(s, ')')
+		default:
+			// Simple fonts use a single byte per character, close enough to
+			// Latin-1 for search
+			s = append(s, rune(c))
+		}
+	}
+	return string(s), i
+}
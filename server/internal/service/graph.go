@@ -0,0 +1,531 @@
+// service/graph.go
+package service
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// Objects are connected when facts mention them together. Each shared fact
+// adds to the weight of the edge, halved every graphHalfLife since it
+// happened, so old acquaintances fade behind recent ones.
+const (
+	graphHalfLife = 180 * 24 * time.Hour
+	// Facts mentioning more objects than this (meeting notes with the whole
+	// address book, imports) are left out of the graph
+	graphMaxMentions = 25
+	// graphMinWeight drops the edges of the exported graph worth less than a
+	// single fact two years old
+	graphMinWeight = 0.06
+	maxGraphEdges  = 20000
+	// maxEvidence bounds the shared facts returned per connection
+	maxEvidence = 5
+	// maxPathLinks bounds the introductions of a path, path finding expands
+	// this many levels at most from both ends together
+	maxPathLinks = 6
+	// maxPathNeighbors bounds the links loaded per level
+	maxPathNeighbors = 20000
+)
+
+// Kinds of graph nodes, team members connect to the objects they wrote
+// facts about
+const (
+	NodeObject  = "object"
+	NodeCreator = "creator"
+)
+
+// Export formats of the graph
+const (
+	FormatGraphML = "graphml"
+	FormatDOT     = "dot"
+)
+
+var (
+	ErrGraphNode   = errors.New("node not found in the graph")
+	ErrNoPath      = errors.New("no path between the nodes")
+	ErrGraphFormat = errors.New("unknown graph format, expected graphml or dot")
+)
+
+// Evidence is a fact mentioning both ends of a connection
+type Evidence struct {
+	FactID     uuid.UUID  `json:"factId"`
+	Text       string     `json:"text"`
+	HappenedAt *time.Time `json:"happenedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	CreatorID  uuid.UUID  `json:"creatorId"`
+}
+
+// Connection is an object mentioned together with another one
+type Connection struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	Photo           string     `json:"photo"`
+	IDString        string     `json:"idString"`
+	Weight          float64    `json:"weight"`
+	FactCount       int64      `json:"factCount"`
+	LastMentionedAt time.Time  `json:"lastMentionedAt"`
+	Evidence        []Evidence `json:"evidence"`
+}
+
+type NetworkNode struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+	Name string    `json:"name"`
+}
+
+type NetworkEdge struct {
+	From            uuid.UUID `json:"from"`
+	To              uuid.UUID `json:"to"`
+	Weight          float64   `json:"weight"`
+	FactCount       int64     `json:"factCount"`
+	LastMentionedAt time.Time `json:"lastMentionedAt"`
+}
+
+// Network is a weighted graph of objects, and of team members when built
+// for path finding
+type Network struct {
+	Nodes []NetworkNode `json:"nodes"`
+	Edges []NetworkEdge `json:"edges"`
+}
+
+// Path is a chain of introductions, Edges[i] links Nodes[i] and Nodes[i+1]
+type Path struct {
+	Nodes []NetworkNode `json:"nodes"`
+	Edges []NetworkEdge `json:"edges"`
+	// Strength is the weight of the weakest link
+	Strength float64 `json:"strength"`
+}
+
+type GraphService struct {
+	queries *database.Queries
+}
+
+func NewGraphService(queries *database.Queries) *GraphService {
+	return &GraphService{queries: queries}
+}
+
+func halfLifeSeconds() float64 {
+	return graphHalfLife.Seconds()
+}
+
+// Connections returns the objects most strongly connected to objID, each
+// with the latest facts mentioning both
+func (s *GraphService) Connections(ctx context.Context, orgID, objID uuid.UUID, limit int32) ([]Connection, error) {
+	ids, err := s.queries.FilterOrgObjectIDs(ctx, database.FilterOrgObjectIDsParams{
+		Column1: []uuid.UUID{objID},
+		OrgID:   orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrGraphNode
+	}
+
+	rows, err := s.queries.ListObjectConnections(ctx, database.ListObjectConnectionsParams{
+		OrgID:   orgID,
+		Column2: halfLifeSeconds(),
+		Column3: graphMaxMentions,
+		ObjID:   objID,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	connections := make([]Connection, 0, len(rows))
+	if len(rows) == 0 {
+		return connections, nil
+	}
+	index := map[uuid.UUID]int{}
+	otherIDs := make([]uuid.UUID, 0, len(rows))
+	for i, row := range rows {
+		index[row.ID] = i
+		otherIDs = append(otherIDs, row.ID)
+		connections = append(connections, Connection{
+			ID:              row.ID,
+			Name:            row.Name,
+			Photo:           row.Photo,
+			IDString:        row.IDString,
+			Weight:          row.Weight,
+			FactCount:       row.FactCount,
+			LastMentionedAt: row.LastMentionedAt,
+			Evidence:        []Evidence{},
+		})
+	}
+
+	facts, err := s.queries.ListSharedFacts(ctx, database.ListSharedFactsParams{
+		ObjID:   objID,
+		Column2: otherIDs,
+		OrgID:   orgID,
+		Column4: maxEvidence,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range facts {
+		c := &connections[index[f.OtherID]]
+		evidence := Evidence{
+			FactID:    f.FactID,
+			Text:      f.Text,
+			CreatedAt: f.CreatedAt,
+			CreatorID: f.CreatorID,
+		}
+		if f.HappenedAt.Valid {
+			happenedAt := f.HappenedAt.Time
+			evidence.HappenedAt = &happenedAt
+		}
+		c.Evidence = append(c.Evidence, evidence)
+	}
+	return connections, nil
+}
+
+// ConnectionNetwork turns the connections of an object into a network
+// centered on it, for export
+func ConnectionNetwork(center NetworkNode, connections []Connection) *Network {
+	g := newGraphBuilder()
+	g.node(center.ID, center.Kind, center.Name)
+	for _, c := range connections {
+		g.node(c.ID, NodeObject, c.Name)
+		g.edge(center.ID, c.ID, c.Weight, c.FactCount, c.LastMentionedAt)
+	}
+	return g.network()
+}
+
+// Graph returns the co-mention graph of the org, without the weakest edges
+func (s *GraphService) Graph(ctx context.Context, orgID uuid.UUID) (*Network, error) {
+	return s.objectGraph(ctx, orgID, graphMinWeight)
+}
+
+func (s *GraphService) objectGraph(ctx context.Context, orgID uuid.UUID, minWeight float64) (*Network, error) {
+	rows, err := s.queries.ListCoMentionEdges(ctx, database.ListCoMentionEdgesParams{
+		OrgID:   orgID,
+		Column2: halfLifeSeconds(),
+		Column3: graphMaxMentions,
+		Column4: minWeight,
+		Limit:   maxGraphEdges,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g := newGraphBuilder()
+	for _, row := range rows {
+		g.node(row.FromID, NodeObject, row.FromName)
+		g.node(row.ToID, NodeObject, row.ToName)
+		g.edge(row.FromID, row.ToID, row.Weight, row.FactCount, row.LastMentionedAt)
+	}
+	return g.network(), nil
+}
+
+// Path finds the strongest chain of introductions from a team member or an
+// object to an object. Team members are part of the graph, linked to the
+// objects they wrote facts about, so a colleague knowing the target can
+// appear along the way. Links cost the inverse of their weight, the path
+// with the lowest total cost wins: a few strong links beat many weak ones.
+//
+// The graph is explored one level at a time from both ends, the smaller
+// side first, until the sides meet. The path is the strongest one among the
+// links explored, so it has at most maxPathLinks introductions.
+func (s *GraphService) Path(ctx context.Context, orgID, fromID, toID uuid.UUID) (*Path, error) {
+	g := newGraphBuilder()
+	for _, id := range []uuid.UUID{fromID, toID} {
+		node, err := s.queries.GetGraphNode(ctx, database.GetGraphNodeParams{ID: id, OrgID: orgID})
+		if err == sql.ErrNoRows {
+			return nil, ErrGraphNode
+		}
+		if err != nil {
+			return nil, err
+		}
+		if id == toID && node.Kind != NodeObject {
+			return nil, ErrGraphNode
+		}
+		g.node(node.ID, node.Kind, node.Name)
+	}
+	if fromID == toID {
+		return g.shortestPath(g.index[fromID], g.index[toID])
+	}
+
+	sides := [2]*pathSide{newPathSide(fromID), newPathSide(toID)}
+	linked := map[[2]uuid.UUID]bool{}
+	for links := 0; links < maxPathLinks; links++ {
+		side, other := sides[0], sides[1]
+		if len(other.frontier) < len(side.frontier) {
+			side, other = other, side
+		}
+		if len(side.frontier) == 0 {
+			break
+		}
+		rows, err := s.queries.ListGraphNeighbors(ctx, database.ListGraphNeighborsParams{
+			OrgID:   orgID,
+			Column2: halfLifeSeconds(),
+			Column3: graphMaxMentions,
+			Column4: side.frontier,
+			Limit:   maxPathNeighbors,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var next []uuid.UUID
+		met := false
+		for _, row := range rows {
+			g.node(row.ToID, row.ToKind, row.ToName)
+			pair := [2]uuid.UUID{row.FromID, row.ToID}
+			if row.ToID.String() < row.FromID.String() {
+				pair = [2]uuid.UUID{row.ToID, row.FromID}
+			}
+			if !linked[pair] {
+				linked[pair] = true
+				g.edge(row.FromID, row.ToID, row.Weight, row.FactCount, row.LastMentionedAt)
+			}
+			if !side.seen[row.ToID] {
+				side.seen[row.ToID] = true
+				next = append(next, row.ToID)
+			}
+			met = met || other.seen[row.ToID]
+		}
+		side.frontier = next
+		if met {
+			break
+		}
+	}
+	return g.shortestPath(g.index[fromID], g.index[toID])
+}
+
+// pathSide is the part of the graph explored from one end of a path
+type pathSide struct {
+	seen     map[uuid.UUID]bool
+	frontier []uuid.UUID
+}
+
+func newPathSide(id uuid.UUID) *pathSide {
+	return &pathSide{seen: map[uuid.UUID]bool{id: true}, frontier: []uuid.UUID{id}}
+}
+
+// Write writes the network as GraphML or DOT
+func (n *Network) Write(format string, w io.Writer) error {
+	switch format {
+	case FormatGraphML:
+		return n.writeGraphML(w)
+	case FormatDOT:
+		return n.writeDOT(w)
+	}
+	return ErrGraphFormat
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func (n *Network) writeGraphML(w io.Writer) error {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "weight", For: "edge", Name: "weight", Type: "double"},
+			{ID: "facts", For: "edge", Name: "factCount", Type: "long"},
+			{ID: "last", For: "edge", Name: "lastMentionedAt", Type: "string"},
+		},
+	}
+	doc.Graph.ID = "muninn"
+	doc.Graph.EdgeDefault = "undirected"
+	for _, node := range n.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.ID.String(),
+			Data: []graphMLData{
+				{Key: "name", Value: node.Name},
+				{Key: "kind", Value: node.Kind},
+			},
+		})
+	}
+	for _, edge := range n.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.From.String(),
+			Target: edge.To.String(),
+			Data: []graphMLData{
+				{Key: "weight", Value: fmt.Sprintf("%g", edge.Weight)},
+				{Key: "facts", Value: fmt.Sprint(edge.FactCount)},
+				{Key: "last", Value: edge.LastMentionedAt.Format(time.RFC3339)},
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (n *Network) writeDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("graph muninn {\n")
+	for _, node := range n.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, kind=%s];\n", dotQuote(node.ID.String()), dotQuote(node.Name), dotQuote(node.Kind))
+	}
+	for _, edge := range n.Edges {
+		fmt.Fprintf(&b, "  %s -- %s [weight=%g, facts=%d];\n", dotQuote(edge.From.String()), dotQuote(edge.To.String()), edge.Weight, edge.FactCount)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT string
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
+
+// graphBuilder collects the nodes and undirected edges of a network
+type graphBuilder struct {
+	nodes []NetworkNode
+	index map[uuid.UUID]int
+	edges []NetworkEdge
+	adj   map[int][]int // node -> edges
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{index: map[uuid.UUID]int{}, adj: map[int][]int{}}
+}
+
+func (g *graphBuilder) node(id uuid.UUID, kind, name string) {
+	if _, ok := g.index[id]; ok {
+		return
+	}
+	g.index[id] = len(g.nodes)
+	g.nodes = append(g.nodes, NetworkNode{ID: id, Kind: kind, Name: name})
+}
+
+func (g *graphBuilder) edge(from, to uuid.UUID, weight float64, factCount int64, last time.Time) {
+	if weight <= 0 {
+		return
+	}
+	i := len(g.edges)
+	g.edges = append(g.edges, NetworkEdge{From: from, To: to, Weight: weight, FactCount: factCount, LastMentionedAt: last})
+	g.adj[g.index[from]] = append(g.adj[g.index[from]], i)
+	g.adj[g.index[to]] = append(g.adj[g.index[to]], i)
+}
+
+func (g *graphBuilder) network() *Network {
+	network := &Network{Nodes: g.nodes, Edges: g.edges}
+	if network.Nodes == nil {
+		network.Nodes = []NetworkNode{}
+	}
+	if network.Edges == nil {
+		network.Edges = []NetworkEdge{}
+	}
+	return network
+}
+
+// shortestPath runs Dijkstra from node from to node to
+func (g *graphBuilder) shortestPath(from, to int) (*Path, error) {
+	dist := map[int]float64{from: 0}
+	via := map[int]int{} // node -> edge reaching it
+	done := map[int]bool{}
+	queue := &pathQueue{{node: from}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		if done[item.node] {
+			continue
+		}
+		done[item.node] = true
+		if item.node == to {
+			break
+		}
+		for _, e := range g.adj[item.node] {
+			edge := g.edges[e]
+			next := g.index[edge.To]
+			if next == item.node {
+				next = g.index[edge.From]
+			}
+			cost := item.cost + 1/edge.Weight
+			if d, ok := dist[next]; !ok || cost < d {
+				dist[next] = cost
+				via[next] = e
+				heap.Push(queue, pathItem{node: next, cost: cost})
+			}
+		}
+	}
+	if !done[to] {
+		return nil, ErrNoPath
+	}
+
+	path := &Path{Nodes: []NetworkNode{g.nodes[to]}, Edges: []NetworkEdge{}}
+	for node := to; node != from; {
+		edge := g.edges[via[node]]
+		if path.Strength == 0 || edge.Weight < path.Strength {
+			path.Strength = edge.Weight
+		}
+		prev := g.index[edge.From]
+		if prev == node {
+			prev = g.index[edge.To]
+		}
+		path.Nodes = append([]NetworkNode{g.nodes[prev]}, path.Nodes...)
+		path.Edges = append([]NetworkEdge{edge}, path.Edges...)
+		node = prev
+	}
+	return path, nil
+}
+
+type pathItem struct {
+	node int
+	cost float64
+}
+
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)        { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
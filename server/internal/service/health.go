@@ -0,0 +1,299 @@
+// service/health.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/google/uuid"
+)
+
+// The health score of an object goes from 0 (slipping away) to 100 (well
+// looked after). It is the weighted average of components between 0 and 1,
+// components that do not apply to an object (tasks of an object without
+// any, funnel components of an object in no funnel) are left out of the
+// average rather than counted as 0.
+const (
+	// healthRecencyHalfLife halves the recency component every 30 days
+	// without a fact
+	healthRecencyHalfLife = 30
+	// healthRecentDays is the window of the frequency component, which is
+	// full at healthTargetFacts facts in the window
+	healthRecentDays  = 90
+	healthTargetFacts = 6
+	// healthTargetTasks open tasks make the open task component full, as
+	// many overdue ones empty the overdue component
+	healthTargetTasks = 3
+	// healthHistoryDays is the default span of the score history
+	healthHistoryDays = 90
+	// healthBatchSize is the number of scores stored per query
+	healthBatchSize = 500
+)
+
+var ErrHealthWeights = errors.New("weights must be between 0 and 100 and at least one above 0")
+
+// HealthWeights are the weights of the components of the health score,
+// configurable per org
+type HealthWeights struct {
+	FactRecency       float64 `json:"factRecency"`
+	FactFrequency     float64 `json:"factFrequency"`
+	OpenTasks         float64 `json:"openTasks"`
+	OverdueTasks      float64 `json:"overdueTasks"`
+	FunnelProgression float64 `json:"funnelProgression"`
+	SubStatus         float64 `json:"subStatus"`
+}
+
+// DefaultHealthWeights is used by orgs that have not set their own
+var DefaultHealthWeights = HealthWeights{
+	FactRecency:       30,
+	FactFrequency:     20,
+	OpenTasks:         10,
+	OverdueTasks:      15,
+	FunnelProgression: 15,
+	SubStatus:         10,
+}
+
+func (w HealthWeights) Validate() error {
+	total := 0.0
+	for _, v := range []float64{w.FactRecency, w.FactFrequency, w.OpenTasks, w.OverdueTasks, w.FunnelProgression, w.SubStatus} {
+		if v < 0 || v > 100 || math.IsNaN(v) {
+			return ErrHealthWeights
+		}
+		total += v
+	}
+	if total == 0 {
+		return ErrHealthWeights
+	}
+	return nil
+}
+
+// HealthComponents are the values between 0 and 1 a score is made of, nil
+// when the component does not apply to the object
+type HealthComponents struct {
+	FactRecency       *float64 `json:"factRecency"`
+	FactFrequency     *float64 `json:"factFrequency"`
+	OpenTasks         *float64 `json:"openTasks"`
+	OverdueTasks      *float64 `json:"overdueTasks"`
+	FunnelProgression *float64 `json:"funnelProgression"`
+	SubStatus         *float64 `json:"subStatus"`
+}
+
+type HealthPoint struct {
+	Date  string  `json:"date"`
+	Score float32 `json:"score"`
+}
+
+// ObjectHealth is the current score of an object and its trend
+type ObjectHealth struct {
+	ObjID      uuid.UUID        `json:"objId"`
+	Score      float32          `json:"score"`
+	Components HealthComponents `json:"components"`
+	ComputedAt time.Time        `json:"computedAt"`
+	History    []HealthPoint    `json:"history"`
+}
+
+type HealthService struct {
+	queries *database.Queries
+}
+
+func NewHealthService(queries *database.Queries) *HealthService {
+	return &HealthService{queries: queries}
+}
+
+// Weights returns the weights of the org, the defaults until it sets its own
+func (s *HealthService) Weights(ctx context.Context, orgID uuid.UUID) (HealthWeights, error) {
+	setting, err := s.queries.GetHealthScoreSetting(ctx, orgID)
+	if err == sql.ErrNoRows {
+		return DefaultHealthWeights, nil
+	}
+	if err != nil {
+		return HealthWeights{}, err
+	}
+	var weights HealthWeights
+	if err := json.Unmarshal(setting.Weights, &weights); err != nil {
+		return HealthWeights{}, fmt.Errorf("error decoding health weights: %w", err)
+	}
+	return weights, nil
+}
+
+// SetWeights changes the weights of the org. Scores change on the next
+// Recompute of the org.
+func (s *HealthService) SetWeights(ctx context.Context, orgID, actorID uuid.UUID, weights HealthWeights) error {
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+	data, _ := json.Marshal(weights)
+	_, err := s.queries.UpsertHealthScoreSetting(ctx, database.UpsertHealthScoreSettingParams{
+		OrgID:     orgID,
+		Weights:   data,
+		UpdatedBy: uuid.NullUUID{UUID: actorID, Valid: true},
+	})
+	return err
+}
+
+// RecomputeAll rescores the objects of every org, facts getting older
+// lower the scores of objects nothing happens to
+func (s *HealthService) RecomputeAll(ctx context.Context) (int, error) {
+	orgs, err := s.queries.ListOrganizations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// An org failing to score does not hold back the others
+	total, failed := 0, 0
+	for _, org := range orgs {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		n, err := s.Recompute(ctx, org.ID, nil)
+		if err != nil {
+			log.Printf("Error scoring org %s: %v", org.ID, err)
+			failed++
+		}
+		total += n
+	}
+	if failed > 0 {
+		return total, fmt.Errorf("%d of %d orgs could not be scored", failed, len(orgs))
+	}
+	return total, nil
+}
+
+// Recompute scores the objects of objIDs, all objects of the org when nil,
+// and returns how many were scored
+func (s *HealthService) Recompute(ctx context.Context, orgID uuid.UUID, objIDs []uuid.UUID) (int, error) {
+	weights, err := s.Weights(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+	inputs, err := s.queries.ListHealthInputs(ctx, database.ListHealthInputsParams{
+		OrgID:   orgID,
+		Column2: objIDs,
+		Column3: healthRecentDays,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error loading health inputs: %w", err)
+	}
+	type objectScore struct {
+		ObjID      uuid.UUID        `json:"obj_id"`
+		Score      float64          `json:"score"`
+		Components HealthComponents `json:"components"`
+	}
+	now := time.Now()
+	scored := 0
+	for start := 0; start < len(inputs); start += healthBatchSize {
+		end := min(start+healthBatchSize, len(inputs))
+		batch := make([]objectScore, 0, end-start)
+		for _, input := range inputs[start:end] {
+			components := healthComponents(input, now)
+			batch = append(batch, objectScore{
+				ObjID:      input.ObjID,
+				Score:      healthScore(components, weights),
+				Components: components,
+			})
+		}
+		data, _ := json.Marshal(batch)
+		if err := s.queries.UpsertObjectHealthBatch(ctx, data); err != nil {
+			return scored, fmt.Errorf("error storing health scores: %w", err)
+		}
+		scored += len(batch)
+	}
+	return scored, nil
+}
+
+// Object returns the health of an object with its daily scores over the
+// last days, scoring it first if it never was
+func (s *HealthService) Object(ctx context.Context, orgID, objID uuid.UUID, days int) (*ObjectHealth, error) {
+	health, err := s.queries.GetObjectHealth(ctx, database.GetObjectHealthParams{ObjID: objID, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		n, err := s.Recompute(ctx, orgID, []uuid.UUID{objID})
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, sql.ErrNoRows
+		}
+		health, err = s.queries.GetObjectHealth(ctx, database.GetObjectHealthParams{ObjID: objID, OrgID: orgID})
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if days < 1 {
+		days = healthHistoryDays
+	}
+	points, err := s.queries.ListObjectHealthHistory(ctx, database.ListObjectHealthHistoryParams{
+		ObjID:   objID,
+		Column2: time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &ObjectHealth{
+		ObjID:      health.ObjID,
+		Score:      health.Score,
+		ComputedAt: health.ComputedAt,
+		History:    make([]HealthPoint, 0, len(points)),
+	}
+	json.Unmarshal(health.Components, &result.Components)
+	for _, p := range points {
+		result.History = append(result.History, HealthPoint{Date: p.ScoredOn.Format("2006-01-02"), Score: p.Score})
+	}
+	return result, nil
+}
+
+func healthComponents(input database.ListHealthInputsRow, now time.Time) HealthComponents {
+	var c HealthComponents
+	recency := 0.0
+	if input.LastFactAt.Valid {
+		days := math.Max(now.Sub(input.LastFactAt.Time).Hours()/24, 0)
+		recency = math.Pow(0.5, days/healthRecencyHalfLife)
+	}
+	c.FactRecency = &recency
+	frequency := math.Min(float64(input.RecentFacts)/healthTargetFacts, 1)
+	c.FactFrequency = &frequency
+
+	if input.TotalTasks > 0 {
+		open := math.Min(float64(input.OpenTasks)/healthTargetTasks, 1)
+		overdue := 1 - math.Min(float64(input.OverdueTasks)/healthTargetTasks, 1)
+		c.OpenTasks = &open
+		c.OverdueTasks = &overdue
+	}
+	if input.StepCount > 0 {
+		progression := input.Progression
+		subStatus := input.SubStatus
+		c.FunnelProgression = &progression
+		c.SubStatus = &subStatus
+	}
+	return c
+}
+
+// healthScore is the weighted average of the components that apply, on a
+// scale of 100
+func healthScore(c HealthComponents, w HealthWeights) float64 {
+	var sum, total float64
+	add := func(value *float64, weight float64) {
+		if value == nil || weight == 0 {
+			return
+		}
+		sum += *value * weight
+		total += weight
+	}
+	add(c.FactRecency, w.FactRecency)
+	add(c.FactFrequency, w.FactFrequency)
+	add(c.OpenTasks, w.OpenTasks)
+	add(c.OverdueTasks, w.OverdueTasks)
+	add(c.FunnelProgression, w.FunnelProgression)
+	add(c.SubStatus, w.SubStatus)
+	if total == 0 {
+		return 0
+	}
+	return math.Round(sum/total*1000) / 10
+}
@@ -0,0 +1,293 @@
+// service/mention.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/google/uuid"
+)
+
+// Status of a mention once resolved
+const (
+	MentionResolved  = "resolved"
+	MentionCreated   = "created"
+	MentionAmbiguous = "ambiguous"
+	MentionUnknown   = "unknown"
+	MentionCreate    = "create"
+	MentionSkipped   = "skipped"
+)
+
+// What the caller chose to do with a mention
+const (
+	MentionChoicePick   = "pick"
+	MentionChoiceCreate = "create"
+	MentionChoiceSkip   = "skip"
+)
+
+// maxMentionCandidates bounds the objects reported for an ambiguous mention
+const maxMentionCandidates = 5
+
+var ErrMentionChoice = errors.New("mention choices must be pick with an object of the org, create or skip")
+
+var (
+	// plainMention is an @alias or @id_string not preceded by a word
+	// character, so emails are left alone. Trailing dots and dashes end
+	// the sentence rather than the mention.
+	plainMention = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@/\]])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]*)`)
+	// mentionMarker is the canonical @[name](object:uuid) form, ] and \ are
+	// escaped in the name
+	mentionMarker = regexp.MustCompile(`@\[(?:[^\]\\]|\\.)*\]\((?:object:)?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\)`)
+)
+
+// MentionChoice settles a mention the resolver could not, or overrides it
+type MentionChoice struct {
+	Action   string    `json:"action"`
+	ObjectID uuid.UUID `json:"objectId,omitempty"`
+}
+
+type MentionObject struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	IDString string    `json:"idString,omitempty"`
+}
+
+// Mention is a plain mention as written, without the @, and what it
+// resolved to
+type Mention struct {
+	Mention    string          `json:"mention"`
+	Status     string          `json:"status"`
+	Object     *MentionObject  `json:"object,omitempty"`
+	Candidates []MentionObject `json:"candidates,omitempty"`
+}
+
+// MentionResolution is a text with its plain mentions resolved. Text has
+// the resolved ones rewritten to canonical markers, the others are left as
+// written.
+type MentionResolution struct {
+	Text      string      `json:"text"`
+	ObjectIDs []uuid.UUID `json:"objectIds"`
+	Mentions  []Mention   `json:"mentions"`
+
+	source string
+	spans  []mentionSpan
+}
+
+type mentionSpan struct {
+	start, end int
+	mention    string
+}
+
+// ResolveMentions resolves the plain mentions of text, and the names given
+// as extra (mentioned without being written in the text), by id_string and
+// aliases within the org. A mention matching one object, or the id_string of
+// exactly one, is resolved; others are reported as ambiguous or unknown
+// unless choices, keyed by the mention as written, settle them. Nothing is
+// written: mentions chosen to be created wait for CreateObjects.
+func ResolveMentions(ctx context.Context, q *database.Queries, orgID uuid.UUID, text string, extra []string, choices map[string]MentionChoice) (*MentionResolution, error) {
+	r := &MentionResolution{source: text, ObjectIDs: []uuid.UUID{}, Mentions: []Mention{}}
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, span := range findMentions(text) {
+		r.spans = append(r.spans, span)
+		add(span.mention)
+	}
+	for _, name := range extra {
+		add(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+	}
+
+	for _, name := range names {
+		m, err := resolveMention(ctx, q, orgID, name, choices[name])
+		if err != nil {
+			return nil, err
+		}
+		r.Mentions = append(r.Mentions, m)
+	}
+	r.rewrite()
+	return r, nil
+}
+
+func resolveMention(ctx context.Context, q *database.Queries, orgID uuid.UUID, name string, choice MentionChoice) (Mention, error) {
+	m := Mention{Mention: name}
+	switch choice.Action {
+	case MentionChoiceSkip:
+		m.Status = MentionSkipped
+		return m, nil
+	case MentionChoiceCreate:
+		m.Status = MentionCreate
+		return m, nil
+	case MentionChoicePick:
+		ids, err := q.FilterOrgObjectIDs(ctx, database.FilterOrgObjectIDsParams{
+			Column1: []uuid.UUID{choice.ObjectID},
+			OrgID:   orgID,
+		})
+		if err != nil {
+			return m, err
+		}
+		if len(ids) == 0 {
+			return m, ErrMentionChoice
+		}
+		object := MentionObject{ID: choice.ObjectID}
+		if names, err := q.ListObjectNames(ctx, ids); err == nil && len(names) > 0 {
+			object.Name = names[0].Name
+		}
+		m.Status, m.Object = MentionResolved, &object
+		return m, nil
+	case "":
+	default:
+		return m, ErrMentionChoice
+	}
+
+	rows, err := q.ListObjectsByAliasOrIDString(ctx, database.ListObjectsByAliasOrIDStringParams{
+		Column1: name,
+		OrgID:   orgID,
+		Limit:   maxMentionCandidates + 1,
+	})
+	if err != nil {
+		return m, fmt.Errorf("error resolving mention %q: %w", name, err)
+	}
+	candidates := make([]MentionObject, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, MentionObject{ID: row.ID, Name: row.Name, IDString: row.IDString})
+	}
+	switch {
+	case len(candidates) == 0:
+		m.Status = MentionUnknown
+	case len(candidates) == 1,
+		// The exact id_string of a single object wins over aliases
+		candidates[0].IDString == name && candidates[1].IDString != name:
+		m.Status, m.Object = MentionResolved, &candidates[0]
+	default:
+		if len(candidates) > maxMentionCandidates {
+			candidates = candidates[:maxMentionCandidates]
+		}
+		m.Status, m.Candidates = MentionAmbiguous, candidates
+	}
+	return m, nil
+}
+
+// Unresolved returns the mentions left ambiguous or unknown, which the
+// caller has to pick, create or skip
+func (r *MentionResolution) Unresolved() []Mention {
+	unresolved := []Mention{}
+	for _, m := range r.Mentions {
+		if m.Status == MentionAmbiguous || m.Status == MentionUnknown {
+			unresolved = append(unresolved, m)
+		}
+	}
+	return unresolved
+}
+
+// CreateObjects creates an object for each mention chosen to be created,
+// named after the mention, and rewrites the text with them
+func (r *MentionResolution) CreateObjects(ctx context.Context, q *database.Queries, creatorID uuid.UUID) error {
+	for i, m := range r.Mentions {
+		if m.Status != MentionCreate {
+			continue
+		}
+		obj, err := q.CreateObject(ctx, database.CreateObjectParams{
+			Name:      m.Mention,
+			IDString:  m.Mention,
+			CreatorID: creatorID,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating object for mention %q: %w", m.Mention, err)
+		}
+		if err := history.Record(ctx, q, creatorID, history.Entry{
+			ObjID:   obj.ID,
+			Action:  history.ActionCreated,
+			Changes: history.DiffObject(database.Obj{}, obj),
+		}); err != nil {
+			return err
+		}
+		r.Mentions[i].Status = MentionCreated
+		r.Mentions[i].Object = &MentionObject{ID: obj.ID, Name: obj.Name, IDString: obj.IDString}
+	}
+	r.rewrite()
+	return nil
+}
+
+// Object returns what a mention resolved to, nil if it did not
+func (r *MentionResolution) Object(name string) *MentionObject {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	for _, m := range r.Mentions {
+		if m.Mention == name {
+			return m.Object
+		}
+	}
+	return nil
+}
+
+func (r *MentionResolution) rewrite() {
+	objects := map[string]*MentionObject{}
+	r.ObjectIDs = r.ObjectIDs[:0]
+	seen := map[uuid.UUID]bool{}
+	for _, m := range r.Mentions {
+		if m.Object == nil {
+			continue
+		}
+		objects[m.Mention] = m.Object
+		if !seen[m.Object.ID] {
+			seen[m.Object.ID] = true
+			r.ObjectIDs = append(r.ObjectIDs, m.Object.ID)
+		}
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, span := range r.spans {
+		object := objects[span.mention]
+		if object == nil {
+			continue
+		}
+		out.WriteString(r.source[last:span.start])
+		out.WriteString(MentionMarker(object.Name, object.ID))
+		last = span.end
+	}
+	out.WriteString(r.source[last:])
+	r.Text = out.String()
+}
+
+// MentionMarker is the canonical mention of an object in fact text. The name
+// is escaped like the text of a markdown link, a ] would end it early.
+func MentionMarker(name string, id uuid.UUID) string {
+	return fmt.Sprintf("@[%s](object:%s)", markerNameEscaper.Replace(name), id)
+}
+
+var markerNameEscaper = strings.NewReplacer(`\`, `\\`, `]`, `\]`)
+
+// findMentions returns the plain mentions of text outside canonical markers
+func findMentions(text string) []mentionSpan {
+	markers := mentionMarker.FindAllStringIndex(text, -1)
+	var spans []mentionSpan
+	for _, loc := range plainMention.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[2]-1, loc[3]
+		inMarker := false
+		for _, marker := range markers {
+			if start < marker[1] && end > marker[0] {
+				inMarker = true
+				break
+			}
+		}
+		if inMarker {
+			continue
+		}
+		mention := strings.TrimRight(text[start+1:end], ".-")
+		if mention == "" {
+			continue
+		}
+		spans = append(spans, mentionSpan{start: start, end: start + 1 + len(mention), mention: mention})
+	}
+	return spans
+}
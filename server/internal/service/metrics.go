@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -20,6 +21,8 @@ type DailyMetrics struct {
 	Date                  time.Time `json:"date"`
 	FactsCreated         int64     `json:"factsCreated"`
 	FactObjectsInvolved  int64     `json:"factObjectsInvolved"`
+	// FactsByKind counts the facts created by kind, kinds without any are left out
+	FactsByKind         map[string]int64 `json:"factsByKind"`
 	TasksTotal          int64     `json:"tasksTotal"`
 	TasksCompleted      int64     `json:"tasksCompleted"`
 	TaskObjectsInvolved int64     `json:"taskObjectsInvolved"`
@@ -44,6 +47,7 @@ type MetricsSummary struct {
 	AverageActivityScore   float64 `json:"averageActivityScore"`
 	TotalTasksCompleted    int64   `json:"totalTasksCompleted"`
 	TotalObjectsProcessed  int64   `json:"totalObjectsProcessed"`
+	TotalFactsByKind       map[string]int64 `json:"totalFactsByKind"`
 	MostActiveDate         string  `json:"mostActiveDate"`
 }
 
@@ -88,6 +92,7 @@ func (s *MetricsService) GetCreatorMetrics(ctx context.Context, requesterOrgID,
 	var summary MetricsSummary
 	var maxScore float64
 	var maxScoreDate string
+	summary.TotalFactsByKind = map[string]int64{}
 
 	for i, m := range dailyMetrics {
 		// ActivityDate is now a time.Time directly from the database
@@ -112,6 +117,12 @@ func (s *MetricsService) GetCreatorMetrics(ctx context.Context, requesterOrgID,
 			TypesUpdated:         m.TypesUpdated,
 			ActivityScore:        m.DailyActivityScore,
 		}
+		if err := json.Unmarshal(m.FactsByKind, &metrics[i].FactsByKind); err != nil {
+			return nil, err
+		}
+		for kind, count := range metrics[i].FactsByKind {
+			summary.TotalFactsByKind[kind] += count
+		}
 
 		// Update summary data
 		summary.TotalActivityScore += m.DailyActivityScore
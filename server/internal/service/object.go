@@ -3,11 +3,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
 	"github.com/crea8r/muninn/server/pkg/pagination"
 	"github.com/google/uuid"
 )
@@ -29,13 +31,16 @@ const (
 	OrderByLastFact  OrderBy = "last_fact"
 	OrderByName      OrderBy = "name"
 	OrderByTypeValue OrderBy = "type_value"
+	// OrderByHealthScore sorts on the relationship health score, see
+	// HealthService
+	OrderByHealthScore OrderBy = "health_score"
 )
 
 // IsValid checks if the ordering option is valid
 func (o OrderBy) IsValid() bool {
 	switch o {
 	case OrderByFactCount, OrderByCreatedAt, OrderByFirstFact,
-		OrderByLastFact, OrderByName, OrderByTypeValue:
+		OrderByLastFact, OrderByName, OrderByTypeValue, OrderByHealthScore:
 		return true
 	default:
 		return false
@@ -54,6 +59,16 @@ type ListObjectsParams struct {
 	TypeValueField    string
 	Ascending         bool
 	SubStatusFilter   []int32
+	FactKinds         []string      // Filter by objects with facts of these kinds
+	Viewer            schema.Viewer // Restricted fields are hidden from the viewer
+	// Query is a text query, see package query, added to the filters above
+	Query          string
+	ExcludeSearch  string       // Filter out objects matching any of these words
+	ExcludeStepIDs []uuid.UUID  // Filter out objects in any of these steps
+	ExcludeTagIDs  []uuid.UUID  // Filter out objects with any of these tags
+	ExcludeTypeIDs []uuid.UUID  // Filter out objects of any of these types
+	CreatedAfter   sql.NullTime // Filter by objects created from, inclusive
+	CreatedBefore  sql.NullTime // Filter by objects created before, exclusive
 }
 
 // ListObjectsAdvancedParams represents the database query parameters
@@ -88,25 +103,43 @@ func (s *ObjectService) ListObjects(ctx context.Context, params ListObjectsParam
 	if err := params.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
+	if err := s.applyQuery(ctx, &params); err != nil {
+		return nil, err
+	}
+
+	// Computed fields are not stored, criteria and ordering on them are
+	// applied in memory after the SQL query
+	computed, err := s.loadOrgSchemas(ctx, params.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := computed.checkReadable(params); err != nil {
+		return nil, err
+	}
+	sqlCriteria, computedCriteria := computed.splitCriteria(params.TypeValueCriteria)
+	sortByComputed := params.OrderBy == OrderByTypeValue && computed.isComputed(params.TypeValueField)
+	inMemory := sortByComputed || len(computedCriteria) > 0
 
 	// Prepare type value criteria
 	nullableCriteria1 := json.RawMessage("null")
 	nullableCriteria2 := json.RawMessage("null")
 	nullableCriteria3 := json.RawMessage(`null`)
-	switch len(params.TypeValueCriteria) {
+	switch len(sqlCriteria) {
 	case 3:
-		nullableCriteria3 = params.TypeValueCriteria[2]
+		nullableCriteria3 = sqlCriteria[2]
 		fallthrough
 	case 2:
-		nullableCriteria2 = params.TypeValueCriteria[1]
+		nullableCriteria2 = sqlCriteria[1]
 		fallthrough
 	case 1:
-		nullableCriteria1 = params.TypeValueCriteria[0]
+		nullableCriteria1 = sqlCriteria[0]
 	}
 
 	// Prepare arrays (nil if empty)
 	var stepIDs, tagIDs, typeIDs []uuid.UUID
+	var excludeStepIDs, excludeTagIDs, excludeTypeIDs []uuid.UUID
 	var subStatusFilter []int32
+	var factKinds []string
 	if len(params.StepIDs) > 0 {
 		stepIDs = params.StepIDs
 	}
@@ -116,9 +149,21 @@ func (s *ObjectService) ListObjects(ctx context.Context, params ListObjectsParam
 	if len(params.TypeIDs) > 0 {
 		typeIDs = params.TypeIDs
 	}
+	if len(params.ExcludeStepIDs) > 0 {
+		excludeStepIDs = params.ExcludeStepIDs
+	}
+	if len(params.ExcludeTagIDs) > 0 {
+		excludeTagIDs = params.ExcludeTagIDs
+	}
+	if len(params.ExcludeTypeIDs) > 0 {
+		excludeTypeIDs = params.ExcludeTypeIDs
+	}
 	if len(params.SubStatusFilter) > 0 {
 		subStatusFilter = params.SubStatusFilter
 	}
+	if len(params.FactKinds) > 0 {
+		factKinds = params.FactKinds
+	}
 
 	if s.debug {
 		log.Printf("ListObjects params: stepIDs=%v, tagIDs=%v, typeIDs=%v",
@@ -126,19 +171,39 @@ func (s *ObjectService) ListObjects(ctx context.Context, params ListObjectsParam
 	}
 
 	count, err := s.db.CountObjectsAdvanced(ctx, database.CountObjectsAdvancedParams{
-		OrgID:   params.OrgID,
-		Column2: params.SearchQuery,
-		Column3: stepIDs,
-		Column4: tagIDs,
-		Column5: typeIDs,
-		Column6: nullableCriteria1,
-		Column7: nullableCriteria2,
-		Column8: nullableCriteria3,
-		Column9: subStatusFilter,
+		OrgID:    params.OrgID,
+		Column2:  params.SearchQuery,
+		Column3:  stepIDs,
+		Column4:  tagIDs,
+		Column5:  typeIDs,
+		Column6:  nullableCriteria1,
+		Column7:  nullableCriteria2,
+		Column8:  nullableCriteria3,
+		Column9:  subStatusFilter,
+		Column10: factKinds,
+		Column11: excludeStepIDs,
+		Column12: excludeTagIDs,
+		Column13: excludeTypeIDs,
+		Column14: params.CreatedAfter,
+		Column15: params.CreatedBefore,
+		Column16: string(params.Viewer.Role),
+		Column17: params.ExcludeSearch,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error counting objects: %w", err)
 	}
+	if inMemory {
+		var counts struct {
+			TotalCount int64 `json:"total_count"`
+		}
+		if err := json.Unmarshal(count, &counts); err != nil {
+			return nil, fmt.Errorf("error reading object count: %w", err)
+		}
+		if counts.TotalCount > maxComputedScan {
+			return nil, fmt.Errorf("%w: %d objects match the other filters, narrow them to at most %d",
+				ErrComputedScanLimit, counts.TotalCount, maxComputedScan)
+		}
+	}
 	listParams := database.ListObjectsAdvancedParams{
 		OrgID:    params.OrgID,
 		Column2:  params.SearchQuery,
@@ -154,6 +219,18 @@ func (s *ObjectService) ListObjects(ctx context.Context, params ListObjectsParam
 		Limit:    params.PageSize,
 		Offset:   params.GetOffset(),
 		Column14: subStatusFilter,
+		Column15: factKinds,
+		Column16: excludeStepIDs,
+		Column17: excludeTagIDs,
+		Column18: excludeTypeIDs,
+		Column19: params.CreatedAfter,
+		Column20: params.CreatedBefore,
+		Column21: string(params.Viewer.Role),
+		Column22: params.ExcludeSearch,
+	}
+	if inMemory {
+		listParams.Limit = maxComputedScan
+		listParams.Offset = 0
 	}
 	items, err := s.db.ListObjectsAdvanced(ctx, listParams)
 	if err != nil {
@@ -195,10 +272,64 @@ func (s *ObjectService) ListObjects(ctx context.Context, params ListObjectsParam
 		}
 	}
 
+	if err := computed.attach(ctx, s.db, items); err != nil {
+		return nil, err
+	}
+	var totalCount interface{} = count
+	if inMemory {
+		items = filterComputed(items, computedCriteria)
+		if sortByComputed {
+			sortComputed(items, params.TypeValueField, params.Ascending)
+		}
+		// The count keeps its shape, recounted over the filtered items
+		if totalCount, err = countComputed(count, items, subStatusFilter); err != nil {
+			return nil, err
+		}
+		start := int(params.GetOffset())
+		if start > len(items) {
+			start = len(items)
+		}
+		end := start + int(params.PageSize)
+		if end > len(items) {
+			end = len(items)
+		}
+		items = items[start:end]
+	}
+	computed.redact(items, params.Viewer)
+
 	return &pagination.PaginatedResult[database.ListObjectsAdvancedRow]{
 		Items:      items,
-		TotalCount: count,
+		TotalCount: totalCount,
 		Page:       params.Page,
 		PageSize:   params.PageSize,
 	}, nil
 }
+
+// ListObjectIDs returns the IDs of all objects matching the filters of
+// params, page by page. It stops once more than max are found, the caller
+// tells an oversized selection by a result longer than max.
+func (s *ObjectService) ListObjectIDs(ctx context.Context, params ListObjectsParams, max int) ([]uuid.UUID, error) {
+	params.Page = 1
+	params.PageSize = 1000
+	// A fixed order keeps the pages apart
+	params.OrderBy = OrderByCreatedAt
+	params.TypeValueField = ""
+	// The query is compiled once rather than for every page
+	if err := s.applyQuery(ctx, &params); err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	for {
+		result, err := s.ListObjects(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			ids = append(ids, item.ID)
+		}
+		if len(ids) > max || len(result.Items) < int(params.PageSize) {
+			return ids, nil
+		}
+		params.Page++
+	}
+}
@@ -0,0 +1,53 @@
+// service/object_query.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/crea8r/muninn/server/internal/query"
+)
+
+// applyQuery compiles the text query of params into its filters. Errors in
+// the query are *query.Error.
+func (s *ObjectService) applyQuery(ctx context.Context, params *ListObjectsParams) error {
+	if params.Query == "" {
+		return nil
+	}
+	filter, err := query.Compile(ctx, s.db, params.OrgID, params.Query)
+	if err != nil {
+		return err
+	}
+	if params.StepIDs, err = filter.MergeIDs(query.FieldStep, params.StepIDs); err != nil {
+		return err
+	}
+	if params.TagIDs, err = filter.MergeIDs(query.FieldTag, params.TagIDs); err != nil {
+		return err
+	}
+	if params.TypeIDs, err = filter.MergeIDs(query.FieldType, params.TypeIDs); err != nil {
+		return err
+	}
+	if filter.Uses(query.FieldKind) {
+		if len(params.FactKinds) > 0 {
+			return filter.Errorf(query.FieldKind, "kind is also filtered outside the query, use one or the other")
+		}
+		params.FactKinds = filter.FactKinds
+	}
+	params.SearchQuery = filter.MergeSearch(params.SearchQuery)
+	params.ExcludeSearch = filter.ExcludeSearch
+	params.ExcludeStepIDs = append(params.ExcludeStepIDs, filter.ExcludeStepIDs...)
+	params.ExcludeTagIDs = append(params.ExcludeTagIDs, filter.ExcludeTagIDs...)
+	params.ExcludeTypeIDs = append(params.ExcludeTypeIDs, filter.ExcludeTypeIDs...)
+	if filter.CreatedAfter.Valid && (!params.CreatedAfter.Valid || filter.CreatedAfter.Time.After(params.CreatedAfter.Time)) {
+		params.CreatedAfter = filter.CreatedAfter
+	}
+	if filter.CreatedBefore.Valid && (!params.CreatedBefore.Valid || filter.CreatedBefore.Time.Before(params.CreatedBefore.Time)) {
+		params.CreatedBefore = filter.CreatedBefore
+	}
+	for _, criterion := range filter.Criteria {
+		data, _ := json.Marshal(criterion)
+		params.TypeValueCriteria = append(params.TypeValueCriteria, data)
+	}
+	params.Query = ""
+	return nil
+}
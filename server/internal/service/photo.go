@@ -0,0 +1,274 @@
+// service/photo.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/blob"
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/google/uuid"
+)
+
+// MaxPhotoSize is the largest photo accepted, in bytes
+const MaxPhotoSize = 5 << 20
+
+// MaxPhotoPixels is the largest photo accepted, in pixels. A small file can
+// declare huge dimensions and decoding it would allocate them all.
+const MaxPhotoPixels = 40_000_000
+
+// Sizes a photo is served in
+const (
+	PhotoOriginal = "original"
+	PhotoMedium   = "medium"
+	PhotoThumb    = "thumb"
+)
+
+// photoSizes are the bounding squares of the generated sizes, in pixels
+var photoSizes = map[string]int{
+	PhotoMedium: 512,
+	PhotoThumb:  128,
+}
+
+// photoTypes are the accepted content types and their file extension
+var photoTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// orphanGracePeriod keeps just uploaded photos away from the cleanup, the
+// object they belong to may not point at them yet
+const orphanGracePeriod = time.Hour
+
+var (
+	ErrPhotoType     = errors.New("photo must be a JPEG, PNG or GIF image")
+	ErrPhotoTooLarge = fmt.Errorf("photo is larger than %d MB", MaxPhotoSize>>20)
+	ErrPhotoPixels   = fmt.Errorf("photo is larger than %d megapixels", MaxPhotoPixels/1_000_000)
+	ErrPhotoNotFound = errors.New("photo not found")
+	ErrPhotoObject   = errors.New("object not found")
+)
+
+type PhotoService struct {
+	queries *database.Queries
+	db      *sql.DB
+	store   blob.Store
+}
+
+func NewPhotoService(queries *database.Queries, db *sql.DB, store blob.Store) *PhotoService {
+	return &PhotoService{queries: queries, db: db, store: store}
+}
+
+// PhotoURL is the path obj.photo holds for an uploaded photo
+func PhotoURL(id uuid.UUID) string {
+	return "/photos/" + id.String()
+}
+
+// photoKey is where a size of a photo is stored. Generated sizes of JPEG
+// photos are JPEGs, the others PNGs to keep their transparency.
+func photoKey(photo database.ObjPhoto, size string) string {
+	ext := photoTypes[photo.ContentType]
+	if size != PhotoOriginal && ext != "jpg" {
+		ext = "png"
+	}
+	return fmt.Sprintf("photos/%s/%s.%s", photo.ID, size, ext)
+}
+
+// Upload stores a new photo for an object with its resized versions and makes
+// it the object photo. The previous photo is left to CleanupOrphans.
+func (s *PhotoService) Upload(ctx context.Context, objID, orgID, actorID uuid.UUID, r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxPhotoSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > MaxPhotoSize {
+		return "", ErrPhotoTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := photoTypes[contentType]; !ok {
+		return "", ErrPhotoType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return "", ErrPhotoType
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPhotoPixels {
+		return "", ErrPhotoPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrPhotoType
+	}
+
+	photo := database.ObjPhoto{
+		ID:          uuid.New(),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       int32(img.Bounds().Dx()),
+		Height:      int32(img.Bounds().Dy()),
+	}
+	if err := s.store.Put(ctx, photoKey(photo, PhotoOriginal), bytes.NewReader(data), photo.Size, contentType); err != nil {
+		return "", fmt.Errorf("error storing photo: %w", err)
+	}
+	for size, maxSize := range photoSizes {
+		if err := s.putResized(ctx, photo, size, img, maxSize); err != nil {
+			s.deleteFiles(ctx, photo)
+			return "", err
+		}
+	}
+
+	_, err = s.queries.CreateObjPhoto(ctx, database.CreateObjPhotoParams{
+		ID:          photo.ID,
+		ObjID:       uuid.NullUUID{UUID: objID, Valid: true},
+		CreatorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+		ContentType: photo.ContentType,
+		Size:        photo.Size,
+		Width:       photo.Width,
+		Height:      photo.Height,
+	})
+	if err != nil {
+		s.deleteFiles(ctx, photo)
+		return "", err
+	}
+
+	url := PhotoURL(photo.ID)
+	if err := s.setObjectPhoto(ctx, objID, orgID, actorID, url); err != nil {
+		s.delete(ctx, photo)
+		return "", err
+	}
+	return url, nil
+}
+
+// Remove clears the photo of an object
+func (s *PhotoService) Remove(ctx context.Context, objID, orgID, actorID uuid.UUID) error {
+	return s.setObjectPhoto(ctx, objID, orgID, actorID, "")
+}
+
+func (s *PhotoService) setObjectPhoto(ctx context.Context, objID, orgID, actorID uuid.UUID, url string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := s.queries.WithTx(tx)
+	old, err := q.SetObjectPhoto(ctx, database.SetObjectPhotoParams{
+		ID:    objID,
+		OrgID: orgID,
+		Photo: url,
+	})
+	if err == sql.ErrNoRows {
+		return ErrPhotoObject
+	}
+	if err != nil {
+		return err
+	}
+	if old != url {
+		if err := history.Record(ctx, q, actorID, history.Entry{
+			ObjID:   objID,
+			Action:  history.ActionUpdated,
+			Changes: []history.Change{{Field: "photo", From: old, To: url}},
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PhotoService) putResized(ctx context.Context, photo database.ObjPhoto, size string, img image.Image, maxSize int) error {
+	resized := thumbnail(img, maxSize)
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/png"
+	if photo.ContentType == "image/jpeg" {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, resized)
+	}
+	if err != nil {
+		return fmt.Errorf("error resizing photo: %w", err)
+	}
+	if err := s.store.Put(ctx, photoKey(photo, size), &buf, int64(buf.Len()), contentType); err != nil {
+		return fmt.Errorf("error storing photo: %w", err)
+	}
+	return nil
+}
+
+// Open returns a size of a photo an object of the org shows. Unknown sizes
+// are served as the original.
+func (s *PhotoService) Open(ctx context.Context, id, orgID uuid.UUID, size string) (io.ReadCloser, blob.Info, error) {
+	photo, err := s.queries.GetObjPhoto(ctx, database.GetObjPhotoParams{ID: id, OrgID: orgID})
+	if err == sql.ErrNoRows {
+		return nil, blob.Info{}, ErrPhotoNotFound
+	}
+	if err != nil {
+		return nil, blob.Info{}, err
+	}
+	if _, ok := photoSizes[size]; !ok {
+		size = PhotoOriginal
+	}
+	rc, info, err := s.store.Get(ctx, photoKey(photo, size))
+	if err == blob.ErrNotFound {
+		return nil, blob.Info{}, ErrPhotoNotFound
+	}
+	if err != nil {
+		return nil, blob.Info{}, err
+	}
+	if info.ContentType == "" || strings.HasPrefix(info.ContentType, "application/octet-stream") {
+		info.ContentType = photo.ContentType
+	}
+	return rc, info, nil
+}
+
+// CleanupOrphans deletes the photos no object shows any more, files
+// included: replaced and removed photos, and those of purged objects
+func (s *PhotoService) CleanupOrphans(ctx context.Context) (int, error) {
+	photos, err := s.queries.ListOrphanedObjPhotos(ctx, database.ListOrphanedObjPhotosParams{
+		CreatedAt: time.Now().Add(-orphanGracePeriod),
+		Limit:     500,
+	})
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, photo := range photos {
+		if err := s.delete(ctx, photo); err != nil {
+			log.Printf("Error deleting orphaned photo %s: %v", photo.ID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *PhotoService) delete(ctx context.Context, photo database.ObjPhoto) error {
+	if err := s.deleteFiles(ctx, photo); err != nil {
+		return err
+	}
+	return s.queries.DeleteObjPhoto(ctx, photo.ID)
+}
+
+func (s *PhotoService) deleteFiles(ctx context.Context, photo database.ObjPhoto) error {
+	if err := s.store.Delete(ctx, photoKey(photo, PhotoOriginal)); err != nil {
+		return err
+	}
+	for size := range photoSizes {
+		if err := s.store.Delete(ctx, photoKey(photo, size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// service/search.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+)
+
+// Kinds of search results
+const (
+	SearchObject = "object"
+	SearchFact   = "fact"
+	SearchTask   = "task"
+	SearchTag    = "tag"
+	SearchList   = "list"
+)
+
+// SearchKinds are the kinds searched, in the order their groups are returned
+var SearchKinds = []string{SearchObject, SearchFact, SearchTask, SearchTag, SearchList}
+
+// Where an object hit matched
+const (
+	MatchObject     = "object_content"
+	MatchTypeValues = "type_values"
+)
+
+// Highlights of snippets, the same as the headlines of the object list
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+)
+
+var ErrSearchKind = errors.New("unknown search kind")
+
+// SearchParams is a search of the org for a viewer. Limit and Offset page
+// the hits of each kind in Kinds, the other kinds are only counted.
+type SearchParams struct {
+	OrgID    uuid.UUID
+	ViewerID uuid.UUID
+	Viewer   schema.Viewer
+	Query    string
+	Kinds    []string
+	Limit    int32
+	Offset   int32
+}
+
+// SearchHit is an entity matching a search. Snippet is the matching text
+// with the query terms between <mark> tags, the rest is escaped.
+type SearchHit struct {
+	Kind    string    `json:"kind"`
+	ID      uuid.UUID `json:"id"`
+	Title   string    `json:"title"`
+	Snippet string    `json:"snippet"`
+	Rank    float64   `json:"rank"`
+	// MatchSource says where an object matched, see MatchObject
+	MatchSource string `json:"matchSource,omitempty"`
+	// Data are the fields of the kind shown next to the hit
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// SearchGroup are the hits of a kind, best first
+type SearchGroup struct {
+	Kind  string      `json:"kind"`
+	Count int64       `json:"count"`
+	Hits  []SearchHit `json:"hits"`
+}
+
+// SearchResult is a search across every kind, Facets counts the hits of
+// each kind
+type SearchResult struct {
+	Query  string           `json:"query"`
+	Total  int64            `json:"total"`
+	Facets map[string]int64 `json:"facets"`
+	Groups []SearchGroup    `json:"groups"`
+}
+
+type SearchService struct {
+	queries *database.Queries
+}
+
+func NewSearchService(queries *database.Queries) *SearchService {
+	return &SearchService{queries: queries}
+}
+
+// Search runs the query against every kind. Objects, facts and tasks are
+// those the viewer can see through creator_obj_type_access, and type values
+// are matched and shown without the fields restricted from the viewer.
+func (s *SearchService) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	paged := map[string]bool{}
+	for _, kind := range params.Kinds {
+		if !isSearchKind(kind) {
+			return nil, fmt.Errorf("%w: %s", ErrSearchKind, kind)
+		}
+		paged[kind] = true
+	}
+	if len(paged) == 0 {
+		for _, kind := range SearchKinds {
+			paged[kind] = true
+		}
+	}
+
+	result := &SearchResult{
+		Query:  params.Query,
+		Facets: make(map[string]int64, len(SearchKinds)),
+		Groups: []SearchGroup{},
+	}
+	for _, kind := range SearchKinds {
+		// Kinds not asked for are counted with a single hit
+		limit, offset := params.Limit, params.Offset
+		if !paged[kind] {
+			limit, offset = 1, 0
+		}
+		group, err := s.searchKind(ctx, kind, params, limit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("error searching %ss: %w", kind, err)
+		}
+		result.Facets[kind] = group.Count
+		result.Total += group.Count
+		if paged[kind] {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	return result, nil
+}
+
+func isSearchKind(kind string) bool {
+	for _, k := range SearchKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *SearchService) searchKind(ctx context.Context, kind string, params SearchParams, limit, offset int32) (SearchGroup, error) {
+	group := SearchGroup{Kind: kind, Hits: []SearchHit{}}
+	switch kind {
+	case SearchObject:
+		return s.searchObjects(ctx, params, limit, offset)
+	case SearchFact:
+		rows, err := s.queries.SearchFacts(ctx, database.SearchFactsParams{
+			OrgID: params.OrgID, Column2: params.Query, ViewerID: params.ViewerID, Limit: limit, Offset: offset,
+		})
+		if err != nil {
+			return group, err
+		}
+		for _, row := range rows {
+			group.Count = row.Total
+			data := map[string]interface{}{"kind": row.Kind, "creatorId": row.CreatorID, "createdAt": row.CreatedAt}
+			if row.HappenedAt.Valid {
+				data["happenedAt"] = row.HappenedAt.Time
+			}
+			group.Hits = append(group.Hits, SearchHit{
+				Kind: kind, ID: row.ID, Title: firstLine(row.Text), Snippet: escapeHeadline(row.Headline), Rank: row.Rank, Data: data,
+			})
+		}
+	case SearchTask:
+		rows, err := s.queries.SearchTasks(ctx, database.SearchTasksParams{
+			OrgID: params.OrgID, Column2: params.Query, ViewerID: params.ViewerID, Limit: limit, Offset: offset,
+		})
+		if err != nil {
+			return group, err
+		}
+		for _, row := range rows {
+			group.Count = row.Total
+			data := map[string]interface{}{"status": row.Status, "createdAt": row.CreatedAt}
+			if row.Deadline.Valid {
+				data["deadline"] = row.Deadline.Time
+			}
+			if row.AssignedID.Valid {
+				data["assignedId"] = row.AssignedID.UUID
+			}
+			group.Hits = append(group.Hits, SearchHit{
+				Kind: kind, ID: row.ID, Title: firstLine(row.Content), Snippet: escapeHeadline(row.Headline), Rank: row.Rank, Data: data,
+			})
+		}
+	case SearchTag:
+		rows, err := s.queries.SearchTags(ctx, database.SearchTagsParams{
+			OrgID: params.OrgID, Column2: params.Query, Limit: limit, Offset: offset,
+		})
+		if err != nil {
+			return group, err
+		}
+		for _, row := range rows {
+			group.Count = row.Total
+			group.Hits = append(group.Hits, SearchHit{
+				Kind: kind, ID: row.ID, Title: row.Name, Snippet: escapeHeadline(row.Headline), Rank: row.Rank,
+				Data: map[string]interface{}{"colorSchema": row.ColorSchema},
+			})
+		}
+	case SearchList:
+		rows, err := s.queries.SearchLists(ctx, database.SearchListsParams{
+			OrgID: params.OrgID, Column2: params.Query, Limit: limit, Offset: offset,
+		})
+		if err != nil {
+			return group, err
+		}
+		for _, row := range rows {
+			group.Count = row.Total
+			group.Hits = append(group.Hits, SearchHit{
+				Kind: kind, ID: row.ID, Title: row.Name, Snippet: escapeHeadline(row.Headline), Rank: row.Rank,
+				Data: map[string]interface{}{"creatorId": row.CreatorID},
+			})
+		}
+	}
+	return group, nil
+}
+
+// matchedTypeValues are the readable values of a type matching the query,
+// as aggregated by SearchObjects
+type matchedTypeValues struct {
+	TypeID     uuid.UUID              `json:"type_id"`
+	TypeValues map[string]interface{} `json:"type_values"`
+	Headline   string                 `json:"headline"`
+}
+
+func (s *SearchService) searchObjects(ctx context.Context, params SearchParams, limit, offset int32) (SearchGroup, error) {
+	group := SearchGroup{Kind: SearchObject, Hits: []SearchHit{}}
+	rows, err := s.queries.SearchObjects(ctx, database.SearchObjectsParams{
+		OrgID: params.OrgID, Column2: params.Query, ViewerID: params.ViewerID, Limit: limit, Offset: offset,
+		Column6: params.Viewer.Role,
+	})
+	if err != nil {
+		return group, err
+	}
+	if len(rows) > 0 {
+		group.Count = rows[0].Total
+	}
+	for _, row := range rows {
+		hit := SearchHit{
+			Kind:        SearchObject,
+			ID:          row.ID,
+			Title:       row.Name,
+			Snippet:     escapeHeadline(row.Headline),
+			Rank:        row.Rank,
+			MatchSource: MatchObject,
+			Data:        map[string]interface{}{"idString": row.IDString, "photo": row.Photo, "createdAt": row.CreatedAt},
+		}
+		if row.Headline == "" {
+			// Matched by type values, whose headlines only quote the fields
+			// the viewer may read
+			var matched []matchedTypeValues
+			if err := json.Unmarshal(row.TypeValues, &matched); err != nil {
+				return group, err
+			}
+			snippets := make([]string, 0, len(matched))
+			for _, tv := range matched {
+				snippets = append(snippets, escapeHeadline(tv.Headline))
+			}
+			hit.MatchSource = MatchTypeValues
+			hit.Snippet = strings.Join(snippets, " … ")
+		}
+		group.Hits = append(group.Hits, hit)
+	}
+	return group, nil
+}
+
+// escapeHeadline escapes a headline of ts_headline, keeping its highlights,
+// so texts with markup cannot inject any in the snippet
+func escapeHeadline(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, html.EscapeString(highlightStart), highlightStart)
+	return strings.ReplaceAll(escaped, html.EscapeString(highlightStop), highlightStop)
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if r := []rune(line); len(r) > 80 {
+		return string(r[:80]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,271 @@
+// service/trash.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/history"
+	"github.com/crea8r/muninn/server/internal/schema"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Kinds of items in the trash. Object steps are never listed, they only go
+// through Purge when removed for good.
+const (
+	TrashObject  = "object"
+	TrashFact    = "fact"
+	TrashTask    = "task"
+	TrashFunnel  = "funnel"
+	TrashTag     = "tag"
+	TrashList    = "list"
+	TrashObjStep = "obj_step"
+)
+
+var (
+	ErrTrashKind     = errors.New("unknown trash kind")
+	ErrTrashNotFound = errors.New("item not found in trash")
+	// ErrTrashConflict is returned when restoring an item would clash with
+	// one created since it was deleted
+	ErrTrashConflict = errors.New("item conflicts with an existing one")
+)
+
+// TrashItem is a soft-deleted item waiting to be restored or purged
+type TrashItem struct {
+	Kind          string     `json:"kind"`
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	DeletedAt     time.Time  `json:"deletedAt"`
+	DeletedBy     *uuid.UUID `json:"deletedBy"`
+	DeletedByName string     `json:"deletedByName"`
+}
+
+// PurgeResult counts the items removed by PurgeExpired
+type PurgeResult struct {
+	Objects int64 `json:"objects"`
+	Facts   int64 `json:"facts"`
+	Tasks   int64 `json:"tasks"`
+	Funnels int64 `json:"funnels"`
+	Tags    int64 `json:"tags"`
+	Lists   int64 `json:"lists"`
+}
+
+func (r PurgeResult) Total() int64 {
+	return r.Objects + r.Facts + r.Tasks + r.Funnels + r.Tags + r.Lists
+}
+
+type TrashService struct {
+	queries *database.Queries
+	db      *sql.DB
+}
+
+func NewTrashService(queries *database.Queries, db *sql.DB) *TrashService {
+	return &TrashService{queries: queries, db: db}
+}
+
+// IsTrashKind reports whether kind can be listed and restored
+func IsTrashKind(kind string) bool {
+	switch kind {
+	case TrashObject, TrashFact, TrashTask, TrashFunnel, TrashTag, TrashList:
+		return true
+	}
+	return false
+}
+
+// List returns the soft-deleted items of the org, most recently deleted
+// first. An empty kind lists every kind.
+func (s *TrashService) List(ctx context.Context, orgID uuid.UUID, kind string, limit, offset int32) ([]TrashItem, int64, error) {
+	if kind != "" && !IsTrashKind(kind) {
+		return nil, 0, ErrTrashKind
+	}
+	rows, err := s.queries.ListTrash(ctx, database.ListTrashParams{
+		OrgID:   orgID,
+		Column2: kind,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]TrashItem, 0, len(rows))
+	var total int64
+	for _, row := range rows {
+		total = row.TotalCount
+		item := TrashItem{
+			Kind:          row.Kind,
+			ID:            row.ID,
+			Name:          row.Name,
+			DeletedAt:     row.DeletedAt,
+			DeletedByName: row.DeletedByName.String,
+		}
+		if row.DeletedBy.Valid {
+			deletedBy := row.DeletedBy.UUID
+			item.DeletedBy = &deletedBy
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// Restore brings an item back from the trash. The links an item kept while
+// deleted (facts, tags and type values of an object, objects of a fact) come
+// back with it, the funnel steps of an object are re-linked.
+func (s *TrashService) Restore(ctx context.Context, kind string, id, orgID, actorID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := s.queries.WithTx(tx)
+
+	params := database.RestoreObjectParams{ID: id, OrgID: orgID}
+	var rows int64
+	switch kind {
+	case TrashObject:
+		if err := s.checkUniqueValues(ctx, id, orgID); err != nil {
+			return err
+		}
+		rows, err = q.RestoreObject(ctx, params)
+	case TrashFact:
+		rows, err = q.RestoreFact(ctx, database.RestoreFactParams(params))
+	case TrashTask:
+		rows, err = q.RestoreTask(ctx, database.RestoreTaskParams(params))
+	case TrashFunnel:
+		rows, err = q.RestoreFunnel(ctx, database.RestoreFunnelParams(params))
+	case TrashTag:
+		rows, err = q.RestoreTag(ctx, database.RestoreTagParams(params))
+	case TrashList:
+		rows, err = q.RestoreList(ctx, database.RestoreListParams(params))
+	default:
+		return ErrTrashKind
+	}
+	if err != nil {
+		return restoreError(err)
+	}
+	if rows == 0 {
+		return ErrTrashNotFound
+	}
+	if kind == TrashObject {
+		if err := history.Record(ctx, q, actorID, history.Entry{ObjID: id, Action: history.ActionRestored}); err != nil {
+			return err
+		}
+	}
+	// Unique type values are checked again at commit
+	if err := tx.Commit(); err != nil {
+		return restoreError(err)
+	}
+	return nil
+}
+
+// restoreError is ErrTrashConflict when a restored item clashes with a
+// unique value taken meanwhile
+func restoreError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrTrashConflict
+	}
+	return err
+}
+
+// checkUniqueValues makes sure the unique fields of a deleted object were not
+// taken by another object in the meantime
+func (s *TrashService) checkUniqueValues(ctx context.Context, id, orgID uuid.UUID) error {
+	typeValues, err := s.queries.ListObjectTypeValuesByObject(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, tv := range typeValues {
+		typeSchema, err := schema.Load(ctx, s.queries, tv.TypeID)
+		if err != nil {
+			continue
+		}
+		err = typeSchema.CheckUniqueRaw(ctx, s.queries, orgID, tv.TypeID, tv.TypeValues, id)
+		var conflict *schema.UniqueConflictError
+		if errors.As(err, &conflict) {
+			return fmt.Errorf("%w: %v", ErrTrashConflict, conflict)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge removes an item for good. Items other than object steps must be in
+// the trash first.
+func (s *TrashService) Purge(ctx context.Context, kind string, id, orgID, actorID uuid.UUID) error {
+	params := database.PurgeObjectParams{ID: id, OrgID: orgID}
+	var rows int64
+	var err error
+	switch kind {
+	case TrashObject:
+		rows, err = s.queries.PurgeObject(ctx, params)
+	case TrashFact:
+		rows, err = s.queries.PurgeFact(ctx, database.PurgeFactParams(params))
+	case TrashTask:
+		rows, err = s.queries.PurgeTask(ctx, database.PurgeTaskParams(params))
+	case TrashFunnel:
+		rows, err = s.queries.PurgeFunnel(ctx, database.PurgeFunnelParams(params))
+	case TrashTag:
+		rows, err = s.queries.PurgeTag(ctx, database.PurgeTagParams(params))
+	case TrashList:
+		rows, err = s.queries.PurgeList(ctx, database.PurgeListParams(params))
+	case TrashObjStep:
+		return s.purgeObjStep(ctx, id, orgID, actorID)
+	default:
+		return ErrTrashKind
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTrashNotFound
+	}
+	return nil
+}
+
+// purgeObjStep removes an object from a funnel step for good, recording the
+// removal when the object was still in the step
+func (s *TrashService) purgeObjStep(ctx context.Context, id, orgID, actorID uuid.UUID) error {
+	objStep, err := s.queries.GetObjStep(ctx, id)
+	if err == sql.ErrNoRows {
+		return ErrTrashNotFound
+	}
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := s.queries.WithTx(tx)
+	rows, err := q.PurgeObjStep(ctx, database.PurgeObjStepParams{ID: id, OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTrashNotFound
+	}
+	if !objStep.DeletedAt.Valid {
+		if err := history.RecordStep(ctx, q, history.SourceFrom(ctx), actorID, objStep.ObjID, objStep.StepID, history.ActionStepRemoved); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PurgeExpired removes the items deleted through the trash longer than
+// retention ago, in every org. Items deleted before the trash existed stay.
+func (s *TrashService) PurgeExpired(ctx context.Context, retention time.Duration) (PurgeResult, error) {
+	row, err := s.queries.PurgeExpiredTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return PurgeResult{}, err
+	}
+	return PurgeResult(row), nil
+}
@@ -9,26 +9,44 @@ import (
 	"sync"
 	"time"
 
+	"github.com/crea8r/muninn/server/internal/calendar"
 	"github.com/crea8r/muninn/server/internal/database"
 	"github.com/crea8r/muninn/server/internal/service"
 )
 
 const automationInterval = 10 * time.Minute
+const trashPurgeInterval = 1 * time.Hour
+const healthScoreInterval = 6 * time.Hour
+// healthScoreDelay leaves a starting server a moment before the first scoring
+const healthScoreDelay = 10 * time.Minute
+const calendarSyncInterval = 1 * time.Hour
 
 // Runner handles periodic task execution
 type Runner struct {
     db              *database.Queries
     automationSvc   *service.AutomationService
+    trashSvc        *service.TrashService
+    photoSvc        *service.PhotoService
+    attachmentSvc   *service.AttachmentService
+    healthSvc       *service.HealthService
+    calendars       *calendar.Importer
+    trashRetention  time.Duration
     wg              sync.WaitGroup
     shutdown        chan struct{}
     log             *log.Logger
 }
 
 // NewRunner creates a new task runner
-func NewRunner(db *database.Queries, automationSvc *service.AutomationService) *Runner {
+func NewRunner(db *database.Queries, automationSvc *service.AutomationService, trashSvc *service.TrashService, photoSvc *service.PhotoService, attachmentSvc *service.AttachmentService, healthSvc *service.HealthService, calendars *calendar.Importer, trashRetention time.Duration) *Runner {
     return &Runner{
-        db:            db,
-        automationSvc: automationSvc,
+        db:             db,
+        automationSvc:  automationSvc,
+        trashSvc:       trashSvc,
+        photoSvc:       photoSvc,
+        attachmentSvc:  attachmentSvc,
+        healthSvc:      healthSvc,
+        calendars:      calendars,
+        trashRetention: trashRetention,
         shutdown:      make(chan struct{}),
         log:          log.New(log.Writer(), "[TaskRunner] ", log.LstdFlags),
     }
@@ -36,8 +54,11 @@ func NewRunner(db *database.Queries, automationSvc *service.AutomationService) *
 
 // Start begins the periodic execution of tasks
 func (r *Runner) Start() {
-    r.wg.Add(1)
+    r.wg.Add(4)
     go r.runAutomationLoop()
+    go r.runTrashPurgeLoop()
+    go r.runHealthScoreLoop()
+    go r.runCalendarSyncLoop()
 }
 
 // Stop gracefully shuts down the task runner
@@ -122,4 +143,139 @@ func (r *Runner) executeAutomatedActions() {
 	}
 
 	wg.Wait()
-}
\ No newline at end of file
+}
+
+// runTrashPurgeLoop first runs an interval after boot, a server started
+// with the wrong retention can still be stopped before anything is purged
+func (r *Runner) runTrashPurgeLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.purgeExpiredTrash()
+			r.cleanupOrphanedFiles()
+		case <-r.shutdown:
+			r.log.Println("Shutting down trash purge runner")
+			return
+		}
+	}
+}
+
+// purgeExpiredTrash hard-deletes the items that stayed in the trash longer
+// than the retention period, never when the retention is 0
+func (r *Runner) purgeExpiredTrash() {
+	if r.trashRetention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := r.trashSvc.PurgeExpired(ctx, r.trashRetention)
+	if err != nil {
+		r.log.Printf("Error purging expired trash: %v", err)
+		return
+	}
+	if result.Total() > 0 {
+		r.log.Printf("Purged %d expired trash items", result.Total())
+	}
+}
+
+// cleanupOrphanedFiles deletes the photos no object shows any more and the
+// attachments left without fact and object, including those of the items
+// just purged
+func (r *Runner) cleanupOrphanedFiles() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	deleted, err := r.photoSvc.CleanupOrphans(ctx)
+	if err != nil {
+		r.log.Printf("Error cleaning up orphaned photos: %v", err)
+	} else if deleted > 0 {
+		r.log.Printf("Deleted %d orphaned photos", deleted)
+	}
+
+	deleted, err = r.attachmentSvc.CleanupOrphans(ctx)
+	if err != nil {
+		r.log.Printf("Error cleaning up orphaned attachments: %v", err)
+	} else if deleted > 0 {
+		r.log.Printf("Deleted %d orphaned attachments", deleted)
+	}
+}
+
+func (r *Runner) runHealthScoreLoop() {
+	defer r.wg.Done()
+
+	select {
+	case <-time.After(healthScoreDelay):
+		r.recomputeHealthScores()
+	case <-r.shutdown:
+		r.log.Println("Shutting down health score runner")
+		return
+	}
+
+	ticker := time.NewTicker(healthScoreInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.recomputeHealthScores()
+		case <-r.shutdown:
+			r.log.Println("Shutting down health score runner")
+			return
+		}
+	}
+}
+
+// recomputeHealthScores rescores every object, scores decay as facts get
+// older even when nothing happens
+func (r *Runner) recomputeHealthScores() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	scored, err := r.healthSvc.RecomputeAll(ctx)
+	if err != nil {
+		r.log.Printf("Error computing health scores: %v", err)
+	}
+	if scored > 0 {
+		r.log.Printf("Computed the health score of %d objects", scored)
+	}
+}
+
+func (r *Runner) runCalendarSyncLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(calendarSyncInterval)
+	defer ticker.Stop()
+
+	r.syncCalendarFeeds()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.syncCalendarFeeds()
+		case <-r.shutdown:
+			r.log.Println("Shutting down calendar sync runner")
+			return
+		}
+	}
+}
+
+// syncCalendarFeeds imports the events of the subscribed calendars that
+// started since their last sync
+func (r *Runner) syncCalendarFeeds() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	imported, err := r.calendars.SyncAll(ctx)
+	if err != nil {
+		r.log.Printf("Error syncing calendar feeds: %v", err)
+	}
+	if imported > 0 {
+		r.log.Printf("Imported %d calendar events", imported)
+	}
+}
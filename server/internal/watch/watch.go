@@ -0,0 +1,236 @@
+// Package watch delivers feed entries to the creators watching an object, a
+// list or a funnel step.
+//
+// A watch names the events its creator wants: new facts, new tasks, step
+// moves and type value changes of the watched objects. A list watch covers
+// the objects matching the filter of the list when the event happens, a step
+// watch those in the step. The creator making a change is never notified of
+// it, and a watcher gets one entry per object even when several of their
+// watches cover it.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"unicode/utf8"
+
+	"github.com/crea8r/muninn/server/internal/database"
+	"github.com/crea8r/muninn/server/internal/query"
+	"github.com/google/uuid"
+)
+
+// Events a watch can subscribe to
+const (
+	EventFact      = "fact"
+	EventTask      = "task"
+	EventStep      = "step"
+	EventTypeValue = "type_value"
+)
+
+// Events lists every event, a watch created without events gets them all
+var Events = []string{EventFact, EventTask, EventStep, EventTypeValue}
+
+// Actions of fact and task events, step and type value events carry the
+// history action
+const (
+	ActionCreated = "created"
+	ActionLinked  = "linked"
+)
+
+// maxSummary bounds the fact or task text copied into a feed entry
+const maxSummary = 200
+
+// IsEvent reports whether event is one a watch can subscribe to
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened to objects. RefID is the fact or task of
+// fact and task events, TypeID the object type of type value events.
+type Event struct {
+	Kind    string
+	Action  string
+	ObjIDs  []uuid.UUID
+	RefID   uuid.UUID
+	TypeID  uuid.UUID
+	Summary string
+}
+
+// Content is the content of the feed entry of a watched event
+type Content struct {
+	Type       string     `json:"type"`
+	Event      string     `json:"event"`
+	Action     string     `json:"action"`
+	ObjectID   uuid.UUID  `json:"objectId"`
+	ObjectName string     `json:"objectName"`
+	ActorID    uuid.UUID  `json:"actorId"`
+	WatchID    uuid.UUID  `json:"watchId"`
+	RefID      *uuid.UUID `json:"refId,omitempty"`
+	TypeID     *uuid.UUID `json:"typeId,omitempty"`
+	Summary    string     `json:"summary,omitempty"`
+}
+
+// Notify writes a feed entry for each watcher of the objects of the event
+// made by actorID. Like history, it runs in the transaction of the change it
+// describes, which is not saved when a notification fails.
+func Notify(ctx context.Context, db *database.Queries, actorID uuid.UUID, e Event) error {
+	if len(e.ObjIDs) == 0 {
+		return nil
+	}
+
+	// watcher -> object -> watch
+	recipients := map[uuid.UUID]map[uuid.UUID]uuid.UUID{}
+	add := func(creatorID, objID, watchID uuid.UUID) {
+		if recipients[creatorID] == nil {
+			recipients[creatorID] = map[uuid.UUID]uuid.UUID{}
+		}
+		if _, ok := recipients[creatorID][objID]; !ok {
+			recipients[creatorID][objID] = watchID
+		}
+	}
+
+	watchers, err := db.ListObjectWatchers(ctx, database.ListObjectWatchersParams{
+		Column1: e.ObjIDs,
+		Column2: e.Kind,
+		ActorID: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing watchers of %s event: %w", e.Kind, err)
+	}
+	for _, w := range watchers {
+		add(w.CreatorID, w.ObjID, w.ID)
+	}
+
+	listWatches, err := db.ListListWatches(ctx, database.ListListWatchesParams{
+		Column1: e.Kind,
+		ActorID: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing list watchers of %s event: %w", e.Kind, err)
+	}
+	// Lists are matched once however many creators watch them, all in one
+	// query
+	matched, err := matchLists(ctx, db, listWatches, e.ObjIDs)
+	if err != nil {
+		return fmt.Errorf("error matching lists of %s event: %w", e.Kind, err)
+	}
+	for _, w := range listWatches {
+		for _, objID := range matched[w.ListID] {
+			add(w.CreatorID, objID, w.ID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	names := map[uuid.UUID]string{}
+	objects, err := db.ListObjectNames(ctx, e.ObjIDs)
+	if err != nil {
+		return fmt.Errorf("error loading watched object names: %w", err)
+	}
+	for _, o := range objects {
+		names[o.ID] = o.Name
+	}
+
+	for creatorID, objs := range recipients {
+		for objID, watchID := range objs {
+			content := Content{
+				Type:       "watch",
+				Event:      e.Kind,
+				Action:     e.Action,
+				ObjectID:   objID,
+				ObjectName: names[objID],
+				ActorID:    actorID,
+				WatchID:    watchID,
+				Summary:    truncate(e.Summary, maxSummary),
+			}
+			if e.RefID != uuid.Nil {
+				content.RefID = &e.RefID
+			}
+			if e.TypeID != uuid.Nil {
+				content.TypeID = &e.TypeID
+			}
+			data, _ := json.Marshal(content)
+			if _, err := db.CreateFeed(ctx, database.CreateFeedParams{
+				CreatorID: creatorID,
+				Content:   data,
+				Seen:      false,
+			}); err != nil {
+				return fmt.Errorf("error creating feed of watch %s: %w", watchID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchLists returns, by list, the objects of objIDs matching the filters of
+// the watched lists. A list whose filter no longer compiles matches nothing,
+// other errors are returned.
+func matchLists(ctx context.Context, db *database.Queries, watches []database.ListListWatchesRow, objIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	matched := map[uuid.UUID][]uuid.UUID{}
+	if len(watches) == 0 {
+		return matched, nil
+	}
+	seen := map[uuid.UUID]bool{}
+	var filters []query.ListMatch
+	for _, w := range watches {
+		if seen[w.ListID] {
+			continue
+		}
+		seen[w.ListID] = true
+		filter, err := query.CompileListFilter(ctx, db, w.OrgID, w.FilterSetting)
+		if invalidFilter(err) {
+			log.Printf("Error matching list %s: %v", w.ListID, err)
+			continue
+		}
+		if err != nil {
+			return matched, err
+		}
+		filter.ListID = w.ListID
+		filters = append(filters, filter)
+	}
+	if len(filters) == 0 {
+		return matched, nil
+	}
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return matched, err
+	}
+	// The watches all belong to the org of the actor
+	rows, err := db.MatchListFilters(ctx, database.MatchListFiltersParams{
+		OrgID:   watches[0].OrgID,
+		Column2: objIDs,
+		Column3: data,
+	})
+	if err != nil {
+		return matched, err
+	}
+	for _, row := range rows {
+		matched[row.ListID] = append(matched[row.ListID], row.ObjID)
+	}
+	return matched, nil
+}
+
+// invalidFilter tells if err is about the filter of a list rather than the
+// database
+func invalidFilter(err error) bool {
+	var queryErr *query.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &queryErr) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + "…"
+}
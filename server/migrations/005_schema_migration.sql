@@ -0,0 +1,23 @@
+-- Background jobs that rewrite obj_type_value.type_values after a field
+-- schema change (rename, drop, convert, split, merge)
+CREATE TABLE schema_migration (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES org(id),
+    creator_id UUID NOT NULL REFERENCES creator(id),
+    obj_type_id UUID NOT NULL REFERENCES obj_type(id) ON DELETE CASCADE,
+    operations JSONB NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    progress INTEGER DEFAULT 0,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER DEFAULT 0,
+    failed_rows INTEGER DEFAULT 0,
+    error_message TEXT,
+    result_summary JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_schema_migration_org_id ON schema_migration(org_id);
+CREATE INDEX idx_schema_migration_obj_type_id ON schema_migration(obj_type_id);
+CREATE INDEX idx_schema_migration_status ON schema_migration(status);
@@ -0,0 +1,48 @@
+-- Typed, directed relations between objects ("works at", "invested in", ...)
+CREATE TABLE obj_relation (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    from_obj_id UUID NOT NULL REFERENCES obj(id) ON DELETE CASCADE,
+    to_obj_id UUID NOT NULL REFERENCES obj(id) ON DELETE CASCADE,
+    relation_type VARCHAR(100) NOT NULL CHECK (relation_type <> ''),
+    metadata JSONB NOT NULL DEFAULT '{}',
+    creator_id UUID NOT NULL REFERENCES creator(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_obj_id <> to_obj_id),
+    UNIQUE (from_obj_id, to_obj_id, relation_type)
+);
+
+CREATE INDEX idx_obj_relation_from_obj_id ON obj_relation(from_obj_id);
+CREATE INDEX idx_obj_relation_to_obj_id ON obj_relation(to_obj_id);
+
+-- Backfill from "object" fields, which hold the id of the related object (or
+-- {id, name}, or an array of either) in type_values. The field name becomes
+-- the relation type.
+INSERT INTO obj_relation (from_obj_id, to_obj_id, relation_type, creator_id)
+SELECT DISTINCT otv.obj_id, target.id, LEFT(f.key, 100), o.creator_id
+FROM obj_type_value otv
+JOIN obj o ON o.id = otv.obj_id
+JOIN creator oc ON oc.id = o.creator_id
+JOIN obj_type ot ON ot.id = otv.type_id
+CROSS JOIN LATERAL jsonb_each(
+    CASE WHEN jsonb_typeof(ot.fields) = 'object' THEN ot.fields ELSE '{}'::jsonb END
+) f
+CROSS JOIN LATERAL (
+    SELECT CASE jsonb_typeof(elem)
+        WHEN 'string' THEN elem #>> '{}'
+        WHEN 'object' THEN elem ->> 'id'
+    END AS ref
+    FROM jsonb_array_elements(
+        CASE jsonb_typeof(otv.type_values -> f.key)
+            WHEN 'array' THEN otv.type_values -> f.key
+            ELSE jsonb_build_array(otv.type_values -> f.key)
+        END
+    ) elem
+) r
+JOIN obj target ON target.id::text = r.ref
+JOIN creator tc ON tc.id = target.creator_id
+WHERE jsonb_typeof(f.value) = 'object'
+  AND f.value ->> 'type' = 'object'
+  AND f.key <> ''
+  AND tc.org_id = oc.org_id
+  AND target.id <> otv.obj_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+-- Change history of objects: who changed what, and through which entry point
+CREATE TABLE obj_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    obj_id UUID NOT NULL REFERENCES obj(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES creator(id),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('ui', 'external_api', 'automation', 'import')),
+    action VARCHAR(50) NOT NULL,
+    -- Set for type value changes, so restricted fields can be hidden on read
+    type_id UUID REFERENCES obj_type(id),
+    -- Field-level diffs: [{"field": ..., "from": ..., "to": ...}]
+    changes JSONB NOT NULL DEFAULT '[]',
+    -- What the action was applied with: tag, step, merged objects...
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_obj_history_obj_id_created_at ON obj_history(obj_id, created_at DESC);
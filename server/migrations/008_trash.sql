@@ -0,0 +1,25 @@
+-- Trash bin: remember who deleted what, so deleted items can be listed and
+-- restored until the retention job purges them
+ALTER TABLE obj ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+ALTER TABLE fact ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+ALTER TABLE task ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+ALTER TABLE funnel ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+ALTER TABLE tag ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+ALTER TABLE list ADD COLUMN deleted_by UUID REFERENCES creator(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_obj_deleted_at ON obj(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_fact_deleted_at ON fact(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_task_deleted_at ON task(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_funnel_deleted_at ON funnel(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_tag_deleted_at ON tag(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_list_deleted_at ON list(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Tags are now soft deleted, a tag in the trash must not block creating a new
+-- one with the same name
+ALTER TABLE tag DROP CONSTRAINT tag_name_org_id_key;
+CREATE UNIQUE INDEX idx_tag_name_org_id ON tag(name, org_id) WHERE deleted_at IS NULL;
+
+-- Purging a merge target removes its merge history
+ALTER TABLE object_merge_history DROP CONSTRAINT object_merge_history_target_object_id_fkey;
+ALTER TABLE object_merge_history ADD CONSTRAINT object_merge_history_target_object_id_fkey
+    FOREIGN KEY (target_object_id) REFERENCES obj(id) ON DELETE CASCADE;
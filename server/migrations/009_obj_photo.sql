@@ -0,0 +1,15 @@
+-- Photos uploaded for objects. The files live in the blob store, obj.photo
+-- points at the current one as /photos/<id>. A photo no object points at any
+-- more (replaced, removed, or its object purged) is cleaned up with its files.
+CREATE TABLE obj_photo (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    obj_id UUID REFERENCES obj(id) ON DELETE SET NULL,
+    creator_id UUID REFERENCES creator(id) ON DELETE SET NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_obj_photo_obj_id ON obj_photo(obj_id);
@@ -0,0 +1,19 @@
+-- Files attached to facts and objects. The content lives in the blob store
+-- under attachments/<id>. An attachment whose fact or object was purged is
+-- left with neither and cleaned up with its file.
+CREATE TABLE attachment (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES org(id) ON DELETE CASCADE,
+    fact_id UUID REFERENCES fact(id) ON DELETE SET NULL,
+    obj_id UUID REFERENCES obj(id) ON DELETE SET NULL,
+    creator_id UUID REFERENCES creator(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    -- Text extracted from PDF and plain text files, searched with the facts
+    text_content TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_attachment_fact_id ON attachment(fact_id);
+CREATE INDEX idx_attachment_obj_id ON attachment(obj_id);
@@ -0,0 +1,24 @@
+-- Background jobs that apply one operation (tags, type value, step, owner,
+-- delete) to a selection of objects
+CREATE TABLE bulk_operation (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES org(id),
+    creator_id UUID NOT NULL REFERENCES creator(id),
+    operation VARCHAR(30) NOT NULL CHECK (operation IN (
+        'add_tags', 'remove_tags', 'set_type_value', 'move_to_step',
+        'set_sub_status', 'assign_owner', 'delete'
+    )),
+    params JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    progress INTEGER DEFAULT 0,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER DEFAULT 0,
+    failed_rows INTEGER DEFAULT 0,
+    error_message TEXT,
+    result_summary JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_operation_org_id ON bulk_operation(org_id);
+CREATE INDEX idx_bulk_operation_status ON bulk_operation(status);
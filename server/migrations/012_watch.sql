@@ -0,0 +1,20 @@
+-- Watch subscriptions: a creator follows an object, the objects of a list or
+-- those in a funnel step, and gets a feed entry for the chosen events
+CREATE TABLE watch (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    creator_id UUID NOT NULL REFERENCES creator(id) ON DELETE CASCADE,
+    obj_id UUID REFERENCES obj(id) ON DELETE CASCADE,
+    list_id UUID REFERENCES list(id) ON DELETE CASCADE,
+    step_id UUID REFERENCES step(id) ON DELETE CASCADE,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(obj_id, list_id, step_id) = 1),
+    CHECK (events <@ ARRAY['fact', 'task', 'step', 'type_value']::text[])
+);
+
+CREATE UNIQUE INDEX idx_watch_creator_obj ON watch(creator_id, obj_id) WHERE obj_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_watch_creator_list ON watch(creator_id, list_id) WHERE list_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_watch_creator_step ON watch(creator_id, step_id) WHERE step_id IS NOT NULL;
+CREATE INDEX idx_watch_obj_id ON watch(obj_id);
+CREATE INDEX idx_watch_list_id ON watch(list_id);
+CREATE INDEX idx_watch_step_id ON watch(step_id);
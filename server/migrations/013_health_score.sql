@@ -0,0 +1,26 @@
+-- Relationship health: an engagement score per object computed from its
+-- facts, tasks and funnel steps, with the weights of the components set per
+-- org. obj_health keeps the current score, obj_health_history one score a
+-- day for trends.
+CREATE TABLE health_score_setting (
+    org_id UUID PRIMARY KEY REFERENCES org(id) ON DELETE CASCADE,
+    weights JSONB NOT NULL,
+    updated_by UUID REFERENCES creator(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE obj_health (
+    obj_id UUID PRIMARY KEY REFERENCES obj(id) ON DELETE CASCADE,
+    score REAL NOT NULL CHECK (score >= 0 AND score <= 100),
+    components JSONB NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_obj_health_score ON obj_health(score);
+
+CREATE TABLE obj_health_history (
+    obj_id UUID NOT NULL REFERENCES obj(id) ON DELETE CASCADE,
+    scored_on DATE NOT NULL,
+    score REAL NOT NULL,
+    PRIMARY KEY (obj_id, scored_on)
+);
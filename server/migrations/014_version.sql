@@ -0,0 +1,27 @@
+-- Versions for optimistic concurrency: clients send the version they edited
+-- as If-Match and get a conflict when someone changed the row meanwhile.
+-- The trigger bumps the version whenever the content changes, whatever the
+-- query, so merges, imports and restores are seen too.
+ALTER TABLE obj ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE obj_type_value ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bump_obj_version
+BEFORE UPDATE ON obj
+FOR EACH ROW
+WHEN ((OLD.name, OLD.photo, OLD.description, OLD.id_string, OLD.aliases, OLD.creator_id)
+    IS DISTINCT FROM (NEW.name, NEW.photo, NEW.description, NEW.id_string, NEW.aliases, NEW.creator_id))
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER bump_obj_type_value_version
+BEFORE UPDATE ON obj_type_value
+FOR EACH ROW
+WHEN (OLD.type_values IS DISTINCT FROM NEW.type_values)
+EXECUTE FUNCTION bump_version();
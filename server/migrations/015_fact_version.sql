@@ -0,0 +1,27 @@
+-- Versions of facts: a snapshot of the text, date, location and linked
+-- objects after every change, with who made it. Facts are the record of
+-- conversations, so edits never lose what was there before.
+CREATE TABLE fact_version (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fact_id UUID NOT NULL REFERENCES fact(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    text TEXT NOT NULL,
+    happened_at TIMESTAMP WITH TIME ZONE,
+    location TEXT NOT NULL,
+    obj_ids UUID[] NOT NULL DEFAULT '{}',
+    editor_id UUID NOT NULL REFERENCES creator(id),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('ui', 'external_api', 'automation', 'import')),
+    -- created, updated or restored
+    action VARCHAR(20) NOT NULL,
+    -- The version a restored version was copied from
+    restored_from INT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (fact_id, version)
+);
+
+-- Existing facts start from their current state, earlier edits were not kept
+INSERT INTO fact_version (fact_id, version, text, happened_at, location, obj_ids, editor_id, source, action, created_at)
+SELECT f.id, 1, f.text, f.happened_at, f.location,
+    ARRAY(SELECT of.obj_id FROM obj_fact of WHERE of.fact_id = f.id ORDER BY of.obj_id),
+    f.creator_id, 'ui', 'created', f.created_at
+FROM fact f;
@@ -0,0 +1,12 @@
+-- Kinds of facts (meeting, call, email...) with metadata checked against the
+-- schema of the kind, so activity can be reported without parsing text.
+-- Existing facts are notes.
+ALTER TABLE fact ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'note'
+    CHECK (kind IN ('meeting', 'call', 'email', 'intro', 'deal_event', 'note'));
+ALTER TABLE fact ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_fact_kind_created_at ON fact(kind, created_at);
+
+-- Versions keep the kind and metadata too
+ALTER TABLE fact_version ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'note';
+ALTER TABLE fact_version ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
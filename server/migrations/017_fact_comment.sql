@@ -0,0 +1,23 @@
+-- Discussions on facts: threaded comments, whose @mentions of creators
+-- notify them in their feed, and emoji reactions
+CREATE TABLE fact_comment (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fact_id UUID NOT NULL REFERENCES fact(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES fact_comment(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES creator(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_updated TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_fact_comment_fact_id ON fact_comment(fact_id, created_at);
+CREATE INDEX idx_fact_comment_parent_id ON fact_comment(parent_id);
+
+CREATE TABLE fact_reaction (
+    fact_id UUID NOT NULL REFERENCES fact(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES creator(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (fact_id, creator_id, emoji)
+);
@@ -0,0 +1,30 @@
+-- Mail drop-box: mail sent or BCC'd to <token>@MAIL_DOMAIN is logged as a
+-- fact of the org owning the token. Facts of senders who are not members of
+-- the org are created by the creator of the drop-box.
+CREATE TABLE mail_dropbox (
+    org_id UUID PRIMARY KEY REFERENCES org(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    creator_id UUID NOT NULL REFERENCES creator(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Outside messages facts were made from, keyed by their id (the Message-ID
+-- of an email), so the same message is never logged twice
+CREATE TABLE fact_source (
+    org_id UUID NOT NULL REFERENCES org(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    external_id TEXT NOT NULL,
+    fact_id UUID NOT NULL REFERENCES fact(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, source, external_id)
+);
+
+CREATE INDEX idx_fact_source_fact_id ON fact_source(fact_id);
+
+-- Changes made by the drop-box have their own source
+ALTER TABLE obj_history DROP CONSTRAINT obj_history_source_check;
+ALTER TABLE obj_history ADD CONSTRAINT obj_history_source_check
+    CHECK (source IN ('ui', 'external_api', 'automation', 'import', 'email'));
+ALTER TABLE fact_version DROP CONSTRAINT fact_version_source_check;
+ALTER TABLE fact_version ADD CONSTRAINT fact_version_source_check
+    CHECK (source IN ('ui', 'external_api', 'automation', 'import', 'email'));
@@ -0,0 +1,33 @@
+-- Email imports: .eml files or an mbox archive are imported as facts of
+-- kind email, tracked by import_task like row imports but without an object
+-- type
+ALTER TABLE import_task ALTER COLUMN obj_type_id DROP NOT NULL;
+ALTER TABLE import_task ADD COLUMN mode VARCHAR(20) NOT NULL DEFAULT 'objects'
+    CHECK (mode IN ('objects', 'email'));
+
+-- Review queue of the addresses of imported emails matching no object nor
+-- member, to turn into objects or dismiss. The facts an address was seen in
+-- are linked to the object it becomes.
+CREATE TABLE address_review (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES org(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    -- Last import the address was seen in
+    import_task_id UUID REFERENCES import_task(id) ON DELETE SET NULL,
+    message_count INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'created', 'dismissed')),
+    obj_id UUID REFERENCES obj(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (org_id, email)
+);
+
+CREATE INDEX idx_address_review_org_status ON address_review(org_id, status);
+
+CREATE TABLE address_review_fact (
+    review_id UUID NOT NULL REFERENCES address_review(id) ON DELETE CASCADE,
+    fact_id UUID NOT NULL REFERENCES fact(id) ON DELETE CASCADE,
+    PRIMARY KEY (review_id, fact_id)
+);
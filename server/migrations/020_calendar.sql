@@ -0,0 +1,19 @@
+-- Calendar imports: events of iCalendar files become meeting facts, keyed
+-- in fact_source by their UID
+ALTER TABLE import_task DROP CONSTRAINT import_task_mode_check;
+ALTER TABLE import_task ADD CONSTRAINT import_task_mode_check
+    CHECK (mode IN ('objects', 'email', 'calendar'));
+
+-- Calendars subscribed to by URL, synced periodically. Their facts are
+-- created by the creator of the feed.
+CREATE TABLE calendar_feed (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES org(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES creator(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL,
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (org_id, url)
+);
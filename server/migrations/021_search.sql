@@ -0,0 +1,37 @@
+-- Unified search: the object visibility rules of creator_obj_type_access as
+-- functions, so every kind of search result applies them the same way, and
+-- the full-text indexes of the kinds searched besides objects
+
+-- A type is visible to admins, to the members given access to it and, when
+-- public, to the whole org
+CREATE OR REPLACE FUNCTION obj_type_visible_to(type UUID, viewer UUID) RETURNS boolean
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT EXISTS (SELECT 1 FROM creator WHERE id = viewer AND role = 'admin')
+        OR EXISTS (SELECT 1 FROM obj_type WHERE id = type AND is_public)
+        OR EXISTS (
+            SELECT 1 FROM creator_obj_type_access
+            WHERE creator_id = viewer AND obj_type_id = type);
+$$;
+
+-- An object is visible when it has no type, or one of its types is
+CREATE OR REPLACE FUNCTION obj_visible_to(obj UUID, viewer UUID) RETURNS boolean
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT NOT EXISTS (
+            SELECT 1 FROM obj_type_value
+            WHERE obj_id = obj AND deleted_at IS NULL)
+        OR EXISTS (
+            SELECT 1 FROM obj_type_value
+            WHERE obj_id = obj AND deleted_at IS NULL
+              AND obj_type_visible_to(type_id, viewer));
+$$;
+
+CREATE INDEX idx_fact_text_search ON fact
+    USING gin (to_tsvector('english', text || ' ' || location));
+CREATE INDEX idx_task_content_search ON task
+    USING gin (to_tsvector('english', content));
+CREATE INDEX idx_tag_text_search ON tag
+    USING gin (to_tsvector('english', name || ' ' || description));
+CREATE INDEX idx_list_text_search ON list
+    USING gin (to_tsvector('english', name || ' ' || description));
@@ -0,0 +1,42 @@
+-- Text queries of objects: type value criteria can compare, as criteria of
+-- computed fields already do (see schema.MatchCriterion). A criterion
+-- starting with >=, <=, !=, >, < or = compares the value numerically when
+-- both sides are numbers, as text otherwise, ISO dates included. Any other
+-- criterion matches the values containing it, ignoring case, as before.
+CREATE OR REPLACE FUNCTION type_value_matches(value TEXT, criterion TEXT) RETURNS boolean
+    LANGUAGE plpgsql IMMUTABLE
+    AS $$
+DECLARE
+    op TEXT;
+    operand TEXT;
+    num_value NUMERIC;
+    num_operand NUMERIC;
+    cmp INT;
+BEGIN
+    criterion := btrim(criterion);
+    op := substring(criterion FROM '^(>=|<=|!=|>|<|=)');
+    IF op IS NULL THEN
+        RETURN value ILIKE '%' || criterion || '%';
+    END IF;
+    operand := btrim(substr(criterion, length(op) + 1));
+
+    IF value ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$' AND operand ~ '^-?[0-9]+(\.[0-9]+)?$' THEN
+        num_value := value::NUMERIC;
+        num_operand := operand::NUMERIC;
+        cmp := CASE WHEN num_value < num_operand THEN -1 WHEN num_value > num_operand THEN 1 ELSE 0 END;
+    ELSIF op IN ('=', '!=') THEN
+        cmp := CASE WHEN lower(value) = lower(operand) THEN 0 ELSE 1 END;
+    ELSE
+        cmp := CASE WHEN value < operand THEN -1 WHEN value > operand THEN 1 ELSE 0 END;
+    END IF;
+
+    RETURN CASE op
+        WHEN '>=' THEN cmp >= 0
+        WHEN '<=' THEN cmp <= 0
+        WHEN '>' THEN cmp > 0
+        WHEN '<' THEN cmp < 0
+        WHEN '=' THEN cmp = 0
+        ELSE cmp <> 0
+    END;
+END;
+$$;
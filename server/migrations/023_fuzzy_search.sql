@@ -0,0 +1,56 @@
+-- Typo tolerant search of objects, by trigram similarity (pg_trgm) of their
+-- name, aliases and id_string, and of the values of their contact fields.
+
+-- The text of an object names are matched against, lower case
+CREATE OR REPLACE FUNCTION obj_fuzzy_text(
+    obj_name text,
+    obj_id_string text,
+    obj_aliases text[]
+) RETURNS text
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT lower(
+        obj_name || ' ' ||
+        obj_id_string || ' ' ||
+        coalesce(array_to_string(obj_aliases, ' '), '')
+    );
+$$;
+
+-- The values of the email, phone and url fields of an object, lower case
+CREATE OR REPLACE FUNCTION obj_contact_text(obj UUID) RETURNS text
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT lower(coalesce(string_agg(v.value, ' '), ''))
+    FROM obj_type_value otv
+    JOIN obj_type ot ON ot.id = otv.type_id
+    CROSS JOIN LATERAL jsonb_each(ot.fields) AS f(name, def)
+    CROSS JOIN LATERAL jsonb_array_elements_text(
+        CASE jsonb_typeof(otv.type_values->f.name)
+            WHEN 'array' THEN otv.type_values->f.name
+            WHEN 'string' THEN jsonb_build_array(otv.type_values->f.name)
+            ELSE '[]'::jsonb
+        END
+    ) AS v(value)
+    WHERE otv.obj_id = obj AND otv.deleted_at IS NULL
+      AND coalesce(f.def->>'type', f.def #>> '{}') IN ('email', 'phone', 'url');
+$$;
+
+-- How close a search is to an object, from 0 to 1: the best of the
+-- similarity to its name and the word similarity to its names, aliases and
+-- contact values, so that partial handles match too
+CREATE OR REPLACE FUNCTION obj_fuzzy_score(
+    query text,
+    obj_name text,
+    obj_id_string text,
+    obj_aliases text[],
+    contact_text text
+) RETURNS float8
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT GREATEST(
+        similarity(lower(query), lower(obj_name)),
+        word_similarity(lower(query), obj_fuzzy_text(obj_name, obj_id_string, obj_aliases)),
+        CASE WHEN contact_text = '' THEN 0 ELSE word_similarity(lower(query), contact_text) END
+    )::float8;
+$$;
+
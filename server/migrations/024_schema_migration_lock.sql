@@ -0,0 +1,62 @@
+-- Schema migrations lock their object type for writes while they run, so no
+-- value is validated against the definition being replaced, and keep the
+-- values they rewrite, so that a run that fails or dies is reverted.
+
+-- A run is live while it keeps making progress, one that did not update its
+-- row for 15 minutes died with its process and no longer locks the type
+CREATE OR REPLACE FUNCTION schema_migration_is_live(status VARCHAR, updated_at TIMESTAMPTZ)
+RETURNS BOOLEAN
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT status IN ('pending', 'processing')
+        AND updated_at > CURRENT_TIMESTAMP - interval '15 minutes';
+$$;
+
+-- The live run rewriting the values of an object type, if any
+CREATE OR REPLACE FUNCTION schema_migration_running(type_id UUID)
+RETURNS UUID
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT id FROM schema_migration
+    WHERE obj_type_id = type_id AND NOT dry_run
+      AND schema_migration_is_live(status, updated_at)
+    LIMIT 1;
+$$;
+
+-- Only the run itself, which sets muninn.schema_migration to its id in its
+-- transactions, writes the values and the fields of a type it locks
+CREATE OR REPLACE FUNCTION schema_migration_lock_trigger() RETURNS trigger AS $$
+DECLARE
+    type_id UUID;
+    running UUID;
+BEGIN
+    IF TG_TABLE_NAME = 'obj_type' THEN
+        type_id := NEW.id;
+    ELSE
+        type_id := NEW.type_id;
+    END IF;
+    running := schema_migration_running(type_id);
+    IF running IS NOT NULL AND running::text IS DISTINCT FROM current_setting('muninn.schema_migration', true) THEN
+        RAISE EXCEPTION 'object type % is being migrated', type_id USING ERRCODE = 'object_in_use';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER schema_migration_lock
+BEFORE INSERT OR UPDATE OF type_values ON obj_type_value
+FOR EACH ROW EXECUTE FUNCTION schema_migration_lock_trigger();
+
+CREATE TRIGGER schema_migration_lock
+BEFORE UPDATE OF fields ON obj_type
+FOR EACH ROW EXECUTE FUNCTION schema_migration_lock_trigger();
+
+-- The values a run rewrote, restored when it is reverted unless the row was
+-- changed again since
+CREATE TABLE schema_migration_backup (
+    migration_id UUID NOT NULL REFERENCES schema_migration(id) ON DELETE CASCADE,
+    type_value_id UUID NOT NULL REFERENCES obj_type_value(id) ON DELETE CASCADE,
+    type_values JSONB NOT NULL,
+    migrated_version INT NOT NULL,
+    PRIMARY KEY (migration_id, type_value_id)
+);
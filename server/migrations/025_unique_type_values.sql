@@ -0,0 +1,61 @@
+-- Unique fields are checked by the write paths before they write, two writes
+-- of the same value at once would both pass. This trigger checks again when
+-- the transaction commits, under a lock on the value: the second write waits
+-- for the first one and then sees its value. It runs at commit so that a
+-- merge can give the target the value of an object it deletes.
+CREATE OR REPLACE FUNCTION obj_type_value_unique_trigger() RETURNS trigger AS $$
+DECLARE
+    current_values JSONB;
+    field TEXT;
+    value_key TEXT;
+    other UUID;
+BEGIN
+    -- The row as the transaction leaves it, it may have changed since
+    SELECT otv.type_values INTO current_values
+    FROM obj_type_value otv
+    JOIN obj o ON o.id = otv.obj_id
+    WHERE otv.id = NEW.id AND otv.deleted_at IS NULL AND o.deleted_at IS NULL;
+    IF NOT FOUND THEN
+        RETURN NULL;
+    END IF;
+
+    FOR field IN
+        SELECT f.key
+        FROM obj_type ot, jsonb_each(ot.fields) f
+        WHERE ot.id = NEW.type_id
+          AND jsonb_typeof(f.value) = 'object'
+          AND f.value #>> '{validation,unique}' = 'true'
+        ORDER BY f.key
+    LOOP
+        value_key := lower(trim(current_values ->> field));
+        CONTINUE WHEN value_key IS NULL OR value_key = '';
+        -- Values kept from before the field was unique are left alone
+        CONTINUE WHEN TG_OP = 'UPDATE' AND lower(trim(OLD.type_values ->> field)) IS NOT DISTINCT FROM value_key;
+
+        PERFORM pg_advisory_xact_lock(hashtext(NEW.type_id::text || ':' || field || ':' || value_key));
+        SELECT o.id INTO other
+        FROM obj_type_value otv
+        JOIN obj o ON o.id = otv.obj_id
+        WHERE otv.type_id = NEW.type_id
+          AND otv.id <> NEW.id
+          AND otv.obj_id <> NEW.obj_id
+          AND otv.deleted_at IS NULL
+          AND o.deleted_at IS NULL
+          AND lower(trim(otv.type_values ->> field)) = value_key
+        LIMIT 1;
+        IF other IS NOT NULL THEN
+            RAISE EXCEPTION '% "%" is already used by object %', field, current_values ->> field, other
+                USING ERRCODE = 'unique_violation',
+                      CONSTRAINT = 'obj_type_value_unique_field',
+                      COLUMN = field,
+                      DETAIL = other::text;
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER obj_type_value_unique
+AFTER INSERT OR UPDATE OF type_values ON obj_type_value
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION obj_type_value_unique_trigger();
@@ -0,0 +1,55 @@
+-- The search vector of a type value covers all of its fields, restricted
+-- ones included. These functions follow schema.CanRead for stored fields so
+-- that a search only matches the values the viewer may read.
+
+-- A field is readable by admins, when it is not restricted, or when the role
+-- is one of its read or write roles
+CREATE OR REPLACE FUNCTION obj_field_readable(def JSONB, viewer_role TEXT) RETURNS BOOLEAN AS $$
+    SELECT viewer_role = 'admin'
+        OR jsonb_typeof(def) IS DISTINCT FROM 'object'
+        OR COALESCE((def #>> '{access,restricted}')::BOOLEAN, false) IS NOT TRUE
+        OR COALESCE(def #> '{access,readRoles}', '[]'::JSONB) ? viewer_role
+        OR COALESCE(def #> '{access,writeRoles}', '[]'::JSONB) ? viewer_role;
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Tells if a type has any restricted field, fields may be stored as an empty
+-- array for types without fields
+CREATE OR REPLACE FUNCTION obj_type_has_restricted(fields JSONB) RETURNS BOOLEAN AS $$
+    SELECT jsonb_typeof(fields) = 'object' AND EXISTS (
+        SELECT 1 FROM jsonb_each(fields) f
+        WHERE jsonb_typeof(f.value) = 'object'
+          AND COALESCE((f.value #>> '{access,restricted}')::BOOLEAN, false)
+    );
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- The type values without the fields the role may not read
+CREATE OR REPLACE FUNCTION obj_type_value_readable(type_values JSONB, fields JSONB, viewer_role TEXT) RETURNS JSONB AS $$
+    SELECT CASE
+        WHEN viewer_role = 'admin' OR NOT obj_type_has_restricted(fields) THEN type_values
+        ELSE COALESCE((
+            SELECT jsonb_object_agg(v.key, v.value)
+            FROM jsonb_each(type_values) v
+            WHERE obj_field_readable(fields -> v.key, viewer_role)
+        ), '{}'::JSONB)
+    END;
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- The type value search vector of an object as the role sees it, the stored
+-- search_vector when nothing is hidden
+CREATE OR REPLACE FUNCTION obj_type_value_search_for(obj UUID, viewer_role TEXT) RETURNS TSVECTOR AS $$
+    SELECT string_agg(
+        CASE WHEN viewer_role = 'admin' OR NOT obj_type_has_restricted(ot.fields) THEN otv.search_vector
+             ELSE to_tsvector('english', jsonb_to_text(obj_type_value_readable(otv.type_values, ot.fields, viewer_role)))
+        END::TEXT, ' ')::TSVECTOR
+    FROM obj_type_value otv
+    JOIN obj_type ot ON ot.id = otv.type_id
+    WHERE otv.obj_id = obj;
+$$ LANGUAGE SQL STABLE;
+
+-- The type values of an object as the role sees them, for search headlines
+CREATE OR REPLACE FUNCTION obj_type_value_text_for(obj UUID, viewer_role TEXT) RETURNS TEXT AS $$
+    SELECT string_agg(DISTINCT COALESCE(obj_type_value_readable(otv.type_values, ot.fields, viewer_role)::TEXT, ''), ' ')
+    FROM obj_type_value otv
+    JOIN obj_type ot ON ot.id = otv.type_id
+    WHERE otv.obj_id = obj;
+$$ LANGUAGE SQL STABLE;
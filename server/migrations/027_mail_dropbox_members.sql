@@ -0,0 +1,8 @@
+-- Drop-box addresses are per member: mail sent to one is logged as a fact of
+-- its member, whatever the From: header says, so nobody can log facts as
+-- another member by forging it. The address an org had becomes the one of
+-- its creator.
+ALTER TABLE mail_dropbox DROP CONSTRAINT mail_dropbox_pkey;
+ALTER TABLE mail_dropbox ADD PRIMARY KEY (creator_id);
+
+CREATE INDEX idx_mail_dropbox_org_id ON mail_dropbox(org_id);
@@ -0,0 +1,47 @@
+-- Type value criteria compiled from text queries compare values: they are
+-- encoded as {"op": ">=", "value": "100"}. Criteria saved as strings match
+-- the values containing them, ignoring case, whatever they start with.
+CREATE OR REPLACE FUNCTION type_value_matches(value TEXT, criterion TEXT) RETURNS boolean
+    LANGUAGE SQL IMMUTABLE
+    AS $$
+    SELECT value ILIKE '%' || criterion || '%';
+$$;
+
+-- A comparison compares numerically when both sides are numbers, else as
+-- text byte by byte, which orders ISO dates whatever the collation
+CREATE OR REPLACE FUNCTION type_value_matches(value TEXT, criterion JSONB) RETURNS boolean
+    LANGUAGE plpgsql IMMUTABLE
+    AS $$
+DECLARE
+    op TEXT;
+    operand TEXT;
+    cmp INT;
+BEGIN
+    IF jsonb_typeof(criterion) IS DISTINCT FROM 'object' THEN
+        RETURN type_value_matches(value, criterion #>> '{}');
+    END IF;
+    op := criterion->>'op';
+    operand := btrim(COALESCE(criterion->>'value', ''));
+    value := btrim(value);
+
+    IF value ~ '^-?[0-9]+(\.[0-9]+)?$' AND operand ~ '^-?[0-9]+(\.[0-9]+)?$' THEN
+        cmp := sign(value::NUMERIC - operand::NUMERIC);
+    ELSIF op IN ('=', '!=') THEN
+        cmp := CASE WHEN lower(value) = lower(operand) THEN 0 ELSE 1 END;
+    ELSE
+        cmp := CASE WHEN value COLLATE "C" < operand COLLATE "C" THEN -1
+                    WHEN value COLLATE "C" > operand COLLATE "C" THEN 1
+                    ELSE 0 END;
+    END IF;
+
+    RETURN CASE op
+        WHEN '>=' THEN cmp >= 0
+        WHEN '<=' THEN cmp <= 0
+        WHEN '>' THEN cmp > 0
+        WHEN '<' THEN cmp < 0
+        WHEN '=' THEN cmp = 0
+        WHEN '!=' THEN cmp <> 0
+        ELSE false
+    END;
+END;
+$$;
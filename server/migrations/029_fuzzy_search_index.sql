@@ -0,0 +1,195 @@
+-- Typo tolerant search through trigram indexes, without the contact values
+-- of fields hidden from the viewer. The contact values of a type value are
+-- kept on its row, the public ones as text and the restricted ones by field,
+-- so a search neither recomputes them for every object nor matches values
+-- the viewer may not read.
+
+-- The aliases of an object as one lower case text, array_to_string is only
+-- stable in general but immutable for text arrays
+CREATE OR REPLACE FUNCTION obj_aliases_text(obj_aliases text[]) RETURNS text
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT lower(coalesce(array_to_string(obj_aliases, ' '), ''));
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_obj_name_trgm ON obj USING GIN (lower(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_obj_id_string_trgm ON obj USING GIN (lower(id_string) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_obj_aliases_trgm ON obj USING GIN (obj_aliases_text(aliases) gin_trgm_ops);
+
+-- The values of the email, phone and url fields of type values, lower case:
+-- the ones of public fields as text, the ones of restricted fields by field
+CREATE OR REPLACE FUNCTION obj_type_value_contacts(
+    type_values JSONB,
+    fields JSONB,
+    OUT contact_text TEXT,
+    OUT restricted_contacts JSONB
+)
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    WITH contact AS (
+        SELECT f.name,
+               jsonb_typeof(f.def) = 'object'
+                   AND COALESCE((f.def #>> '{access,restricted}')::BOOLEAN, false) AS restricted,
+               lower(string_agg(v.value, ' ')) AS value_text
+        FROM jsonb_each(CASE WHEN jsonb_typeof(fields) = 'object' THEN fields ELSE '{}'::JSONB END) AS f(name, def)
+        CROSS JOIN LATERAL jsonb_array_elements_text(
+            CASE jsonb_typeof(type_values->f.name)
+                WHEN 'array' THEN type_values->f.name
+                WHEN 'string' THEN jsonb_build_array(type_values->f.name)
+                ELSE '[]'::JSONB
+            END
+        ) AS v(value)
+        WHERE coalesce(f.def->>'type', f.def #>> '{}') IN ('email', 'phone', 'url')
+        GROUP BY f.name, f.def
+    )
+    SELECT
+        coalesce((SELECT string_agg(value_text, ' ') FROM contact WHERE NOT restricted), ''),
+        coalesce((SELECT jsonb_object_agg(name, value_text) FROM contact WHERE restricted), '{}'::JSONB);
+$$;
+
+ALTER TABLE obj_type_value
+    ADD COLUMN contact_text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN restricted_contacts JSONB NOT NULL DEFAULT '{}';
+
+-- Recomputing the contact values is not an edit of the type value, so only
+-- changes of the values themselves or of other columns touch last_updated
+DROP TRIGGER update_obj_type_value_last_updated ON obj_type_value;
+CREATE TRIGGER update_obj_type_value_last_updated
+BEFORE UPDATE ON obj_type_value
+FOR EACH ROW
+WHEN (OLD.type_values IS DISTINCT FROM NEW.type_values
+    OR (OLD.contact_text, OLD.restricted_contacts) IS NOT DISTINCT FROM (NEW.contact_text, NEW.restricted_contacts))
+EXECUTE FUNCTION update_last_updated();
+
+UPDATE obj_type_value otv
+SET (contact_text, restricted_contacts) = (
+    SELECT c.contact_text, c.restricted_contacts
+    FROM obj_type_value_contacts(otv.type_values, ot.fields) c
+)
+FROM obj_type ot
+WHERE ot.id = otv.type_id;
+
+CREATE OR REPLACE FUNCTION obj_type_value_contacts_trigger() RETURNS trigger AS $$
+BEGIN
+    SELECT c.contact_text, c.restricted_contacts
+    INTO NEW.contact_text, NEW.restricted_contacts
+    FROM obj_type ot
+    CROSS JOIN LATERAL obj_type_value_contacts(NEW.type_values, ot.fields) c
+    WHERE ot.id = NEW.type_id;
+    NEW.contact_text := coalesce(NEW.contact_text, '');
+    NEW.restricted_contacts := coalesce(NEW.restricted_contacts, '{}');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER obj_type_value_contacts_update
+BEFORE INSERT OR UPDATE OF type_values, type_id ON obj_type_value
+FOR EACH ROW EXECUTE FUNCTION obj_type_value_contacts_trigger();
+
+-- A field may become a contact field, or restricted, or public again
+CREATE OR REPLACE FUNCTION obj_type_contacts_trigger() RETURNS trigger AS $$
+BEGIN
+    UPDATE obj_type_value otv
+    SET (contact_text, restricted_contacts) = (
+        SELECT c.contact_text, c.restricted_contacts
+        FROM obj_type_value_contacts(otv.type_values, NEW.fields) c
+    )
+    WHERE otv.type_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER obj_type_contacts_update
+AFTER UPDATE OF fields ON obj_type
+FOR EACH ROW
+WHEN (OLD.fields IS DISTINCT FROM NEW.fields)
+EXECUTE FUNCTION obj_type_contacts_trigger();
+
+CREATE INDEX IF NOT EXISTS idx_obj_type_value_contact_trgm ON obj_type_value USING GIN (contact_text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_obj_type_value_restricted_contacts ON obj_type_value (obj_id)
+    WHERE restricted_contacts <> '{}';
+
+-- The contact values of a type value the role may read
+CREATE OR REPLACE FUNCTION obj_contact_text_for(
+    contact_text TEXT,
+    restricted_contacts JSONB,
+    fields JSONB,
+    viewer_role TEXT
+) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT concat_ws(' ', NULLIF(contact_text, ''), (
+        SELECT string_agg(r.value, ' ')
+        FROM jsonb_each_text(restricted_contacts) r
+        WHERE obj_field_readable(fields -> r.key, viewer_role)
+    ));
+$$;
+
+-- The contact values of an object the role may read, replaces the one of
+-- 023 that read every field
+DROP FUNCTION IF EXISTS obj_contact_text(UUID);
+CREATE OR REPLACE FUNCTION obj_contact_text(obj UUID, viewer_role TEXT) RETURNS TEXT
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT coalesce(string_agg(NULLIF(obj_contact_text_for(otv.contact_text, otv.restricted_contacts, ot.fields, viewer_role), ''), ' '), '')
+    FROM obj_type_value otv
+    JOIN obj_type ot ON ot.id = otv.type_id
+    WHERE otv.obj_id = obj AND otv.deleted_at IS NULL;
+$$;
+
+-- How close a search is to an object, from 0 to 1: the best of the
+-- similarity to its name and the word similarity to its name, id_string,
+-- aliases and contact values, each of them indexed so that the % and <%
+-- operators of obj_fuzzy_matches find the same objects
+CREATE OR REPLACE FUNCTION obj_fuzzy_score(
+    query text,
+    obj_name text,
+    obj_id_string text,
+    obj_aliases text[],
+    contact_text text
+) RETURNS float8
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT GREATEST(
+        similarity(lower(query), lower(obj_name)),
+        word_similarity(lower(query), lower(obj_name)),
+        word_similarity(lower(query), lower(obj_id_string)),
+        word_similarity(lower(query), obj_aliases_text(obj_aliases)),
+        CASE WHEN contact_text = '' THEN 0 ELSE word_similarity(lower(query), contact_text) END
+    )::float8;
+$$;
+
+DROP FUNCTION IF EXISTS obj_fuzzy_text(text, text, text[]);
+
+-- The objects of an org close to a search as the role sees them, with their
+-- score. Candidates come from the trigram indexes, so the pg_trgm
+-- similarity_threshold and word_similarity_threshold of the transaction
+-- decide how close they must be
+CREATE OR REPLACE FUNCTION obj_fuzzy_matches(search_org UUID, search_query TEXT, viewer_role TEXT)
+RETURNS TABLE (obj_id UUID, similarity float8)
+    LANGUAGE sql STABLE
+    AS $$
+    WITH s AS (
+        SELECT lower(search_query) AS q WHERE search_query <> ''
+    ),
+    candidate AS (
+        SELECT o.id FROM s, obj o WHERE s.q % lower(o.name) OR s.q <% lower(o.name)
+        UNION
+        SELECT o.id FROM s, obj o WHERE s.q <% lower(o.id_string)
+        UNION
+        SELECT o.id FROM s, obj o WHERE s.q <% obj_aliases_text(o.aliases)
+        UNION
+        SELECT otv.obj_id FROM s, obj_type_value otv
+        WHERE s.q <% otv.contact_text AND otv.deleted_at IS NULL
+        UNION
+        SELECT otv.obj_id FROM s, obj_type_value otv
+        JOIN obj_type ot ON ot.id = otv.type_id
+        WHERE otv.restricted_contacts <> '{}' AND otv.deleted_at IS NULL
+          AND s.q <% obj_contact_text_for(otv.contact_text, otv.restricted_contacts, ot.fields, viewer_role)
+    )
+    SELECT o.id, obj_fuzzy_score(search_query, o.name, o.id_string, o.aliases, obj_contact_text(o.id, viewer_role))
+    FROM candidate cd
+    JOIN obj o ON o.id = cd.id
+    JOIN creator c ON c.id = o.creator_id
+    WHERE c.org_id = search_org AND o.deleted_at IS NULL;
+$$;
@@ -0,0 +1,3 @@
+-- The history of an object is an audit trail, it outlives the object: purging
+-- an object from the trash leaves its history rows, still keyed by its id
+ALTER TABLE obj_history DROP CONSTRAINT IF EXISTS obj_history_obj_id_fkey;
@@ -17,7 +17,8 @@ const MarkdownDisplay: React.FC<MarkdownDisplayProps> = ({
   const [isExpanded, setIsExpanded] = React.useState(false);
   const renderedContent = useMemo(() => {
     // Replace mentions with links
-    const mentionRegex = /@\[([^\]]+)\]\((\w+):([^)]+)\)/g;
+    // Names keep their escapes, they are markdown link text
+    const mentionRegex = /@\[((?:[^\]\\]|\\.)+)\]\((\w+):([^)]+)\)/g;
     return content.replace(mentionRegex, (_, name, type, id) => {
       const path = type === 'creator' ? `/users/${id}` : `/objects/${id}`;
       return `[${name}](${path})`;
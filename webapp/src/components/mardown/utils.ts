@@ -1,10 +1,12 @@
 export const extractRelatedItems = (text: string): any[] => {
-  const mentionRegex = /@\[([^\]]+)\]\((\w+):([^)]+)\)/g;
+  // ] and \ are escaped in mention names
+  const mentionRegex = /@\[((?:[^\]\\]|\\.)+)\]\((\w+):([^)]+)\)/g;
   const items: any[] = [];
   let match;
 
   while ((match = mentionRegex.exec(text)) !== null) {
-    const [, name, type, id] = match;
+    const [, escapedName, type, id] = match;
+    const name = escapedName.replace(/\\(.)/g, '$1');
     const item = {
       type,
       payload: { id, name },
@@ -27,5 +29,6 @@ export const getInitialValueFromMentionItem = ({
     name: string;
   };
 }): string => {
-  return `@[${payload.name}](${type}:${payload.id})`;
+  const name = payload.name.replace(/[\\\]]/g, '\\$&');
+  return `@[${name}](${type}:${payload.id})`;
 };